@@ -6,6 +6,7 @@ package apply
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -15,10 +16,13 @@ import (
 	"k8s.io/kubectl/pkg/util/i18n"
 	"sigs.k8s.io/cli-utils/cmd/flagutils"
 	"sigs.k8s.io/cli-utils/pkg/apply"
+	"sigs.k8s.io/cli-utils/pkg/apply/prune"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/manifestreader"
 	"sigs.k8s.io/cli-utils/pkg/printers"
+	"sigs.k8s.io/cli-utils/pkg/printers/journal"
 )
 
 func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory,
@@ -42,6 +46,10 @@ func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory,
 		"If true, overwrite applied fields on server if field manager conflict.")
 	cmd.Flags().StringVar(&r.serverSideOptions.FieldManager, "field-manager", common.DefaultFieldManager,
 		"The client owner of the fields being applied on the server-side.")
+	cmd.Flags().BoolVar(&r.upgradeClientSideApply, "upgrade-client-side-apply", false,
+		"If true with --server-side, transfer ownership of fields previously applied client-side to the field manager.")
+	cmd.Flags().BoolVar(&r.clientSideApplyFallback, "client-side-apply-fallback", false,
+		"If true with --server-side, apply objects the server can't apply server-side, e.g. custom resources with a non-structural schema, with client-side apply.")
 
 	cmd.Flags().StringVar(&r.output, "output", printers.DefaultPrinter(),
 		fmt.Sprintf("Output format, must be one of %s", strings.Join(printers.SupportedPrinters(), ",")))
@@ -53,6 +61,11 @@ func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory,
 		"Background", "Propagation policy for pruning")
 	cmd.Flags().DurationVar(&r.pruneTimeout, "prune-timeout", time.Duration(0),
 		"Timeout threshold for waiting for all pruned resources to be deleted")
+	cmd.Flags().StringVar(&r.pruneOrdering, "prune-ordering", string(prune.OrderingReverseApply),
+		fmt.Sprintf("Order in which resources are pruned, must be one of %q, %q or %q",
+			prune.OrderingReverseApply, prune.OrderingDependencies, prune.OrderingSimultaneous))
+	cmd.Flags().IntVar(&r.pruneConcurrency, "prune-concurrency", 1,
+		"Maximum number of resources deleted at the same time while pruning")
 	cmd.Flags().StringVar(&r.inventoryPolicy, flagutils.InventoryPolicyFlag, flagutils.InventoryPolicyStrict,
 		"It determines the behavior when the resources don't belong to current inventory. Available options "+
 			fmt.Sprintf("%q, %q and %q.", flagutils.InventoryPolicyStrict, flagutils.InventoryPolicyAdopt, flagutils.InventoryPolicyForceAdopt))
@@ -60,6 +73,39 @@ func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory,
 		"How long to wait before exiting")
 	cmd.Flags().BoolVar(&r.printStatusEvents, "status-events", false,
 		"Print status events (always enabled for table output)")
+	cmd.Flags().BoolVar(&r.continueOnError, "continue-on-error", false,
+		"Apply objects even if their dependencies failed to apply or reconcile")
+	cmd.Flags().BoolVar(&r.adoptOrphaned, "adopt-orphaned", false,
+		"If true, claim existing resources that don't belong to any inventory before applying them")
+	cmd.Flags().BoolVar(&r.traceOrdering, "trace-ordering", false,
+		"If true, print why each resource is applied or pruned in its phase")
+	cmd.Flags().BoolVar(&r.planSummary, "plan-summary", false,
+		"If true, print the number of resources to create, configure and prune before applying them")
+	cmd.Flags().BoolVar(&r.waitForExternalDependencies, "wait-for-external-dependencies", false,
+		"If true, allow depends-on annotations to reference resources outside the inventory, and wait for them "+
+			"before applying any resources")
+	cmd.Flags().StringVar(&r.externalDependencyCondition, "external-dependency-condition", externalDependencyCurrent,
+		fmt.Sprintf("Condition external dependencies are waited for, must be one of %q or %q",
+			externalDependencyCurrent, externalDependencyExists))
+	cmd.Flags().DurationVar(&r.externalDependencyTimeout, "external-dependency-timeout", time.Duration(0),
+		"Timeout threshold for waiting for external dependencies")
+	cmd.Flags().BoolVar(&r.rollback, "rollback", false,
+		"If true, delete or revert the resources applied by this run if the apply fails")
+	cmd.Flags().BoolVar(&r.validateSchema, "validate-schema", false,
+		"If true, validate the resources against the OpenAPI schema of the cluster before applying any of them")
+	cmd.Flags().StringVar(&r.statusStrategy, flagutils.StatusStrategyFlag, flagutils.StatusStrategyWatch,
+		fmt.Sprintf("How the status of resources is tracked, must be one of %q or %q. "+
+			"Watching falls back to polling if watching resources is forbidden.",
+			flagutils.StatusStrategyWatch, flagutils.StatusStrategyPoll))
+	cmd.Flags().Float32Var(&r.throttleQPS, flagutils.ThrottleQPSFlag, 0,
+		"Maximum number of apply and delete requests per second, reduced while the server throttles requests. "+
+			"Zero disables throttling.")
+	cmd.Flags().IntVar(&r.throttleBurst, flagutils.ThrottleBurstFlag, 1,
+		"Maximum number of requests sent at once when throttled by --"+flagutils.ThrottleQPSFlag)
+
+	cmd.Flags().StringVar(&r.journal, "journal", "",
+		"If set, write the events of the run to the file, to be printed later with the replay command. "+
+			"The values of Secrets are redacted.")
 
 	r.Command = cmd
 	return r
@@ -77,17 +123,40 @@ type Runner struct {
 	invFactory inventory.ClientFactory
 	loader     manifestreader.ManifestLoader
 
-	serverSideOptions      common.ServerSideOptions
-	output                 string
-	reconcileTimeout       time.Duration
-	noPrune                bool
-	prunePropagationPolicy string
-	pruneTimeout           time.Duration
-	inventoryPolicy        string
-	timeout                time.Duration
-	printStatusEvents      bool
+	serverSideOptions       common.ServerSideOptions
+	output                  string
+	reconcileTimeout        time.Duration
+	noPrune                 bool
+	prunePropagationPolicy  string
+	pruneTimeout            time.Duration
+	pruneOrdering           string
+	pruneConcurrency        int
+	inventoryPolicy         string
+	timeout                 time.Duration
+	printStatusEvents       bool
+	continueOnError         bool
+	statusStrategy          string
+	throttleQPS             float32
+	throttleBurst           int
+	journal                 string
+	adoptOrphaned           bool
+	traceOrdering           bool
+	planSummary             bool
+	upgradeClientSideApply  bool
+	clientSideApplyFallback bool
+	rollback                bool
+	validateSchema          bool
+
+	waitForExternalDependencies bool
+	externalDependencyCondition string
+	externalDependencyTimeout   time.Duration
 }
 
+const (
+	externalDependencyCurrent = "current"
+	externalDependencyExists  = "exists"
+)
+
 func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 	// If specified, cancel with timeout.
@@ -101,10 +170,28 @@ func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	pruneOrdering, err := prune.ParseOrdering(r.pruneOrdering)
+	if err != nil {
+		return err
+	}
 	inventoryPolicy, err := flagutils.ConvertInventoryPolicy(r.inventoryPolicy)
 	if err != nil {
 		return err
 	}
+	statusStrategy, err := flagutils.ConvertStatusStrategy(r.statusStrategy)
+	if err != nil {
+		return err
+	}
+
+	var externalDependencyCondition taskrunner.Condition
+	switch r.externalDependencyCondition {
+	case externalDependencyCurrent:
+		externalDependencyCondition = taskrunner.AllCurrent
+	case externalDependencyExists:
+		externalDependencyCondition = taskrunner.AllExist
+	default:
+		return fmt.Errorf("unknown external dependency condition %q", r.externalDependencyCondition)
+	}
 
 	if found := printers.ValidatePrinterType(r.output); !found {
 		return fmt.Errorf("unknown output type %q", r.output)
@@ -135,6 +222,13 @@ func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	// Write the inventory with the same field manager as the objects, if
+	// one is set explicitly.
+	if cc, ok := invClient.(*inventory.ClusterClient); ok && cmd.Flags().Changed("field-manager") {
+		if err := cc.SetFieldManager(r.serverSideOptions.FieldManager); err != nil {
+			return err
+		}
+	}
 
 	// Run the applier. It will return a channel where we can receive updates
 	// to keep track of progress and any issues.
@@ -151,21 +245,61 @@ func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
 		r.printStatusEvents = true
 	}
 
+	// Open the journal before the run starts, so the run is not started
+	// if the journal can not be written.
+	var jw *journal.Writer
+	if r.journal != "" {
+		f, err := os.Create(r.journal)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		jw = journal.NewWriter(f, common.DryRunNone)
+	}
+
 	ch := a.Run(ctx, inv, objs, apply.ApplierOptions{
 		ServerSideOptions: r.serverSideOptions,
 		ReconcileTimeout:  r.reconcileTimeout,
 		// If we are not waiting for status, tell the applier to not
 		// emit the events.
-		EmitStatusEvents:       r.printStatusEvents,
-		NoPrune:                r.noPrune,
-		DryRunStrategy:         common.DryRunNone,
-		PrunePropagationPolicy: prunePropPolicy,
-		PruneTimeout:           r.pruneTimeout,
-		InventoryPolicy:        inventoryPolicy,
+		EmitStatusEvents:        r.printStatusEvents,
+		NoPrune:                 r.noPrune,
+		DryRunStrategy:          common.DryRunNone,
+		PrunePropagationPolicy:  prunePropPolicy,
+		PruneTimeout:            r.pruneTimeout,
+		PruneOrdering:           pruneOrdering,
+		PruneConcurrency:        r.pruneConcurrency,
+		InventoryPolicy:         inventoryPolicy,
+		AdoptOrphaned:           r.adoptOrphaned,
+		EmitTraceEvents:         r.traceOrdering,
+		EmitPlanSummary:         r.planSummary,
+		UpgradeClientSideApply:  r.upgradeClientSideApply,
+		ClientSideApplyFallback: r.clientSideApplyFallback,
+		ContinueOnError:         r.continueOnError,
+		StatusStrategy:          statusStrategy,
+		QPS:                     r.throttleQPS,
+		Burst:                   r.throttleBurst,
+		Rollback:                r.rollback,
+		ValidateSchema:          r.validateSchema,
+
+		WaitForExternalDependencies: r.waitForExternalDependencies,
+		ExternalDependencyCondition: externalDependencyCondition,
+		ExternalDependencyTimeout:   r.externalDependencyTimeout,
 	})
 
+	// Write the events to the journal while printing them.
+	if jw != nil {
+		ch = jw.Tee(ch)
+	}
+
 	// The printer will print updates from the channel. It will block
 	// until the channel is closed.
 	printer := printers.GetPrinter(r.output, r.ioStreams)
-	return printer.Print(ch, common.DryRunNone, r.printStatusEvents)
+	if err := printer.Print(ch, common.DryRunNone, r.printStatusEvents); err != nil {
+		return err
+	}
+	if jw != nil {
+		return jw.Err()
+	}
+	return nil
 }
@@ -6,6 +6,7 @@ package destroy
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -15,10 +16,12 @@ import (
 	"k8s.io/kubectl/pkg/util/i18n"
 	"sigs.k8s.io/cli-utils/cmd/flagutils"
 	"sigs.k8s.io/cli-utils/pkg/apply"
+	"sigs.k8s.io/cli-utils/pkg/apply/prune"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/manifestreader"
 	"sigs.k8s.io/cli-utils/pkg/printers"
+	"sigs.k8s.io/cli-utils/pkg/printers/journal"
 )
 
 // GetRunner creates and returns the Runner which stores the cobra command.
@@ -46,10 +49,31 @@ func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory,
 		"Timeout threshold for waiting for all deleted resources to complete deletion")
 	cmd.Flags().StringVar(&r.deletePropagationPolicy, "delete-propagation-policy",
 		"Background", "Propagation policy for deletion")
+	cmd.Flags().StringVar(&r.deleteOrdering, "delete-ordering", string(prune.OrderingReverseApply),
+		fmt.Sprintf("Order in which resources are deleted, must be one of %q, %q or %q",
+			prune.OrderingReverseApply, prune.OrderingDependencies, prune.OrderingSimultaneous))
+	cmd.Flags().IntVar(&r.deleteConcurrency, "delete-concurrency", 1,
+		"Maximum number of resources deleted at the same time")
+	cmd.Flags().Int64Var(&r.gracePeriod, "grace-period", -1,
+		"Period of time in seconds given to each resource to terminate gracefully. "+
+			"Ignored if negative, in which case the default for the resource is used.")
 	cmd.Flags().DurationVar(&r.timeout, "timeout", 0,
 		"How long to wait before exiting")
 	cmd.Flags().BoolVar(&r.printStatusEvents, "status-events", false,
 		"Print status events (always enabled for table output)")
+	cmd.Flags().StringVar(&r.statusStrategy, flagutils.StatusStrategyFlag, flagutils.StatusStrategyWatch,
+		fmt.Sprintf("How the status of resources is tracked, must be one of %q or %q. "+
+			"Watching falls back to polling if watching resources is forbidden.",
+			flagutils.StatusStrategyWatch, flagutils.StatusStrategyPoll))
+	cmd.Flags().Float32Var(&r.throttleQPS, flagutils.ThrottleQPSFlag, 0,
+		"Maximum number of delete requests per second, reduced while the server throttles requests. "+
+			"Zero disables throttling.")
+	cmd.Flags().IntVar(&r.throttleBurst, flagutils.ThrottleBurstFlag, 1,
+		"Maximum number of requests sent at once when throttled by --"+flagutils.ThrottleQPSFlag)
+
+	cmd.Flags().StringVar(&r.journal, "journal", "",
+		"If set, write the events of the run to the file, to be printed later with the replay command. "+
+			"The values of Secrets are redacted.")
 
 	r.Command = cmd
 	return r
@@ -72,9 +96,16 @@ type Runner struct {
 	output                  string
 	deleteTimeout           time.Duration
 	deletePropagationPolicy string
+	deleteOrdering          string
+	deleteConcurrency       int
+	gracePeriod             int64
 	inventoryPolicy         string
 	timeout                 time.Duration
 	printStatusEvents       bool
+	statusStrategy          string
+	throttleQPS             float32
+	throttleBurst           int
+	journal                 string
 }
 
 func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
@@ -90,10 +121,18 @@ func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	deleteOrdering, err := prune.ParseOrdering(r.deleteOrdering)
+	if err != nil {
+		return err
+	}
 	inventoryPolicy, err := flagutils.ConvertInventoryPolicy(r.inventoryPolicy)
 	if err != nil {
 		return err
 	}
+	statusStrategy, err := flagutils.ConvertStatusStrategy(r.statusStrategy)
+	if err != nil {
+		return err
+	}
 
 	if found := printers.ValidatePrinterType(r.output); !found {
 		return fmt.Errorf("unknown output type %q", r.output)
@@ -131,17 +170,52 @@ func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
 		r.printStatusEvents = true
 	}
 
+	// A negative grace period means the resource default is used.
+	var gracePeriodSeconds *int64
+	if r.gracePeriod >= 0 {
+		gracePeriodSeconds = &r.gracePeriod
+	}
+
+	// Open the journal before the run starts, so the run is not started
+	// if the journal can not be written.
+	var jw *journal.Writer
+	if r.journal != "" {
+		f, err := os.Create(r.journal)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		jw = journal.NewWriter(f, common.DryRunNone)
+	}
+
 	// Run the destroyer. It will return a channel where we can receive updates
 	// to keep track of progress and any issues.
 	ch := d.Run(ctx, inv, apply.DestroyerOptions{
-		DeleteTimeout:           r.deleteTimeout,
-		DeletePropagationPolicy: deletePropPolicy,
-		InventoryPolicy:         inventoryPolicy,
-		EmitStatusEvents:        r.printStatusEvents,
+		DeleteTimeout:            r.deleteTimeout,
+		DeletePropagationPolicy:  deletePropPolicy,
+		DeleteGracePeriodSeconds: gracePeriodSeconds,
+		DeleteOrdering:           deleteOrdering,
+		DeleteConcurrency:        r.deleteConcurrency,
+		InventoryPolicy:          inventoryPolicy,
+		EmitStatusEvents:         r.printStatusEvents,
+		StatusStrategy:           statusStrategy,
+		QPS:                      r.throttleQPS,
+		Burst:                    r.throttleBurst,
 	})
 
+	// Write the events to the journal while printing them.
+	if jw != nil {
+		ch = jw.Tee(ch)
+	}
+
 	// The printer will print updates from the channel. It will block
 	// until the channel is closed.
 	printer := printers.GetPrinter(r.output, r.ioStreams)
-	return printer.Print(ch, common.DryRunNone, r.printStatusEvents)
+	if err := printer.Print(ch, common.DryRunNone, r.printStatusEvents); err != nil {
+		return err
+	}
+	if jw != nil {
+		return jw.Err()
+	}
+	return nil
 }
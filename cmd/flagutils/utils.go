@@ -8,6 +8,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
 )
 
 const (
@@ -15,6 +16,13 @@ const (
 	InventoryPolicyStrict     = "strict"
 	InventoryPolicyAdopt      = "adopt"
 	InventoryPolicyForceAdopt = "force-adopt"
+
+	StatusStrategyFlag  = "status-strategy"
+	StatusStrategyWatch = "watch"
+	StatusStrategyPoll  = "poll"
+
+	ThrottleQPSFlag   = "throttle-qps"
+	ThrottleBurstFlag = "throttle-burst"
 )
 
 // ConvertPropagationPolicy converts a propagationPolicy described as a
@@ -47,6 +55,20 @@ func ConvertInventoryPolicy(policy string) (inventory.Policy, error) {
 	}
 }
 
+// ConvertStatusStrategy converts a status strategy described as a string to
+// a watcher.Strategy that is passed into the Applier or Destroyer.
+func ConvertStatusStrategy(strategy string) (watcher.Strategy, error) {
+	switch strategy {
+	case StatusStrategyWatch:
+		return watcher.WatchStrategy, nil
+	case StatusStrategyPoll:
+		return watcher.PollStrategy, nil
+	default:
+		return watcher.WatchStrategy, fmt.Errorf(
+			"status strategy must be one of watch, poll")
+	}
+}
+
 // PathFromArgs returns the path which is a positional arg from args list
 // returns "-" if there is length of args is 0, which implies no path is provided
 func PathFromArgs(args []string) string {
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
 )
 
 func TestConvertInventoryPolicy(t *testing.T) {
@@ -50,3 +51,40 @@ func TestConvertInventoryPolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertStatusStrategy(t *testing.T) {
+	testcases := []struct {
+		value    string
+		strategy watcher.Strategy
+		err      error
+	}{
+		{
+			value:    "watch",
+			strategy: watcher.WatchStrategy,
+		},
+		{
+			value:    "poll",
+			strategy: watcher.PollStrategy,
+		},
+		{
+			value: "random",
+			err:   fmt.Errorf("status strategy must be one of watch, poll"),
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.value, func(t *testing.T) {
+			strategy, err := ConvertStatusStrategy(tc.value)
+			if tc.err == nil {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				if strategy != tc.strategy {
+					t.Errorf("expected %v but got %v", tc.strategy, strategy)
+				}
+			}
+			if err == nil && tc.err != nil {
+				t.Errorf("expected an error, but not happened")
+			}
+		})
+	}
+}
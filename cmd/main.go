@@ -22,6 +22,7 @@ import (
 	"sigs.k8s.io/cli-utils/cmd/diff"
 	"sigs.k8s.io/cli-utils/cmd/initcmd"
 	"sigs.k8s.io/cli-utils/cmd/preview"
+	"sigs.k8s.io/cli-utils/cmd/replay"
 	"sigs.k8s.io/cli-utils/cmd/status"
 	"sigs.k8s.io/cli-utils/pkg/flowcontrol"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
@@ -61,7 +62,12 @@ func main() {
 		ErrOut: os.Stderr,
 	}
 
-	loader := manifestreader.NewManifestLoader(f)
+	loaderOptions := &manifestreader.LoaderOptions{}
+	flags.BoolVar(&loaderOptions.Kustomize, "kustomize", false,
+		"If true, build the kustomization in the directory instead of reading its manifest files.")
+	flags.StringVar(&loaderOptions.Checksum, "checksum", "",
+		"Expected digest of the manifests fetched from an HTTPS URL or OCI artifact, e.g. sha256:<hex>.")
+	loader := manifestreader.NewManifestLoaderWithOptions(f, loaderOptions)
 	invFactory := inventory.ClusterClientFactory{StatusPolicy: inventory.StatusPolicyNone}
 
 	names := []string{"init", "apply", "destroy", "diff", "preview", "status"}
@@ -78,6 +84,8 @@ func main() {
 		updateHelp(names, subCmd)
 		cmd.AddCommand(subCmd)
 	}
+	// Replaying a journal does not talk to the server.
+	cmd.AddCommand(replay.Command(ioStreams))
 
 	code := cli.Run(cmd)
 	os.Exit(code)
@@ -59,6 +59,10 @@ func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory,
 			fmt.Sprintf("%q, %q and %q.", flagutils.InventoryPolicyStrict, flagutils.InventoryPolicyAdopt, flagutils.InventoryPolicyForceAdopt))
 	cmd.Flags().DurationVar(&r.timeout, "timeout", 0,
 		"How long to wait before exiting")
+	cmd.Flags().BoolVar(&r.diff, "diff", false,
+		"If true, print the field changes of each object, computed with a server-side dry-run apply.")
+	cmd.Flags().BoolVar(&r.validateSchema, "validate-schema", false,
+		"If true, validate the resources against the OpenAPI schema of the cluster before previewing them.")
 
 	r.Command = cmd
 	return r
@@ -82,6 +86,8 @@ type Runner struct {
 	output            string
 	inventoryPolicy   string
 	timeout           time.Duration
+	diff              bool
+	validateSchema    bool
 }
 
 // RunE is the function run from the cobra command.
@@ -150,10 +156,12 @@ func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
 		// to keep track of progress and any issues.
 		ch = a.Run(ctx, inv, objs, apply.ApplierOptions{
 			EmitStatusEvents:  false,
+			EmitDiffEvents:    r.diff,
 			NoPrune:           noPrune,
 			DryRunStrategy:    drs,
 			ServerSideOptions: r.serverSideOptions,
 			InventoryPolicy:   inventoryPolicy,
+			ValidateSchema:    r.validateSchema,
 		})
 	} else {
 		d, err := apply.NewDestroyerBuilder().
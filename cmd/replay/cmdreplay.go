@@ -0,0 +1,72 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package replay
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/pkg/printers"
+	"sigs.k8s.io/cli-utils/pkg/printers/journal"
+)
+
+// GetRunner creates and returns the Runner which stores the cobra command.
+func GetRunner(ioStreams genericclioptions.IOStreams) *Runner {
+	r := &Runner{
+		ioStreams: ioStreams,
+	}
+	cmd := &cobra.Command{
+		Use:                   "replay JOURNAL",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Print the events of a run written with --journal"),
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  r.RunE,
+	}
+
+	cmd.Flags().StringVar(&r.output, "output", printers.DefaultPrinter(),
+		fmt.Sprintf("Output format, must be one of %s", strings.Join(printers.SupportedPrinters(), ",")))
+	cmd.Flags().BoolVar(&r.printStatusEvents, "status-events", false,
+		"Print status events (always enabled for table output)")
+
+	r.Command = cmd
+	return r
+}
+
+// Command creates the Runner, returning the cobra command associated with it.
+func Command(ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetRunner(ioStreams).Command
+}
+
+// Runner encapsulates data necessary to run the replay command.
+type Runner struct {
+	Command   *cobra.Command
+	ioStreams genericclioptions.IOStreams
+
+	output            string
+	printStatusEvents bool
+}
+
+func (r *Runner) RunE(_ *cobra.Command, args []string) error {
+	if found := printers.ValidatePrinterType(r.output); !found {
+		return fmt.Errorf("unknown output type %q", r.output)
+	}
+
+	// Always enable status events for the table printer
+	if r.output == printers.TablePrinter {
+		r.printStatusEvents = true
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	printer := printers.GetPrinter(r.output, r.ioStreams)
+	return journal.Replay(f, printer, r.printStatusEvents)
+}
@@ -5,28 +5,44 @@ package apply
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/util/openapi"
 	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
 	"sigs.k8s.io/cli-utils/pkg/apply/cache"
+	"sigs.k8s.io/cli-utils/pkg/apply/checkpoint"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/apply/filter"
 	"sigs.k8s.io/cli-utils/pkg/apply/info"
 	"sigs.k8s.io/cli-utils/pkg/apply/mutator"
+	"sigs.k8s.io/cli-utils/pkg/apply/plan"
+	"sigs.k8s.io/cli-utils/pkg/apply/policy"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
+	"sigs.k8s.io/cli-utils/pkg/apply/report"
+	"sigs.k8s.io/cli-utils/pkg/apply/rollback"
 	"sigs.k8s.io/cli-utils/pkg/apply/solver"
+	"sigs.k8s.io/cli-utils/pkg/apply/task"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/diff"
+	"sigs.k8s.io/cli-utils/pkg/eventrecorder"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
+	"sigs.k8s.io/cli-utils/pkg/metrics"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/graph"
 	"sigs.k8s.io/cli-utils/pkg/object/validation"
 )
 
@@ -43,11 +59,30 @@ import (
 type Applier struct {
 	pruner        *prune.Pruner
 	statusWatcher watcher.StatusWatcher
+	statusPoller  watcher.StatusWatcher
 	invClient     inventory.Client
 	client        dynamic.Interface
 	openAPIGetter discovery.OpenAPISchemaInterface
 	mapper        meta.RESTMapper
 	infoHelper    info.Helper
+	// filters and mutators are run on the objects to apply, after the
+	// built-in ones.
+	filters  []filter.ValidationFilter
+	mutators []mutator.Interface
+	// policyGates decide whether each object is applied.
+	policyGates []policy.Gate
+	// metrics records the metrics of each run, if set.
+	metrics *metrics.Metrics
+	// tracerProvider provides the tracer of the spans of each run, if set.
+	tracerProvider trace.TracerProvider
+	// eventRecorder posts the Events of each run, if set.
+	eventRecorder *eventrecorder.Recorder
+	// reporter saves the report of each run, if set.
+	reporter *report.Reporter
+	// dependencyRules add implicit dependencies between the objects.
+	dependencyRules []graph.Rule
+	// checkpointStore stores the progress of each run, if set.
+	checkpointStore checkpoint.Store
 }
 
 // prepareObjects returns the set of objects to apply and to prune or
@@ -103,10 +138,55 @@ func (a *Applier) prepareObjects(localInv inventory.Info, localObjs object.Unstr
 // resources to become current.
 func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects object.UnstructuredSet, options ApplierOptions) <-chan event.Event {
 	klog.V(4).Infof("apply run for %d objects", len(objects))
+	return a.metrics.Instrument(metrics.ApplyOperation,
+		a.recordEvents(invInfo, metrics.ApplyOperation, options, a.run(ctx, invInfo, objects, options, false)))
+}
+
+// Prune performs only the prune step of Run: the objects in the inventory
+// that are not in the passed set of current objects are pruned, and waited
+// on to be deleted, but the current objects are not applied. This is meant
+// for garbage collection of objects removed from the desired state without
+// changing the live objects. The events are the same as for Run, without
+// the apply events. Current objects already in the inventory are retained
+// in it; new objects are not added. Rollback and AdoptOrphaned are ignored.
+func (a *Applier) Prune(ctx context.Context, invInfo inventory.Info, objects object.UnstructuredSet, options ApplierOptions) <-chan event.Event {
+	klog.V(4).Infof("prune run for %d current objects", len(objects))
+	return a.metrics.Instrument(metrics.PruneOperation,
+		a.recordEvents(invInfo, metrics.PruneOperation, options, a.run(ctx, invInfo, objects, options, true)))
+}
+
+// recordEvents posts the Events of the run and saves its report, unless it
+// is a dry-run.
+func (a *Applier) recordEvents(invInfo inventory.Info, operation string, options ApplierOptions,
+	ch <-chan event.Event) <-chan event.Event {
+	if options.DryRunStrategy.ClientOrServerDryRun() {
+		return ch
+	}
+	return a.reporter.Report(invInfo, operation, a.eventRecorder.Record(invInfo, ch))
+}
+
+// run runs the apply and prune steps, or only the prune step if pruneOnly
+// is true.
+func (a *Applier) run(ctx context.Context, invInfo inventory.Info, objects object.UnstructuredSet,
+	options ApplierOptions, pruneOnly bool) chan event.Event {
 	eventChannel := make(chan event.Event)
 	setDefaults(&options)
 	go func() {
 		defer close(eventChannel)
+		if err := common.ValidateFieldManager(options.ServerSideOptions.FieldManager); err != nil {
+			handleError(eventChannel, err)
+			return
+		}
+		// Generate the namespaces of namespaced objects that are neither
+		// applied nor in the cluster.
+		if options.CreateNamespaces && !pruneOnly {
+			namespaces, err := a.missingNamespaces(ctx, invInfo, objects)
+			if err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+			objects = append(append(object.UnstructuredSet{}, objects...), namespaces...)
+		}
 		// Validate the resources to make sure we catch those problems early
 		// before anything has been updated in the cluster.
 		vCollector := &validation.Collector{}
@@ -115,6 +195,12 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 			Mapper:    a.mapper,
 		}
 		validator.Validate(objects)
+		if options.ValidateSchema {
+			if err := a.validateSchema(vCollector.FilterInvalidObjects(objects), vCollector); err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+		}
 
 		// Decide which objects to apply and which to prune
 		applyObjs, pruneObjs, err := a.prepareObjects(invInfo, objects, options)
@@ -123,27 +209,58 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 			return
 		}
 		klog.V(4).Infof("calculated %d apply objs; %d prune objs", len(applyObjs), len(pruneObjs))
+		var currentObjs object.UnstructuredSet
+		if pruneOnly {
+			currentObjs, applyObjs = applyObjs, nil
+			options.NoPrune = false
+			options.Rollback = false
+			options.AdoptOrphaned = false
+		}
+		// Only actuate the selected objects. The excluded objects are
+		// retained in the inventory, as if their apply or prune was skipped.
+		var excludedPruneObjs object.UnstructuredSet
+		if options.Selector != nil {
+			var excludedObjs object.UnstructuredSet
+			applyObjs, excludedObjs = options.Selector.split(applyObjs)
+			currentObjs = append(currentObjs, excludedObjs...)
+			pruneObjs, excludedPruneObjs = options.Selector.split(pruneObjs)
+			klog.V(4).Infof("selected %d apply objs; %d prune objs", len(applyObjs), len(pruneObjs))
+		}
 
 		// Build a TaskContext for passing info between tasks
 		resourceCache := cache.NewResourceCacheMap()
 		taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+		if a.tracerProvider != nil {
+			taskContext.SetTracer(a.tracerProvider.Tracer(taskrunner.TracerName))
+		}
 
 		// Fetch the queue (channel) of tasks that should be executed.
 		klog.V(4).Infoln("applier building task queue...")
+		// The result of a dry-run adoption, set before the objects are
+		// applied.
+		dryRunAdoption := &inventory.AdoptionResult{}
 		// Build list of apply validation filters.
 		applyFilters := []filter.ValidationFilter{
 			filter.InventoryPolicyApplyFilter{
-				Client:    a.client,
-				Mapper:    a.mapper,
-				Inv:       invInfo,
-				InvPolicy: options.InventoryPolicy,
+				Client:         a.client,
+				Mapper:         a.mapper,
+				Inv:            invInfo,
+				InvPolicy:      options.InventoryPolicy,
+				DryRunAdoption: dryRunAdoption,
+			},
+			filter.PatchIfExistsFilter{
+				Client: a.client,
+				Mapper: a.mapper,
 			},
 			filter.DependencyFilter{
 				TaskContext:       taskContext,
 				ActuationStrategy: actuation.ActuationStrategyApply,
 				DryRunStrategy:    options.DryRunStrategy,
+				ContinueOnError:   options.ContinueOnError,
 			},
 		}
+		// Registered filters run after the built-in ones.
+		applyFilters = append(applyFilters, a.filters...)
 		// Build list of prune validation filters.
 		pruneFilters := []filter.ValidationFilter{
 			filter.PreventRemoveFilter{},
@@ -158,6 +275,7 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 				TaskContext:       taskContext,
 				ActuationStrategy: actuation.ActuationStrategyDelete,
 				DryRunStrategy:    options.DryRunStrategy,
+				ContinueOnError:   options.ContinueOnError,
 			},
 		}
 		// Build list of apply mutators.
@@ -168,6 +286,8 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 				ResourceCache: resourceCache,
 			},
 		}
+		// Registered mutators run after the built-in ones.
+		applyMutators = append(applyMutators, a.mutators...)
 		taskBuilder := &solver.TaskQueueBuilder{
 			Pruner:        a.pruner,
 			DynamicClient: a.client,
@@ -178,17 +298,46 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 			Collector:     vCollector,
 			ApplyFilters:  applyFilters,
 			ApplyMutators: applyMutators,
+			PolicyGates:   a.policyGates,
 			PruneFilters:  pruneFilters,
+			Metrics:       a.metrics,
+
+			DependencyRules: a.dependencyRules,
 		}
 		opts := solver.Options{
-			ServerSideOptions:      options.ServerSideOptions,
-			ReconcileTimeout:       options.ReconcileTimeout,
-			Destroy:                false,
-			Prune:                  !options.NoPrune,
-			DryRunStrategy:         options.DryRunStrategy,
-			PrunePropagationPolicy: options.PrunePropagationPolicy,
-			PruneTimeout:           options.PruneTimeout,
-			InventoryPolicy:        options.InventoryPolicy,
+			ServerSideOptions:       options.ServerSideOptions,
+			ReconcileTimeout:        options.ReconcileTimeout,
+			Destroy:                 false,
+			Prune:                   !options.NoPrune,
+			DryRunStrategy:          options.DryRunStrategy,
+			PrunePropagationPolicy:  options.PrunePropagationPolicy,
+			PruneTimeout:            options.PruneTimeout,
+			PruneOrdering:           options.PruneOrdering,
+			PruneConcurrency:        options.PruneConcurrency,
+			InventoryPolicy:         options.InventoryPolicy,
+			RetryPolicy:             options.RetryPolicy,
+			EmitDiffEvents:          options.EmitDiffEvents,
+			EmitTraceEvents:         options.EmitTraceEvents,
+			Concurrency:             options.Concurrency,
+			Throttle:                task.NewThrottle(options.QPS, options.Burst),
+			UpgradeClientSideApply:  options.UpgradeClientSideApply,
+			ClientSideApplyFallback: options.ClientSideApplyFallback,
+
+			WaitForExternalDependencies: options.WaitForExternalDependencies,
+			ExternalDependencyCondition: options.ExternalDependencyCondition,
+			ExternalDependencyTimeout:   options.ExternalDependencyTimeout,
+			SatisfiedDependencies:       options.SatisfiedDependencies,
+		}
+		// The inventory before the run, to remove the rolled back objects
+		// that were added by the run.
+		var rollbackPrevIds object.ObjMetadataSet
+		if options.Rollback && !options.DryRunStrategy.ClientOrServerDryRun() {
+			opts.Journal = rollback.NewJournal()
+			rollbackPrevIds, err = a.invClient.GetClusterObjs(invInfo)
+			if err != nil {
+				handleError(eventChannel, err)
+				return
+			}
 		}
 
 		// Build the ordered set of tasks to execute.
@@ -198,6 +347,15 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 			WithInventory(invInfo).
 			Build(taskContext, opts)
 
+		// Retain the current objects in the inventory, as if their apply was
+		// skipped.
+		for _, id := range vCollector.FilterInvalidIds(object.UnstructuredSetToObjMetadataSet(currentObjs)) {
+			taskContext.InventoryManager().AddSkippedApply(id)
+		}
+		for _, id := range object.UnstructuredSetToObjMetadataSet(excludedPruneObjs) {
+			taskContext.InventoryManager().AddSkippedDelete(id)
+		}
+
 		klog.V(4).Infof("validation errors: %d", len(vCollector.Errors))
 		klog.V(4).Infof("invalid objects: %d", len(vCollector.InvalidIds))
 
@@ -218,11 +376,39 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 			return
 		}
 
+		// When executing a plan, make sure the actions still match it.
+		if options.Plan != nil {
+			if err := options.Plan.Verify(invInfo, taskQueue.ToActionGroups()); err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+		}
+
 		// Register invalid objects to be retained in the inventory, if present.
 		for _, id := range vCollector.InvalidIds {
 			taskContext.AddInvalidObject(id)
 		}
 
+		// Record the progress of the run, and resume a previous run of the
+		// same objects that was interrupted.
+		var fingerprint string
+		var completedTasks []string
+		checkpointing := a.checkpointStore != nil && !options.DryRunStrategy.ClientOrServerDryRun()
+		if checkpointing {
+			fingerprint, err = checkpoint.Fingerprint(taskQueue.ToActionGroups(), applyObjs)
+			if err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+			if options.Resume {
+				completedTasks, err = a.resume(ctx, invInfo, fingerprint, taskQueue, taskContext)
+				if err != nil {
+					handleError(eventChannel, err)
+					return
+				}
+			}
+		}
+
 		// Send event to inform the caller about the resources that
 		// will be applied/pruned.
 		eventChannel <- event.Event{
@@ -231,19 +417,104 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 				ActionGroups: taskQueue.ToActionGroups(),
 			},
 		}
+		for _, te := range taskQueue.TraceEvents() {
+			eventChannel <- event.Event{
+				Type:       event.TraceType,
+				TraceEvent: te,
+			}
+		}
+		if options.EmitPlanSummary {
+			eventChannel <- event.Event{
+				Type: event.PlanSummaryType,
+				PlanSummaryEvent: a.planSummary(ctx, vCollector.FilterInvalidObjects(applyObjs),
+					vCollector.FilterInvalidObjects(pruneObjs), options.ServerSideOptions.FieldManager),
+			}
+		}
+		// Claim live objects without an owning inventory, so the inventory
+		// policy allows them to be applied.
+		if options.AdoptOrphaned {
+			adopter := &inventory.Adopter{
+				Client: a.client,
+				Mapper: a.mapper,
+			}
+			adoptIds := vCollector.FilterInvalidIds(object.UnstructuredSetToObjMetadataSet(applyObjs))
+			result, err := adopter.Adopt(ctx, invInfo, adoptIds, options.DryRunStrategy)
+			if err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+			if options.DryRunStrategy.ClientOrServerDryRun() {
+				*dryRunAdoption = *result
+			}
+			for _, id := range result.Adopted {
+				eventChannel <- event.Event{
+					Type: event.AdoptType,
+					AdoptEvent: event.AdoptEvent{
+						Identifier: id,
+					},
+				}
+			}
+			for _, id := range result.Conflicts {
+				eventChannel <- event.Event{
+					Type: event.AdoptType,
+					AdoptEvent: event.AdoptEvent{
+						Identifier: id,
+						Error: &inventory.PolicyPreventedActuationError{
+							Strategy: actuation.ActuationStrategyApply,
+							Policy:   inventory.PolicyAdoptIfNoInventory,
+							Status:   inventory.NoMatch,
+						},
+					},
+				}
+			}
+		}
 		// Create a new TaskStatusRunner to execute the taskQueue.
 		klog.V(4).Infoln("applier building TaskStatusRunner...")
 		allIds := object.UnstructuredSetToObjMetadataSet(append(applyObjs, pruneObjs...))
+		// Watch the external dependencies, so they can be waited for.
+		allIds = allIds.Union(taskContext.Graph().AllExternalDependencies())
 		statusWatcher := a.statusWatcher
+		if options.StatusStrategy == watcher.PollStrategy && a.statusPoller != nil {
+			statusWatcher = a.statusPoller
+		}
 		// Disable watcher for dry runs
 		if opts.DryRunStrategy.ClientOrServerDryRun() {
 			statusWatcher = watcher.BlindStatusWatcher{}
 		}
 		runner := taskrunner.NewTaskStatusRunner(allIds, statusWatcher)
 		klog.V(4).Infoln("applier running TaskStatusRunner...")
-		err = runner.Run(ctx, taskContext, taskQueue.ToChannel(), taskrunner.Options{
+		runnerOpts := taskrunner.Options{
 			EmitStatusEvents: options.EmitStatusEvents,
-		})
+		}
+		if checkpointing {
+			runnerOpts.OnTaskCompleted = func(t taskrunner.Task) {
+				if _, ok := t.(*taskrunner.ExternalWaitTask); ok {
+					// Waited for again when resuming, see SkipCompleted.
+					return
+				}
+				completedTasks = append(completedTasks, t.Name())
+				cp := &checkpoint.Checkpoint{
+					Fingerprint:    fingerprint,
+					CompletedTasks: completedTasks,
+					Objects:        taskContext.InventoryManager().Inventory().Status.Objects,
+				}
+				if err := a.checkpointStore.Save(ctx, invInfo, cp); err != nil {
+					klog.Warningf("failed to save checkpoint after task %q: %v", t.Name(), err)
+				}
+			}
+		}
+		err = runner.Run(ctx, taskContext, taskQueue.ToChannel(), runnerOpts)
+		if checkpointing && err == nil {
+			if err := a.checkpointStore.Delete(ctx, invInfo); err != nil {
+				klog.Warningf("failed to delete checkpoint: %v", err)
+			}
+		}
+		if opts.Journal != nil && (err != nil || runFailed(taskContext.InventoryManager().Inventory())) {
+			status := taskContext.InventoryManager().Inventory().Status.Objects
+			if rbErr := a.rollback(invInfo, rollbackPrevIds, status, opts.Journal, eventChannel); rbErr != nil && err == nil {
+				err = rbErr
+			}
+		}
 		if err != nil {
 			handleError(eventChannel, err)
 			return
@@ -265,6 +536,33 @@ type ApplierOptions struct {
 	// emitted on the eventChannel to the caller.
 	EmitStatusEvents bool
 
+	// StatusStrategy defines how the status of objects is watched. By
+	// default, objects are watched, with a fallback to polling if watching
+	// is forbidden. Ignored if a StatusWatcher was provided to the builder.
+	StatusStrategy watcher.Strategy
+
+	// EmitDiffEvents defines whether a diff event should be emitted on
+	// the eventChannel for each object before it is applied. Computing
+	// the diff requires a server-side dry-run apply of every object.
+	EmitDiffEvents bool
+
+	// EmitPlanSummary defines whether a plan summary event should be
+	// emitted after the init event, with the objects the run would create,
+	// configure, leave unchanged and prune. Computing the summary requires
+	// a server-side dry-run apply of every object.
+	EmitPlanSummary bool
+
+	// EmitTraceEvents defines whether a trace event should be emitted for
+	// each object after the init event, explaining why the object was
+	// assigned to its task group.
+	EmitTraceEvents bool
+
+	// Concurrency is the maximum number of objects applied at the same
+	// time within a task group. The events of each object are still sent
+	// in order, but the events of different objects may be interleaved.
+	// Defaults to applying one object at a time.
+	Concurrency int
+
 	// NoPrune defines whether pruning of previously applied
 	// objects should happen after apply.
 	NoPrune bool
@@ -283,11 +581,249 @@ type ApplierOptions struct {
 	// wait.
 	PruneTimeout time.Duration
 
+	// PruneOrdering defines the order in which prune objects are deleted.
+	// Defaults to prune.OrderingReverseApply, the reverse of the apply
+	// order.
+	PruneOrdering prune.Ordering
+
+	// PruneConcurrency is the maximum number of objects deleted at the same
+	// time by each prune task. Objects are deleted one by one if it is less
+	// than 2.
+	PruneConcurrency int
+
 	// InventoryPolicy defines the inventory policy of apply.
 	InventoryPolicy inventory.Policy
 
+	// AdoptOrphaned defines whether live objects without an owning
+	// inventory, e.g. created with kubectl apply, should be claimed by the
+	// inventory before they are applied, regardless of the InventoryPolicy.
+	// An AdoptEvent is sent for each claimed object, and an AdoptEvent with
+	// an error for each object owned by another inventory. Dry-runs check
+	// the InventoryPolicy as if the objects were claimed.
+	AdoptOrphaned bool
+
 	// ValidationPolicy defines how to handle invalid objects.
 	ValidationPolicy validation.Policy
+
+	// ContinueOnError defines whether to keep applying objects whose
+	// dependencies failed, instead of skipping them. Failures are still
+	// reported in the events for each object and in the final stats.
+	ContinueOnError bool
+
+	// RetryPolicy defines how apply and prune operations that failed with a
+	// transient error are retried. If nil, operations are not retried.
+	RetryPolicy *task.RetryPolicy
+
+	// QPS is the maximum number of apply and delete requests per second,
+	// in addition to any throttling by the REST client. The rate is
+	// reduced while the server responds with 429 Too Many Requests, e.g.
+	// when API Priority and Fairness rejects requests, and restored
+	// afterwards. Zero disables throttling.
+	QPS float32
+
+	// Burst is the maximum number of requests sent at once when throttled
+	// by QPS. Defaults to 1.
+	Burst int
+
+	// UpgradeClientSideApply defines whether the ownership of fields that
+	// were managed with client-side apply, e.g. by kubectl apply, is
+	// transferred to the server-side apply field manager before each
+	// object is applied. This avoids conflicts, and fields removed from the
+	// manifest not being removed, when switching to server-side apply.
+	// Ignored unless ServerSideOptions.ServerSideApply is true.
+	UpgradeClientSideApply bool
+
+	// ClientSideApplyFallback defines whether objects that fail to apply
+	// with server-side apply, because the API server can't compute a
+	// server-side apply patch, e.g. for custom resources whose CRD has a
+	// schema that is not structural, are applied again with client-side
+	// apply. Other errors, e.g. conflicts, never fall back. Objects can
+	// also be applied client-side with the
+	// config.kubernetes.io/apply-strategy annotation.
+	// Ignored unless ServerSideOptions.ServerSideApply is true.
+	ClientSideApplyFallback bool
+
+	// Plan is a plan generated by a Planner. If set, the run fails with a
+	// plan.StaleError if the actions to perform differ from the plan.
+	Plan *plan.Plan
+
+	// Rollback defines whether the objects applied by the run are reverted
+	// if the run fails, is cancelled, or any object fails to be applied,
+	// pruned or reconciled. Objects created by the run are deleted and
+	// updated objects are restored to their state before the run. Pruned
+	// objects are not restored. Ignored for dry-runs.
+	Rollback bool
+
+	// Resume defines whether the run resumes a previous run of the same
+	// objects that was interrupted, e.g. by a restart of the
+	// process, from the checkpoint saved after each completed task. Tasks
+	// that completed are not run again; the apply task and wait task that
+	// were running are. Events are only sent for the tasks that run.
+	// Requires a checkpoint store, see ApplierBuilder.WithCheckpointStore.
+	// Ignored if there is no checkpoint, or it is of a different run.
+	Resume bool
+
+	// CreateNamespaces defines whether a Namespace object is generated and
+	// applied for each namespace of the namespaced objects that is neither
+	// in the objects nor in the cluster. Like other namespaces, they are
+	// applied before the objects in them. Generated namespaces are added to
+	// the inventory and annotated with common.NamespaceCreatedAnnotation, so
+	// they are generated again by later runs instead of being pruned.
+	// See also DestroyerOptions.DeleteEmptyNamespacesOnly.
+	CreateNamespaces bool
+
+	// ValidateSchema defines whether the objects are validated against the
+	// OpenAPI schema of the cluster before any object is applied. Unknown
+	// fields and fields with the wrong type are reported as validation
+	// errors and handled according to the ValidationPolicy.
+	ValidateSchema bool
+
+	// WaitForExternalDependencies allows depends-on annotations to reference
+	// objects that are not managed by the inventory, e.g. operators
+	// installed by another team, instead of being invalid. Before any object
+	// is applied, the external dependencies are waited for until they meet
+	// the ExternalDependencyCondition, or the ExternalDependencyTimeout
+	// expires. Objects whose external dependencies are not ready are
+	// skipped, unless ContinueOnError is set. Ignored for dry-runs.
+	WaitForExternalDependencies bool
+
+	// ExternalDependencyCondition is the condition the external dependencies
+	// are waited for: taskrunner.AllCurrent (default), or taskrunner.AllExist
+	// to only wait until they exist.
+	ExternalDependencyCondition taskrunner.Condition
+
+	// ExternalDependencyTimeout defines how long to wait for the external
+	// dependencies. Zero means no timeout.
+	ExternalDependencyTimeout time.Duration
+
+	// Selector restricts the objects that are applied and pruned, e.g. for
+	// partial rollouts of a package. The objects that are not selected are
+	// neither applied nor pruned, and are retained in the inventory if they
+	// are in it already, so they are not pruned by later runs either.
+	// Dependencies of selected objects on excluded objects are treated like
+	// dependencies outside the inventory, see WaitForExternalDependencies.
+	// If nil, all objects are selected.
+	Selector *Selector
+
+	// SatisfiedDependencies are objects outside the applied set that the
+	// depends-on annotations of the objects may reference, because they
+	// are known to be reconciled, e.g. objects applied to another cluster
+	// by the MultiClusterApplier. They are neither waited for nor invalid.
+	SatisfiedDependencies object.ObjMetadataSet
+}
+
+// resume restores the progress of the previous run from its checkpoint, if
+// the previous run had the same fingerprint: the completed tasks are removed
+// from the queue and the statuses of the objects are restored, so the wait
+// tasks that did not complete wait for the objects applied before the
+// interruption. The names of the completed tasks are returned.
+func (a *Applier) resume(ctx context.Context, invInfo inventory.Info, fingerprint string,
+	taskQueue *solver.TaskQueue, taskContext *taskrunner.TaskContext) ([]string, error) {
+	cp, err := a.checkpointStore.Load(ctx, invInfo)
+	if err != nil {
+		return nil, err
+	}
+	if cp == nil || cp.Fingerprint != fingerprint {
+		klog.V(4).Infoln("no checkpoint of the run to resume")
+		return nil, nil
+	}
+	if err := taskQueue.SkipCompleted(cp.CompletedTasks); err != nil {
+		return nil, fmt.Errorf("failed to resume from checkpoint: %w", err)
+	}
+	klog.V(4).Infof("resuming run after %d completed tasks", len(cp.CompletedTasks))
+	for _, objStatus := range cp.Objects {
+		taskContext.InventoryManager().SetObjectStatus(objStatus)
+	}
+	return cp.CompletedTasks, nil
+}
+
+// validateSchema validates the objects against the OpenAPI schema of the
+// cluster, adding the errors to the collector.
+func (a *Applier) validateSchema(objs object.UnstructuredSet, vCollector *validation.Collector) error {
+	if a.openAPIGetter == nil {
+		return errors.New("schema validation requires an OpenAPI client")
+	}
+	resources, err := openapi.NewOpenAPIParser(a.openAPIGetter).Parse()
+	if err != nil {
+		return fmt.Errorf("failed to fetch OpenAPI schema: %w", err)
+	}
+	validator := &validation.SchemaValidator{
+		Resources: resources,
+		Collector: vCollector,
+	}
+	validator.Validate(objs)
+	return nil
+}
+
+// rollback reverts the objects recorded in the journal, in the reverse order
+// they were applied, and sends a RollbackEvent for each object. The objects
+// that were rolled back and were not in the inventory before the run, prevIds,
+// are then removed from the inventory, since the run already stored them.
+func (a *Applier) rollback(invInfo inventory.Info, prevIds object.ObjMetadataSet, status []actuation.ObjectStatus,
+	journal *rollback.Journal, eventChannel chan<- event.Event) error {
+	// The context of the run may have been cancelled, which must not
+	// prevent the rollback.
+	ctx := context.Background()
+	rollbacker := &rollback.Rollbacker{
+		Client: a.client,
+		Mapper: a.mapper,
+	}
+	entries := journal.Entries()
+	klog.V(4).Infof("applier rolling back %d objects", len(entries))
+	var rolledBack object.ObjMetadataSet
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		re := event.RollbackEvent{
+			Identifier: entry.Identifier,
+			Operation:  event.RollbackRevert,
+			Status:     event.RollbackSuccessful,
+		}
+		if entry.Created() {
+			re.Operation = event.RollbackDelete
+		}
+		if err := rollbacker.Rollback(ctx, entry); err != nil {
+			re.Status = event.RollbackFailed
+			re.Error = err
+		} else {
+			rolledBack = append(rolledBack, entry.Identifier)
+		}
+		eventChannel <- event.Event{
+			Type:          event.RollbackType,
+			RollbackEvent: re,
+		}
+	}
+
+	removed := rolledBack.Diff(prevIds)
+	if len(removed) == 0 {
+		return nil
+	}
+	ids, err := a.invClient.GetClusterObjs(invInfo)
+	if err != nil {
+		return fmt.Errorf("failed to remove rolled back objects from inventory: %w", err)
+	}
+	var kept []actuation.ObjectStatus
+	for _, s := range status {
+		if !removed.Contains(inventory.ObjMetadataFromObjectReference(s.ObjectReference)) {
+			kept = append(kept, s)
+		}
+	}
+	klog.V(4).Infof("applier removing %d rolled back objects from inventory", len(removed))
+	if err := a.invClient.Replace(invInfo, ids.Diff(removed), kept, common.DryRunNone); err != nil {
+		return fmt.Errorf("failed to remove rolled back objects from inventory: %w", err)
+	}
+	return nil
+}
+
+// runFailed returns true if any object failed to be actuated or reconciled.
+func runFailed(inv *actuation.Inventory) bool {
+	for _, s := range inv.Status.Objects {
+		if s.Actuation == actuation.ActuationFailed ||
+			s.Reconcile == actuation.ReconcileFailed ||
+			s.Reconcile == actuation.ReconcileTimeout {
+			return true
+		}
+	}
+	return false
 }
 
 // setDefaults set the options to the default values if they
@@ -307,6 +843,60 @@ func handleError(eventChannel chan event.Event, err error) {
 	}
 }
 
+var (
+	namespaceGK  = schema.GroupKind{Group: "", Kind: "Namespace"}
+	namespaceGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+)
+
+// missingNamespaces returns a Namespace object for each namespace of the
+// namespaced objects that is not in the objects, and either does not exist
+// in the cluster, or was generated by a previous run and is still in the
+// inventory, so it is not pruned. The namespaces are annotated with
+// common.NamespaceCreatedAnnotation.
+func (a *Applier) missingNamespaces(ctx context.Context, invInfo inventory.Info,
+	objs object.UnstructuredSet) (object.UnstructuredSet, error) {
+	applied := sets.NewString()
+	needed := sets.NewString()
+	for _, obj := range objs {
+		id := object.UnstructuredToObjMetadata(obj)
+		if id.GroupKind == namespaceGK {
+			applied.Insert(id.Name)
+		} else if id.Namespace != "" {
+			needed.Insert(id.Namespace)
+		}
+	}
+	missing := needed.Difference(applied)
+	if missing.Len() == 0 {
+		return nil, nil
+	}
+	invIDs, err := a.invClient.GetClusterObjs(invInfo)
+	if err != nil {
+		return nil, err
+	}
+	var namespaces object.UnstructuredSet
+	for _, name := range missing.List() {
+		if !invIDs.Contains(object.ObjMetadata{Name: name, GroupKind: namespaceGK}) {
+			_, err := a.client.Resource(namespaceGVR).Get(ctx, name, metav1.GetOptions{})
+			if err == nil {
+				continue
+			}
+			if !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get namespace %q: %w", name, err)
+			}
+		}
+		klog.V(4).Infof("generating namespace %q", name)
+		ns := &unstructured.Unstructured{}
+		ns.SetAPIVersion("v1")
+		ns.SetKind("Namespace")
+		ns.SetName(name)
+		ns.SetAnnotations(map[string]string{
+			common.NamespaceCreatedAnnotation: common.NamespaceCreated,
+		})
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces, nil
+}
+
 // localNamespaces stores a set of strings of all the namespaces
 // for the passed non cluster-scoped localObjs, plus the namespace
 // of the passed inventory object. This is used to skip deleting
@@ -346,3 +936,39 @@ func handleValidationError(eventChannel chan<- event.Event, err error) {
 		}
 	}
 }
+
+// planSummary returns the summary of the changes of applying and pruning the
+// objects, computed with a server-side dry-run apply of each applied object.
+func (a *Applier) planSummary(ctx context.Context, applyObjs, pruneObjs object.UnstructuredSet,
+	fieldManager string) event.PlanSummaryEvent {
+	if fieldManager == "" {
+		fieldManager = common.DefaultFieldManager
+	}
+	differ := &diff.Differ{
+		Client:       a.client,
+		Mapper:       a.mapper,
+		FieldManager: fieldManager,
+	}
+	summary := event.PlanSummaryEvent{
+		Prune: object.UnstructuredSetToObjMetadataSet(pruneObjs),
+	}
+	for _, obj := range applyObjs {
+		id := object.UnstructuredToObjMetadata(obj)
+		live, diffs, err := differ.DiffLive(ctx, obj)
+		switch {
+		case err != nil:
+			klog.V(4).Infof("plan summary dry-run failed (object: %s): %v", id, err)
+			if summary.Errors == nil {
+				summary.Errors = make(map[object.ObjMetadata]error)
+			}
+			summary.Errors[id] = err
+		case live == nil:
+			summary.Create = append(summary.Create, id)
+		case len(diffs) == 0:
+			summary.Unchanged = append(summary.Unchanged, id)
+		default:
+			summary.Configure = append(summary.Configure, id)
+		}
+	}
+	return summary
+}
@@ -4,20 +4,40 @@
 package apply
 
 import (
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/apply/checkpoint"
+	"sigs.k8s.io/cli-utils/pkg/apply/filter"
 	"sigs.k8s.io/cli-utils/pkg/apply/info"
+	"sigs.k8s.io/cli-utils/pkg/apply/mutator"
+	"sigs.k8s.io/cli-utils/pkg/apply/policy"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
+	"sigs.k8s.io/cli-utils/pkg/apply/report"
+	"sigs.k8s.io/cli-utils/pkg/eventrecorder"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
+	"sigs.k8s.io/cli-utils/pkg/metrics"
+	"sigs.k8s.io/cli-utils/pkg/object/graph"
 )
 
+// ApplierBuilder builds an Applier. The clients are either provided
+// explicitly, or retrieved from a factory with WithFactory. Without a
+// factory, the clients that are not provided explicitly are created from
+// the rest config provided with WithRestConfig, so controllers can embed
+// the Applier without kubectl plumbing.
 type ApplierBuilder struct {
 	commonBuilder
+	filters     []filter.ValidationFilter
+	mutators    []mutator.Interface
+	policyGates []policy.Gate
+	// checkpointStore is only set if provided explicitly.
+	checkpointStore checkpoint.Store
 }
 
 // NewApplierBuilder returns a new ApplierBuilder.
@@ -37,13 +57,24 @@ func (b *ApplierBuilder) Build() (*Applier, error) {
 			InvClient: bx.invClient,
 			Client:    bx.client,
 			Mapper:    bx.mapper,
+			Metrics:   bx.metrics,
 		},
-		statusWatcher: bx.statusWatcher,
-		invClient:     bx.invClient,
-		client:        bx.client,
-		openAPIGetter: bx.discoClient,
-		mapper:        bx.mapper,
-		infoHelper:    info.NewHelper(bx.mapper, bx.unstructuredClientForMapping),
+		statusWatcher:   bx.statusWatcher,
+		statusPoller:    bx.statusPoller,
+		invClient:       bx.invClient,
+		client:          bx.client,
+		openAPIGetter:   bx.discoClient,
+		mapper:          bx.mapper,
+		infoHelper:      info.NewHelper(bx.mapper, bx.unstructuredClientForMapping),
+		metrics:         bx.metrics,
+		tracerProvider:  bx.tracerProvider,
+		eventRecorder:   bx.newEventRecorder(),
+		reporter:        bx.newReporter(),
+		dependencyRules: bx.dependencyRules,
+		filters:         b.filters,
+		mutators:        b.mutators,
+		policyGates:     b.policyGates,
+		checkpointStore: b.checkpointStore,
 	}, nil
 }
 
@@ -86,3 +117,88 @@ func (b *ApplierBuilder) WithStatusWatcher(statusWatcher watcher.StatusWatcher)
 	b.statusWatcher = statusWatcher
 	return b
 }
+
+// WithFilter registers a filter that is run on every object before it is
+// applied. Objects the filter returns an error for are skipped, unless the
+// error is a filter.FatalError, which fails the apply of the object.
+// Registered filters run after the built-in inventory policy and dependency
+// filters, in the order they were registered.
+func (b *ApplierBuilder) WithFilter(f filter.ValidationFilter) *ApplierBuilder {
+	b.filters = append(b.filters, f)
+	return b
+}
+
+// WithMutator registers a mutator that is run on every object before it is
+// applied, e.g. to inject labels. Registered mutators run after the built-in
+// apply-time mutation, in the order they were registered, and only on
+// objects that passed all filters.
+func (b *ApplierBuilder) WithMutator(m mutator.Interface) *ApplierBuilder {
+	b.mutators = append(b.mutators, m)
+	return b
+}
+
+// WithPolicyGate registers a policy gate that decides whether each object is
+// applied. Gates are evaluated in the order they were registered, on the
+// objects that passed all filters, after all mutators. The first gate that
+// skips or fails an object decides its outcome, reported with the reason in
+// the object's apply event.
+func (b *ApplierBuilder) WithPolicyGate(g policy.Gate) *ApplierBuilder {
+	b.policyGates = append(b.policyGates, g)
+	return b
+}
+
+// WithMetrics enables recording the metrics of each run, e.g. the duration
+// of the run and of each apply, on the passed Metrics. The polling status
+// watcher also records the duration of each poll, unless a status watcher
+// was provided.
+func (b *ApplierBuilder) WithMetrics(m *metrics.Metrics) *ApplierBuilder {
+	b.metrics = m
+	return b
+}
+
+// WithTracerProvider sets the TracerProvider of the OpenTelemetry spans of
+// each run, its tasks and the actuation of each object. Spans are children
+// of the span in the context passed to Run, if any. Defaults to the global
+// TracerProvider.
+func (b *ApplierBuilder) WithTracerProvider(tp trace.TracerProvider) *ApplierBuilder {
+	b.tracerProvider = tp
+	return b
+}
+
+// WithCheckpointStore enables saving the progress of each run to the store
+// after each task, so a run interrupted e.g. by a restart of the process can
+// be resumed with ApplierOptions.Resume. The checkpoint is deleted when the
+// run completes. Checkpoints are not saved for dry-runs.
+func (b *ApplierBuilder) WithCheckpointStore(s checkpoint.Store) *ApplierBuilder {
+	b.checkpointStore = s
+	return b
+}
+
+// WithEventRecorder enables posting a Kubernetes Event for each apply and prune result
+// of a run, except for dry-runs, either on the actuated objects or on the
+// inventory object, depending on the target. Events of successes are of type
+// Normal, events of failures of type Warning.
+func (b *ApplierBuilder) WithEventRecorder(recorder record.EventRecorder, target eventrecorder.Target) *ApplierBuilder {
+	b.eventRecorder = recorder
+	b.eventTarget = target
+	return b
+}
+
+// WithReportStore enables saving a report of each run to the store when the
+// run completes, with the actuation status, reconcile status, error and
+// durations of each object, e.g. to a report.ConfigMapStore or a
+// report.CustomResourceStore. Reports are not saved for dry-runs.
+func (b *ApplierBuilder) WithReportStore(s report.Store) *ApplierBuilder {
+	b.reportStore = s
+	return b
+}
+
+// WithDependencyRule registers a rule of implicit dependencies between the
+// objects, in addition to the built-in ones like namespaces and CRDs, e.g.
+// graph.ServiceAccountRule. Dependency cycles through the edges of a rule
+// are reported as validation errors naming the rule. Build fails if the
+// rule is invalid, see graph.Rule.Validate.
+func (b *ApplierBuilder) WithDependencyRule(rule graph.Rule) *ApplierBuilder {
+	b.dependencyRules = append(b.dependencyRules, rule)
+	return b
+}
@@ -4,24 +4,48 @@
 package apply
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	openapi_v2 "github.com/google/gnostic/openapiv2"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
 	"k8s.io/kubectl/pkg/scheme"
 	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/apply/checkpoint"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/filter"
+	"sigs.k8s.io/cli-utils/pkg/apply/mutator"
+	"sigs.k8s.io/cli-utils/pkg/apply/policy"
+	"sigs.k8s.io/cli-utils/pkg/apply/report"
+	"sigs.k8s.io/cli-utils/pkg/apply/rollback"
+	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
+	"sigs.k8s.io/cli-utils/pkg/metrics"
 	"sigs.k8s.io/cli-utils/pkg/multierror"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/cli-utils/pkg/object/validation"
+	"sigs.k8s.io/cli-utils/pkg/printers/journal"
 	"sigs.k8s.io/cli-utils/pkg/testutil"
 )
 
@@ -2067,3 +2091,747 @@ func TestReadAndPrepareObjects(t *testing.T) {
 		})
 	}
 }
+
+func TestApplierRollback(t *testing.T) {
+	created := testutil.Unstructured(t, resources["secret"])
+	updated := testutil.Unstructured(t, resources["deployment"])
+	createdID := object.UnstructuredToObjMetadata(created)
+	updatedID := object.UnstructuredToObjMetadata(updated)
+
+	keptID := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Kind: "ConfigMap"},
+		Name:      "kept",
+		Namespace: "default",
+	}
+	invInfo := inventoryInfo{name: "inv-123", namespace: "default", id: "test"}
+
+	journal := rollback.NewJournal()
+	journal.Record(updatedID, updated)
+	journal.Record(createdID, nil)
+
+	// The run added the created object to the inventory.
+	invClient := inventory.NewFakeClient(object.ObjMetadataSet{keptID, updatedID, createdID})
+	status := []actuation.ObjectStatus{
+		{ObjectReference: inventory.ObjectReferenceFromObjMetadata(keptID)},
+		{ObjectReference: inventory.ObjectReferenceFromObjMetadata(updatedID)},
+		{ObjectReference: inventory.ObjectReferenceFromObjMetadata(createdID)},
+	}
+	prevIds := object.ObjMetadataSet{keptID, updatedID}
+
+	applier := &Applier{
+		invClient: invClient,
+		client:    dynamicfake.NewSimpleDynamicClient(scheme.Scheme, created),
+		mapper: testutil.NewFakeRESTMapper(
+			schema.GroupVersionKind{Version: "v1", Kind: "Secret"},
+			schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		),
+	}
+	eventChannel := make(chan event.Event, 2)
+	err := applier.rollback(invInfo.toWrapped(), prevIds, status, journal, eventChannel)
+	require.NoError(t, err)
+	close(eventChannel)
+
+	var events []event.RollbackEvent
+	for e := range eventChannel {
+		require.Equal(t, event.RollbackType, e.Type)
+		events = append(events, e.RollbackEvent)
+	}
+	// Objects are rolled back in reverse order. The deployment no longer
+	// exists, so there is nothing to revert.
+	assert.Equal(t, []event.RollbackEvent{
+		{
+			Identifier: createdID,
+			Operation:  event.RollbackDelete,
+			Status:     event.RollbackSuccessful,
+		},
+		{
+			Identifier: updatedID,
+			Operation:  event.RollbackRevert,
+			Status:     event.RollbackSuccessful,
+		},
+	}, events)
+
+	// The created object is removed from the inventory. The updated object
+	// is kept, since it was in the inventory before the run.
+	testutil.AssertEqual(t, object.ObjMetadataSet{keptID, updatedID}, invClient.Objs)
+	assert.Equal(t, status[:2], invClient.Status)
+}
+
+func TestRunFailed(t *testing.T) {
+	testCases := map[string]struct {
+		status   actuation.ObjectStatus
+		expected bool
+	}{
+		"reconciled": {
+			status: actuation.ObjectStatus{
+				Actuation: actuation.ActuationSucceeded,
+				Reconcile: actuation.ReconcileSucceeded,
+			},
+			expected: false,
+		},
+		"skipped": {
+			status: actuation.ObjectStatus{
+				Actuation: actuation.ActuationSkipped,
+				Reconcile: actuation.ReconcileSkipped,
+			},
+			expected: false,
+		},
+		"apply failed": {
+			status: actuation.ObjectStatus{
+				Actuation: actuation.ActuationFailed,
+				Reconcile: actuation.ReconcileSkipped,
+			},
+			expected: true,
+		},
+		"reconcile timeout": {
+			status: actuation.ObjectStatus{
+				Actuation: actuation.ActuationSucceeded,
+				Reconcile: actuation.ReconcileTimeout,
+			},
+			expected: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			inv := &actuation.Inventory{
+				Status: actuation.InventoryStatus{
+					Objects: []actuation.ObjectStatus{tc.status},
+				},
+			}
+			assert.Equal(t, tc.expected, runFailed(inv))
+		})
+	}
+}
+
+// fakeOpenAPIGetter serves the schema of apps/v1 Deployments with a
+// replicas field.
+type fakeOpenAPIGetter struct{}
+
+func (fakeOpenAPIGetter) OpenAPISchema() (*openapi_v2.Document, error) {
+	return openapi_v2.ParseDocument([]byte(`{
+  "swagger": "2.0",
+  "info": {"title": "test", "version": "v1"},
+  "paths": {},
+  "definitions": {
+    "io.k8s.api.apps.v1.Deployment": {
+      "type": "object",
+      "properties": {
+        "apiVersion": {"type": "string"},
+        "kind": {"type": "string"},
+        "metadata": {"type": "object", "additionalProperties": {}},
+        "spec": {
+          "type": "object",
+          "properties": {
+            "replicas": {"type": "integer"}
+          }
+        }
+      },
+      "x-kubernetes-group-version-kind": [
+        {"group": "apps", "kind": "Deployment", "version": "v1"}
+      ]
+    }
+  }
+}`))
+}
+
+func TestApplierValidateSchema(t *testing.T) {
+	valid := testutil.Unstructured(t, resources["deployment"])
+	invalid := testutil.Unstructured(t, resources["deployment"])
+	invalid.SetName("invalid")
+	require.NoError(t, unstructured.SetNestedField(invalid.Object, "two", "spec", "replicas"))
+	require.NoError(t, unstructured.SetNestedField(invalid.Object, true, "spec", "unknown"))
+
+	vCollector := &validation.Collector{}
+	applier := &Applier{openAPIGetter: fakeOpenAPIGetter{}}
+	err := applier.validateSchema(object.UnstructuredSet{valid, invalid}, vCollector)
+	require.NoError(t, err)
+
+	assert.Equal(t, object.ObjMetadataSet{object.UnstructuredToObjMetadata(invalid)}, vCollector.InvalidIds)
+	require.Len(t, vCollector.Errors, 1)
+	assert.Contains(t, vCollector.Errors[0].Error(), `unknown field "unknown"`)
+	assert.Contains(t, vCollector.Errors[0].Error(), `invalid type`)
+
+	applier = &Applier{}
+	err = applier.validateSchema(object.UnstructuredSet{valid}, &validation.Collector{})
+	assert.Error(t, err)
+}
+
+// secretFilter skips the apply of secrets.
+type secretFilter struct{}
+
+func (secretFilter) Name() string { return "SecretFilter" }
+
+func (secretFilter) Filter(obj *unstructured.Unstructured) error {
+	if obj.GetKind() == "Secret" {
+		return errors.New("secrets are not allowed")
+	}
+	return nil
+}
+
+// labelMutator adds a label to every object and records the mutated objects.
+type labelMutator struct {
+	mu      sync.Mutex
+	mutated []string
+}
+
+func (*labelMutator) Name() string { return "LabelMutator" }
+
+func (m *labelMutator) Mutate(_ context.Context, obj *unstructured.Unstructured) (bool, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mutated = append(m.mutated, obj.GetName())
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels["team"] = "platform"
+	obj.SetLabels(labels)
+	return true, "standard labels", nil
+}
+
+func TestApplierBuilder_FiltersAndMutators(t *testing.T) {
+	f := secretFilter{}
+	m := &labelMutator{}
+	b := NewApplierBuilder().
+		WithFilter(f).
+		WithMutator(m)
+	assert.Equal(t, []filter.ValidationFilter{f}, b.filters)
+	assert.Equal(t, []mutator.Interface{m}, b.mutators)
+
+	invInfo := inventoryInfo{
+		name:      "inv-123",
+		namespace: "default",
+		id:        "test",
+	}
+	deployment := testutil.Unstructured(t, resources["deployment"])
+	secret := testutil.Unstructured(t, resources["secret"])
+	applier := newTestApplier(t, invInfo, object.UnstructuredSet{deployment, secret},
+		object.UnstructuredSet{}, watcher.BlindStatusWatcher{})
+	applier.filters = b.filters
+	applier.mutators = b.mutators
+
+	var applyEvents []event.ApplyEvent
+	// Dry-run to skip waiting for the objects to reconcile.
+	for e := range applier.Run(context.TODO(), invInfo.toWrapped(), object.UnstructuredSet{deployment, secret}, ApplierOptions{
+		InventoryPolicy: inventory.PolicyMustMatch,
+		DryRunStrategy:  common.DryRunClient,
+	}) {
+		require.NotEqual(t, event.ErrorType, e.Type, e.ErrorEvent.Err)
+		if e.Type == event.ApplyType {
+			applyEvents = append(applyEvents, e.ApplyEvent)
+		}
+	}
+
+	require.Len(t, applyEvents, 2)
+	statuses := make(map[string]event.ApplyEventStatus)
+	for _, e := range applyEvents {
+		statuses[e.Identifier.Name] = e.Status
+		if e.Status == event.ApplySkipped {
+			assert.EqualError(t, e.Error, "secrets are not allowed")
+		}
+	}
+	assert.Equal(t, map[string]event.ApplyEventStatus{
+		deployment.GetName(): event.ApplySuccessful,
+		secret.GetName():     event.ApplySkipped,
+	}, statuses)
+	// Mutators only run on objects that passed the filters.
+	assert.Equal(t, []string{deployment.GetName()}, m.mutated)
+}
+
+type allowGate struct{}
+
+func (allowGate) Name() string { return "allow" }
+
+func (allowGate) Evaluate(context.Context, *unstructured.Unstructured) (policy.Decision, string, error) {
+	return policy.Allow, "", nil
+}
+
+func TestApplierBuilder_WithPolicyGate(t *testing.T) {
+	b := NewApplierBuilder().
+		WithPolicyGate(allowGate{}).
+		WithPolicyGate(secretGate{})
+	assert.Equal(t, []policy.Gate{allowGate{}, secretGate{}}, b.policyGates)
+}
+
+// secretGate skips the apply of secrets.
+type secretGate struct{}
+
+func (secretGate) Name() string { return "no-secrets" }
+
+func (secretGate) Evaluate(_ context.Context, obj *unstructured.Unstructured) (policy.Decision, string, error) {
+	if obj.GetKind() == "Secret" {
+		return policy.Skip, "secrets are managed elsewhere", nil
+	}
+	return policy.Allow, "", nil
+}
+
+func TestApplierBuilder_WithMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := metrics.New(reg)
+	require.NoError(t, err)
+	b := NewApplierBuilder().WithMetrics(m)
+	assert.Equal(t, m, b.metrics)
+
+	invInfo := inventoryInfo{
+		name:      "inv-123",
+		namespace: "default",
+		id:        "test",
+	}
+	deployment := testutil.Unstructured(t, resources["deployment"])
+	applier := newTestApplier(t, invInfo, object.UnstructuredSet{deployment},
+		object.UnstructuredSet{}, watcher.BlindStatusWatcher{})
+	applier.metrics = b.metrics
+
+	// Dry-run to skip waiting for the objects to reconcile.
+	for e := range applier.Run(context.TODO(), invInfo.toWrapped(), object.UnstructuredSet{deployment}, ApplierOptions{
+		InventoryPolicy: inventory.PolicyMustMatch,
+		DryRunStrategy:  common.DryRunClient,
+	}) {
+		require.NotEqual(t, event.ErrorType, e.Type, e.ErrorEvent.Err)
+	}
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	samples := make(map[string]uint64)
+	for _, f := range families {
+		for _, metric := range f.GetMetric() {
+			if n := metric.GetHistogram().GetSampleCount(); n > 0 {
+				samples[f.GetName()] += n
+			}
+		}
+	}
+	assert.Equal(t, map[string]uint64{
+		"cli_utils_apply_duration_seconds": 1,
+		"cli_utils_run_duration_seconds":   1,
+	}, samples)
+}
+
+// fakeCheckpointStore stores the checkpoint in memory and records each saved
+// checkpoint.
+type fakeCheckpointStore struct {
+	checkpoint *checkpoint.Checkpoint
+	saved      []checkpoint.Checkpoint
+	deleted    bool
+}
+
+func (s *fakeCheckpointStore) Load(_ context.Context, _ inventory.Info) (*checkpoint.Checkpoint, error) {
+	return s.checkpoint, nil
+}
+
+func (s *fakeCheckpointStore) Save(_ context.Context, _ inventory.Info, cp *checkpoint.Checkpoint) error {
+	s.checkpoint = cp
+	s.saved = append(s.saved, *cp)
+	return nil
+}
+
+func (s *fakeCheckpointStore) Delete(_ context.Context, _ inventory.Info) error {
+	s.checkpoint = nil
+	s.deleted = true
+	return nil
+}
+
+func TestApplierCheckpoint(t *testing.T) {
+	invInfo := inventoryInfo{
+		name:      "inv-123",
+		namespace: "default",
+		id:        "test",
+	}
+	options := ApplierOptions{
+		InventoryPolicy:  inventory.PolicyMustMatch,
+		ReconcileTimeout: time.Millisecond,
+	}
+
+	runGroups := func(store *fakeCheckpointStore, options ApplierOptions) []string {
+		// Each run reads the objects again, like a restarted process.
+		objs := object.UnstructuredSet{testutil.Unstructured(t, resources["deployment"])}
+		applier := newTestApplier(t, invInfo, objs, object.UnstructuredSet{}, watcher.BlindStatusWatcher{})
+		applier.checkpointStore = NewApplierBuilder().WithCheckpointStore(store).checkpointStore
+		var groups []string
+		for e := range applier.Run(context.TODO(), invInfo.toWrapped(), objs, options) {
+			require.NotEqual(t, event.ErrorType, e.Type, e.ErrorEvent.Err)
+			if e.Type == event.ActionGroupType && e.ActionGroupEvent.Status == event.Started {
+				groups = append(groups, e.ActionGroupEvent.GroupName)
+			}
+		}
+		return groups
+	}
+
+	// A checkpoint is saved after each task and deleted after the run.
+	store := &fakeCheckpointStore{}
+	groups := runGroups(store, options)
+	require.Len(t, store.saved, len(groups))
+	assert.True(t, store.deleted)
+	assert.Nil(t, store.checkpoint)
+	for i, cp := range store.saved {
+		assert.Equal(t, groups[:i+1], cp.CompletedTasks)
+	}
+	applyIndex := -1
+	for i, name := range groups {
+		if strings.HasPrefix(name, "apply-") {
+			applyIndex = i
+		}
+	}
+	require.NotEqual(t, -1, applyIndex)
+	afterApply := store.saved[applyIndex]
+	require.Len(t, afterApply.Objects, 1)
+	assert.Equal(t, actuation.ActuationSucceeded, afterApply.Objects[0].Actuation)
+
+	// Resuming after the apply task only runs the remaining tasks.
+	store = &fakeCheckpointStore{checkpoint: &afterApply}
+	resumeOptions := options
+	resumeOptions.Resume = true
+	assert.Equal(t, groups[applyIndex+1:], runGroups(store, resumeOptions))
+	assert.True(t, store.deleted)
+
+	// A checkpoint of different objects is ignored.
+	stale := afterApply
+	stale.Fingerprint = "stale"
+	store = &fakeCheckpointStore{checkpoint: &stale}
+	assert.Equal(t, groups, runGroups(store, resumeOptions))
+}
+
+// fakeReportStore records each saved report.
+type fakeReportStore struct {
+	reports []*report.Report
+}
+
+func (s *fakeReportStore) Save(_ context.Context, _ inventory.Info, rep *report.Report) error {
+	s.reports = append(s.reports, rep)
+	return nil
+}
+
+func TestApplierReport(t *testing.T) {
+	invInfo := inventoryInfo{
+		name:      "inv-123",
+		namespace: "default",
+		id:        "test",
+	}
+	objs := object.UnstructuredSet{testutil.Unstructured(t, resources["deployment"])}
+	store := &fakeReportStore{}
+	applier := newTestApplier(t, invInfo, objs, object.UnstructuredSet{}, watcher.BlindStatusWatcher{})
+	applier.reporter = NewApplierBuilder().WithReportStore(store).newReporter()
+
+	options := ApplierOptions{
+		InventoryPolicy:  inventory.PolicyMustMatch,
+		ReconcileTimeout: time.Millisecond,
+	}
+	for e := range applier.Run(context.TODO(), invInfo.toWrapped(), objs, options) {
+		require.NotEqual(t, event.ErrorType, e.Type, e.ErrorEvent.Err)
+	}
+	require.Len(t, store.reports, 1)
+	rep := store.reports[0]
+	assert.Equal(t, "apply", rep.Operation)
+	assert.Equal(t, report.InventoryReference{Name: "inv-123", Namespace: "default", ID: "test"}, rep.Inventory)
+	require.Len(t, rep.Objects, 1)
+	assert.Equal(t, "Deployment", rep.Objects[0].Kind)
+	assert.Equal(t, "Succeeded", rep.Objects[0].Actuation)
+	assert.Equal(t, "Timeout", rep.Objects[0].Reconcile)
+
+	// Reports are not saved for dry-runs.
+	options.DryRunStrategy = common.DryRunClient
+	for range applier.Run(context.TODO(), invInfo.toWrapped(), objs, options) {
+	}
+	assert.Len(t, store.reports, 1)
+}
+
+func TestApplierPrune(t *testing.T) {
+	deployment := testutil.Unstructured(t, resources["deployment"], testutil.AddOwningInv(t, "test"))
+	secret := testutil.Unstructured(t, resources["secret"], testutil.AddOwningInv(t, "test"))
+	deploymentID := object.UnstructuredToObjMetadata(deployment)
+	secretID := object.UnstructuredToObjMetadata(secret)
+	invInfo := inventoryInfo{
+		name:      "inv-123",
+		namespace: "default",
+		id:        "test",
+		set:       object.ObjMetadataSet{deploymentID, secretID},
+	}
+	current := object.UnstructuredSet{testutil.Unstructured(t, resources["deployment"])}
+	applier := newTestApplier(t, invInfo, current,
+		object.UnstructuredSet{deployment, secret}, watcher.BlindStatusWatcher{})
+
+	var groups []string
+	var pruned object.ObjMetadataSet
+	for e := range applier.Prune(context.TODO(), invInfo.toWrapped(), current, ApplierOptions{
+		InventoryPolicy: inventory.PolicyMustMatch,
+		PruneTimeout:    time.Millisecond,
+	}) {
+		switch e.Type {
+		case event.ErrorType:
+			t.Fatalf("unexpected error: %v", e.ErrorEvent.Err)
+		case event.InitType:
+			for _, ag := range e.InitEvent.ActionGroups {
+				groups = append(groups, ag.Name)
+			}
+		case event.ApplyType:
+			t.Fatalf("unexpected apply event: %v", e.ApplyEvent)
+		case event.PruneType:
+			if e.PruneEvent.Status == event.PruneSuccessful {
+				pruned = append(pruned, e.PruneEvent.Identifier)
+			}
+		}
+	}
+	assert.Equal(t, []string{"inventory-add-0", "prune-0", "wait-0", "inventory-set-0"}, groups)
+	assert.Equal(t, object.ObjMetadataSet{secretID}, pruned)
+
+	// The current object is retained in the inventory.
+	invObj, err := applier.client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).
+		Namespace(invInfo.namespace).Get(context.TODO(), invInfo.name, metav1.GetOptions{})
+	require.NoError(t, err)
+	invObjs, err := inventory.WrapInventoryObj(invObj).Load()
+	require.NoError(t, err)
+	assert.Equal(t, object.ObjMetadataSet{deploymentID}, invObjs)
+}
+
+func TestApplierSelector(t *testing.T) {
+	deploymentID := testutil.ToIdentifier(t, resources["deployment"])
+	secretID := testutil.ToIdentifier(t, resources["secret"])
+	secretGK := schema.GroupKind{Kind: "Secret"}
+
+	testCases := map[string]struct {
+		selector          *Selector
+		expectedGroups    []string
+		expectedApplied   object.ObjMetadataSet
+		expectedPruned    object.ObjMetadataSet
+		expectedInventory object.ObjMetadataSet
+	}{
+		"apply selected kind, retain excluded prune object": {
+			selector:          &Selector{IncludeKinds: []schema.GroupKind{deploymentID.GroupKind}},
+			expectedGroups:    []string{"inventory-add-0", "apply-0", "wait-0", "inventory-set-0"},
+			expectedApplied:   object.ObjMetadataSet{deploymentID},
+			expectedInventory: object.ObjMetadataSet{deploymentID, secretID},
+		},
+		"prune selected kind, retain excluded object": {
+			selector:          &Selector{IncludeKinds: []schema.GroupKind{secretGK}},
+			expectedGroups:    []string{"inventory-add-0", "prune-0", "wait-0", "inventory-set-0"},
+			expectedPruned:    object.ObjMetadataSet{secretID},
+			expectedInventory: object.ObjMetadataSet{deploymentID},
+		},
+		"exclude kind": {
+			selector:          &Selector{ExcludeKinds: []schema.GroupKind{secretGK}},
+			expectedGroups:    []string{"inventory-add-0", "apply-0", "wait-0", "inventory-set-0"},
+			expectedApplied:   object.ObjMetadataSet{deploymentID},
+			expectedInventory: object.ObjMetadataSet{deploymentID, secretID},
+		},
+		"no objects selected": {
+			selector:          &Selector{LabelSelector: labels.SelectorFromSet(labels.Set{"app": "none"})},
+			expectedGroups:    []string{"inventory-add-0", "inventory-set-0"},
+			expectedInventory: object.ObjMetadataSet{deploymentID, secretID},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			invInfo := inventoryInfo{
+				name:      "inv-123",
+				namespace: "default",
+				id:        "test",
+				set:       object.ObjMetadataSet{deploymentID, secretID},
+			}
+			objs := object.UnstructuredSet{testutil.Unstructured(t, resources["deployment"])}
+			clusterObjs := object.UnstructuredSet{
+				testutil.Unstructured(t, resources["deployment"], testutil.AddOwningInv(t, "test")),
+				testutil.Unstructured(t, resources["secret"], testutil.AddOwningInv(t, "test")),
+			}
+			applier := newTestApplier(t, invInfo, objs, clusterObjs, watcher.BlindStatusWatcher{})
+
+			var groups []string
+			var applied, pruned object.ObjMetadataSet
+			for e := range applier.Run(context.TODO(), invInfo.toWrapped(), objs, ApplierOptions{
+				InventoryPolicy:  inventory.PolicyMustMatch,
+				ReconcileTimeout: time.Millisecond,
+				PruneTimeout:     time.Millisecond,
+				Selector:         tc.selector,
+			}) {
+				switch e.Type {
+				case event.ErrorType:
+					t.Fatalf("unexpected error: %v", e.ErrorEvent.Err)
+				case event.InitType:
+					for _, ag := range e.InitEvent.ActionGroups {
+						groups = append(groups, ag.Name)
+					}
+				case event.ApplyType:
+					if e.ApplyEvent.Status == event.ApplySuccessful {
+						applied = append(applied, e.ApplyEvent.Identifier)
+					}
+				case event.PruneType:
+					if e.PruneEvent.Status == event.PruneSuccessful {
+						pruned = append(pruned, e.PruneEvent.Identifier)
+					}
+				}
+			}
+			assert.Equal(t, tc.expectedGroups, groups)
+			assert.Equal(t, tc.expectedApplied, applied)
+			assert.Equal(t, tc.expectedPruned, pruned)
+
+			invObj, err := applier.client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).
+				Namespace(invInfo.namespace).Get(context.TODO(), invInfo.name, metav1.GetOptions{})
+			require.NoError(t, err)
+			invObjs, err := inventory.WrapInventoryObj(invObj).Load()
+			require.NoError(t, err)
+			testutil.AssertEqual(t, tc.expectedInventory, invObjs)
+		})
+	}
+}
+
+func TestApplierCreateNamespaces(t *testing.T) {
+	invInfo := inventoryInfo{
+		name:      "inv-123",
+		namespace: "default",
+		id:        "test",
+	}
+	deployment := testutil.Unstructured(t, resources["deployment"])
+	namespace := &unstructured.Unstructured{}
+	namespace.SetAPIVersion("v1")
+	namespace.SetKind("Namespace")
+	namespace.SetName(deployment.GetNamespace())
+	// The namespace does not exist in the cluster.
+	applier := newTestApplier(t, invInfo, object.UnstructuredSet{deployment, namespace},
+		object.UnstructuredSet{}, watcher.BlindStatusWatcher{})
+
+	var groups []event.ActionGroup
+	for e := range applier.Run(context.TODO(), invInfo.toWrapped(), object.UnstructuredSet{deployment}, ApplierOptions{
+		InventoryPolicy:  inventory.PolicyMustMatch,
+		DryRunStrategy:   common.DryRunClient,
+		ReconcileTimeout: time.Millisecond,
+		CreateNamespaces: true,
+	}) {
+		switch e.Type {
+		case event.ErrorType:
+			t.Fatalf("unexpected error: %v", e.ErrorEvent.Err)
+		case event.InitType:
+			groups = e.InitEvent.ActionGroups
+		}
+	}
+
+	// The generated namespace is applied before the deployment.
+	var applyGroups []object.ObjMetadataSet
+	for _, ag := range groups {
+		if ag.Action == event.ApplyAction {
+			applyGroups = append(applyGroups, ag.Identifiers)
+		}
+	}
+	assert.Equal(t, []object.ObjMetadataSet{
+		{object.UnstructuredToObjMetadata(namespace)},
+		{object.UnstructuredToObjMetadata(deployment)},
+	}, applyGroups)
+}
+
+func TestApplierBuilder_WithRestConfig(t *testing.T) {
+	invClient := inventory.NewFakeClient(object.ObjMetadataSet{})
+
+	// Without a factory, the clients are created from the rest config.
+	applier, err := NewApplierBuilder().
+		WithInventoryClient(invClient).
+		WithRestConfig(&rest.Config{Host: "https://127.0.0.1:6443"}).
+		Build()
+	require.NoError(t, err)
+	assert.NotNil(t, applier.client)
+	assert.NotNil(t, applier.mapper)
+	assert.NotNil(t, applier.openAPIGetter)
+	assert.NotNil(t, applier.statusWatcher)
+	mapping := &meta.RESTMapping{
+		GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+	}
+	restClient, err := unstructuredClientForConfig(&rest.Config{Host: "https://127.0.0.1:6443"})(mapping)
+	require.NoError(t, err)
+	assert.NotNil(t, restClient)
+
+	// Without a factory and a rest config, all clients must be provided.
+	_, err = NewApplierBuilder().
+		WithInventoryClient(invClient).
+		WithDynamicClient(dynamicfake.NewSimpleDynamicClient(scheme.Scheme)).
+		Build()
+	assert.EqualError(t, err, "a factory, a rest config, or all other options must be provided")
+
+	destroyer, err := NewDestroyerBuilder().
+		WithInventoryClient(invClient).
+		WithRestConfig(&rest.Config{Host: "https://127.0.0.1:6443"}).
+		Build()
+	require.NoError(t, err)
+	assert.NotNil(t, destroyer.discoClient)
+}
+
+func TestApplierPlanSummary(t *testing.T) {
+	deploymentGVR := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	deployment := func(name string, replicas int64) *unstructured.Unstructured {
+		u := testutil.Unstructured(t, resources["deployment"])
+		u.SetName(name)
+		require.NoError(t, unstructured.SetNestedField(u.Object, replicas, "spec", "replicas"))
+		return u
+	}
+	unchanged := deployment("unchanged", 1)
+	configured := deployment("configured", 3)
+	created := deployment("created", 1)
+	pruned := deployment("pruned", 1)
+	unknown := testutil.Unstructured(t, resources["deployment"])
+	unknown.SetAPIVersion("example.com/v1")
+	unknown.SetKind("Unknown")
+
+	client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme,
+		deployment("unchanged", 1), deployment("configured", 1))
+	// Dry-run applies return the live object with the applied spec.
+	client.PrependReactor("patch", "deployments", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(clienttesting.PatchAction)
+		patch := &unstructured.Unstructured{}
+		require.NoError(t, patch.UnmarshalJSON(patchAction.GetPatch()))
+		obj, err := client.Tracker().Get(deploymentGVR, patchAction.GetNamespace(), patchAction.GetName())
+		if err != nil {
+			return true, patch, nil
+		}
+		applied := obj.(*unstructured.Unstructured).DeepCopy()
+		applied.Object["spec"] = patch.Object["spec"]
+		return true, applied, nil
+	})
+	applier := &Applier{
+		client: client,
+		mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+			scheme.Scheme.PrioritizedVersionsAllGroups()...),
+	}
+
+	summary := applier.planSummary(context.TODO(),
+		object.UnstructuredSet{unchanged, configured, created, unknown},
+		object.UnstructuredSet{pruned}, "")
+	assert.Equal(t, object.ObjMetadataSet{object.UnstructuredToObjMetadata(created)}, summary.Create)
+	assert.Equal(t, object.ObjMetadataSet{object.UnstructuredToObjMetadata(configured)}, summary.Configure)
+	assert.Equal(t, object.ObjMetadataSet{object.UnstructuredToObjMetadata(unchanged)}, summary.Unchanged)
+	assert.Equal(t, object.ObjMetadataSet{object.UnstructuredToObjMetadata(pruned)}, summary.Prune)
+	assert.Len(t, summary.Errors, 1)
+	assert.Contains(t, summary.Errors, object.UnstructuredToObjMetadata(unknown))
+}
+
+func TestApplierPlanSummary_Journal(t *testing.T) {
+	invInfo := inventoryInfo{
+		name:      "inv-123",
+		namespace: "default",
+		id:        "test",
+	}
+	objs := object.UnstructuredSet{testutil.Unstructured(t, resources["deployment"])}
+	applier := newTestApplier(t, invInfo, objs, object.UnstructuredSet{}, watcher.BlindStatusWatcher{})
+
+	options := ApplierOptions{
+		InventoryPolicy:  inventory.PolicyMustMatch,
+		ReconcileTimeout: time.Millisecond,
+		EmitPlanSummary:  true,
+	}
+	var buf bytes.Buffer
+	jw := journal.NewWriter(&buf, common.DryRunNone)
+	var summaries int
+	for e := range jw.Tee(applier.Run(context.TODO(), invInfo.toWrapped(), objs, options)) {
+		if e.Type == event.PlanSummaryType {
+			summaries++
+		}
+	}
+	require.NoError(t, jw.Err())
+	assert.Equal(t, 1, summaries)
+
+	entries, err := journal.ReadAll(&buf)
+	require.NoError(t, err)
+	var recorded int
+	for _, entry := range entries {
+		if entry.Event.Type == event.PlanSummaryType {
+			recorded++
+		}
+	}
+	assert.Equal(t, 1, recorded)
+}
@@ -7,16 +7,31 @@ import (
 	"errors"
 	"fmt"
 
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/apply/report"
+	"sigs.k8s.io/cli-utils/pkg/eventrecorder"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
+	"sigs.k8s.io/cli-utils/pkg/metrics"
+	"sigs.k8s.io/cli-utils/pkg/object/graph"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// errMissingClients is returned if neither a factory nor a rest config is
+// provided, and a client that can only be created from either is missing.
+var errMissingClients = errors.New("a factory, a rest config, or all other options must be provided")
+
 type commonBuilder struct {
 	// factory is only used to retrieve things that have not been provided explicitly.
 	factory                      util.Factory
@@ -27,6 +42,19 @@ type commonBuilder struct {
 	restConfig                   *rest.Config
 	unstructuredClientForMapping func(*meta.RESTMapping) (resource.RESTClient, error)
 	statusWatcher                watcher.StatusWatcher
+	// statusPoller is only set if no statusWatcher was provided explicitly.
+	statusPoller watcher.StatusWatcher
+	metrics      *metrics.Metrics
+	// tracerProvider is only set if provided explicitly, otherwise the
+	// global TracerProvider is used.
+	tracerProvider trace.TracerProvider
+	// dependencyRules are the registered implicit dependency rules.
+	dependencyRules []graph.Rule
+	// eventRecorder and eventTarget are only set if events are enabled.
+	eventRecorder record.EventRecorder
+	eventTarget   eventrecorder.Target
+	// reportStore is only set if reports are enabled.
+	reportStore report.Store
 }
 
 func (cb *commonBuilder) finalize() (*commonBuilder, error) {
@@ -35,50 +63,127 @@ func (cb *commonBuilder) finalize() (*commonBuilder, error) {
 	if cx.invClient == nil {
 		return nil, errors.New("inventory client must be provided")
 	}
+	for _, rule := range cx.dependencyRules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if cx.restConfig == nil && cx.factory != nil {
+		cx.restConfig, err = cx.factory.ToRESTConfig()
+		if err != nil {
+			return nil, fmt.Errorf("error getting rest config: %v", err)
+		}
+	}
 	if cx.client == nil {
-		if cx.factory == nil {
-			return nil, fmt.Errorf("a factory must be provided or all other options: %v", err)
+		switch {
+		case cx.factory != nil:
+			cx.client, err = cx.factory.DynamicClient()
+		case cx.restConfig != nil:
+			cx.client, err = dynamic.NewForConfig(cx.restConfig)
+		default:
+			return nil, errMissingClients
 		}
-		cx.client, err = cx.factory.DynamicClient()
 		if err != nil {
 			return nil, fmt.Errorf("error getting dynamic client: %v", err)
 		}
 	}
 	if cx.discoClient == nil {
-		if cx.factory == nil {
-			return nil, fmt.Errorf("a factory must be provided or all other options: %v", err)
+		switch {
+		case cx.factory != nil:
+			cx.discoClient, err = cx.factory.ToDiscoveryClient()
+		case cx.restConfig != nil:
+			var dc *discovery.DiscoveryClient
+			dc, err = discovery.NewDiscoveryClientForConfig(cx.restConfig)
+			if err == nil {
+				cx.discoClient = memory.NewMemCacheClient(dc)
+			}
+		default:
+			return nil, errMissingClients
 		}
-		cx.discoClient, err = cx.factory.ToDiscoveryClient()
 		if err != nil {
 			return nil, fmt.Errorf("error getting discovery client: %v", err)
 		}
 	}
 	if cx.mapper == nil {
-		if cx.factory == nil {
-			return nil, fmt.Errorf("a factory must be provided or all other options: %v", err)
-		}
-		cx.mapper, err = cx.factory.ToRESTMapper()
-		if err != nil {
-			return nil, fmt.Errorf("error getting rest mapper: %v", err)
+		if cx.factory != nil {
+			cx.mapper, err = cx.factory.ToRESTMapper()
+			if err != nil {
+				return nil, fmt.Errorf("error getting rest mapper: %v", err)
+			}
+		} else {
+			cx.mapper = restmapper.NewDeferredDiscoveryRESTMapper(cx.discoClient)
 		}
 	}
-	if cx.restConfig == nil {
-		if cx.factory == nil {
-			return nil, fmt.Errorf("a factory must be provided or all other options: %v", err)
+	if cx.unstructuredClientForMapping == nil {
+		switch {
+		case cx.factory != nil:
+			cx.unstructuredClientForMapping = cx.factory.UnstructuredClientForMapping
+		case cx.restConfig != nil:
+			cx.unstructuredClientForMapping = unstructuredClientForConfig(cx.restConfig)
+		default:
+			return nil, errMissingClients
 		}
-		cx.restConfig, err = cx.factory.ToRESTConfig()
+	}
+	if cx.statusWatcher == nil && cx.restConfig == nil {
+		return nil, errMissingClients
+	}
+	if cx.statusWatcher == nil {
+		reader, err := client.New(cx.restConfig, client.Options{Scheme: scheme.Scheme, Mapper: cx.mapper})
 		if err != nil {
-			return nil, fmt.Errorf("error getting rest config: %v", err)
+			return nil, fmt.Errorf("error creating client: %v", err)
+		}
+		poller := watcher.NewPollingStatusWatcher(reader, cx.mapper)
+		if cx.metrics != nil {
+			poller.OnPoll = cx.metrics.ObserveStatusPoll
+		}
+		cx.statusPoller = poller
+		cx.statusWatcher = &watcher.FallbackStatusWatcher{
+			Watcher:  watcher.NewDefaultStatusWatcher(cx.client, cx.mapper),
+			Fallback: cx.statusPoller,
 		}
 	}
-	if cx.unstructuredClientForMapping == nil {
-		if cx.factory == nil {
-			return nil, fmt.Errorf("a factory must be provided or all other options: %v", err)
+	return &cx, nil
+}
+
+// unstructuredClientForConfig returns a function that creates REST clients
+// for unstructured objects of a mapping, like
+// Factory.UnstructuredClientForMapping, from the rest config.
+func unstructuredClientForConfig(restConfig *rest.Config) func(*meta.RESTMapping) (resource.RESTClient, error) {
+	return func(mapping *meta.RESTMapping) (resource.RESTClient, error) {
+		cfg := rest.CopyConfig(restConfig)
+		if err := rest.SetKubernetesDefaults(cfg); err != nil {
+			return nil, err
 		}
-		cx.unstructuredClientForMapping = cx.factory.UnstructuredClientForMapping
+		cfg.APIPath = "/apis"
+		if mapping.GroupVersionKind.Group == corev1.GroupName {
+			cfg.APIPath = "/api"
+		}
+		gv := mapping.GroupVersionKind.GroupVersion()
+		cfg.ContentConfig = resource.UnstructuredPlusDefaultContentConfig()
+		cfg.GroupVersion = &gv
+		return rest.RESTClientFor(cfg)
 	}
-	if cx.statusWatcher == nil {
-		cx.statusWatcher = watcher.NewDefaultStatusWatcher(cx.client, cx.mapper)
+}
+
+// newReporter returns the reporter of each run, or nil if reports are not
+// enabled.
+func (cb *commonBuilder) newReporter() *report.Reporter {
+	if cb.reportStore == nil {
+		return nil
+	}
+	return &report.Reporter{Store: cb.reportStore}
+}
+
+// newEventRecorder returns the recorder of the Events of each run, or nil if
+// events are not enabled. Must be called on a finalized builder.
+func (cb *commonBuilder) newEventRecorder() *eventrecorder.Recorder {
+	if cb.eventRecorder == nil {
+		return nil
+	}
+	return &eventrecorder.Recorder{
+		Recorder: cb.eventRecorder,
+		Client:   cb.client,
+		Mapper:   cb.mapper,
+		Target:   cb.eventTarget,
 	}
-	return &cx, nil
 }
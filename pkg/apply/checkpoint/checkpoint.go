@@ -0,0 +1,164 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package checkpoint persists the progress of an apply run, so a restarted
+// process can resume an interrupted run where it left off, instead of
+// running every task again.
+package checkpoint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Checkpoint is the progress of an apply run.
+type Checkpoint struct {
+	// Fingerprint identifies the task queue and the objects of the run.
+	// A checkpoint is only resumed by a run with the same fingerprint.
+	Fingerprint string `json:"fingerprint"`
+	// CompletedTasks are the names of the tasks that completed, in the
+	// order they ran.
+	CompletedTasks []string `json:"completedTasks,omitempty"`
+	// Objects are the actuation and reconcile statuses of the objects,
+	// e.g. the UID and generation of the applied objects.
+	Objects []actuation.ObjectStatus `json:"objects,omitempty"`
+}
+
+// Fingerprint returns the fingerprint of a run with the action groups and
+// the objects to apply.
+func Fingerprint(actionGroups []event.ActionGroup, applyObjs object.UnstructuredSet) (string, error) {
+	data, err := json.Marshal(struct {
+		ActionGroups []event.ActionGroup    `json:"actionGroups"`
+		Objects      object.UnstructuredSet `json:"objects"`
+	}{
+		ActionGroups: actionGroups,
+		Objects:      applyObjs,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode run: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Store loads and saves the checkpoint of the runs of an inventory.
+type Store interface {
+	// Load returns the checkpoint of the inventory, or nil if there is none.
+	Load(ctx context.Context, inv inventory.Info) (*Checkpoint, error)
+	// Save replaces the checkpoint of the inventory.
+	Save(ctx context.Context, inv inventory.Info, cp *Checkpoint) error
+	// Delete deletes the checkpoint of the inventory, if any.
+	Delete(ctx context.Context, inv inventory.Info) error
+}
+
+// DataKey is the key of the checkpoint in the data of the ConfigMap.
+const DataKey = "checkpoint"
+
+var configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// ConfigMapStore stores the checkpoint of an inventory in a ConfigMap next
+// to the inventory object, named after it with the suffix "-checkpoint" and
+// labeled with the inventory.OwnerLabel. ConfigMaps of other inventories
+// are neither loaded, updated nor deleted.
+type ConfigMapStore struct {
+	Client dynamic.Interface
+}
+
+var _ Store = &ConfigMapStore{}
+
+// Name returns the name of the ConfigMap of the checkpoint of the inventory.
+func Name(inv inventory.Info) string {
+	return inv.Name() + "-checkpoint"
+}
+
+// Load returns the checkpoint of the inventory, or nil if the ConfigMap
+// does not exist or is not owned by the inventory.
+func (s *ConfigMapStore) Load(ctx context.Context, inv inventory.Info) (*Checkpoint, error) {
+	obj, err := s.Client.Resource(configMapGVR).Namespace(inv.Namespace()).
+		Get(ctx, Name(inv), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get checkpoint: %w", err)
+	}
+	if !inventory.IsOwner(inv, obj) {
+		klog.V(4).Infof("ignoring checkpoint %s/%s: not owned by inventory %q", inv.Namespace(), Name(inv), inv.ID())
+		return nil, nil
+	}
+	data, found, err := unstructured.NestedString(obj.Object, "data", DataKey)
+	if err != nil || !found {
+		return nil, fmt.Errorf("invalid checkpoint %s/%s: missing data key %q",
+			inv.Namespace(), Name(inv), DataKey)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal([]byte(data), &cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s/%s: %w", inv.Namespace(), Name(inv), err)
+	}
+	return &cp, nil
+}
+
+// Save creates or updates the ConfigMap with the checkpoint.
+func (s *ConfigMapStore) Save(ctx context.Context, inv inventory.Info, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetName(Name(inv))
+	obj.SetNamespace(inv.Namespace())
+	if err := unstructured.SetNestedField(obj.Object, string(data), "data", DataKey); err != nil {
+		return err
+	}
+	inventory.SetOwner(obj, inv)
+	client := s.Client.Resource(configMapGVR).Namespace(inv.Namespace())
+	live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = client.Create(ctx, obj, metav1.CreateOptions{})
+	case err == nil:
+		if err = inventory.CheckOwner(inv, live); err != nil {
+			break
+		}
+		obj.SetResourceVersion(live.GetResourceVersion())
+		_, err = client.Update(ctx, obj, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes the ConfigMap of the checkpoint, if it exists.
+func (s *ConfigMapStore) Delete(ctx context.Context, inv inventory.Info) error {
+	client := s.Client.Resource(configMapGVR).Namespace(inv.Namespace())
+	live, err := client.Get(ctx, Name(inv), metav1.GetOptions{})
+	if err == nil {
+		if err = inventory.CheckOwner(inv, live); err == nil {
+			uid := live.GetUID()
+			err = client.Delete(ctx, Name(inv), metav1.DeleteOptions{
+				Preconditions: &metav1.Preconditions{UID: &uid},
+			})
+		}
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	return nil
+}
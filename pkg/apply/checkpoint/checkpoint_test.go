@@ -0,0 +1,158 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package checkpoint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func newInventory() inventory.Info {
+	inv := &unstructured.Unstructured{}
+	inv.SetAPIVersion("v1")
+	inv.SetKind("ConfigMap")
+	inv.SetName("inventory")
+	inv.SetNamespace("default")
+	inv.SetLabels(map[string]string{common.InventoryLabel: "test"})
+	return inventory.WrapInventoryInfoObj(inv)
+}
+
+func TestConfigMapStore(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme)
+	store := &ConfigMapStore{Client: client}
+	inv := newInventory()
+	ctx := context.Background()
+
+	cp, err := store.Load(ctx, inv)
+	require.NoError(t, err)
+	assert.Nil(t, cp)
+
+	first := &Checkpoint{
+		Fingerprint:    "abc",
+		CompletedTasks: []string{"inventory-add-0"},
+	}
+	require.NoError(t, store.Save(ctx, inv, first))
+	second := &Checkpoint{
+		Fingerprint:    "abc",
+		CompletedTasks: []string{"inventory-add-0", "apply-0"},
+		Objects: []actuation.ObjectStatus{
+			{
+				ObjectReference: actuation.ObjectReference{
+					Group:     "apps",
+					Kind:      "Deployment",
+					Name:      "foo",
+					Namespace: "default",
+				},
+				Strategy:   actuation.ActuationStrategyApply,
+				Actuation:  actuation.ActuationSucceeded,
+				Reconcile:  actuation.ReconcilePending,
+				UID:        "uid-1",
+				Generation: 2,
+			},
+		},
+	}
+	require.NoError(t, store.Save(ctx, inv, second))
+
+	obj, err := client.Resource(configMapGVR).Namespace("default").
+		Get(ctx, "inventory-checkpoint", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, obj.Object["data"], DataKey)
+
+	cp, err = store.Load(ctx, inv)
+	require.NoError(t, err)
+	assert.Equal(t, second, cp)
+
+	require.NoError(t, store.Delete(ctx, inv))
+	cp, err = store.Load(ctx, inv)
+	require.NoError(t, err)
+	assert.Nil(t, cp)
+	// Deleting a missing checkpoint is not an error.
+	require.NoError(t, store.Delete(ctx, inv))
+}
+
+func TestConfigMapStore_Invalid(t *testing.T) {
+	invalid := &unstructured.Unstructured{}
+	invalid.SetAPIVersion("v1")
+	invalid.SetKind("ConfigMap")
+	invalid.SetName("inventory-checkpoint")
+	invalid.SetNamespace("default")
+	invalid.SetLabels(map[string]string{inventory.OwnerLabel: "test"})
+	client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, invalid)
+	store := &ConfigMapStore{Client: client}
+
+	_, err := store.Load(context.Background(), newInventory())
+	assert.EqualError(t, err, `invalid checkpoint default/inventory-checkpoint: missing data key "checkpoint"`)
+}
+
+func TestConfigMapStore_Owner(t *testing.T) {
+	other := &unstructured.Unstructured{}
+	other.SetAPIVersion("v1")
+	other.SetKind("ConfigMap")
+	other.SetName("inventory-checkpoint")
+	other.SetNamespace("default")
+	other.SetLabels(map[string]string{inventory.OwnerLabel: "other"})
+	client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, other)
+	store := &ConfigMapStore{Client: client}
+	inv := newInventory()
+	ctx := context.Background()
+
+	cp, err := store.Load(ctx, inv)
+	require.NoError(t, err)
+	assert.Nil(t, cp)
+	assert.EqualError(t, store.Save(ctx, inv, &Checkpoint{Fingerprint: "abc"}),
+		`failed to save checkpoint: refusing to overwrite ConfigMap default/inventory-checkpoint: `+
+			`not owned by inventory "test" (label cli-utils.sigs.k8s.io/owner-inventory-id="other")`)
+	assert.Error(t, store.Delete(ctx, inv))
+
+	obj, err := client.Resource(configMapGVR).Namespace("default").
+		Get(ctx, "inventory-checkpoint", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, other, obj)
+}
+
+func TestFingerprint(t *testing.T) {
+	newObj := func(replicas int64) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("apps/v1")
+		obj.SetKind("Deployment")
+		obj.SetName("foo")
+		obj.SetNamespace("default")
+		require.NoError(t, unstructured.SetNestedField(obj.Object, replicas, "spec", "replicas"))
+		return obj
+	}
+	groups := []event.ActionGroup{
+		{
+			Name:        "apply-0",
+			Action:      event.ApplyAction,
+			Identifiers: object.ObjMetadataSet{object.UnstructuredToObjMetadata(newObj(1))},
+		},
+	}
+
+	fp, err := Fingerprint(groups, object.UnstructuredSet{newObj(1)})
+	require.NoError(t, err)
+	same, err := Fingerprint(groups, object.UnstructuredSet{newObj(1)})
+	require.NoError(t, err)
+	assert.Equal(t, fp, same)
+
+	changed, err := Fingerprint(groups, object.UnstructuredSet{newObj(2)})
+	require.NoError(t, err)
+	assert.NotEqual(t, fp, changed)
+
+	regrouped, err := Fingerprint(append(groups, event.ActionGroup{Name: "wait-0", Action: event.WaitAction}),
+		object.UnstructuredSet{newObj(1)})
+	require.NoError(t, err)
+	assert.NotEqual(t, fp, regrouped)
+}
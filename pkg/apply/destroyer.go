@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/discovery"
@@ -19,12 +20,17 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/apply/filter"
 	"sigs.k8s.io/cli-utils/pkg/apply/info"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
+	"sigs.k8s.io/cli-utils/pkg/apply/report"
 	"sigs.k8s.io/cli-utils/pkg/apply/solver"
+	"sigs.k8s.io/cli-utils/pkg/apply/task"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/eventrecorder"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
+	"sigs.k8s.io/cli-utils/pkg/metrics"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/graph"
 	"sigs.k8s.io/cli-utils/pkg/object/validation"
 )
 
@@ -33,11 +39,23 @@ import (
 type Destroyer struct {
 	pruner        *prune.Pruner
 	statusWatcher watcher.StatusWatcher
+	statusPoller  watcher.StatusWatcher
 	invClient     inventory.Client
 	mapper        meta.RESTMapper
 	client        dynamic.Interface
 	openAPIGetter discovery.OpenAPISchemaInterface
+	discoClient   discovery.DiscoveryInterface
 	infoHelper    info.Helper
+	// metrics records the metrics of each run, if set.
+	metrics *metrics.Metrics
+	// tracerProvider provides the tracer of the spans of each run, if set.
+	tracerProvider trace.TracerProvider
+	// eventRecorder posts the Events of each run, if set.
+	eventRecorder *eventrecorder.Recorder
+	// reporter saves the report of each run, if set.
+	reporter *report.Reporter
+	// dependencyRules add implicit dependencies between the objects.
+	dependencyRules []graph.Rule
 }
 
 type DestroyerOptions struct {
@@ -54,15 +72,61 @@ type DestroyerOptions struct {
 
 	// DeletePropagationPolicy defines the deletion propagation policy
 	// that should be used. If this is not provided, the default is to
-	// use the Background policy.
+	// use the Background policy. Objects may override the policy with the
+	// config.kubernetes.io/delete-propagation-policy annotation.
 	DeletePropagationPolicy metav1.DeletionPropagation
 
+	// DeleteGracePeriodSeconds defines the duration in seconds before
+	// each object should be deleted. If nil, the default grace period of
+	// the resource type is used. Zero deletes immediately.
+	DeleteGracePeriodSeconds *int64
+
+	// DeleteOrdering defines the order in which objects are deleted.
+	// Defaults to prune.OrderingReverseApply, the reverse of the apply
+	// order.
+	DeleteOrdering prune.Ordering
+
+	// DeleteConcurrency is the maximum number of objects deleted at the
+	// same time by each delete task. Objects are deleted one by one if it
+	// is less than 2.
+	DeleteConcurrency int
+
 	// EmitStatusEvents defines whether status events should be
 	// emitted on the eventChannel to the caller.
 	EmitStatusEvents bool
 
+	// StatusStrategy defines how the status of objects is watched. By
+	// default, objects are watched, with a fallback to polling if watching
+	// is forbidden. Ignored if a StatusWatcher was provided to the builder.
+	StatusStrategy watcher.Strategy
+
 	// ValidationPolicy defines how to handle invalid objects.
 	ValidationPolicy validation.Policy
+
+	// ContinueOnError defines whether to keep deleting objects whose
+	// dependencies failed, instead of skipping them. Failures are still
+	// reported in the events for each object and in the final stats.
+	ContinueOnError bool
+
+	// RetryPolicy defines how delete operations that failed with a
+	// transient error are retried. If nil, operations are not retried.
+	RetryPolicy *task.RetryPolicy
+
+	// QPS is the maximum number of delete requests per second, in addition
+	// to any throttling by the REST client. The rate is reduced while the
+	// server responds with 429 Too Many Requests. Zero disables throttling.
+	QPS float32
+
+	// Burst is the maximum number of requests sent at once when throttled
+	// by QPS. Defaults to 1.
+	Burst int
+
+	// DeleteEmptyNamespacesOnly defines whether the namespaces generated by
+	// the applier, see ApplierOptions.CreateNamespaces, are only deleted if
+	// they are empty once the other objects were deleted. Namespaces are
+	// deleted after the objects in them. Non-empty namespaces are skipped
+	// and remain in the inventory.
+	DeleteEmptyNamespacesOnly bool
 }
 
 func setDestroyerDefaults(o *DestroyerOptions) {
@@ -102,6 +166,9 @@ func (d *Destroyer) Run(ctx context.Context, invInfo inventory.Info, options Des
 		// Build a TaskContext for passing info between tasks
 		resourceCache := cache.NewResourceCacheMap()
 		taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+		if d.tracerProvider != nil {
+			taskContext.SetTracer(d.tracerProvider.Tracer(taskrunner.TracerName))
+		}
 
 		klog.V(4).Infoln("destroyer building task queue...")
 		deleteFilters := []filter.ValidationFilter{
@@ -114,8 +181,15 @@ func (d *Destroyer) Run(ctx context.Context, invInfo inventory.Info, options Des
 				TaskContext:       taskContext,
 				ActuationStrategy: actuation.ActuationStrategyDelete,
 				DryRunStrategy:    options.DryRunStrategy,
+				ContinueOnError:   options.ContinueOnError,
 			},
 		}
+		if options.DeleteEmptyNamespacesOnly {
+			deleteFilters = append(deleteFilters, filter.EmptyNamespaceFilter{
+				Client:    d.client,
+				Discovery: d.discoClient,
+			})
+		}
 		taskBuilder := &solver.TaskQueueBuilder{
 			Pruner:        d.pruner,
 			DynamicClient: d.client,
@@ -125,14 +199,21 @@ func (d *Destroyer) Run(ctx context.Context, invInfo inventory.Info, options Des
 			InvClient:     d.invClient,
 			Collector:     vCollector,
 			PruneFilters:  deleteFilters,
+
+			DependencyRules: d.dependencyRules,
 		}
 		opts := solver.Options{
-			Destroy:                true,
-			Prune:                  true,
-			DryRunStrategy:         options.DryRunStrategy,
-			PrunePropagationPolicy: options.DeletePropagationPolicy,
-			PruneTimeout:           options.DeleteTimeout,
-			InventoryPolicy:        options.InventoryPolicy,
+			Destroy:                 true,
+			Prune:                   true,
+			DryRunStrategy:          options.DryRunStrategy,
+			PrunePropagationPolicy:  options.DeletePropagationPolicy,
+			PruneGracePeriodSeconds: options.DeleteGracePeriodSeconds,
+			PruneTimeout:            options.DeleteTimeout,
+			PruneOrdering:           options.DeleteOrdering,
+			PruneConcurrency:        options.DeleteConcurrency,
+			InventoryPolicy:         options.InventoryPolicy,
+			RetryPolicy:             options.RetryPolicy,
+			Throttle:                task.NewThrottle(options.QPS, options.Burst),
 		}
 
 		// Build the ordered set of tasks to execute.
@@ -178,6 +259,9 @@ func (d *Destroyer) Run(ctx context.Context, invInfo inventory.Info, options Des
 		klog.V(4).Infoln("destroyer building TaskStatusRunner...")
 		deleteIds := object.UnstructuredSetToObjMetadataSet(deleteObjs)
 		statusWatcher := d.statusWatcher
+		if options.StatusStrategy == watcher.PollStrategy && d.statusPoller != nil {
+			statusWatcher = d.statusPoller
+		}
 		// Disable watcher for dry runs
 		if opts.DryRunStrategy.ClientOrServerDryRun() {
 			statusWatcher = watcher.BlindStatusWatcher{}
@@ -192,5 +276,9 @@ func (d *Destroyer) Run(ctx context.Context, invInfo inventory.Info, options Des
 			return
 		}
 	}()
-	return eventChannel
+	if options.DryRunStrategy.ClientOrServerDryRun() {
+		return d.metrics.Instrument(metrics.DestroyOperation, eventChannel)
+	}
+	return d.metrics.Instrument(metrics.DestroyOperation,
+		d.reporter.Report(invInfo, metrics.DestroyOperation, d.eventRecorder.Record(invInfo, eventChannel)))
 }
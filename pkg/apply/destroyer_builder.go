@@ -4,18 +4,27 @@
 package apply
 
 import (
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/kubectl/pkg/cmd/util"
 	"sigs.k8s.io/cli-utils/pkg/apply/info"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
+	"sigs.k8s.io/cli-utils/pkg/apply/report"
+	"sigs.k8s.io/cli-utils/pkg/eventrecorder"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
+	"sigs.k8s.io/cli-utils/pkg/metrics"
+	"sigs.k8s.io/cli-utils/pkg/object/graph"
 )
 
+// DestroyerBuilder builds a Destroyer. Like the ApplierBuilder, it
+// requires either a factory or a rest config, unless all clients are
+// provided explicitly.
 type DestroyerBuilder struct {
 	commonBuilder
 }
@@ -37,13 +46,21 @@ func (b *DestroyerBuilder) Build() (*Destroyer, error) {
 			InvClient: bx.invClient,
 			Client:    bx.client,
 			Mapper:    bx.mapper,
+			Metrics:   bx.metrics,
 		},
-		statusWatcher: bx.statusWatcher,
-		invClient:     bx.invClient,
-		mapper:        bx.mapper,
-		client:        bx.client,
-		openAPIGetter: bx.discoClient,
-		infoHelper:    info.NewHelper(bx.mapper, bx.unstructuredClientForMapping),
+		statusWatcher:   bx.statusWatcher,
+		statusPoller:    bx.statusPoller,
+		invClient:       bx.invClient,
+		mapper:          bx.mapper,
+		client:          bx.client,
+		openAPIGetter:   bx.discoClient,
+		discoClient:     bx.discoClient,
+		infoHelper:      info.NewHelper(bx.mapper, bx.unstructuredClientForMapping),
+		metrics:         bx.metrics,
+		tracerProvider:  bx.tracerProvider,
+		eventRecorder:   bx.newEventRecorder(),
+		reporter:        bx.newReporter(),
+		dependencyRules: bx.dependencyRules,
 	}, nil
 }
 
@@ -86,3 +103,49 @@ func (b *DestroyerBuilder) WithStatusWatcher(statusWatcher watcher.StatusWatcher
 	b.statusWatcher = statusWatcher
 	return b
 }
+
+// WithMetrics enables recording the metrics of each run, e.g. the duration
+// of the run and of each apply, on the passed Metrics. The polling status
+// watcher also records the duration of each poll, unless a status watcher
+// was provided.
+func (b *DestroyerBuilder) WithMetrics(m *metrics.Metrics) *DestroyerBuilder {
+	b.metrics = m
+	return b
+}
+
+// WithTracerProvider sets the TracerProvider of the OpenTelemetry spans of
+// each run, its tasks and the actuation of each object. Spans are children
+// of the span in the context passed to Run, if any. Defaults to the global
+// TracerProvider.
+func (b *DestroyerBuilder) WithTracerProvider(tp trace.TracerProvider) *DestroyerBuilder {
+	b.tracerProvider = tp
+	return b
+}
+
+// WithEventRecorder enables posting a Kubernetes Event for each delete result
+// of a run, except for dry-runs, either on the actuated objects or on the
+// inventory object, depending on the target. Events of successes are of type
+// Normal, events of failures of type Warning.
+func (b *DestroyerBuilder) WithEventRecorder(recorder record.EventRecorder, target eventrecorder.Target) *DestroyerBuilder {
+	b.eventRecorder = recorder
+	b.eventTarget = target
+	return b
+}
+
+// WithReportStore enables saving a report of each run to the store when the
+// run completes, with the delete status, reconcile status, error and
+// durations of each object. Reports are not saved for dry-runs.
+func (b *DestroyerBuilder) WithReportStore(s report.Store) *DestroyerBuilder {
+	b.reportStore = s
+	return b
+}
+
+// WithDependencyRule registers a rule of implicit dependencies between the
+// objects, in addition to the built-in ones like namespaces and CRDs, e.g.
+// graph.ServiceAccountRule. Dependency cycles through the edges of a rule
+// are reported as validation errors naming the rule. Build fails if the
+// rule is invalid, see graph.Rule.Validate.
+func (b *DestroyerBuilder) WithDependencyRule(rule graph.Rule) *DestroyerBuilder {
+	b.dependencyRules = append(b.dependencyRules, rule)
+	return b
+}
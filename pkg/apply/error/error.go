@@ -2,6 +2,10 @@
 // SPDX-License-Identifier: Apache-2.0
 package error
 
+import (
+	"sigs.k8s.io/cli-utils/pkg/reason"
+)
+
 type UnknownTypeError struct {
 	err error
 }
@@ -10,6 +14,18 @@ func (e *UnknownTypeError) Error() string {
 	return e.err.Error()
 }
 
+func (e *UnknownTypeError) Unwrap() error {
+	return e.err
+}
+
+func (e *UnknownTypeError) ErrorReason() reason.Reason {
+	return reason.UnknownType
+}
+
+func (e *UnknownTypeError) ErrorRetryable() bool {
+	return false
+}
+
 func NewUnknownTypeError(err error) *UnknownTypeError {
 	return &UnknownTypeError{err: err}
 }
@@ -22,6 +38,23 @@ func (e *ApplyRunError) Error() string {
 	return e.err.Error()
 }
 
+func (e *ApplyRunError) Unwrap() error {
+	return e.err
+}
+
+// ErrorReason returns the reason of the wrapped error, if classified, or
+// reason.ApplyFailed.
+func (e *ApplyRunError) ErrorReason() reason.Reason {
+	if r := reason.Of(e.err); r != reason.Unknown {
+		return r
+	}
+	return reason.ApplyFailed
+}
+
+func (e *ApplyRunError) ErrorRetryable() bool {
+	return reason.IsRetryable(e.err)
+}
+
 func NewApplyRunError(err error) *ApplyRunError {
 	return &ApplyRunError{err: err}
 }
@@ -34,6 +67,10 @@ func (e *InitializeApplyOptionError) Error() string {
 	return e.err.Error()
 }
 
+func (e *InitializeApplyOptionError) Unwrap() error {
+	return e.err
+}
+
 func NewInitializeApplyOptionError(err error) *InitializeApplyOptionError {
 	return &InitializeApplyOptionError{err: err}
 }
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/diff"
 	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/object"
 )
@@ -27,6 +28,13 @@ const (
 	DeleteType
 	WaitType
 	ValidationType
+	DiffType
+	AdoptType
+	TraceType
+	ConflictType
+	RollbackType
+	DriftType
+	PlanSummaryType
 )
 
 // Event is the type of the objects that will be returned through
@@ -70,6 +78,32 @@ type Event struct {
 
 	// ValidationEvent contains information about validation errors.
 	ValidationEvent ValidationEvent
+
+	// DiffEvent contains the changes an apply will make to an object.
+	DiffEvent DiffEvent
+
+	// AdoptEvent contains information about an object claimed by the
+	// inventory before it is applied.
+	AdoptEvent AdoptEvent
+
+	// TraceEvent explains why an object was assigned to its task group.
+	TraceEvent TraceEvent
+
+	// ConflictEvent lists the fields of an object owned by other field
+	// managers that caused a server-side apply to fail.
+	ConflictEvent ConflictEvent
+
+	// RollbackEvent contains information about an object reverted after
+	// a failed apply.
+	RollbackEvent RollbackEvent
+
+	// DriftEvent contains information about whether a live object has
+	// drifted from its desired state.
+	DriftEvent DriftEvent
+
+	// PlanSummaryEvent contains the objects a run would create, configure,
+	// leave unchanged and prune.
+	PlanSummaryEvent PlanSummaryEvent
 }
 
 // String returns a string suitable for logging
@@ -94,6 +128,20 @@ func (e Event) String() string {
 		sb.WriteString(e.WaitEvent.String())
 	case ValidationType:
 		sb.WriteString(e.ValidationEvent.String())
+	case DiffType:
+		sb.WriteString(e.DiffEvent.String())
+	case AdoptType:
+		sb.WriteString(e.AdoptEvent.String())
+	case TraceType:
+		sb.WriteString(e.TraceEvent.String())
+	case ConflictType:
+		sb.WriteString(e.ConflictEvent.String())
+	case RollbackType:
+		sb.WriteString(e.RollbackEvent.String())
+	case DriftType:
+		sb.WriteString(e.DriftEvent.String())
+	case PlanSummaryType:
+		sb.WriteString(e.PlanSummaryEvent.String())
 	}
 	return sb.String()
 }
@@ -166,6 +214,9 @@ const (
 	ReconcileSkipped                           // Skipped
 	ReconcileTimeout                           // Timeout
 	ReconcileFailed                            // Failed
+	// ReconcileSkippedByAnnotation means the object was applied, but not
+	// waited for, because of the config.kubernetes.io/reconcile annotation.
+	ReconcileSkippedByAnnotation // SkippedByAnnotation
 )
 
 type WaitEvent struct {
@@ -210,12 +261,24 @@ const (
 	ApplyFailed                             // Failed
 )
 
+//go:generate stringer -type=ApplyStrategy -linecomment
+type ApplyStrategy int
+
+const (
+	ApplyStrategyUnknown    ApplyStrategy = iota // Unknown
+	ApplyStrategyServerSide                      // ServerSide
+	ApplyStrategyClientSide                      // ClientSide
+)
+
 type ApplyEvent struct {
 	GroupName  string
 	Identifier object.ObjMetadata
 	Status     ApplyEventStatus
 	Resource   *unstructured.Unstructured
 	Error      error
+	// Strategy is how the object was applied. Only set for successful
+	// applies.
+	Strategy ApplyStrategy
 }
 
 // String returns a string suitable for logging
@@ -261,6 +324,7 @@ const (
 	PruneSuccessful                         // Successful
 	PruneSkipped                            // Skipped
 	PruneFailed                             // Failed
+	PruneDetached                           // Detached
 )
 
 type PruneEvent struct {
@@ -289,6 +353,7 @@ const (
 	DeleteSuccessful                          // Successful
 	DeleteSkipped                             // Skipped
 	DeleteFailed                              // Failed
+	DeleteDetached                            // Detached
 )
 
 type DeleteEvent struct {
@@ -323,3 +388,192 @@ func (ve ValidationEvent) String() string {
 	return fmt.Sprintf("ValidationEvent{ Identifiers: %+v }",
 		ve.Identifiers)
 }
+
+// DiffEvent contains the differences between the live object and the
+// result of a server-side dry-run apply, computed before the object is
+// applied.
+type DiffEvent struct {
+	GroupName  string
+	Identifier object.ObjMetadata
+	Diffs      []diff.FieldDiff
+	Error      error
+}
+
+// String returns a string suitable for logging
+func (de DiffEvent) String() string {
+	if de.Error != nil {
+		return fmt.Sprintf("DiffEvent{ GroupName: %q, Identifier: %q, Error: %q }",
+			de.GroupName, de.Identifier, de.Error)
+	}
+	return fmt.Sprintf("DiffEvent{ GroupName: %q, Identifier: %q, Diffs: %v }",
+		de.GroupName, de.Identifier, de.Diffs)
+}
+
+// AdoptEvent reports that a live object without an owning inventory was
+// claimed by the inventory.
+type AdoptEvent struct {
+	Identifier object.ObjMetadata
+	// Error is set if the object was not adopted, because it is owned by
+	// another inventory.
+	Error error
+}
+
+// String returns a string suitable for logging
+func (ae AdoptEvent) String() string {
+	if ae.Error != nil {
+		return fmt.Sprintf("AdoptEvent{ Identifier: %q, Error: %q }", ae.Identifier, ae.Error)
+	}
+	return fmt.Sprintf("AdoptEvent{ Identifier: %q }", ae.Identifier)
+}
+
+// TraceEvent explains why an object was assigned to a task group. Objects
+// are applied after the objects they depend on, and pruned after the
+// objects that depend on them.
+type TraceEvent struct {
+	GroupName  string
+	Identifier object.ObjMetadata
+	// After are the objects in earlier task groups of the same action that
+	// this object is ordered after. Empty if the object has no dependencies.
+	After []TraceDependency
+}
+
+// String returns a string suitable for logging
+func (te TraceEvent) String() string {
+	return fmt.Sprintf("TraceEvent{ GroupName: %q, Identifier: %q, After: %v }",
+		te.GroupName, te.Identifier, te.After)
+}
+
+// TraceDependency is an object another object is ordered after, with the
+// reasons of the dependency, e.g. "depends-on" or "namespace".
+type TraceDependency struct {
+	Identifier object.ObjMetadata
+	Reasons    []string
+}
+
+// ConflictEvent is sent before the ApplyEvent of an object that failed to
+// be applied, because fields of the object are owned by other field
+// managers.
+type ConflictEvent struct {
+	GroupName  string
+	Identifier object.ObjMetadata
+	Conflicts  []FieldConflict
+}
+
+// String returns a string suitable for logging
+func (ce ConflictEvent) String() string {
+	return fmt.Sprintf("ConflictEvent{ GroupName: %q, Identifier: %q, Conflicts: %v }",
+		ce.GroupName, ce.Identifier, ce.Conflicts)
+}
+
+// FieldConflict is a field owned by another field manager.
+type FieldConflict struct {
+	// Manager is the field manager that owns the field.
+	Manager string
+	// Field is the path of the field, e.g. ".spec.replicas".
+	Field string
+}
+
+//go:generate stringer -type=RollbackOperation -linecomment
+type RollbackOperation int
+
+const (
+	// RollbackDelete deletes an object created by the failed apply.
+	RollbackDelete RollbackOperation = iota // Delete
+	// RollbackRevert restores the state of an object from before the
+	// failed apply.
+	RollbackRevert // Revert
+)
+
+//go:generate stringer -type=RollbackEventStatus -linecomment
+type RollbackEventStatus int
+
+const (
+	RollbackSuccessful RollbackEventStatus = iota // Successful
+	RollbackFailed                                // Failed
+)
+
+// RollbackEvent is sent for each object applied by a failed apply, when
+// rollback is enabled.
+type RollbackEvent struct {
+	Identifier object.ObjMetadata
+	Operation  RollbackOperation
+	Status     RollbackEventStatus
+	Error      error
+}
+
+// String returns a string suitable for logging
+func (re RollbackEvent) String() string {
+	if re.Error != nil {
+		return fmt.Sprintf("RollbackEvent{ Operation: %q, Status: %q, Identifier: %q, Error: %q }",
+			re.Operation, re.Status, re.Identifier, re.Error)
+	}
+	return fmt.Sprintf("RollbackEvent{ Operation: %q, Status: %q, Identifier: %q }",
+		re.Operation, re.Status, re.Identifier)
+}
+
+//go:generate stringer -type=DriftStatus -linecomment
+type DriftStatus int
+
+const (
+	// DriftInSync objects would not change if they were applied.
+	DriftInSync DriftStatus = iota // InSync
+	// DriftModified objects were changed since they were applied, e.g. by
+	// other field managers, so applying them would change them.
+	DriftModified // Modified
+	// DriftDeleted objects are in the inventory, but were deleted from the
+	// cluster out-of-band.
+	DriftDeleted // Deleted
+	// DriftNotApplied objects are neither in the inventory nor in the
+	// cluster.
+	DriftNotApplied // NotApplied
+	// DriftFailed objects could not be compared with the live object.
+	DriftFailed // Failed
+)
+
+// DriftEvent reports whether a live object has drifted from the state it
+// would have after being applied.
+type DriftEvent struct {
+	Identifier object.ObjMetadata
+	Status     DriftStatus
+	// Diffs are the fields that applying the object would change. Only set
+	// if the object was modified.
+	Diffs []diff.FieldDiff
+	// Managers are the field managers of the live object that manage any
+	// field that applying the object would take over. Only set if the
+	// object was modified.
+	Managers []string
+	Error    error
+}
+
+// String returns a string suitable for logging
+func (de DriftEvent) String() string {
+	if de.Error != nil {
+		return fmt.Sprintf("DriftEvent{ Status: %q, Identifier: %q, Error: %q }",
+			de.Status, de.Identifier, de.Error)
+	}
+	return fmt.Sprintf("DriftEvent{ Status: %q, Identifier: %q, Diffs: %v, Managers: %q }",
+		de.Status, de.Identifier, de.Diffs, de.Managers)
+}
+
+// PlanSummaryEvent summarizes the changes a run would make, computed with a
+// server-side dry-run apply of each object before anything is actuated.
+type PlanSummaryEvent struct {
+	// Create are the objects that do not exist yet.
+	Create object.ObjMetadataSet
+	// Configure are the objects that applying would change.
+	Configure object.ObjMetadataSet
+	// Unchanged are the objects that applying would not change.
+	Unchanged object.ObjMetadataSet
+	// Prune are the objects of the inventory that would be pruned, unless
+	// a prune filter skips them.
+	Prune object.ObjMetadataSet
+	// Errors are the errors of the objects whose dry-run failed. These
+	// objects are not in any of the other sets.
+	Errors map[object.ObjMetadata]error
+}
+
+// String returns a string suitable for logging
+func (pe PlanSummaryEvent) String() string {
+	return fmt.Sprintf("PlanSummaryEvent{ Create: %d, Configure: %d, Unchanged: %d, Prune: %d, Errors: %d }",
+		len(pe.Create), len(pe.Configure), len(pe.Unchanged), len(pe.Prune), len(pe.Errors))
+}
@@ -12,11 +12,12 @@ func _() {
 	_ = x[PruneSuccessful-1]
 	_ = x[PruneSkipped-2]
 	_ = x[PruneFailed-3]
+	_ = x[PruneDetached-4]
 }
 
-const _PruneEventStatus_name = "PendingSuccessfulSkippedFailed"
+const _PruneEventStatus_name = "PendingSuccessfulSkippedFailedDetached"
 
-var _PruneEventStatus_index = [...]uint8{0, 7, 17, 24, 30}
+var _PruneEventStatus_index = [...]uint8{0, 7, 17, 24, 30, 38}
 
 func (i PruneEventStatus) String() string {
 	if i < 0 || i >= PruneEventStatus(len(_PruneEventStatus_index)-1) {
@@ -17,11 +17,18 @@ func _() {
 	_ = x[DeleteType-6]
 	_ = x[WaitType-7]
 	_ = x[ValidationType-8]
+	_ = x[DiffType-9]
+	_ = x[AdoptType-10]
+	_ = x[TraceType-11]
+	_ = x[ConflictType-12]
+	_ = x[RollbackType-13]
+	_ = x[DriftType-14]
+	_ = x[PlanSummaryType-15]
 }
 
-const _Type_name = "InitTypeErrorTypeActionGroupTypeApplyTypeStatusTypePruneTypeDeleteTypeWaitTypeValidationType"
+const _Type_name = "InitTypeErrorTypeActionGroupTypeApplyTypeStatusTypePruneTypeDeleteTypeWaitTypeValidationTypeDiffTypeAdoptTypeTraceTypeConflictTypeRollbackTypeDriftTypePlanSummaryType"
 
-var _Type_index = [...]uint8{0, 8, 17, 32, 41, 51, 60, 70, 78, 92}
+var _Type_index = [...]uint8{0, 8, 17, 32, 41, 51, 60, 70, 78, 92, 100, 109, 118, 130, 142, 151, 166}
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_Type_index)-1) {
@@ -0,0 +1,128 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fake provides an Applier and a Destroyer that send scripted
+// events instead of changing a cluster, to unit test code that drives an
+// apply.Applier or an apply.Destroyer through the apply.ApplyRunner and
+// apply.DestroyRunner interfaces.
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"sigs.k8s.io/cli-utils/pkg/apply"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// ApplyCall is a call of Run or Prune of the fake Applier.
+type ApplyCall struct {
+	// Prune is true for calls of Prune.
+	Prune     bool
+	Inventory inventory.Info
+	Objects   object.UnstructuredSet
+	Options   apply.ApplierOptions
+}
+
+// Applier is a fake apply.ApplyRunner. Each call of Run or Prune sends the
+// events of the next script; once all scripts were sent, the last script is
+// sent again. Without scripts, the event channel is closed immediately.
+type Applier struct {
+	// Scripts are the events of the calls, in order. See Script to build
+	// the events of common scenarios.
+	Scripts [][]event.Event
+
+	mu    sync.Mutex
+	calls []ApplyCall
+}
+
+var _ apply.ApplyRunner = &Applier{}
+
+// Run records the call and sends the events of the next script.
+func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects object.UnstructuredSet, options apply.ApplierOptions) <-chan event.Event {
+	return a.call(ctx, ApplyCall{Inventory: invInfo, Objects: objects, Options: options})
+}
+
+// Prune records the call and sends the events of the next script.
+func (a *Applier) Prune(ctx context.Context, invInfo inventory.Info, objects object.UnstructuredSet, options apply.ApplierOptions) <-chan event.Event {
+	return a.call(ctx, ApplyCall{Prune: true, Inventory: invInfo, Objects: objects, Options: options})
+}
+
+func (a *Applier) call(ctx context.Context, c ApplyCall) <-chan event.Event {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	events := nextScript(a.Scripts, len(a.calls))
+	a.calls = append(a.calls, c)
+	return send(ctx, events)
+}
+
+// Calls returns the calls of Run and Prune, in order.
+func (a *Applier) Calls() []ApplyCall {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]ApplyCall(nil), a.calls...)
+}
+
+// DestroyCall is a call of Run of the fake Destroyer.
+type DestroyCall struct {
+	Inventory inventory.Info
+	Options   apply.DestroyerOptions
+}
+
+// Destroyer is a fake apply.DestroyRunner. Each call of Run sends the
+// events of the next script, like the fake Applier.
+type Destroyer struct {
+	// Scripts are the events of the calls, in order.
+	Scripts [][]event.Event
+
+	mu    sync.Mutex
+	calls []DestroyCall
+}
+
+var _ apply.DestroyRunner = &Destroyer{}
+
+// Run records the call and sends the events of the next script.
+func (d *Destroyer) Run(ctx context.Context, invInfo inventory.Info, options apply.DestroyerOptions) <-chan event.Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	events := nextScript(d.Scripts, len(d.calls))
+	d.calls = append(d.calls, DestroyCall{Inventory: invInfo, Options: options})
+	return send(ctx, events)
+}
+
+// Calls returns the calls of Run, in order.
+func (d *Destroyer) Calls() []DestroyCall {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]DestroyCall(nil), d.calls...)
+}
+
+// nextScript returns the script of the call with the index.
+func nextScript(scripts [][]event.Event, i int) []event.Event {
+	if len(scripts) == 0 {
+		return nil
+	}
+	if i >= len(scripts) {
+		i = len(scripts) - 1
+	}
+	return scripts[i]
+}
+
+// send sends the events to the returned channel, and closes it once all
+// events were sent or the context is done.
+func send(ctx context.Context, events []event.Event) <-chan event.Event {
+	eventChannel := make(chan event.Event)
+	go func() {
+		defer close(eventChannel)
+		for _, e := range events {
+			select {
+			case eventChannel <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return eventChannel
+}
@@ -0,0 +1,125 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fake
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/apply"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func deployment(name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("apps/v1")
+	u.SetKind("Deployment")
+	u.SetName(name)
+	u.SetNamespace("default")
+	return u
+}
+
+func invInfo() inventory.Info {
+	inv := &unstructured.Unstructured{}
+	inv.SetAPIVersion("v1")
+	inv.SetKind("ConfigMap")
+	inv.SetName("inventory")
+	inv.SetNamespace("default")
+	inv.SetLabels(map[string]string{common.InventoryLabel: "test"})
+	return inventory.WrapInventoryInfoObj(inv)
+}
+
+func collect(ch <-chan event.Event) []event.Event {
+	var events []event.Event
+	for e := range ch {
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestApplier(t *testing.T) {
+	obj := deployment("foo")
+	id := object.UnstructuredToObjMetadata(obj)
+	inv := invInfo()
+	testErr := errors.New("forbidden")
+	applier := &Applier{
+		Scripts: [][]event.Event{
+			SuccessfulApply(obj),
+			NewScript().ApplyFailed(testErr, obj).Error(testErr).Events(),
+		},
+	}
+
+	events := collect(applier.Run(context.TODO(), inv, object.UnstructuredSet{obj}, apply.ApplierOptions{}))
+	assert.NoError(t, testutil.VerifyEvents([]testutil.ExpEvent{
+		{EventType: event.InitType},
+		{EventType: event.ActionGroupType, ActionGroupEvent: &testutil.ExpActionGroupEvent{
+			GroupName: "apply-0", Action: event.ApplyAction, Type: event.Started}},
+		{EventType: event.ApplyType, ApplyEvent: &testutil.ExpApplyEvent{
+			GroupName: "apply-0", Status: event.ApplySuccessful, Identifier: id}},
+		{EventType: event.ActionGroupType, ActionGroupEvent: &testutil.ExpActionGroupEvent{
+			GroupName: "apply-0", Action: event.ApplyAction, Type: event.Finished}},
+		{EventType: event.ActionGroupType, ActionGroupEvent: &testutil.ExpActionGroupEvent{
+			GroupName: "wait-0", Action: event.WaitAction, Type: event.Started}},
+		{EventType: event.WaitType, WaitEvent: &testutil.ExpWaitEvent{
+			GroupName: "wait-0", Status: event.ReconcileSuccessful, Identifier: id}},
+		{EventType: event.ActionGroupType, ActionGroupEvent: &testutil.ExpActionGroupEvent{
+			GroupName: "wait-0", Action: event.WaitAction, Type: event.Finished}},
+	}, events))
+	assert.Equal(t, event.ActionGroupList{
+		{Name: "apply-0", Action: event.ApplyAction, Identifiers: object.ObjMetadataSet{id}},
+		{Name: "wait-0", Action: event.WaitAction, Identifiers: object.ObjMetadataSet{id}},
+	}, events[0].InitEvent.ActionGroups)
+
+	// The last script is repeated.
+	for i := 0; i < 2; i++ {
+		events = collect(applier.Prune(context.TODO(), inv, nil, apply.ApplierOptions{}))
+		assert.Equal(t, event.ErrorType, events[len(events)-1].Type)
+		assert.Equal(t, testErr, events[len(events)-1].ErrorEvent.Err)
+	}
+
+	calls := applier.Calls()
+	assert.Len(t, calls, 3)
+	assert.False(t, calls[0].Prune)
+	assert.Equal(t, object.UnstructuredSet{obj}, calls[0].Objects)
+	assert.True(t, calls[1].Prune)
+}
+
+func TestApplier_Cancel(t *testing.T) {
+	applier := &Applier{
+		Scripts: [][]event.Event{SuccessfulApply(deployment("foo"))},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := applier.Run(ctx, invInfo(), nil, apply.ApplierOptions{})
+	<-ch
+	cancel()
+	// The channel is closed without sending the remaining events.
+	collect(ch)
+}
+
+func TestDestroyer(t *testing.T) {
+	id := object.UnstructuredToObjMetadata(deployment("foo"))
+	destroyer := &Destroyer{
+		Scripts: [][]event.Event{SuccessfulDestroy(id)},
+	}
+	options := apply.DestroyerOptions{DryRunStrategy: common.DryRunServer}
+	events := collect(destroyer.Run(context.TODO(), invInfo(), options))
+	assert.Len(t, events, 7)
+	assert.Equal(t, event.DeleteEvent{
+		GroupName:  "delete-0",
+		Identifier: id,
+		Status:     event.DeleteSuccessful,
+	}, events[2].DeleteEvent)
+	assert.Equal(t, []DestroyCall{{Inventory: invInfo(), Options: options}},
+		destroyer.Calls())
+
+	// Without scripts, the channel is closed immediately.
+	assert.Empty(t, collect((&Destroyer{}).Run(context.TODO(), nil, options)))
+}
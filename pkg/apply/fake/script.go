@@ -0,0 +1,173 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fake
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Script builds the events of a run, like they are sent by the Applier or
+// the Destroyer: an InitEvent with the action groups, followed by the events
+// of each action group, framed by its Started and Finished events. Groups
+// are named like the tasks of the solver, e.g. "apply-0" and "wait-0".
+type Script struct {
+	groups   event.ActionGroupList
+	events   []event.Event
+	counters map[string]int
+}
+
+// NewScript returns an empty Script.
+func NewScript() *Script {
+	return &Script{counters: make(map[string]int)}
+}
+
+// Apply adds an apply group with a successful apply of each object.
+func (s *Script) Apply(objs ...*unstructured.Unstructured) *Script {
+	return s.apply(event.ApplySuccessful, nil, objs)
+}
+
+// ApplyFailed adds an apply group with a failed apply of each object.
+func (s *Script) ApplyFailed(err error, objs ...*unstructured.Unstructured) *Script {
+	return s.apply(event.ApplyFailed, err, objs)
+}
+
+func (s *Script) apply(status event.ApplyEventStatus, err error, objs []*unstructured.Unstructured) *Script {
+	ids := object.UnstructuredSetToObjMetadataSet(objs)
+	name := s.group("apply", event.ApplyAction, ids)
+	for i, obj := range objs {
+		s.events = append(s.events, event.Event{
+			Type: event.ApplyType,
+			ApplyEvent: event.ApplyEvent{
+				GroupName:  name,
+				Identifier: ids[i],
+				Status:     status,
+				Resource:   obj,
+				Error:      err,
+			},
+		})
+	}
+	return s.finish(name, event.ApplyAction)
+}
+
+// Wait adds a wait group with the reconcile status of each object.
+func (s *Script) Wait(status event.WaitEventStatus, ids ...object.ObjMetadata) *Script {
+	name := s.group("wait", event.WaitAction, ids)
+	for _, id := range ids {
+		s.events = append(s.events, event.Event{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  name,
+				Identifier: id,
+				Status:     status,
+			},
+		})
+	}
+	return s.finish(name, event.WaitAction)
+}
+
+// Prune adds a prune group with the prune status of each object.
+func (s *Script) Prune(status event.PruneEventStatus, ids ...object.ObjMetadata) *Script {
+	name := s.group("prune", event.PruneAction, ids)
+	for _, id := range ids {
+		s.events = append(s.events, event.Event{
+			Type: event.PruneType,
+			PruneEvent: event.PruneEvent{
+				GroupName:  name,
+				Identifier: id,
+				Status:     status,
+			},
+		})
+	}
+	return s.finish(name, event.PruneAction)
+}
+
+// Delete adds a delete group with the delete status of each object.
+func (s *Script) Delete(status event.DeleteEventStatus, ids ...object.ObjMetadata) *Script {
+	name := s.group("delete", event.DeleteAction, ids)
+	for _, id := range ids {
+		s.events = append(s.events, event.Event{
+			Type: event.DeleteType,
+			DeleteEvent: event.DeleteEvent{
+				GroupName:  name,
+				Identifier: id,
+				Status:     status,
+			},
+		})
+	}
+	return s.finish(name, event.DeleteAction)
+}
+
+// Error adds an ErrorEvent, which ends a run.
+func (s *Script) Error(err error) *Script {
+	s.events = append(s.events, event.Event{
+		Type:       event.ErrorType,
+		ErrorEvent: event.ErrorEvent{Err: err},
+	})
+	return s
+}
+
+// Events returns the events of the run.
+func (s *Script) Events() []event.Event {
+	events := make([]event.Event, 0, len(s.events)+1)
+	events = append(events, event.Event{
+		Type:      event.InitType,
+		InitEvent: event.InitEvent{ActionGroups: s.groups},
+	})
+	return append(events, s.events...)
+}
+
+// group adds an action group, and its Started event, and returns its name.
+func (s *Script) group(prefix string, action event.ResourceAction, ids object.ObjMetadataSet) string {
+	name := fmt.Sprintf("%s-%d", prefix, s.counters[prefix])
+	s.counters[prefix]++
+	s.groups = append(s.groups, event.ActionGroup{
+		Name:        name,
+		Action:      action,
+		Identifiers: ids,
+	})
+	s.events = append(s.events, event.Event{
+		Type: event.ActionGroupType,
+		ActionGroupEvent: event.ActionGroupEvent{
+			GroupName: name,
+			Action:    action,
+			Status:    event.Started,
+		},
+	})
+	return name
+}
+
+// finish adds the Finished event of the action group.
+func (s *Script) finish(name string, action event.ResourceAction) *Script {
+	s.events = append(s.events, event.Event{
+		Type: event.ActionGroupType,
+		ActionGroupEvent: event.ActionGroupEvent{
+			GroupName: name,
+			Action:    action,
+			Status:    event.Finished,
+		},
+	})
+	return s
+}
+
+// SuccessfulApply returns the events of a run that applies the objects,
+// which then all reconcile.
+func SuccessfulApply(objs ...*unstructured.Unstructured) []event.Event {
+	return NewScript().
+		Apply(objs...).
+		Wait(event.ReconcileSuccessful, object.UnstructuredSetToObjMetadataSet(objs)...).
+		Events()
+}
+
+// SuccessfulDestroy returns the events of a run that deletes the objects,
+// which are then all deleted from the cluster.
+func SuccessfulDestroy(ids ...object.ObjMetadata) []event.Event {
+	return NewScript().
+		Delete(event.DeleteSuccessful, ids...).
+		Wait(event.ReconcileSuccessful, ids...).
+		Events()
+}
@@ -9,6 +9,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/cli-utils/pkg/reason"
 )
 
 // CurrentUIDFilter implements ValidationFilter interface to determine
@@ -51,3 +52,11 @@ func (e *ApplyPreventedDeletionError) Is(err error) bool {
 	}
 	return e.UID == tErr.UID
 }
+
+func (e *ApplyPreventedDeletionError) ErrorReason() reason.Reason {
+	return reason.CurrentlyApplied
+}
+
+func (e *ApplyPreventedDeletionError) ErrorRetryable() bool {
+	return false
+}
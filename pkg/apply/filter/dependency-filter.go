@@ -12,6 +12,7 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/reason"
 )
 
 //go:generate stringer -type=Relationship -linecomment
@@ -36,6 +37,10 @@ type DependencyFilter struct {
 	TaskContext       *taskrunner.TaskContext
 	ActuationStrategy actuation.ActuationStrategy
 	DryRunStrategy    common.DryRunStrategy
+	// ContinueOnError allows objects to be actuated even if one of their
+	// dependencies failed to actuate or reconcile. Skipped dependencies
+	// still cause their dependents to be skipped.
+	ContinueOnError bool
 }
 
 const DependencyFilterName = "DependencyFilter"
@@ -62,6 +67,12 @@ func (dnrf DependencyFilter) Filter(obj *unstructured.Unstructured) error {
 				return err
 			}
 		}
+		for _, depID := range dnrf.TaskContext.Graph().ExternalDependencies(id) {
+			err := dnrf.filterByExternalDependency(id, depID)
+			if err != nil {
+				return err
+			}
+		}
 	case actuation.ActuationStrategyDelete:
 		// For delete, check dependents (incoming)
 		for _, depID := range dnrf.TaskContext.Graph().Dependents(id) {
@@ -91,7 +102,8 @@ func (dnrf DependencyFilter) filterByRelationship(aID, bID object.ObjMetadata, r
 	status, found := dnrf.TaskContext.InventoryManager().ObjectStatus(bID)
 	if !found {
 		// Status is registered during planning.
-		// So if status is not found, the object is external (NYI) or invalid.
+		// So if status is not found, the object is invalid. External
+		// dependencies are not in the graph, see filterByExternalDependency.
 		// Should have been caught in validation.
 		return NewFatalError(fmt.Errorf("unknown %s actuation strategy: %s",
 			strings.ToLower(relationship.String()), bID))
@@ -119,7 +131,23 @@ func (dnrf DependencyFilter) filterByRelationship(aID, bID object.ObjMetadata, r
 			strings.ToLower(status.Strategy.String()),
 			strings.ToLower(status.Actuation.String()),
 			bID))
-	case actuation.ActuationSkipped, actuation.ActuationFailed:
+	case actuation.ActuationFailed:
+		if dnrf.ContinueOnError {
+			// Don't skip! Reconcile is skipped for failed objects, so
+			// don't check it either.
+			return nil
+		}
+		// Skip!
+		return &DependencyPreventedActuationError{
+			Object:                  aID,
+			Strategy:                dnrf.ActuationStrategy,
+			Relationship:            relationship,
+			Relation:                bID,
+			RelationPhase:           PhaseActuation,
+			RelationActuationStatus: status.Actuation,
+			RelationReconcileStatus: status.Reconcile,
+		}
+	case actuation.ActuationSkipped:
 		// Skip!
 		return &DependencyPreventedActuationError{
 			Object:                  aID,
@@ -155,7 +183,26 @@ func (dnrf DependencyFilter) filterByRelationship(aID, bID object.ObjMetadata, r
 			strings.ToLower(status.Strategy.String()),
 			strings.ToLower(status.Reconcile.String()),
 			bID))
-	case actuation.ReconcileSkipped, actuation.ReconcileFailed, actuation.ReconcileTimeout:
+	case actuation.ReconcileFailed, actuation.ReconcileTimeout:
+		if dnrf.ContinueOnError {
+			// Don't skip!
+			return nil
+		}
+		// Skip!
+		return &DependencyPreventedActuationError{
+			Object:                  aID,
+			Strategy:                dnrf.ActuationStrategy,
+			Relationship:            relationship,
+			Relation:                bID,
+			RelationPhase:           PhaseReconcile,
+			RelationActuationStatus: status.Actuation,
+			RelationReconcileStatus: status.Reconcile,
+		}
+	case actuation.ReconcileSkipped:
+		if dnrf.TaskContext.IsSkipWaitObject(bID) {
+			// Applied, but not waited for. Don't skip!
+			return nil
+		}
 		// Skip!
 		return &DependencyPreventedActuationError{
 			Object:                  aID,
@@ -180,6 +227,25 @@ func (dnrf DependencyFilter) filterByRelationship(aID, bID object.ObjMetadata, r
 	return nil
 }
 
+// filterByExternalDependency returns an error if the external dependency,
+// which is not managed by the inventory, was not found ready by the
+// ExternalWaitTask.
+func (dnrf DependencyFilter) filterByExternalDependency(id, depID object.ObjMetadata) error {
+	// DryRun skips WaitTasks, so readiness can be ignored
+	if dnrf.DryRunStrategy.ClientOrServerDryRun() || dnrf.ContinueOnError {
+		// Don't skip!
+		return nil
+	}
+	if !dnrf.TaskContext.IsReadyExternalObject(depID) {
+		// Skip!
+		return &ExternalDependencyNotReadyError{
+			Object:   id,
+			Relation: depID,
+		}
+	}
+	return nil
+}
+
 type DependencyPreventedActuationError struct {
 	Object       object.ObjMetadata
 	Strategy     actuation.ActuationStrategy
@@ -229,6 +295,14 @@ func (e *DependencyPreventedActuationError) Is(err error) bool {
 		e.RelationReconcileStatus == tErr.RelationReconcileStatus
 }
 
+func (e *DependencyPreventedActuationError) ErrorReason() reason.Reason {
+	return reason.DependencyNotReady
+}
+
+func (e *DependencyPreventedActuationError) ErrorRetryable() bool {
+	return true
+}
+
 type DependencyActuationMismatchError struct {
 	Object       object.ObjMetadata
 	Strategy     actuation.ActuationStrategy
@@ -259,3 +333,42 @@ func (e *DependencyActuationMismatchError) Is(err error) bool {
 		e.Relation == tErr.Relation &&
 		e.RelationStrategy == tErr.RelationStrategy
 }
+
+func (e *DependencyActuationMismatchError) ErrorReason() reason.Reason {
+	return reason.DependencyMismatch
+}
+
+func (e *DependencyActuationMismatchError) ErrorRetryable() bool {
+	return false
+}
+
+// ExternalDependencyNotReadyError means that an external dependency, which
+// is not managed by the inventory, did not become ready in time.
+type ExternalDependencyNotReadyError struct {
+	Object   object.ObjMetadata
+	Relation object.ObjMetadata
+}
+
+func (e *ExternalDependencyNotReadyError) Error() string {
+	return fmt.Sprintf("external dependency not ready: %s", e.Relation)
+}
+
+func (e *ExternalDependencyNotReadyError) Is(err error) bool {
+	if err == nil {
+		return false
+	}
+	tErr, ok := err.(*ExternalDependencyNotReadyError)
+	if !ok {
+		return false
+	}
+	return e.Object == tErr.Object &&
+		e.Relation == tErr.Relation
+}
+
+func (e *ExternalDependencyNotReadyError) ErrorReason() reason.Reason {
+	return reason.DependencyNotReady
+}
+
+func (e *ExternalDependencyNotReadyError) ErrorRetryable() bool {
+	return true
+}
@@ -7,12 +7,14 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/graph"
 	"sigs.k8s.io/cli-utils/pkg/testutil"
 )
 
@@ -44,6 +46,7 @@ var idB = object.ObjMetadata{
 func TestDependencyFilter(t *testing.T) {
 	tests := map[string]struct {
 		dryRunStrategy    common.DryRunStrategy
+		continueOnError   bool
 		actuationStrategy actuation.ActuationStrategy
 		contextSetup      func(*taskrunner.TaskContext)
 		id                object.ObjMetadata
@@ -525,6 +528,88 @@ func TestDependencyFilter(t *testing.T) {
 			id:            idB,
 			expectedError: nil,
 		},
+		"apply A (A -> B) when B apply failed, with ContinueOnError": {
+			actuationStrategy: actuation.ActuationStrategyApply,
+			continueOnError:   true,
+			contextSetup: func(taskContext *taskrunner.TaskContext) {
+				taskContext.Graph().AddVertex(idA)
+				taskContext.Graph().AddVertex(idB)
+				taskContext.Graph().AddEdge(idA, idB)
+				taskContext.InventoryManager().AddPendingApply(idA)
+				taskContext.InventoryManager().SetObjectStatus(actuation.ObjectStatus{
+					ObjectReference: inventory.ObjectReferenceFromObjMetadata(idB),
+					Strategy:        actuation.ActuationStrategyApply,
+					Actuation:       actuation.ActuationFailed,
+					Reconcile:       actuation.ReconcileSkipped,
+				})
+			},
+			id:            idA,
+			expectedError: nil,
+		},
+		"apply A (A -> B) when B reconcile timed out, with ContinueOnError": {
+			actuationStrategy: actuation.ActuationStrategyApply,
+			continueOnError:   true,
+			contextSetup: func(taskContext *taskrunner.TaskContext) {
+				taskContext.Graph().AddVertex(idA)
+				taskContext.Graph().AddVertex(idB)
+				taskContext.Graph().AddEdge(idA, idB)
+				taskContext.InventoryManager().AddPendingApply(idA)
+				taskContext.InventoryManager().SetObjectStatus(actuation.ObjectStatus{
+					ObjectReference: inventory.ObjectReferenceFromObjMetadata(idB),
+					Strategy:        actuation.ActuationStrategyApply,
+					Actuation:       actuation.ActuationSucceeded,
+					Reconcile:       actuation.ReconcileTimeout,
+				})
+			},
+			id:            idA,
+			expectedError: nil,
+		},
+		"apply A (A -> B) when B reconcile skipped by annotation": {
+			actuationStrategy: actuation.ActuationStrategyApply,
+			contextSetup: func(taskContext *taskrunner.TaskContext) {
+				taskContext.Graph().AddVertex(idA)
+				taskContext.Graph().AddVertex(idB)
+				taskContext.Graph().AddEdge(idA, idB)
+				taskContext.InventoryManager().AddPendingApply(idA)
+				taskContext.InventoryManager().SetObjectStatus(actuation.ObjectStatus{
+					ObjectReference: inventory.ObjectReferenceFromObjMetadata(idB),
+					Strategy:        actuation.ActuationStrategyApply,
+					Actuation:       actuation.ActuationSucceeded,
+					Reconcile:       actuation.ReconcileSkipped,
+				})
+				taskContext.AddSkipWaitObject(idB)
+			},
+			id:            idA,
+			expectedError: nil,
+		},
+		"apply A (A -> B) when B apply skipped, with ContinueOnError": {
+			actuationStrategy: actuation.ActuationStrategyApply,
+			continueOnError:   true,
+			contextSetup: func(taskContext *taskrunner.TaskContext) {
+				taskContext.Graph().AddVertex(idA)
+				taskContext.Graph().AddVertex(idB)
+				taskContext.Graph().AddEdge(idA, idB)
+				taskContext.InventoryManager().AddPendingApply(idA)
+				taskContext.InventoryManager().SetObjectStatus(actuation.ObjectStatus{
+					ObjectReference: inventory.ObjectReferenceFromObjMetadata(idB),
+					Strategy:        actuation.ActuationStrategyApply,
+					Actuation:       actuation.ActuationSkipped,
+					Reconcile:       actuation.ReconcileSkipped,
+				})
+			},
+			id: idA,
+			expectedError: testutil.EqualError(
+				&DependencyPreventedActuationError{
+					Object:                  idA,
+					Strategy:                actuation.ActuationStrategyApply,
+					Relationship:            RelationshipDependency,
+					Relation:                idB,
+					RelationPhase:           PhaseActuation,
+					RelationActuationStatus: actuation.ActuationSkipped,
+					RelationReconcileStatus: actuation.ReconcileSkipped,
+				},
+			),
+		},
 	}
 
 	for name, tc := range tests {
@@ -536,6 +621,7 @@ func TestDependencyFilter(t *testing.T) {
 				TaskContext:       taskContext,
 				ActuationStrategy: tc.actuationStrategy,
 				DryRunStrategy:    tc.dryRunStrategy,
+				ContinueOnError:   tc.continueOnError,
 			}
 			obj := defaultObj.DeepCopy()
 			obj.SetGroupVersionKind(tc.id.GroupKind.WithVersion("v1"))
@@ -547,3 +633,59 @@ func TestDependencyFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestDependencyFilterExternalDependency(t *testing.T) {
+	obj := defaultObj.DeepCopy()
+	obj.SetGroupVersionKind(idA.GroupKind.WithVersion("v1"))
+	obj.SetName(idA.Name)
+	obj.SetNamespace(idA.Namespace)
+	testutil.Mutate(obj, testutil.AddDependsOn(t, idB))
+
+	tests := map[string]struct {
+		dryRunStrategy  common.DryRunStrategy
+		continueOnError bool
+		ready           bool
+		expectedError   error
+	}{
+		"apply A (A -> external B) when B is ready": {
+			ready: true,
+		},
+		"apply A (A -> external B) when B is not ready": {
+			expectedError: testutil.EqualError(
+				&ExternalDependencyNotReadyError{
+					Object:   idA,
+					Relation: idB,
+				},
+			),
+		},
+		"apply A (A -> external B) when B is not ready with continue on error": {
+			continueOnError: true,
+		},
+		"dry-run apply A (A -> external B) when B is not ready": {
+			dryRunStrategy: common.DryRunClient,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			taskContext := taskrunner.NewTaskContext(nil, nil)
+			g, err := graph.DependencyGraphWithOptions(object.UnstructuredSet{obj},
+				graph.Options{ExternalDependencies: true})
+			require.NoError(t, err)
+			taskContext.SetGraph(g)
+			taskContext.InventoryManager().AddPendingApply(idA)
+			if tc.ready {
+				taskContext.AddReadyExternalObject(idB)
+			}
+
+			filter := DependencyFilter{
+				TaskContext:       taskContext,
+				ActuationStrategy: actuation.ActuationStrategyApply,
+				DryRunStrategy:    tc.dryRunStrategy,
+				ContinueOnError:   tc.continueOnError,
+			}
+			err = filter.Filter(obj)
+			testutil.AssertEqual(t, tc.expectedError, err)
+		})
+	}
+}
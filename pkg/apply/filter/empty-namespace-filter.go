@@ -0,0 +1,162 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filter
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/reason"
+)
+
+// EmptyNamespaceFilter implements ValidationFilter interface to determine
+// if a Namespace generated by the applier should not be deleted, because
+// it still contains objects, e.g. objects that are not in the inventory.
+// Other namespaces are not filtered. Events, and the objects created in
+// every namespace by the control plane, like the default ServiceAccount and
+// the kube-root-ca.crt ConfigMap, are ignored.
+type EmptyNamespaceFilter struct {
+	Client    dynamic.Interface
+	Discovery discovery.DiscoveryInterface
+}
+
+// Name returns a filter identifier for logging.
+func (enf EmptyNamespaceFilter) Name() string {
+	return "EmptyNamespaceFilter"
+}
+
+// Filter returns a NamespaceNotEmptyError if the object prune/delete should
+// be skipped.
+func (enf EmptyNamespaceFilter) Filter(obj *unstructured.Unstructured) error {
+	id := object.UnstructuredToObjMetadata(obj)
+	if id.GroupKind != namespaceGK ||
+		obj.GetAnnotations()[common.NamespaceCreatedAnnotation] != common.NamespaceCreated {
+		return nil
+	}
+	resources, err := discovery.ServerPreferredNamespacedResources(enf.Discovery)
+	if err != nil && len(resources) == 0 {
+		return NewFatalError(fmt.Errorf("failed to discover namespaced resources: %w", err))
+	}
+	for _, list := range resources {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if !hasVerb(r, "list") {
+				continue
+			}
+			gvr := gv.WithResource(r.Name)
+			gr := gvr.GroupResource()
+			if eventResources[gr] {
+				continue
+			}
+			opts := metav1.ListOptions{}
+			if !systemResources[gr] {
+				// Any object of the resource means the namespace is not empty.
+				opts.Limit = 1
+			}
+			objs, err := enf.Client.Resource(gvr).Namespace(id.Name).List(context.TODO(), opts)
+			if err != nil {
+				if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) || apierrors.IsMethodNotSupported(err) {
+					continue
+				}
+				return NewFatalError(fmt.Errorf("failed to list %s in namespace %s: %w", gr, id.Name, err))
+			}
+			for i := range objs.Items {
+				if isSystemObject(gr, &objs.Items[i]) {
+					continue
+				}
+				return &NamespaceNotEmptyError{
+					Namespace: id.Name,
+					Resource:  gr.String(),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	// eventResources are the resources of Events, which are ignored.
+	eventResources = map[schema.GroupResource]bool{
+		{Resource: "events"}:                         true,
+		{Group: "events.k8s.io", Resource: "events"}: true,
+	}
+	// systemResources are the resources of the objects that the control
+	// plane creates in every namespace.
+	systemResources = map[schema.GroupResource]bool{
+		{Resource: "configmaps"}:      true,
+		{Resource: "serviceaccounts"}: true,
+		{Resource: "secrets"}:         true,
+	}
+)
+
+// isSystemObject returns true if the object is created in every namespace
+// by the control plane: the kube-root-ca.crt ConfigMap, the default
+// ServiceAccount, and the token Secret of the default ServiceAccount on
+// clusters that still generate them.
+func isSystemObject(gr schema.GroupResource, obj *unstructured.Unstructured) bool {
+	if !systemResources[gr] {
+		return false
+	}
+	switch gr.Resource {
+	case "configmaps":
+		return obj.GetName() == "kube-root-ca.crt"
+	case "serviceaccounts":
+		return obj.GetName() == "default"
+	case "secrets":
+		secretType, _, _ := unstructured.NestedString(obj.Object, "type")
+		return secretType == "kubernetes.io/service-account-token" &&
+			obj.GetAnnotations()["kubernetes.io/service-account.name"] == "default"
+	}
+	return false
+}
+
+func hasVerb(r metav1.APIResource, verb string) bool {
+	for _, v := range r.Verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+type NamespaceNotEmptyError struct {
+	Namespace string
+	// Resource is the resource of an object found in the namespace.
+	Resource string
+}
+
+func (e *NamespaceNotEmptyError) Error() string {
+	return fmt.Sprintf("namespace not empty: %s (contains %s)", e.Namespace, e.Resource)
+}
+
+func (e *NamespaceNotEmptyError) Is(err error) bool {
+	if err == nil {
+		return false
+	}
+	tErr, ok := err.(*NamespaceNotEmptyError)
+	if !ok {
+		return false
+	}
+	return e.Namespace == tErr.Namespace &&
+		e.Resource == tErr.Resource
+}
+
+func (e *NamespaceNotEmptyError) ErrorReason() reason.Reason {
+	return reason.NamespaceNotEmpty
+}
+
+func (e *NamespaceNotEmptyError) ErrorRetryable() bool {
+	return true
+}
@@ -0,0 +1,127 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestEmptyNamespaceFilter(t *testing.T) {
+	configMap := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "cm",
+				"namespace": "not-empty",
+			},
+		},
+	}
+	created := map[string]string{common.NamespaceCreatedAnnotation: common.NamespaceCreated}
+
+	// The objects of a namespace created by the control plane, and Events.
+	defaults := []runtime.Object{
+		namespacedObject("v1", "ConfigMap", "defaults", "kube-root-ca.crt"),
+		namespacedObject("v1", "ServiceAccount", "defaults", "default"),
+		namespacedObject("v1", "Event", "defaults", "default.1234"),
+		namespacedObject("events.k8s.io/v1", "Event", "defaults", "default.5678"),
+		func() runtime.Object {
+			secret := namespacedObject("v1", "Secret", "defaults", "default-token-abcde")
+			secret.Object["type"] = "kubernetes.io/service-account-token"
+			secret.SetAnnotations(map[string]string{"kubernetes.io/service-account.name": "default"})
+			return secret
+		}(),
+	}
+
+	tests := map[string]struct {
+		namespace     string
+		annotations   map[string]string
+		expectedError error
+	}{
+		"Empty generated namespace is not filtered": {
+			namespace:   "empty",
+			annotations: created,
+		},
+		"Generated namespace with the default objects is not filtered": {
+			namespace:   "defaults",
+			annotations: created,
+		},
+		"Non-empty generated namespace is filtered": {
+			namespace:   "not-empty",
+			annotations: created,
+			expectedError: &NamespaceNotEmptyError{
+				Namespace: "not-empty",
+				Resource:  "configmaps",
+			},
+		},
+		"Non-empty namespace that was not generated is not filtered": {
+			namespace: "not-empty",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{
+					{Version: "v1", Resource: "configmaps"}:                     "ConfigMapList",
+					{Version: "v1", Resource: "secrets"}:                        "SecretList",
+					{Version: "v1", Resource: "serviceaccounts"}:                "ServiceAccountList",
+					{Version: "v1", Resource: "events"}:                         "EventList",
+					{Group: "events.k8s.io", Version: "v1", Resource: "events"}: "EventList",
+				}, append([]runtime.Object{configMap}, defaults...)...)
+			disco := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{
+				Resources: []*metav1.APIResourceList{
+					{
+						GroupVersion: "v1",
+						APIResources: []metav1.APIResource{
+							{Name: "configmaps", Namespaced: true, Kind: "ConfigMap", Verbs: []string{"list"}},
+							{Name: "secrets", Namespaced: true, Kind: "Secret", Verbs: []string{"list"}},
+							{Name: "serviceaccounts", Namespaced: true, Kind: "ServiceAccount", Verbs: []string{"list"}},
+							{Name: "events", Namespaced: true, Kind: "Event", Verbs: []string{"list"}},
+							{Name: "namespaces", Namespaced: false, Kind: "Namespace", Verbs: []string{"list"}},
+						},
+					},
+					{
+						GroupVersion: "events.k8s.io/v1",
+						APIResources: []metav1.APIResource{
+							{Name: "events", Namespaced: true, Kind: "Event", Verbs: []string{"list"}},
+						},
+					},
+				},
+			}}
+			filter := EmptyNamespaceFilter{
+				Client:    client,
+				Discovery: disco,
+			}
+			obj := testNamespace.DeepCopy()
+			obj.SetName(tc.namespace)
+			obj.SetAnnotations(tc.annotations)
+			err := filter.Filter(obj)
+			testutil.AssertEqual(t, tc.expectedError, err)
+		})
+	}
+
+	// Objects other than namespaces are not filtered.
+	assert.NoError(t, EmptyNamespaceFilter{}.Filter(configMap))
+}
+
+func namespacedObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
@@ -17,6 +17,10 @@ func (e *FatalError) Error() string {
 	return e.Err.Error()
 }
 
+func (e *FatalError) Unwrap() error {
+	return e.Err
+}
+
 func (e *FatalError) Is(err error) bool {
 	if err == nil {
 		return false
@@ -24,6 +24,10 @@ type InventoryPolicyApplyFilter struct {
 	Mapper    meta.RESTMapper
 	Inv       inventory.Info
 	InvPolicy inventory.Policy
+	// DryRunAdoption is the result of a dry-run adoption of orphaned
+	// objects, if any. The adopted objects are not updated in the cluster,
+	// so they are checked as if they had the owning-inventory annotation.
+	DryRunAdoption *inventory.AdoptionResult
 }
 
 // Name returns a filter identifier for logging.
@@ -47,6 +51,14 @@ func (ipaf InventoryPolicyApplyFilter) Filter(obj *unstructured.Unstructured) er
 		}
 		return NewFatalError(fmt.Errorf("failed to get current object from cluster: %w", err))
 	}
+	if ipaf.DryRunAdoption != nil && ipaf.DryRunAdoption.Adopted.Contains(object.UnstructuredToObjMetadata(obj)) {
+		inventory.AddInventoryIDAnnotation(clusterObj, ipaf.Inv)
+	}
+	if IsPatchIfExists(obj) && inventory.IDMatch(ipaf.Inv, clusterObj) == inventory.Empty {
+		// Objects only patched by the applier are never deleted, so they
+		// can be adopted from other systems that don't use inventories.
+		return nil
+	}
 	_, err = inventory.CanApply(ipaf.Inv, clusterObj, ipaf.InvPolicy)
 	if err != nil {
 		return err
@@ -13,6 +13,7 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/cli-utils/pkg/testutil"
 )
 
@@ -31,8 +32,13 @@ func TestInventoryPolicyApplyFilter(t *testing.T) {
 	tests := map[string]struct {
 		inventoryID    string
 		objInventoryID string
-		policy         inventory.Policy
-		expectedError  error
+		// noObjInventory omits the inventory annotation of the object
+		noObjInventory bool
+		patchIfExists  bool
+		// dryRunAdopted marks the object as adopted by a dry-run
+		dryRunAdopted bool
+		policy        inventory.Policy
+		expectedError error
 	}{
 		"inventory and object ids match, not filtered": {
 			inventoryID:    "foo",
@@ -84,6 +90,29 @@ func TestInventoryPolicyApplyFilter(t *testing.T) {
 				Status:   inventory.NoMatch,
 			},
 		},
+		"object without inventory, patch-if-exists and policy must match, not filtered": {
+			inventoryID:    "foo",
+			noObjInventory: true,
+			patchIfExists:  true,
+			policy:         inventory.PolicyMustMatch,
+		},
+		"inventory and object ids do no match, patch-if-exists and policy must match, filtered and error": {
+			inventoryID:    "foo",
+			objInventoryID: "bar",
+			patchIfExists:  true,
+			policy:         inventory.PolicyMustMatch,
+			expectedError: &inventory.PolicyPreventedActuationError{
+				Strategy: actuation.ActuationStrategyApply,
+				Policy:   inventory.PolicyMustMatch,
+				Status:   inventory.NoMatch,
+			},
+		},
+		"object without inventory, dry-run adopted and policy must match, not filtered": {
+			inventoryID:    "foo",
+			noObjInventory: true,
+			dryRunAdopted:  true,
+			policy:         inventory.PolicyMustMatch,
+		},
 	}
 
 	for name, tc := range tests {
@@ -92,6 +121,12 @@ func TestInventoryPolicyApplyFilter(t *testing.T) {
 			objIDAnnotation := map[string]string{
 				"config.k8s.io/owning-inventory": tc.objInventoryID,
 			}
+			if tc.noObjInventory {
+				delete(objIDAnnotation, "config.k8s.io/owning-inventory")
+			}
+			if tc.patchIfExists {
+				objIDAnnotation[common.ActuationStrategyAnnotation] = common.PatchIfExists
+			}
 			obj.SetAnnotations(objIDAnnotation)
 			invIDLabel := map[string]string{
 				common.InventoryLabel: tc.inventoryID,
@@ -105,6 +140,11 @@ func TestInventoryPolicyApplyFilter(t *testing.T) {
 				Inv:       inventory.WrapInventoryInfoObj(invObj),
 				InvPolicy: tc.policy,
 			}
+			if tc.dryRunAdopted {
+				filter.DryRunAdoption = &inventory.AdoptionResult{
+					Adopted: object.ObjMetadataSet{object.UnstructuredToObjMetadata(obj)},
+				}
+			}
 			err := filter.Filter(obj)
 			testutil.AssertEqual(t, tc.expectedError, err)
 		})
@@ -10,6 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/reason"
 )
 
 var (
@@ -59,3 +60,11 @@ func (e *NamespaceInUseError) Is(err error) bool {
 	}
 	return e.Namespace == tErr.Namespace
 }
+
+func (e *NamespaceInUseError) ErrorReason() reason.Reason {
+	return reason.NamespaceInUse
+}
+
+func (e *NamespaceInUseError) ErrorRetryable() bool {
+	return false
+}
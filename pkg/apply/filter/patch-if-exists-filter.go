@@ -0,0 +1,109 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filter
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/reason"
+)
+
+// PatchIfExistsFilter implements ValidationFilter interface to determine
+// if an object should not be applied, because it has the patch-if-exists
+// actuation strategy and does not exist in the cluster.
+type PatchIfExistsFilter struct {
+	Client dynamic.Interface
+	Mapper meta.RESTMapper
+}
+
+const PatchIfExistsFilterName = "PatchIfExistsFilter"
+
+// Name returns a filter identifier for logging.
+func (pief PatchIfExistsFilter) Name() string {
+	return PatchIfExistsFilterName
+}
+
+// Filter returns a PatchTargetNotFoundError if the object has the
+// patch-if-exists actuation strategy and does not exist.
+func (pief PatchIfExistsFilter) Filter(obj *unstructured.Unstructured) error {
+	if !IsPatchIfExists(obj) {
+		return nil
+	}
+	id := object.UnstructuredToObjMetadata(obj)
+	mapping, err := pief.Mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		return NewFatalError(fmt.Errorf("failed to map object: %w", err))
+	}
+	_, err = pief.Client.Resource(mapping.Resource).Namespace(id.Namespace).
+		Get(context.TODO(), id.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return &PatchTargetNotFoundError{Object: id}
+		}
+		return NewFatalError(fmt.Errorf("failed to get current object from cluster: %w", err))
+	}
+	return nil
+}
+
+// ReadActuationStrategy returns the value of the actuation-strategy
+// annotation, or an empty string if the annotation is not present. Returns
+// an object.InvalidAnnotationError for unknown strategies.
+func ReadActuationStrategy(obj *unstructured.Unstructured) (string, error) {
+	strategy, found := obj.GetAnnotations()[common.ActuationStrategyAnnotation]
+	if !found {
+		return "", nil
+	}
+	if strategy != common.PatchIfExists {
+		return "", object.InvalidAnnotationError{
+			Annotation: common.ActuationStrategyAnnotation,
+			Cause:      fmt.Errorf("must be %q, got %q", common.PatchIfExists, strategy),
+		}
+	}
+	return strategy, nil
+}
+
+// IsPatchIfExists returns true if the object has the patch-if-exists
+// actuation strategy.
+func IsPatchIfExists(obj *unstructured.Unstructured) bool {
+	strategy, _ := ReadActuationStrategy(obj)
+	return strategy == common.PatchIfExists
+}
+
+// PatchTargetNotFoundError is returned for objects with the patch-if-exists
+// actuation strategy that do not exist, so they are not created.
+type PatchTargetNotFoundError struct {
+	Object object.ObjMetadata
+}
+
+func (e *PatchTargetNotFoundError) Error() string {
+	return fmt.Sprintf("object not found, and not created because of its actuation strategy (%q: %q)",
+		common.ActuationStrategyAnnotation, common.PatchIfExists)
+}
+
+func (e *PatchTargetNotFoundError) Is(err error) bool {
+	if err == nil {
+		return false
+	}
+	tErr, ok := err.(*PatchTargetNotFoundError)
+	if !ok {
+		return false
+	}
+	return e.Object == tErr.Object
+}
+
+func (e *PatchTargetNotFoundError) ErrorReason() reason.Reason {
+	return reason.NotFound
+}
+
+func (e *PatchTargetNotFoundError) ErrorRetryable() bool {
+	return false
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestPatchIfExistsFilter(t *testing.T) {
+	tests := map[string]struct {
+		annotations   map[string]string
+		exists        bool
+		expectedError error
+	}{
+		"no annotation and object not found, not filtered": {
+			annotations: nil,
+			exists:      false,
+		},
+		"patch-if-exists and object exists, not filtered": {
+			annotations: map[string]string{
+				common.ActuationStrategyAnnotation: common.PatchIfExists,
+			},
+			exists: true,
+		},
+		"patch-if-exists and object not found, filtered": {
+			annotations: map[string]string{
+				common.ActuationStrategyAnnotation: common.PatchIfExists,
+			},
+			exists: false,
+			expectedError: &PatchTargetNotFoundError{
+				Object: object.UnstructuredToObjMetadata(defaultObj),
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			obj := defaultObj.DeepCopy()
+			obj.SetAnnotations(tc.annotations)
+			var clusterObjs []runtime.Object
+			if tc.exists {
+				clusterObjs = append(clusterObjs, defaultObj.DeepCopy())
+			}
+			filter := PatchIfExistsFilter{
+				Client: dynamicfake.NewSimpleDynamicClient(scheme.Scheme, clusterObjs...),
+				Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+					scheme.Scheme.PrioritizedVersionsAllGroups()...),
+			}
+			err := filter.Filter(obj)
+			testutil.AssertEqual(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestReadActuationStrategy(t *testing.T) {
+	obj := defaultObj.DeepCopy()
+	strategy, err := ReadActuationStrategy(obj)
+	assert.NoError(t, err)
+	assert.Empty(t, strategy)
+
+	obj.SetAnnotations(map[string]string{common.ActuationStrategyAnnotation: common.PatchIfExists})
+	strategy, err = ReadActuationStrategy(obj)
+	assert.NoError(t, err)
+	assert.Equal(t, common.PatchIfExists, strategy)
+	assert.True(t, IsPatchIfExists(obj))
+
+	obj.SetAnnotations(map[string]string{common.ActuationStrategyAnnotation: "create-only"})
+	_, err = ReadActuationStrategy(obj)
+	assert.EqualError(t, err, `invalid "config.kubernetes.io/actuation-strategy" annotation: must be "patch-if-exists", got "create-only"`)
+	assert.False(t, IsPatchIfExists(&unstructured.Unstructured{}))
+}
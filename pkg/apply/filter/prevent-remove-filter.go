@@ -8,6 +8,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/reason"
 )
 
 // PreventRemoveFilter implements ValidationFilter interface to determine
@@ -57,3 +58,11 @@ func (e *AnnotationPreventedDeletionError) Is(err error) bool {
 	return e.Annotation == tErr.Annotation &&
 		e.Value == tErr.Value
 }
+
+func (e *AnnotationPreventedDeletionError) ErrorReason() reason.Reason {
+	return reason.DeletionPrevented
+}
+
+func (e *AnnotationPreventedDeletionError) ErrorRetryable() bool {
+	return false
+}
@@ -66,6 +66,15 @@ func TestPreventDeleteAnnotation(t *testing.T) {
 				Value:      common.PreventDeletion,
 			},
 		},
+		"Annotation key config.kubernetes.io/actuation-strategy and value patch-if-exists is true": {
+			annotations: map[string]string{
+				common.ActuationStrategyAnnotation: common.PatchIfExists,
+			},
+			expectedError: &AnnotationPreventedDeletionError{
+				Annotation: common.ActuationStrategyAnnotation,
+				Value:      common.PatchIfExists,
+			},
+		},
 	}
 
 	for name, tc := range tests {
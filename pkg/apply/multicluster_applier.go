@@ -0,0 +1,491 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/dependson"
+	"sigs.k8s.io/cli-utils/pkg/object/graph"
+	"sigs.k8s.io/cli-utils/pkg/reason"
+)
+
+// MultiClusterApplier applies a set of objects to multiple clusters, using
+// one Applier and one inventory per cluster. The cluster of each object is
+// selected with the config.kubernetes.io/target-cluster annotation. Objects
+// without the annotation are applied to the default cluster.
+//
+// The depends-on annotation may reference objects applied to other
+// clusters. The objects are then applied in stages: an object is only
+// applied once the objects it depends on in other clusters have been
+// applied and reconciled, or applied for dry-runs. Objects whose
+// dependencies in other clusters are not reconciled are skipped, and
+// retained in their inventory. The clusters of each stage are applied
+// concurrently. Each stage of a cluster is a separate run of its Applier,
+// with its own events. Objects of a cluster are pruned in its last stage.
+type MultiClusterApplier struct {
+	appliers       map[string]*Applier
+	defaultCluster string
+}
+
+// NewMultiClusterApplier returns a MultiClusterApplier for the passed
+// appliers, by cluster name. The defaultCluster may be empty, in which case
+// every object must have the target-cluster annotation.
+func NewMultiClusterApplier(appliers map[string]*Applier, defaultCluster string) (*MultiClusterApplier, error) {
+	if defaultCluster != "" {
+		if _, found := appliers[defaultCluster]; !found {
+			return nil, fmt.Errorf("no applier for default cluster %q", defaultCluster)
+		}
+	}
+	return &MultiClusterApplier{
+		appliers:       appliers,
+		defaultCluster: defaultCluster,
+	}, nil
+}
+
+// NewMultiClusterApplierForFactories returns a MultiClusterApplier with an
+// Applier for each of the passed factories, by cluster name. The inventory
+// client of each cluster is created by the invFactory.
+func NewMultiClusterApplierForFactories(factories map[string]util.Factory, invFactory inventory.ClientFactory,
+	defaultCluster string) (*MultiClusterApplier, error) {
+	appliers := make(map[string]*Applier, len(factories))
+	for cluster, factory := range factories {
+		invClient, err := invFactory.NewClient(factory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create inventory client for cluster %q: %w", cluster, err)
+		}
+		applier, err := NewApplierBuilder().
+			WithFactory(factory).
+			WithInventoryClient(invClient).
+			Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create applier for cluster %q: %w", cluster, err)
+		}
+		appliers[cluster] = applier
+	}
+	return NewMultiClusterApplier(appliers, defaultCluster)
+}
+
+// ClusterEvent is an applier event tagged with the name of the cluster it
+// originated from.
+type ClusterEvent struct {
+	// Cluster is the name of the cluster. It is empty for errors that are
+	// not specific to a cluster.
+	Cluster string
+	Event   event.Event
+}
+
+// CrossClusterDependencyNotReadyError means that an object was not applied,
+// because an object it depends on was not applied or reconciled.
+type CrossClusterDependencyNotReadyError struct {
+	Object          object.ObjMetadata
+	RelationCluster string
+	Relation        object.ObjMetadata
+}
+
+func (e *CrossClusterDependencyNotReadyError) Error() string {
+	return fmt.Sprintf("dependency not ready: %s in cluster %q", e.Relation, e.RelationCluster)
+}
+
+// ErrorReason returns reason.DependencyNotReady.
+func (e *CrossClusterDependencyNotReadyError) ErrorReason() reason.Reason {
+	return reason.DependencyNotReady
+}
+
+// ErrorRetryable returns true, since the dependency may be ready when the
+// objects are applied again.
+func (e *CrossClusterDependencyNotReadyError) ErrorRetryable() bool {
+	return true
+}
+
+// Run applies the objects to their target clusters. The inventories map
+// holds the inventory of each cluster, by cluster name. Clusters with an
+// inventory but no objects are still run, so their objects are pruned.
+// The events of all clusters are merged into the returned channel, which
+// is closed once every cluster is done.
+func (m *MultiClusterApplier) Run(ctx context.Context, inventories map[string]inventory.Info,
+	objects object.UnstructuredSet, options ApplierOptions) <-chan ClusterEvent {
+	eventChannel := make(chan ClusterEvent)
+	go func() {
+		defer close(eventChannel)
+		objsByCluster, err := m.splitObjects(inventories, objects)
+		if err != nil {
+			sendClusterError(eventChannel, err)
+			return
+		}
+		p, err := m.plan(objsByCluster)
+		if err != nil {
+			sendClusterError(eventChannel, err)
+			return
+		}
+
+		ready := newReadySet(options.DryRunStrategy.ClientOrServerDryRun())
+		clusters := sortedClusters(inventories)
+		for stage := 0; stage <= p.maxStage; stage++ {
+			var wg sync.WaitGroup
+			for _, cluster := range clusters {
+				opts, ok := m.stageOptions(p, ready, cluster, stage, options, eventChannel)
+				if !ok {
+					continue
+				}
+				klog.V(4).Infof("multi-cluster applier running stage %d of cluster %q", stage, cluster)
+				cluster := cluster
+				ch := m.appliers[cluster].Run(ctx, inventories[cluster], objsByCluster[cluster], opts)
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for e := range ch {
+						ready.record(cluster, e)
+						eventChannel <- ClusterEvent{Cluster: cluster, Event: e}
+					}
+				}()
+			}
+			wg.Wait()
+		}
+	}()
+	return eventChannel
+}
+
+func sendClusterError(eventChannel chan<- ClusterEvent, err error) {
+	eventChannel <- ClusterEvent{
+		Event: event.Event{
+			Type:       event.ErrorType,
+			ErrorEvent: event.ErrorEvent{Err: err},
+		},
+	}
+}
+
+// clusterObject identifies an object in a cluster.
+type clusterObject struct {
+	cluster string
+	id      object.ObjMetadata
+}
+
+func (o clusterObject) String() string {
+	return fmt.Sprintf("%s in cluster %q", o.id, o.cluster)
+}
+
+// multiClusterPlan holds the stage each object is applied in.
+type multiClusterPlan struct {
+	// ids are the objects of each cluster.
+	ids map[string]object.ObjMetadataSet
+	// deps are the dependencies of each object, in its own and in other
+	// clusters.
+	deps map[clusterObject][]clusterObject
+	// remote are the objects in other clusters referenced by the objects of
+	// each cluster.
+	remote map[string]object.ObjMetadataSet
+	// stages are the stages the objects are applied in.
+	stages map[clusterObject]int
+	// lastStages are the stages the clusters are pruned in.
+	lastStages map[string]int
+	maxStage   int
+}
+
+// plan assigns a stage to each object. Objects without dependencies in other
+// clusters, directly or through their dependencies in the same cluster, are
+// applied in the first stage. Other objects are applied in the stage after
+// the last of their dependencies in other clusters.
+func (m *MultiClusterApplier) plan(objsByCluster map[string]object.UnstructuredSet) (*multiClusterPlan, error) {
+	p := &multiClusterPlan{
+		ids:        make(map[string]object.ObjMetadataSet),
+		deps:       make(map[clusterObject][]clusterObject),
+		remote:     make(map[string]object.ObjMetadataSet),
+		stages:     make(map[clusterObject]int),
+		lastStages: make(map[string]int),
+	}
+	for cluster, objs := range objsByCluster {
+		p.ids[cluster] = object.UnstructuredSetToObjMetadataSet(objs)
+	}
+	for _, cluster := range sortedKeys(objsByCluster) {
+		objs := objsByCluster[cluster]
+		ids := p.ids[cluster]
+		for i, obj := range objs {
+			from := clusterObject{cluster: cluster, id: ids[i]}
+			// Invalid annotations are reported by the applier of the cluster.
+			refs, _, _ := dependson.ReadDependencies(obj)
+			for _, ref := range refs {
+				if ids.Contains(ref) {
+					continue
+				}
+				for _, other := range sortedKeys(objsByCluster) {
+					if other != cluster && p.ids[other].Contains(ref) {
+						p.deps[from] = append(p.deps[from], clusterObject{cluster: other, id: ref})
+						p.remote[cluster] = p.remote[cluster].Union(object.ObjMetadataSet{ref})
+					}
+				}
+			}
+		}
+		if len(p.remote[cluster]) == 0 {
+			continue
+		}
+		// The implicit and explicit dependencies in the cluster decide the
+		// stage of the objects too, e.g. objects in a namespace are not
+		// applied before the namespace. Errors are reported by the applier
+		// of the cluster.
+		g, _ := graph.DependencyGraphWithOptions(objs, graph.Options{
+			Rules:                 m.appliers[cluster].dependencyRules,
+			ExternalDependencies:  true,
+			SatisfiedDependencies: p.remote[cluster],
+		})
+		for _, id := range ids {
+			from := clusterObject{cluster: cluster, id: id}
+			for _, dep := range g.Dependencies(id) {
+				p.deps[from] = append(p.deps[from], clusterObject{cluster: cluster, id: dep})
+			}
+		}
+	}
+
+	s := &stageSorter{plan: p, visiting: make(map[clusterObject]int)}
+	for _, cluster := range sortedKeys(objsByCluster) {
+		for _, id := range p.ids[cluster] {
+			stage, err := s.stage(clusterObject{cluster: cluster, id: id})
+			if err != nil {
+				return nil, err
+			}
+			if stage > p.lastStages[cluster] {
+				p.lastStages[cluster] = stage
+			}
+			if stage > p.maxStage {
+				p.maxStage = stage
+			}
+		}
+	}
+	return p, nil
+}
+
+// stageSorter computes the stages of the objects with a depth-first search.
+type stageSorter struct {
+	plan *multiClusterPlan
+	// stack is the path of the search, and visiting the index of each
+	// object of the path in the stack.
+	stack    []clusterObject
+	visiting map[clusterObject]int
+}
+
+func (s *stageSorter) stage(o clusterObject) (int, error) {
+	if stage, found := s.plan.stages[o]; found {
+		return stage, nil
+	}
+	if i, found := s.visiting[o]; found {
+		// Cycles in a single cluster are reported by the applier of the
+		// cluster. Cycles across clusters can not be applied in stages.
+		for _, c := range s.stack[i:] {
+			if c.cluster != o.cluster {
+				return 0, fmt.Errorf("cyclic dependency between clusters: %s depends on itself", o)
+			}
+		}
+		return 0, nil
+	}
+	s.visiting[o] = len(s.stack)
+	s.stack = append(s.stack, o)
+	stage := 0
+	for _, dep := range s.plan.deps[o] {
+		depStage, err := s.stage(dep)
+		if err != nil {
+			return 0, err
+		}
+		if dep.cluster != o.cluster {
+			depStage++
+		}
+		if depStage > stage {
+			stage = depStage
+		}
+	}
+	s.stack = s.stack[:len(s.stack)-1]
+	delete(s.visiting, o)
+	s.plan.stages[o] = stage
+	return stage, nil
+}
+
+// stageOptions returns the options of the run of the cluster in the stage,
+// or false if the cluster is not run in the stage. The objects of the stage
+// whose dependencies are not ready are skipped.
+func (m *MultiClusterApplier) stageOptions(p *multiClusterPlan, ready *readySet, cluster string, stage int,
+	options ApplierOptions, eventChannel chan<- ClusterEvent) (ApplierOptions, bool) {
+	if stage > p.lastStages[cluster] {
+		return options, false
+	}
+	skipped := p.skipped(ready, cluster, stage)
+	var selected, excluded object.ObjMetadataSet
+	for _, id := range p.ids[cluster] {
+		o := clusterObject{cluster: cluster, id: id}
+		if p.stages[o] != stage {
+			excluded = append(excluded, id)
+			continue
+		}
+		if dep, found := skipped[o]; found {
+			eventChannel <- ClusterEvent{
+				Cluster: cluster,
+				Event: event.Event{
+					Type: event.ApplyType,
+					ApplyEvent: event.ApplyEvent{
+						Identifier: id,
+						Status:     event.ApplySkipped,
+						Error: &CrossClusterDependencyNotReadyError{
+							Object:          id,
+							RelationCluster: dep.cluster,
+							Relation:        dep.id,
+						},
+					},
+				},
+			}
+			excluded = append(excluded, id)
+			continue
+		}
+		selected = append(selected, id)
+	}
+	if len(p.remote[cluster]) == 0 {
+		// No objects of the cluster depend on other clusters, so they are
+		// all applied in a single stage.
+		return options, true
+	}
+
+	var selector Selector
+	if options.Selector != nil {
+		selector = *options.Selector
+	}
+	if stage == p.lastStages[cluster] {
+		// The objects to prune are only known to the applier, so they are
+		// pruned in the last stage, by excluding the other objects.
+		selector.ExcludeObjects = selector.ExcludeObjects.Union(excluded)
+	} else {
+		if len(selector.IncludeObjects) > 0 {
+			selected = selected.Intersection(selector.IncludeObjects)
+		}
+		if len(selected) == 0 {
+			return options, false
+		}
+		selector.IncludeObjects = selected
+	}
+	options.Selector = &selector
+	// The objects of other stages and of other clusters are excluded from
+	// the run, but may be referenced by the depends-on annotations.
+	options.SatisfiedDependencies = options.SatisfiedDependencies.Union(p.remote[cluster]).Union(excluded)
+	return options, true
+}
+
+// skipped returns the objects of the cluster in the stage that depend on
+// objects that are not ready, with the first of these dependencies. Objects
+// that depend on skipped objects of the same stage are skipped too.
+func (p *multiClusterPlan) skipped(ready *readySet, cluster string, stage int) map[clusterObject]clusterObject {
+	skipped := make(map[clusterObject]clusterObject)
+	for changed := true; changed; {
+		changed = false
+		for _, id := range p.ids[cluster] {
+			o := clusterObject{cluster: cluster, id: id}
+			if _, found := skipped[o]; found || p.stages[o] != stage {
+				continue
+			}
+			for _, dep := range p.deps[o] {
+				if dep.cluster == cluster && p.stages[dep] == stage {
+					// Dependencies in the same run are handled by the
+					// applier, unless they are skipped.
+					if _, found := skipped[dep]; !found {
+						continue
+					}
+				} else if ready.contains(dep) {
+					continue
+				}
+				skipped[o] = dep
+				changed = true
+				break
+			}
+		}
+	}
+	return skipped
+}
+
+// readySet records the objects that were reconciled, or applied for
+// dry-runs. It is safe for concurrent use.
+type readySet struct {
+	dryRun  bool
+	mu      sync.Mutex
+	objects map[clusterObject]bool
+}
+
+func newReadySet(dryRun bool) *readySet {
+	return &readySet{
+		dryRun:  dryRun,
+		objects: make(map[clusterObject]bool),
+	}
+}
+
+// record updates the set with an event of the run of the cluster.
+func (r *readySet) record(cluster string, e event.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch e.Type {
+	case event.ApplyType:
+		if r.dryRun && e.ApplyEvent.Status == event.ApplySuccessful {
+			r.objects[clusterObject{cluster: cluster, id: e.ApplyEvent.Identifier}] = true
+		}
+	case event.WaitType:
+		switch e.WaitEvent.Status {
+		case event.ReconcileSuccessful, event.ReconcileSkippedByAnnotation:
+			r.objects[clusterObject{cluster: cluster, id: e.WaitEvent.Identifier}] = true
+		}
+	}
+}
+
+func (r *readySet) contains(o clusterObject) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.objects[o]
+}
+
+// splitObjects groups the objects by target cluster, and verifies there is
+// an applier and an inventory for every cluster.
+func (m *MultiClusterApplier) splitObjects(inventories map[string]inventory.Info,
+	objects object.UnstructuredSet) (map[string]object.UnstructuredSet, error) {
+	for cluster := range inventories {
+		if _, found := m.appliers[cluster]; !found {
+			return nil, fmt.Errorf("no applier for cluster %q", cluster)
+		}
+	}
+	objsByCluster := make(map[string]object.UnstructuredSet)
+	for _, obj := range objects {
+		cluster, found := obj.GetAnnotations()[common.TargetClusterAnnotation]
+		if !found {
+			cluster = m.defaultCluster
+		}
+		if cluster == "" {
+			return nil, fmt.Errorf("no target cluster for object %s",
+				object.UnstructuredToObjMetadata(obj))
+		}
+		if _, found := inventories[cluster]; !found {
+			return nil, fmt.Errorf("no inventory for cluster %q of object %s",
+				cluster, object.UnstructuredToObjMetadata(obj))
+		}
+		objsByCluster[cluster] = append(objsByCluster[cluster], obj)
+	}
+	return objsByCluster, nil
+}
+
+func sortedClusters(inventories map[string]inventory.Info) []string {
+	clusters := make([]string, 0, len(inventories))
+	for cluster := range inventories {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+	return clusters
+}
+
+func sortedKeys(objsByCluster map[string]object.UnstructuredSet) []string {
+	clusters := make([]string, 0, len(objsByCluster))
+	for cluster := range objsByCluster {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+	return clusters
+}
@@ -0,0 +1,213 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestMultiClusterApplier_Run(t *testing.T) {
+	deployment := testutil.Unstructured(t, resources["deployment"])
+	secret := testutil.Unstructured(t, resources["secret"],
+		testutil.AddAnnotation(common.TargetClusterAnnotation, "remote"))
+	deploymentID := object.UnstructuredToObjMetadata(deployment)
+	secretID := object.UnstructuredToObjMetadata(secret)
+	invInfo := inventoryInfo{
+		name:      "abc-123",
+		namespace: "default",
+		id:        "test",
+	}
+	options := ApplierOptions{
+		DryRunStrategy:  common.DryRunClient,
+		InventoryPolicy: inventory.PolicyMustMatch,
+		NoPrune:         true,
+	}
+
+	m, err := NewMultiClusterApplier(map[string]*Applier{
+		"local":  newTestApplier(t, invInfo, object.UnstructuredSet{deployment}, nil, newFakeWatcher(nil)),
+		"remote": newTestApplier(t, invInfo, object.UnstructuredSet{secret}, nil, newFakeWatcher(nil)),
+	}, "local")
+	require.NoError(t, err)
+
+	inventories := map[string]inventory.Info{
+		"local":  invInfo.toWrapped(),
+		"remote": invInfo.toWrapped(),
+	}
+	applied := make(map[string]object.ObjMetadataSet)
+	for e := range m.Run(context.Background(), inventories, object.UnstructuredSet{deployment, secret}, options) {
+		require.NotEqual(t, event.ErrorType, e.Event.Type, "unexpected error: %v", e.Event.ErrorEvent.Err)
+		if e.Event.Type == event.ApplyType {
+			applied[e.Cluster] = append(applied[e.Cluster], e.Event.ApplyEvent.Identifier)
+		}
+	}
+	assert.Equal(t, map[string]object.ObjMetadataSet{
+		"local":  {deploymentID},
+		"remote": {secretID},
+	}, applied)
+}
+
+func TestMultiClusterApplier_Run_MissingInventory(t *testing.T) {
+	secret := testutil.Unstructured(t, resources["secret"],
+		testutil.AddAnnotation(common.TargetClusterAnnotation, "remote"))
+	invInfo := inventoryInfo{
+		name:      "abc-123",
+		namespace: "default",
+		id:        "test",
+	}
+
+	m, err := NewMultiClusterApplier(map[string]*Applier{
+		"local":  newTestApplier(t, invInfo, nil, nil, newFakeWatcher(nil)),
+		"remote": newTestApplier(t, invInfo, object.UnstructuredSet{secret}, nil, newFakeWatcher(nil)),
+	}, "local")
+	require.NoError(t, err)
+
+	var events []ClusterEvent
+	for e := range m.Run(context.Background(), map[string]inventory.Info{"local": invInfo.toWrapped()},
+		object.UnstructuredSet{secret}, ApplierOptions{}) {
+		events = append(events, e)
+	}
+	require.Len(t, events, 1)
+	assert.Equal(t, "", events[0].Cluster)
+	assert.Equal(t, event.ErrorType, events[0].Event.Type)
+	assert.Contains(t, events[0].Event.ErrorEvent.Err.Error(), `no inventory for cluster "remote"`)
+}
+
+func TestNewMultiClusterApplier_UnknownDefault(t *testing.T) {
+	_, err := NewMultiClusterApplier(map[string]*Applier{}, "local")
+	assert.EqualError(t, err, `no applier for default cluster "local"`)
+}
+
+func TestMultiClusterApplier_Run_CrossClusterDependency(t *testing.T) {
+	secret := testutil.Unstructured(t, resources["secret"],
+		testutil.AddAnnotation(common.TargetClusterAnnotation, "remote"))
+	secretID := object.UnstructuredToObjMetadata(secret)
+	deployment := testutil.Unstructured(t, resources["deployment"],
+		testutil.AddDependsOn(t, secretID))
+	deploymentID := object.UnstructuredToObjMetadata(deployment)
+	invInfo := inventoryInfo{
+		name:      "abc-123",
+		namespace: "default",
+		id:        "test",
+	}
+
+	// appliedObject is the result of an apply event.
+	type appliedObject struct {
+		cluster string
+		id      object.ObjMetadata
+		status  event.ApplyEventStatus
+		err     error
+	}
+
+	testCases := map[string]struct {
+		selector        *Selector
+		expectedApplied []appliedObject
+	}{
+		"dependents are applied after their dependencies": {
+			expectedApplied: []appliedObject{
+				{cluster: "remote", id: secretID, status: event.ApplySuccessful},
+				{cluster: "local", id: deploymentID, status: event.ApplySuccessful},
+			},
+		},
+		"dependents of objects that are not applied are skipped": {
+			selector: &Selector{ExcludeKinds: []schema.GroupKind{secretID.GroupKind}},
+			expectedApplied: []appliedObject{
+				{
+					cluster: "local",
+					id:      deploymentID,
+					status:  event.ApplySkipped,
+					err: &CrossClusterDependencyNotReadyError{
+						Object:          deploymentID,
+						RelationCluster: "remote",
+						Relation:        secretID,
+					},
+				},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			m, err := NewMultiClusterApplier(map[string]*Applier{
+				"local":  newTestApplier(t, invInfo, object.UnstructuredSet{deployment}, nil, newFakeWatcher(nil)),
+				"remote": newTestApplier(t, invInfo, object.UnstructuredSet{secret}, nil, newFakeWatcher(nil)),
+			}, "local")
+			require.NoError(t, err)
+
+			inventories := map[string]inventory.Info{
+				"local":  invInfo.toWrapped(),
+				"remote": invInfo.toWrapped(),
+			}
+			options := ApplierOptions{
+				DryRunStrategy:  common.DryRunClient,
+				InventoryPolicy: inventory.PolicyMustMatch,
+				NoPrune:         true,
+				Selector:        tc.selector,
+			}
+			var applied []appliedObject
+			for e := range m.Run(context.Background(), inventories, object.UnstructuredSet{deployment, secret}, options) {
+				require.NotEqual(t, event.ErrorType, e.Event.Type, "unexpected error: %v", e.Event.ErrorEvent.Err)
+				if e.Event.Type == event.ApplyType {
+					ae := e.Event.ApplyEvent
+					applied = append(applied, appliedObject{e.Cluster, ae.Identifier, ae.Status, ae.Error})
+				}
+			}
+			assert.Equal(t, tc.expectedApplied, applied)
+		})
+	}
+}
+
+func TestMultiClusterApplier_Plan(t *testing.T) {
+	namespace := testutil.Unstructured(t, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: default
+`)
+	secret := testutil.Unstructured(t, resources["secret"])
+	secretID := object.UnstructuredToObjMetadata(secret)
+	deployment := testutil.Unstructured(t, resources["deployment"],
+		testutil.AddDependsOn(t, secretID))
+	deploymentID := object.UnstructuredToObjMetadata(deployment)
+	pod := testutil.Unstructured(t, resources["obj1"],
+		testutil.AddDependsOn(t, deploymentID))
+
+	m := &MultiClusterApplier{appliers: map[string]*Applier{"a": {}, "b": {}}}
+
+	// The namespace of the deployment depends on the secret in cluster b
+	// too, and the pod in cluster b depends on the deployment in cluster a.
+	p, err := m.plan(map[string]object.UnstructuredSet{
+		"a": {namespace, deployment},
+		"b": {secret, pod},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[clusterObject]int{
+		{cluster: "a", id: object.UnstructuredToObjMetadata(namespace)}: 0,
+		{cluster: "a", id: deploymentID}:                                1,
+		{cluster: "b", id: secretID}:                                    0,
+		{cluster: "b", id: object.UnstructuredToObjMetadata(pod)}:       2,
+	}, p.stages)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, p.lastStages)
+	assert.Equal(t, 2, p.maxStage)
+
+	// Cycles across clusters are invalid.
+	cyclicSecret := testutil.Unstructured(t, resources["secret"],
+		testutil.AddDependsOn(t, deploymentID))
+	_, err = m.plan(map[string]object.UnstructuredSet{
+		"a": {deployment},
+		"b": {cyclicSecret},
+	})
+	assert.EqualError(t, err,
+		fmt.Sprintf("cyclic dependency between clusters: %s in cluster \"a\" depends on itself", deploymentID))
+}
@@ -5,9 +5,12 @@ package mutator
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -112,6 +115,11 @@ func (atm *ApplyTimeMutator) Mutate(ctx context.Context, obj *unstructured.Unstr
 			return mutated, reason, fmt.Errorf("source field (%s) not present in source object (%s)", sub.SourcePath, sourceRef)
 		}
 
+		sourceValue, err = transformValue(sub, sourceValue)
+		if err != nil {
+			return mutated, reason, fmt.Errorf("failed to transform field (%s) from source object (%s): %w", sub.SourcePath, sourceRef, err)
+		}
+
 		var newValue interface{}
 		if sub.Token == "" {
 			// token not specified, replace the entire target value with the source value
@@ -274,6 +282,48 @@ func writeFieldValue(obj *unstructured.Unstructured, path string, value interfac
 	return nil
 }
 
+// transformValue applies the transforms specified by the substitution to the
+// source field value.
+func transformValue(sub mutation.FieldSubstitution, value interface{}) (interface{}, error) {
+	if sub.Base64Decode {
+		encoded, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("base64 decode requires a string value, found %T", value)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode base64 value: %w", err)
+		}
+		value = string(decoded)
+	}
+	if sub.Offset != 0 {
+		// Numbers are returned as int, which is supported by jsonpath.Set.
+		switch valueTyped := value.(type) {
+		case int:
+			value = int(int64(valueTyped) + sub.Offset)
+		case int32:
+			value = int(int64(valueTyped) + sub.Offset)
+		case int64:
+			value = int(valueTyped + sub.Offset)
+		case float64:
+			if valueTyped != math.Trunc(valueTyped) {
+				return nil, fmt.Errorf("offset requires an integer value, found %v", valueTyped)
+			}
+			value = int(int64(valueTyped) + sub.Offset)
+		case string:
+			i, err := strconv.ParseInt(strings.TrimSpace(valueTyped), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("offset requires an integer value: %w", err)
+			}
+			// Keep string values as strings
+			value = strconv.FormatInt(i+sub.Offset, 10)
+		default:
+			return nil, fmt.Errorf("offset requires an integer value, found %T", value)
+		}
+	}
+	return value, nil
+}
+
 // valueToString converts an interface{} to a string, formatting as json for
 // maps, lists. Designed to handle yaml/json/krm primitives.
 func valueToString(value interface{}) (string, error) {
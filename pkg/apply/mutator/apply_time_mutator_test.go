@@ -18,6 +18,7 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/apply/cache"
 	ktestutil "sigs.k8s.io/cli-utils/pkg/kstatus/polling/testutil"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/mutation"
 
 	// Using gopkg.in/yaml.v3 instead of sigs.k8s.io/yaml on purpose.
 	// yaml.v3 correctly parses ints:
@@ -364,6 +365,58 @@ roleRef:
   apiGroup: rbac.authorization.k8s.io
 `
 
+var secret1y = `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db-credentials
+  namespace: app-namespace
+data:
+  username: YWRtaW4= # admin
+  password: czNjcjN0 # s3cr3t
+`
+
+var pod4y = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod4-name
+  namespace: app-namespace
+  annotations:
+    config.kubernetes.io/apply-time-mutation: |
+      - sourceRef:
+          kind: Secret
+          name: db-credentials
+        sourcePath: $.data.username
+        targetPath: $.spec.containers[0].env[0].value
+        token: ${username}
+        base64Decode: true
+      - sourceRef:
+          kind: Secret
+          name: db-credentials
+        sourcePath: $.data.password
+        targetPath: $.spec.containers[0].env[0].value
+        token: ${password}
+        base64Decode: true
+      - sourceRef:
+          group: apps
+          kind: Deployment
+          name: deployment1-name
+          namespace: deployment1-namespace
+        sourcePath: $.spec.template.spec.containers[?(@.name=="tcp-handler")].ports[0].containerPort
+        targetPath: $.spec.containers[0].ports[0].containerPort
+        offset: 1000
+spec:
+  containers:
+  - name: app
+    image: example:1.0
+    ports:
+    - containerPort: 0 # field must exist to be mutated
+    env:
+    - name: DB_URL
+      value: postgres://${username}:${password}@db:5432
+`
+
 type nestedFieldValue struct {
 	Field []interface{}
 	Value interface{}
@@ -383,6 +436,8 @@ func TestMutate(t *testing.T) {
 	deployment1 := ktestutil.YamlToUnstructured(t, deployment1y)
 	clusterrole1 := ktestutil.YamlToUnstructured(t, clusterrole1y)
 	clusterrolebinding1 := ktestutil.YamlToUnstructured(t, clusterrolebinding1y)
+	secret1 := ktestutil.YamlToUnstructured(t, secret1y)
+	pod4 := ktestutil.YamlToUnstructured(t, pod4y)
 
 	joinedPaths := make([]interface{}, 0)
 	err := yaml.Unmarshal([]byte(joinedPathsYaml), &joinedPaths)
@@ -561,6 +616,22 @@ func TestMutate(t *testing.T) {
 				},
 			},
 		},
+		"base64 decode, multiple tokens and offset": {
+			target:  pod4,
+			sources: []*unstructured.Unstructured{secret1, secret1, deployment1}, // repeats, because not cached
+			mutated: true,
+			reason:  expectedReason,
+			expected: []nestedFieldValue{
+				{
+					Field: []interface{}{"spec", "containers", 0, "env", 0, "value"},
+					Value: "postgres://admin:s3cr3t@db:5432",
+				},
+				{
+					Field: []interface{}{"spec", "containers", 0, "ports", 0, "containerPort"},
+					Value: 9080,
+				},
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -667,6 +738,77 @@ func TestValueToString(t *testing.T) {
 	}
 }
 
+func TestTransformValue(t *testing.T) {
+	tests := map[string]struct {
+		sub      mutation.FieldSubstitution
+		value    interface{}
+		expected interface{}
+		errMsg   string
+	}{
+		"no transform": {
+			value:    "YWRtaW4=",
+			expected: "YWRtaW4=",
+		},
+		"base64 decode": {
+			sub:      mutation.FieldSubstitution{Base64Decode: true},
+			value:    "YWRtaW4=",
+			expected: "admin",
+		},
+		"base64 decode non-string": {
+			sub:    mutation.FieldSubstitution{Base64Decode: true},
+			value:  int64(1),
+			errMsg: "base64 decode requires a string value, found int64",
+		},
+		"base64 decode invalid": {
+			sub:    mutation.FieldSubstitution{Base64Decode: true},
+			value:  "not base64!",
+			errMsg: "failed to decode base64 value: illegal base64 data at input byte 3",
+		},
+		"offset int": {
+			sub:      mutation.FieldSubstitution{Offset: 1000},
+			value:    int64(8080),
+			expected: 9080,
+		},
+		"negative offset float": {
+			sub:      mutation.FieldSubstitution{Offset: -80},
+			value:    float64(8080),
+			expected: 8000,
+		},
+		"offset string": {
+			sub:      mutation.FieldSubstitution{Offset: 1},
+			value:    "8080",
+			expected: "8081",
+		},
+		"base64 decode and offset": {
+			sub:      mutation.FieldSubstitution{Base64Decode: true, Offset: 1},
+			value:    "ODA4MA==", // 8080
+			expected: "8081",
+		},
+		"offset non-integer": {
+			sub:    mutation.FieldSubstitution{Offset: 1},
+			value:  1.5,
+			errMsg: "offset requires an integer value, found 1.5",
+		},
+		"offset bool": {
+			sub:    mutation.FieldSubstitution{Offset: 1},
+			value:  true,
+			errMsg: "offset requires an integer value, found bool",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			received, err := transformValue(tc.sub, tc.value)
+			if tc.errMsg != "" {
+				require.EqualError(t, err, tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, received, "unexpected result")
+		})
+	}
+}
+
 // fakeNamespaceClient wraps ResourceInterface, overwriting the Get func.
 type fakeNamespaceClient struct {
 	dynamic.ResourceInterface
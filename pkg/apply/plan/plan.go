@@ -0,0 +1,206 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package plan defines a serializable description of the actions an
+// applier run will perform, so they can be reviewed before they are
+// executed.
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/reason"
+	"sigs.k8s.io/yaml"
+)
+
+// Decision describes what will happen to a single object.
+type Decision string
+
+const (
+	// Apply means the object will be created or updated.
+	Apply Decision = "Apply"
+	// Prune means the object will be deleted, because it is no longer in
+	// the set of objects being applied.
+	Prune Decision = "Prune"
+	// Delete means the object will be deleted by a destroy.
+	Delete Decision = "Delete"
+	// Detach means the object will be removed from the inventory without
+	// being deleted.
+	Detach Decision = "Detach"
+	// Skip means the object will not be actuated, e.g. because of a
+	// filter. The reason is included in the ObjectAction.
+	Skip Decision = "Skip"
+	// Fail means the dry-run actuation of the object failed.
+	Fail Decision = "Fail"
+)
+
+// Plan describes the ordered actions of an applier run.
+type Plan struct {
+	// Inventory identifies the inventory the plan was generated for.
+	Inventory Inventory `json:"inventory"`
+	// Actions are the groups of actions, in the order they will run.
+	Actions []Action `json:"actions"`
+	// Invalid lists the objects that failed validation. They are neither
+	// applied nor pruned.
+	Invalid []ObjectAction `json:"invalid,omitempty"`
+	// Objects are the objects to apply when executing the plan.
+	Objects []*unstructured.Unstructured `json:"objects,omitempty"`
+}
+
+// Inventory identifies an inventory object.
+type Inventory struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	ID        string `json:"id,omitempty"`
+}
+
+// Action is a group of objects actuated or waited on together.
+type Action struct {
+	// Name is the name of the task, e.g. "apply-0".
+	Name string `json:"name"`
+	// Type is the type of action, e.g. "Apply" or "Wait".
+	Type string `json:"type"`
+	// Condition is the condition waited for, only set for Wait actions.
+	Condition string `json:"condition,omitempty"`
+	// Objects are the objects in the group.
+	Objects []ObjectAction `json:"objects,omitempty"`
+}
+
+// ObjectAction describes the decision for a single object.
+type ObjectAction struct {
+	Group     string   `json:"group,omitempty"`
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace,omitempty"`
+	Name      string   `json:"name"`
+	Decision  Decision `json:"decision,omitempty"`
+	Reason    string   `json:"reason,omitempty"`
+}
+
+// NewObjectAction returns an ObjectAction for the object with the passed
+// id.
+func NewObjectAction(id object.ObjMetadata, decision Decision, reason string) ObjectAction {
+	return ObjectAction{
+		Group:     id.GroupKind.Group,
+		Kind:      id.GroupKind.Kind,
+		Namespace: id.Namespace,
+		Name:      id.Name,
+		Decision:  decision,
+		Reason:    reason,
+	}
+}
+
+// ID returns the identifier of the object.
+func (oa ObjectAction) ID() object.ObjMetadata {
+	return object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: oa.Group, Kind: oa.Kind},
+		Namespace: oa.Namespace,
+		Name:      oa.Name,
+	}
+}
+
+// StaleError is returned when executing a plan, if the actions that would
+// be executed no longer match the plan.
+type StaleError struct {
+	Reason string
+}
+
+func (e *StaleError) Error() string {
+	return fmt.Sprintf("plan is stale: %s", e.Reason)
+}
+
+func (e *StaleError) ErrorReason() reason.Reason {
+	return reason.StalePlan
+}
+
+func (e *StaleError) ErrorRetryable() bool {
+	return false
+}
+
+// Verify returns a StaleError if the inventory or the action groups of an
+// applier run differ from the plan. Wait actions are not compared, because
+// they are only included in the plan to describe the ordering.
+func (p *Plan) Verify(inv inventory.Info, actionGroups []event.ActionGroup) error {
+	if inv.Name() != p.Inventory.Name || inv.Namespace() != p.Inventory.Namespace ||
+		inv.ID() != p.Inventory.ID {
+		return &StaleError{Reason: fmt.Sprintf("inventory %s/%s does not match planned inventory %s/%s",
+			inv.Namespace(), inv.Name(), p.Inventory.Namespace, p.Inventory.Name)}
+	}
+	var planned []Action
+	for _, action := range p.Actions {
+		if action.Type != event.WaitAction.String() {
+			planned = append(planned, action)
+		}
+	}
+	var actual []event.ActionGroup
+	for _, ag := range actionGroups {
+		if ag.Action != event.WaitAction {
+			actual = append(actual, ag)
+		}
+	}
+	if len(planned) != len(actual) {
+		return &StaleError{Reason: fmt.Sprintf("expected %d actions, found %d", len(planned), len(actual))}
+	}
+	for i, ag := range actual {
+		action := planned[i]
+		if action.Name != ag.Name || action.Type != ag.Action.String() {
+			return &StaleError{Reason: fmt.Sprintf("expected action %q (%s), found %q (%s)",
+				action.Name, action.Type, ag.Name, ag.Action)}
+		}
+		ids := make(object.ObjMetadataSet, len(action.Objects))
+		for j, oa := range action.Objects {
+			ids[j] = oa.ID()
+		}
+		if !ids.Equal(ag.Identifiers) {
+			return &StaleError{Reason: fmt.Sprintf("objects of action %q changed", ag.Name)}
+		}
+	}
+	return nil
+}
+
+// Format is the serialization format of a plan file.
+type Format string
+
+const (
+	YAML Format = "yaml"
+	JSON Format = "json"
+)
+
+// Write serializes the plan to the writer in the passed format.
+func Write(w io.Writer, p *Plan, format Format) error {
+	var b []byte
+	var err error
+	switch format {
+	case YAML:
+		b, err = yaml.Marshal(p)
+	case JSON:
+		b, err = json.MarshalIndent(p, "", "  ")
+		b = append(b, '\n')
+	default:
+		return fmt.Errorf("unknown plan format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Read parses a plan in either YAML or JSON format.
+func Read(r io.Reader) (*Plan, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &Plan{}
+	if err := yaml.Unmarshal(b, p); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package plan
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+var (
+	deploymentID = object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		Namespace: "default",
+		Name:      "foo",
+	}
+	configMapID = object.ObjMetadata{
+		GroupKind: schema.GroupKind{Kind: "ConfigMap"},
+		Namespace: "default",
+		Name:      "bar",
+	}
+)
+
+func newInventory(name, id string) inventory.Info {
+	return inventory.WrapInventoryInfoObj(&unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+				"labels": map[string]interface{}{
+					common.InventoryLabel: id,
+				},
+			},
+		},
+	})
+}
+
+func newPlan() *Plan {
+	return &Plan{
+		Inventory: Inventory{
+			Name:      "inv",
+			Namespace: "default",
+			ID:        "test",
+		},
+		Actions: []Action{
+			{
+				Name: "apply-0",
+				Type: "Apply",
+				Objects: []ObjectAction{
+					NewObjectAction(deploymentID, Apply, ""),
+				},
+			},
+			{
+				Name:      "wait-0",
+				Type:      "Wait",
+				Condition: "AllCurrent",
+				Objects: []ObjectAction{
+					NewObjectAction(deploymentID, "", ""),
+				},
+			},
+			{
+				Name: "prune-0",
+				Type: "Prune",
+				Objects: []ObjectAction{
+					NewObjectAction(configMapID, Skip, "object has a prevent-remove annotation"),
+				},
+			},
+		},
+		Objects: []*unstructured.Unstructured{
+			{
+				Object: map[string]interface{}{
+					"apiVersion": "apps/v1",
+					"kind":       "Deployment",
+					"metadata": map[string]interface{}{
+						"name":      "foo",
+						"namespace": "default",
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	for _, format := range []Format{YAML, JSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Write(&buf, newPlan(), format))
+			p, err := Read(&buf)
+			require.NoError(t, err)
+			assert.Equal(t, newPlan(), p)
+		})
+	}
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, newPlan(), "xml")
+	assert.EqualError(t, err, `unknown plan format "xml"`)
+}
+
+func TestVerify(t *testing.T) {
+	testCases := map[string]struct {
+		inv          inventory.Info
+		actionGroups []event.ActionGroup
+		expectedErr  error
+	}{
+		"matching actions": {
+			inv: newInventory("inv", "test"),
+			actionGroups: []event.ActionGroup{
+				{
+					Name:        "apply-0",
+					Action:      event.ApplyAction,
+					Identifiers: object.ObjMetadataSet{deploymentID},
+				},
+				{
+					Name:        "prune-0",
+					Action:      event.PruneAction,
+					Identifiers: object.ObjMetadataSet{configMapID},
+				},
+			},
+		},
+		"wait actions are ignored": {
+			inv: newInventory("inv", "test"),
+			actionGroups: []event.ActionGroup{
+				{
+					Name:        "apply-0",
+					Action:      event.ApplyAction,
+					Identifiers: object.ObjMetadataSet{deploymentID},
+				},
+				{
+					Name:        "wait-0",
+					Action:      event.WaitAction,
+					Identifiers: object.ObjMetadataSet{deploymentID, configMapID},
+				},
+				{
+					Name:        "prune-0",
+					Action:      event.PruneAction,
+					Identifiers: object.ObjMetadataSet{configMapID},
+				},
+			},
+		},
+		"different inventory": {
+			inv: newInventory("other", "test"),
+			expectedErr: &StaleError{
+				Reason: "inventory default/other does not match planned inventory default/inv",
+			},
+		},
+		"missing action": {
+			inv: newInventory("inv", "test"),
+			actionGroups: []event.ActionGroup{
+				{
+					Name:        "apply-0",
+					Action:      event.ApplyAction,
+					Identifiers: object.ObjMetadataSet{deploymentID},
+				},
+			},
+			expectedErr: &StaleError{Reason: "expected 2 actions, found 1"},
+		},
+		"different objects": {
+			inv: newInventory("inv", "test"),
+			actionGroups: []event.ActionGroup{
+				{
+					Name:        "apply-0",
+					Action:      event.ApplyAction,
+					Identifiers: object.ObjMetadataSet{deploymentID, configMapID},
+				},
+				{
+					Name:        "prune-0",
+					Action:      event.PruneAction,
+					Identifiers: object.ObjMetadataSet{},
+				},
+			},
+			expectedErr: &StaleError{Reason: `objects of action "apply-0" changed`},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			err := newPlan().Verify(tc.inv, tc.actionGroups)
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tc.expectedErr, err)
+		})
+	}
+}
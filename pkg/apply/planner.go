@@ -0,0 +1,162 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/plan"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Planner generates a plan of the actions an apply would perform, by
+// running the applier with a dry-run strategy.
+type Planner struct {
+	applier *Applier
+}
+
+// NewPlanner returns a Planner that uses the passed Applier.
+func NewPlanner(applier *Applier) *Planner {
+	return &Planner{applier: applier}
+}
+
+// Plan runs the applier with the passed options and returns the resulting
+// plan. If no dry-run strategy is set in the options, a server-side
+// dry-run is used.
+func (p *Planner) Plan(ctx context.Context, invInfo inventory.Info, objects object.UnstructuredSet, options ApplierOptions) (*plan.Plan, error) {
+	if !options.DryRunStrategy.ClientOrServerDryRun() {
+		options.DryRunStrategy = common.DryRunServer
+	}
+	// Keep a copy of the objects, since the applier mutates them.
+	planObjs := make(object.UnstructuredSet, len(objects))
+	for i, obj := range objects {
+		planObjs[i] = obj.DeepCopy()
+	}
+	result := &plan.Plan{
+		Inventory: plan.Inventory{
+			Name:      invInfo.Name(),
+			Namespace: invInfo.Namespace(),
+			ID:        invInfo.ID(),
+		},
+		Objects: planObjs,
+	}
+
+	// The index of each object action, by group name and object.
+	index := make(map[string]map[object.ObjMetadata]*plan.ObjectAction)
+	decide := func(groupName string, id object.ObjMetadata, decision plan.Decision, err error) {
+		oa, found := index[groupName][id]
+		if !found {
+			return
+		}
+		oa.Decision = decision
+		if err != nil {
+			oa.Reason = err.Error()
+		}
+	}
+
+	var runErr error
+	for e := range p.applier.Run(ctx, invInfo, objects, options) {
+		switch e.Type {
+		case event.InitType:
+			result.Actions = planActions(e.InitEvent.ActionGroups)
+			for i := range result.Actions {
+				action := &result.Actions[i]
+				index[action.Name] = make(map[object.ObjMetadata]*plan.ObjectAction)
+				for j := range action.Objects {
+					index[action.Name][action.Objects[j].ID()] = &action.Objects[j]
+				}
+			}
+		case event.ErrorType:
+			runErr = e.ErrorEvent.Err
+		case event.ValidationType:
+			for _, id := range e.ValidationEvent.Identifiers {
+				result.Invalid = append(result.Invalid,
+					plan.NewObjectAction(id, plan.Skip, e.ValidationEvent.Error.Error()))
+			}
+		case event.ApplyType:
+			ae := e.ApplyEvent
+			switch ae.Status {
+			case event.ApplySuccessful:
+				decide(ae.GroupName, ae.Identifier, plan.Apply, nil)
+			case event.ApplySkipped:
+				decide(ae.GroupName, ae.Identifier, plan.Skip, ae.Error)
+			case event.ApplyFailed:
+				decide(ae.GroupName, ae.Identifier, plan.Fail, ae.Error)
+			}
+		case event.PruneType:
+			pe := e.PruneEvent
+			switch pe.Status {
+			case event.PruneSuccessful:
+				decide(pe.GroupName, pe.Identifier, plan.Prune, nil)
+			case event.PruneSkipped:
+				decide(pe.GroupName, pe.Identifier, plan.Skip, pe.Error)
+			case event.PruneDetached:
+				decide(pe.GroupName, pe.Identifier, plan.Detach, nil)
+			case event.PruneFailed:
+				decide(pe.GroupName, pe.Identifier, plan.Fail, pe.Error)
+			}
+		}
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("failed to generate plan: %w", runErr)
+	}
+	return result, nil
+}
+
+// planActions converts the action groups of a dry-run into plan actions.
+// Dry-runs skip wait tasks, so the wait actions that would follow each
+// apply and prune action are added, in the same order the solver adds them.
+func planActions(actionGroups []event.ActionGroup) []plan.Action {
+	var actions []plan.Action
+	waitCounter := 0
+	for _, ag := range actionGroups {
+		action := plan.Action{
+			Name: ag.Name,
+			Type: ag.Action.String(),
+		}
+		for _, id := range ag.Identifiers {
+			action.Objects = append(action.Objects, plan.NewObjectAction(id, "", ""))
+		}
+		actions = append(actions, action)
+
+		var condition taskrunner.Condition
+		switch ag.Action {
+		case event.ApplyAction:
+			condition = taskrunner.AllCurrent
+		case event.PruneAction:
+			condition = taskrunner.AllNotFound
+		default:
+			continue
+		}
+		wait := plan.Action{
+			Name:      fmt.Sprintf("wait-%d", waitCounter),
+			Type:      event.WaitAction.String(),
+			Condition: string(condition),
+		}
+		for _, id := range ag.Identifiers {
+			wait.Objects = append(wait.Objects, plan.NewObjectAction(id, "", ""))
+		}
+		actions = append(actions, wait)
+		waitCounter++
+	}
+	return actions
+}
+
+// RunPlan executes a plan generated by a Planner. The objects in the plan
+// are applied with the passed options. If the actions the applier would
+// perform no longer match the plan, an error event with a
+// plan.StaleError is sent and nothing is actuated.
+func (a *Applier) RunPlan(ctx context.Context, invInfo inventory.Info, p *plan.Plan, options ApplierOptions) <-chan event.Event {
+	options.Plan = p
+	objects := make(object.UnstructuredSet, len(p.Objects))
+	for i, obj := range p.Objects {
+		objects[i] = obj.DeepCopy()
+	}
+	return a.Run(ctx, invInfo, objects, options)
+}
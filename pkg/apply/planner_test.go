@@ -0,0 +1,122 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/plan"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestPlanner_Plan(t *testing.T) {
+	deployment := testutil.Unstructured(t, resources["deployment"])
+	secret := testutil.Unstructured(t, resources["secret"])
+	deploymentID := object.UnstructuredToObjMetadata(deployment)
+	secretID := object.UnstructuredToObjMetadata(secret)
+	invInfo := inventoryInfo{
+		name:      "abc-123",
+		namespace: "default",
+		id:        "test",
+		set:       object.ObjMetadataSet{secretID},
+	}
+
+	applier := newTestApplier(t, invInfo,
+		object.UnstructuredSet{deployment},
+		object.UnstructuredSet{testutil.Unstructured(t, resources["secret"], testutil.AddOwningInv(t, "test"))},
+		newFakeWatcher(nil),
+	)
+	p, err := NewPlanner(applier).Plan(context.Background(), invInfo.toWrapped(),
+		object.UnstructuredSet{deployment}, ApplierOptions{
+			DryRunStrategy:  common.DryRunClient,
+			InventoryPolicy: inventory.PolicyMustMatch,
+		})
+	require.NoError(t, err)
+
+	assert.Equal(t, plan.Inventory{Name: "abc-123", Namespace: "default", ID: "test"}, p.Inventory)
+	assert.Equal(t, []plan.Action{
+		{
+			Name: "inventory-add-0",
+			Type: "Inventory",
+			Objects: []plan.ObjectAction{
+				plan.NewObjectAction(deploymentID, "", ""),
+			},
+		},
+		{
+			Name: "apply-0",
+			Type: "Apply",
+			Objects: []plan.ObjectAction{
+				plan.NewObjectAction(deploymentID, plan.Apply, ""),
+			},
+		},
+		{
+			Name:      "wait-0",
+			Type:      "Wait",
+			Condition: "AllCurrent",
+			Objects: []plan.ObjectAction{
+				plan.NewObjectAction(deploymentID, "", ""),
+			},
+		},
+		{
+			Name: "prune-0",
+			Type: "Prune",
+			Objects: []plan.ObjectAction{
+				plan.NewObjectAction(secretID, plan.Prune, ""),
+			},
+		},
+		{
+			Name:      "wait-1",
+			Type:      "Wait",
+			Condition: "AllNotFound",
+			Objects: []plan.ObjectAction{
+				plan.NewObjectAction(secretID, "", ""),
+			},
+		},
+		{
+			Name: "inventory-set-0",
+			Type: "Inventory",
+		},
+	}, p.Actions)
+	assert.Empty(t, p.Invalid)
+	require.Len(t, p.Objects, 1)
+	assert.Equal(t, deployment.GetName(), p.Objects[0].GetName())
+}
+
+func TestApplier_RunPlan_Stale(t *testing.T) {
+	deployment := testutil.Unstructured(t, resources["deployment"])
+	invInfo := inventoryInfo{
+		name:      "abc-123",
+		namespace: "default",
+		id:        "test",
+	}
+	p := &plan.Plan{
+		Inventory: plan.Inventory{Name: "abc-123", Namespace: "default", ID: "test"},
+		Actions: []plan.Action{
+			{Name: "inventory-add-0", Type: "Inventory"},
+			{Name: "inventory-set-0", Type: "Inventory"},
+		},
+		Objects: object.UnstructuredSet{deployment},
+	}
+
+	applier := newTestApplier(t, invInfo, object.UnstructuredSet{deployment},
+		object.UnstructuredSet{}, newFakeWatcher(nil))
+
+	var events []event.Event
+	for e := range applier.RunPlan(context.Background(), invInfo.toWrapped(), p, ApplierOptions{
+		NoPrune:         true,
+		InventoryPolicy: inventory.PolicyMustMatch,
+	}) {
+		events = append(events, e)
+	}
+	require.Len(t, events, 1)
+	assert.Equal(t, event.ErrorType, events[0].Type)
+	assert.Equal(t, &plan.StaleError{Reason: "expected 2 actions, found 3"}, events[0].ErrorEvent.Err)
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package policy provides the policy gates that decide, per object, whether
+// it is applied. Unlike filters and mutators, gates are meant to integrate
+// policy engines, e.g. OPA or Kyverno, and report a reason for each decision.
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/reason"
+)
+
+//go:generate stringer -type=Decision
+type Decision int
+
+const (
+	// Allow lets the object be applied.
+	Allow Decision = iota
+	// Skip skips the apply of the object, without failing the apply.
+	Skip
+	// Fail fails the apply of the object.
+	Fail
+)
+
+// Gate decides whether an object is applied.
+type Gate interface {
+	// Name returns the gate name (usually for logging).
+	Name() string
+	// Evaluate returns the decision for the object and the reason for it.
+	// The object is evaluated after all mutations, right before it is
+	// applied. An error fails the apply of the object.
+	Evaluate(ctx context.Context, obj *unstructured.Unstructured) (Decision, string, error)
+}
+
+// DecisionError is reported when a gate skips or fails an object.
+type DecisionError struct {
+	Gate     string
+	Decision Decision
+	Reason   string
+}
+
+func (e *DecisionError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("policy %q: %s", e.Gate, e.Decision)
+	}
+	return fmt.Sprintf("policy %q: %s", e.Gate, e.Reason)
+}
+
+func (e *DecisionError) ErrorReason() reason.Reason {
+	return reason.PolicyDenied
+}
+
+func (e *DecisionError) ErrorRetryable() bool {
+	return false
+}
+
+// Evaluate evaluates the gates in order and returns the first decision that
+// is not Allow, with a DecisionError carrying its reason. If a gate errors,
+// Fail is returned with the error.
+func Evaluate(ctx context.Context, gates []Gate, obj *unstructured.Unstructured) (Decision, error) {
+	for _, gate := range gates {
+		decision, reason, err := gate.Evaluate(ctx, obj)
+		if err != nil {
+			return Fail, fmt.Errorf("failed to evaluate policy %q: %w", gate.Name(), err)
+		}
+		switch decision {
+		case Allow:
+			continue
+		case Skip, Fail:
+			return decision, &DecisionError{
+				Gate:     gate.Name(),
+				Decision: decision,
+				Reason:   reason,
+			}
+		default:
+			return Fail, fmt.Errorf("invalid decision of policy %q: %s", gate.Name(), decision)
+		}
+	}
+	return Allow, nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type fakeGate struct {
+	name     string
+	decision Decision
+	reason   string
+	err      error
+}
+
+func (g fakeGate) Name() string { return g.name }
+
+func (g fakeGate) Evaluate(context.Context, *unstructured.Unstructured) (Decision, string, error) {
+	return g.decision, g.reason, g.err
+}
+
+func TestEvaluate(t *testing.T) {
+	allow := fakeGate{name: "allow", decision: Allow}
+	skip := fakeGate{name: "no-host-path", decision: Skip, reason: "hostPath volumes are not allowed"}
+	fail := fakeGate{name: "require-owner", decision: Fail, reason: "owner label is required"}
+
+	testCases := map[string]struct {
+		gates            []Gate
+		expectedDecision Decision
+		expectedError    string
+	}{
+		"no gates": {
+			expectedDecision: Allow,
+		},
+		"all allow": {
+			gates:            []Gate{allow, allow},
+			expectedDecision: Allow,
+		},
+		"skip": {
+			gates:            []Gate{allow, skip, fail},
+			expectedDecision: Skip,
+			expectedError:    `policy "no-host-path": hostPath volumes are not allowed`,
+		},
+		"fail": {
+			gates:            []Gate{fail, skip},
+			expectedDecision: Fail,
+			expectedError:    `policy "require-owner": owner label is required`,
+		},
+		"fail without reason": {
+			gates:            []Gate{fakeGate{name: "deny", decision: Fail}},
+			expectedDecision: Fail,
+			expectedError:    `policy "deny": Fail`,
+		},
+		"evaluation error": {
+			gates:            []Gate{fakeGate{name: "opa", err: errors.New("connection refused")}},
+			expectedDecision: Fail,
+			expectedError:    `failed to evaluate policy "opa": connection refused`,
+		},
+		"invalid decision": {
+			gates:            []Gate{fakeGate{name: "broken", decision: Decision(7)}},
+			expectedDecision: Fail,
+			expectedError:    `invalid decision of policy "broken": Decision(7)`,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			decision, err := Evaluate(context.TODO(), tc.gates, &unstructured.Unstructured{})
+			assert.Equal(t, tc.expectedDecision, decision)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
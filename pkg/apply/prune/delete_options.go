@@ -0,0 +1,33 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package prune
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/object/propagationpolicy"
+)
+
+// deleteOptions returns the DeleteOptions to use for the passed object,
+// applying the per-object propagation policy override, if any.
+func (o Options) deleteOptions(obj *unstructured.Unstructured) (metav1.DeleteOptions, error) {
+	uid := obj.GetUID()
+	propagationPolicy := o.PropagationPolicy
+	policy, found, err := propagationpolicy.ReadAnnotation(obj)
+	if err != nil {
+		return metav1.DeleteOptions{}, err
+	}
+	if found {
+		propagationPolicy = policy
+	}
+	return metav1.DeleteOptions{
+		// Only delete the resource if it hasn't already been deleted
+		// and recreated since the last GET. Otherwise error.
+		Preconditions: &metav1.Preconditions{
+			UID: &uid,
+		},
+		PropagationPolicy:  &propagationPolicy,
+		GracePeriodSeconds: o.GracePeriodSeconds,
+	}, nil
+}
@@ -16,6 +16,7 @@ type EventFactory interface {
 	CreateSuccessEvent(obj *unstructured.Unstructured) event.Event
 	CreateSkippedEvent(obj *unstructured.Unstructured, err error) event.Event
 	CreateFailedEvent(id object.ObjMetadata, err error) event.Event
+	CreateDetachedEvent(obj *unstructured.Unstructured) event.Event
 }
 
 // CreateEventFactory returns the correct concrete version of
@@ -76,6 +77,18 @@ func (pef PruneEventFactory) CreateFailedEvent(id object.ObjMetadata, err error)
 	}
 }
 
+func (pef PruneEventFactory) CreateDetachedEvent(obj *unstructured.Unstructured) event.Event {
+	return event.Event{
+		Type: event.PruneType,
+		PruneEvent: event.PruneEvent{
+			GroupName:  pef.groupName,
+			Status:     event.PruneDetached,
+			Object:     obj,
+			Identifier: object.UnstructuredToObjMetadata(obj),
+		},
+	}
+}
+
 // DeleteEventFactory implements EventFactory interface as a concrete
 // representation of for delete events.
 type DeleteEventFactory struct {
@@ -118,3 +131,15 @@ func (def DeleteEventFactory) CreateFailedEvent(id object.ObjMetadata, err error
 		},
 	}
 }
+
+func (def DeleteEventFactory) CreateDetachedEvent(obj *unstructured.Unstructured) event.Event {
+	return event.Event{
+		Type: event.DeleteType,
+		DeleteEvent: event.DeleteEvent{
+			GroupName:  def.groupName,
+			Status:     event.DeleteDetached,
+			Object:     obj,
+			Identifier: object.UnstructuredToObjMetadata(obj),
+		},
+	}
+}
@@ -14,6 +14,8 @@ package prune
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -26,6 +28,7 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/metrics"
 	"sigs.k8s.io/cli-utils/pkg/object"
 )
 
@@ -35,6 +38,8 @@ type Pruner struct {
 	InvClient inventory.Client
 	Client    dynamic.Interface
 	Mapper    meta.RESTMapper
+	// Metrics records the errors of delete requests. Optional.
+	Metrics *metrics.Metrics
 }
 
 // NewPruner returns a new Pruner.
@@ -65,9 +70,64 @@ type Options struct {
 
 	PropagationPolicy metav1.DeletionPropagation
 
+	// GracePeriodSeconds is the duration in seconds before each object
+	// should be deleted. If nil, the default for the resource type is used.
+	GracePeriodSeconds *int64
+
 	// True if we are destroying, which deletes the inventory object
 	// as well (possibly) the inventory namespace.
 	Destroy bool
+
+	// Retry is called with each delete operation and may call it again
+	// if it fails. If nil, each object is deleted once.
+	Retry func(func() error) error
+
+	// Concurrency is the maximum number of objects deleted at the same time.
+	// Objects are deleted one by one if it is less than 2.
+	Concurrency int
+}
+
+// Ordering is the strategy that orders the deletion of prune objects.
+type Ordering string
+
+const (
+	// OrderingReverseApply deletes the objects in the reverse order they
+	// would be applied in, taking the applied objects into account. This is
+	// the default.
+	OrderingReverseApply Ordering = "reverse-apply"
+	// OrderingDependencies deletes the objects in the reverse order of the
+	// dependencies between the prune objects only, from their depends-on
+	// annotations and implicit dependencies, e.g. custom resources before
+	// their CRD. Objects are deleted in as few groups as possible.
+	OrderingDependencies Ordering = "dependencies"
+	// OrderingSimultaneous deletes all the objects in a single group,
+	// ignoring dependencies, with bounded concurrency.
+	OrderingSimultaneous Ordering = "simultaneous"
+)
+
+// Orderings are the valid prune orderings.
+var Orderings = []Ordering{OrderingReverseApply, OrderingDependencies, OrderingSimultaneous}
+
+// ParseOrdering returns the prune ordering with the name, or
+// OrderingReverseApply if the name is empty.
+func ParseOrdering(name string) (Ordering, error) {
+	if name == "" {
+		return OrderingReverseApply, nil
+	}
+	for _, o := range Orderings {
+		if string(o) == name {
+			return o, nil
+		}
+	}
+	return "", fmt.Errorf("invalid prune ordering %q: must be one of %v", name, Orderings)
+}
+
+// retry calls fn using the Retry function, if set.
+func (o Options) retry(fn func() error) error {
+	if o.Retry == nil {
+		return fn()
+	}
+	return o.Retry(fn)
 }
 
 // Prune deletes the set of passed objects. A prune skip/failure is
@@ -96,102 +156,204 @@ func (p *Pruner) Prune(
 	eventFactory := CreateEventFactory(opts.Destroy, taskName)
 	// Iterate through objects to prune (delete). If an object is not pruned
 	// and we need to keep it in the inventory, we must capture the prune failure.
+	var pending []pendingDelete
 	for _, obj := range objs {
-		id := object.UnstructuredToObjMetadata(obj)
-		klog.V(5).Infof("evaluating prune filters (object: %q)", id)
-
-		// UID will change if the object is deleted and re-created.
-		uid := obj.GetUID()
-		if uid == "" {
-			err := object.NotFound([]interface{}{"metadata", "uid"}, "")
-			if klog.V(4).Enabled() {
-				// only log event emitted errors if the verbosity > 4
-				klog.Errorf("prune uid lookup errored (object: %s): %v", id, err)
-			}
-			taskContext.SendEvent(eventFactory.CreateFailedEvent(id, err))
-			taskContext.InventoryManager().AddFailedDelete(id)
+		d, ok := p.prepareDelete(obj, pruneFilters, taskContext, eventFactory, opts)
+		if !ok {
+			continue
+		}
+		if opts.Concurrency <= 1 {
+			d.err = p.delete(taskContext, d, opts)
+			recordDelete(taskContext, eventFactory, d)
 			continue
 		}
+		pending = append(pending, d)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
 
-		// Check filters to see if we're prevented from pruning/deleting object.
-		var filterErr error
-		for _, pruneFilter := range pruneFilters {
-			klog.V(6).Infof("prune filter evaluating (filter: %s, object: %s)", pruneFilter.Name(), id)
-			filterErr = pruneFilter.Filter(obj)
-			if filterErr != nil {
-				var fatalErr *filter.FatalError
-				if errors.As(filterErr, &fatalErr) {
-					if klog.V(4).Enabled() {
-						// only log event emitted errors if the verbosity > 4
-						klog.Errorf("prune filter errored (filter: %s, object: %s): %v", pruneFilter.Name(), id, fatalErr.Err)
-					}
-					taskContext.SendEvent(eventFactory.CreateFailedEvent(id, fatalErr.Err))
-					taskContext.InventoryManager().AddFailedDelete(id)
-					break
+	// Delete the objects concurrently, then record the results in order,
+	// since the task context is not safe for concurrent use.
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i := range pending {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(d *pendingDelete) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			d.err = p.delete(taskContext, *d, opts)
+		}(&pending[i])
+	}
+	wg.Wait()
+	for _, d := range pending {
+		recordDelete(taskContext, eventFactory, d)
+	}
+	return nil
+}
+
+// pendingDelete is an object that passed the prune filters and is deleted,
+// unless dry-run.
+type pendingDelete struct {
+	id         object.ObjMetadata
+	obj        *unstructured.Unstructured
+	deleteOpts metav1.DeleteOptions
+	err        error
+}
+
+// prepareDelete evaluates the prune filters and the detach annotation of
+// the object. Returns false if the object must not be deleted, in which
+// case the result is already recorded in the task context.
+func (p *Pruner) prepareDelete(
+	obj *unstructured.Unstructured,
+	pruneFilters []filter.ValidationFilter,
+	taskContext *taskrunner.TaskContext,
+	eventFactory EventFactory,
+	opts Options,
+) (pendingDelete, bool) {
+	id := object.UnstructuredToObjMetadata(obj)
+	klog.V(5).Infof("evaluating prune filters (object: %q)", id)
+
+	// UID will change if the object is deleted and re-created.
+	uid := obj.GetUID()
+	if uid == "" {
+		err := object.NotFound([]interface{}{"metadata", "uid"}, "")
+		if klog.V(4).Enabled() {
+			// only log event emitted errors if the verbosity > 4
+			klog.Errorf("prune uid lookup errored (object: %s): %v", id, err)
+		}
+		taskContext.SendEvent(eventFactory.CreateFailedEvent(id, err))
+		taskContext.InventoryManager().AddFailedDelete(id)
+		return pendingDelete{}, false
+	}
+
+	// Check filters to see if we're prevented from pruning/deleting object.
+	var filterErr error
+	for _, pruneFilter := range pruneFilters {
+		klog.V(6).Infof("prune filter evaluating (filter: %s, object: %s)", pruneFilter.Name(), id)
+		filterErr = pruneFilter.Filter(obj)
+		if filterErr != nil {
+			var fatalErr *filter.FatalError
+			if errors.As(filterErr, &fatalErr) {
+				if klog.V(4).Enabled() {
+					// only log event emitted errors if the verbosity > 4
+					klog.Errorf("prune filter errored (filter: %s, object: %s): %v", pruneFilter.Name(), id, fatalErr.Err)
 				}
-				klog.V(4).Infof("prune filtered (filter: %s, object: %s): %v", pruneFilter.Name(), id, filterErr)
-
-				// Remove the inventory annotation if deletion was prevented.
-				// This abandons the object so it won't be pruned by future applier runs.
-				var abandonErr *filter.AnnotationPreventedDeletionError
-				if errors.As(filterErr, &abandonErr) {
-					if !opts.DryRunStrategy.ClientOrServerDryRun() {
-						var err error
-						obj, err = p.removeInventoryAnnotation(obj)
-						if err != nil {
-							if klog.V(4).Enabled() {
-								// only log event emitted errors if the verbosity > 4
-								klog.Errorf("error removing annotation (object: %q, annotation: %q): %v", id, inventory.OwningInventoryKey, err)
-							}
-							taskContext.SendEvent(eventFactory.CreateFailedEvent(id, err))
-							taskContext.InventoryManager().AddFailedDelete(id)
-							break
+				taskContext.SendEvent(eventFactory.CreateFailedEvent(id, fatalErr.Err))
+				taskContext.InventoryManager().AddFailedDelete(id)
+				break
+			}
+			klog.V(4).Infof("prune filtered (filter: %s, object: %s): %v", pruneFilter.Name(), id, filterErr)
+
+			// Remove the inventory annotation if deletion was prevented.
+			// This abandons the object so it won't be pruned by future applier runs.
+			var abandonErr *filter.AnnotationPreventedDeletionError
+			if errors.As(filterErr, &abandonErr) {
+				if !opts.DryRunStrategy.ClientOrServerDryRun() {
+					var err error
+					obj, err = p.removeInventoryAnnotation(obj)
+					if err != nil {
+						if klog.V(4).Enabled() {
+							// only log event emitted errors if the verbosity > 4
+							klog.Errorf("error removing annotation (object: %q, annotation: %q): %v", id, inventory.OwningInventoryKey, err)
 						}
-						// Inventory annotation was successfully removed from the object.
-						// Register for removal from the inventory.
-						taskContext.AddAbandonedObject(id)
+						taskContext.SendEvent(eventFactory.CreateFailedEvent(id, err))
+						taskContext.InventoryManager().AddFailedDelete(id)
+						break
 					}
+					// Inventory annotation was successfully removed from the object.
+					// Register for removal from the inventory.
+					taskContext.AddAbandonedObject(id)
 				}
-
-				taskContext.SendEvent(eventFactory.CreateSkippedEvent(obj, filterErr))
-				taskContext.InventoryManager().AddSkippedDelete(id)
-				break
 			}
+
+			taskContext.SendEvent(eventFactory.CreateSkippedEvent(obj, filterErr))
+			taskContext.InventoryManager().AddSkippedDelete(id)
+			break
 		}
-		if filterErr != nil {
-			continue
-		}
+	}
+	if filterErr != nil {
+		return pendingDelete{}, false
+	}
 
-		// Filters passed--actually delete object if not dry run.
+	// Remove the object from the inventory, without deleting it, if the
+	// detach annotation is set.
+	if common.IsDetached(obj.GetAnnotations()) {
+		klog.V(4).Infof("detaching object (object: %q)", id)
 		if !opts.DryRunStrategy.ClientOrServerDryRun() {
-			klog.V(4).Infof("deleting object (object: %q)", id)
-			err := p.deleteObject(id, metav1.DeleteOptions{
-				// Only delete the resource if it hasn't already been deleted
-				// and recreated since the last GET. Otherwise error.
-				Preconditions: &metav1.Preconditions{
-					UID: &uid,
-				},
-				PropagationPolicy: &opts.PropagationPolicy,
-			})
+			var err error
+			obj, err = p.removeInventoryAnnotation(obj)
 			if err != nil {
-				if apierrors.IsNotFound(err) {
-					klog.Warningf("error deleting object (object: %q): object not found: object may have been deleted asynchronously by another client", id)
-					// treat this as successful idempotent deletion
-				} else {
-					if klog.V(4).Enabled() {
-						// only log event emitted errors if the verbosity > 4
-						klog.Errorf("error deleting object (object: %q): %v", id, err)
-					}
-					taskContext.SendEvent(eventFactory.CreateFailedEvent(id, err))
-					taskContext.InventoryManager().AddFailedDelete(id)
-					continue
+				if klog.V(4).Enabled() {
+					// only log event emitted errors if the verbosity > 4
+					klog.Errorf("error removing annotation (object: %q, annotation: %q): %v", id, inventory.OwningInventoryKey, err)
 				}
+				taskContext.SendEvent(eventFactory.CreateFailedEvent(id, err))
+				taskContext.InventoryManager().AddFailedDelete(id)
+				return pendingDelete{}, false
 			}
+			// Register for removal from the inventory.
+			taskContext.AddAbandonedObject(id)
 		}
-		taskContext.InventoryManager().AddSuccessfulDelete(id, obj.GetUID())
-		taskContext.SendEvent(eventFactory.CreateSuccessEvent(obj))
+		taskContext.InventoryManager().AddSkippedDelete(id)
+		taskContext.SendEvent(eventFactory.CreateDetachedEvent(obj))
+		return pendingDelete{}, false
 	}
-	return nil
+
+	d := pendingDelete{id: id, obj: obj}
+	if !opts.DryRunStrategy.ClientOrServerDryRun() {
+		var err error
+		d.deleteOpts, err = opts.deleteOptions(obj)
+		if err != nil {
+			if klog.V(4).Enabled() {
+				// only log event emitted errors if the verbosity > 4
+				klog.Errorf("invalid delete options (object: %q): %v", id, err)
+			}
+			taskContext.SendEvent(eventFactory.CreateFailedEvent(id, err))
+			taskContext.InventoryManager().AddFailedDelete(id)
+			return pendingDelete{}, false
+		}
+	}
+	return d, true
+}
+
+// delete deletes the object, unless dry-run. Objects that are already
+// deleted are treated as successfully deleted.
+func (p *Pruner) delete(taskContext *taskrunner.TaskContext, d pendingDelete, opts Options) error {
+	if opts.DryRunStrategy.ClientOrServerDryRun() {
+		return nil
+	}
+	klog.V(4).Infof("deleting object (object: %q)", d.id)
+	ctx, span := taskContext.StartObjectSpan("Delete", d.id)
+	err := opts.retry(func() error {
+		return p.deleteObject(ctx, d.id, d.deleteOpts)
+	})
+	if apierrors.IsNotFound(err) {
+		// The object was already deleted.
+		taskrunner.EndSpan(span, nil)
+		klog.Warningf("error deleting object (object: %q): object not found: object may have been deleted asynchronously by another client", d.id)
+		// treat this as successful idempotent deletion
+		return nil
+	}
+	taskrunner.EndSpan(span, err)
+	return err
+}
+
+// recordDelete records the result of the deletion of the object in the
+// task context.
+func recordDelete(taskContext *taskrunner.TaskContext, eventFactory EventFactory, d pendingDelete) {
+	if d.err != nil {
+		if klog.V(4).Enabled() {
+			// only log event emitted errors if the verbosity > 4
+			klog.Errorf("error deleting object (object: %q): %v", d.id, d.err)
+		}
+		taskContext.SendEvent(eventFactory.CreateFailedEvent(d.id, d.err))
+		taskContext.InventoryManager().AddFailedDelete(d.id)
+		return
+	}
+	taskContext.InventoryManager().AddSuccessfulDelete(d.id, d.obj.GetUID())
+	taskContext.SendEvent(eventFactory.CreateSuccessEvent(d.obj))
 }
 
 // removeInventoryAnnotation removes the `config.k8s.io/owning-inventory` annotation from pruneObj.
@@ -260,12 +422,14 @@ func (p *Pruner) getObject(id object.ObjMetadata) (*unstructured.Unstructured, e
 	return namespacedClient.Get(context.TODO(), id.Name, metav1.GetOptions{})
 }
 
-func (p *Pruner) deleteObject(id object.ObjMetadata, opts metav1.DeleteOptions) error {
+func (p *Pruner) deleteObject(ctx context.Context, id object.ObjMetadata, opts metav1.DeleteOptions) error {
 	namespacedClient, err := p.namespacedClient(id)
 	if err != nil {
 		return err
 	}
-	return namespacedClient.Delete(context.TODO(), id.Name, opts)
+	err = namespacedClient.Delete(ctx, id.Name, opts)
+	p.Metrics.ObserveDelete(err)
+	return err
 }
 
 func (p *Pruner) namespacedClient(id object.ObjMetadata) (dynamic.ResourceInterface, error) {
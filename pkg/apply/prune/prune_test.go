@@ -7,7 +7,9 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,6 +20,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/fake"
@@ -29,6 +32,7 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/propagationpolicy"
 	"sigs.k8s.io/cli-utils/pkg/testutil"
 )
 
@@ -165,6 +169,18 @@ metadata:
     config.k8s.io/owning-inventory: test-app-label
 `
 
+var pdbDetachManifest = `
+apiVersion: "policy/v1beta1"
+kind: PodDisruptionBudget
+metadata:
+  name: pdb-detach
+  namespace: test-namespace
+  uid: uid3
+  annotations:
+    client.lifecycle.config.k8s.io/detach: "true"
+    config.k8s.io/owning-inventory: test-app-label
+`
+
 // Options with different dry-run values.
 var (
 	defaultOptions = Options{
@@ -698,6 +714,56 @@ func TestPruneDeletionPrevention(t *testing.T) {
 	}
 }
 
+func TestPruneDetach(t *testing.T) {
+	tests := map[string]struct {
+		options        Options
+		expectedStatus interface{}
+	}{
+		"prune": {
+			options:        defaultOptions,
+			expectedStatus: event.PruneDetached,
+		},
+		"destroy": {
+			options:        defaultOptionsDestroy,
+			expectedStatus: event.DeleteDetached,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			pruneObj := testutil.Unstructured(t, pdbDetachManifest)
+			pruneID := object.UnstructuredToObjMetadata(pruneObj)
+			po := Pruner{
+				InvClient: inventory.NewFakeClient(object.ObjMetadataSet{pruneID}),
+				Client:    fake.NewSimpleDynamicClient(scheme.Scheme, pruneObj),
+				Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+					scheme.Scheme.PrioritizedVersionsAllGroups()...),
+			}
+			eventChannel := make(chan event.Event, 1)
+			resourceCache := cache.NewResourceCacheMap()
+			taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+			err := po.Prune([]*unstructured.Unstructured{pruneObj}, []filter.ValidationFilter{filter.PreventRemoveFilter{}}, taskContext, "test-0", tc.options)
+			require.NoError(t, err)
+
+			e := <-eventChannel
+			if tc.options.Destroy {
+				assert.Equal(t, tc.expectedStatus, e.DeleteEvent.Status)
+			} else {
+				assert.Equal(t, tc.expectedStatus, e.PruneEvent.Status)
+			}
+
+			// verify that the object was not deleted, but no longer has the annotation
+			obj, err := po.getObject(pruneID)
+			require.NoError(t, err)
+			assert.NotContains(t, obj.GetAnnotations(), inventory.OwningInventoryKey)
+
+			im := taskContext.InventoryManager()
+			assert.Truef(t, taskContext.IsAbandonedObject(pruneID), "Prune() should mark object as abandoned")
+			assert.Truef(t, im.IsSkippedDelete(pruneID), "Prune() should mark object as skipped")
+			assert.Falsef(t, im.IsSuccessfulDelete(pruneID), "Prune() should NOT mark object as deleted")
+		})
+	}
+}
+
 // failureNamespaceClient wrappers around a namespaceClient with the overwriting to Get and Delete functions.
 type failureNamespaceClient struct {
 	dynamic.ResourceInterface
@@ -948,14 +1014,48 @@ func (c *optionsCaptureNamespaceClient) Delete(_ context.Context, _ string, opti
 }
 
 func TestPrune_PropagationPolicy(t *testing.T) {
+	gracePeriod := int64(30)
+	withPolicy := func(policy string) *unstructured.Unstructured {
+		obj := pdb.DeepCopy()
+		annotations := obj.GetAnnotations()
+		annotations[propagationpolicy.Annotation] = policy
+		obj.SetAnnotations(annotations)
+		return obj
+	}
 	testCases := map[string]struct {
-		propagationPolicy metav1.DeletionPropagation
+		obj                 *unstructured.Unstructured
+		propagationPolicy   metav1.DeletionPropagation
+		gracePeriodSeconds  *int64
+		expectedPolicy      metav1.DeletionPropagation
+		expectedGracePeriod *int64
+		expectedFailure     bool
 	}{
 		"background propagation policy": {
+			obj:               pdb,
 			propagationPolicy: metav1.DeletePropagationBackground,
+			expectedPolicy:    metav1.DeletePropagationBackground,
 		},
 		"foreground propagation policy": {
+			obj:               pdb,
 			propagationPolicy: metav1.DeletePropagationForeground,
+			expectedPolicy:    metav1.DeletePropagationForeground,
+		},
+		"annotation overrides propagation policy": {
+			obj:               withPolicy(string(metav1.DeletePropagationOrphan)),
+			propagationPolicy: metav1.DeletePropagationBackground,
+			expectedPolicy:    metav1.DeletePropagationOrphan,
+		},
+		"invalid annotation fails delete": {
+			obj:               withPolicy("Sideways"),
+			propagationPolicy: metav1.DeletePropagationBackground,
+			expectedFailure:   true,
+		},
+		"grace period seconds": {
+			obj:                 pdb,
+			propagationPolicy:   metav1.DeletePropagationBackground,
+			gracePeriodSeconds:  &gracePeriod,
+			expectedPolicy:      metav1.DeletePropagationBackground,
+			expectedGracePeriod: &gracePeriod,
 		},
 	}
 	for name, tc := range testCases {
@@ -973,12 +1073,102 @@ func TestPrune_PropagationPolicy(t *testing.T) {
 			eventChannel := make(chan event.Event, 1)
 			resourceCache := cache.NewResourceCacheMap()
 			taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
-			err := po.Prune([]*unstructured.Unstructured{pdb}, []filter.ValidationFilter{}, taskContext, "test-0", Options{
-				PropagationPolicy: tc.propagationPolicy,
+			err := po.Prune([]*unstructured.Unstructured{tc.obj}, []filter.ValidationFilter{}, taskContext, "test-0", Options{
+				PropagationPolicy:  tc.propagationPolicy,
+				GracePeriodSeconds: tc.gracePeriodSeconds,
 			})
 			assert.NoError(t, err)
+			id := object.UnstructuredToObjMetadata(tc.obj)
+			if tc.expectedFailure {
+				assert.True(t, taskContext.InventoryManager().IsFailedDelete(id))
+				assert.Nil(t, captureClient.options.PropagationPolicy)
+				return
+			}
 			require.NotNil(t, captureClient.options.PropagationPolicy)
-			assert.Equal(t, tc.propagationPolicy, *captureClient.options.PropagationPolicy)
+			assert.Equal(t, tc.expectedPolicy, *captureClient.options.PropagationPolicy)
+			assert.Equal(t, tc.expectedGracePeriod, captureClient.options.GracePeriodSeconds)
+		})
+	}
+}
+
+// flakyNamespaceClient fails the first failures deletes with err.
+type flakyNamespaceClient struct {
+	dynamic.ResourceInterface
+	failures int
+	err      error
+	attempts int
+}
+
+var _ dynamic.ResourceInterface = &flakyNamespaceClient{}
+
+func (c *flakyNamespaceClient) Delete(_ context.Context, _ string, _ metav1.DeleteOptions, _ ...string) error {
+	c.attempts++
+	if c.attempts <= c.failures {
+		return c.err
+	}
+	return nil
+}
+
+func TestPrune_Retry(t *testing.T) {
+	retryTwice := func(fn func() error) error {
+		var err error
+		for i := 0; i < 2; i++ {
+			if err = fn(); err == nil {
+				return nil
+			}
+		}
+		return err
+	}
+	testCases := map[string]struct {
+		retry            func(func() error) error
+		failures         int
+		expectedAttempts int
+		expectedFailed   bool
+	}{
+		"no retry": {
+			failures:         1,
+			expectedAttempts: 1,
+			expectedFailed:   true,
+		},
+		"retry succeeds": {
+			retry:            retryTwice,
+			failures:         1,
+			expectedAttempts: 2,
+			expectedFailed:   false,
+		},
+		"retry fails": {
+			retry:            retryTwice,
+			failures:         2,
+			expectedAttempts: 2,
+			expectedFailed:   true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			flakyClient := &flakyNamespaceClient{
+				failures: tc.failures,
+				err:      apierrors.NewTooManyRequests("slow down", 1),
+			}
+			po := Pruner{
+				InvClient: inventory.NewFakeClient(object.ObjMetadataSet{}),
+				Client: &fakeDynamicClient{
+					resourceInterface: flakyClient,
+				},
+				Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+					scheme.Scheme.PrioritizedVersionsAllGroups()...),
+			}
+
+			eventChannel := make(chan event.Event, 1)
+			resourceCache := cache.NewResourceCacheMap()
+			taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+			err := po.Prune([]*unstructured.Unstructured{pdb}, []filter.ValidationFilter{}, taskContext, "test-0", Options{
+				PropagationPolicy: metav1.DeletePropagationBackground,
+				Retry:             tc.retry,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedAttempts, flakyClient.attempts)
+			id := object.UnstructuredToObjMetadata(pdb)
+			assert.Equal(t, tc.expectedFailed, taskContext.InventoryManager().IsFailedDelete(id))
 		})
 	}
 }
@@ -1004,3 +1194,87 @@ type fakeDynamicResourceClient struct {
 func (c *fakeDynamicResourceClient) Namespace(ns string) dynamic.ResourceInterface {
 	return c.resourceInterface
 }
+
+// concurrentNamespaceClient records the maximum number of concurrent
+// deletes, and fails to delete the objects with the failing name.
+type concurrentNamespaceClient struct {
+	dynamic.ResourceInterface
+	failing string
+	mu      sync.Mutex
+	active  int
+	max     int
+}
+
+var _ dynamic.ResourceInterface = &concurrentNamespaceClient{}
+
+func (c *concurrentNamespaceClient) Delete(_ context.Context, name string, _ metav1.DeleteOptions, _ ...string) error {
+	c.mu.Lock()
+	c.active++
+	if c.active > c.max {
+		c.max = c.active
+	}
+	c.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	c.mu.Lock()
+	c.active--
+	c.mu.Unlock()
+	if name == c.failing {
+		return apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, name, fmt.Errorf("denied"))
+	}
+	return nil
+}
+
+func TestPrune_Concurrency(t *testing.T) {
+	var objs object.UnstructuredSet
+	for i := 0; i < 6; i++ {
+		obj := pod.DeepCopy()
+		obj.SetName(fmt.Sprintf("pod-%d", i))
+		obj.SetUID(types.UID(fmt.Sprintf("uid-%d", i)))
+		objs = append(objs, obj)
+	}
+	client := &concurrentNamespaceClient{failing: "pod-3"}
+	po := Pruner{
+		InvClient: inventory.NewFakeClient(object.ObjMetadataSet{}),
+		Client: &fakeDynamicClient{
+			resourceInterface: client,
+		},
+		Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+			scheme.Scheme.PrioritizedVersionsAllGroups()...),
+	}
+
+	eventChannel := make(chan event.Event, len(objs))
+	taskContext := taskrunner.NewTaskContext(eventChannel, cache.NewResourceCacheMap())
+	err := po.Prune(objs, []filter.ValidationFilter{}, taskContext, "test-0", Options{
+		PropagationPolicy: metav1.DeletePropagationBackground,
+		Concurrency:       3,
+	})
+	require.NoError(t, err)
+	close(eventChannel)
+	assert.Equal(t, 3, client.max)
+
+	// Events are sent in the order of the objects.
+	var names []string
+	for e := range eventChannel {
+		names = append(names, e.PruneEvent.Identifier.Name)
+		if e.PruneEvent.Identifier.Name == "pod-3" {
+			assert.Equal(t, event.PruneFailed, e.PruneEvent.Status)
+		} else {
+			assert.Equal(t, event.PruneSuccessful, e.PruneEvent.Status)
+		}
+	}
+	assert.Equal(t, []string{"pod-0", "pod-1", "pod-2", "pod-3", "pod-4", "pod-5"}, names)
+	assert.True(t, taskContext.InventoryManager().IsFailedDelete(object.UnstructuredToObjMetadata(objs[3])))
+}
+
+func TestParseOrdering(t *testing.T) {
+	for _, o := range Orderings {
+		parsed, err := ParseOrdering(string(o))
+		require.NoError(t, err)
+		assert.Equal(t, o, parsed)
+	}
+	parsed, err := ParseOrdering("")
+	require.NoError(t, err)
+	assert.Equal(t, OrderingReverseApply, parsed)
+	_, err = ParseOrdering("random")
+	assert.EqualError(t, err, `invalid prune ordering "random": must be one of [reverse-apply dependencies simultaneous]`)
+}
@@ -0,0 +1,100 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"encoding/json"
+	"strings"
+
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+)
+
+const (
+	// DefaultMaxSize is the default maximum size of an encoded report, in
+	// bytes. It leaves room below the 1MiB size limit of the objects of
+	// the API server for the metadata of the object that stores the report.
+	DefaultMaxSize = 512 * 1024
+
+	// maxErrorLength is the maximum length of the error messages of a
+	// report, in bytes. Longer messages are truncated.
+	maxErrorLength = 1024
+
+	// omittedSize is the size reserved for the counts of the omitted
+	// errors and object results.
+	omittedSize = 64
+)
+
+// bound limits the size of the encoded report to maxSize bytes. Long error
+// messages are truncated. If the report is still too large, object results
+// are omitted, successful ones first, starting with the last, and then
+// errors, starting with the last. The omitted errors and object results are
+// counted in the report.
+func bound(rep *Report, maxSize int) *Report {
+	for i := range rep.Errors {
+		rep.Errors[i] = truncate(rep.Errors[i])
+	}
+	for i := range rep.Objects {
+		rep.Objects[i].Error = truncate(rep.Objects[i].Error)
+	}
+	size := encodedSize(rep)
+	if size <= maxSize {
+		return rep
+	}
+	maxSize -= omittedSize
+
+	omitted := make([]bool, len(rep.Objects))
+	for _, successful := range []bool{true, false} {
+		for i := len(rep.Objects) - 1; i >= 0 && size > maxSize; i-- {
+			if omitted[i] || isSuccessful(rep.Objects[i]) != successful {
+				continue
+			}
+			omitted[i] = true
+			// The separating comma is omitted too.
+			size -= encodedSize(rep.Objects[i]) + 1
+			rep.OmittedObjects++
+		}
+	}
+	var kept []ObjectResult
+	for i, result := range rep.Objects {
+		if !omitted[i] {
+			kept = append(kept, result)
+		}
+	}
+	rep.Objects = kept
+	// The counts of the omitted results are in the reserved size.
+	uncounted := *rep
+	uncounted.OmittedObjects = 0
+	size = encodedSize(&uncounted)
+
+	for len(rep.Errors) > 0 && size > maxSize {
+		last := len(rep.Errors) - 1
+		size -= encodedSize(rep.Errors[last]) + 1
+		rep.Errors = rep.Errors[:last]
+		rep.OmittedErrors++
+	}
+	return rep
+}
+
+// isSuccessful returns true if the object was actuated, and reconciled or
+// not waited for.
+func isSuccessful(result ObjectResult) bool {
+	return result.Actuation == actuation.ActuationSucceeded.String() &&
+		(result.Reconcile == actuation.ReconcileSucceeded.String() ||
+			result.Reconcile == actuation.ReconcileSkipped.String())
+}
+
+// truncate returns the message, truncated to maxErrorLength bytes.
+func truncate(msg string) string {
+	if len(msg) <= maxErrorLength {
+		return msg
+	}
+	return strings.ToValidUTF8(msg[:maxErrorLength], "") + "..."
+}
+
+// encodedSize returns the size of the value encoded as JSON. The values of
+// a report always encode.
+func encodedSize(v interface{}) int {
+	data, _ := json.Marshal(v)
+	return len(data)
+}
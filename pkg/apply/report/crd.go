@@ -0,0 +1,113 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"strings"
+)
+
+// CRD is the manifest of the CustomResourceDefinition of the ApplyReport,
+// which must be applied before reports are saved by a CustomResourceStore.
+var CRD = []byte(strings.TrimSpace(`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: applyreports.cli-utils.sigs.k8s.io
+spec:
+  group: cli-utils.sigs.k8s.io
+  names:
+    kind: ApplyReport
+    listKind: ApplyReportList
+    plural: applyreports
+    singular: applyreport
+  scope: Namespaced
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: true
+    additionalPrinterColumns:
+    - jsonPath: .report.operation
+      name: Operation
+      type: string
+    - jsonPath: .report.endTime
+      name: End Time
+      type: date
+    schema:
+      openAPIV3Schema:
+        description: ApplyReport is the report of the last run of the Applier or the Destroyer for an inventory.
+        type: object
+        properties:
+          apiVersion:
+            type: string
+          kind:
+            type: string
+          metadata:
+            type: object
+          report:
+            type: object
+            required:
+            - inventory
+            - operation
+            - startTime
+            - endTime
+            properties:
+              inventory:
+                type: object
+                required:
+                - name
+                properties:
+                  name:
+                    type: string
+                  namespace:
+                    type: string
+                  id:
+                    type: string
+              operation:
+                type: string
+              startTime:
+                type: string
+                format: date-time
+              endTime:
+                type: string
+                format: date-time
+              errors:
+                type: array
+                items:
+                  type: string
+              objects:
+                type: array
+                items:
+                  type: object
+                  required:
+                  - kind
+                  - name
+                  - strategy
+                  - actuation
+                  - reconcile
+                  properties:
+                    group:
+                      type: string
+                    kind:
+                      type: string
+                    name:
+                      type: string
+                    namespace:
+                      type: string
+                    strategy:
+                      type: string
+                    actuation:
+                      type: string
+                    reconcile:
+                      type: string
+                    error:
+                      type: string
+                    actuationDuration:
+                      type: string
+                    reconcileDuration:
+                      type: string
+              omittedErrors:
+                type: integer
+              omittedObjects:
+                type: integer
+`))
@@ -0,0 +1,343 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	deploymentID = object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		Name:      "foo",
+		Namespace: "default",
+	}
+	configMapID = object.ObjMetadata{
+		GroupKind: schema.GroupKind{Kind: "ConfigMap"},
+		Name:      "bar",
+		Namespace: "default",
+	}
+	secretID = object.ObjMetadata{
+		GroupKind: schema.GroupKind{Kind: "Secret"},
+		Name:      "old",
+		Namespace: "default",
+	}
+)
+
+func newInventory() inventory.Info {
+	inv := &unstructured.Unstructured{}
+	inv.SetAPIVersion("v1")
+	inv.SetKind("ConfigMap")
+	inv.SetName("inventory")
+	inv.SetNamespace("default")
+	inv.SetLabels(map[string]string{common.InventoryLabel: "test"})
+	return inventory.WrapInventoryInfoObj(inv)
+}
+
+// fakeStore records the saved reports.
+type fakeStore struct {
+	reports []*Report
+	err     error
+}
+
+func (s *fakeStore) Save(_ context.Context, _ inventory.Info, rep *Report) error {
+	s.reports = append(s.reports, rep)
+	return s.err
+}
+
+// tickingClock returns a clock that advances by a second on every call.
+func tickingClock(start time.Time) func() time.Time {
+	now := start
+	return func() time.Time {
+		t := now
+		now = now.Add(time.Second)
+		return t
+	}
+}
+
+func TestReporter(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &fakeStore{}
+	reporter := &Reporter{Store: store, Now: tickingClock(start)}
+
+	events := []event.Event{
+		{
+			Type: event.InitType,
+			InitEvent: event.InitEvent{
+				ActionGroups: event.ActionGroupList{
+					{Name: "apply-0", Action: event.ApplyAction, Identifiers: object.ObjMetadataSet{deploymentID, configMapID}},
+					{Name: "wait-0", Action: event.WaitAction, Identifiers: object.ObjMetadataSet{deploymentID, configMapID}},
+					{Name: "prune-0", Action: event.PruneAction, Identifiers: object.ObjMetadataSet{secretID}},
+				},
+			},
+		},
+		{
+			Type:             event.ActionGroupType,
+			ActionGroupEvent: event.ActionGroupEvent{GroupName: "apply-0", Action: event.ApplyAction, Status: event.Started},
+		},
+		{
+			Type:       event.ApplyType,
+			ApplyEvent: event.ApplyEvent{GroupName: "apply-0", Identifier: deploymentID, Status: event.ApplySuccessful},
+		},
+		{
+			Type: event.ApplyType,
+			ApplyEvent: event.ApplyEvent{GroupName: "apply-0", Identifier: configMapID, Status: event.ApplyFailed,
+				Error: errors.New("forbidden")},
+		},
+		{
+			Type:      event.WaitType,
+			WaitEvent: event.WaitEvent{GroupName: "wait-0", Identifier: deploymentID, Status: event.ReconcilePending},
+		},
+		{
+			Type:      event.WaitType,
+			WaitEvent: event.WaitEvent{GroupName: "wait-0", Identifier: deploymentID, Status: event.ReconcileTimeout},
+		},
+		{
+			Type:       event.ErrorType,
+			ErrorEvent: event.ErrorEvent{Err: errors.New("context canceled")},
+		},
+	}
+	ch := make(chan event.Event)
+	go func() {
+		defer close(ch)
+		for _, e := range events {
+			ch <- e
+		}
+	}()
+	var received []event.Event
+	for e := range reporter.Report(newInventory(), "apply", ch) {
+		received = append(received, e)
+	}
+	assert.Equal(t, events, received)
+
+	// The report is saved before the channel is closed.
+	require.Len(t, store.reports, 1)
+	assert.Equal(t, &Report{
+		Inventory: InventoryReference{Name: "inventory", Namespace: "default", ID: "test"},
+		Operation: "apply",
+		StartTime: metav1.NewTime(start),
+		EndTime:   metav1.NewTime(start.Add(8 * time.Second)),
+		Errors:    []string{"context canceled"},
+		Objects: []ObjectResult{
+			{
+				ObjectReference: actuation.ObjectReference{
+					Group: "apps", Kind: "Deployment", Name: "foo", Namespace: "default",
+				},
+				Strategy:          "Apply",
+				Actuation:         "Succeeded",
+				Reconcile:         "Timeout",
+				ActuationDuration: metav1.Duration{Duration: time.Second},
+				ReconcileDuration: metav1.Duration{Duration: 3 * time.Second},
+			},
+			{
+				ObjectReference: actuation.ObjectReference{
+					Kind: "ConfigMap", Name: "bar", Namespace: "default",
+				},
+				Strategy:          "Apply",
+				Actuation:         "Failed",
+				Reconcile:         "Skipped",
+				Error:             "forbidden",
+				ActuationDuration: metav1.Duration{Duration: 2 * time.Second},
+			},
+			{
+				ObjectReference: actuation.ObjectReference{
+					Kind: "Secret", Name: "old", Namespace: "default",
+				},
+				Strategy:  "Delete",
+				Actuation: "Pending",
+				Reconcile: "Pending",
+			},
+		},
+	}, store.reports[0])
+
+	// Failing to save the report does not fail the run.
+	store.err = errors.New("forbidden")
+	ch = make(chan event.Event)
+	close(ch)
+	for range reporter.Report(newInventory(), "apply", ch) {
+	}
+	assert.Len(t, store.reports, 2)
+
+	// A nil reporter forwards the channel.
+	var nilReporter *Reporter
+	ch = make(chan event.Event)
+	assert.Equal(t, (<-chan event.Event)(ch), nilReporter.Report(newInventory(), "apply", ch))
+}
+
+func TestConfigMapStore(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme)
+	store := &ConfigMapStore{Client: client}
+	inv := newInventory()
+	ctx := context.Background()
+
+	require.NoError(t, store.Save(ctx, inv, &Report{Operation: "apply"}))
+	second := &Report{
+		Inventory: InventoryReference{Name: "inventory", Namespace: "default", ID: "test"},
+		Operation: "destroy",
+	}
+	require.NoError(t, store.Save(ctx, inv, second))
+
+	obj, err := client.Resource(configMapGVR).Namespace("default").
+		Get(ctx, "inventory-report", metav1.GetOptions{})
+	require.NoError(t, err)
+	data, _, err := unstructured.NestedString(obj.Object, "data", DataKey)
+	require.NoError(t, err)
+	assert.Equal(t, "test", obj.GetLabels()[inventory.OwnerLabel])
+	var saved Report
+	require.NoError(t, json.Unmarshal([]byte(data), &saved))
+	assert.Equal(t, second.Operation, saved.Operation)
+	assert.Equal(t, second.Inventory, saved.Inventory)
+}
+
+func TestCustomResourceStore(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{GroupVersionResource: Kind + "List"})
+	store := &CustomResourceStore{Client: client}
+	inv := newInventory()
+	ctx := context.Background()
+
+	require.NoError(t, store.Save(ctx, inv, &Report{Operation: "apply"}))
+	require.NoError(t, store.Save(ctx, inv, &Report{Operation: "prune"}))
+
+	obj, err := client.Resource(GroupVersionResource).Namespace("default").
+		Get(ctx, "inventory-report", metav1.GetOptions{})
+	require.NoError(t, err)
+	var saved ApplyReport
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &saved))
+	assert.Equal(t, "cli-utils.sigs.k8s.io/v1alpha1", saved.APIVersion)
+	assert.Equal(t, Kind, saved.Kind)
+	assert.Equal(t, "prune", saved.Report.Operation)
+	assert.Equal(t, "test", saved.Labels[inventory.OwnerLabel])
+}
+
+func TestCRD(t *testing.T) {
+	var crd apiextensionsv1.CustomResourceDefinition
+	require.NoError(t, yaml.UnmarshalStrict(CRD, &crd))
+	assert.Equal(t, GroupVersionResource.Resource+"."+GroupVersion.Group, crd.Name)
+	assert.Equal(t, GroupVersion.Group, crd.Spec.Group)
+	assert.Equal(t, Kind, crd.Spec.Names.Kind)
+	assert.Equal(t, GroupVersionResource.Resource, crd.Spec.Names.Plural)
+	require.Len(t, crd.Spec.Versions, 1)
+	assert.Equal(t, GroupVersion.Version, crd.Spec.Versions[0].Name)
+
+	// Every field of the report is in the schema, so it is not pruned.
+	props := crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["report"].Properties
+	rep := &Report{
+		Errors:         []string{"failed"},
+		Objects:        []ObjectResult{{Error: "failed"}},
+		OmittedErrors:  1,
+		OmittedObjects: 1,
+	}
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(rep)
+	require.NoError(t, err)
+	for field := range u {
+		assert.Contains(t, props, field)
+	}
+	objectProps := props["objects"].Items.Schema.Properties
+	for field := range u["objects"].([]interface{})[0].(map[string]interface{}) {
+		assert.Contains(t, objectProps, field)
+	}
+}
+
+func TestBound(t *testing.T) {
+	longError := strings.Repeat("x", 2*maxErrorLength)
+	newReport := func() *Report {
+		rep := &Report{
+			Operation: "apply",
+			Errors:    []string{longError, "second"},
+		}
+		for i := 0; i < 100; i++ {
+			result := ObjectResult{
+				ObjectReference: actuation.ObjectReference{Kind: "ConfigMap", Name: fmt.Sprintf("cm-%d", i)},
+				Strategy:        actuation.ActuationStrategyApply.String(),
+				Actuation:       actuation.ActuationSucceeded.String(),
+				Reconcile:       actuation.ReconcileSucceeded.String(),
+			}
+			if i%10 == 0 {
+				result.Actuation = actuation.ActuationFailed.String()
+				result.Reconcile = actuation.ReconcileSkipped.String()
+				result.Error = longError
+			}
+			rep.Objects = append(rep.Objects, result)
+		}
+		return rep
+	}
+
+	// Long errors are truncated.
+	rep := bound(newReport(), DefaultMaxSize)
+	assert.Len(t, rep.Errors[0], maxErrorLength+len("..."))
+	assert.Len(t, rep.Objects[0].Error, maxErrorLength+len("..."))
+	assert.Len(t, rep.Objects, 100)
+	assert.Zero(t, rep.OmittedObjects)
+
+	// Successful results are omitted first.
+	maxSize := encodedSize(newReport()) / 2
+	rep = bound(newReport(), maxSize)
+	assert.LessOrEqual(t, encodedSize(rep), maxSize)
+	assert.Equal(t, 100-len(rep.Objects), rep.OmittedObjects)
+	assert.Positive(t, rep.OmittedObjects)
+	failed := 0
+	for _, result := range rep.Objects {
+		if result.Actuation == actuation.ActuationFailed.String() {
+			failed++
+		}
+	}
+	assert.Equal(t, 10, failed)
+	assert.Len(t, rep.Errors, 2)
+
+	// Errors are omitted last.
+	maxSize = encodedSize(&Report{
+		Operation: "apply",
+		Errors:    []string{truncate(longError)},
+	}) + omittedSize
+	rep = bound(newReport(), maxSize)
+	assert.LessOrEqual(t, encodedSize(rep), maxSize)
+	assert.Empty(t, rep.Objects)
+	assert.Equal(t, 100, rep.OmittedObjects)
+	assert.Equal(t, []string{truncate(longError)}, rep.Errors)
+	assert.Equal(t, 1, rep.OmittedErrors)
+}
+
+func TestConfigMapStore_Owner(t *testing.T) {
+	other := &unstructured.Unstructured{}
+	other.SetAPIVersion("v1")
+	other.SetKind("ConfigMap")
+	other.SetName("inventory-report")
+	other.SetNamespace("default")
+	other.SetLabels(map[string]string{inventory.OwnerLabel: "other"})
+	client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, other)
+	store := &ConfigMapStore{Client: client}
+	ctx := context.Background()
+
+	err := store.Save(ctx, newInventory(), &Report{Operation: "apply"})
+	assert.EqualError(t, err, `failed to save report: refusing to overwrite ConfigMap default/inventory-report: `+
+		`not owned by inventory "test" (label cli-utils.sigs.k8s.io/owner-inventory-id="other")`)
+
+	obj, err := client.Resource(configMapGVR).Namespace("default").
+		Get(ctx, "inventory-report", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, other, obj)
+}
@@ -0,0 +1,251 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package report writes a structured report of each run of the Applier and
+// the Destroyer back to the cluster, with the actuation status, reconcile
+// status, error and durations of each object, so dashboards can show the
+// results of the runs without scraping their logs.
+//
+// Reports are enabled with the WithReportStore method of the ApplierBuilder
+// and the DestroyerBuilder. The methods of a nil *Reporter do nothing, so
+// callers do not need to check whether reports are enabled.
+package report
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Reporter builds the report of a run from its events, and saves it to the
+// store when the run completes.
+type Reporter struct {
+	Store Store
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+	// MaxSize is the maximum size of the encoded report, in bytes. Results
+	// are omitted from larger reports. Defaults to DefaultMaxSize.
+	MaxSize int
+}
+
+// Report builds the report of the run of the operation from the events
+// received from the passed channel, and forwards the events to the returned
+// channel. The report is saved before the returned channel is closed.
+// Failing to save the report does not fail the run, and is only logged.
+func (r *Reporter) Report(inv inventory.Info, operation string, ch <-chan event.Event) <-chan event.Event {
+	if r == nil {
+		return ch
+	}
+	out := make(chan event.Event)
+	go func() {
+		defer close(out)
+		b := newBuilder(inv, operation, r.now())
+		for e := range ch {
+			b.add(e, r.now())
+			out <- e
+		}
+		rep := bound(b.build(r.now()), r.maxSize())
+		// The context of the run may have been cancelled, which must not
+		// prevent the report of the run from being saved.
+		if err := r.Store.Save(context.Background(), inv, rep); err != nil {
+			klog.Warningf("failed to save report of inventory %s/%s: %v", inv.Namespace(), inv.Name(), err)
+		}
+	}()
+	return out
+}
+
+func (r *Reporter) maxSize() int {
+	if r.MaxSize <= 0 {
+		return DefaultMaxSize
+	}
+	return r.MaxSize
+}
+
+func (r *Reporter) now() time.Time {
+	if r.Now == nil {
+		return time.Now()
+	}
+	return r.Now()
+}
+
+// builder accumulates the report of a run.
+type builder struct {
+	report *Report
+	// results are the indices of the results of the objects.
+	results map[object.ObjMetadata]int
+	// groupStarts are the start times of the actuation tasks, by name.
+	groupStarts map[string]time.Time
+	// actuated are the actuation times of the objects.
+	actuated map[object.ObjMetadata]time.Time
+}
+
+func newBuilder(inv inventory.Info, operation string, now time.Time) *builder {
+	return &builder{
+		report: &Report{
+			Inventory: InventoryReference{
+				Name:      inv.Name(),
+				Namespace: inv.Namespace(),
+				ID:        inv.ID(),
+			},
+			Operation: operation,
+			StartTime: metav1.NewTime(now),
+		},
+		results:     make(map[object.ObjMetadata]int),
+		groupStarts: make(map[string]time.Time),
+		actuated:    make(map[object.ObjMetadata]time.Time),
+	}
+}
+
+// add updates the report with the event, received at the passed time.
+func (b *builder) add(e event.Event, now time.Time) {
+	switch e.Type {
+	case event.InitType:
+		for _, ag := range e.InitEvent.ActionGroups {
+			strategy, ok := actionStrategy(ag.Action)
+			if !ok {
+				continue
+			}
+			for _, id := range ag.Identifiers {
+				b.register(id, strategy)
+			}
+		}
+	case event.ErrorType:
+		b.report.Errors = append(b.report.Errors, e.ErrorEvent.Err.Error())
+	case event.ValidationType:
+		b.report.Errors = append(b.report.Errors, e.ValidationEvent.Error.Error())
+	case event.ActionGroupType:
+		if e.ActionGroupEvent.Status == event.Started {
+			b.groupStarts[e.ActionGroupEvent.GroupName] = now
+		}
+	case event.ApplyType:
+		ae := e.ApplyEvent
+		switch ae.Status {
+		case event.ApplySuccessful:
+			b.actuate(ae.GroupName, ae.Identifier, actuation.ActuationSucceeded, nil, now)
+		case event.ApplySkipped:
+			b.actuate(ae.GroupName, ae.Identifier, actuation.ActuationSkipped, ae.Error, now)
+		case event.ApplyFailed:
+			b.actuate(ae.GroupName, ae.Identifier, actuation.ActuationFailed, ae.Error, now)
+		}
+	case event.PruneType:
+		pe := e.PruneEvent
+		switch pe.Status {
+		case event.PruneSuccessful:
+			b.actuate(pe.GroupName, pe.Identifier, actuation.ActuationSucceeded, nil, now)
+		case event.PruneSkipped, event.PruneDetached:
+			b.actuate(pe.GroupName, pe.Identifier, actuation.ActuationSkipped, pe.Error, now)
+		case event.PruneFailed:
+			b.actuate(pe.GroupName, pe.Identifier, actuation.ActuationFailed, pe.Error, now)
+		}
+	case event.DeleteType:
+		de := e.DeleteEvent
+		switch de.Status {
+		case event.DeleteSuccessful:
+			b.actuate(de.GroupName, de.Identifier, actuation.ActuationSucceeded, nil, now)
+		case event.DeleteSkipped, event.DeleteDetached:
+			b.actuate(de.GroupName, de.Identifier, actuation.ActuationSkipped, de.Error, now)
+		case event.DeleteFailed:
+			b.actuate(de.GroupName, de.Identifier, actuation.ActuationFailed, de.Error, now)
+		}
+	case event.WaitType:
+		we := e.WaitEvent
+		switch we.Status {
+		case event.ReconcileSuccessful:
+			b.reconcile(we.Identifier, actuation.ReconcileSucceeded, now)
+		case event.ReconcileSkipped, event.ReconcileSkippedByAnnotation:
+			b.reconcile(we.Identifier, actuation.ReconcileSkipped, now)
+		case event.ReconcileFailed:
+			b.reconcile(we.Identifier, actuation.ReconcileFailed, now)
+		case event.ReconcileTimeout:
+			b.reconcile(we.Identifier, actuation.ReconcileTimeout, now)
+		}
+	}
+}
+
+// actionStrategy returns the actuation strategy of the action, or false if
+// the objects of the action are not actuated.
+func actionStrategy(action event.ResourceAction) (actuation.ActuationStrategy, bool) {
+	switch action {
+	case event.ApplyAction:
+		return actuation.ActuationStrategyApply, true
+	case event.PruneAction, event.DeleteAction:
+		return actuation.ActuationStrategyDelete, true
+	default:
+		return 0, false
+	}
+}
+
+// register adds the pending result of the object.
+func (b *builder) register(id object.ObjMetadata, strategy actuation.ActuationStrategy) {
+	if _, found := b.results[id]; found {
+		return
+	}
+	b.report.Objects = append(b.report.Objects, ObjectResult{
+		ObjectReference: actuation.ObjectReference{
+			Group:     id.GroupKind.Group,
+			Kind:      id.GroupKind.Kind,
+			Name:      id.Name,
+			Namespace: id.Namespace,
+		},
+		Strategy:  strategy.String(),
+		Actuation: actuation.ActuationPending.String(),
+		Reconcile: actuation.ReconcilePending.String(),
+	})
+	b.results[id] = len(b.report.Objects) - 1
+}
+
+// actuate records the actuation result of the object. Objects that are not
+// registered by the init event are ignored.
+func (b *builder) actuate(groupName string, id object.ObjMetadata, status actuation.ActuationStatus, err error, now time.Time) {
+	result := b.result(id)
+	if result == nil {
+		return
+	}
+	result.Actuation = status.String()
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if start, found := b.groupStarts[groupName]; found {
+		result.ActuationDuration = metav1.Duration{Duration: now.Sub(start)}
+	}
+	if status == actuation.ActuationSucceeded {
+		b.actuated[id] = now
+	} else {
+		// Objects that were not actuated are not waited for.
+		result.Reconcile = actuation.ReconcileSkipped.String()
+	}
+}
+
+// reconcile records the reconcile result of the object. Objects that are
+// not registered by the init event, e.g. external dependencies, are ignored.
+func (b *builder) reconcile(id object.ObjMetadata, status actuation.ReconcileStatus, now time.Time) {
+	result := b.result(id)
+	if result == nil {
+		return
+	}
+	result.Reconcile = status.String()
+	if actuated, found := b.actuated[id]; found {
+		result.ReconcileDuration = metav1.Duration{Duration: now.Sub(actuated)}
+	}
+}
+
+// result returns the result of the object, or nil if it is not registered.
+func (b *builder) result(id object.ObjMetadata) *ObjectResult {
+	i, found := b.results[id]
+	if !found {
+		return nil
+	}
+	return &b.report.Objects[i]
+}
+
+// build returns the report of the run, completed at the passed time.
+func (b *builder) build(now time.Time) *Report {
+	b.report.EndTime = metav1.NewTime(now)
+	return b.report
+}
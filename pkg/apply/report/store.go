@@ -0,0 +1,118 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+)
+
+// Store saves the report of the last run of an inventory.
+type Store interface {
+	// Save replaces the report of the inventory.
+	Save(ctx context.Context, inv inventory.Info, rep *Report) error
+}
+
+// DataKey is the key of the report in the data of the ConfigMap.
+const DataKey = "report"
+
+var (
+	configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+	// GroupVersionResource is the resource of the ApplyReport custom
+	// resource.
+	GroupVersionResource = GroupVersion.WithResource("applyreports")
+)
+
+// Name returns the name of the object of the report of the inventory.
+func Name(inv inventory.Info) string {
+	return inv.Name() + "-report"
+}
+
+// ConfigMapStore stores the report of an inventory as JSON in a ConfigMap
+// next to the inventory object, named after it with the suffix "-report"
+// and labeled with the inventory.OwnerLabel.
+type ConfigMapStore struct {
+	Client dynamic.Interface
+}
+
+var _ Store = &ConfigMapStore{}
+
+// Save creates or updates the ConfigMap with the report.
+func (s *ConfigMapStore) Save(ctx context.Context, inv inventory.Info, rep *Report) error {
+	data, err := json.Marshal(rep)
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetName(Name(inv))
+	obj.SetNamespace(inv.Namespace())
+	if err := unstructured.SetNestedField(obj.Object, string(data), "data", DataKey); err != nil {
+		return err
+	}
+	return save(ctx, s.Client.Resource(configMapGVR).Namespace(inv.Namespace()), inv, obj)
+}
+
+// CustomResourceStore stores the report of an inventory in an ApplyReport
+// custom resource next to the inventory object, named after it with the
+// suffix "-report" and labeled with the inventory.OwnerLabel. The CRD
+// manifest of the ApplyReport is CRD.
+type CustomResourceStore struct {
+	Client dynamic.Interface
+}
+
+var _ Store = &CustomResourceStore{}
+
+// Save creates or updates the ApplyReport with the report.
+func (s *CustomResourceStore) Save(ctx context.Context, inv inventory.Info, rep *Report) error {
+	ar := &ApplyReport{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: GroupVersion.String(),
+			Kind:       Kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Name(inv),
+			Namespace: inv.Namespace(),
+		},
+		Report: *rep,
+	}
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ar)
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	obj := &unstructured.Unstructured{Object: u}
+	return save(ctx, s.Client.Resource(GroupVersionResource).Namespace(inv.Namespace()), inv, obj)
+}
+
+// save creates the object, owned by the inventory, or updates it if it
+// exists. Objects that are not owned by the inventory are not updated.
+func save(ctx context.Context, client dynamic.ResourceInterface, inv inventory.Info, obj *unstructured.Unstructured) error {
+	inventory.SetOwner(obj, inv)
+	live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = client.Create(ctx, obj, metav1.CreateOptions{})
+	case err == nil:
+		if err = inventory.CheckOwner(inv, live); err != nil {
+			break
+		}
+		obj.SetResourceVersion(live.GetResourceVersion())
+		_, err = client.Update(ctx, obj, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+)
+
+// GroupVersion is the API group and version of the ApplyReport custom
+// resource.
+var GroupVersion = schema.GroupVersion{Group: "cli-utils.sigs.k8s.io", Version: "v1alpha1"}
+
+// Kind is the kind of the ApplyReport custom resource.
+const Kind = "ApplyReport"
+
+// ApplyReport is the custom resource the report of a run is written to by
+// the CustomResourceStore. Its CustomResourceDefinition, CRD, must be
+// installed in the cluster.
+type ApplyReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Report Report `json:"report"`
+}
+
+// Report summarizes the actuation and reconcile results of the objects of a
+// single run of the Applier or the Destroyer.
+type Report struct {
+	// Inventory identifies the inventory of the run.
+	Inventory InventoryReference `json:"inventory"`
+	// Operation is the operation of the run, e.g. "apply", "prune" or
+	// "destroy", like the operation label of the metrics.
+	Operation string `json:"operation"`
+	// StartTime is the time the run started.
+	StartTime metav1.Time `json:"startTime"`
+	// EndTime is the time the run completed.
+	EndTime metav1.Time `json:"endTime"`
+	// Errors are the errors that terminated the run, or invalidated objects.
+	// Errors of single objects are reported in their results.
+	// +optional
+	Errors []string `json:"errors,omitempty"`
+	// Objects are the results of the objects that were planned to be
+	// applied or deleted, in the order of their tasks.
+	// +optional
+	Objects []ObjectResult `json:"objects,omitempty"`
+	// OmittedErrors is the number of errors omitted from the report, to
+	// bound its size.
+	// +optional
+	OmittedErrors int `json:"omittedErrors,omitempty"`
+	// OmittedObjects is the number of object results omitted from the
+	// report, to bound its size.
+	// +optional
+	OmittedObjects int `json:"omittedObjects,omitempty"`
+}
+
+// InventoryReference identifies the inventory of a run.
+type InventoryReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// ID is the inventory ID, which is the value of the owning-inventory
+	// annotation of the objects.
+	ID string `json:"id,omitempty"`
+}
+
+// ObjectResult is the result of a single object of a run.
+type ObjectResult struct {
+	actuation.ObjectReference `json:",inline"`
+
+	// Strategy is how the object was actuated, "Apply" or "Delete".
+	Strategy string `json:"strategy"`
+	// Actuation is the actuation status: "Pending", "Succeeded", "Skipped"
+	// or "Failed". Pending objects were not actuated before the run
+	// completed, e.g. because it was cancelled.
+	Actuation string `json:"actuation"`
+	// Reconcile is the reconcile status: "Pending", "Succeeded", "Skipped",
+	// "Failed" or "Timeout".
+	Reconcile string `json:"reconcile"`
+	// Error is the error of the actuation, if it failed or was skipped.
+	// +optional
+	Error string `json:"error,omitempty"`
+	// ActuationDuration is how long it took from the start of the task of
+	// the object until it was actuated.
+	// +optional
+	ActuationDuration metav1.Duration `json:"actuationDuration,omitempty"`
+	// ReconcileDuration is how long it took from the actuation of the
+	// object until it was reconciled, failed or timed out.
+	// +optional
+	ReconcileDuration metav1.Duration `json:"reconcileDuration,omitempty"`
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package rollback records the objects applied during a run and reverts
+// them, when the run fails.
+package rollback
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Entry is an object applied during a run.
+type Entry struct {
+	Identifier object.ObjMetadata
+	// Previous is the live object before it was first applied during the
+	// run, or nil if the run created the object.
+	Previous *unstructured.Unstructured
+}
+
+// Created returns true if the object did not exist before the run.
+func (e Entry) Created() bool {
+	return e.Previous == nil
+}
+
+// Journal records the objects applied during a run, in the order they were
+// applied. It is safe for concurrent use.
+type Journal struct {
+	mu       sync.Mutex
+	entries  []Entry
+	recorded map[object.ObjMetadata]bool
+}
+
+// NewJournal returns an empty Journal.
+func NewJournal() *Journal {
+	return &Journal{
+		recorded: make(map[object.ObjMetadata]bool),
+	}
+}
+
+// Record adds an applied object to the journal, with its state before it
+// was applied. Only the first record of each object is kept, so rolling
+// back restores the state from before the run.
+func (j *Journal) Record(id object.ObjMetadata, previous *unstructured.Unstructured) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.recorded[id] {
+		return
+	}
+	j.recorded[id] = true
+	if previous != nil {
+		previous = previous.DeepCopy()
+	}
+	j.entries = append(j.entries, Entry{Identifier: id, Previous: previous})
+}
+
+// Entries returns the recorded objects in the order they were applied.
+func (j *Journal) Entries() []Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries := make([]Entry, len(j.entries))
+	copy(entries, j.entries)
+	return entries
+}
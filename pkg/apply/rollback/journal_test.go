@@ -0,0 +1,43 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package rollback
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestJournal_Record(t *testing.T) {
+	deploymentID := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		Name:      "foo",
+		Namespace: "default",
+	}
+	secretID := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Kind: "Secret"},
+		Name:      "bar",
+		Namespace: "default",
+	}
+	previous := &unstructured.Unstructured{}
+	previous.SetResourceVersion("1")
+
+	j := NewJournal()
+	j.Record(secretID, nil)
+	j.Record(deploymentID, previous)
+	// Later records must not replace the state before the run.
+	j.Record(secretID, previous)
+	previous.SetResourceVersion("2")
+
+	entries := j.Entries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, secretID, entries[0].Identifier)
+	assert.True(t, entries[0].Created())
+	assert.Equal(t, deploymentID, entries[1].Identifier)
+	assert.False(t, entries[1].Created())
+	assert.Equal(t, "1", entries[1].Previous.GetResourceVersion())
+}
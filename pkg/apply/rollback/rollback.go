@@ -0,0 +1,56 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package rollback
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/reason"
+)
+
+// Rollbacker reverts the objects recorded in a Journal.
+type Rollbacker struct {
+	Client dynamic.Interface
+	Mapper meta.RESTMapper
+}
+
+// Rollback reverts a single journal entry. Objects created during the run
+// are deleted. Objects that existed before the run are updated to their
+// previous state. Objects that no longer exist are ignored.
+func (r *Rollbacker) Rollback(ctx context.Context, entry Entry) error {
+	client, err := object.ResourceClient(r.Client, r.Mapper, entry.Identifier)
+	if err != nil {
+		return err
+	}
+	if entry.Created() {
+		propagation := metav1.DeletePropagationBackground
+		err := client.Delete(ctx, entry.Identifier.Name, metav1.DeleteOptions{
+			PropagationPolicy: &propagation,
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return reason.ForObject(entry.Identifier, fmt.Errorf("failed to delete %s: %w", entry.Identifier, err))
+		}
+		return nil
+	}
+
+	live, err := client.Get(ctx, entry.Identifier.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return reason.ForObject(entry.Identifier, fmt.Errorf("failed to get %s: %w", entry.Identifier, err))
+	}
+	previous := entry.Previous.DeepCopy()
+	previous.SetResourceVersion(live.GetResourceVersion())
+	if _, err := client.Update(ctx, previous, metav1.UpdateOptions{}); err != nil {
+		return reason.ForObject(entry.Identifier, fmt.Errorf("failed to revert %s: %w", entry.Identifier, err))
+	}
+	return nil
+}
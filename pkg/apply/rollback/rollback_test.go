@@ -0,0 +1,80 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package rollback
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func configMap(name, value string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+			"data": map[string]interface{}{
+				"key": value,
+			},
+		},
+	}
+}
+
+func TestRollbacker_Rollback(t *testing.T) {
+	created := configMap("created", "new")
+	updated := configMap("updated", "new")
+	missing := configMap("missing", "old")
+
+	client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme,
+		[]runtime.Object{created, updated}...)
+	rollbacker := &Rollbacker{
+		Client: client,
+		Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+			scheme.Scheme.PrioritizedVersionsAllGroups()...),
+	}
+
+	ctx := context.TODO()
+	require.NoError(t, rollbacker.Rollback(ctx, Entry{
+		Identifier: object.UnstructuredToObjMetadata(created),
+	}))
+	require.NoError(t, rollbacker.Rollback(ctx, Entry{
+		Identifier: object.UnstructuredToObjMetadata(updated),
+		Previous:   configMap("updated", "old"),
+	}))
+	// Objects deleted by another actor are ignored.
+	require.NoError(t, rollbacker.Rollback(ctx, Entry{
+		Identifier: object.UnstructuredToObjMetadata(missing),
+		Previous:   missing,
+	}))
+	require.NoError(t, rollbacker.Rollback(ctx, Entry{
+		Identifier: object.UnstructuredToObjMetadata(missing),
+	}))
+
+	configMaps := client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).
+		Namespace("default")
+	_, err := configMaps.Get(ctx, "created", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), "created object was not deleted")
+	live, err := configMaps.Get(ctx, "updated", metav1.GetOptions{})
+	require.NoError(t, err)
+	value, _, err := unstructured.NestedString(live.Object, "data", "key")
+	require.NoError(t, err)
+	assert.Equal(t, "old", value)
+	_, err = configMaps.Get(ctx, "missing", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), "missing object was recreated")
+}
@@ -0,0 +1,31 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// ApplyRunner runs applies and prunes of an inventory. It is implemented by
+// the Applier, and by the fake.Applier for testing code that drives an
+// Applier without a cluster.
+type ApplyRunner interface {
+	Run(ctx context.Context, invInfo inventory.Info, objects object.UnstructuredSet, options ApplierOptions) <-chan event.Event
+	Prune(ctx context.Context, invInfo inventory.Info, objects object.UnstructuredSet, options ApplierOptions) <-chan event.Event
+}
+
+// DestroyRunner runs destroys of an inventory. It is implemented by the
+// Destroyer, and by the fake.Destroyer for testing.
+type DestroyRunner interface {
+	Run(ctx context.Context, invInfo inventory.Info, options DestroyerOptions) <-chan event.Event
+}
+
+var (
+	_ ApplyRunner   = &Applier{}
+	_ DestroyRunner = &Destroyer{}
+)
@@ -0,0 +1,74 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Selector restricts the objects of a run that are actuated, e.g. to only
+// apply the ConfigMaps of a package. An object is selected if it matches all
+// of the set criteria.
+type Selector struct {
+	// LabelSelector selects the objects with matching labels. If nil,
+	// labels are not matched.
+	LabelSelector labels.Selector
+	// IncludeKinds selects the objects of the kinds. If empty, objects of
+	// all kinds are selected.
+	IncludeKinds []schema.GroupKind
+	// ExcludeKinds excludes the objects of the kinds.
+	ExcludeKinds []schema.GroupKind
+	// IncludeObjects selects the objects with the identifiers. If empty,
+	// objects are not matched by identifier.
+	IncludeObjects object.ObjMetadataSet
+	// ExcludeObjects excludes the objects with the identifiers.
+	ExcludeObjects object.ObjMetadataSet
+}
+
+// Matches returns true if the object is selected.
+func (s *Selector) Matches(obj *unstructured.Unstructured) bool {
+	if s.LabelSelector != nil && !s.LabelSelector.Matches(labels.Set(obj.GetLabels())) {
+		return false
+	}
+	gk := obj.GroupVersionKind().GroupKind()
+	if len(s.IncludeKinds) > 0 && !containsGroupKind(s.IncludeKinds, gk) {
+		return false
+	}
+	if containsGroupKind(s.ExcludeKinds, gk) {
+		return false
+	}
+	if len(s.IncludeObjects) == 0 && len(s.ExcludeObjects) == 0 {
+		return true
+	}
+	id := object.UnstructuredToObjMetadata(obj)
+	if len(s.IncludeObjects) > 0 && !s.IncludeObjects.Contains(id) {
+		return false
+	}
+	return !s.ExcludeObjects.Contains(id)
+}
+
+// split returns the selected and the excluded objects, in order.
+func (s *Selector) split(objs object.UnstructuredSet) (object.UnstructuredSet, object.UnstructuredSet) {
+	var selected, excluded object.UnstructuredSet
+	for _, obj := range objs {
+		if s.Matches(obj) {
+			selected = append(selected, obj)
+		} else {
+			excluded = append(excluded, obj)
+		}
+	}
+	return selected, excluded
+}
+
+func containsGroupKind(gks []schema.GroupKind, gk schema.GroupKind) bool {
+	for _, k := range gks {
+		if k == gk {
+			return true
+		}
+	}
+	return false
+}
@@ -20,6 +20,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/klog/v2"
@@ -27,13 +28,19 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/apply/filter"
 	"sigs.k8s.io/cli-utils/pkg/apply/info"
 	"sigs.k8s.io/cli-utils/pkg/apply/mutator"
+	"sigs.k8s.io/cli-utils/pkg/apply/policy"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
+	"sigs.k8s.io/cli-utils/pkg/apply/rollback"
 	"sigs.k8s.io/cli-utils/pkg/apply/task"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/metrics"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/cli-utils/pkg/object/graph"
+	"sigs.k8s.io/cli-utils/pkg/object/readycondition"
+	"sigs.k8s.io/cli-utils/pkg/object/reconciletimeout"
+	"sigs.k8s.io/cli-utils/pkg/object/skipwait"
 	"sigs.k8s.io/cli-utils/pkg/object/validation"
 )
 
@@ -49,7 +56,13 @@ type TaskQueueBuilder struct {
 	Collector     *validation.Collector
 	ApplyFilters  []filter.ValidationFilter
 	ApplyMutators []mutator.Interface
+	PolicyGates   []policy.Gate
 	PruneFilters  []filter.ValidationFilter
+	// DependencyRules add implicit dependencies between the objects, in
+	// addition to the built-in ones. Optional.
+	DependencyRules []graph.Rule
+	// Metrics records the duration of each apply. Optional.
+	Metrics *metrics.Metrics
 
 	// The accumulated tasks and counter variables to name tasks.
 	applyCounter int
@@ -62,7 +75,8 @@ type TaskQueueBuilder struct {
 }
 
 type TaskQueue struct {
-	tasks []taskrunner.Task
+	tasks  []taskrunner.Task
+	traces []event.TraceEvent
 }
 
 func (tq *TaskQueue) ToChannel() chan taskrunner.Task {
@@ -73,6 +87,49 @@ func (tq *TaskQueue) ToChannel() chan taskrunner.Task {
 	return taskQueue
 }
 
+// TraceEvents returns the events explaining the task group of each object,
+// in task order. Only populated if Options.EmitTraceEvents is true.
+func (tq *TaskQueue) TraceEvents() []event.TraceEvent {
+	return tq.traces
+}
+
+// SkipCompleted removes the tasks that completed during a previous run of
+// the same queue from the start of the queue, e.g. to resume the run from a
+// checkpoint. An error is returned if the named tasks are not the tasks at
+// the start of the queue. External wait tasks are never removed, and must
+// not be named: the readiness of the external dependencies is not part of
+// the checkpoint, so they are waited for again.
+func (tq *TaskQueue) SkipCompleted(completed []string) error {
+	skippable := 0
+	for _, t := range tq.tasks {
+		if !isExternalWaitTask(t) {
+			skippable++
+		}
+	}
+	if len(completed) > skippable {
+		return fmt.Errorf("%d tasks completed, but the queue has only %d tasks", len(completed), skippable)
+	}
+	var kept []taskrunner.Task
+	i := 0
+	for _, name := range completed {
+		for isExternalWaitTask(tq.tasks[i]) {
+			kept = append(kept, tq.tasks[i])
+			i++
+		}
+		if tq.tasks[i].Name() != name {
+			return fmt.Errorf("completed task %q does not match task %q of the queue", name, tq.tasks[i].Name())
+		}
+		i++
+	}
+	tq.tasks = append(kept, tq.tasks[i:]...)
+	return nil
+}
+
+func isExternalWaitTask(t taskrunner.Task) bool {
+	_, ok := t.(*taskrunner.ExternalWaitTask)
+	return ok
+}
+
 func (tq *TaskQueue) ToActionGroups() []event.ActionGroup {
 	var ags []event.ActionGroup
 
@@ -96,8 +153,56 @@ type Options struct {
 	Prune                  bool
 	DryRunStrategy         common.DryRunStrategy
 	PrunePropagationPolicy metav1.DeletionPropagation
-	PruneTimeout           time.Duration
-	InventoryPolicy        inventory.Policy
+	// PruneGracePeriodSeconds is passed to each delete request. If nil,
+	// the default grace period of the resource type is used.
+	PruneGracePeriodSeconds *int64
+	PruneTimeout            time.Duration
+	// PruneOrdering defines the order in which prune objects are deleted.
+	// Defaults to prune.OrderingReverseApply.
+	PruneOrdering prune.Ordering
+	// PruneConcurrency is the maximum number of objects each prune task
+	// deletes at the same time.
+	PruneConcurrency int
+	InventoryPolicy  inventory.Policy
+	// RetryPolicy defines how apply and prune tasks retry transient
+	// errors. If nil, operations are not retried.
+	RetryPolicy *task.RetryPolicy
+	// EmitDiffEvents defines whether apply tasks send a diff event for
+	// each object before it is applied.
+	EmitDiffEvents bool
+	// EmitTraceEvents defines whether the task queue includes a trace event
+	// for each apply and prune object, explaining its task group.
+	EmitTraceEvents bool
+	// Concurrency is the maximum number of objects each apply task applies
+	// at the same time.
+	Concurrency int
+	// Throttle limits the rate of apply and delete requests, shared by all
+	// tasks. If nil, requests are not throttled.
+	Throttle *task.Throttle
+	// UpgradeClientSideApply defines whether apply tasks transfer the
+	// ownership of fields managed with client-side apply to the
+	// server-side apply field manager before applying each object.
+	UpgradeClientSideApply bool
+	// ClientSideApplyFallback defines whether apply tasks apply objects with
+	// client-side apply, if server-side apply fails.
+	ClientSideApplyFallback bool
+	// Journal records the objects applied by the apply tasks, so they can
+	// be rolled back. If nil, nothing is recorded.
+	Journal *rollback.Journal
+	// WaitForExternalDependencies allows depends-on annotations to reference
+	// objects that are not applied or pruned. An external wait task waits
+	// for them before the first apply task.
+	WaitForExternalDependencies bool
+	// ExternalDependencyCondition is the condition the external
+	// dependencies are waited for, either taskrunner.AllCurrent or
+	// taskrunner.AllExist. Defaults to taskrunner.AllCurrent.
+	ExternalDependencyCondition taskrunner.Condition
+	// ExternalDependencyTimeout defines how long to wait for the external
+	// dependencies. Zero means no timeout.
+	ExternalDependencyTimeout time.Duration
+	// SatisfiedDependencies are objects outside the set that depends-on
+	// annotations may reference without adding a dependency.
+	SatisfiedDependencies object.ObjMetadataSet
 }
 
 // WithInventory sets the inventory info and returns the builder for chaining.
@@ -121,6 +226,7 @@ func (t *TaskQueueBuilder) WithPruneObjects(pruneObjs object.UnstructuredSet) *T
 // Build returns the queue of tasks that have been created
 func (t *TaskQueueBuilder) Build(taskContext *taskrunner.TaskContext, o Options) *TaskQueue {
 	var tasks []taskrunner.Task
+	var traces []event.TraceEvent
 
 	// reset counters
 	t.applyCounter = 0
@@ -137,7 +243,11 @@ func (t *TaskQueueBuilder) Build(taskContext *taskrunner.TaskContext, o Options)
 	allObjs := make(object.UnstructuredSet, 0, len(applyObjs)+len(pruneObjs))
 	allObjs = append(allObjs, applyObjs...)
 	allObjs = append(allObjs, pruneObjs...)
-	g, err := graph.DependencyGraph(allObjs)
+	g, err := graph.DependencyGraphWithOptions(allObjs, graph.Options{
+		Rules:                 t.DependencyRules,
+		ExternalDependencies:  o.WaitForExternalDependencies,
+		SatisfiedDependencies: o.SatisfiedDependencies,
+	})
 	if err != nil {
 		t.Collector.Collect(err)
 	}
@@ -145,12 +255,54 @@ func (t *TaskQueueBuilder) Build(taskContext *taskrunner.TaskContext, o Options)
 	taskContext.SetGraph(g)
 	// Sort objects into phases (apply order).
 	// Cycles will be treated as validation errors.
-	idSetList, err := g.Sort()
-	if err != nil {
-		t.Collector.Collect(err)
+	idSetList, sortErr := g.Sort()
+	if sortErr != nil {
+		t.Collector.Collect(sortErr)
+	}
+
+	// Invalid conflict policy annotations will be treated as validation errors.
+	for _, obj := range applyObjs {
+		if _, _, err := task.ReadConflictPolicy(obj); err != nil {
+			t.Collector.Collect(validation.NewError(err, object.UnstructuredToObjMetadata(obj)))
+		}
 	}
 
-	// Filter objects with cycles or invalid dependency annotations
+	// Invalid actuation strategy annotations will be treated as validation errors.
+	for _, obj := range applyObjs {
+		if _, err := filter.ReadActuationStrategy(obj); err != nil {
+			t.Collector.Collect(validation.NewError(err, object.UnstructuredToObjMetadata(obj)))
+		}
+	}
+
+	// Invalid apply strategy annotations will be treated as validation errors.
+	for _, obj := range applyObjs {
+		if _, _, err := task.ReadApplyStrategy(obj); err != nil {
+			t.Collector.Collect(validation.NewError(err, object.UnstructuredToObjMetadata(obj)))
+		}
+	}
+
+	// Invalid reconcile timeout annotations will be treated as validation errors.
+	for _, obj := range applyObjs {
+		if _, err := reconciletimeout.ReadAnnotation(obj); err != nil {
+			t.Collector.Collect(validation.NewError(err, object.UnstructuredToObjMetadata(obj)))
+		}
+	}
+
+	// Invalid ready condition annotations will be treated as validation errors.
+	for _, obj := range applyObjs {
+		if _, err := readycondition.ReadAnnotation(obj); err != nil {
+			t.Collector.Collect(validation.NewError(err, object.UnstructuredToObjMetadata(obj)))
+		}
+	}
+
+	// Invalid reconcile annotations will be treated as validation errors.
+	for _, obj := range applyObjs {
+		if _, err := skipwait.ReadAnnotation(obj); err != nil {
+			t.Collector.Collect(validation.NewError(err, object.UnstructuredToObjMetadata(obj)))
+		}
+	}
+
+	// Filter objects with cycles or invalid annotations
 	applyObjs = t.Collector.FilterInvalidObjects(applyObjs)
 	pruneObjs = t.Collector.FilterInvalidObjects(pruneObjs)
 
@@ -166,9 +318,28 @@ func (t *TaskQueueBuilder) Build(taskContext *taskrunner.TaskContext, o Options)
 		})
 	}
 
+	// Wait for the dependencies outside the inventory before applying the
+	// objects that depend on them. Dry-run skips wait tasks, and the
+	// dependencies are not checked.
+	if externalIds := g.AllExternalDependencies(); len(externalIds) > 0 &&
+		!o.DryRunStrategy.ClientOrServerDryRun() {
+		klog.V(2).Infof("adding external wait task (%d objects)", len(externalIds))
+		condition := o.ExternalDependencyCondition
+		if condition == "" {
+			condition = taskrunner.AllCurrent
+		}
+		tasks = append(tasks, &taskrunner.ExternalWaitTask{
+			TaskName:  "wait-external-0",
+			Ids:       externalIds,
+			Condition: condition,
+			Timeout:   o.ExternalDependencyTimeout,
+		})
+	}
+
 	if len(applyObjs) > 0 {
 		// Register actuation plan in the inventory
-		for _, id := range object.UnstructuredSetToObjMetadataSet(applyObjs) {
+		allApplyIds := object.UnstructuredSetToObjMetadataSet(applyObjs)
+		for _, id := range allApplyIds {
 			taskContext.InventoryManager().AddPendingApply(id)
 		}
 
@@ -176,37 +347,49 @@ func (t *TaskQueueBuilder) Build(taskContext *taskrunner.TaskContext, o Options)
 		applySets := graph.HydrateSetList(idSetList, applyObjs)
 
 		for _, applySet := range applySets {
-			tasks = append(tasks,
-				t.newApplyTask(applySet, t.ApplyFilters, t.ApplyMutators, o))
+			applyTask := t.newApplyTask(applySet, t.ApplyFilters, t.ApplyMutators, o)
+			tasks = append(tasks, applyTask)
+			if o.EmitTraceEvents {
+				traces = append(traces, applyTraces(g, applyTask, allApplyIds)...)
+			}
 			// dry-run skips wait tasks
 			if !o.DryRunStrategy.ClientOrServerDryRun() {
 				applyIds := object.UnstructuredSetToObjMetadataSet(applySet)
-				tasks = append(tasks,
-					t.newWaitTask(applyIds, taskrunner.AllCurrent, o.ReconcileTimeout))
+				waitTask := t.newWaitTask(applyIds, taskrunner.AllCurrent, o.ReconcileTimeout,
+					objectTimeouts(applySet))
+				waitTask.ReadyConditions = readyConditions(applySet)
+				waitTask.CRDVersions = crdVersions(applySet, applyObjs)
+				waitTask.SkipWait = skipWaitIds(applySet)
+				tasks = append(tasks, waitTask)
 			}
 		}
 	}
 
 	if o.Prune && len(pruneObjs) > 0 {
 		// Register actuation plan in the inventory
-		for _, id := range object.UnstructuredSetToObjMetadataSet(pruneObjs) {
+		allPruneIds := object.UnstructuredSetToObjMetadataSet(pruneObjs)
+		for _, id := range allPruneIds {
 			taskContext.InventoryManager().AddPendingDelete(id)
 		}
 
-		// Filter idSetList down to just prune objects
-		pruneSets := graph.HydrateSetList(idSetList, pruneObjs)
-
-		// Reverse apply order to get prune order
-		graph.ReverseSetList(pruneSets)
+		pruneSets, err := pruneSetList(g, idSetList, pruneObjs, o.PruneOrdering)
+		// A cycle between prune objects is also a cycle of the full graph,
+		// so only collect it if that one was not collected already.
+		if err != nil && sortErr == nil {
+			t.Collector.Collect(err)
+		}
 
 		for _, pruneSet := range pruneSets {
-			tasks = append(tasks,
-				t.newPruneTask(pruneSet, t.PruneFilters, o))
+			pruneTask := t.newPruneTask(pruneSet, t.PruneFilters, o)
+			tasks = append(tasks, pruneTask)
+			if o.EmitTraceEvents {
+				traces = append(traces, pruneTraces(g, pruneTask, allPruneIds)...)
+			}
 			// dry-run skips wait tasks
 			if !o.DryRunStrategy.ClientOrServerDryRun() {
 				pruneIds := object.UnstructuredSetToObjMetadataSet(pruneSet)
 				tasks = append(tasks,
-					t.newWaitTask(pruneIds, taskrunner.AllNotFound, o.PruneTimeout))
+					t.newWaitTask(pruneIds, taskrunner.AllNotFound, o.PruneTimeout, nil))
 			}
 		}
 	}
@@ -232,7 +415,84 @@ func (t *TaskQueueBuilder) Build(taskContext *taskrunner.TaskContext, o Options)
 		})
 	}
 
-	return &TaskQueue{tasks: tasks}
+	return &TaskQueue{tasks: tasks, traces: traces}
+}
+
+// pruneSetList returns the groups of prune objects in the order they are
+// deleted with the prune ordering.
+func pruneSetList(g *graph.Graph, idSetList []object.ObjMetadataSet, pruneObjs object.UnstructuredSet,
+	ordering prune.Ordering) ([]object.UnstructuredSet, error) {
+	var err error
+	switch ordering {
+	case prune.OrderingSimultaneous:
+		return []object.UnstructuredSet{pruneObjs}, nil
+	case prune.OrderingDependencies:
+		// Fall back to the apply order if the prune objects can not be
+		// sorted, returning the error.
+		var pruneIdSetList []object.ObjMetadataSet
+		pruneIdSetList, err = g.Subgraph(object.UnstructuredSetToObjMetadataSet(pruneObjs)).Sort()
+		if err == nil {
+			idSetList = pruneIdSetList
+		}
+	}
+	// Filter idSetList down to just prune objects
+	pruneSets := graph.HydrateSetList(idSetList, pruneObjs)
+
+	// Reverse apply order to get prune order
+	graph.ReverseSetList(pruneSets)
+	return pruneSets, err
+}
+
+// applyTraces returns a trace event for each object of the apply task,
+// listing the dependencies it is applied after. Dependencies that are not
+// in applyIds are ignored.
+func applyTraces(g *graph.Graph, applyTask taskrunner.Task, applyIds object.ObjMetadataSet) []event.TraceEvent {
+	var traces []event.TraceEvent
+	for _, id := range applyTask.Identifiers() {
+		traces = append(traces, event.TraceEvent{
+			GroupName:  applyTask.Name(),
+			Identifier: id,
+			After:      traceDependencies(g, g.Dependencies(id).Intersection(applyIds), id, false),
+		})
+	}
+	return traces
+}
+
+// pruneTraces returns a trace event for each object of the prune task,
+// listing the dependents it is pruned after. Dependents that are not in
+// pruneIds are ignored.
+func pruneTraces(g *graph.Graph, pruneTask taskrunner.Task, pruneIds object.ObjMetadataSet) []event.TraceEvent {
+	var traces []event.TraceEvent
+	for _, id := range pruneTask.Identifiers() {
+		traces = append(traces, event.TraceEvent{
+			GroupName:  pruneTask.Name(),
+			Identifier: id,
+			After:      traceDependencies(g, g.Dependents(id).Intersection(pruneIds), id, true),
+		})
+	}
+	return traces
+}
+
+// traceDependencies converts the adjacent vertices of id into trace
+// dependencies, with the reasons of their edges. If reverse is true, the
+// edges point from the adjacent vertices to id.
+func traceDependencies(g *graph.Graph, adjacent object.ObjMetadataSet, id object.ObjMetadata, reverse bool) []event.TraceDependency {
+	var deps []event.TraceDependency
+	for _, adj := range adjacent {
+		from, to := id, adj
+		if reverse {
+			from, to = adj, id
+		}
+		var reasons []string
+		for _, r := range g.EdgeReasons(from, to) {
+			reasons = append(reasons, string(r))
+		}
+		deps = append(deps, event.TraceDependency{
+			Identifier: adj,
+			Reasons:    reasons,
+		})
+	}
+	return deps
 }
 
 // AppendApplyTask appends a task to the task queue to apply the passed objects
@@ -241,17 +501,52 @@ func (t *TaskQueueBuilder) newApplyTask(applyObjs object.UnstructuredSet,
 	applyFilters []filter.ValidationFilter, applyMutators []mutator.Interface, o Options) taskrunner.Task {
 	applyObjs = t.Collector.FilterInvalidObjects(applyObjs)
 	klog.V(2).Infof("adding apply task (%d objects)", len(applyObjs))
+	var forceConflicts map[object.ObjMetadata]bool
+	for _, obj := range applyObjs {
+		// Invalid annotations were filtered out during Build.
+		force, found, _ := task.ReadConflictPolicy(obj)
+		if !found {
+			continue
+		}
+		if forceConflicts == nil {
+			forceConflicts = make(map[object.ObjMetadata]bool)
+		}
+		forceConflicts[object.UnstructuredToObjMetadata(obj)] = force
+	}
+	var clientSideApply map[object.ObjMetadata]bool
+	for _, obj := range applyObjs {
+		// Invalid annotations were filtered out during Build.
+		clientSide, found, _ := task.ReadApplyStrategy(obj)
+		if !found {
+			continue
+		}
+		if clientSideApply == nil {
+			clientSideApply = make(map[object.ObjMetadata]bool)
+		}
+		clientSideApply[object.UnstructuredToObjMetadata(obj)] = clientSide
+	}
 	task := &task.ApplyTask{
-		TaskName:          fmt.Sprintf("apply-%d", t.applyCounter),
-		Objects:           applyObjs,
-		Filters:           applyFilters,
-		Mutators:          applyMutators,
-		ServerSideOptions: o.ServerSideOptions,
-		DryRunStrategy:    o.DryRunStrategy,
-		DynamicClient:     t.DynamicClient,
-		OpenAPIGetter:     t.OpenAPIGetter,
-		InfoHelper:        t.InfoHelper,
-		Mapper:            t.Mapper,
+		TaskName:                fmt.Sprintf("apply-%d", t.applyCounter),
+		Objects:                 applyObjs,
+		Filters:                 applyFilters,
+		Mutators:                applyMutators,
+		PolicyGates:             t.PolicyGates,
+		ServerSideOptions:       o.ServerSideOptions,
+		ForceConflicts:          forceConflicts,
+		ClientSideApply:         clientSideApply,
+		DryRunStrategy:          o.DryRunStrategy,
+		DynamicClient:           t.DynamicClient,
+		OpenAPIGetter:           t.OpenAPIGetter,
+		InfoHelper:              t.InfoHelper,
+		Mapper:                  t.Mapper,
+		RetryPolicy:             o.RetryPolicy,
+		EmitDiffEvents:          o.EmitDiffEvents,
+		Concurrency:             o.Concurrency,
+		Throttle:                o.Throttle,
+		UpgradeClientSideApply:  o.UpgradeClientSideApply,
+		ClientSideApplyFallback: o.ClientSideApplyFallback,
+		Journal:                 o.Journal,
+		Metrics:                 t.Metrics,
 	}
 	t.applyCounter++
 	return task
@@ -260,7 +555,7 @@ func (t *TaskQueueBuilder) newApplyTask(applyObjs object.UnstructuredSet,
 // AppendWaitTask appends a task to wait on the passed objects to the task queue.
 // Returns a pointer to the Builder to chain function calls.
 func (t *TaskQueueBuilder) newWaitTask(waitIds object.ObjMetadataSet, condition taskrunner.Condition,
-	waitTimeout time.Duration) taskrunner.Task {
+	waitTimeout time.Duration, objectTimeouts map[object.ObjMetadata]time.Duration) *taskrunner.WaitTask {
 	waitIds = t.Collector.FilterInvalidIds(waitIds)
 	klog.V(2).Infoln("adding wait task")
 	task := taskrunner.NewWaitTask(
@@ -270,10 +565,91 @@ func (t *TaskQueueBuilder) newWaitTask(waitIds object.ObjMetadataSet, condition
 		waitTimeout,
 		t.Mapper,
 	)
+	task.ObjectTimeouts = objectTimeouts
 	t.waitCounter++
 	return task
 }
 
+// objectTimeouts returns the reconcile timeouts set by annotation on the
+// passed objects, or nil if none of the objects have the annotation.
+func objectTimeouts(objs object.UnstructuredSet) map[object.ObjMetadata]time.Duration {
+	var timeouts map[object.ObjMetadata]time.Duration
+	for _, obj := range objs {
+		// Invalid annotations were filtered out during Build.
+		timeout, _ := reconciletimeout.ReadAnnotation(obj)
+		if timeout == 0 {
+			continue
+		}
+		if timeouts == nil {
+			timeouts = make(map[object.ObjMetadata]time.Duration)
+		}
+		timeouts[object.UnstructuredToObjMetadata(obj)] = timeout
+	}
+	return timeouts
+}
+
+// readyConditions returns the ready-condition expressions set by annotation
+// on the passed objects, or nil if none of the objects have the annotation.
+func readyConditions(objs object.UnstructuredSet) map[object.ObjMetadata]*readycondition.Expression {
+	var conditions map[object.ObjMetadata]*readycondition.Expression
+	for _, obj := range objs {
+		// Invalid annotations were filtered out during Build.
+		expr, _ := readycondition.ReadAnnotation(obj)
+		if expr == nil {
+			continue
+		}
+		if conditions == nil {
+			conditions = make(map[object.ObjMetadata]*readycondition.Expression)
+		}
+		conditions[object.UnstructuredToObjMetadata(obj)] = expr
+	}
+	return conditions
+}
+
+// skipWaitIds returns the ids of the passed objects that are not waited for,
+// because of the reconcile annotation.
+func skipWaitIds(objs object.UnstructuredSet) object.ObjMetadataSet {
+	var ids object.ObjMetadataSet
+	for _, obj := range objs {
+		// Invalid annotations were filtered out during Build.
+		if skip, _ := skipwait.ReadAnnotation(obj); skip {
+			ids = append(ids, object.UnstructuredToObjMetadata(obj))
+		}
+	}
+	return ids
+}
+
+// crdVersions returns the API versions of the custom resources in applyObjs
+// defined by each of the CRDs in crds, or nil if none of the CRDs define
+// any of the objects.
+func crdVersions(crds, applyObjs object.UnstructuredSet) map[object.ObjMetadata][]string {
+	var versions map[object.ObjMetadata][]string
+	for _, crd := range crds {
+		if !object.IsCRD(crd) {
+			continue
+		}
+		gk, found := object.GetCRDGroupKind(crd)
+		if !found {
+			continue
+		}
+		crdVersions := sets.NewString()
+		for _, obj := range applyObjs {
+			gvk := obj.GroupVersionKind()
+			if gvk.GroupKind() == gk {
+				crdVersions.Insert(gvk.Version)
+			}
+		}
+		if crdVersions.Len() == 0 {
+			continue
+		}
+		if versions == nil {
+			versions = make(map[object.ObjMetadata][]string)
+		}
+		versions[object.UnstructuredToObjMetadata(crd)] = crdVersions.List()
+	}
+	return versions
+}
+
 // AppendPruneTask appends a task to delete objects from the cluster to the task queue.
 // Returns a pointer to the Builder to chain function calls.
 func (t *TaskQueueBuilder) newPruneTask(pruneObjs object.UnstructuredSet,
@@ -281,13 +657,17 @@ func (t *TaskQueueBuilder) newPruneTask(pruneObjs object.UnstructuredSet,
 	pruneObjs = t.Collector.FilterInvalidObjects(pruneObjs)
 	klog.V(2).Infof("adding prune task (%d objects)", len(pruneObjs))
 	task := &task.PruneTask{
-		TaskName:          fmt.Sprintf("prune-%d", t.pruneCounter),
-		Objects:           pruneObjs,
-		Filters:           pruneFilters,
-		Pruner:            t.Pruner,
-		PropagationPolicy: o.PrunePropagationPolicy,
-		DryRunStrategy:    o.DryRunStrategy,
-		Destroy:           o.Destroy,
+		TaskName:           fmt.Sprintf("prune-%d", t.pruneCounter),
+		Objects:            pruneObjs,
+		Filters:            pruneFilters,
+		Pruner:             t.Pruner,
+		PropagationPolicy:  o.PrunePropagationPolicy,
+		GracePeriodSeconds: o.PruneGracePeriodSeconds,
+		Concurrency:        o.PruneConcurrency,
+		DryRunStrategy:     o.DryRunStrategy,
+		Destroy:            o.Destroy,
+		RetryPolicy:        o.RetryPolicy,
+		Throttle:           o.Throttle,
 	}
 	t.pruneCounter++
 	return task
@@ -4,14 +4,17 @@
 package solver
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
 	"sigs.k8s.io/cli-utils/pkg/apply/task"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
@@ -19,6 +22,9 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/cli-utils/pkg/object/graph"
+	"sigs.k8s.io/cli-utils/pkg/object/readycondition"
+	"sigs.k8s.io/cli-utils/pkg/object/reconciletimeout"
+	"sigs.k8s.io/cli-utils/pkg/object/skipwait"
 	"sigs.k8s.io/cli-utils/pkg/object/validation"
 	"sigs.k8s.io/cli-utils/pkg/testutil"
 )
@@ -125,6 +131,7 @@ func TestTaskQueueBuilder_ApplyBuild(t *testing.T) {
 	// Use a custom Asserter to customize the comparison options
 	asserter := testutil.NewAsserter(
 		cmpopts.EquateErrors(),
+		cmpopts.IgnoreUnexported(task.ApplyTask{}, task.PruneTask{}),
 		waitTaskComparer(),
 		fakeClientComparer(),
 		inventoryInfoComparer(),
@@ -475,6 +482,9 @@ func TestTaskQueueBuilder_ApplyBuild(t *testing.T) {
 						testutil.ToIdentifier(t, resources["crd"]),
 					},
 					Condition: taskrunner.AllCurrent,
+					CRDVersions: map[object.ObjMetadata][]string{
+						testutil.ToIdentifier(t, resources["crd"]): {"v1"},
+					},
 				},
 				&task.ApplyTask{
 					TaskName: "apply-1",
@@ -766,6 +776,395 @@ func TestTaskQueueBuilder_ApplyBuild(t *testing.T) {
 				},
 			},
 		},
+		"conflict policy annotation sets per-object force conflicts": {
+			applyObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddAnnotation(task.ConflictPolicyAnnotation, task.ConflictPolicyForce)),
+				testutil.Unstructured(t, resources["secret"],
+					testutil.AddAnnotation(task.ConflictPolicyAnnotation, task.ConflictPolicyFail)),
+			},
+			options: Options{
+				ServerSideOptions: common.ServerSideOptions{ServerSideApply: true},
+			},
+			expectedTasks: []taskrunner.Task{
+				&task.InvAddTask{
+					TaskName:  "inventory-add-0",
+					InvClient: &inventory.FakeClient{},
+					InvInfo:   invInfo,
+					Objects: object.UnstructuredSet{
+						testutil.Unstructured(t, resources["deployment"],
+							testutil.AddAnnotation(task.ConflictPolicyAnnotation, task.ConflictPolicyForce)),
+						testutil.Unstructured(t, resources["secret"],
+							testutil.AddAnnotation(task.ConflictPolicyAnnotation, task.ConflictPolicyFail)),
+					},
+				},
+				&task.ApplyTask{
+					TaskName: "apply-0",
+					Objects: []*unstructured.Unstructured{
+						testutil.Unstructured(t, resources["deployment"],
+							testutil.AddAnnotation(task.ConflictPolicyAnnotation, task.ConflictPolicyForce)),
+						testutil.Unstructured(t, resources["secret"],
+							testutil.AddAnnotation(task.ConflictPolicyAnnotation, task.ConflictPolicyFail)),
+					},
+					ServerSideOptions: common.ServerSideOptions{ServerSideApply: true},
+					ForceConflicts: map[object.ObjMetadata]bool{
+						testutil.ToIdentifier(t, resources["deployment"]): true,
+						testutil.ToIdentifier(t, resources["secret"]):     false,
+					},
+				},
+				&taskrunner.WaitTask{
+					TaskName: "wait-0",
+					Ids: object.ObjMetadataSet{
+						testutil.ToIdentifier(t, resources["deployment"]),
+						testutil.ToIdentifier(t, resources["secret"]),
+					},
+					Condition: taskrunner.AllCurrent,
+				},
+				&task.InvSetTask{
+					TaskName:  "inventory-set-0",
+					InvClient: &inventory.FakeClient{},
+					InvInfo:   invInfo,
+					PrevInventory: object.ObjMetadataSet{
+						testutil.ToIdentifier(t, resources["deployment"]),
+						testutil.ToIdentifier(t, resources["secret"]),
+					},
+				},
+			},
+			expectedStatus: []actuation.ObjectStatus{
+				{
+					ObjectReference: inventory.ObjectReferenceFromObjMetadata(
+						testutil.ToIdentifier(t, resources["deployment"]),
+					),
+					Strategy:  actuation.ActuationStrategyApply,
+					Actuation: actuation.ActuationPending,
+					Reconcile: actuation.ReconcilePending,
+				},
+				{
+					ObjectReference: inventory.ObjectReferenceFromObjMetadata(
+						testutil.ToIdentifier(t, resources["secret"]),
+					),
+					Strategy:  actuation.ActuationStrategyApply,
+					Actuation: actuation.ActuationPending,
+					Reconcile: actuation.ReconcilePending,
+				},
+			},
+		},
+		"invalid conflict policy annotation returns error": {
+			applyObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddAnnotation(task.ConflictPolicyAnnotation, "sometimes")),
+			},
+			expectedTasks: []taskrunner.Task{},
+			expectedError: validation.NewError(
+				object.InvalidAnnotationError{
+					Annotation: task.ConflictPolicyAnnotation,
+					Cause:      errors.New(`must be "force" or "fail", got "sometimes"`),
+				},
+				testutil.ToIdentifier(t, resources["deployment"]),
+			),
+		},
+		"apply strategy annotation sets per-object client-side apply": {
+			applyObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddAnnotation(task.ApplyStrategyAnnotation, task.ApplyStrategyClientSide)),
+				testutil.Unstructured(t, resources["secret"],
+					testutil.AddAnnotation(task.ApplyStrategyAnnotation, task.ApplyStrategyServerSide)),
+			},
+			options: Options{
+				ServerSideOptions:       common.ServerSideOptions{ServerSideApply: true},
+				ClientSideApplyFallback: true,
+			},
+			expectedTasks: []taskrunner.Task{
+				&task.InvAddTask{
+					TaskName:  "inventory-add-0",
+					InvClient: &inventory.FakeClient{},
+					InvInfo:   invInfo,
+					Objects: object.UnstructuredSet{
+						testutil.Unstructured(t, resources["deployment"],
+							testutil.AddAnnotation(task.ApplyStrategyAnnotation, task.ApplyStrategyClientSide)),
+						testutil.Unstructured(t, resources["secret"],
+							testutil.AddAnnotation(task.ApplyStrategyAnnotation, task.ApplyStrategyServerSide)),
+					},
+				},
+				&task.ApplyTask{
+					TaskName: "apply-0",
+					Objects: []*unstructured.Unstructured{
+						testutil.Unstructured(t, resources["deployment"],
+							testutil.AddAnnotation(task.ApplyStrategyAnnotation, task.ApplyStrategyClientSide)),
+						testutil.Unstructured(t, resources["secret"],
+							testutil.AddAnnotation(task.ApplyStrategyAnnotation, task.ApplyStrategyServerSide)),
+					},
+					ServerSideOptions: common.ServerSideOptions{ServerSideApply: true},
+					ClientSideApply: map[object.ObjMetadata]bool{
+						testutil.ToIdentifier(t, resources["deployment"]): true,
+						testutil.ToIdentifier(t, resources["secret"]):     false,
+					},
+					ClientSideApplyFallback: true,
+				},
+				&taskrunner.WaitTask{
+					TaskName: "wait-0",
+					Ids: object.ObjMetadataSet{
+						testutil.ToIdentifier(t, resources["deployment"]),
+						testutil.ToIdentifier(t, resources["secret"]),
+					},
+					Condition: taskrunner.AllCurrent,
+				},
+				&task.InvSetTask{
+					TaskName:  "inventory-set-0",
+					InvClient: &inventory.FakeClient{},
+					InvInfo:   invInfo,
+					PrevInventory: object.ObjMetadataSet{
+						testutil.ToIdentifier(t, resources["deployment"]),
+						testutil.ToIdentifier(t, resources["secret"]),
+					},
+				},
+			},
+			expectedStatus: []actuation.ObjectStatus{
+				{
+					ObjectReference: inventory.ObjectReferenceFromObjMetadata(
+						testutil.ToIdentifier(t, resources["deployment"]),
+					),
+					Strategy:  actuation.ActuationStrategyApply,
+					Actuation: actuation.ActuationPending,
+					Reconcile: actuation.ReconcilePending,
+				},
+				{
+					ObjectReference: inventory.ObjectReferenceFromObjMetadata(
+						testutil.ToIdentifier(t, resources["secret"]),
+					),
+					Strategy:  actuation.ActuationStrategyApply,
+					Actuation: actuation.ActuationPending,
+					Reconcile: actuation.ReconcilePending,
+				},
+			},
+		},
+		"invalid apply strategy annotation returns error": {
+			applyObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddAnnotation(task.ApplyStrategyAnnotation, "kubectl")),
+			},
+			expectedTasks: []taskrunner.Task{},
+			expectedError: validation.NewError(
+				object.InvalidAnnotationError{
+					Annotation: task.ApplyStrategyAnnotation,
+					Cause:      errors.New(`must be "client-side" or "server-side", got "kubectl"`),
+				},
+				testutil.ToIdentifier(t, resources["deployment"]),
+			),
+		},
+		"invalid actuation strategy annotation returns error": {
+			applyObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddAnnotation(common.ActuationStrategyAnnotation, "create-only")),
+			},
+			expectedTasks: []taskrunner.Task{},
+			expectedError: validation.NewError(
+				object.InvalidAnnotationError{
+					Annotation: common.ActuationStrategyAnnotation,
+					Cause:      errors.New(`must be "patch-if-exists", got "create-only"`),
+				},
+				testutil.ToIdentifier(t, resources["deployment"]),
+			),
+		},
+		"reconcile timeout annotation sets per-object wait timeout": {
+			applyObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddAnnotation(reconciletimeout.Annotation, "5m")),
+			},
+			expectedTasks: []taskrunner.Task{
+				&task.InvAddTask{
+					TaskName:  "inventory-add-0",
+					InvClient: &inventory.FakeClient{},
+					InvInfo:   invInfo,
+					Objects: object.UnstructuredSet{
+						testutil.Unstructured(t, resources["deployment"],
+							testutil.AddAnnotation(reconciletimeout.Annotation, "5m")),
+					},
+				},
+				&task.ApplyTask{
+					TaskName: "apply-0",
+					Objects: []*unstructured.Unstructured{
+						testutil.Unstructured(t, resources["deployment"],
+							testutil.AddAnnotation(reconciletimeout.Annotation, "5m")),
+					},
+				},
+				&taskrunner.WaitTask{
+					TaskName: "wait-0",
+					Ids: object.ObjMetadataSet{
+						testutil.ToIdentifier(t, resources["deployment"]),
+					},
+					Condition: taskrunner.AllCurrent,
+					ObjectTimeouts: map[object.ObjMetadata]time.Duration{
+						testutil.ToIdentifier(t, resources["deployment"]): 5 * time.Minute,
+					},
+				},
+				&task.InvSetTask{
+					TaskName:  "inventory-set-0",
+					InvClient: &inventory.FakeClient{},
+					InvInfo:   invInfo,
+					PrevInventory: object.ObjMetadataSet{
+						testutil.ToIdentifier(t, resources["deployment"]),
+					},
+				},
+			},
+			expectedStatus: []actuation.ObjectStatus{
+				{
+					ObjectReference: inventory.ObjectReferenceFromObjMetadata(
+						testutil.ToIdentifier(t, resources["deployment"]),
+					),
+					Strategy:  actuation.ActuationStrategyApply,
+					Actuation: actuation.ActuationPending,
+					Reconcile: actuation.ReconcilePending,
+				},
+			},
+		},
+		"invalid reconcile timeout annotation returns error": {
+			applyObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddAnnotation(reconciletimeout.Annotation, "-5m")),
+			},
+			expectedTasks: []taskrunner.Task{},
+			expectedError: validation.NewError(
+				object.InvalidAnnotationError{
+					Annotation: reconciletimeout.Annotation,
+					Cause:      errors.New(`must be positive, got "-5m"`),
+				},
+				testutil.ToIdentifier(t, resources["deployment"]),
+			),
+		},
+		"ready condition annotation sets per-object wait condition": {
+			applyObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddAnnotation(readycondition.Annotation, "status.readyReplicas >= spec.replicas")),
+			},
+			expectedTasks: []taskrunner.Task{
+				&task.InvAddTask{
+					TaskName:  "inventory-add-0",
+					InvClient: &inventory.FakeClient{},
+					InvInfo:   invInfo,
+					Objects: object.UnstructuredSet{
+						testutil.Unstructured(t, resources["deployment"],
+							testutil.AddAnnotation(readycondition.Annotation, "status.readyReplicas >= spec.replicas")),
+					},
+				},
+				&task.ApplyTask{
+					TaskName: "apply-0",
+					Objects: []*unstructured.Unstructured{
+						testutil.Unstructured(t, resources["deployment"],
+							testutil.AddAnnotation(readycondition.Annotation, "status.readyReplicas >= spec.replicas")),
+					},
+				},
+				&taskrunner.WaitTask{
+					TaskName: "wait-0",
+					Ids: object.ObjMetadataSet{
+						testutil.ToIdentifier(t, resources["deployment"]),
+					},
+					Condition: taskrunner.AllCurrent,
+					ReadyConditions: map[object.ObjMetadata]*readycondition.Expression{
+						testutil.ToIdentifier(t, resources["deployment"]): mustParseReadyCondition(t,
+							"status.readyReplicas >= spec.replicas"),
+					},
+				},
+				&task.InvSetTask{
+					TaskName:  "inventory-set-0",
+					InvClient: &inventory.FakeClient{},
+					InvInfo:   invInfo,
+					PrevInventory: object.ObjMetadataSet{
+						testutil.ToIdentifier(t, resources["deployment"]),
+					},
+				},
+			},
+			expectedStatus: []actuation.ObjectStatus{
+				{
+					ObjectReference: inventory.ObjectReferenceFromObjMetadata(
+						testutil.ToIdentifier(t, resources["deployment"]),
+					),
+					Strategy:  actuation.ActuationStrategyApply,
+					Actuation: actuation.ActuationPending,
+					Reconcile: actuation.ReconcilePending,
+				},
+			},
+		},
+		"invalid ready condition annotation returns error": {
+			applyObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddAnnotation(readycondition.Annotation, ">= 3")),
+			},
+			expectedTasks: []taskrunner.Task{},
+			expectedError: validation.NewError(
+				object.InvalidAnnotationError{
+					Annotation: readycondition.Annotation,
+					Cause:      errors.New("operand is empty"),
+				},
+				testutil.ToIdentifier(t, resources["deployment"]),
+			),
+		},
+		"reconcile annotation skips waiting for the object": {
+			applyObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddAnnotation(skipwait.Annotation, skipwait.Skip)),
+			},
+			expectedTasks: []taskrunner.Task{
+				&task.InvAddTask{
+					TaskName:  "inventory-add-0",
+					InvClient: &inventory.FakeClient{},
+					InvInfo:   invInfo,
+					Objects: object.UnstructuredSet{
+						testutil.Unstructured(t, resources["deployment"],
+							testutil.AddAnnotation(skipwait.Annotation, skipwait.Skip)),
+					},
+				},
+				&task.ApplyTask{
+					TaskName: "apply-0",
+					Objects: []*unstructured.Unstructured{
+						testutil.Unstructured(t, resources["deployment"],
+							testutil.AddAnnotation(skipwait.Annotation, skipwait.Skip)),
+					},
+				},
+				&taskrunner.WaitTask{
+					TaskName: "wait-0",
+					Ids: object.ObjMetadataSet{
+						testutil.ToIdentifier(t, resources["deployment"]),
+					},
+					Condition: taskrunner.AllCurrent,
+					SkipWait: object.ObjMetadataSet{
+						testutil.ToIdentifier(t, resources["deployment"]),
+					},
+				},
+				&task.InvSetTask{
+					TaskName:  "inventory-set-0",
+					InvClient: &inventory.FakeClient{},
+					InvInfo:   invInfo,
+					PrevInventory: object.ObjMetadataSet{
+						testutil.ToIdentifier(t, resources["deployment"]),
+					},
+				},
+			},
+			expectedStatus: []actuation.ObjectStatus{
+				{
+					ObjectReference: inventory.ObjectReferenceFromObjMetadata(
+						testutil.ToIdentifier(t, resources["deployment"]),
+					),
+					Strategy:  actuation.ActuationStrategyApply,
+					Actuation: actuation.ActuationPending,
+					Reconcile: actuation.ReconcilePending,
+				},
+			},
+		},
+		"invalid reconcile annotation returns error": {
+			applyObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddAnnotation(skipwait.Annotation, "later")),
+			},
+			expectedTasks: []taskrunner.Task{},
+			expectedError: validation.NewError(
+				object.InvalidAnnotationError{
+					Annotation: skipwait.Annotation,
+					Cause:      errors.New(`must be "skip" or "disabled", got "later"`),
+				},
+				testutil.ToIdentifier(t, resources["deployment"]),
+			),
+		},
 		"cyclic dependency returns error": {
 			applyObjs: []*unstructured.Unstructured{
 				testutil.Unstructured(t, resources["deployment"],
@@ -837,6 +1236,7 @@ func TestTaskQueueBuilder_PruneBuild(t *testing.T) {
 	// Use a custom Asserter to customize the comparison options
 	asserter := testutil.NewAsserter(
 		cmpopts.EquateErrors(),
+		cmpopts.IgnoreUnexported(task.ApplyTask{}, task.PruneTask{}),
 		waitTaskComparer(),
 		fakeClientComparer(),
 		inventoryInfoComparer(),
@@ -1511,6 +1911,7 @@ func TestTaskQueueBuilder_ApplyPruneBuild(t *testing.T) {
 	// Use a custom Asserter to customize the comparison options
 	asserter := testutil.NewAsserter(
 		cmpopts.EquateErrors(),
+		cmpopts.IgnoreUnexported(task.ApplyTask{}, task.PruneTask{}),
 		waitTaskComparer(),
 		fakeClientComparer(),
 		inventoryInfoComparer(),
@@ -1863,6 +2264,168 @@ func TestTaskQueueBuilder_ApplyPruneBuild(t *testing.T) {
 	}
 }
 
+func TestTaskQueueBuilder_TraceEvents(t *testing.T) {
+	invInfo := inventory.WrapInventoryInfoObj(newInvObject(
+		"abc-123", "default", "test"))
+	namespaceID := testutil.ToIdentifier(t, resources["namespace"])
+	secretID := testutil.ToIdentifier(t, resources["secret"])
+	deploymentID := testutil.ToIdentifier(t, resources["deployment"])
+	crdID := testutil.ToIdentifier(t, resources["crd"])
+	crontabID := testutil.ToIdentifier(t, resources["crontab1"])
+
+	tqb := TaskQueueBuilder{
+		Pruner:    pruner,
+		Mapper:    testutil.NewFakeRESTMapper(),
+		InvClient: inventory.NewFakeClient(object.ObjMetadataSet{crdID, crontabID}),
+		Collector: &validation.Collector{},
+	}
+	tq := tqb.WithInventory(invInfo).
+		WithApplyObjects(object.UnstructuredSet{
+			testutil.Unstructured(t, resources["deployment"], testutil.AddDependsOn(t, secretID)),
+			testutil.Unstructured(t, resources["secret"]),
+			testutil.Unstructured(t, resources["namespace"]),
+		}).
+		WithPruneObjects(object.UnstructuredSet{
+			testutil.Unstructured(t, resources["crd"]),
+			testutil.Unstructured(t, resources["crontab1"]),
+		}).
+		Build(taskrunner.NewTaskContext(nil, nil), Options{
+			Prune:           true,
+			DryRunStrategy:  common.DryRunClient,
+			EmitTraceEvents: true,
+		})
+	assert.NoError(t, tqb.Collector.ToError())
+
+	testutil.AssertEqual(t, []event.TraceEvent{
+		{
+			GroupName:  "apply-0",
+			Identifier: namespaceID,
+		},
+		{
+			GroupName:  "apply-1",
+			Identifier: secretID,
+			After: []event.TraceDependency{
+				{Identifier: namespaceID, Reasons: []string{"namespace"}},
+			},
+		},
+		{
+			GroupName:  "apply-2",
+			Identifier: deploymentID,
+			After: []event.TraceDependency{
+				{Identifier: namespaceID, Reasons: []string{"namespace"}},
+				{Identifier: secretID, Reasons: []string{"depends-on"}},
+			},
+		},
+		{
+			GroupName:  "prune-0",
+			Identifier: crontabID,
+		},
+		{
+			GroupName:  "prune-1",
+			Identifier: crdID,
+			After: []event.TraceDependency{
+				{Identifier: crontabID, Reasons: []string{"crd"}},
+			},
+		},
+	}, tq.TraceEvents())
+}
+
+func TestTaskQueue_SkipCompleted(t *testing.T) {
+	invInfo := inventory.WrapInventoryInfoObj(newInvObject(
+		"abc-123", "default", "test"))
+	newQueue := func() *TaskQueue {
+		tqb := TaskQueueBuilder{
+			Pruner:    pruner,
+			Mapper:    testutil.NewFakeRESTMapper(),
+			InvClient: inventory.NewFakeClient(object.ObjMetadataSet{}),
+			Collector: &validation.Collector{},
+		}
+		return tqb.WithInventory(invInfo).
+			WithApplyObjects(object.UnstructuredSet{
+				testutil.Unstructured(t, resources["deployment"]),
+			}).
+			Build(taskrunner.NewTaskContext(nil, nil), Options{})
+	}
+
+	tq := newQueue()
+	require.NoError(t, tq.SkipCompleted([]string{"inventory-add-0", "apply-0"}))
+	var names []string
+	for _, ag := range tq.ToActionGroups() {
+		names = append(names, ag.Name)
+	}
+	assert.Equal(t, []string{"wait-0", "inventory-set-0"}, names)
+
+	assert.EqualError(t, newQueue().SkipCompleted([]string{"apply-0"}),
+		`completed task "apply-0" does not match task "inventory-add-0" of the queue`)
+	assert.EqualError(t, newQueue().SkipCompleted(make([]string, 5)),
+		"5 tasks completed, but the queue has only 4 tasks")
+}
+
+func TestTaskQueueBuilder_ExternalDependencies(t *testing.T) {
+	invInfo := inventory.WrapInventoryInfoObj(newInvObject(
+		"abc-123", "default", "test"))
+	externalID := testutil.ToIdentifier(t, resources["secret"])
+	newQueue := func(collector *validation.Collector, o Options) *TaskQueue {
+		tqb := TaskQueueBuilder{
+			Pruner:    pruner,
+			Mapper:    testutil.NewFakeRESTMapper(),
+			InvClient: inventory.NewFakeClient(object.ObjMetadataSet{}),
+			Collector: collector,
+		}
+		return tqb.WithInventory(invInfo).
+			WithApplyObjects(object.UnstructuredSet{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddDependsOn(t, externalID)),
+			}).
+			Build(taskrunner.NewTaskContext(nil, nil), o)
+	}
+	taskNames := func(tq *TaskQueue) []string {
+		var names []string
+		for _, ag := range tq.ToActionGroups() {
+			names = append(names, ag.Name)
+		}
+		return names
+	}
+
+	// By default, external dependencies are invalid.
+	collector := &validation.Collector{}
+	tq := newQueue(collector, Options{})
+	assert.Error(t, collector.ToError())
+	assert.Equal(t, []string{"inventory-add-0", "inventory-set-0"}, taskNames(tq))
+
+	collector = &validation.Collector{}
+	tq = newQueue(collector, Options{
+		WaitForExternalDependencies: true,
+		ExternalDependencyTimeout:   time.Minute,
+	})
+	require.NoError(t, collector.ToError())
+	assert.Equal(t, []string{"inventory-add-0", "wait-external-0", "apply-0", "wait-0", "inventory-set-0"},
+		taskNames(tq))
+	assert.Equal(t, &taskrunner.ExternalWaitTask{
+		TaskName:  "wait-external-0",
+		Ids:       object.ObjMetadataSet{externalID},
+		Condition: taskrunner.AllCurrent,
+		Timeout:   time.Minute,
+	}, tq.tasks[1])
+
+	// External wait tasks are not skipped when resuming.
+	require.NoError(t, tq.SkipCompleted([]string{"inventory-add-0", "apply-0"}))
+	assert.Equal(t, []string{"wait-external-0", "wait-0", "inventory-set-0"}, taskNames(tq))
+
+	// Dry-run skips wait tasks.
+	tq = newQueue(&validation.Collector{}, Options{
+		WaitForExternalDependencies: true,
+		DryRunStrategy:              common.DryRunClient,
+	})
+	assert.Equal(t, []string{"inventory-add-0", "apply-0", "inventory-set-0"}, taskNames(tq))
+}
+
+func mustParseReadyCondition(t *testing.T, expr string) *readycondition.Expression {
+	parsed, err := readycondition.Parse(expr)
+	require.NoError(t, err)
+	return parsed
+}
+
 // waitTaskComparer allows comparion of WaitTasks, ignoring private fields.
 func waitTaskComparer() cmp.Option {
 	return cmp.Comparer(func(x, y *taskrunner.WaitTask) bool {
@@ -1876,10 +2439,27 @@ func waitTaskComparer() cmp.Option {
 			x.Ids.Hash() == y.Ids.Hash() && // exact order match
 			x.Condition == y.Condition &&
 			x.Timeout == y.Timeout &&
+			cmp.Equal(x.ObjectTimeouts, y.ObjectTimeouts) &&
+			cmp.Equal(readyConditionStrings(x.ReadyConditions), readyConditionStrings(y.ReadyConditions)) &&
+			cmp.Equal(x.CRDVersions, y.CRDVersions) &&
+			x.SkipWait.Hash() == y.SkipWait.Hash() &&
 			cmp.Equal(x.Mapper, y.Mapper)
 	})
 }
 
+// readyConditionStrings returns the ready conditions as strings, to compare
+// them without the parsed expressions.
+func readyConditionStrings(conditions map[object.ObjMetadata]*readycondition.Expression) map[object.ObjMetadata]string {
+	if conditions == nil {
+		return nil
+	}
+	strs := make(map[object.ObjMetadata]string, len(conditions))
+	for id, expr := range conditions {
+		strs[id] = expr.String()
+	}
+	return strs
+}
+
 // fakeClientComparer allows comparion of inventory.FakeClient, ignoring objs.
 func fakeClientComparer() cmp.Option {
 	return cmp.Comparer(func(x, y *inventory.FakeClient) bool {
@@ -1902,3 +2482,102 @@ func inventoryInfoComparer() cmp.Option {
 			x.Strategy() == y.Strategy()
 	})
 }
+
+func TestTaskQueueBuilder_PruneOrdering(t *testing.T) {
+	invInfo := inventory.WrapInventoryInfoObj(newInvObject(
+		"abc-123", "default", "test"))
+	secretID := testutil.ToIdentifier(t, resources["secret"])
+	deploymentID := testutil.ToIdentifier(t, resources["deployment"])
+	podID := testutil.ToIdentifier(t, resources["pod"])
+	crdID := testutil.ToIdentifier(t, resources["crd"])
+	crontabID := testutil.ToIdentifier(t, resources["crontab1"])
+
+	testCases := map[string]struct {
+		ordering      prune.Ordering
+		expectedTasks []object.ObjMetadataSet
+	}{
+		"reverse apply": {
+			ordering: prune.OrderingReverseApply,
+			// The deployment is pruned after the pod, since it is applied
+			// after the secret.
+			expectedTasks: []object.ObjMetadataSet{
+				{crontabID, deploymentID},
+				{crdID, podID},
+			},
+		},
+		"dependencies": {
+			ordering: prune.OrderingDependencies,
+			// Only the custom resource must be pruned before its CRD.
+			expectedTasks: []object.ObjMetadataSet{
+				{crontabID},
+				{deploymentID, podID, crdID},
+			},
+		},
+		"simultaneous": {
+			ordering: prune.OrderingSimultaneous,
+			expectedTasks: []object.ObjMetadataSet{
+				{deploymentID, podID, crdID, crontabID},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			tqb := TaskQueueBuilder{
+				Pruner:    pruner,
+				Mapper:    testutil.NewFakeRESTMapper(),
+				InvClient: inventory.NewFakeClient(object.ObjMetadataSet{deploymentID, podID, crdID, crontabID}),
+				Collector: &validation.Collector{},
+			}
+			tq := tqb.WithInventory(invInfo).
+				WithApplyObjects(object.UnstructuredSet{
+					testutil.Unstructured(t, resources["secret"]),
+				}).
+				WithPruneObjects(object.UnstructuredSet{
+					testutil.Unstructured(t, resources["deployment"], testutil.AddDependsOn(t, secretID)),
+					testutil.Unstructured(t, resources["pod"]),
+					testutil.Unstructured(t, resources["crd"]),
+					testutil.Unstructured(t, resources["crontab1"]),
+				}).
+				Build(taskrunner.NewTaskContext(nil, nil), Options{
+					Prune:            true,
+					DryRunStrategy:   common.DryRunClient,
+					PruneOrdering:    tc.ordering,
+					PruneConcurrency: 4,
+				})
+			require.NoError(t, tqb.Collector.ToError())
+
+			var pruneTasks []object.ObjMetadataSet
+			for _, tsk := range tq.tasks {
+				if pruneTask, ok := tsk.(*task.PruneTask); ok {
+					assert.Equal(t, 4, pruneTask.Concurrency)
+					pruneTasks = append(pruneTasks, pruneTask.Identifiers())
+				}
+			}
+			require.Len(t, pruneTasks, len(tc.expectedTasks))
+			for i := range tc.expectedTasks {
+				assert.ElementsMatch(t, tc.expectedTasks[i], pruneTasks[i])
+			}
+		})
+	}
+}
+
+func TestPruneSetList_Cycle(t *testing.T) {
+	deployment := testutil.Unstructured(t, resources["deployment"])
+	pod := testutil.Unstructured(t, resources["pod"])
+	deploymentID := object.UnstructuredToObjMetadata(deployment)
+	podID := object.UnstructuredToObjMetadata(pod)
+
+	g := graph.New()
+	g.AddEdge(deploymentID, podID)
+	g.AddEdge(podID, deploymentID)
+	idSetList := []object.ObjMetadataSet{{deploymentID, podID}}
+
+	pruneSets, err := pruneSetList(g, idSetList, object.UnstructuredSet{deployment, pod},
+		prune.OrderingDependencies)
+	var cycleErr graph.CyclicDependencyError
+	assert.ErrorAs(t, err, &cycleErr)
+	// The apply order is used instead
+	require.Len(t, pruneSets, 1)
+	assert.ElementsMatch(t, object.UnstructuredSet{deployment, pod}, pruneSets[0])
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	// ApplyStrategyAnnotation is the annotation key used to override how a
+	// single object is applied, e.g. for custom resources whose CRD has a
+	// schema that rejects server-side apply.
+	ApplyStrategyAnnotation = "config.kubernetes.io/apply-strategy"
+
+	// ApplyStrategyClientSide applies the object with a client-side
+	// three-way merge, using the last-applied-configuration annotation,
+	// even if server-side apply is enabled.
+	ApplyStrategyClientSide = "client-side"
+
+	// ApplyStrategyServerSide applies the object with server-side apply,
+	// without falling back to client-side apply if it fails.
+	ApplyStrategyServerSide = "server-side"
+)
+
+// ReadApplyStrategy reads the apply-strategy annotation and returns
+// whether the object should be applied client-side. The second return
+// value is false if the annotation is not present, in which case the
+// global ServerSideOptions apply.
+func ReadApplyStrategy(u *unstructured.Unstructured) (bool, bool, error) {
+	if u == nil {
+		return false, false, nil
+	}
+	strategy, found := u.GetAnnotations()[ApplyStrategyAnnotation]
+	if !found {
+		return false, false, nil
+	}
+	switch strategy {
+	case ApplyStrategyClientSide:
+		return true, true, nil
+	case ApplyStrategyServerSide:
+		return false, true, nil
+	default:
+		return false, false, object.InvalidAnnotationError{
+			Annotation: ApplyStrategyAnnotation,
+			Cause: fmt.Errorf("must be %q or %q, got %q",
+				ApplyStrategyClientSide, ApplyStrategyServerSide, strategy),
+		}
+	}
+}
@@ -9,9 +9,14 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
+	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/resource"
@@ -25,9 +30,15 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/apply/filter"
 	"sigs.k8s.io/cli-utils/pkg/apply/info"
 	"sigs.k8s.io/cli-utils/pkg/apply/mutator"
+	"sigs.k8s.io/cli-utils/pkg/apply/policy"
+	"sigs.k8s.io/cli-utils/pkg/apply/rollback"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/diff"
+	"sigs.k8s.io/cli-utils/pkg/metrics"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/reason"
+	"sigs.k8s.io/cli-utils/pkg/ssa"
 )
 
 // applyOptions defines the two key functions on the ApplyOptions
@@ -48,15 +59,59 @@ type applyOptions interface {
 type ApplyTask struct {
 	TaskName string
 
-	DynamicClient     dynamic.Interface
-	OpenAPIGetter     discovery.OpenAPISchemaInterface
-	InfoHelper        info.Helper
-	Mapper            meta.RESTMapper
-	Objects           object.UnstructuredSet
-	Filters           []filter.ValidationFilter
-	Mutators          []mutator.Interface
+	DynamicClient dynamic.Interface
+	OpenAPIGetter discovery.OpenAPISchemaInterface
+	InfoHelper    info.Helper
+	Mapper        meta.RESTMapper
+	Objects       object.UnstructuredSet
+	Filters       []filter.ValidationFilter
+	Mutators      []mutator.Interface
+	// PolicyGates decide whether each object is applied. They are
+	// evaluated in order, after the filters and mutators.
+	PolicyGates       []policy.Gate
 	DryRunStrategy    common.DryRunStrategy
 	ServerSideOptions common.ServerSideOptions
+	// ForceConflicts overrides ServerSideOptions.ForceConflicts for
+	// individual objects, as set by the ssa-conflict-policy annotation.
+	ForceConflicts map[object.ObjMetadata]bool
+	// ClientSideApply overrides ServerSideOptions.ServerSideApply for
+	// individual objects, as set by the apply-strategy annotation. Objects
+	// that are applied server-side this way never fall back to client-side
+	// apply.
+	ClientSideApply map[object.ObjMetadata]bool
+	// ClientSideApplyFallback enables applying objects with client-side
+	// apply, if the API server can't compute a server-side apply patch,
+	// e.g. for custom resources whose CRD schema is not structural.
+	// Ignored for client-side apply.
+	ClientSideApplyFallback bool
+	// RetryPolicy defines how to retry applies that failed with a
+	// transient error. If nil, each object is applied once.
+	RetryPolicy *RetryPolicy
+	// EmitDiffEvents enables sending a DiffEvent for each object before
+	// it is applied.
+	EmitDiffEvents bool
+	// Concurrency is the maximum number of objects applied at the same
+	// time. Values below 2 apply the objects one at a time.
+	Concurrency int
+	// Throttle limits the rate of apply requests, including retries. If
+	// nil, applies are not throttled.
+	Throttle *Throttle
+	// UpgradeClientSideApply enables transferring the ownership of fields
+	// managed with client-side apply to the server-side apply field
+	// manager, before each object is applied. Ignored for client-side
+	// apply.
+	UpgradeClientSideApply bool
+	// Journal records the state of each object before it is applied, so
+	// the objects can be rolled back if the apply fails. If nil, nothing
+	// is recorded. Ignored for dry-runs.
+	Journal *rollback.Journal
+	// Metrics records the duration and errors of each apply. Optional.
+	Metrics *metrics.Metrics
+
+	// retries is done when the task is cancelled, to stop waiting between
+	// the attempts of the RetryPolicy.
+	retries       context.Context
+	cancelRetries context.CancelFunc
 }
 
 // applyOptionsFactoryFunc is a factory function for creating a new
@@ -84,107 +139,302 @@ func (a *ApplyTask) Identifiers() object.ObjMetadataSet {
 // to the taskContext. The generation is increased every time
 // the desired state of a resource is changed.
 func (a *ApplyTask) Start(taskContext *taskrunner.TaskContext) {
+	a.retries, a.cancelRetries = context.WithCancel(context.Background())
 	go func() {
-		// TODO: pipe Context through TaskContext
-		ctx := context.TODO()
+		defer a.cancelRetries()
+		// The context carries the span of the task.
+		ctx := taskContext.Context()
 		objects := a.Objects
 		klog.V(2).Infof("apply task starting (name: %q, objects: %d)",
 			a.Name(), len(objects))
-		for _, obj := range objects {
-			// Set the client and mapping fields on the provided
-			// info so they can be applied to the cluster.
-			info, err := a.InfoHelper.BuildInfo(obj)
-			// BuildInfo strips path annotations.
-			// Use modified object for filters, mutations, and events.
-			obj = info.Object.(*unstructured.Unstructured)
-			id := object.UnstructuredToObjMetadata(obj)
-			if err != nil {
-				err = applyerror.NewUnknownTypeError(err)
-				if klog.V(4).Enabled() {
-					// only log event emitted errors if the verbosity > 4
-					klog.Errorf("apply task errored (object: %s): unable to convert obj to info: %v", id, err)
-				}
-				taskContext.SendEvent(a.createApplyFailedEvent(id, err))
-				taskContext.InventoryManager().AddFailedApply(id)
-				continue
+		if a.Concurrency <= 1 {
+			for _, obj := range objects {
+				a.recordResult(taskContext, a.applyObject(ctx, taskContext, obj))
 			}
-
-			// Check filters to see if we're prevented from applying.
-			var filterErr error
-			for _, applyFilter := range a.Filters {
-				klog.V(6).Infof("apply filter evaluating (filter: %s, object: %s)", applyFilter.Name(), id)
-				filterErr = applyFilter.Filter(obj)
-				if filterErr != nil {
-					var fatalErr *filter.FatalError
-					if errors.As(filterErr, &fatalErr) {
-						if klog.V(4).Enabled() {
-							// only log event emitted errors if the verbosity > 4
-							klog.Errorf("apply filter errored (filter: %s, object: %s): %v", applyFilter.Name(), id, fatalErr.Err)
-						}
-						taskContext.SendEvent(a.createApplyFailedEvent(id, err))
-						taskContext.InventoryManager().AddFailedApply(id)
-						break
+		} else {
+			// The events of each object are sent by a single worker, so
+			// they stay in order. The results are recorded in the inventory
+			// once all workers are done, because the inventory manager is not
+			// safe for concurrent use.
+			results := make([]applyResult, len(objects))
+			indices := make(chan int)
+			var wg sync.WaitGroup
+			for w := 0; w < a.Concurrency && w < len(objects); w++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range indices {
+						results[i] = a.applyObject(ctx, taskContext, objects[i])
 					}
-					klog.V(4).Infof("apply filtered (filter: %s, object: %s): %v", applyFilter.Name(), id, filterErr)
-					taskContext.SendEvent(a.createApplySkippedEvent(id, obj, filterErr))
-					taskContext.InventoryManager().AddSkippedApply(id)
-					break
-				}
+				}()
 			}
-			if filterErr != nil {
-				continue
+			for i := range objects {
+				indices <- i
 			}
+			close(indices)
+			wg.Wait()
+			for _, result := range results {
+				a.recordResult(taskContext, result)
+			}
+		}
+		a.sendTaskResult(taskContext)
+	}()
+}
 
-			// Execute mutators, if any apply
-			err = a.mutate(ctx, obj)
-			if err != nil {
+// applyResult is the outcome of applying a single object.
+type applyResult struct {
+	id      object.ObjMetadata
+	failed  bool
+	skipped bool
+	// applied is the object returned by the apply, if successful.
+	applied runtime.Object
+}
+
+// applyObject filters, mutates and applies a single object, sending the
+// events of the object on the event channel.
+func (a *ApplyTask) applyObject(ctx context.Context, taskContext *taskrunner.TaskContext, obj *unstructured.Unstructured) applyResult {
+	// Capture the source location before BuildInfo strips it, so failures
+	// can be reported with the file and line of the object.
+	source := object.Source(obj)
+	// Set the client and mapping fields on the provided
+	// info so they can be applied to the cluster.
+	info, err := a.InfoHelper.BuildInfo(obj)
+	// BuildInfo strips path annotations.
+	// Use modified object for filters, mutations, and events.
+	obj = info.Object.(*unstructured.Unstructured)
+	id := object.UnstructuredToObjMetadata(obj)
+	if err != nil {
+		err = applyerror.NewUnknownTypeError(err)
+		if klog.V(4).Enabled() {
+			// only log event emitted errors if the verbosity > 4
+			klog.Errorf("apply task errored (object: %s): unable to convert obj to info: %v", id, err)
+		}
+		taskContext.SendEvent(a.createApplyFailedEvent(id, object.WrapSourceError(err, source)))
+		return applyResult{id: id, failed: true}
+	}
+
+	// Check filters to see if we're prevented from applying.
+	for _, applyFilter := range a.Filters {
+		klog.V(6).Infof("apply filter evaluating (filter: %s, object: %s)", applyFilter.Name(), id)
+		filterErr := applyFilter.Filter(obj)
+		if filterErr != nil {
+			var fatalErr *filter.FatalError
+			if errors.As(filterErr, &fatalErr) {
 				if klog.V(4).Enabled() {
 					// only log event emitted errors if the verbosity > 4
-					klog.Errorf("apply mutation errored (object: %s): %v", id, err)
+					klog.Errorf("apply filter errored (filter: %s, object: %s): %v", applyFilter.Name(), id, fatalErr.Err)
 				}
-				taskContext.SendEvent(a.createApplyFailedEvent(id, err))
-				taskContext.InventoryManager().AddFailedApply(id)
-				continue
+				taskContext.SendEvent(a.createApplyFailedEvent(id, object.WrapSourceError(err, source)))
+				return applyResult{id: id, failed: true}
 			}
+			klog.V(4).Infof("apply filtered (filter: %s, object: %s): %v", applyFilter.Name(), id, filterErr)
+			taskContext.SendEvent(a.createApplySkippedEvent(id, obj, filterErr))
+			return applyResult{id: id, skipped: true}
+		}
+	}
+
+	// Execute mutators, if any apply
+	err = a.mutate(ctx, obj)
+	if err != nil {
+		if klog.V(4).Enabled() {
+			// only log event emitted errors if the verbosity > 4
+			klog.Errorf("apply mutation errored (object: %s): %v", id, err)
+		}
+		taskContext.SendEvent(a.createApplyFailedEvent(id, object.WrapSourceError(err, source)))
+		return applyResult{id: id, failed: true}
+	}
 
-			// Create a new instance of the applyOptions interface and use it
-			// to apply the objects.
-			ao := applyOptionsFactoryFunc(a.Name(), taskContext.EventChannel(),
-				a.ServerSideOptions, a.DryRunStrategy, a.DynamicClient, a.OpenAPIGetter)
-			ao.SetObjects([]*resource.Info{info})
-			klog.V(5).Infof("applying object: %v", id)
-			err = ao.Run()
-			if err != nil && a.ServerSideOptions.ServerSideApply && isAPIService(obj) && isStreamError(err) {
-				// Server-side Apply doesn't work with APIService before k8s 1.21
-				// https://github.com/kubernetes/kubernetes/issues/89264
-				// Thus APIService is handled specially using client-side apply.
-				err = a.clientSideApply(info, taskContext.EventChannel())
+	// Evaluate policy gates on the mutated object.
+	decision, err := policy.Evaluate(ctx, a.PolicyGates, obj)
+	switch decision {
+	case policy.Skip:
+		klog.V(4).Infof("apply skipped by policy (object: %s): %v", id, err)
+		taskContext.SendEvent(a.createApplySkippedEvent(id, obj, err))
+		return applyResult{id: id, skipped: true}
+	case policy.Fail:
+		if klog.V(4).Enabled() {
+			// only log event emitted errors if the verbosity > 4
+			klog.Errorf("apply policy errored (object: %s): %v", id, err)
+		}
+		taskContext.SendEvent(a.createApplyFailedEvent(id, object.WrapSourceError(err, source)))
+		return applyResult{id: id, failed: true}
+	}
+
+	if a.UpgradeClientSideApply && a.serverSideOptions(id).ServerSideApply {
+		upgrader := &ssa.Upgrader{
+			Client:       a.DynamicClient,
+			Mapper:       a.Mapper,
+			FieldManager: a.ServerSideOptions.FieldManager,
+		}
+		if _, err := upgrader.Upgrade(ctx, id, a.DryRunStrategy); err != nil {
+			if klog.V(4).Enabled() {
+				// only log event emitted errors if the verbosity > 4
+				klog.Errorf("apply upgrade errored (object: %s): %v", id, err)
 			}
-			if err != nil {
-				err = applyerror.NewApplyRunError(err)
-				if klog.V(4).Enabled() {
-					// only log event emitted errors if the verbosity > 4
-					klog.Errorf("apply errored (object: %s): %v", id, err)
-				}
-				taskContext.SendEvent(a.createApplyFailedEvent(id, err))
-				taskContext.InventoryManager().AddFailedApply(id)
-			} else if info.Object != nil {
-				acc, err := meta.Accessor(info.Object)
-				if err == nil {
-					uid := acc.GetUID()
-					gen := acc.GetGeneration()
-					taskContext.InventoryManager().AddSuccessfulApply(id, uid, gen)
-				}
+			taskContext.SendEvent(a.createApplyFailedEvent(id, object.WrapSourceError(err, source)))
+			return applyResult{id: id, failed: true}
+		}
+	}
+
+	if a.EmitDiffEvents {
+		taskContext.SendEvent(a.createDiffEvent(ctx, id, obj))
+	}
+
+	var previous *unstructured.Unstructured
+	journal := a.Journal != nil && !a.DryRunStrategy.ClientOrServerDryRun()
+	if journal {
+		previous, err = a.getLive(ctx, id)
+		if err != nil {
+			if klog.V(4).Enabled() {
+				// only log event emitted errors if the verbosity > 4
+				klog.Errorf("apply journal errored (object: %s): %v", id, err)
 			}
+			taskContext.SendEvent(a.createApplyFailedEvent(id, object.WrapSourceError(err, source)))
+			return applyResult{id: id, failed: true}
 		}
-		a.sendTaskResult(taskContext)
-	}()
+	}
+
+	_, span := taskContext.StartObjectSpan("Apply", id)
+	start := time.Now()
+	err = a.RetryPolicy.Do(a.retries, a.Throttle.Wrap(a.retries, func() error {
+		// Create a new instance of the applyOptions interface and use it
+		// to apply the objects.
+		ao := applyOptionsFactoryFunc(a.Name(), taskContext.EventChannel(),
+			a.serverSideOptions(id), a.DryRunStrategy, a.DynamicClient, a.OpenAPIGetter)
+		ao.SetObjects([]*resource.Info{info})
+		klog.V(5).Infof("applying object: %v", id)
+		return ao.Run()
+	}))
+	if err != nil && a.clientSideFallback(id, obj, err) {
+		klog.V(4).Infof("apply falling back to client-side apply (object: %s): %v", id, err)
+		err = a.RetryPolicy.Do(a.retries, a.Throttle.Wrap(a.retries, func() error {
+			return a.clientSideApply(info, taskContext.EventChannel())
+		}))
+	}
+	a.Metrics.ObserveApply(time.Since(start), err)
+	taskrunner.EndSpan(span, err)
+	if opts := a.serverSideOptions(id); apierrors.IsConflict(err) && opts.ServerSideApply && !opts.ForceConflicts {
+		if conflicts := a.detectConflicts(ctx, id, obj); len(conflicts) > 0 {
+			taskContext.SendEvent(a.createConflictEvent(id, conflicts))
+		}
+	}
+	if err != nil {
+		err = applyerror.NewApplyRunError(err)
+		if klog.V(4).Enabled() {
+			// only log event emitted errors if the verbosity > 4
+			klog.Errorf("apply errored (object: %s): %v", id, err)
+		}
+		taskContext.SendEvent(a.createApplyFailedEvent(id, object.WrapSourceError(err, source)))
+		return applyResult{id: id, failed: true}
+	}
+	if journal {
+		a.Journal.Record(id, previous)
+	}
+	return applyResult{id: id, applied: info.Object}
+}
+
+// getLive returns the object from the cluster, or nil if it does not exist.
+func (a *ApplyTask) getLive(ctx context.Context, id object.ObjMetadata) (*unstructured.Unstructured, error) {
+	client, err := object.ResourceClient(a.DynamicClient, a.Mapper, id)
+	if err != nil {
+		return nil, err
+	}
+	live, err := client.Get(ctx, id.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, reason.ForObject(id, fmt.Errorf("failed to get object %s: %w", id, err))
+	}
+	return live, nil
+}
+
+// recordResult records the outcome of applying an object in the inventory.
+func (a *ApplyTask) recordResult(taskContext *taskrunner.TaskContext, result applyResult) {
+	switch {
+	case result.failed:
+		taskContext.InventoryManager().AddFailedApply(result.id)
+	case result.skipped:
+		taskContext.InventoryManager().AddSkippedApply(result.id)
+	case result.applied != nil:
+		acc, err := meta.Accessor(result.applied)
+		if err == nil {
+			uid := acc.GetUID()
+			gen := acc.GetGeneration()
+			taskContext.InventoryManager().AddSuccessfulApply(result.id, uid, gen)
+		}
+	}
+}
+
+// serverSideOptions returns the ServerSideOptions to use when applying the
+// object with the given id, including any per-object conflict policy and
+// apply strategy.
+func (a *ApplyTask) serverSideOptions(id object.ObjMetadata) common.ServerSideOptions {
+	opts := a.ServerSideOptions
+	if force, found := a.ForceConflicts[id]; found {
+		opts.ForceConflicts = force
+	}
+	if clientSide := a.ClientSideApply[id]; clientSide {
+		opts.ServerSideApply = false
+	}
+	return opts
+}
+
+// clientSideFallback returns true if the object should be applied again
+// with client-side apply, after server-side apply failed with the error.
+func (a *ApplyTask) clientSideFallback(id object.ObjMetadata, obj *unstructured.Unstructured, err error) bool {
+	if !a.serverSideOptions(id).ServerSideApply {
+		return false
+	}
+	if isAPIService(obj) && isStreamError(err) {
+		// Server-side Apply doesn't work with APIService before k8s 1.21
+		// https://github.com/kubernetes/kubernetes/issues/89264
+		// Thus APIService is handled specially using client-side apply.
+		return true
+	}
+	if _, found := a.ClientSideApply[id]; found {
+		// Server-side apply was requested by annotation.
+		return false
+	}
+	return a.ClientSideApplyFallback && isServerSideApplyUnsupported(err)
+}
+
+// serverSideApplyUnsupportedMessages are messages of the errors returned by
+// the API server when it cannot compute a server-side apply patch, e.g.
+// for custom resources whose CRD schema is not structural.
+var serverSideApplyUnsupportedMessages = []string{
+	"failed to create typed patch object",
+	"failed to create typed live object",
+	"failed to create manager for existing fields",
+}
+
+// isServerSideApplyUnsupported checks if the error is returned by an API
+// server that doesn't support server-side apply of the object. Other errors,
+// e.g. conflicts, validation or authorization errors, would fail client-side
+// apply too, or would hide problems that server-side apply detects.
+func isServerSideApplyUnsupported(err error) bool {
+	if apierrors.IsUnsupportedMediaType(err) || apierrors.IsMethodNotSupported(err) {
+		return true
+	}
+	msg := err.Error()
+	for _, m := range serverSideApplyUnsupportedMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
 }
 
 func newApplyOptions(taskName string, eventChannel chan<- event.Event, serverSideOptions common.ServerSideOptions,
 	strategy common.DryRunStrategy, dynamicClient dynamic.Interface,
 	openAPIGetter discovery.OpenAPISchemaInterface) applyOptions {
 	emptyString := ""
+	// Server-side apply if flag set or server-side dry run.
+	serverSideApply := strategy.ServerDryRun() || serverSideOptions.ServerSideApply
+	applyStrategy := event.ApplyStrategyClientSide
+	if serverSideApply {
+		applyStrategy = event.ApplyStrategyServerSide
+	}
 	return &apply.ApplyOptions{
 		VisitedNamespaces: sets.NewString(),
 		VisitedUids:       sets.NewString(),
@@ -203,14 +453,14 @@ func newApplyOptions(taskName string, eventChannel chan<- event.Event, serverSid
 		PrintFlags: &genericclioptions.PrintFlags{
 			OutputFormat: &emptyString,
 		},
-		// Server-side apply if flag set or server-side dry run.
-		ServerSideApply: strategy.ServerDryRun() || serverSideOptions.ServerSideApply,
+		ServerSideApply: serverSideApply,
 		ForceConflicts:  serverSideOptions.ForceConflicts,
 		FieldManager:    serverSideOptions.FieldManager,
 		DryRunStrategy:  strategy.Strategy(),
 		ToPrinter: (&KubectlPrinterAdapter{
 			ch:        eventChannel,
 			groupName: taskName,
+			strategy:  applyStrategy,
 		}).toPrinterFunc(),
 		DynamicClient:  dynamicClient,
 		DryRunVerifier: resource.NewQueryParamVerifier(dynamicClient, openAPIGetter, resource.QueryParamDryRun),
@@ -222,8 +472,14 @@ func (a *ApplyTask) sendTaskResult(taskContext *taskrunner.TaskContext) {
 	taskContext.TaskChannel() <- taskrunner.TaskResult{}
 }
 
-// Cancel is not supported by the ApplyTask.
-func (a *ApplyTask) Cancel(_ *taskrunner.TaskContext) {}
+// Cancel stops retrying objects that failed with a transient error. The
+// remaining objects are still applied, because the ApplyTask can not be
+// interrupted.
+func (a *ApplyTask) Cancel(_ *taskrunner.TaskContext) {
+	if a.cancelRetries != nil {
+		a.cancelRetries()
+	}
+}
 
 // StatusUpdate is not supported by the ApplyTask.
 func (a *ApplyTask) StatusUpdate(_ *taskrunner.TaskContext, _ object.ObjMetadata) {}
@@ -269,6 +525,35 @@ func (a *ApplyTask) createApplySkippedEvent(id object.ObjMetadata, resource *uns
 	}
 }
 
+// createDiffEvent computes the diff of the object using a server-side
+// dry-run apply. Errors are reported in the event and do not prevent the
+// object from being applied.
+func (a *ApplyTask) createDiffEvent(ctx context.Context, id object.ObjMetadata, obj *unstructured.Unstructured) event.Event {
+	fieldManager := a.ServerSideOptions.FieldManager
+	if fieldManager == "" {
+		fieldManager = common.DefaultFieldManager
+	}
+	differ := &diff.Differ{
+		Client:       a.DynamicClient,
+		Mapper:       a.Mapper,
+		FieldManager: fieldManager,
+	}
+	diffs, err := differ.Diff(ctx, obj)
+	if err != nil && klog.V(4).Enabled() {
+		// only log event emitted errors if the verbosity > 4
+		klog.Errorf("apply diff errored (object: %s): %v", id, err)
+	}
+	return event.Event{
+		Type: event.DiffType,
+		DiffEvent: event.DiffEvent{
+			GroupName:  a.Name(),
+			Identifier: id,
+			Diffs:      diffs,
+			Error:      err,
+		},
+	}
+}
+
 func isAPIService(obj *unstructured.Unstructured) bool {
 	gk := obj.GroupVersionKind().GroupKind()
 	return gk.Group == "apiregistration.k8s.io" && gk.Kind == "APIService"
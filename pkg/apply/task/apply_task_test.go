@@ -4,24 +4,35 @@
 package task
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
 	"sigs.k8s.io/cli-utils/pkg/apply/cache"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/policy"
+	"sigs.k8s.io/cli-utils/pkg/apply/rollback"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/cli-utils/pkg/testutil"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
 )
 
 type resourceInfo struct {
@@ -210,6 +221,658 @@ func TestApplyTask_FetchGeneration(t *testing.T) {
 	}
 }
 
+func TestApplyTask_ForceConflicts(t *testing.T) {
+	rss := []resourceInfo{
+		{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       "forced",
+			namespace:  "default",
+		},
+		{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       "not-forced",
+			namespace:  "default",
+		},
+		{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       "default",
+			namespace:  "default",
+		},
+	}
+	forcedID := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		Name:      "forced",
+		Namespace: "default",
+	}
+	notForcedID := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		Name:      "not-forced",
+		Namespace: "default",
+	}
+
+	testCases := map[string]struct {
+		globalForce   bool
+		expectedForce map[string]bool
+	}{
+		"global force disabled": {
+			globalForce: false,
+			expectedForce: map[string]bool{
+				"forced":     true,
+				"not-forced": false,
+				"default":    false,
+			},
+		},
+		"global force enabled": {
+			globalForce: true,
+			expectedForce: map[string]bool{
+				"forced":     true,
+				"not-forced": false,
+				"default":    true,
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			eventChannel := make(chan event.Event)
+			defer close(eventChannel)
+			resourceCache := cache.NewResourceCacheMap()
+			taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+
+			objs := toUnstructureds(rss)
+
+			actualForce := make(map[string]bool)
+			oldAO := applyOptionsFactoryFunc
+			applyOptionsFactoryFunc = func(_ string, _ chan<- event.Event, serverSideOptions common.ServerSideOptions, _ common.DryRunStrategy,
+				_ dynamic.Interface, _ discovery.OpenAPISchemaInterface) applyOptions {
+				return &fakeApplyOptions{
+					forceConflicts: serverSideOptions.ForceConflicts,
+					appliedForce:   actualForce,
+				}
+			}
+			defer func() { applyOptionsFactoryFunc = oldAO }()
+
+			applyTask := &ApplyTask{
+				Objects:    objs,
+				InfoHelper: &fakeInfoHelper{},
+				ServerSideOptions: common.ServerSideOptions{
+					ServerSideApply: true,
+					ForceConflicts:  tc.globalForce,
+				},
+				ForceConflicts: map[object.ObjMetadata]bool{
+					forcedID:    true,
+					notForcedID: false,
+				},
+			}
+			applyTask.Start(taskContext)
+			<-taskContext.TaskChannel()
+
+			assert.Equal(t, tc.expectedForce, actualForce)
+		})
+	}
+}
+
+func TestApplyTask_ClientSideApply(t *testing.T) {
+	rss := []resourceInfo{
+		{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       "client-side",
+			namespace:  "default",
+		},
+		{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       "server-side",
+			namespace:  "default",
+		},
+		{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       "default",
+			namespace:  "default",
+		},
+	}
+	ids := make(map[string]object.ObjMetadata)
+	for _, rs := range rss {
+		ids[rs.name] = object.ObjMetadata{
+			GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+			Name:      rs.name,
+			Namespace: "default",
+		}
+	}
+	schemaErr := apierrors.NewInternalError(errors.New("failed to create typed patch object"))
+	conflictErr := apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"},
+		"default", errors.New("conflict with kubectl"))
+	unsupportedErr := apierrors.NewGenericServerResponse(http.StatusUnsupportedMediaType, "patch",
+		schema.GroupResource{Group: "apps", Resource: "deployments"}, "default", "", 0, false)
+	forbiddenErr := apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"},
+		"default", errors.New("not allowed"))
+
+	testCases := map[string]struct {
+		fallback         bool
+		serverSideErr    error
+		expectedAttempts map[string][]bool
+		expectedFailed   []string
+	}{
+		"fallback disabled": {
+			fallback:      false,
+			serverSideErr: schemaErr,
+			expectedAttempts: map[string][]bool{
+				"client-side": {false},
+				"server-side": {true},
+				"default":     {true},
+			},
+			expectedFailed: []string{"server-side", "default"},
+		},
+		"fallback enabled": {
+			fallback:      true,
+			serverSideErr: schemaErr,
+			expectedAttempts: map[string][]bool{
+				"client-side": {false},
+				"server-side": {true},
+				"default":     {true, false},
+			},
+			expectedFailed: []string{"server-side"},
+		},
+		"unsupported patch type falls back": {
+			fallback:      true,
+			serverSideErr: unsupportedErr,
+			expectedAttempts: map[string][]bool{
+				"client-side": {false},
+				"server-side": {true},
+				"default":     {true, false},
+			},
+			expectedFailed: []string{"server-side"},
+		},
+		"forbidden doesn't fall back": {
+			fallback:      true,
+			serverSideErr: forbiddenErr,
+			expectedAttempts: map[string][]bool{
+				"client-side": {false},
+				"server-side": {true},
+				"default":     {true},
+			},
+			expectedFailed: []string{"server-side", "default"},
+		},
+		"conflicts don't fall back": {
+			fallback:      true,
+			serverSideErr: conflictErr,
+			expectedAttempts: map[string][]bool{
+				"client-side": {false},
+				"server-side": {true},
+				"default":     {true},
+			},
+			expectedFailed: []string{"server-side", "default"},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			eventChannel := make(chan event.Event)
+			defer close(eventChannel)
+			resourceCache := cache.NewResourceCacheMap()
+			taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+			go func() {
+				for range eventChannel {
+				}
+			}()
+
+			attempts := make(map[string][]bool)
+			oldAO := applyOptionsFactoryFunc
+			applyOptionsFactoryFunc = func(_ string, _ chan<- event.Event, serverSideOptions common.ServerSideOptions, _ common.DryRunStrategy,
+				_ dynamic.Interface, _ discovery.OpenAPISchemaInterface) applyOptions {
+				return &strategyApplyOptions{
+					serverSide:    serverSideOptions.ServerSideApply,
+					serverSideErr: tc.serverSideErr,
+					attempts:      attempts,
+				}
+			}
+			defer func() { applyOptionsFactoryFunc = oldAO }()
+
+			applyTask := &ApplyTask{
+				Objects:    toUnstructureds(rss),
+				InfoHelper: &fakeInfoHelper{},
+				// Forced, to not detect conflicts without a mapper
+				ServerSideOptions: common.ServerSideOptions{
+					ServerSideApply: true,
+					ForceConflicts:  true,
+				},
+				ClientSideApply: map[object.ObjMetadata]bool{
+					ids["client-side"]: true,
+					ids["server-side"]: false,
+				},
+				ClientSideApplyFallback: tc.fallback,
+			}
+			applyTask.Start(taskContext)
+			<-taskContext.TaskChannel()
+
+			assert.Equal(t, tc.expectedAttempts, attempts)
+			var failed []string
+			for _, rs := range rss {
+				if taskContext.InventoryManager().IsFailedApply(ids[rs.name]) {
+					failed = append(failed, rs.name)
+				}
+			}
+			assert.Equal(t, tc.expectedFailed, failed)
+		})
+	}
+}
+
+// strategyApplyOptions records whether each object was applied server-side,
+// failing every server-side apply with serverSideErr.
+type strategyApplyOptions struct {
+	objects       []*resource.Info
+	serverSide    bool
+	serverSideErr error
+	attempts      map[string][]bool
+}
+
+func (f *strategyApplyOptions) Run() error {
+	for _, obj := range f.objects {
+		f.attempts[obj.Name] = append(f.attempts[obj.Name], f.serverSide)
+	}
+	if f.serverSide {
+		return f.serverSideErr
+	}
+	return nil
+}
+
+func (f *strategyApplyOptions) SetObjects(objects []*resource.Info) {
+	f.objects = objects
+}
+
+func TestApplyTask_Retry(t *testing.T) {
+	rss := []resourceInfo{
+		{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       "foo",
+			namespace:  "default",
+		},
+	}
+	id := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		Name:      "foo",
+		Namespace: "default",
+	}
+	transientErr := apierrors.NewTooManyRequests("slow down", 1)
+
+	testCases := map[string]struct {
+		retryPolicy      *RetryPolicy
+		failures         int
+		expectedAttempts int
+		expectedFailed   bool
+	}{
+		"no retry policy": {
+			retryPolicy:      nil,
+			failures:         1,
+			expectedAttempts: 1,
+			expectedFailed:   true,
+		},
+		"transient failure is retried": {
+			retryPolicy:      &RetryPolicy{MaxAttempts: 3},
+			failures:         2,
+			expectedAttempts: 3,
+			expectedFailed:   false,
+		},
+		"retries are exhausted": {
+			retryPolicy:      &RetryPolicy{MaxAttempts: 3},
+			failures:         3,
+			expectedAttempts: 3,
+			expectedFailed:   true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			eventChannel := make(chan event.Event)
+			resourceCache := cache.NewResourceCacheMap()
+			taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+
+			attempts := 0
+			oldAO := applyOptionsFactoryFunc
+			applyOptionsFactoryFunc = func(string, chan<- event.Event, common.ServerSideOptions, common.DryRunStrategy,
+				dynamic.Interface, discovery.OpenAPISchemaInterface) applyOptions {
+				attempts++
+				if attempts <= tc.failures {
+					return &fakeApplyOptions{err: transientErr}
+				}
+				return &fakeApplyOptions{}
+			}
+			defer func() { applyOptionsFactoryFunc = oldAO }()
+
+			applyTask := &ApplyTask{
+				Objects:     toUnstructureds(rss),
+				InfoHelper:  &fakeInfoHelper{},
+				RetryPolicy: tc.retryPolicy,
+			}
+
+			var events []event.Event
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for msg := range eventChannel {
+					events = append(events, msg)
+				}
+			}()
+
+			applyTask.Start(taskContext)
+			<-taskContext.TaskChannel()
+			close(eventChannel)
+			wg.Wait()
+
+			assert.Equal(t, tc.expectedAttempts, attempts)
+			assert.Equal(t, tc.expectedFailed, taskContext.InventoryManager().IsFailedApply(id))
+			if tc.expectedFailed {
+				assert.Len(t, events, 1)
+			} else {
+				assert.Empty(t, events)
+			}
+		})
+	}
+}
+
+func TestApplyTask_Concurrency(t *testing.T) {
+	const concurrency = 3
+	var rss []resourceInfo
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("foo-%d", i)
+		if i == 4 {
+			name = "foo-failure"
+		}
+		rss = append(rss, resourceInfo{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       name,
+			namespace:  "default",
+			uid:        types.UID(name),
+			generation: int64(i),
+		})
+	}
+	objs := toUnstructureds(rss)
+
+	eventChannel := make(chan event.Event)
+	resourceCache := cache.NewResourceCacheMap()
+	taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+
+	tracker := &concurrencyTracker{
+		limit: concurrency,
+		ready: make(chan struct{}),
+	}
+	oldAO := applyOptionsFactoryFunc
+	applyOptionsFactoryFunc = func(string, chan<- event.Event, common.ServerSideOptions, common.DryRunStrategy,
+		dynamic.Interface, discovery.OpenAPISchemaInterface) applyOptions {
+		return &trackingApplyOptions{tracker: tracker}
+	}
+	defer func() { applyOptionsFactoryFunc = oldAO }()
+
+	applyTask := &ApplyTask{
+		Objects:     objs,
+		InfoHelper:  &fakeInfoHelper{},
+		Concurrency: concurrency,
+	}
+
+	var events []event.Event
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for msg := range eventChannel {
+			events = append(events, msg)
+		}
+	}()
+
+	applyTask.Start(taskContext)
+	<-taskContext.TaskChannel()
+	close(eventChannel)
+	wg.Wait()
+
+	assert.Equal(t, concurrency, tracker.max)
+	assert.Len(t, events, 1)
+
+	im := taskContext.InventoryManager()
+	for i, id := range object.UnstructuredSetToObjMetadataSet(objs) {
+		if i == 4 {
+			assert.True(t, im.IsFailedApply(id), id)
+			continue
+		}
+		assert.True(t, im.IsSuccessfulApply(id), id)
+		gen, _ := im.AppliedGeneration(id)
+		assert.Equal(t, int64(i), gen, id)
+	}
+}
+
+// concurrencyTracker records the maximum number of concurrent applies.
+// Applies block until limit applies are in flight, so the test fails
+// instead of passing by chance if the objects are applied serially.
+type concurrencyTracker struct {
+	mu       sync.Mutex
+	limit    int
+	inFlight int
+	max      int
+	ready    chan struct{}
+	once     sync.Once
+}
+
+type trackingApplyOptions struct {
+	fakeApplyOptions
+	tracker *concurrencyTracker
+}
+
+func (f *trackingApplyOptions) Run() error {
+	tr := f.tracker
+	tr.mu.Lock()
+	tr.inFlight++
+	if tr.inFlight > tr.max {
+		tr.max = tr.inFlight
+	}
+	if tr.inFlight == tr.limit {
+		tr.once.Do(func() { close(tr.ready) })
+	}
+	tr.mu.Unlock()
+
+	select {
+	case <-tr.ready:
+	case <-time.After(5 * time.Second):
+	}
+
+	defer func() {
+		tr.mu.Lock()
+		tr.inFlight--
+		tr.mu.Unlock()
+	}()
+	return f.fakeApplyOptions.Run()
+}
+
+func TestApplyTask_Journal(t *testing.T) {
+	rss := []resourceInfo{
+		{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       "existing",
+			namespace:  "default",
+		},
+		{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       "new",
+			namespace:  "default",
+		},
+	}
+	objs := toUnstructureds(rss)
+	existing := objs[0].DeepCopy()
+	existing.SetResourceVersion("7")
+
+	testCases := map[string]struct {
+		dryRun          common.DryRunStrategy
+		expectedEntries int
+	}{
+		"applied objects are recorded": {
+			dryRun:          common.DryRunNone,
+			expectedEntries: 2,
+		},
+		"dry-run is not recorded": {
+			dryRun:          common.DryRunServer,
+			expectedEntries: 0,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			eventChannel := make(chan event.Event)
+			defer close(eventChannel)
+			resourceCache := cache.NewResourceCacheMap()
+			taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+
+			oldAO := applyOptionsFactoryFunc
+			applyOptionsFactoryFunc = func(string, chan<- event.Event, common.ServerSideOptions, common.DryRunStrategy,
+				dynamic.Interface, discovery.OpenAPISchemaInterface) applyOptions {
+				return &fakeApplyOptions{}
+			}
+			defer func() { applyOptionsFactoryFunc = oldAO }()
+
+			journal := rollback.NewJournal()
+			applyTask := &ApplyTask{
+				Objects:        objs,
+				InfoHelper:     &fakeInfoHelper{},
+				DryRunStrategy: tc.dryRun,
+				DynamicClient:  dynamicfake.NewSimpleDynamicClient(scheme.Scheme, existing),
+				Mapper: testutil.NewFakeRESTMapper(schema.GroupVersionKind{
+					Group:   "apps",
+					Version: "v1",
+					Kind:    "Deployment",
+				}),
+				Journal: journal,
+			}
+			applyTask.Start(taskContext)
+			<-taskContext.TaskChannel()
+
+			entries := journal.Entries()
+			if !assert.Len(t, entries, tc.expectedEntries) || tc.expectedEntries == 0 {
+				return
+			}
+			assert.Equal(t, object.UnstructuredToObjMetadata(objs[0]), entries[0].Identifier)
+			assert.False(t, entries[0].Created())
+			assert.Equal(t, "7", entries[0].Previous.GetResourceVersion())
+			assert.Equal(t, object.UnstructuredToObjMetadata(objs[1]), entries[1].Identifier)
+			assert.True(t, entries[1].Created())
+		})
+	}
+}
+
+// labelGate skips objects with the skip label and fails objects with the
+// fail label.
+type labelGate struct{}
+
+func (labelGate) Name() string { return "label-gate" }
+
+func (labelGate) Evaluate(_ context.Context, obj *unstructured.Unstructured) (policy.Decision, string, error) {
+	switch {
+	case obj.GetLabels()["skip"] != "":
+		return policy.Skip, obj.GetLabels()["skip"], nil
+	case obj.GetLabels()["fail"] != "":
+		return policy.Fail, obj.GetLabels()["fail"], nil
+	}
+	return policy.Allow, "", nil
+}
+
+func TestApplyTask_PolicyGates(t *testing.T) {
+	rss := []resourceInfo{
+		{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       "allowed",
+			namespace:  "default",
+		},
+		{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       "skipped",
+			namespace:  "default",
+		},
+		{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       "failed",
+			namespace:  "default",
+		},
+	}
+	objs := toUnstructureds(rss)
+	objs[1].SetLabels(map[string]string{"skip": "hostPath volumes are not allowed"})
+	objs[2].SetLabels(map[string]string{"fail": "owner label is required"})
+	ids := object.UnstructuredSetToObjMetadataSet(objs)
+
+	eventChannel := make(chan event.Event)
+	resourceCache := cache.NewResourceCacheMap()
+	taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+
+	ao := &fakeApplyOptions{}
+	oldAO := applyOptionsFactoryFunc
+	applyOptionsFactoryFunc = func(string, chan<- event.Event, common.ServerSideOptions, common.DryRunStrategy,
+		dynamic.Interface, discovery.OpenAPISchemaInterface) applyOptions {
+		return ao
+	}
+	defer func() { applyOptionsFactoryFunc = oldAO }()
+
+	applyTask := &ApplyTask{
+		Objects:     objs,
+		InfoHelper:  &fakeInfoHelper{},
+		PolicyGates: []policy.Gate{labelGate{}},
+	}
+
+	var events []event.ApplyEvent
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for msg := range eventChannel {
+			events = append(events, msg.ApplyEvent)
+		}
+	}()
+	applyTask.Start(taskContext)
+	<-taskContext.TaskChannel()
+	close(eventChannel)
+	wg.Wait()
+
+	// Successful apply events are sent by the apply options.
+	require.Len(t, ao.passedObjects, 1)
+	assert.Equal(t, "allowed", ao.passedObjects[0].Name)
+	require.Len(t, events, 2)
+	for _, e := range events {
+		switch e.Identifier {
+		case ids[1]:
+			assert.Equal(t, event.ApplySkipped, e.Status)
+			assert.EqualError(t, e.Error, `policy "label-gate": hostPath volumes are not allowed`)
+		case ids[2]:
+			assert.Equal(t, event.ApplyFailed, e.Status)
+			assert.EqualError(t, e.Error, `policy "label-gate": owner label is required`)
+		default:
+			t.Errorf("unexpected event: %v", e)
+		}
+	}
+	im := taskContext.InventoryManager()
+	assert.True(t, im.IsSkippedApply(ids[1]))
+	assert.True(t, im.IsFailedApply(ids[2]))
+}
+
 func TestApplyTask_DryRun(t *testing.T) {
 	testCases := map[string]struct {
 		objs            []*unstructured.Unstructured
@@ -428,6 +1091,41 @@ func TestApplyTaskWithError(t *testing.T) {
 				},
 			},
 		},
+		"apply error reports the source of the object": {
+			objs: []*unstructured.Unstructured{
+				toUnstructured(map[string]interface{}{
+					"apiVersion": "apps/v1",
+					"kind":       "Deployment",
+					"metadata": map[string]interface{}{
+						"name":      "deployment-with-failure",
+						"namespace": "default",
+						"annotations": map[string]interface{}{
+							kioutil.PathAnnotation:      "deployment.yaml",
+							object.SourceLineAnnotation: "42",
+						},
+					},
+				}),
+			},
+			expectedEvents: []event.Event{
+				{
+					Type: event.ApplyType,
+					ApplyEvent: event.ApplyEvent{
+						Status: event.ApplyFailed,
+						Error:  fmt.Errorf("deployment.yaml:42: expected apply error"),
+					},
+				},
+			},
+			expectedFailed: object.ObjMetadataSet{
+				{
+					GroupKind: schema.GroupKind{
+						Group: "apps",
+						Kind:  "Deployment",
+					},
+					Name:      "deployment-with-failure",
+					Namespace: "default",
+				},
+			},
+		},
 	}
 
 	for tn, tc := range testCases {
@@ -544,13 +1242,22 @@ func toUnstructureds(rss []resourceInfo) []*unstructured.Unstructured {
 }
 
 type fakeApplyOptions struct {
-	objects       []*resource.Info
-	passedObjects []*resource.Info
+	objects        []*resource.Info
+	passedObjects  []*resource.Info
+	forceConflicts bool
+	appliedForce   map[string]bool
+	err            error
 }
 
 func (f *fakeApplyOptions) Run() error {
+	if f.err != nil {
+		return f.err
+	}
 	var err error
 	for _, obj := range f.objects {
+		if f.appliedForce != nil {
+			f.appliedForce[obj.Name] = f.forceConflicts
+		}
 		if strings.Contains(obj.Name, "failure") {
 			err = fmt.Errorf("expected apply error")
 		} else {
@@ -0,0 +1,51 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	// ConflictPolicyAnnotation is the annotation key used to override the
+	// server-side apply conflict resolution for a single object.
+	ConflictPolicyAnnotation = "config.kubernetes.io/ssa-conflict-policy"
+
+	// ConflictPolicyForce forces conflicts to be resolved by taking
+	// ownership of the conflicting fields, like --force-conflicts.
+	ConflictPolicyForce = "force"
+
+	// ConflictPolicyFail fails the apply if there are conflicting fields,
+	// even if --force-conflicts is set.
+	ConflictPolicyFail = "fail"
+)
+
+// ReadConflictPolicy reads the ssa-conflict-policy annotation and returns
+// whether conflicts should be forced for the object. The second return
+// value is false if the annotation is not present, in which case the
+// global ServerSideOptions apply.
+func ReadConflictPolicy(u *unstructured.Unstructured) (bool, bool, error) {
+	if u == nil {
+		return false, false, nil
+	}
+	policy, found := u.GetAnnotations()[ConflictPolicyAnnotation]
+	if !found {
+		return false, false, nil
+	}
+	switch policy {
+	case ConflictPolicyForce:
+		return true, true, nil
+	case ConflictPolicyFail:
+		return false, true, nil
+	default:
+		return false, false, object.InvalidAnnotationError{
+			Annotation: ConflictPolicyAnnotation,
+			Cause: fmt.Errorf("must be %q or %q, got %q",
+				ConflictPolicyForce, ConflictPolicyFail, policy),
+		}
+	}
+}
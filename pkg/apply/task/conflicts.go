@@ -0,0 +1,85 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// conflictManagerRegexp matches the manager in the message of a field
+// manager conflict cause, e.g. `conflict with "kubectl-edit" using apps/v1`.
+var conflictManagerRegexp = regexp.MustCompile(`conflict with "([^"]*)"`)
+
+// fieldConflicts returns the field manager conflicts reported by a
+// server-side apply error, or nil if the error is not a conflict.
+func fieldConflicts(err error) []event.FieldConflict {
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) || !apierrors.IsConflict(err) {
+		return nil
+	}
+	details := statusErr.Status().Details
+	if details == nil {
+		return nil
+	}
+	var conflicts []event.FieldConflict
+	for _, cause := range details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		var manager string
+		if m := conflictManagerRegexp.FindStringSubmatch(cause.Message); m != nil {
+			manager = m[1]
+		}
+		conflicts = append(conflicts, event.FieldConflict{
+			Manager: manager,
+			Field:   cause.Field,
+		})
+	}
+	return conflicts
+}
+
+// detectConflicts returns the fields of the object owned by other field
+// managers, after its server-side apply failed with a conflict. kubectl does
+// not preserve the causes of a failed apply, so the object is applied again
+// with a server-side dry-run to find the conflicts.
+func (a *ApplyTask) detectConflicts(ctx context.Context, id object.ObjMetadata, obj *unstructured.Unstructured) []event.FieldConflict {
+	client, err := object.ResourceClient(a.DynamicClient, a.Mapper, id)
+	if err != nil {
+		return nil
+	}
+	fieldManager := a.ServerSideOptions.FieldManager
+	if fieldManager == "" {
+		fieldManager = common.DefaultFieldManager
+	}
+	_, err = client.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+		DryRun:       []string{metav1.DryRunAll},
+		FieldManager: fieldManager,
+	})
+	if err != nil && klog.V(4).Enabled() {
+		// only log event emitted errors if the verbosity > 4
+		klog.Errorf("apply conflict detection errored (object: %s): %v", id, err)
+	}
+	return fieldConflicts(err)
+}
+
+func (a *ApplyTask) createConflictEvent(id object.ObjMetadata, conflicts []event.FieldConflict) event.Event {
+	return event.Event{
+		Type: event.ConflictType,
+		ConflictEvent: event.ConflictEvent{
+			GroupName:  a.Name(),
+			Identifier: id,
+			Conflicts:  conflicts,
+		},
+	}
+}
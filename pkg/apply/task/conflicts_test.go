@@ -0,0 +1,207 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/apply/cache"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func conflictError(causes ...metav1.StatusCause) error {
+	return &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusConflict,
+		Reason:  metav1.StatusReasonConflict,
+		Message: "Apply failed with conflicts",
+		Details: &metav1.StatusDetails{Causes: causes},
+	}}
+}
+
+func TestFieldConflicts(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		expected []event.FieldConflict
+	}{
+		"no error": {
+			err:      nil,
+			expected: nil,
+		},
+		"not a status error": {
+			err:      errors.New("apply failed"),
+			expected: nil,
+		},
+		"not a conflict": {
+			err:      apierrors.NewNotFound(schema.GroupResource{Resource: "deployments"}, "foo"),
+			expected: nil,
+		},
+		"field manager conflicts": {
+			err: fmt.Errorf("wrapped: %w", conflictError(
+				metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldManagerConflict,
+					Message: `conflict with "kubectl-edit" using apps/v1`,
+					Field:   ".spec.replicas",
+				},
+				metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "ignored",
+					Field:   ".spec.selector",
+				},
+				metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldManagerConflict,
+					Message: `conflict with "hpa-controller" with subresource "scale" using apps/v1`,
+					Field:   ".spec.template.spec.containers[name=\"app\"].resources",
+				},
+			)),
+			expected: []event.FieldConflict{
+				{Manager: "kubectl-edit", Field: ".spec.replicas"},
+				{Manager: "hpa-controller", Field: ".spec.template.spec.containers[name=\"app\"].resources"},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expected, fieldConflicts(tc.err))
+		})
+	}
+}
+
+func TestApplyTask_ConflictEvent(t *testing.T) {
+	rss := []resourceInfo{
+		{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       "foo",
+			namespace:  "default",
+		},
+	}
+	id := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		Name:      "foo",
+		Namespace: "default",
+	}
+
+	// kubectl does not preserve the causes of the conflict.
+	applyConflictErr := apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"},
+		"foo", errors.New("Apply failed with 1 conflict"))
+
+	testCases := map[string]struct {
+		forceConflicts bool
+		applyErr       error
+		expectedEvents []event.Type
+		detected       bool
+	}{
+		"conflicts are reported": {
+			forceConflicts: false,
+			applyErr:       applyConflictErr,
+			expectedEvents: []event.Type{event.ConflictType, event.ApplyType},
+			detected:       true,
+		},
+		"conflicts are not detected when forced": {
+			forceConflicts: true,
+			applyErr:       applyConflictErr,
+			expectedEvents: []event.Type{event.ApplyType},
+		},
+		"conflicts are not detected for other errors": {
+			forceConflicts: false,
+			applyErr:       apierrors.NewBadRequest("invalid"),
+			expectedEvents: []event.Type{event.ApplyType},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			eventChannel := make(chan event.Event)
+			resourceCache := cache.NewResourceCacheMap()
+			taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+
+			dc := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+			dryRuns := 0
+			dc.PrependReactor("patch", "deployments", func(clienttesting.Action) (bool, runtime.Object, error) {
+				dryRuns++
+				return true, nil, conflictError(metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldManagerConflict,
+					Message: `conflict with "kubectl-edit" using apps/v1`,
+					Field:   ".spec.replicas",
+				})
+			})
+
+			oldAO := applyOptionsFactoryFunc
+			applyOptionsFactoryFunc = func(string, chan<- event.Event, common.ServerSideOptions, common.DryRunStrategy,
+				dynamic.Interface, discovery.OpenAPISchemaInterface) applyOptions {
+				return &fakeApplyOptions{err: tc.applyErr}
+			}
+			defer func() { applyOptionsFactoryFunc = oldAO }()
+
+			applyTask := &ApplyTask{
+				TaskName:      "apply-0",
+				Objects:       toUnstructureds(rss),
+				InfoHelper:    &fakeInfoHelper{},
+				DynamicClient: dc,
+				Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+					scheme.Scheme.PrioritizedVersionsAllGroups()...),
+				ServerSideOptions: common.ServerSideOptions{
+					ServerSideApply: true,
+					ForceConflicts:  tc.forceConflicts,
+					FieldManager:    "my-manager",
+				},
+			}
+
+			var events []event.Event
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for msg := range eventChannel {
+					events = append(events, msg)
+				}
+			}()
+
+			applyTask.Start(taskContext)
+			<-taskContext.TaskChannel()
+			close(eventChannel)
+			wg.Wait()
+
+			var types []event.Type
+			for _, e := range events {
+				types = append(types, e.Type)
+			}
+			assert.Equal(t, tc.expectedEvents, types)
+			assert.True(t, taskContext.InventoryManager().IsFailedApply(id))
+			if !tc.detected {
+				assert.Equal(t, 0, dryRuns)
+				return
+			}
+			assert.Equal(t, 1, dryRuns)
+			assert.Equal(t, event.ConflictEvent{
+				GroupName:  "apply-0",
+				Identifier: id,
+				Conflicts: []event.FieldConflict{
+					{Manager: "kubectl-edit", Field: ".spec.replicas"},
+				},
+			}, events[0].ConflictEvent)
+		})
+	}
+}
@@ -103,7 +103,7 @@ func (i *InvSetTask) Start(taskContext *taskrunner.TaskContext) {
 		klog.V(4).Infof("keep in inventory %d skipped prunes", len(pruneSkips))
 		invObjs = invObjs.Union(pruneSkips)
 
-		// If an object is abandoned, then remove it from the inventory.
+		// If an object is abandoned or detached, then remove it from the inventory.
 		abandonedObjects := taskContext.AbandonedObjects()
 		klog.V(4).Infof("remove from inventory %d abandoned objects", len(abandonedObjects))
 		invObjs = invObjs.Diff(abandonedObjects)
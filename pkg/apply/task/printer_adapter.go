@@ -22,6 +22,7 @@ import (
 type KubectlPrinterAdapter struct {
 	ch        chan<- event.Event
 	groupName string
+	strategy  event.ApplyStrategy
 }
 
 // resourcePrinterImpl implements the ResourcePrinter interface. But
@@ -30,6 +31,7 @@ type resourcePrinterImpl struct {
 	applyStatus event.ApplyEventStatus
 	ch          chan<- event.Event
 	groupName   string
+	strategy    event.ApplyStrategy
 }
 
 // PrintObj takes the provided object and operation and emits
@@ -46,6 +48,7 @@ func (r *resourcePrinterImpl) PrintObj(obj runtime.Object, _ io.Writer) error {
 			Identifier: id,
 			Status:     r.applyStatus,
 			Resource:   obj.(*unstructured.Unstructured),
+			Strategy:   r.strategy,
 		},
 	}
 	return nil
@@ -62,6 +65,7 @@ func (p *KubectlPrinterAdapter) toPrinterFunc() toPrinterFunc {
 			ch:          p.ch,
 			applyStatus: applyStatus,
 			groupName:   p.groupName,
+			strategy:    p.strategy,
 		}, err
 	}
 }
@@ -21,6 +21,7 @@ func TestKubectlPrinterAdapter(t *testing.T) {
 	adapter := KubectlPrinterAdapter{
 		ch:        ch,
 		groupName: "test-0",
+		strategy:  event.ApplyStrategyServerSide,
 	}
 
 	toPrinterFunc := adapter.toPrinterFunc()
@@ -52,4 +53,5 @@ func TestKubectlPrinterAdapter(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, event.ApplySuccessful, msg.ApplyEvent.Status)
 	assert.Equal(t, deployment, msg.ApplyEvent.Resource)
+	assert.Equal(t, event.ApplyStrategyServerSide, msg.ApplyEvent.Strategy)
 }
@@ -4,6 +4,8 @@
 package task
 
 import (
+	"context"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
@@ -25,9 +27,26 @@ type PruneTask struct {
 	Filters           []filter.ValidationFilter
 	DryRunStrategy    common.DryRunStrategy
 	PropagationPolicy metav1.DeletionPropagation
+	// GracePeriodSeconds is passed to each delete request. If nil, the
+	// default grace period of the resource type is used.
+	GracePeriodSeconds *int64
 	// True if we are destroying, which deletes the inventory object
 	// as well (possibly) the inventory namespace.
 	Destroy bool
+	// RetryPolicy defines how to retry deletes that failed with a
+	// transient error. If nil, each object is deleted once.
+	RetryPolicy *RetryPolicy
+	// Throttle limits the rate of delete requests, including retries. If
+	// nil, deletes are not throttled.
+	Throttle *Throttle
+	// Concurrency is the maximum number of objects deleted at the same
+	// time. Objects are deleted one by one if it is less than 2.
+	Concurrency int
+
+	// retries is done when the task is cancelled, to stop waiting between
+	// the attempts of the RetryPolicy.
+	retries       context.Context
+	cancelRetries context.CancelFunc
 }
 
 func (p *PruneTask) Name() string {
@@ -51,7 +70,9 @@ func (p *PruneTask) Identifiers() object.ObjMetadataSet {
 // the cluster. It will push a TaskResult on the taskChannel
 // to signal to the taskrunner that the task has completed (or failed).
 func (p *PruneTask) Start(taskContext *taskrunner.TaskContext) {
+	p.retries, p.cancelRetries = context.WithCancel(context.Background())
 	go func() {
+		defer p.cancelRetries()
 		klog.V(2).Infof("prune task starting (name: %q, objects: %d)",
 			p.Name(), len(p.Objects))
 		// Create filter to prevent deletion of currently applied
@@ -66,9 +87,12 @@ func (p *PruneTask) Start(taskContext *taskrunner.TaskContext) {
 			taskContext,
 			p.Name(),
 			prune.Options{
-				DryRunStrategy:    p.DryRunStrategy,
-				PropagationPolicy: p.PropagationPolicy,
-				Destroy:           p.Destroy,
+				DryRunStrategy:     p.DryRunStrategy,
+				PropagationPolicy:  p.PropagationPolicy,
+				GracePeriodSeconds: p.GracePeriodSeconds,
+				Destroy:            p.Destroy,
+				Retry:              p.retry,
+				Concurrency:        p.Concurrency,
 			},
 		)
 		klog.V(2).Infof("prune task completing (name: %q)", p.Name())
@@ -78,8 +102,19 @@ func (p *PruneTask) Start(taskContext *taskrunner.TaskContext) {
 	}()
 }
 
-// Cancel is not supported by the PruneTask.
-func (p *PruneTask) Cancel(_ *taskrunner.TaskContext) {}
+// retry deletes an object using the RetryPolicy and Throttle.
+func (p *PruneTask) retry(fn func() error) error {
+	return p.RetryPolicy.Do(p.retries, p.Throttle.Wrap(p.retries, fn))
+}
+
+// Cancel stops retrying objects that failed with a transient error. The
+// remaining objects are still deleted, because the PruneTask can not be
+// interrupted.
+func (p *PruneTask) Cancel(_ *taskrunner.TaskContext) {
+	if p.cancelRetries != nil {
+		p.cancelRetries()
+	}
+}
 
 // StatusUpdate is not supported by the PruneTask.
 func (p *PruneTask) StatusUpdate(_ *taskrunner.TaskContext, _ object.ObjMetadata) {}
@@ -0,0 +1,123 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// RetryPolicy defines how the ApplyTask and PruneTask retry operations
+// on individual objects that failed with a transient error, before
+// reporting the object as failed.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times an operation is
+	// attempted, including the first attempt. Values less than 2 disable
+	// retries.
+	MaxAttempts int
+
+	// Backoff defines the delay between attempts. Steps is ignored and
+	// replaced by MaxAttempts.
+	Backoff wait.Backoff
+
+	// Retryable returns true if the operation should be retried after
+	// the passed error. Defaults to IsTransientError.
+	Retryable func(error) bool
+
+	// RespectRetryAfter defines whether the delay suggested by the server
+	// with a Retry-After header, e.g. when the request was throttled by
+	// API Priority and Fairness, is used if it is longer than the backoff.
+	RespectRetryAfter bool
+}
+
+// sleep is used to wait between attempts. It returns the error of the
+// context, if it is done before the duration elapses. Replaced by unit
+// tests.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// DefaultRetryPolicy returns a RetryPolicy that attempts each operation
+// up to 5 times, with an exponential backoff starting at 500ms.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 5,
+		Backoff: wait.Backoff{
+			Duration: 500 * time.Millisecond,
+			Factor:   2.0,
+			Jitter:   0.1,
+			Cap:      10 * time.Second,
+		},
+		Retryable:         IsTransientError,
+		RespectRetryAfter: true,
+	}
+}
+
+// Do calls fn until it succeeds, returns an error that is not
+// retryable, the maximum number of attempts has been reached, or the
+// context is done while waiting between attempts. The error from the last
+// attempt is returned. A nil RetryPolicy calls fn exactly once.
+func (p *RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	if p == nil || p.MaxAttempts < 2 {
+		return fn()
+	}
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsTransientError
+	}
+	backoff := p.Backoff
+	backoff.Steps = p.MaxAttempts
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !retryable(err) {
+			return err
+		}
+		delay := backoff.Step()
+		if p.RespectRetryAfter {
+			if seconds, ok := apierrors.SuggestsClientDelay(err); ok {
+				if retryAfter := time.Duration(seconds) * time.Second; retryAfter > delay {
+					delay = retryAfter
+				}
+			}
+		}
+		klog.V(4).Infof("retrying in %s after transient error (attempt: %d/%d): %v", delay, attempt, p.MaxAttempts, err)
+		if sleep(ctx, delay) != nil {
+			klog.V(4).Infof("not retrying after transient error: %v", ctx.Err())
+			return err
+		}
+	}
+}
+
+// IsTransientError returns true if the error is likely to be resolved by
+// trying again: throttling, server and webhook timeouts, unavailable
+// servers, and conflicts with concurrent updates. Conflicts with other
+// field managers of a server-side apply are not transient.
+func IsTransientError(err error) bool {
+	switch {
+	case apierrors.IsTooManyRequests(err),
+		apierrors.IsServerTimeout(err),
+		apierrors.IsTimeout(err),
+		apierrors.IsServiceUnavailable(err):
+		return true
+	case apierrors.IsConflict(err):
+		return len(fieldConflicts(err)) == 0
+	case apierrors.IsInternalError(err):
+		// Admission webhooks that time out are reported as internal errors.
+		return strings.Contains(err.Error(), "failed calling webhook")
+	default:
+		return false
+	}
+}
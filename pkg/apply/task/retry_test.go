@@ -0,0 +1,219 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var testGR = schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	transientErr := apierrors.NewTooManyRequests("slow down", 1)
+	permanentErr := apierrors.NewBadRequest("invalid")
+
+	testCases := map[string]struct {
+		policy           *RetryPolicy
+		errs             []error
+		expectedAttempts int
+		expectedErr      error
+	}{
+		"nil policy attempts once": {
+			policy:           nil,
+			errs:             []error{transientErr, nil},
+			expectedAttempts: 1,
+			expectedErr:      transientErr,
+		},
+		"transient errors are retried until success": {
+			policy:           &RetryPolicy{MaxAttempts: 3},
+			errs:             []error{transientErr, transientErr, nil},
+			expectedAttempts: 3,
+			expectedErr:      nil,
+		},
+		"transient errors are retried up to max attempts": {
+			policy:           &RetryPolicy{MaxAttempts: 2},
+			errs:             []error{transientErr, transientErr, nil},
+			expectedAttempts: 2,
+			expectedErr:      transientErr,
+		},
+		"permanent errors are not retried": {
+			policy:           &RetryPolicy{MaxAttempts: 3},
+			errs:             []error{permanentErr, nil},
+			expectedAttempts: 1,
+			expectedErr:      permanentErr,
+		},
+		"custom classifier": {
+			policy: &RetryPolicy{
+				MaxAttempts: 3,
+				Retryable:   apierrors.IsBadRequest,
+			},
+			errs:             []error{permanentErr, nil},
+			expectedAttempts: 2,
+			expectedErr:      nil,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			if tc.policy != nil {
+				// Don't sleep between attempts
+				tc.policy.Backoff = wait.Backoff{}
+			}
+			attempts := 0
+			err := tc.policy.Do(context.TODO(), func() error {
+				err := tc.errs[attempts]
+				attempts++
+				return err
+			})
+			assert.Equal(t, tc.expectedAttempts, attempts)
+			assert.Equal(t, tc.expectedErr, err)
+		})
+	}
+}
+
+func TestRetryPolicy_Do_RetryAfter(t *testing.T) {
+	throttledErr := apierrors.NewTooManyRequests("slow down", 3)
+
+	testCases := map[string]struct {
+		respectRetryAfter bool
+		expectedDelays    []time.Duration
+	}{
+		"retry-after is respected": {
+			respectRetryAfter: true,
+			expectedDelays:    []time.Duration{3 * time.Second, 4 * time.Second},
+		},
+		"retry-after is ignored": {
+			respectRetryAfter: false,
+			expectedDelays:    []time.Duration{time.Second, 4 * time.Second},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			var delays []time.Duration
+			oldSleep := sleep
+			sleep = func(_ context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			}
+			defer func() { sleep = oldSleep }()
+
+			policy := &RetryPolicy{
+				MaxAttempts: 3,
+				Backoff: wait.Backoff{
+					Duration: time.Second,
+					Factor:   4,
+				},
+				RespectRetryAfter: tc.respectRetryAfter,
+			}
+			attempts := 0
+			err := policy.Do(context.TODO(), func() error {
+				attempts++
+				return throttledErr
+			})
+			assert.Equal(t, throttledErr, err)
+			assert.Equal(t, 3, attempts)
+			assert.Equal(t, tc.expectedDelays, delays)
+		})
+	}
+}
+
+func TestRetryPolicy_Do_Cancel(t *testing.T) {
+	transientErr := apierrors.NewTooManyRequests("slow down", 1)
+	policy := &RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     wait.Backoff{Duration: time.Hour},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	done := make(chan error)
+	go func() {
+		done <- policy.Do(ctx, func() error {
+			attempts++
+			return transientErr
+		})
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.Equal(t, transientErr, err)
+		assert.Equal(t, 1, attempts)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the retries to stop")
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"too many requests": {
+			err:      apierrors.NewTooManyRequests("slow down", 1),
+			expected: true,
+		},
+		"server timeout": {
+			err:      apierrors.NewServerTimeout(testGR, "patch", 1),
+			expected: true,
+		},
+		"timeout": {
+			err:      apierrors.NewTimeoutError("timed out", 1),
+			expected: true,
+		},
+		"service unavailable": {
+			err:      apierrors.NewServiceUnavailable("unavailable"),
+			expected: true,
+		},
+		"conflict": {
+			err:      apierrors.NewConflict(testGR, "foo", errors.New("modified")),
+			expected: true,
+		},
+		"wrapped conflict": {
+			err:      fmt.Errorf("apply failed: %w", apierrors.NewConflict(testGR, "foo", errors.New("modified"))),
+			expected: true,
+		},
+		"field manager conflict": {
+			err: conflictError(metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldManagerConflict,
+				Message: `conflict with "kubectl-edit" using apps/v1`,
+				Field:   ".spec.replicas",
+			}),
+			expected: false,
+		},
+		"webhook failure": {
+			err: apierrors.NewInternalError(errors.New(
+				`failed calling webhook "validate.example.com": context deadline exceeded`)),
+			expected: true,
+		},
+		"other internal error": {
+			err:      apierrors.NewInternalError(errors.New("boom")),
+			expected: false,
+		},
+		"not found": {
+			err:      apierrors.NewNotFound(testGR, "foo"),
+			expected: false,
+		},
+		"non-api error": {
+			err:      errors.New("boom"),
+			expected: false,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsTransientError(tc.err))
+		})
+	}
+}
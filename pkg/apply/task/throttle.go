@@ -0,0 +1,95 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/time/rate"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+// minQPSFactor is the fraction of the configured QPS the Throttle slows
+// down to at most.
+const minQPSFactor = 0.1
+
+// Throttle limits the rate of apply and delete operations on individual
+// objects, on top of any throttling by the REST client. The rate is halved
+// every time the server responds with 429 Too Many Requests, and gradually
+// restored after successful operations. A Throttle is safe for concurrent
+// use and may be shared by multiple tasks.
+type Throttle struct {
+	mu      sync.Mutex
+	qps     float64
+	current float64
+	limiter *rate.Limiter
+}
+
+// NewThrottle returns a Throttle that allows up to qps operations per
+// second, with bursts of up to burst operations. Returns nil, which
+// disables throttling, if qps is not positive.
+func NewThrottle(qps float32, burst int) *Throttle {
+	if qps <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &Throttle{
+		qps:     float64(qps),
+		current: float64(qps),
+		limiter: rate.NewLimiter(rate.Limit(qps), burst),
+	}
+}
+
+// QPS returns the current rate limit, in operations per second.
+func (t *Throttle) QPS() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.current
+}
+
+// Wrap returns a function that waits for the throttle before calling fn,
+// and adjusts the rate based on the returned error. The wait is interrupted
+// with the error of the context, if it is done. A nil Throttle returns fn
+// unchanged.
+func (t *Throttle) Wrap(ctx context.Context, fn func() error) func() error {
+	if t == nil {
+		return fn
+	}
+	return func() error {
+		if err := t.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		err := fn()
+		t.observe(err)
+		return err
+	}
+}
+
+// observe adjusts the rate based on the result of an operation.
+func (t *Throttle) observe(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	next := t.current
+	switch {
+	case apierrors.IsTooManyRequests(err):
+		next = t.current / 2
+		if min := t.qps * minQPSFactor; next < min {
+			next = min
+		}
+	case err == nil:
+		next = t.current + t.qps*minQPSFactor
+		if next > t.qps {
+			next = t.qps
+		}
+	}
+	if next != t.current {
+		klog.V(4).Infof("adjusting throttle (qps: %.2f -> %.2f)", t.current, next)
+		t.current = next
+		t.limiter.SetLimit(rate.Limit(next))
+	}
+}
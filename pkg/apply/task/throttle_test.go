@@ -0,0 +1,97 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestNewThrottle_Disabled(t *testing.T) {
+	throttle := NewThrottle(0, 10)
+	assert.Nil(t, throttle)
+
+	calls := 0
+	err := throttle.Wrap(context.TODO(), func() error {
+		calls++
+		return nil
+	})()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestThrottle_Wrap(t *testing.T) {
+	throttledErr := apierrors.NewTooManyRequests("slow down", 1)
+	permanentErr := apierrors.NewBadRequest("invalid")
+
+	testCases := map[string]struct {
+		errs        []error
+		expectedQPS float64
+	}{
+		"success keeps the rate": {
+			errs:        []error{nil, nil},
+			expectedQPS: 1000,
+		},
+		"throttled requests halve the rate": {
+			errs:        []error{throttledErr, throttledErr},
+			expectedQPS: 250,
+		},
+		"rate is not reduced below the minimum": {
+			errs:        []error{throttledErr, throttledErr, throttledErr, throttledErr, throttledErr},
+			expectedQPS: 100,
+		},
+		"successes restore the rate": {
+			errs:        []error{throttledErr, nil, nil},
+			expectedQPS: 700,
+		},
+		"other errors keep the rate": {
+			errs:        []error{throttledErr, permanentErr, errors.New("unexpected")},
+			expectedQPS: 500,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			throttle := NewThrottle(1000, 10)
+			for _, expectedErr := range tc.errs {
+				err := throttle.Wrap(context.TODO(), func() error {
+					return expectedErr
+				})()
+				assert.Equal(t, expectedErr, err)
+			}
+			assert.InDelta(t, tc.expectedQPS, throttle.QPS(), 0.001)
+		})
+	}
+}
+
+func TestThrottle_Wrap_Cancel(t *testing.T) {
+	throttle := NewThrottle(0.001, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	fn := throttle.Wrap(ctx, func() error {
+		calls++
+		return nil
+	})
+	// The first call uses the burst, the second waits for the limiter.
+	assert.NoError(t, fn())
+
+	done := make(chan error)
+	go func() {
+		done <- fn()
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the throttle to stop")
+	}
+}
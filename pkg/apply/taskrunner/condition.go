@@ -4,8 +4,10 @@
 package taskrunner
 
 import (
+	"k8s.io/klog/v2"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/readycondition"
 )
 
 // Condition is a type that defines the types of conditions
@@ -21,6 +23,10 @@ const (
 	// has reached the NotFound status, i.e. they are all deleted
 	// from the cluster.
 	AllNotFound Condition = "AllNotFound"
+
+	// AllExist Condition means all the provided resources exist in the
+	// cluster, regardless of whether they are reconciled.
+	AllExist Condition = "AllExist"
 )
 
 // Meets returns true if the provided status meets the condition and
@@ -31,6 +37,8 @@ func (c Condition) Meets(s status.Status) bool {
 		return s == status.CurrentStatus
 	case AllNotFound:
 		return s == status.NotFoundStatus
+	case AllExist:
+		return s != status.NotFoundStatus && s != status.UnknownStatus
 	default:
 		return false
 	}
@@ -45,6 +53,9 @@ func conditionMet(taskContext *TaskContext, ids object.ObjMetadataSet, c Conditi
 		return allMatchStatus(taskContext, ids, status.CurrentStatus)
 	case AllNotFound:
 		return allMatchStatus(taskContext, ids, status.NotFoundStatus)
+	case AllExist:
+		return noneMatchStatus(taskContext, ids, status.NotFoundStatus) &&
+			noneMatchStatus(taskContext, ids, status.UnknownStatus)
 	default:
 		return noneMatchStatus(taskContext, ids, status.UnknownStatus)
 	}
@@ -93,3 +104,24 @@ func noneMatchStatus(taskContext *TaskContext, ids object.ObjMetadataSet, s stat
 	}
 	return true
 }
+
+// readyConditionMet checks whether the ready-condition expression holds for
+// the resource in the cache. Resources with older generations, or that do
+// not exist, are non-matches.
+func readyConditionMet(taskContext *TaskContext, id object.ObjMetadata, expr *readycondition.Expression) bool {
+	cached := taskContext.ResourceCache().Get(id)
+	if cached.Resource == nil {
+		return false
+	}
+	applyGen, _ := taskContext.InventoryManager().AppliedGeneration(id) // generation at apply time
+	if cached.Resource.GetGeneration() < applyGen {
+		// cache too old
+		return false
+	}
+	ready, err := expr.Evaluate(cached.Resource)
+	if err != nil {
+		klog.V(3).Infof("ready-condition errored (object: %s, expression: %q): %v", id, expr, err)
+		return false
+	}
+	return ready
+}
@@ -4,6 +4,10 @@
 package taskrunner
 
 import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cli-utils/pkg/apply/cache"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
@@ -21,7 +25,11 @@ func NewTaskContext(eventChannel chan event.Event, resourceCache cache.ResourceC
 		inventoryManager: inventory.NewManager(),
 		abandonedObjects: make(map[object.ObjMetadata]struct{}),
 		invalidObjects:   make(map[object.ObjMetadata]struct{}),
+		skipWaitObjects:  make(map[object.ObjMetadata]struct{}),
+		readyExternal:    make(map[object.ObjMetadata]struct{}),
 		graph:            graph.New(),
+		tracer:           otel.Tracer(TracerName),
+		ctx:              context.Background(),
 	}
 }
 
@@ -34,7 +42,14 @@ type TaskContext struct {
 	inventoryManager *inventory.Manager
 	abandonedObjects map[object.ObjMetadata]struct{}
 	invalidObjects   map[object.ObjMetadata]struct{}
+	skipWaitObjects  map[object.ObjMetadata]struct{}
+	readyExternal    map[object.ObjMetadata]struct{}
 	graph            *graph.Graph
+	tracer           trace.Tracer
+	// ctx carries the span of the running task. It is not cancelled with
+	// the context of the run, because tasks can not be interrupted.
+	ctx      context.Context
+	taskSpan trace.Span
 }
 
 func (tc *TaskContext) TaskChannel() chan TaskResult {
@@ -61,6 +76,23 @@ func (tc *TaskContext) SetGraph(g *graph.Graph) {
 	tc.graph = g
 }
 
+// Tracer returns the tracer of the spans of the runner, the tasks and the
+// objects. Defaults to the tracer of the global TracerProvider.
+func (tc *TaskContext) Tracer() trace.Tracer {
+	return tc.tracer
+}
+
+// SetTracer sets the tracer of the spans of the runner, the tasks and the
+// objects.
+func (tc *TaskContext) SetTracer(tracer trace.Tracer) {
+	tc.tracer = tracer
+}
+
+// Context returns the context of the running task, with its span.
+func (tc *TaskContext) Context() context.Context {
+	return tc.ctx
+}
+
 // SendEvent sends an event on the event channel
 func (tc *TaskContext) SendEvent(e event.Event) {
 	klog.V(3).Infof("Sending event: %v", e)
@@ -98,3 +130,28 @@ func (tc *TaskContext) AddInvalidObject(id object.ObjMetadata) {
 func (tc *TaskContext) InvalidObjects() object.ObjMetadataSet {
 	return object.ObjMetadataSetFromMap(tc.invalidObjects)
 }
+
+// IsSkipWaitObject returns true if the object is not waited for, because of
+// the reconcile annotation
+func (tc *TaskContext) IsSkipWaitObject(id object.ObjMetadata) bool {
+	_, found := tc.skipWaitObjects[id]
+	return found
+}
+
+// AddSkipWaitObject registers that the object is not waited for
+func (tc *TaskContext) AddSkipWaitObject(id object.ObjMetadata) {
+	tc.skipWaitObjects[id] = struct{}{}
+}
+
+// IsReadyExternalObject returns true if the object is an external
+// dependency, which is not managed by the inventory, and was found ready
+// by an ExternalWaitTask.
+func (tc *TaskContext) IsReadyExternalObject(id object.ObjMetadata) bool {
+	_, found := tc.readyExternal[id]
+	return found
+}
+
+// AddReadyExternalObject registers that the external dependency is ready
+func (tc *TaskContext) AddReadyExternalObject(id object.ObjMetadata) {
+	tc.readyExternal[id] = struct{}{}
+}
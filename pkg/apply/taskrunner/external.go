@@ -0,0 +1,144 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package taskrunner
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// ExternalWaitTask is an implementation of the Task interface that waits
+// for external dependencies, i.e. objects referenced by depends-on
+// annotations that are not managed by the inventory, until they meet the
+// condition or the timeout expires.
+// Unlike the WaitTask, it does not update the InventoryManager. The objects
+// that met the condition are registered in the TaskContext instead, so the
+// objects that depend on the others can be skipped.
+type ExternalWaitTask struct {
+	// TaskName allows providing a name for the task.
+	TaskName string
+	// Ids are the external dependencies to wait for.
+	Ids object.ObjMetadataSet
+	// Condition defines the status we want all resources to reach, either
+	// AllCurrent or AllExist.
+	Condition Condition
+	// Timeout defines how long we are willing to wait for the condition
+	// to be met. Zero means no timeout.
+	Timeout time.Duration
+
+	// cancelFunc is a function that will cancel the timeout timer
+	// on the task.
+	cancelFunc context.CancelFunc
+	// pending is the set of resources that we are still waiting for.
+	pending object.ObjMetadataSet
+	// mu protects the pending ObjMetadataSet
+	mu sync.Mutex
+}
+
+var _ Task = &ExternalWaitTask{}
+
+func (w *ExternalWaitTask) Name() string {
+	return w.TaskName
+}
+
+func (w *ExternalWaitTask) Action() event.ResourceAction {
+	return event.WaitAction
+}
+
+func (w *ExternalWaitTask) Identifiers() object.ObjMetadataSet {
+	return w.Ids
+}
+
+// Start sends a reconciled or pending event for each object and sets up
+// the timeout timer.
+func (w *ExternalWaitTask) Start(taskContext *TaskContext) {
+	klog.V(2).Infof("external wait task starting (name: %q, objects: %d)",
+		w.Name(), len(w.Ids))
+
+	ctx := context.Background()
+	if w.Timeout > 0 {
+		ctx, w.cancelFunc = context.WithTimeout(ctx, w.Timeout)
+	} else {
+		ctx, w.cancelFunc = context.WithCancel(ctx)
+	}
+
+	w.mu.Lock()
+	pending := object.ObjMetadataSet{}
+	for _, id := range w.Ids {
+		if conditionMet(taskContext, object.ObjMetadataSet{id}, w.Condition) {
+			taskContext.AddReadyExternalObject(id)
+			w.sendEvent(taskContext, id, event.ReconcileSuccessful)
+			continue
+		}
+		pending = append(pending, id)
+		w.sendEvent(taskContext, id, event.ReconcilePending)
+	}
+	w.pending = pending
+	if len(pending) == 0 {
+		klog.V(3).Infof("all external dependencies ready (name: %q)", w.TaskName)
+		w.cancelFunc()
+	}
+	w.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		klog.V(2).Infof("external wait task completing (name: %q): %v", w.TaskName, ctx.Err())
+		if ctx.Err() == context.DeadlineExceeded {
+			w.sendTimeoutEvents(taskContext)
+		}
+		taskContext.TaskChannel() <- TaskResult{}
+	}()
+}
+
+func (w *ExternalWaitTask) sendEvent(taskContext *TaskContext, id object.ObjMetadata, status event.WaitEventStatus) {
+	taskContext.SendEvent(event.Event{
+		Type: event.WaitType,
+		WaitEvent: event.WaitEvent{
+			GroupName:  w.Name(),
+			Identifier: id,
+			Status:     status,
+		},
+	})
+}
+
+// sendTimeoutEvents sends a timeout event for every remaining pending object.
+func (w *ExternalWaitTask) sendTimeoutEvents(taskContext *TaskContext) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, id := range w.pending {
+		w.sendEvent(taskContext, id, event.ReconcileTimeout)
+	}
+}
+
+// Cancel exits early with a timeout error
+func (w *ExternalWaitTask) Cancel(_ *TaskContext) {
+	w.cancelFunc()
+}
+
+// StatusUpdate registers pending objects that meet the condition as ready
+// and sends WaitEvents. Ready objects stay ready, even if their status
+// changes again. If no objects are pending anymore, cancelFunc is called.
+func (w *ExternalWaitTask) StatusUpdate(taskContext *TaskContext, id object.ObjMetadata) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.pending.Contains(id) ||
+		!conditionMet(taskContext, object.ObjMetadataSet{id}, w.Condition) {
+		return
+	}
+	taskContext.AddReadyExternalObject(id)
+	w.pending = w.pending.Remove(id)
+	w.sendEvent(taskContext, id, event.ReconcileSuccessful)
+
+	if len(w.pending) == 0 {
+		klog.V(3).Infof("all external dependencies ready (name: %q)", w.TaskName)
+		w.cancelFunc()
+	}
+}
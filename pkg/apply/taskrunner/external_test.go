@@ -0,0 +1,114 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package taskrunner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/cli-utils/pkg/apply/cache"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestExternalWaitTask(t *testing.T) {
+	testDeployment1ID := testutil.ToIdentifier(t, testDeployment1YAML)
+	testDeployment1 := testutil.Unstructured(t, testDeployment1YAML)
+	testDeployment2ID := testutil.ToIdentifier(t, testDeployment2YAML)
+	testDeployment2 := testutil.Unstructured(t, testDeployment2YAML)
+	testDeployment3ID := testutil.ToIdentifier(t, testDeployment3YAML)
+	taskName := "wait-external-0"
+
+	testCases := map[string]struct {
+		condition      Condition
+		expectedReady  object.ObjMetadataSet
+		expectedEvents []event.WaitEventStatus
+	}{
+		"current": {
+			condition:     AllCurrent,
+			expectedReady: object.ObjMetadataSet{testDeployment1ID, testDeployment2ID},
+			expectedEvents: []event.WaitEventStatus{
+				event.ReconcileSuccessful, // deployment1 current
+				event.ReconcilePending,    // deployment2 in progress
+				event.ReconcilePending,    // deployment3 not found
+				event.ReconcileSuccessful, // deployment2 current
+				event.ReconcileTimeout,    // deployment3 not found
+			},
+		},
+		"exist": {
+			condition:     AllExist,
+			expectedReady: object.ObjMetadataSet{testDeployment1ID, testDeployment2ID},
+			expectedEvents: []event.WaitEventStatus{
+				event.ReconcileSuccessful, // deployment1 current
+				event.ReconcileSuccessful, // deployment2 in progress
+				event.ReconcilePending,    // deployment3 not found
+				event.ReconcileTimeout,    // deployment3 not found
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			task := &ExternalWaitTask{
+				TaskName:  taskName,
+				Ids:       object.ObjMetadataSet{testDeployment1ID, testDeployment2ID, testDeployment3ID},
+				Condition: tc.condition,
+				Timeout:   time.Second,
+			}
+
+			eventChannel := make(chan event.Event)
+			resourceCache := cache.NewResourceCacheMap()
+			taskContext := NewTaskContext(eventChannel, resourceCache)
+			defer close(eventChannel)
+
+			resourceCache.Put(testDeployment1ID, cache.ResourceStatus{
+				Resource: testDeployment1,
+				Status:   status.CurrentStatus,
+			})
+			resourceCache.Put(testDeployment2ID, cache.ResourceStatus{
+				Resource: testDeployment2,
+				Status:   status.InProgressStatus,
+			})
+			resourceCache.Put(testDeployment3ID, cache.ResourceStatus{
+				Status: status.NotFoundStatus,
+			})
+
+			go func() {
+				task.Start(taskContext)
+				resourceCache.Put(testDeployment2ID, cache.ResourceStatus{
+					Resource: testDeployment2,
+					Status:   status.CurrentStatus,
+				})
+				task.StatusUpdate(taskContext, testDeployment2ID)
+			}()
+
+			timer := time.NewTimer(5 * time.Second)
+			var receivedEvents []event.WaitEventStatus
+		loop:
+			for {
+				select {
+				case e := <-taskContext.EventChannel():
+					assert.Equal(t, taskName, e.WaitEvent.GroupName)
+					receivedEvents = append(receivedEvents, e.WaitEvent.Status)
+				case res := <-taskContext.TaskChannel():
+					timer.Stop()
+					assert.NoError(t, res.Err)
+					break loop
+				case <-timer.C:
+					t.Fatalf("timed out waiting for TaskResult")
+				}
+			}
+
+			assert.Equal(t, tc.expectedEvents, receivedEvents)
+			for _, id := range task.Ids {
+				assert.Equal(t, tc.expectedReady.Contains(id), taskContext.IsReadyExternalObject(id), id)
+			}
+			// The inventory is not updated.
+			assert.Empty(t, taskContext.InventoryManager().Inventory().Status.Objects)
+		})
+	}
+}
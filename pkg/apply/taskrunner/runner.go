@@ -7,12 +7,14 @@ import (
 	"context"
 	"fmt"
 
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cli-utils/pkg/apply/cache"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/reason"
 )
 
 // NewTaskStatusRunner returns a new TaskStatusRunner.
@@ -35,6 +37,10 @@ type TaskStatusRunner struct {
 // the statusPoller.
 type Options struct {
 	EmitStatusEvents bool
+	// OnTaskCompleted, if set, is called after each task completes
+	// successfully, before the next task is started. It is not called for
+	// tasks interrupted by an abort of the run.
+	OnTaskCompleted func(Task)
 }
 
 // Run executes the tasks in the taskqueue, with the statusPoller running in the
@@ -53,6 +59,10 @@ func (tsr *TaskStatusRunner) Run(
 	taskQueue chan Task,
 	opts Options,
 ) error {
+	// The spans of the tasks are children of the span of the run.
+	_, span := taskContext.Tracer().Start(ctx, "TaskStatusRunner.Run")
+	spanCtx := trace.ContextWithSpan(context.Background(), span)
+
 	// Give the poller its own context and run it in the background.
 	// If taskStatusRunner.Run is cancelled, baseRunner.run will exit early,
 	// causing the poller to be cancelled.
@@ -70,6 +80,7 @@ func (tsr *TaskStatusRunner) Run(
 		for statusEvent := range statusChannel {
 			klog.V(7).Infof("Runner ignored status event: %v", statusEvent)
 		}
+		EndSpan(span, err)
 		return err
 	}
 
@@ -117,8 +128,8 @@ func (tsr *TaskStatusRunner) Run(
 			// the statusChannel will be closed soon.
 			if statusEvent.Type == pollevent.ErrorEvent {
 				abort = true
-				abortReason = fmt.Errorf("polling for status failed: %v",
-					statusEvent.Error)
+				abortReason = reason.New(reason.StatusPollFailed, true,
+					fmt.Errorf("polling for status failed: %v", statusEvent.Error))
 				if currentTask != nil {
 					currentTask.Cancel(taskContext)
 				} else {
@@ -132,7 +143,7 @@ func (tsr *TaskStatusRunner) Run(
 			// Tasks may commence!
 			if statusEvent.Type == pollevent.SyncEvent {
 				// Find and start the first task in the queue.
-				currentTask, done = nextTask(taskQueue, taskContext)
+				currentTask, done = nextTask(spanCtx, taskQueue, taskContext)
 				if done {
 					return complete(nil)
 				}
@@ -178,6 +189,7 @@ func (tsr *TaskStatusRunner) Run(
 		// finish, we exit.
 		// If everything is ok, we fetch and start the next task.
 		case msg := <-taskContext.TaskChannel():
+			taskContext.endTaskSpan(msg.Err)
 			taskContext.SendEvent(event.Event{
 				Type: event.ActionGroupType,
 				ActionGroupEvent: event.ActionGroupEvent{
@@ -194,7 +206,10 @@ func (tsr *TaskStatusRunner) Run(
 			if abort {
 				return complete(abortReason)
 			}
-			currentTask, done = nextTask(taskQueue, taskContext)
+			if opts.OnTaskCompleted != nil {
+				opts.OnTaskCompleted(currentTask)
+			}
+			currentTask, done = nextTask(spanCtx, taskQueue, taskContext)
 			// If there are no more tasks, we are done. So just
 			// return.
 			if done {
@@ -219,9 +234,9 @@ func (tsr *TaskStatusRunner) Run(
 }
 
 // nextTask fetches the latest task from the taskQueue and
-// starts it. If the taskQueue is empty, it the second
-// return value will be true.
-func nextTask(taskQueue chan Task, taskContext *TaskContext) (Task, bool) {
+// starts it, with a span that is a child of the span in the context.
+// If the taskQueue is empty, it the second return value will be true.
+func nextTask(ctx context.Context, taskQueue chan Task, taskContext *TaskContext) (Task, bool) {
 	var tsk Task
 	select {
 	// If there is any tasks left in the queue, this
@@ -242,6 +257,7 @@ func nextTask(taskQueue chan Task, taskContext *TaskContext) (Task, bool) {
 		},
 	})
 
+	taskContext.startTaskSpan(ctx, tsk)
 	tsk.Start(taskContext)
 
 	return tsk, false
@@ -10,16 +10,25 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/readycondition"
 )
 
 var (
 	crdGK = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+
+	// restMapperPollInterval and restMapperPollTimeout define how often and
+	// how long the RESTMapper is reset after CRDs have been reconciled,
+	// until discovery serves the versions of their custom resources.
+	restMapperPollInterval = time.Second
+	restMapperPollTimeout  = 30 * time.Second
 )
 
 // Task is the interface that must be implemented by
@@ -62,16 +71,45 @@ type WaitTask struct {
 	// Timeout defines how long we are willing to wait for the condition
 	// to be met.
 	Timeout time.Duration
+	// ObjectTimeouts overrides the Timeout for individual objects. Objects
+	// that exceed their own timeout are marked as timed out, while the
+	// remaining objects continue to be waited on.
+	ObjectTimeouts map[object.ObjMetadata]time.Duration
+	// ReadyConditions overrides the computed status of individual objects
+	// when waiting for the AllCurrent condition. The objects are reconciled
+	// once their expression holds and are never considered failed.
+	ReadyConditions map[object.ObjMetadata]*readycondition.Expression
+	// CRDVersions are the API versions of the custom resources defined by
+	// the CRDs, by CRD, that are applied by later tasks. A CRD is only
+	// reconciled once it serves all of its versions, and the RESTMapper is
+	// reset until discovery maps them.
+	CRDVersions map[object.ObjMetadata][]string
+	// SkipWait are the objects that are not waited for, because of the
+	// reconcile annotation. They are marked as skipped once applied, without
+	// preventing their dependents from being applied.
+	SkipWait object.ObjMetadataSet
 	// Mapper is the RESTMapper to update after CRDs have been reconciled
 	Mapper meta.RESTMapper
 	// cancelFunc is a function that will cancel the timeout timer
 	// on the task.
 	cancelFunc context.CancelFunc
+	// cancelled is done when the task is cancelled by the task runner,
+	// unlike the context cancelled by cancelFunc, which also completes
+	// the task.
+	cancelled    context.Context
+	cancelRunner context.CancelFunc
 	// pending is the set of resources that we are still waiting for.
 	pending object.ObjMetadataSet
 	// failed is the set of resources that we are waiting for, but is considered
 	// failed, i.e. unlikely to successfully reconcile.
 	failed object.ObjMetadataSet
+	// timedOut is the set of resources that exceeded their own timeout.
+	timedOut object.ObjMetadataSet
+	// timers are the timers for the objects with their own timeout.
+	timers []*time.Timer
+	// done is true once the task has completed, after which the timers
+	// must not update the pending set.
+	done bool
 	// mu protects the pending ObjMetadataSet
 	mu sync.RWMutex
 }
@@ -98,13 +136,16 @@ func (w *WaitTask) Start(taskContext *TaskContext) {
 	ctx := context.Background()
 
 	// use a context wrapper to handle complete/cancel/timeout
-	if w.Timeout > 0 {
-		ctx, w.cancelFunc = context.WithTimeout(ctx, w.Timeout)
+	taskTimeout := w.taskTimeout()
+	if taskTimeout > 0 {
+		ctx, w.cancelFunc = context.WithTimeout(ctx, taskTimeout)
 	} else {
 		ctx, w.cancelFunc = context.WithCancel(ctx)
 	}
+	w.cancelled, w.cancelRunner = context.WithCancel(context.Background())
 
 	w.startInner(taskContext)
+	w.startObjectTimers(taskContext, taskTimeout)
 
 	// A goroutine to handle ending the WaitTask.
 	go func() {
@@ -115,6 +156,8 @@ func (w *WaitTask) Start(taskContext *TaskContext) {
 
 		klog.V(2).Infof("wait task completing (name: %q,): %v", w.TaskName, err)
 
+		w.stopObjectTimers()
+
 		switch err {
 		case context.Canceled:
 			// happy path - cancelled or completed (not considered an error)
@@ -124,7 +167,8 @@ func (w *WaitTask) Start(taskContext *TaskContext) {
 		}
 
 		// Update RESTMapper to pick up new custom resource types
-		w.updateRESTMapper(taskContext)
+		w.updateRESTMapper(w.cancelled, taskContext)
+		w.cancelRunner()
 
 		// Done here. signal completion to the task runner
 		taskContext.TaskChannel() <- TaskResult{}
@@ -161,6 +205,14 @@ func (w *WaitTask) startInner(taskContext *TaskContext) {
 				klog.Errorf("Failed to mark object as skipped reconcile: %v", err)
 			}
 			w.sendEvent(taskContext, id, event.ReconcileSkipped)
+		case w.SkipWait.Contains(id):
+			err := taskContext.InventoryManager().SetSkippedReconcile(id)
+			if err != nil {
+				// Object never applied or deleted!
+				klog.Errorf("Failed to mark object as skipped reconcile: %v", err)
+			}
+			taskContext.AddSkipWaitObject(id)
+			w.sendEvent(taskContext, id, event.ReconcileSkippedByAnnotation)
 		case w.changedUID(taskContext, id):
 			// replaced
 			w.handleChangedUID(taskContext, id)
@@ -192,6 +244,96 @@ func (w *WaitTask) startInner(taskContext *TaskContext) {
 	}
 }
 
+// objectTimeout returns how long to wait for the object with the given id.
+func (w *WaitTask) objectTimeout(id object.ObjMetadata) time.Duration {
+	if timeout, found := w.ObjectTimeouts[id]; found {
+		return timeout
+	}
+	return w.Timeout
+}
+
+// taskTimeout returns how long to wait for all the objects, which is the
+// longest timeout of any object, or zero if any object has no timeout.
+func (w *WaitTask) taskTimeout() time.Duration {
+	if len(w.ObjectTimeouts) == 0 {
+		return w.Timeout
+	}
+	var taskTimeout time.Duration
+	for _, id := range w.Ids {
+		timeout := w.objectTimeout(id)
+		if timeout <= 0 {
+			return 0
+		}
+		if timeout > taskTimeout {
+			taskTimeout = timeout
+		}
+	}
+	return taskTimeout
+}
+
+// startObjectTimers starts a timer for each pending object with a timeout
+// shorter than the timeout of the task.
+// The pending set is write locked during execution of startObjectTimers.
+func (w *WaitTask) startObjectTimers(taskContext *TaskContext, taskTimeout time.Duration) {
+	if len(w.ObjectTimeouts) == 0 {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, id := range w.pending {
+		timeout := w.objectTimeout(id)
+		if timeout <= 0 || (taskTimeout > 0 && timeout >= taskTimeout) {
+			// Handled by the task timeout
+			continue
+		}
+		id := id
+		w.timers = append(w.timers, time.AfterFunc(timeout, func() {
+			w.handleObjectTimeout(taskContext, id)
+		}))
+	}
+}
+
+// stopObjectTimers stops the timers for objects with their own timeout.
+// The pending set is write locked during execution of stopObjectTimers.
+func (w *WaitTask) stopObjectTimers() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.done = true
+	for _, timer := range w.timers {
+		timer.Stop()
+	}
+	w.timers = nil
+}
+
+// handleObjectTimeout sends a timeout event for the object, if it is still
+// pending. If no objects are pending anymore, cancelFunc is called.
+// The pending set is write locked during execution of handleObjectTimeout.
+func (w *WaitTask) handleObjectTimeout(taskContext *TaskContext, id object.ObjMetadata) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.done || !w.pending.Contains(id) {
+		return
+	}
+	klog.V(3).Infof("object reconcile timed out (name: %q, object: %q)", w.TaskName, id)
+	err := taskContext.InventoryManager().SetTimeoutReconcile(id)
+	if err != nil {
+		// Object never applied or deleted!
+		klog.Errorf("Failed to mark object as timeout reconcile: %v", err)
+	}
+	w.pending = w.pending.Remove(id)
+	w.timedOut = append(w.timedOut, id)
+	w.sendEvent(taskContext, id, event.ReconcileTimeout)
+
+	if len(w.pending) == 0 {
+		// all reconciled or timed out, so exit
+		klog.V(3).Infof("all objects reconciled, skipped or timed out (name: %q)", w.TaskName)
+		w.cancelFunc()
+	}
+}
+
 // sendTimeoutEvents sends a timeout event for every remaining pending object
 // The pending set is read locked during execution of sendTimeoutEvents.
 func (w *WaitTask) sendTimeoutEvents(taskContext *TaskContext) {
@@ -211,7 +353,44 @@ func (w *WaitTask) sendTimeoutEvents(taskContext *TaskContext) {
 // reconciledByID checks whether the condition set in the task is currently met
 // for the specified object given the status of resource in the cache.
 func (w *WaitTask) reconciledByID(taskContext *TaskContext, id object.ObjMetadata) bool {
-	return conditionMet(taskContext, object.ObjMetadataSet{id}, w.Condition)
+	if expr, found := w.ReadyConditions[id]; found && w.Condition == AllCurrent {
+		return readyConditionMet(taskContext, id, expr)
+	}
+	if !conditionMet(taskContext, object.ObjMetadataSet{id}, w.Condition) {
+		return false
+	}
+	if versions, found := w.CRDVersions[id]; found && w.Condition == AllCurrent {
+		return versionsServed(taskContext.ResourceCache().Get(id).Resource, versions)
+	}
+	return true
+}
+
+// versionsServed returns true if the CRD serves all of the versions. The
+// version of CRDs with the deprecated spec.version field is always served.
+func versionsServed(crd *unstructured.Unstructured, versions []string) bool {
+	if crd == nil {
+		return false
+	}
+	specVersions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	served := make(map[string]bool, len(specVersions)+1)
+	if version, _, _ := unstructured.NestedString(crd.Object, "spec", "version"); version != "" {
+		served[version] = true
+	}
+	for _, v := range specVersions {
+		version, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(version, "name")
+		served[name], _, _ = unstructured.NestedBool(version, "served")
+	}
+	for _, version := range versions {
+		if !served[version] {
+			klog.V(3).Infof("CRD %s does not serve version %q yet", crd.GetName(), version)
+			return false
+		}
+	}
+	return true
 }
 
 // skipped returns true if the object failed or was skipped by a preceding
@@ -231,6 +410,9 @@ func (w *WaitTask) skipped(taskContext *TaskContext, id object.ObjMetadata) bool
 
 // failedByID returns true if the resource is failed.
 func (w *WaitTask) failedByID(taskContext *TaskContext, id object.ObjMetadata) bool {
+	if _, found := w.ReadyConditions[id]; found && w.Condition == AllCurrent {
+		return false
+	}
 	cached := taskContext.ResourceCache().Get(id)
 	return cached.Status == status.FailedStatus
 }
@@ -308,6 +490,7 @@ func (w *WaitTask) handleChangedUID(taskContext *TaskContext, id object.ObjMetad
 
 // Cancel exits early with a timeout error
 func (w *WaitTask) Cancel(_ *TaskContext) {
+	w.cancelRunner()
 	w.cancelFunc()
 }
 
@@ -354,9 +537,15 @@ func (w *WaitTask) StatusUpdate(taskContext *TaskContext, id object.ObjMetadata)
 	case !w.Ids.Contains(id):
 		// not in wait group - ignore
 		return
+	case w.timedOut.Contains(id):
+		// timed out - ignore
+		return
 	case w.skipped(taskContext, id):
 		// skipped - ignore
 		return
+	case w.SkipWait.Contains(id):
+		// not waited for - ignore
+		return
 	case w.failed.Contains(id):
 		// If a failed resource becomes current before other
 		// resources have completed/timed out, we consider it
@@ -410,10 +599,11 @@ func (w *WaitTask) StatusUpdate(taskContext *TaskContext, id object.ObjMetadata)
 }
 
 // updateRESTMapper resets the RESTMapper if CRDs were applied, so that new
-// resource types can be applied by subsequent tasks.
+// resource types can be applied by subsequent tasks. Waiting for discovery
+// is skipped if the context is cancelled.
 // TODO: find a way to add/remove mappers without resetting the entire mapper
 // Resetting the mapper requires all CRDs to be queried again.
-func (w *WaitTask) updateRESTMapper(taskContext *TaskContext) {
+func (w *WaitTask) updateRESTMapper(ctx context.Context, taskContext *TaskContext) {
 	foundCRD := false
 	for _, id := range w.Ids {
 		if id.GroupKind == crdGK && !w.skipped(taskContext, id) {
@@ -428,4 +618,41 @@ func (w *WaitTask) updateRESTMapper(taskContext *TaskContext) {
 
 	klog.V(3).Infof("Resetting RESTMapper")
 	meta.MaybeResetRESTMapper(w.Mapper)
+
+	// Discovery may lag behind the CRDs being established. Keep resetting
+	// the RESTMapper until it maps the custom resources applied next, so
+	// they don't fail with "no matches for kind".
+	var gvks []schema.GroupVersionKind
+	for _, id := range w.Ids {
+		versions, found := w.CRDVersions[id]
+		if !found || !taskContext.InventoryManager().IsSuccessfulReconcile(id) {
+			continue
+		}
+		crd := taskContext.ResourceCache().Get(id).Resource
+		gk, found := object.GetCRDGroupKind(crd)
+		if !found {
+			continue
+		}
+		for _, version := range versions {
+			gvks = append(gvks, gk.WithVersion(version))
+		}
+	}
+	if len(gvks) == 0 || ctx.Err() != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, restMapperPollTimeout)
+	defer cancel()
+	err := wait.PollImmediateUntilWithContext(ctx, restMapperPollInterval, func(context.Context) (bool, error) {
+		for _, gvk := range gvks {
+			if _, err := w.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+				klog.V(3).Infof("Resetting RESTMapper: %v", err)
+				meta.MaybeResetRESTMapper(w.Mapper)
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		klog.Warningf("RESTMapper did not discover the custom resources of the CRDs (name: %q): %v", w.TaskName, err)
+	}
 }
@@ -4,16 +4,22 @@
 package taskrunner
 
 import (
+	"math"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
 	"sigs.k8s.io/cli-utils/pkg/apply/cache"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/readycondition"
 	"sigs.k8s.io/cli-utils/pkg/testutil"
 )
 
@@ -425,6 +431,359 @@ loop:
 	testutil.AssertEqual(t, &expectedInventory, taskContext.InventoryManager().Inventory())
 }
 
+func TestWaitTask_ObjectTimeout(t *testing.T) {
+	testDeployment1ID := testutil.ToIdentifier(t, testDeployment1YAML)
+	testDeployment1 := testutil.Unstructured(t, testDeployment1YAML)
+	testDeployment2ID := testutil.ToIdentifier(t, testDeployment2YAML)
+	testDeployment2 := testutil.Unstructured(t, testDeployment2YAML)
+	ids := object.ObjMetadataSet{
+		testDeployment1ID,
+		testDeployment2ID,
+	}
+	taskName := "wait-object-timeout"
+	// No task timeout, but deployment1 has its own timeout
+	task := NewWaitTask(taskName, ids, AllCurrent,
+		0, testutil.NewFakeRESTMapper())
+	task.ObjectTimeouts = map[object.ObjMetadata]time.Duration{
+		testDeployment1ID: 100 * time.Millisecond,
+	}
+	assert.Equal(t, time.Duration(0), task.taskTimeout())
+
+	eventChannel := make(chan event.Event)
+	resourceCache := cache.NewResourceCacheMap()
+	taskContext := NewTaskContext(eventChannel, resourceCache)
+	defer close(eventChannel)
+
+	// Update metadata on successfully applied objects
+	testDeployment1.SetUID("a")
+	testDeployment1.SetGeneration(1)
+	testDeployment2.SetUID("b")
+	testDeployment2.SetGeneration(1)
+
+	// mark deployment 1 & 2 as apply succeeded
+	taskContext.InventoryManager().AddSuccessfulApply(testDeployment1ID,
+		testDeployment1.GetUID(), testDeployment1.GetGeneration())
+	taskContext.InventoryManager().AddSuccessfulApply(testDeployment2ID,
+		testDeployment2.GetUID(), testDeployment2.GetGeneration())
+
+	// run task async, to let the test collect events
+	go func() {
+		// start the task
+		task.Start(taskContext)
+
+		// wait for deployment1 to time out
+		time.Sleep(500 * time.Millisecond)
+
+		// mark deployment1 as Current, after it timed out
+		resourceCache.Put(testDeployment1ID, cache.ResourceStatus{
+			Resource: testDeployment1,
+			Status:   status.CurrentStatus,
+		})
+		// tell the WaitTask deployment1 has new status
+		task.StatusUpdate(taskContext, testDeployment1ID)
+
+		// mark deployment2 as Current
+		resourceCache.Put(testDeployment2ID, cache.ResourceStatus{
+			Resource: testDeployment2,
+			Status:   status.CurrentStatus,
+		})
+		// tell the WaitTask deployment2 has new status
+		task.StatusUpdate(taskContext, testDeployment2ID)
+	}()
+
+	// wait for task result
+	timer := time.NewTimer(5 * time.Second)
+	receivedEvents := []event.Event{}
+loop:
+	for {
+		select {
+		case e := <-taskContext.EventChannel():
+			receivedEvents = append(receivedEvents, e)
+		case res := <-taskContext.TaskChannel():
+			timer.Stop()
+			assert.NoError(t, res.Err)
+			break loop
+		case <-timer.C:
+			t.Fatalf("timed out waiting for TaskResult")
+		}
+	}
+
+	expectedEvents := []event.Event{
+		// deployment1 pending
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: testDeployment1ID,
+				Status:     event.ReconcilePending,
+			},
+		},
+		// deployment2 pending
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: testDeployment2ID,
+				Status:     event.ReconcilePending,
+			},
+		},
+		// deployment1 timeout, while deployment2 is still pending
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: testDeployment1ID,
+				Status:     event.ReconcileTimeout,
+			},
+		},
+		// deployment2 current
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: testDeployment2ID,
+				Status:     event.ReconcileSuccessful,
+			},
+		},
+	}
+	testutil.AssertEqual(t, expectedEvents, receivedEvents,
+		"Actual events (%d) do not match expected events (%d)",
+		len(receivedEvents), len(expectedEvents))
+
+	expectedInventory := actuation.Inventory{
+		Status: actuation.InventoryStatus{
+			Objects: []actuation.ObjectStatus{
+				{
+					ObjectReference: inventory.ObjectReferenceFromObjMetadata(testDeployment1ID),
+					Strategy:        actuation.ActuationStrategyApply,
+					Actuation:       actuation.ActuationSucceeded,
+					Reconcile:       actuation.ReconcileTimeout,
+					UID:             testDeployment1.GetUID(),
+					Generation:      testDeployment1.GetGeneration(),
+				},
+				{
+					ObjectReference: inventory.ObjectReferenceFromObjMetadata(testDeployment2ID),
+					Strategy:        actuation.ActuationStrategyApply,
+					Actuation:       actuation.ActuationSucceeded,
+					Reconcile:       actuation.ReconcileSucceeded,
+					UID:             testDeployment2.GetUID(),
+					Generation:      testDeployment2.GetGeneration(),
+				},
+			},
+		},
+	}
+	testutil.AssertEqual(t, &expectedInventory, taskContext.InventoryManager().Inventory())
+}
+
+func TestWaitTask_ReadyCondition(t *testing.T) {
+	testDeployment1ID := testutil.ToIdentifier(t, testDeployment1YAML)
+	testDeployment1 := testutil.Unstructured(t, testDeployment1YAML)
+	ids := object.ObjMetadataSet{
+		testDeployment1ID,
+	}
+	taskName := "wait-ready-condition"
+	task := NewWaitTask(taskName, ids, AllCurrent,
+		2*time.Second, testutil.NewFakeRESTMapper())
+	expr, err := readycondition.Parse("status.readyReplicas >= spec.replicas")
+	require.NoError(t, err)
+	task.ReadyConditions = map[object.ObjMetadata]*readycondition.Expression{
+		testDeployment1ID: expr,
+	}
+
+	eventChannel := make(chan event.Event)
+	resourceCache := cache.NewResourceCacheMap()
+	taskContext := NewTaskContext(eventChannel, resourceCache)
+	defer close(eventChannel)
+
+	// Update metadata on successfully applied objects
+	testDeployment1.SetUID("a")
+	testDeployment1.SetGeneration(1)
+	require.NoError(t, unstructured.SetNestedField(testDeployment1.Object, int64(2), "spec", "replicas"))
+
+	// mark deployment 1 as apply succeeded
+	taskContext.InventoryManager().AddSuccessfulApply(testDeployment1ID,
+		testDeployment1.GetUID(), testDeployment1.GetGeneration())
+
+	notReady := testDeployment1.DeepCopy()
+	require.NoError(t, unstructured.SetNestedField(notReady.Object, int64(1), "status", "readyReplicas"))
+	ready := testDeployment1.DeepCopy()
+	require.NoError(t, unstructured.SetNestedField(ready.Object, int64(2), "status", "readyReplicas"))
+
+	// run task async, to let the test collect events
+	go func() {
+		// start the task
+		task.Start(taskContext)
+
+		// mark deployment1 as Current, without enough ready replicas
+		resourceCache.Put(testDeployment1ID, cache.ResourceStatus{
+			Resource: notReady,
+			Status:   status.CurrentStatus,
+		})
+		task.StatusUpdate(taskContext, testDeployment1ID)
+
+		// mark deployment1 as Failed, with enough ready replicas
+		resourceCache.Put(testDeployment1ID, cache.ResourceStatus{
+			Resource: ready,
+			Status:   status.FailedStatus,
+		})
+		task.StatusUpdate(taskContext, testDeployment1ID)
+	}()
+
+	// wait for task result
+	timer := time.NewTimer(5 * time.Second)
+	receivedEvents := []event.Event{}
+loop:
+	for {
+		select {
+		case e := <-taskContext.EventChannel():
+			receivedEvents = append(receivedEvents, e)
+		case res := <-taskContext.TaskChannel():
+			timer.Stop()
+			assert.NoError(t, res.Err)
+			break loop
+		case <-timer.C:
+			t.Fatalf("timed out waiting for TaskResult")
+		}
+	}
+
+	expectedEvents := []event.Event{
+		// deployment1 pending
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: testDeployment1ID,
+				Status:     event.ReconcilePending,
+			},
+		},
+		// deployment1 ready, regardless of its status
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: testDeployment1ID,
+				Status:     event.ReconcileSuccessful,
+			},
+		},
+	}
+	testutil.AssertEqual(t, expectedEvents, receivedEvents,
+		"Actual events (%d) do not match expected events (%d)",
+		len(receivedEvents), len(expectedEvents))
+}
+
+func TestWaitTask_SkipWait(t *testing.T) {
+	testDeployment1ID := testutil.ToIdentifier(t, testDeployment1YAML)
+	testDeployment1 := testutil.Unstructured(t, testDeployment1YAML)
+	ids := object.ObjMetadataSet{
+		testDeployment1ID,
+	}
+	taskName := "wait-skip"
+	task := NewWaitTask(taskName, ids, AllCurrent,
+		2*time.Second, testutil.NewFakeRESTMapper())
+	task.SkipWait = object.ObjMetadataSet{testDeployment1ID}
+
+	eventChannel := make(chan event.Event)
+	resourceCache := cache.NewResourceCacheMap()
+	taskContext := NewTaskContext(eventChannel, resourceCache)
+	defer close(eventChannel)
+
+	// mark deployment 1 as apply succeeded
+	testDeployment1.SetUID("a")
+	testDeployment1.SetGeneration(1)
+	taskContext.InventoryManager().AddSuccessfulApply(testDeployment1ID,
+		testDeployment1.GetUID(), testDeployment1.GetGeneration())
+
+	// run task async, to let the test collect events
+	go func() {
+		// start the task
+		task.Start(taskContext)
+
+		// status updates of skipped objects are ignored
+		resourceCache.Put(testDeployment1ID, cache.ResourceStatus{
+			Resource: testDeployment1,
+			Status:   status.InProgressStatus,
+		})
+		task.StatusUpdate(taskContext, testDeployment1ID)
+	}()
+
+	// wait for task result
+	timer := time.NewTimer(5 * time.Second)
+	receivedEvents := []event.Event{}
+loop:
+	for {
+		select {
+		case e := <-taskContext.EventChannel():
+			receivedEvents = append(receivedEvents, e)
+		case res := <-taskContext.TaskChannel():
+			timer.Stop()
+			assert.NoError(t, res.Err)
+			break loop
+		case <-timer.C:
+			t.Fatalf("timed out waiting for TaskResult")
+		}
+	}
+
+	expectedEvents := []event.Event{
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: testDeployment1ID,
+				Status:     event.ReconcileSkippedByAnnotation,
+			},
+		},
+	}
+	testutil.AssertEqual(t, expectedEvents, receivedEvents,
+		"Actual events (%d) do not match expected events (%d)",
+		len(receivedEvents), len(expectedEvents))
+
+	assert.True(t, taskContext.InventoryManager().IsSkippedReconcile(testDeployment1ID))
+	assert.True(t, taskContext.IsSkipWaitObject(testDeployment1ID))
+}
+
+func TestWaitTask_TaskTimeout(t *testing.T) {
+	id1 := testutil.ToIdentifier(t, testDeployment1YAML)
+	id2 := testutil.ToIdentifier(t, testDeployment2YAML)
+	ids := object.ObjMetadataSet{id1, id2}
+
+	testCases := map[string]struct {
+		timeout        time.Duration
+		objectTimeouts map[object.ObjMetadata]time.Duration
+		expected       time.Duration
+	}{
+		"no object timeouts": {
+			timeout:  time.Minute,
+			expected: time.Minute,
+		},
+		"longer object timeout": {
+			timeout:        time.Minute,
+			objectTimeouts: map[object.ObjMetadata]time.Duration{id1: time.Hour},
+			expected:       time.Hour,
+		},
+		"shorter object timeout": {
+			timeout:        time.Minute,
+			objectTimeouts: map[object.ObjMetadata]time.Duration{id1: time.Second},
+			expected:       time.Minute,
+		},
+		"all objects have timeouts": {
+			objectTimeouts: map[object.ObjMetadata]time.Duration{id1: time.Second, id2: time.Minute},
+			expected:       time.Minute,
+		},
+		"some objects without timeout": {
+			objectTimeouts: map[object.ObjMetadata]time.Duration{id1: time.Second},
+			expected:       0,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			task := NewWaitTask("wait", ids, AllCurrent, tc.timeout, testutil.NewFakeRESTMapper())
+			task.ObjectTimeouts = tc.objectTimeouts
+			assert.Equal(t, tc.expected, task.taskTimeout())
+		})
+	}
+}
+
 func TestWaitTask_StartAndComplete(t *testing.T) {
 	testDeploymentID := testutil.ToIdentifier(t, testDeployment1YAML)
 	testDeployment := testutil.Unstructured(t, testDeployment1YAML)
@@ -1403,3 +1762,194 @@ func TestWaitTask_UIDChanged(t *testing.T) {
 		})
 	}
 }
+
+var testCRDYAML = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+  uid: crd-uid
+  generation: 1
+spec:
+  group: stable.example.com
+  names:
+    kind: CronTab
+  versions:
+  - name: v1
+    served: true
+  - name: v2
+    served: false
+`
+
+// lazyRESTMapper only maps its kinds after it has been reset a number of
+// times, like a RESTMapper whose discovery lags behind the cluster.
+type lazyRESTMapper struct {
+	meta.RESTMapper
+	resets    int
+	minResets int
+}
+
+func (m *lazyRESTMapper) Reset() {
+	m.resets++
+}
+
+func (m *lazyRESTMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	if m.resets < m.minResets {
+		return nil, &meta.NoKindMatchError{GroupKind: gk, SearchedVersions: versions}
+	}
+	return m.RESTMapper.RESTMapping(gk, versions...)
+}
+
+func TestWaitTask_CRDVersions(t *testing.T) {
+	original := restMapperPollInterval
+	restMapperPollInterval = time.Millisecond
+	defer func() { restMapperPollInterval = original }()
+
+	crdID := testutil.ToIdentifier(t, testCRDYAML)
+	crd := testutil.Unstructured(t, testCRDYAML)
+	mapper := &lazyRESTMapper{
+		RESTMapper: testutil.NewFakeRESTMapper(
+			schema.GroupVersionKind{Group: "stable.example.com", Version: "v2", Kind: "CronTab"},
+		),
+		minResets: 3,
+	}
+	taskName := "wait-crd"
+	task := NewWaitTask(taskName, object.ObjMetadataSet{crdID}, AllCurrent, 2*time.Second, mapper)
+	task.CRDVersions = map[object.ObjMetadata][]string{
+		crdID: {"v2"},
+	}
+
+	eventChannel := make(chan event.Event)
+	resourceCache := cache.NewResourceCacheMap()
+	taskContext := NewTaskContext(eventChannel, resourceCache)
+	defer close(eventChannel)
+
+	taskContext.InventoryManager().AddSuccessfulApply(crdID, crd.GetUID(), crd.GetGeneration())
+
+	served := crd.DeepCopy()
+	versions, _, err := unstructured.NestedSlice(served.Object, "spec", "versions")
+	require.NoError(t, err)
+	versions[1].(map[string]interface{})["served"] = true
+	require.NoError(t, unstructured.SetNestedSlice(served.Object, versions, "spec", "versions"))
+
+	// run task async, to let the test collect events
+	go func() {
+		// start the task
+		task.Start(taskContext)
+
+		// mark the CRD as Current, without serving v2
+		resourceCache.Put(crdID, cache.ResourceStatus{
+			Resource: crd,
+			Status:   status.CurrentStatus,
+		})
+		task.StatusUpdate(taskContext, crdID)
+
+		// mark the CRD as Current, serving v2
+		resourceCache.Put(crdID, cache.ResourceStatus{
+			Resource: served,
+			Status:   status.CurrentStatus,
+		})
+		task.StatusUpdate(taskContext, crdID)
+	}()
+
+	// wait for task result
+	timer := time.NewTimer(5 * time.Second)
+	receivedEvents := []event.Event{}
+loop:
+	for {
+		select {
+		case e := <-taskContext.EventChannel():
+			receivedEvents = append(receivedEvents, e)
+		case res := <-taskContext.TaskChannel():
+			timer.Stop()
+			assert.NoError(t, res.Err)
+			break loop
+		case <-timer.C:
+			t.Fatalf("timed out waiting for TaskResult")
+		}
+	}
+
+	expectedEvents := []event.Event{
+		// crd pending
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: crdID,
+				Status:     event.ReconcilePending,
+			},
+		},
+		// crd reconciled, once it serves v2
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: crdID,
+				Status:     event.ReconcileSuccessful,
+			},
+		},
+	}
+	testutil.AssertEqual(t, expectedEvents, receivedEvents,
+		"Actual events (%d) do not match expected events (%d)",
+		len(receivedEvents), len(expectedEvents))
+
+	// The RESTMapper is reset until it maps the custom resources.
+	assert.Equal(t, 3, mapper.resets)
+}
+
+func TestWaitTask_CRDVersions_Cancel(t *testing.T) {
+	original := restMapperPollInterval
+	restMapperPollInterval = time.Millisecond
+	defer func() { restMapperPollInterval = original }()
+
+	crdID := testutil.ToIdentifier(t, testCRDYAML)
+	crd := testutil.Unstructured(t, testCRDYAML)
+	// Discovery never serves the custom resources.
+	mapper := &lazyRESTMapper{
+		RESTMapper: testutil.NewFakeRESTMapper(),
+		minResets:  math.MaxInt,
+	}
+	task := NewWaitTask("wait-crd", object.ObjMetadataSet{crdID}, AllCurrent, 0, mapper)
+	task.CRDVersions = map[object.ObjMetadata][]string{
+		crdID: {"v1"},
+	}
+
+	eventChannel := make(chan event.Event, 10)
+	resourceCache := cache.NewResourceCacheMap()
+	taskContext := NewTaskContext(eventChannel, resourceCache)
+	defer close(eventChannel)
+
+	taskContext.InventoryManager().AddSuccessfulApply(crdID, crd.GetUID(), crd.GetGeneration())
+
+	task.Start(taskContext)
+	resourceCache.Put(crdID, cache.ResourceStatus{
+		Resource: crd,
+		Status:   status.CurrentStatus,
+	})
+	task.StatusUpdate(taskContext, crdID)
+	// The task is cancelled while waiting for discovery.
+	task.Cancel(taskContext)
+
+	select {
+	case res := <-taskContext.TaskChannel():
+		assert.NoError(t, res.Err)
+	case <-time.After(restMapperPollTimeout / 2):
+		t.Fatalf("timed out waiting for TaskResult")
+	}
+	// The RESTMapper was reset before the task was cancelled.
+	assert.Greater(t, mapper.resets, 0)
+}
+
+func TestVersionsServed(t *testing.T) {
+	crd := testutil.Unstructured(t, testCRDYAML)
+	assert.True(t, versionsServed(crd, []string{"v1"}))
+	assert.False(t, versionsServed(crd, []string{"v1", "v2"}))
+
+	// CRDs with the deprecated version field serve it.
+	legacy := crd.DeepCopy()
+	unstructured.RemoveNestedField(legacy.Object, "spec", "versions")
+	require.NoError(t, unstructured.SetNestedField(legacy.Object, "v1beta1", "spec", "version"))
+	assert.True(t, versionsServed(legacy, []string{"v1beta1"}))
+	assert.False(t, versionsServed(legacy, []string{"v1"}))
+	assert.False(t, versionsServed(nil, []string{"v1"}))
+}
@@ -0,0 +1,63 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package taskrunner
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// TracerName is the name of the OpenTelemetry tracer of the task runner.
+const TracerName = "sigs.k8s.io/cli-utils/pkg/apply"
+
+// Attributes of the spans of tasks and objects.
+const (
+	TaskNameKey        = attribute.Key("cli_utils.task.name")
+	TaskActionKey      = attribute.Key("cli_utils.task.action")
+	ObjectGroupKey     = attribute.Key("cli_utils.object.group")
+	ObjectKindKey      = attribute.Key("cli_utils.object.kind")
+	ObjectNamespaceKey = attribute.Key("cli_utils.object.namespace")
+	ObjectNameKey      = attribute.Key("cli_utils.object.name")
+)
+
+// StartObjectSpan starts a span of an operation on an object, e.g. "Apply",
+// as a child of the span of the running task.
+func (tc *TaskContext) StartObjectSpan(operation string, id object.ObjMetadata) (context.Context, trace.Span) {
+	return tc.tracer.Start(tc.Context(), operation, trace.WithAttributes(
+		ObjectGroupKey.String(id.GroupKind.Group),
+		ObjectKindKey.String(id.GroupKind.Kind),
+		ObjectNamespaceKey.String(id.Namespace),
+		ObjectNameKey.String(id.Name),
+	))
+}
+
+// startTaskSpan starts the span of the task as a child of the span in the
+// parent context, and sets it as the context of the running task.
+func (tc *TaskContext) startTaskSpan(parent context.Context, t Task) {
+	tc.ctx, tc.taskSpan = tc.tracer.Start(parent, t.Name(), trace.WithAttributes(
+		TaskNameKey.String(t.Name()),
+		TaskActionKey.String(t.Action().String()),
+	))
+}
+
+// endTaskSpan ends the span of the running task, if any.
+func (tc *TaskContext) endTaskSpan(err error) {
+	if tc.taskSpan != nil {
+		EndSpan(tc.taskSpan, err)
+		tc.taskSpan = nil
+	}
+}
+
+// EndSpan records the error on the span, if any, and ends the span.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package taskrunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"sigs.k8s.io/cli-utils/pkg/apply/cache"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// objectSpanTask starts and ends a span for each of its objects.
+type objectSpanTask struct {
+	name string
+	ids  object.ObjMetadataSet
+	err  error
+}
+
+func (f *objectSpanTask) Name() string {
+	return f.name
+}
+
+func (f *objectSpanTask) Action() event.ResourceAction {
+	return event.ApplyAction
+}
+
+func (f *objectSpanTask) Identifiers() object.ObjMetadataSet {
+	return f.ids
+}
+
+func (f *objectSpanTask) Start(taskContext *TaskContext) {
+	go func() {
+		for _, id := range f.ids {
+			_, span := taskContext.StartObjectSpan("Apply", id)
+			EndSpan(span, f.err)
+		}
+		taskContext.TaskChannel() <- TaskResult{Err: f.err}
+	}()
+}
+
+func (f *objectSpanTask) Cancel(_ *TaskContext) {}
+
+func (f *objectSpanTask) StatusUpdate(_ *TaskContext, _ object.ObjMetadata) {}
+
+func TestTaskStatusRunnerSpans(t *testing.T) {
+	testErr := errors.New("apply failed")
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	taskQueue := make(chan Task, 2)
+	taskQueue <- &objectSpanTask{name: "apply-0", ids: object.ObjMetadataSet{depID}}
+	taskQueue <- &objectSpanTask{name: "apply-1", ids: object.ObjMetadataSet{cmID}, err: testErr}
+
+	eventChannel := make(chan event.Event)
+	taskContext := NewTaskContext(eventChannel, cache.NewResourceCacheMap())
+	taskContext.SetTracer(tp.Tracer(TracerName))
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range eventChannel {
+		}
+	}()
+
+	statusWatcher := newFakeWatcher(nil)
+	statusWatcher.Start()
+	ctx, parent := tp.Tracer("caller").Start(context.Background(), "caller")
+	err := NewTaskStatusRunner(object.ObjMetadataSet{}, statusWatcher).
+		Run(ctx, taskContext, taskQueue, Options{})
+	parent.End()
+	close(eventChannel)
+	wg.Wait()
+	require.EqualError(t, err, fmt.Sprintf(`task failed (action: "Apply", name: "apply-1"): %v`, testErr))
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range recorder.Ended() {
+		name := s.Name()
+		for _, attr := range s.Attributes() {
+			if attr.Key == ObjectNameKey {
+				name += "/" + attr.Value.AsString()
+			}
+		}
+		spans[name] = s
+	}
+	require.Len(t, spans, 6)
+
+	// The spans are propagated from the context of the caller.
+	assert.Equal(t, spans["caller"].SpanContext().SpanID(), spans["TaskStatusRunner.Run"].Parent().SpanID())
+	assert.Equal(t, spans["TaskStatusRunner.Run"].SpanContext().SpanID(), spans["apply-0"].Parent().SpanID())
+	assert.Equal(t, spans["TaskStatusRunner.Run"].SpanContext().SpanID(), spans["apply-1"].Parent().SpanID())
+	assert.Equal(t, spans["apply-0"].SpanContext().SpanID(), spans["Apply/dep"].Parent().SpanID())
+	assert.Equal(t, spans["apply-1"].SpanContext().SpanID(), spans["Apply/cm"].Parent().SpanID())
+
+	assert.Equal(t, codes.Unset, spans["apply-0"].Status().Code)
+	assert.Equal(t, codes.Unset, spans["Apply/dep"].Status().Code)
+	assert.Equal(t, codes.Error, spans["Apply/cm"].Status().Code)
+	assert.Equal(t, codes.Error, spans["apply-1"].Status().Code)
+	assert.Equal(t, codes.Error, spans["TaskStatusRunner.Run"].Status().Code)
+}
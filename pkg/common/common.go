@@ -41,6 +41,36 @@ const (
 	// PreventDeletion is the value used with LifecycleDeletionAnnotation
 	// to prevent deleting a resource.
 	PreventDeletion = "detach"
+
+	// LifecycleDetachAnnotation is the lifecycle annotation key used to
+	// remove an object from the inventory without deleting it, the next
+	// time it would be pruned or deleted.
+	LifecycleDetachAnnotation = "client.lifecycle.config.k8s.io/detach"
+
+	// Detach is the value used with LifecycleDetachAnnotation to detach
+	// the object from its inventory.
+	Detach = "true"
+
+	// TargetClusterAnnotation is the annotation key used by the
+	// MultiClusterApplier to select the cluster an object is applied to.
+	TargetClusterAnnotation = "config.kubernetes.io/target-cluster"
+
+	// NamespaceCreatedAnnotation is the annotation key set on the Namespace
+	// objects that the applier generated for namespaced objects whose
+	// namespace was neither applied nor in the cluster.
+	NamespaceCreatedAnnotation = "cli-utils.sigs.k8s.io/namespace-created"
+
+	// NamespaceCreated is the value used with NamespaceCreatedAnnotation.
+	NamespaceCreated = "true"
+
+	// ActuationStrategyAnnotation is the annotation key used to change how
+	// an object is actuated.
+	ActuationStrategyAnnotation = "config.kubernetes.io/actuation-strategy"
+
+	// PatchIfExists is the value used with ActuationStrategyAnnotation to
+	// only patch the object if it already exists. The object is never
+	// created, nor deleted, e.g. because it is owned by another system.
+	PatchIfExists = "patch-if-exists"
 )
 
 // RandomStr returns an eight-digit (with leading zeros) string of a
@@ -54,8 +84,9 @@ func RandomStr() string {
 // true if that matches with the prevent deletion annotation.
 func NoDeletion(key, value string) bool {
 	m := map[string]string{
-		LifecycleDeleteAnnotation: PreventDeletion,
-		OnRemoveAnnotation:        OnRemoveKeep,
+		LifecycleDeleteAnnotation:   PreventDeletion,
+		OnRemoveAnnotation:          OnRemoveKeep,
+		ActuationStrategyAnnotation: PatchIfExists,
 	}
 	if val, found := m[key]; found {
 		return val == value
@@ -63,6 +94,12 @@ func NoDeletion(key, value string) bool {
 	return false
 }
 
+// IsDetached returns true if the passed annotations contain the detach
+// lifecycle annotation.
+func IsDetached(annotations map[string]string) bool {
+	return annotations[LifecycleDetachAnnotation] == Detach
+}
+
 var Strategies = []DryRunStrategy{DryRunClient, DryRunServer}
 
 //go:generate stringer -type=DryRunStrategy
@@ -0,0 +1,30 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package common
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// maxFieldManagerLength is the maximum length of a field manager accepted
+// by the API server.
+const maxFieldManagerLength = 128
+
+// ValidateFieldManager returns an error if the API server would reject the
+// passed field manager. An empty field manager is valid, the API server
+// derives one from the user agent.
+func ValidateFieldManager(fieldManager string) error {
+	if len(fieldManager) > maxFieldManagerLength {
+		return fmt.Errorf("invalid field manager %q: must have at most %d bytes",
+			fieldManager, maxFieldManagerLength)
+	}
+	for _, r := range fieldManager {
+		if !unicode.IsPrint(r) {
+			return fmt.Errorf("invalid field manager %q: must only contain printable characters",
+				fieldManager)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFieldManager(t *testing.T) {
+	testCases := map[string]struct {
+		fieldManager string
+		valid        bool
+	}{
+		"empty": {
+			fieldManager: "",
+			valid:        true,
+		},
+		"default": {
+			fieldManager: DefaultFieldManager,
+			valid:        true,
+		},
+		"maximum length": {
+			fieldManager: strings.Repeat("a", 128),
+			valid:        true,
+		},
+		"too long": {
+			fieldManager: strings.Repeat("a", 129),
+			valid:        false,
+		},
+		"control character": {
+			fieldManager: "kubectl\n",
+			valid:        false,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			err := ValidateFieldManager(tc.fieldManager)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
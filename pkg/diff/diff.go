@@ -0,0 +1,248 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package diff computes structured differences between the live state of
+// an object in the cluster and the state it would have after being applied.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Operation describes how a field differs.
+type Operation string
+
+const (
+	// Added fields are only set after the apply.
+	Added Operation = "Added"
+	// Removed fields are only set in the live object.
+	Removed Operation = "Removed"
+	// Changed fields are set in both, with different values.
+	Changed Operation = "Changed"
+)
+
+// IgnoredFields are the paths of fields that are excluded from diffs,
+// because they are updated by the server whenever an object changes.
+var IgnoredFields = []string{
+	".metadata.creationTimestamp",
+	".metadata.generation",
+	".metadata.managedFields",
+	".metadata.resourceVersion",
+	".metadata.selfLink",
+	".metadata.uid",
+	".status",
+}
+
+// Placeholders that replace the values of Secrets in diffs, so they do not
+// leak into events and logs. Like kubectl diff, changed values are replaced
+// with different placeholders, so the change stays visible.
+const (
+	maskedValue  = "***"
+	maskedBefore = "*** (before)"
+	maskedAfter  = "*** (after)"
+)
+
+// FieldDiff describes the difference of a single field.
+type FieldDiff struct {
+	// Path of the field, e.g. ".spec.template.spec.containers[0].image".
+	Path string
+	// Operation describes how the field differs.
+	Operation Operation
+	// Old is the live value of the field, or nil if it was Added.
+	Old interface{}
+	// New is the value of the field after the apply, or nil if it was
+	// Removed.
+	New interface{}
+}
+
+// String returns a string suitable for logging
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s %s", d.Operation, d.Change())
+}
+
+// Change returns the path and the values of the field, formatted as JSON.
+func (d FieldDiff) Change() string {
+	switch d.Operation {
+	case Added:
+		return fmt.Sprintf("%s: %s", d.Path, formatValue(d.New))
+	case Removed:
+		return fmt.Sprintf("%s: %s", d.Path, formatValue(d.Old))
+	default:
+		return fmt.Sprintf("%s: %s -> %s", d.Path, formatValue(d.Old), formatValue(d.New))
+	}
+}
+
+// formatValue formats the value of a field as compact JSON.
+func formatValue(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(b)
+}
+
+// Objects returns the differences between the live object and the object
+// after the apply, sorted by path. The live object may be nil, if the
+// object does not exist yet. The values of Secrets are masked.
+func Objects(live, applied *unstructured.Unstructured) []FieldDiff {
+	var liveObj, appliedObj map[string]interface{}
+	if live != nil {
+		liveObj = live.Object
+	}
+	if applied != nil {
+		appliedObj = applied.Object
+	}
+	if isSecret(live) || isSecret(applied) {
+		liveObj, appliedObj = maskSecret(liveObj, appliedObj)
+	}
+	var diffs []FieldDiff
+	diffMaps("", liveObj, appliedObj, &diffs)
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs
+}
+
+func isSecret(u *unstructured.Unstructured) bool {
+	if u == nil {
+		return false
+	}
+	gvk := u.GroupVersionKind()
+	return gvk.Group == "" && gvk.Version == "v1" && gvk.Kind == "Secret"
+}
+
+// maskSecret returns copies of the passed Secrets, with the values of
+// .data and .stringData replaced by placeholders.
+func maskSecret(live, applied map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	live = runtime.DeepCopyJSON(live)
+	applied = runtime.DeepCopyJSON(applied)
+	for _, field := range []string{"data", "stringData"} {
+		liveData, _ := live[field].(map[string]interface{})
+		appliedData, _ := applied[field].(map[string]interface{})
+		for key, liveValue := range liveData {
+			appliedValue, found := appliedData[key]
+			switch {
+			case !found:
+				liveData[key] = maskedValue
+			case reflect.DeepEqual(liveValue, appliedValue):
+				liveData[key] = maskedValue
+				appliedData[key] = maskedValue
+			default:
+				liveData[key] = maskedBefore
+				appliedData[key] = maskedAfter
+			}
+		}
+		for key := range appliedData {
+			if _, found := liveData[key]; !found {
+				appliedData[key] = maskedValue
+			}
+		}
+	}
+	return live, applied
+}
+
+func diffValues(path string, from, to interface{}, diffs *[]FieldDiff) {
+	if isIgnored(path) {
+		return
+	}
+	switch fromValue := from.(type) {
+	case map[string]interface{}:
+		if toValue, ok := to.(map[string]interface{}); ok {
+			diffMaps(path, fromValue, toValue, diffs)
+			return
+		}
+	case []interface{}:
+		if toValue, ok := to.([]interface{}); ok {
+			diffSlices(path, fromValue, toValue, diffs)
+			return
+		}
+	}
+	if !reflect.DeepEqual(from, to) {
+		*diffs = append(*diffs, FieldDiff{
+			Path:      path,
+			Operation: Changed,
+			Old:       from,
+			New:       to,
+		})
+	}
+}
+
+func diffMaps(path string, from, to map[string]interface{}, diffs *[]FieldDiff) {
+	for key, fromValue := range from {
+		fieldPath := path + fieldSegment(key)
+		toValue, found := to[key]
+		if !found {
+			if !isIgnored(fieldPath) {
+				*diffs = append(*diffs, FieldDiff{
+					Path:      fieldPath,
+					Operation: Removed,
+					Old:       fromValue,
+				})
+			}
+			continue
+		}
+		diffValues(fieldPath, fromValue, toValue, diffs)
+	}
+	for key, toValue := range to {
+		fieldPath := path + fieldSegment(key)
+		if _, found := from[key]; found || isIgnored(fieldPath) {
+			continue
+		}
+		*diffs = append(*diffs, FieldDiff{
+			Path:      fieldPath,
+			Operation: Added,
+			New:       toValue,
+		})
+	}
+}
+
+func diffSlices(path string, from, to []interface{}, diffs *[]FieldDiff) {
+	for i := 0; i < len(from) || i < len(to); i++ {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(to):
+			*diffs = append(*diffs, FieldDiff{
+				Path:      itemPath,
+				Operation: Removed,
+				Old:       from[i],
+			})
+		case i >= len(from):
+			*diffs = append(*diffs, FieldDiff{
+				Path:      itemPath,
+				Operation: Added,
+				New:       to[i],
+			})
+		default:
+			diffValues(itemPath, from[i], to[i], diffs)
+		}
+	}
+}
+
+var simpleFieldRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// fieldSegment returns the path segment for a field name. Field names that
+// are not simple identifiers, like most annotation and label keys, are
+// quoted.
+func fieldSegment(field string) string {
+	if simpleFieldRegexp.MatchString(field) {
+		return "." + field
+	}
+	return "[" + strconv.Quote(field) + "]"
+}
+
+func isIgnored(path string) bool {
+	for _, ignored := range IgnoredFields {
+		if path == ignored {
+			return true
+		}
+	}
+	return false
+}
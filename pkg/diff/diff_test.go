@@ -0,0 +1,264 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+var deploymentYAML = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  namespace: default
+  resourceVersion: "1"
+  annotations:
+    config.k8s.io/owning-inventory: test
+spec:
+  replicas: 1
+  paused: true
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+status:
+  replicas: 1
+`
+
+var secretYAML = `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: foo
+  namespace: default
+data:
+  password: cGFzc3dvcmQ=
+  username: YWRtaW4=
+`
+
+func toUnstructured(t *testing.T, manifest string, mutators ...func(u *unstructured.Unstructured)) *unstructured.Unstructured {
+	j, err := yaml.YAMLToJSON([]byte(manifest))
+	require.NoError(t, err)
+	u := &unstructured.Unstructured{}
+	require.NoError(t, u.UnmarshalJSON(j))
+	for _, mutate := range mutators {
+		mutate(u)
+	}
+	return u
+}
+
+func TestObjects(t *testing.T) {
+	live := toUnstructured(t, deploymentYAML)
+
+	testCases := map[string]struct {
+		live     *unstructured.Unstructured
+		applied  *unstructured.Unstructured
+		expected []FieldDiff
+	}{
+		"no changes": {
+			live:     live,
+			applied:  live.DeepCopy(),
+			expected: nil,
+		},
+		"server-managed fields are ignored": {
+			live: live,
+			applied: toUnstructured(t, deploymentYAML,
+				func(u *unstructured.Unstructured) {
+					u.SetResourceVersion("2")
+					u.SetGeneration(2)
+					u.Object["status"] = map[string]interface{}{"replicas": int64(3)}
+				}),
+			expected: nil,
+		},
+		"added, removed and changed fields": {
+			live: live,
+			applied: toUnstructured(t, deploymentYAML,
+				func(u *unstructured.Unstructured) {
+					spec := u.Object["spec"].(map[string]interface{})
+					spec["replicas"] = int64(3)
+					delete(spec, "paused")
+					u.SetLabels(map[string]string{"app": "foo"})
+					u.SetAnnotations(map[string]string{
+						"config.k8s.io/owning-inventory": "test",
+						"example.com/owner":              "team",
+					})
+				}),
+			expected: []FieldDiff{
+				{
+					Path:      `.metadata.annotations["example.com/owner"]`,
+					Operation: Added,
+					New:       "team",
+				},
+				{
+					Path:      ".metadata.labels",
+					Operation: Added,
+					New:       map[string]interface{}{"app": "foo"},
+				},
+				{
+					Path:      ".spec.paused",
+					Operation: Removed,
+					Old:       true,
+				},
+				{
+					Path:      ".spec.replicas",
+					Operation: Changed,
+					Old:       int64(1),
+					New:       int64(3),
+				},
+			},
+		},
+		"list items": {
+			live: live,
+			applied: toUnstructured(t, deploymentYAML,
+				func(u *unstructured.Unstructured) {
+					containers := []interface{}{
+						map[string]interface{}{"name": "app", "image": "app:v2"},
+						map[string]interface{}{"name": "sidecar", "image": "sidecar:v1"},
+					}
+					err := unstructured.SetNestedSlice(u.Object, containers, "spec", "template", "spec", "containers")
+					assert.NoError(t, err)
+				}),
+			expected: []FieldDiff{
+				{
+					Path:      ".spec.template.spec.containers[0].image",
+					Operation: Changed,
+					Old:       "app:v1",
+					New:       "app:v2",
+				},
+				{
+					Path:      ".spec.template.spec.containers[1]",
+					Operation: Added,
+					New:       map[string]interface{}{"name": "sidecar", "image": "sidecar:v1"},
+				},
+			},
+		},
+		"new object": {
+			live: nil,
+			applied: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+			}},
+			expected: []FieldDiff{
+				{
+					Path:      ".apiVersion",
+					Operation: Added,
+					New:       "v1",
+				},
+				{
+					Path:      ".kind",
+					Operation: Added,
+					New:       "ConfigMap",
+				},
+			},
+		},
+		"secret data is masked": {
+			live: toUnstructured(t, secretYAML),
+			applied: toUnstructured(t, secretYAML,
+				func(u *unstructured.Unstructured) {
+					data := u.Object["data"].(map[string]interface{})
+					data["password"] = "c2VjcmV0"
+					data["token"] = "dG9rZW4="
+					delete(data, "username")
+				}),
+			expected: []FieldDiff{
+				{
+					Path:      ".data.password",
+					Operation: Changed,
+					Old:       "*** (before)",
+					New:       "*** (after)",
+				},
+				{
+					Path:      ".data.token",
+					Operation: Added,
+					New:       "***",
+				},
+				{
+					Path:      ".data.username",
+					Operation: Removed,
+					Old:       "***",
+				},
+			},
+		},
+		"new secret is masked": {
+			live: nil,
+			applied: toUnstructured(t, secretYAML,
+				func(u *unstructured.Unstructured) {
+					u.Object["metadata"] = map[string]interface{}{"name": "foo"}
+					u.Object["stringData"] = map[string]interface{}{"key": "value"}
+				}),
+			expected: []FieldDiff{
+				{
+					Path:      ".apiVersion",
+					Operation: Added,
+					New:       "v1",
+				},
+				{
+					Path:      ".data",
+					Operation: Added,
+					New: map[string]interface{}{
+						"password": "***",
+						"username": "***",
+					},
+				},
+				{
+					Path:      ".kind",
+					Operation: Added,
+					New:       "Secret",
+				},
+				{
+					Path:      ".metadata",
+					Operation: Added,
+					New:       map[string]interface{}{"name": "foo"},
+				},
+				{
+					Path:      ".stringData",
+					Operation: Added,
+					New:       map[string]interface{}{"key": "***"},
+				},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			var live, applied *unstructured.Unstructured
+			if tc.live != nil {
+				live = tc.live.DeepCopy()
+			}
+			if tc.applied != nil {
+				applied = tc.applied.DeepCopy()
+			}
+			assert.Equal(t, tc.expected, Objects(tc.live, tc.applied))
+			// The passed objects are not modified
+			assert.Equal(t, live, tc.live)
+			assert.Equal(t, applied, tc.applied)
+		})
+	}
+}
+
+func TestFieldDiff_String(t *testing.T) {
+	assert.Equal(t, `Added .metadata.labels: {"app":"foo"}`, FieldDiff{
+		Path:      ".metadata.labels",
+		Operation: Added,
+		New:       map[string]interface{}{"app": "foo"},
+	}.String())
+	assert.Equal(t, `Removed .spec.paused: true`, FieldDiff{
+		Path:      ".spec.paused",
+		Operation: Removed,
+		Old:       true,
+	}.String())
+	assert.Equal(t, `Changed .spec.replicas: 1 -> 3`, FieldDiff{
+		Path:      ".spec.replicas",
+		Operation: Changed,
+		Old:       1,
+		New:       3,
+	}.String())
+}
@@ -0,0 +1,61 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Differ computes the diff of an object by comparing the live object in
+// the cluster with the result of a server-side dry-run apply.
+type Differ struct {
+	Client dynamic.Interface
+	Mapper meta.RESTMapper
+	// FieldManager is the field manager used for the dry-run apply.
+	FieldManager string
+}
+
+// Diff returns the differences between the live object and the object
+// after the passed object is applied. Conflicts with other field managers
+// are forced, so the diff shows the result of taking ownership.
+func (d *Differ) Diff(ctx context.Context, obj *unstructured.Unstructured) ([]FieldDiff, error) {
+	_, diffs, err := d.DiffLive(ctx, obj)
+	return diffs, err
+}
+
+// DiffLive returns the live object, or nil if it does not exist, and the
+// differences like Diff.
+func (d *Differ) DiffLive(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, []FieldDiff, error) {
+	client, err := object.ResourceClient(d.Client, d.Mapper,
+		object.UnstructuredToObjMetadata(obj), obj.GroupVersionKind().Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, nil, fmt.Errorf("failed to get live object: %w", err)
+		}
+		live = nil
+	}
+
+	applied, err := client.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+		DryRun:       []string{metav1.DryRunAll},
+		Force:        true,
+		FieldManager: d.FieldManager,
+	})
+	if err != nil {
+		return live, nil, fmt.Errorf("failed to dry-run apply: %w", err)
+	}
+	return live, Objects(live, applied), nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+// dryRunApplyReactor returns the applied object as the result of every
+// apply patch, like a server-side dry-run apply without defaulting.
+func dryRunApplyReactor(t *testing.T) clienttesting.ReactionFunc {
+	return func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(clienttesting.PatchAction)
+		require.Equal(t, types.ApplyPatchType, patchAction.GetPatchType())
+		u := &unstructured.Unstructured{}
+		require.NoError(t, u.UnmarshalJSON(patchAction.GetPatch()))
+		return true, u, nil
+	}
+}
+
+func TestDiffer_Diff(t *testing.T) {
+	live := toUnstructured(t, deploymentYAML)
+	desired := toUnstructured(t, deploymentYAML, func(u *unstructured.Unstructured) {
+		u.SetResourceVersion("")
+		delete(u.Object, "status")
+		spec := u.Object["spec"].(map[string]interface{})
+		spec["replicas"] = int64(3)
+	})
+
+	testCases := map[string]struct {
+		clusterObjs []runtime.Object
+		expected    []FieldDiff
+	}{
+		"existing object": {
+			clusterObjs: []runtime.Object{live},
+			expected: []FieldDiff{
+				{
+					Path:      ".spec.replicas",
+					Operation: Changed,
+					Old:       int64(1),
+					New:       int64(3),
+				},
+			},
+		},
+		"new object": {
+			expected: []FieldDiff{
+				{Path: ".apiVersion", Operation: Added, New: "apps/v1"},
+				{Path: ".kind", Operation: Added, New: "Deployment"},
+				{Path: ".metadata", Operation: Added, New: desired.Object["metadata"]},
+				{Path: ".spec", Operation: Added, New: desired.Object["spec"]},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClient(scheme.Scheme, tc.clusterObjs...)
+			client.PrependReactor("patch", "deployments", dryRunApplyReactor(t))
+			differ := &Differ{
+				FieldManager: "test",
+				Client:       client,
+				Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+					scheme.Scheme.PrioritizedVersionsAllGroups()...),
+			}
+			diffs, err := differ.Diff(context.TODO(), desired)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, diffs)
+		})
+	}
+}
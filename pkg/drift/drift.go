@@ -0,0 +1,181 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package drift detects objects of an inventory whose live state in the
+// cluster has drifted from their desired state, e.g. because fields were
+// changed by other field managers, or the objects were deleted out-of-band.
+//
+// Drift is detected with a server-side dry-run apply of each object, so
+// nothing in the cluster is changed.
+package drift
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/diff"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Detector detects the drift of the objects of an inventory.
+type Detector struct {
+	Client    dynamic.Interface
+	Mapper    meta.RESTMapper
+	InvClient inventory.Client
+	// FieldManager is the field manager of the dry-run apply. It should be
+	// the field manager the objects are applied with. Defaults to
+	// common.DefaultFieldManager.
+	FieldManager string
+}
+
+// Detect returns the drift of each of the objects, in order. The objects
+// are the desired state of the inventory, as passed to Applier.Run. Objects
+// in the inventory that are not passed would be pruned, and are not
+// reported. An error is only returned if the inventory cannot be read;
+// errors of single objects are reported in their result.
+func (d *Detector) Detect(ctx context.Context, inv inventory.Info, objs object.UnstructuredSet) ([]event.DriftEvent, error) {
+	invIDs, err := d.InvClient.GetClusterObjs(inv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+	results := make([]event.DriftEvent, 0, len(objs))
+	for _, obj := range objs {
+		results = append(results, d.detect(ctx, inv, invIDs, obj))
+	}
+	return results, nil
+}
+
+// Run detects the drift of the objects like Detect, sending a DriftEvent
+// for each object to the returned channel, so the results can be printed
+// like the events of an apply. An ErrorEvent is sent if the inventory cannot
+// be read.
+func (d *Detector) Run(ctx context.Context, inv inventory.Info, objs object.UnstructuredSet) <-chan event.Event {
+	eventChannel := make(chan event.Event)
+	go func() {
+		defer close(eventChannel)
+		invIDs, err := d.InvClient.GetClusterObjs(inv)
+		if err != nil {
+			eventChannel <- event.Event{
+				Type:       event.ErrorType,
+				ErrorEvent: event.ErrorEvent{Err: fmt.Errorf("failed to read inventory: %w", err)},
+			}
+			return
+		}
+		for _, obj := range objs {
+			eventChannel <- event.Event{
+				Type:       event.DriftType,
+				DriftEvent: d.detect(ctx, inv, invIDs, obj),
+			}
+		}
+	}()
+	return eventChannel
+}
+
+// detect returns the drift of a single object.
+func (d *Detector) detect(ctx context.Context, inv inventory.Info, invIDs object.ObjMetadataSet,
+	obj *unstructured.Unstructured) event.DriftEvent {
+	id := object.UnstructuredToObjMetadata(obj)
+	result := event.DriftEvent{Identifier: id}
+	// Compare with the object as it would be applied, which includes the
+	// inventory annotation.
+	obj = obj.DeepCopy()
+	inventory.AddInventoryIDAnnotation(obj, inv)
+
+	client, err := object.ResourceClient(d.Client, d.Mapper,
+		object.UnstructuredToObjMetadata(obj), obj.GroupVersionKind().Version)
+	if err != nil {
+		return failed(result, err)
+	}
+	live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return failed(result, fmt.Errorf("failed to get live object: %w", err))
+		}
+		if invIDs.Contains(id) {
+			result.Status = event.DriftDeleted
+		} else {
+			result.Status = event.DriftNotApplied
+		}
+		return result
+	}
+	applied, err := client.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+		DryRun:       []string{metav1.DryRunAll},
+		Force:        true,
+		FieldManager: d.fieldManager(),
+	})
+	if err != nil {
+		return failed(result, fmt.Errorf("failed to dry-run apply: %w", err))
+	}
+	diffs := diff.Objects(live, applied)
+	if len(diffs) == 0 {
+		result.Status = event.DriftInSync
+		return result
+	}
+	result.Status = event.DriftModified
+	result.Diffs = diffs
+	result.Managers = d.overriddenManagers(live, applied)
+	klog.V(4).Infof("object %s drifted: %d fields changed", id, len(result.Diffs))
+	return result
+}
+
+// overriddenManagers returns the field managers of the live object, other
+// than the field manager of the apply, that lose ownership of any field to
+// the forced dry-run apply, sorted by name.
+func (d *Detector) overriddenManagers(live, applied *unstructured.Unstructured) []string {
+	appliedFields := make(map[string]metav1.ManagedFieldsEntry)
+	for _, entry := range applied.GetManagedFields() {
+		appliedFields[managedFieldsKey(entry)] = entry
+	}
+	managers := make(map[string]bool)
+	for _, entry := range live.GetManagedFields() {
+		if entry.Manager == d.fieldManager() {
+			continue
+		}
+		after, found := appliedFields[managedFieldsKey(entry)]
+		if !found || !sameFields(entry.FieldsV1, after.FieldsV1) {
+			managers[entry.Manager] = true
+		}
+	}
+	var names []string
+	for name := range managers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// managedFieldsKey identifies the managed fields entry of a field manager.
+func managedFieldsKey(entry metav1.ManagedFieldsEntry) string {
+	return fmt.Sprintf("%s/%s/%s", entry.Manager, entry.Operation, entry.Subresource)
+}
+
+func sameFields(a, b *metav1.FieldsV1) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return bytes.Equal(a.Raw, b.Raw)
+}
+
+func (d *Detector) fieldManager() string {
+	if d.FieldManager == "" {
+		return common.DefaultFieldManager
+	}
+	return d.FieldManager
+}
+
+func failed(result event.DriftEvent, err error) event.DriftEvent {
+	result.Status = event.DriftFailed
+	result.Error = err
+	return result
+}
@@ -0,0 +1,182 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package drift
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/diff"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const fieldManager = "test"
+
+var deploymentGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+func deployment(name string, replicas int64, managers ...string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+				"annotations": map[string]interface{}{
+					"config.k8s.io/owning-inventory": "test",
+				},
+			},
+			"spec": map[string]interface{}{
+				"replicas": replicas,
+			},
+		},
+	}
+	var entries []metav1.ManagedFieldsEntry
+	for _, m := range managers {
+		entries = append(entries, metav1.ManagedFieldsEntry{
+			Manager:   m,
+			Operation: metav1.ManagedFieldsOperationUpdate,
+			FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+		})
+	}
+	u.SetManagedFields(entries)
+	return u
+}
+
+// dryRunApplyReactor returns the live object with the spec of the applied
+// object, owned by the field manager of the apply, like a forced server-side
+// dry-run apply.
+func dryRunApplyReactor(t *testing.T, client *fake.FakeDynamicClient) clienttesting.ReactionFunc {
+	return func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(clienttesting.PatchAction)
+		patch := &unstructured.Unstructured{}
+		require.NoError(t, patch.UnmarshalJSON(patchAction.GetPatch()))
+		obj, err := client.Tracker().Get(deploymentGVR, patchAction.GetNamespace(), patchAction.GetName())
+		require.NoError(t, err)
+		applied := obj.(*unstructured.Unstructured).DeepCopy()
+		applied.Object["spec"] = patch.Object["spec"]
+		applied.SetManagedFields([]metav1.ManagedFieldsEntry{{
+			Manager:   fieldManager,
+			Operation: metav1.ManagedFieldsOperationApply,
+			FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+		}})
+		return true, applied, nil
+	}
+}
+
+func TestDetector_Detect(t *testing.T) {
+	inv := inventory.WrapInventoryInfoObj(inventoryObj())
+	synced := deployment("synced", 1, fieldManager)
+	modified := deployment("modified", 1, fieldManager)
+	deleted := deployment("deleted", 1)
+	notApplied := deployment("not-applied", 1)
+	unknown := &unstructured.Unstructured{}
+	unknown.SetAPIVersion("example.com/v1")
+	unknown.SetKind("Unknown")
+	unknown.SetName("unknown")
+	unknown.SetNamespace("default")
+
+	client := fake.NewSimpleDynamicClient(scheme.Scheme,
+		deployment("synced", 1, fieldManager),
+		deployment("modified", 3, "kubectl-edit"),
+	)
+	client.PrependReactor("patch", "deployments", dryRunApplyReactor(t, client))
+	detector := &Detector{
+		Client: client,
+		Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+			scheme.Scheme.PrioritizedVersionsAllGroups()...),
+		InvClient: inventory.NewFakeClient(object.ObjMetadataSet{
+			object.UnstructuredToObjMetadata(synced),
+			object.UnstructuredToObjMetadata(modified),
+			object.UnstructuredToObjMetadata(deleted),
+		}),
+		FieldManager: fieldManager,
+	}
+
+	results, err := detector.Detect(context.TODO(), inv,
+		object.UnstructuredSet{synced, modified, deleted, notApplied, unknown})
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+
+	assert.Equal(t, event.DriftEvent{
+		Identifier: object.UnstructuredToObjMetadata(synced),
+		Status:     event.DriftInSync,
+	}, results[0])
+	assert.Equal(t, event.DriftEvent{
+		Identifier: object.UnstructuredToObjMetadata(modified),
+		Status:     event.DriftModified,
+		Diffs: []diff.FieldDiff{
+			{Path: ".spec.replicas", Operation: diff.Changed, Old: int64(3), New: int64(1)},
+		},
+		Managers: []string{"kubectl-edit"},
+	}, results[1])
+	assert.Equal(t, event.DriftDeleted, results[2].Status)
+	assert.Equal(t, event.DriftNotApplied, results[3].Status)
+	assert.Equal(t, event.DriftFailed, results[4].Status)
+	assert.Error(t, results[4].Error)
+
+	// The passed objects are not modified.
+	assert.Equal(t, deployment("synced", 1, fieldManager), synced)
+}
+
+func TestDetector_Run(t *testing.T) {
+	inv := inventory.WrapInventoryInfoObj(inventoryObj())
+	obj := deployment("deleted", 1)
+	id := object.UnstructuredToObjMetadata(obj)
+	detector := &Detector{
+		Client: fake.NewSimpleDynamicClient(scheme.Scheme),
+		Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+			scheme.Scheme.PrioritizedVersionsAllGroups()...),
+		InvClient: inventory.NewFakeClient(object.ObjMetadataSet{id}),
+	}
+
+	var events []event.Event
+	for e := range detector.Run(context.TODO(), inv, object.UnstructuredSet{obj}) {
+		events = append(events, e)
+	}
+	assert.Equal(t, []event.Event{
+		{
+			Type: event.DriftType,
+			DriftEvent: event.DriftEvent{
+				Identifier: id,
+				Status:     event.DriftDeleted,
+			},
+		},
+	}, events)
+
+	invClient := inventory.NewFakeClient(object.ObjMetadataSet{})
+	invClient.Err = errors.New("forbidden")
+	detector.InvClient = invClient
+	events = nil
+	for e := range detector.Run(context.TODO(), inv, object.UnstructuredSet{obj}) {
+		events = append(events, e)
+	}
+	require.Len(t, events, 1)
+	assert.Equal(t, event.ErrorType, events[0].Type)
+	assert.EqualError(t, events[0].ErrorEvent.Err, "failed to read inventory: forbidden")
+}
+
+func inventoryObj() *unstructured.Unstructured {
+	inv := &unstructured.Unstructured{}
+	inv.SetAPIVersion("v1")
+	inv.SetKind("ConfigMap")
+	inv.SetName("inventory")
+	inv.SetNamespace("default")
+	inv.SetLabels(map[string]string{common.InventoryLabel: "test"})
+	return inv
+}
@@ -0,0 +1,198 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package eventrecorder posts Kubernetes Events for the actuation results of
+// the runs of the Applier and the Destroyer, so cluster operators can see
+// the deployment activity with kubectl describe, or alert on the events.
+//
+// Events are enabled with the WithEventRecorder method of the
+// ApplierBuilder and the DestroyerBuilder. The methods of a nil *Recorder
+// do nothing, so callers do not need to check whether events are enabled.
+package eventrecorder
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Reasons of the recorded Events.
+const (
+	ReasonApplied      = "Applied"
+	ReasonApplyFailed  = "ApplyFailed"
+	ReasonPruned       = "Pruned"
+	ReasonPruneFailed  = "PruneFailed"
+	ReasonDeleted      = "Deleted"
+	ReasonDeleteFailed = "DeleteFailed"
+)
+
+// Target selects the objects the Events are posted on.
+type Target int
+
+const (
+	// TargetObjects posts the Events on each of the actuated objects.
+	TargetObjects Target = iota
+	// TargetInventory posts all the Events on the inventory object.
+	TargetInventory
+)
+
+// Recorder posts an Event for each actuation result of a run.
+type Recorder struct {
+	// Recorder posts the Events.
+	Recorder record.EventRecorder
+	// Client and Mapper look up the objects the Events are posted on, if
+	// the actuation result does not include them, e.g. for apply failures.
+	Client dynamic.Interface
+	Mapper meta.RESTMapper
+	// Target selects the objects the Events are posted on. Defaults to
+	// TargetObjects.
+	Target Target
+	// InventoryGVK is the GroupVersionKind of the inventory object, for
+	// TargetInventory. Defaults to inventory.ConfigMapGVK.
+	InventoryGVK schema.GroupVersionKind
+}
+
+// Record posts an Event for each apply, prune and delete result received
+// from the passed channel, and forwards the events to the returned channel.
+// Pending and skipped results are not recorded.
+func (r *Recorder) Record(inv inventory.Info, ch <-chan event.Event) <-chan event.Event {
+	if r == nil {
+		return ch
+	}
+	out := make(chan event.Event)
+	go func() {
+		defer close(out)
+		refs := make(map[object.ObjMetadata]*corev1.ObjectReference)
+		for e := range ch {
+			r.record(inv, refs, e)
+			out <- e
+		}
+	}()
+	return out
+}
+
+// record posts the Event of the actuation result, if any.
+func (r *Recorder) record(inv inventory.Info, refs map[object.ObjMetadata]*corev1.ObjectReference, e event.Event) {
+	switch e.Type {
+	case event.ApplyType:
+		switch e.ApplyEvent.Status {
+		case event.ApplySuccessful:
+			r.event(inv, refs, e.ApplyEvent.Identifier, e.ApplyEvent.Resource,
+				corev1.EventTypeNormal, ReasonApplied, "applied", nil)
+		case event.ApplyFailed:
+			r.event(inv, refs, e.ApplyEvent.Identifier, e.ApplyEvent.Resource,
+				corev1.EventTypeWarning, ReasonApplyFailed, "apply failed", e.ApplyEvent.Error)
+		}
+	case event.PruneType:
+		switch e.PruneEvent.Status {
+		case event.PruneSuccessful:
+			r.event(inv, refs, e.PruneEvent.Identifier, e.PruneEvent.Object,
+				corev1.EventTypeNormal, ReasonPruned, "pruned", nil)
+		case event.PruneFailed:
+			r.event(inv, refs, e.PruneEvent.Identifier, e.PruneEvent.Object,
+				corev1.EventTypeWarning, ReasonPruneFailed, "prune failed", e.PruneEvent.Error)
+		}
+	case event.DeleteType:
+		switch e.DeleteEvent.Status {
+		case event.DeleteSuccessful:
+			r.event(inv, refs, e.DeleteEvent.Identifier, e.DeleteEvent.Object,
+				corev1.EventTypeNormal, ReasonDeleted, "deleted", nil)
+		case event.DeleteFailed:
+			r.event(inv, refs, e.DeleteEvent.Identifier, e.DeleteEvent.Object,
+				corev1.EventTypeWarning, ReasonDeleteFailed, "delete failed", e.DeleteEvent.Error)
+		}
+	}
+}
+
+// event posts an Event about the object with the id on the target.
+func (r *Recorder) event(inv inventory.Info, refs map[object.ObjMetadata]*corev1.ObjectReference,
+	id object.ObjMetadata, obj *unstructured.Unstructured, eventType, reason, action string, err error) {
+	var ref *corev1.ObjectReference
+	var message string
+	if r.Target == TargetInventory {
+		ref = r.inventoryReference(inv, refs)
+		message = fmt.Sprintf("%s %s/%s %s", id.GroupKind, id.Namespace, id.Name, action)
+	} else {
+		ref = r.reference(refs, id, obj)
+		message = fmt.Sprintf("Object %s by inventory %s/%s", action, inv.Namespace(), inv.Name())
+	}
+	if err != nil {
+		message = fmt.Sprintf("%s: %v", message, err)
+	}
+	r.Recorder.Event(ref, eventType, reason, message)
+}
+
+// reference returns the reference of the object with the id. The reference
+// includes the UID of the object, so the Event is shown by kubectl describe,
+// unless the object can not be found.
+func (r *Recorder) reference(refs map[object.ObjMetadata]*corev1.ObjectReference,
+	id object.ObjMetadata, obj *unstructured.Unstructured) *corev1.ObjectReference {
+	if obj != nil && obj.GetUID() != "" {
+		ref := &corev1.ObjectReference{
+			APIVersion:      obj.GetAPIVersion(),
+			Kind:            obj.GetKind(),
+			Namespace:       obj.GetNamespace(),
+			Name:            obj.GetName(),
+			UID:             obj.GetUID(),
+			ResourceVersion: obj.GetResourceVersion(),
+		}
+		refs[id] = ref
+		return ref
+	}
+	if ref, found := refs[id]; found {
+		return ref
+	}
+	ref := &corev1.ObjectReference{
+		Kind:      id.GroupKind.Kind,
+		Namespace: id.Namespace,
+		Name:      id.Name,
+	}
+	if r.Mapper == nil {
+		refs[id] = ref
+		return ref
+	}
+	mapping, err := r.Mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		klog.V(4).Infof("failed to map event object %s: %v", id, err)
+		refs[id] = ref
+		return ref
+	}
+	ref.APIVersion = mapping.GroupVersionKind.GroupVersion().String()
+	if r.Client != nil {
+		client := object.ResourceClientForMapping(r.Client, mapping, id.Namespace)
+		live, err := client.Get(context.TODO(), id.Name, metav1.GetOptions{})
+		if err != nil {
+			klog.V(4).Infof("failed to get event object %s: %v", id, err)
+		} else {
+			ref.UID = live.GetUID()
+			ref.ResourceVersion = live.GetResourceVersion()
+		}
+	}
+	refs[id] = ref
+	return ref
+}
+
+// inventoryReference returns the reference of the inventory object.
+func (r *Recorder) inventoryReference(inv inventory.Info, refs map[object.ObjMetadata]*corev1.ObjectReference) *corev1.ObjectReference {
+	gvk := r.InventoryGVK
+	if gvk.Empty() {
+		gvk = inventory.ConfigMapGVK
+	}
+	id := object.ObjMetadata{
+		GroupKind: gvk.GroupKind(),
+		Namespace: inv.Namespace(),
+		Name:      inv.Name(),
+	}
+	return r.reference(refs, id, inventory.InvInfoToConfigMap(inv))
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package eventrecorder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func deployment(name string, uid types.UID) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("apps/v1")
+	u.SetKind("Deployment")
+	u.SetName(name)
+	u.SetNamespace("default")
+	u.SetUID(uid)
+	return u
+}
+
+func inventoryObj() *unstructured.Unstructured {
+	inv := &unstructured.Unstructured{}
+	inv.SetAPIVersion("v1")
+	inv.SetKind("ConfigMap")
+	inv.SetName("inventory")
+	inv.SetNamespace("default")
+	inv.SetUID("inv-uid")
+	inv.SetLabels(map[string]string{common.InventoryLabel: "test"})
+	return inv
+}
+
+func runEvents() []event.Event {
+	applied := deployment("applied", "applied-uid")
+	failed := deployment("failed", "")
+	pruned := deployment("pruned", "pruned-uid")
+	return []event.Event{
+		{Type: event.InitType},
+		{Type: event.ApplyType, ApplyEvent: event.ApplyEvent{
+			Identifier: object.UnstructuredToObjMetadata(applied),
+			Status:     event.ApplySuccessful,
+			Resource:   applied,
+		}},
+		{Type: event.ApplyType, ApplyEvent: event.ApplyEvent{
+			Identifier: object.UnstructuredToObjMetadata(failed),
+			Status:     event.ApplyFailed,
+			Error:      errors.New("forbidden"),
+		}},
+		{Type: event.ApplyType, ApplyEvent: event.ApplyEvent{
+			Identifier: object.UnstructuredToObjMetadata(failed),
+			Status:     event.ApplySkipped,
+		}},
+		{Type: event.PruneType, PruneEvent: event.PruneEvent{
+			Identifier: object.UnstructuredToObjMetadata(pruned),
+			Status:     event.PruneSuccessful,
+			Object:     pruned,
+		}},
+	}
+}
+
+func runRecorder(r *Recorder, events []event.Event) []event.Event {
+	ch := make(chan event.Event)
+	go func() {
+		defer close(ch)
+		for _, e := range events {
+			ch <- e
+		}
+	}()
+	var received []event.Event
+	for e := range r.Record(inventory.WrapInventoryInfoObj(inventoryObj()), ch) {
+		received = append(received, e)
+	}
+	return received
+}
+
+func recorded(fr *record.FakeRecorder) []string {
+	close(fr.Events)
+	var events []string
+	for e := range fr.Events {
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestRecorder_Record(t *testing.T) {
+	fr := record.NewFakeRecorder(10)
+	r := &Recorder{
+		Recorder: fr,
+		Client: fake.NewSimpleDynamicClient(scheme.Scheme,
+			deployment("failed", "failed-uid")),
+		Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+			scheme.Scheme.PrioritizedVersionsAllGroups()...),
+	}
+	events := runEvents()
+	assert.Equal(t, events, runRecorder(r, events))
+	assert.Equal(t, []string{
+		"Normal Applied Object applied by inventory default/inventory",
+		"Warning ApplyFailed Object apply failed by inventory default/inventory: forbidden",
+		"Normal Pruned Object pruned by inventory default/inventory",
+	}, recorded(fr))
+}
+
+func TestRecorder_Record_Inventory(t *testing.T) {
+	fr := record.NewFakeRecorder(10)
+	r := &Recorder{
+		Recorder: fr,
+		Target:   TargetInventory,
+	}
+	runRecorder(r, runEvents())
+	assert.Equal(t, []string{
+		"Normal Applied Deployment.apps default/applied applied",
+		"Warning ApplyFailed Deployment.apps default/failed apply failed: forbidden",
+		"Normal Pruned Deployment.apps default/pruned pruned",
+	}, recorded(fr))
+}
+
+func TestRecorder_Record_Nil(t *testing.T) {
+	var r *Recorder
+	ch := make(chan event.Event)
+	assert.Equal(t, (<-chan event.Event)(ch), r.Record(nil, ch))
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/reason"
+)
+
+// Adopter claims live objects that are not owned by any inventory, e.g.
+// objects created with kubectl apply, by setting their owning-inventory
+// annotation.
+type Adopter struct {
+	// Client is used to read and update the objects.
+	Client dynamic.Interface
+	// Mapper is used to map object identifiers to resources.
+	Mapper meta.RESTMapper
+}
+
+// AdoptionResult describes the objects handled by an adoption.
+type AdoptionResult struct {
+	// Adopted are the objects that were claimed by the inventory.
+	Adopted object.ObjMetadataSet
+	// Conflicts are the objects owned by another inventory. They are not
+	// updated.
+	Conflicts object.ObjMetadataSet
+}
+
+// Adopt sets the owning-inventory annotation of the passed objects that
+// exist in the cluster without one. Objects that do not exist yet, or are
+// already owned by the inventory, are ignored. Dry-runs do not persist the
+// annotation, so the caller must treat the adopted objects as owned by the
+// inventory.
+func (a *Adopter) Adopt(ctx context.Context, inv Info, ids object.ObjMetadataSet, dryRun common.DryRunStrategy) (*AdoptionResult, error) {
+	result := &AdoptionResult{}
+	for _, id := range ids {
+		client, err := object.ResourceClient(a.Client, a.Mapper, id)
+		if err != nil {
+			return nil, err
+		}
+		live, err := client.Get(ctx, id.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, reason.ForObject(id, fmt.Errorf("failed to get object %s: %w", id, err))
+		}
+		switch IDMatch(inv, live) {
+		case Match:
+			continue
+		case NoMatch:
+			result.Conflicts = append(result.Conflicts, id)
+			continue
+		}
+		result.Adopted = append(result.Adopted, id)
+		if dryRun.ClientDryRun() {
+			klog.V(4).Infof("dry-run adopt %s: not updated", id)
+			continue
+		}
+		AddInventoryIDAnnotation(live, inv)
+		opts := metav1.UpdateOptions{}
+		if dryRun.ServerDryRun() {
+			opts.DryRun = []string{metav1.DryRunAll}
+		}
+		if _, err := client.Update(ctx, live, opts); err != nil {
+			return nil, reason.ForObject(id, fmt.Errorf("failed to adopt %s: %w", id, err))
+		}
+	}
+	return result, nil
+}
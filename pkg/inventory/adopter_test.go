@@ -0,0 +1,77 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestAdopter_Adopt(t *testing.T) {
+	owned := podWithOwner("owned", testInventoryLabel)
+	orphan := podWithOwner("orphan", "")
+	conflict := podWithOwner("conflict", "other")
+	missing := podWithOwner("missing", "")
+	ids := object.UnstructuredSetToObjMetadataSet(object.UnstructuredSet{owned, orphan, conflict, missing})
+
+	testCases := map[string]struct {
+		dryRun         common.DryRunStrategy
+		expectedOwners map[string]string
+	}{
+		"adopt": {
+			dryRun: common.DryRunNone,
+			expectedOwners: map[string]string{
+				"owned":    testInventoryLabel,
+				"orphan":   testInventoryLabel,
+				"conflict": "other",
+			},
+		},
+		"client dry-run": {
+			dryRun: common.DryRunClient,
+			expectedOwners: map[string]string{
+				"owned":    testInventoryLabel,
+				"orphan":   "",
+				"conflict": "other",
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme,
+				[]runtime.Object{owned.DeepCopy(), orphan.DeepCopy(), conflict.DeepCopy()}...)
+			adopter := &Adopter{
+				Client: client,
+				Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+					scheme.Scheme.PrioritizedVersionsAllGroups()...),
+			}
+
+			result, err := adopter.Adopt(context.TODO(), localInv, ids, tc.dryRun)
+			require.NoError(t, err)
+			assert.Equal(t, &AdoptionResult{
+				Adopted:   object.ObjMetadataSet{object.UnstructuredToObjMetadata(orphan)},
+				Conflicts: object.ObjMetadataSet{object.UnstructuredToObjMetadata(conflict)},
+			}, result)
+
+			pods := client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "pods"}).
+				Namespace(testNamespace)
+			for name, owner := range tc.expectedOwners {
+				pod, err := pods.Get(context.TODO(), name, metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, owner, pod.GetAnnotations()[OwningInventoryKey], name)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// WithFieldManager returns a dynamic client that sets the passed field
+// manager on every create, update, patch and apply request that does not
+// set one already. The passed client is returned if the field manager is
+// empty.
+func WithFieldManager(dc dynamic.Interface, fieldManager string) dynamic.Interface {
+	if fieldManager == "" {
+		return dc
+	}
+	return &fieldManagerClient{Interface: dc, fieldManager: fieldManager}
+}
+
+type fieldManagerClient struct {
+	dynamic.Interface
+	fieldManager string
+}
+
+func (c *fieldManagerClient) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &fieldManagerNamespaceableResource{
+		NamespaceableResourceInterface: c.Interface.Resource(resource),
+		fieldManager:                   c.fieldManager,
+	}
+}
+
+type fieldManagerNamespaceableResource struct {
+	dynamic.NamespaceableResourceInterface
+	fieldManager string
+}
+
+func (r *fieldManagerNamespaceableResource) Namespace(ns string) dynamic.ResourceInterface {
+	return &fieldManagerResource{
+		ResourceInterface: r.NamespaceableResourceInterface.Namespace(ns),
+		fieldManager:      r.fieldManager,
+	}
+}
+
+func (r *fieldManagerNamespaceableResource) resource() *fieldManagerResource {
+	return &fieldManagerResource{
+		ResourceInterface: r.NamespaceableResourceInterface,
+		fieldManager:      r.fieldManager,
+	}
+}
+
+func (r *fieldManagerNamespaceableResource) Create(ctx context.Context, obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	return r.resource().Create(ctx, obj, options, subresources...)
+}
+
+func (r *fieldManagerNamespaceableResource) Update(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	return r.resource().Update(ctx, obj, options, subresources...)
+}
+
+func (r *fieldManagerNamespaceableResource) UpdateStatus(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions) (*unstructured.Unstructured, error) {
+	return r.resource().UpdateStatus(ctx, obj, options)
+}
+
+func (r *fieldManagerNamespaceableResource) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	return r.resource().Patch(ctx, name, pt, data, options, subresources...)
+}
+
+func (r *fieldManagerNamespaceableResource) Apply(ctx context.Context, name string, obj *unstructured.Unstructured, options metav1.ApplyOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	return r.resource().Apply(ctx, name, obj, options, subresources...)
+}
+
+func (r *fieldManagerNamespaceableResource) ApplyStatus(ctx context.Context, name string, obj *unstructured.Unstructured, options metav1.ApplyOptions) (*unstructured.Unstructured, error) {
+	return r.resource().ApplyStatus(ctx, name, obj, options)
+}
+
+type fieldManagerResource struct {
+	dynamic.ResourceInterface
+	fieldManager string
+}
+
+func (r *fieldManagerResource) manager(fieldManager string) string {
+	if fieldManager == "" {
+		return r.fieldManager
+	}
+	return fieldManager
+}
+
+func (r *fieldManagerResource) Create(ctx context.Context, obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	options.FieldManager = r.manager(options.FieldManager)
+	return r.ResourceInterface.Create(ctx, obj, options, subresources...)
+}
+
+func (r *fieldManagerResource) Update(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	options.FieldManager = r.manager(options.FieldManager)
+	return r.ResourceInterface.Update(ctx, obj, options, subresources...)
+}
+
+func (r *fieldManagerResource) UpdateStatus(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions) (*unstructured.Unstructured, error) {
+	options.FieldManager = r.manager(options.FieldManager)
+	return r.ResourceInterface.UpdateStatus(ctx, obj, options)
+}
+
+func (r *fieldManagerResource) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	options.FieldManager = r.manager(options.FieldManager)
+	return r.ResourceInterface.Patch(ctx, name, pt, data, options, subresources...)
+}
+
+func (r *fieldManagerResource) Apply(ctx context.Context, name string, obj *unstructured.Unstructured, options metav1.ApplyOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	options.FieldManager = r.manager(options.FieldManager)
+	return r.ResourceInterface.Apply(ctx, name, obj, options, subresources...)
+}
+
+func (r *fieldManagerResource) ApplyStatus(ctx context.Context, name string, obj *unstructured.Unstructured, options metav1.ApplyOptions) (*unstructured.Unstructured, error) {
+	options.FieldManager = r.manager(options.FieldManager)
+	return r.ResourceInterface.ApplyStatus(ctx, name, obj, options)
+}
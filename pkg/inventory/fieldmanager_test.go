@@ -0,0 +1,67 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// recordingClient records the field manager of the write requests.
+type recordingClient struct {
+	dynamic.Interface
+	dynamic.NamespaceableResourceInterface
+	managers []string
+}
+
+func (c *recordingClient) Resource(schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return c
+}
+
+func (c *recordingClient) Namespace(string) dynamic.ResourceInterface {
+	return c
+}
+
+func (c *recordingClient) Create(_ context.Context, obj *unstructured.Unstructured, options metav1.CreateOptions, _ ...string) (*unstructured.Unstructured, error) {
+	c.managers = append(c.managers, options.FieldManager)
+	return obj, nil
+}
+
+func (c *recordingClient) Update(_ context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions, _ ...string) (*unstructured.Unstructured, error) {
+	c.managers = append(c.managers, options.FieldManager)
+	return obj, nil
+}
+
+func (c *recordingClient) Patch(_ context.Context, _ string, _ types.PatchType, _ []byte, options metav1.PatchOptions, _ ...string) (*unstructured.Unstructured, error) {
+	c.managers = append(c.managers, options.FieldManager)
+	return nil, nil
+}
+
+func TestWithFieldManager(t *testing.T) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	obj := &unstructured.Unstructured{}
+	ctx := context.TODO()
+
+	rc := &recordingClient{}
+	assert.Same(t, dynamic.Interface(rc), WithFieldManager(rc, ""))
+
+	dc := WithFieldManager(rc, "inventory-manager")
+	_, err := dc.Resource(gvr).Namespace(testNamespace).Create(ctx, obj, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = dc.Resource(gvr).Update(ctx, obj, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	_, err = dc.Resource(gvr).Namespace(testNamespace).Patch(ctx, "inv", types.MergePatchType, nil,
+		metav1.PatchOptions{FieldManager: "explicit"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"inventory-manager", "inventory-manager", "explicit"}, rc.managers)
+}
@@ -0,0 +1,262 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// HistoryAnnotation is the annotation of the inventory object that stores
+// the history of the applies of the inventory, as a JSON list of revisions,
+// oldest first. It is only written if the history is enabled with
+// ClusterClient.SetHistoryLimit.
+//
+// Revisions only record the objects added and removed by each apply, not
+// the objects in the inventory, so large inventories, which are sharded,
+// don't exceed the size limit of the annotations.
+const HistoryAnnotation = "cli-utils.sigs.k8s.io/inventory-history"
+
+const (
+	// MaxRevisionChanges is the number of added and removed objects above
+	// which the changes of a revision are not recorded.
+	MaxRevisionChanges = 100
+	// MaxHistorySize is the size of the encoded history above which the
+	// oldest revisions are dropped, regardless of the history limit.
+	MaxHistorySize = 64 * 1024
+)
+
+// Revision records an apply of the inventory.
+type Revision struct {
+	// Revision is the number of the revision, starting at 1.
+	Revision int `json:"revision"`
+	// Timestamp is the time the inventory was written at the end of the
+	// apply.
+	Timestamp metav1.Time `json:"timestamp"`
+	// Hash identifies the set of objects in the inventory.
+	Hash string `json:"hash"`
+	// Count is the number of objects in the inventory.
+	Count int `json:"count"`
+	// Added are the objects added to the inventory by the apply, sorted.
+	Added []string `json:"added,omitempty"`
+	// Removed are the objects removed from the inventory by the apply,
+	// sorted.
+	Removed []string `json:"removed,omitempty"`
+	// Truncated is true if the apply changed more than MaxRevisionChanges
+	// objects, in which case Added and Removed are not recorded.
+	Truncated bool `json:"truncated,omitempty"`
+	// Summary counts the objects by their actuation status.
+	Summary ActuationSummary `json:"summary"`
+}
+
+// ActuationSummary counts the objects of a revision by actuation status.
+type ActuationSummary struct {
+	Succeeded int `json:"succeeded,omitempty"`
+	Skipped   int `json:"skipped,omitempty"`
+	Failed    int `json:"failed,omitempty"`
+	Pending   int `json:"pending,omitempty"`
+}
+
+// RevisionDiff is the difference between the objects of two revisions.
+type RevisionDiff struct {
+	Added   object.ObjMetadataSet
+	Removed object.ObjMetadataSet
+}
+
+// DiffRevisions returns the objects added and removed between the revisions
+// from and to of the history, by replaying the changes of the revisions
+// after from, up to to. It fails if these revisions are not all in the
+// history, or if the changes of one of them were truncated.
+func DiffRevisions(history []Revision, from, to int) (RevisionDiff, error) {
+	if from > to {
+		return RevisionDiff{}, fmt.Errorf("revision %d is newer than revision %d", from, to)
+	}
+	added := make(map[object.ObjMetadata]bool)
+	removed := make(map[object.ObjMetadata]bool)
+	next := from + 1
+	for _, r := range history {
+		if r.Revision <= from || r.Revision > to {
+			continue
+		}
+		if r.Revision != next {
+			return RevisionDiff{}, fmt.Errorf("revision %d is not in the history", next)
+		}
+		next++
+		if r.Truncated {
+			return RevisionDiff{}, fmt.Errorf("the changes of revision %d are not recorded", r.Revision)
+		}
+		addedIds, err := parseObjMetadataSet(r.Added)
+		if err != nil {
+			return RevisionDiff{}, fmt.Errorf("invalid revision %d: %w", r.Revision, err)
+		}
+		removedIds, err := parseObjMetadataSet(r.Removed)
+		if err != nil {
+			return RevisionDiff{}, fmt.Errorf("invalid revision %d: %w", r.Revision, err)
+		}
+		for _, id := range addedIds {
+			if removed[id] {
+				delete(removed, id)
+			} else {
+				added[id] = true
+			}
+		}
+		for _, id := range removedIds {
+			if added[id] {
+				delete(added, id)
+			} else {
+				removed[id] = true
+			}
+		}
+	}
+	if next <= to {
+		return RevisionDiff{}, fmt.Errorf("revision %d is not in the history", next)
+	}
+	return RevisionDiff{
+		Added:   sortedObjMetadataSet(added),
+		Removed: sortedObjMetadataSet(removed),
+	}, nil
+}
+
+func parseObjMetadataSet(objStrs []string) (object.ObjMetadataSet, error) {
+	objs := make(object.ObjMetadataSet, 0, len(objStrs))
+	for _, objStr := range objStrs {
+		id, err := object.ParseObjMetadata(objStr)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, id)
+	}
+	return objs, nil
+}
+
+func sortedObjMetadataSet(ids map[object.ObjMetadata]bool) object.ObjMetadataSet {
+	set := make(object.ObjMetadataSet, 0, len(ids))
+	for id := range ids {
+		set = append(set, id)
+	}
+	sort.Slice(set, func(i, j int) bool {
+		return set[i].String() < set[j].String()
+	})
+	return set
+}
+
+// History returns the revisions stored on the inventory object, oldest
+// first, or nil if it has no history.
+func History(obj *unstructured.Unstructured) ([]Revision, error) {
+	value, found := obj.GetAnnotations()[HistoryAnnotation]
+	if !found || value == "" {
+		return nil, nil
+	}
+	var revisions []Revision
+	if err := json.Unmarshal([]byte(value), &revisions); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation of inventory object %s/%s: %w",
+			HistoryAnnotation, obj.GetNamespace(), obj.GetName(), err)
+	}
+	return revisions, nil
+}
+
+// newRevision returns the revision of the objects and their status, which
+// replaced the previous objects.
+func newRevision(number int, now time.Time, prevObjs, objs object.ObjMetadataSet,
+	status []actuation.ObjectStatus) Revision {
+	ids := sortedStrings(objs)
+	sum := sha256.New()
+	for _, id := range ids {
+		sum.Write([]byte(id + "\n"))
+	}
+	var summary ActuationSummary
+	for _, s := range status {
+		switch s.Actuation {
+		case actuation.ActuationSucceeded:
+			summary.Succeeded++
+		case actuation.ActuationSkipped:
+			summary.Skipped++
+		case actuation.ActuationFailed:
+			summary.Failed++
+		case actuation.ActuationPending:
+			summary.Pending++
+		}
+	}
+	r := Revision{
+		Revision:  number,
+		Timestamp: metav1.NewTime(now),
+		Hash:      hex.EncodeToString(sum.Sum(nil)),
+		Count:     len(ids),
+		Summary:   summary,
+	}
+	added := objs.Diff(prevObjs)
+	removed := prevObjs.Diff(objs)
+	if len(added)+len(removed) > MaxRevisionChanges {
+		r.Truncated = true
+	} else {
+		r.Added = sortedStrings(added)
+		r.Removed = sortedStrings(removed)
+	}
+	return r
+}
+
+func sortedStrings(objs object.ObjMetadataSet) []string {
+	ids := make([]string, 0, len(objs))
+	for _, id := range objs {
+		ids = append(ids, id.String())
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// recordRevision returns a copy of the inventory object with a new revision
+// of the objects, which replaced prevObjs, appended to its history. At most
+// limit revisions are kept, and the oldest revisions are dropped until the
+// encoded history fits in MaxHistorySize.
+func recordRevision(obj *unstructured.Unstructured, limit int, now time.Time,
+	prevObjs, objs object.ObjMetadataSet, status []actuation.ObjectStatus) (*unstructured.Unstructured, error) {
+	revisions, err := History(obj)
+	if err != nil {
+		return nil, err
+	}
+	number := 1
+	if len(revisions) > 0 {
+		number = revisions[len(revisions)-1].Revision + 1
+	}
+	revisions = append(revisions, newRevision(number, now, prevObjs, objs, status))
+	if len(revisions) > limit {
+		revisions = revisions[len(revisions)-limit:]
+	}
+	var data []byte
+	for {
+		data, err = json.Marshal(revisions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode inventory history: %w", err)
+		}
+		if len(data) <= MaxHistorySize {
+			break
+		}
+		if len(revisions) > 1 {
+			revisions = revisions[1:]
+			continue
+		}
+		if revisions[0].Truncated {
+			break
+		}
+		// The changes of the only revision are too large to be recorded.
+		revisions[0].Added, revisions[0].Removed, revisions[0].Truncated = nil, nil, true
+	}
+	obj = obj.DeepCopy()
+	annos := obj.GetAnnotations()
+	if annos == nil {
+		annos = map[string]string{}
+	}
+	annos[HistoryAnnotation] = string(data)
+	obj.SetAnnotations(annos)
+	return obj, nil
+}
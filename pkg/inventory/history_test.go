@@ -0,0 +1,171 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clienttesting "k8s.io/client-go/testing"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestRecordRevision(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.Local)
+	pod1 := ignoreErrInfoToObjMeta(pod1Info)
+	pod2 := ignoreErrInfoToObjMeta(pod2Info)
+	pod3 := ignoreErrInfoToObjMeta(pod3Info)
+	failed := podStatus(pod2Info)
+	failed.Actuation = actuation.ActuationFailed
+
+	inv := copyInventoryInfo()
+	revisions, err := History(inv)
+	require.NoError(t, err)
+	assert.Nil(t, revisions)
+
+	inv, err = recordRevision(inv, 3, now, nil, object.ObjMetadataSet{pod2, pod1},
+		[]actuation.ObjectStatus{podStatus(pod1Info), failed})
+	require.NoError(t, err)
+	inv, err = recordRevision(inv, 3, now.Add(time.Minute), object.ObjMetadataSet{pod2, pod1},
+		object.ObjMetadataSet{pod1, pod3}, nil)
+	require.NoError(t, err)
+	inv, err = recordRevision(inv, 3, now.Add(2*time.Minute), object.ObjMetadataSet{pod1, pod3},
+		object.ObjMetadataSet{pod1, pod3}, nil)
+	require.NoError(t, err)
+
+	revisions, err = History(inv)
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	first := revisions[0]
+	assert.Equal(t, 1, first.Revision)
+	assert.Equal(t, 2, first.Count)
+	assert.Equal(t, []string{pod1.String(), pod2.String()}, first.Added)
+	assert.Empty(t, first.Removed)
+	assert.Equal(t, ActuationSummary{Succeeded: 1, Failed: 1}, first.Summary)
+	// Only the changes of each revision are recorded.
+	assert.Equal(t, []string{pod3.String()}, revisions[1].Added)
+	assert.Equal(t, []string{pod2.String()}, revisions[1].Removed)
+	assert.Empty(t, revisions[2].Added)
+	assert.Empty(t, revisions[2].Removed)
+	assert.True(t, now.Add(2*time.Minute).Equal(revisions[2].Timestamp.Time))
+	// The same object set has the same hash.
+	assert.Equal(t, revisions[1].Hash, revisions[2].Hash)
+	assert.NotEqual(t, first.Hash, revisions[1].Hash)
+
+	diff, err := DiffRevisions(revisions, 0, 3)
+	require.NoError(t, err)
+	assert.Equal(t, RevisionDiff{
+		Added:   object.ObjMetadataSet{pod1, pod3},
+		Removed: object.ObjMetadataSet{},
+	}, diff)
+	diff, err = DiffRevisions(revisions, 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, RevisionDiff{
+		Added:   object.ObjMetadataSet{pod3},
+		Removed: object.ObjMetadataSet{pod2},
+	}, diff)
+
+	// Only the last revisions are kept.
+	inv, err = recordRevision(inv, 3, now.Add(3*time.Minute), object.ObjMetadataSet{pod1, pod3},
+		object.ObjMetadataSet{pod1}, nil)
+	require.NoError(t, err)
+	revisions, err = History(inv)
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	assert.Equal(t, 2, revisions[0].Revision)
+	_, err = DiffRevisions(revisions, 0, 4)
+	assert.EqualError(t, err, "revision 1 is not in the history")
+	_, err = DiffRevisions(revisions, 2, 5)
+	assert.EqualError(t, err, "revision 5 is not in the history")
+}
+
+func TestRecordRevision_Bounded(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.Local)
+	objs := func(n int) object.ObjMetadataSet {
+		ids := make(object.ObjMetadataSet, n)
+		for i := range ids {
+			ids[i] = object.ObjMetadata{
+				GroupKind: schema.GroupKind{Kind: "ConfigMap"},
+				Namespace: testNamespace,
+				Name:      fmt.Sprintf("%s-%d", strings.Repeat("x", 200), i),
+			}
+		}
+		return ids
+	}
+
+	// The changes of large applies are not recorded.
+	inv, err := recordRevision(copyInventoryInfo(), 1000, now, nil, objs(MaxRevisionChanges+1), nil)
+	require.NoError(t, err)
+	revisions, err := History(inv)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	assert.True(t, revisions[0].Truncated)
+	assert.Empty(t, revisions[0].Added)
+	assert.Equal(t, MaxRevisionChanges+1, revisions[0].Count)
+	_, err = DiffRevisions(revisions, 0, 1)
+	assert.EqualError(t, err, "the changes of revision 1 are not recorded")
+
+	// The oldest revisions are dropped when the history is too large,
+	// regardless of the limit.
+	prev := object.ObjMetadataSet{}
+	for i := 0; i < 20; i++ {
+		next := objs(MaxRevisionChanges / 2 * (i%2 + 1))
+		inv, err = recordRevision(inv, 1000, now, prev, next, nil)
+		require.NoError(t, err)
+		prev = next
+	}
+	assert.LessOrEqual(t, len(inv.GetAnnotations()[HistoryAnnotation]), MaxHistorySize)
+	revisions, err = History(inv)
+	require.NoError(t, err)
+	assert.Less(t, len(revisions), 21)
+	assert.Equal(t, 21, revisions[len(revisions)-1].Revision)
+}
+
+func TestHistory_Invalid(t *testing.T) {
+	inv := copyInventoryInfo()
+	inv.SetAnnotations(map[string]string{HistoryAnnotation: "not json"})
+	_, err := History(inv)
+	assert.Error(t, err)
+}
+
+func TestReplace_History(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+
+	pod1 := ignoreErrInfoToObjMeta(pod1Info)
+	tf.FakeDynamicClient.PrependReactor("list", "configmaps", toReactionFunc(object.ObjMetadataSet{pod1}))
+	var updated *unstructured.Unstructured
+	tf.FakeDynamicClient.PrependReactor("update", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		updated = action.(clienttesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+		return true, updated, nil
+	})
+
+	invClient, err := NewClient(tf, WrapInventoryObj, InvInfoToConfigMap, StatusPolicyNone, ConfigMapGVK)
+	require.NoError(t, err)
+	assert.Error(t, invClient.SetHistoryLimit(-1))
+	require.NoError(t, invClient.SetHistoryLimit(10))
+
+	// The inventory is updated to record the revision, even though the
+	// objects did not change.
+	err = invClient.Replace(copyInventory(), object.ObjMetadataSet{pod1},
+		[]actuation.ObjectStatus{podStatus(pod1Info)}, common.DryRunNone)
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	revisions, err := History(updated)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	assert.Equal(t, 1, revisions[0].Revision)
+	assert.Equal(t, 1, revisions[0].Count)
+	assert.Equal(t, ActuationSummary{Succeeded: 1}, revisions[0].Summary)
+}
@@ -4,11 +4,13 @@
 package inventory
 
 import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 )
 
 var (
 	_ ClientFactory = ClusterClientFactory{}
+	_ ClientFactory = SecretClusterClientFactory{}
 )
 
 // ClientFactory is a factory that constructs new Client instances.
@@ -19,8 +21,41 @@ type ClientFactory interface {
 // ClusterClientFactory is a factory that creates instances of ClusterClient inventory client.
 type ClusterClientFactory struct {
 	StatusPolicy StatusPolicy
+	// FieldManager is the field manager used to write the inventory
+	// objects. Optional.
+	FieldManager string
 }
 
 func (ccf ClusterClientFactory) NewClient(factory cmdutil.Factory) (Client, error) {
-	return NewClient(factory, WrapInventoryObj, InvInfoToConfigMap, ccf.StatusPolicy, ConfigMapGVK)
+	return newClusterClient(factory, WrapInventoryObj, InvInfoToConfigMap, ConfigMapGVK,
+		ccf.StatusPolicy, ccf.FieldManager)
+}
+
+// SecretClusterClientFactory is a factory that creates instances of
+// ClusterClient inventory client, which store the inventory in a Secret
+// instead of a ConfigMap.
+type SecretClusterClientFactory struct {
+	StatusPolicy StatusPolicy
+	// FieldManager is the field manager used to write the inventory
+	// objects. Optional.
+	FieldManager string
+}
+
+func (scf SecretClusterClientFactory) NewClient(factory cmdutil.Factory) (Client, error) {
+	return newClusterClient(factory, WrapSecretInventoryObj, InvInfoToSecret, SecretGVK,
+		scf.StatusPolicy, scf.FieldManager)
+}
+
+// newClusterClient returns a ClusterClient storing the inventory in
+// objects of the passed kind, written with the passed field manager.
+func newClusterClient(factory cmdutil.Factory, storageFunc StorageFactoryFunc, infoFunc ToUnstructuredFunc,
+	gvk schema.GroupVersionKind, statusPolicy StatusPolicy, fieldManager string) (Client, error) {
+	client, err := NewClient(factory, storageFunc, infoFunc, statusPolicy, gvk)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.SetFieldManager(fieldManager); err != nil {
+		return nil, err
+	}
+	return client, nil
 }
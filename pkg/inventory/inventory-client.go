@@ -6,6 +6,7 @@ package inventory
 import (
 	"context"
 	"fmt"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -59,6 +60,8 @@ type ClusterClient struct {
 	invToUnstructuredFunc ToUnstructuredFunc
 	statusPolicy          StatusPolicy
 	gvk                   schema.GroupVersionKind
+	template              ObjectTemplate
+	historyLimit          int
 }
 
 var _ Client = &ClusterClient{}
@@ -79,20 +82,84 @@ func NewClient(factory cmdutil.Factory,
 	if err != nil {
 		return nil, err
 	}
-	discoveryClinet, err := factory.ToDiscoveryClient()
+	discoveryClient, err := factory.ToDiscoveryClient()
 	if err != nil {
 		return nil, err
 	}
-	clusterClient := ClusterClient{
+	return NewClusterClient(dc, mapper, discoveryClient, invFunc, invToUnstructuredFunc, statusPolicy, gvk), nil
+}
+
+// NewClusterClient returns a ClusterClient that uses the passed clients,
+// for callers that do not have a cmdutil.Factory, like controllers.
+func NewClusterClient(dc dynamic.Interface,
+	mapper meta.RESTMapper,
+	discoveryClient discovery.CachedDiscoveryInterface,
+	invFunc StorageFactoryFunc,
+	invToUnstructuredFunc ToUnstructuredFunc,
+	statusPolicy StatusPolicy,
+	gvk schema.GroupVersionKind,
+) *ClusterClient {
+	return &ClusterClient{
 		dc:                    dc,
-		discoveryClient:       discoveryClinet,
+		discoveryClient:       discoveryClient,
 		mapper:                mapper,
 		InventoryFactoryFunc:  invFunc,
 		invToUnstructuredFunc: invToUnstructuredFunc,
 		statusPolicy:          statusPolicy,
 		gvk:                   gvk,
 	}
-	return &clusterClient, nil
+}
+
+// SetFieldManager sets the field manager used to create and update the
+// inventory objects. The default field manager of the API server is used
+// if it is empty.
+func (cic *ClusterClient) SetFieldManager(fieldManager string) error {
+	if err := common.ValidateFieldManager(fieldManager); err != nil {
+		return err
+	}
+	cic.dc = WithFieldManager(cic.dc, fieldManager)
+	return nil
+}
+
+// SetObjectTemplate sets the additional labels, annotations and owner
+// references written on the inventory object when it is created or updated.
+func (cic *ClusterClient) SetObjectTemplate(template ObjectTemplate) error {
+	if err := template.Validate(); err != nil {
+		return err
+	}
+	cic.template = template
+	return nil
+}
+
+// SetHistoryLimit enables the history of the applies of the inventory,
+// keeping at most limit revisions in the HistoryAnnotation of the inventory
+// object. A revision is recorded each time the inventory is replaced at the
+// end of an apply. The history is disabled if limit is zero.
+func (cic *ClusterClient) SetHistoryLimit(limit int) error {
+	if limit < 0 {
+		return fmt.Errorf("inventory history limit must not be negative: %d", limit)
+	}
+	cic.historyLimit = limit
+	return nil
+}
+
+// GetHistory returns the revisions of the applies of the inventory, oldest
+// first, or nil if the inventory has no history.
+func (cic *ClusterClient) GetHistory(inv Info) ([]Revision, error) {
+	clusterInv, err := cic.GetClusterInventoryInfo(inv)
+	if err != nil {
+		return nil, err
+	}
+	if clusterInv == nil {
+		return nil, nil
+	}
+	return History(clusterInv)
+}
+
+// wrap returns the storage of the inventory object, with the metadata of
+// the object template.
+func (cic *ClusterClient) wrap(obj *unstructured.Unstructured) Storage {
+	return cic.InventoryFactoryFunc(cic.template.apply(obj))
 }
 
 // Merge stores the union of the passed objects with the objects currently
@@ -117,7 +184,7 @@ func (cic *ClusterClient) Merge(localInv Info, objs object.ObjMetadataSet, dryRu
 		if cic.statusPolicy == StatusPolicyAll {
 			status = getObjStatus(nil, objs)
 		}
-		inv := cic.InventoryFactoryFunc(invObj)
+		inv := cic.wrap(invObj)
 		if err := inv.Store(objs, status); err != nil {
 			return nil, err
 		}
@@ -145,7 +212,7 @@ func (cic *ClusterClient) Merge(localInv Info, objs object.ObjMetadataSet, dryRu
 	}
 	klog.V(4).Infof("num objects to prune: %d", len(pruneIds))
 	klog.V(4).Infof("num merged objects to store in inventory: %d", len(unionObjs))
-	wrappedInv := cic.InventoryFactoryFunc(clusterInv)
+	wrappedInv := cic.wrap(clusterInv)
 	if err = wrappedInv.Store(unionObjs, status); err != nil {
 		return pruneIds, err
 	}
@@ -153,7 +220,7 @@ func (cic *ClusterClient) Merge(localInv Info, objs object.ObjMetadataSet, dryRu
 	// Update not required when all objects in inventory are the same and
 	// status does not need to be updated. If status is stored, always update the
 	// inventory to store the latest status.
-	if objs.Equal(clusterObjs) && cic.statusPolicy == StatusPolicyNone {
+	if objs.Equal(clusterObjs) && cic.statusPolicy == StatusPolicyNone && !cic.template.changes(clusterInv) {
 		return pruneIds, nil
 	}
 
@@ -184,6 +251,13 @@ func (cic *ClusterClient) Replace(localInv Info, objs object.ObjMetadataSet, sta
 		return fmt.Errorf("failed to read inventory objects from cluster: %w", err)
 	}
 
+	templateChanges := cic.template.changes(clusterInv)
+	if cic.historyLimit > 0 && clusterInv != nil {
+		clusterInv, err = recordRevision(clusterInv, cic.historyLimit, time.Now(), clusterObjs, objs, status)
+		if err != nil {
+			return err
+		}
+	}
 	clusterInv, wrappedInv, err := cic.replaceInventory(clusterInv, objs, status)
 	if err != nil {
 		return err
@@ -192,7 +266,7 @@ func (cic *ClusterClient) Replace(localInv Info, objs object.ObjMetadataSet, sta
 	// Update not required when all objects in inventory are the same and
 	// status does not need to be updated. If status is stored, always update the
 	// inventory to store the latest status.
-	if objs.Equal(clusterObjs) && cic.statusPolicy == StatusPolicyNone {
+	if objs.Equal(clusterObjs) && cic.statusPolicy == StatusPolicyNone && !templateChanges && cic.historyLimit == 0 {
 		return nil
 	}
 
@@ -212,7 +286,7 @@ func (cic *ClusterClient) replaceInventory(inv *unstructured.Unstructured, objs
 	if cic.statusPolicy == StatusPolicyNone {
 		status = nil
 	}
-	wrappedInv := cic.InventoryFactoryFunc(inv)
+	wrappedInv := cic.wrap(inv)
 	if err := wrappedInv.Store(objs, status); err != nil {
 		return nil, nil, err
 	}
@@ -284,10 +358,18 @@ func (cic *ClusterClient) GetClusterInventoryInfo(inv Info) (*unstructured.Unstr
 	}
 
 	var clusterInv *unstructured.Unstructured
-	if len(clusterInvObjects) == 1 {
+	if len(clusterInvObjects) == 1 && !isShard(clusterInvObjects[0]) {
 		clusterInv = clusterInvObjects[0]
-	} else if l := len(clusterInvObjects); l > 1 {
-		return nil, fmt.Errorf("found %d inventory objects with inventory id %s", l, inv.ID())
+	} else if l := len(clusterInvObjects); l > 0 {
+		// Multiple objects are expected if the inventory is sharded.
+		merged, sharded, err := mergeShards(clusterInvObjects)
+		if err != nil {
+			return nil, err
+		}
+		if !sharded {
+			return nil, fmt.Errorf("found %d inventory objects with inventory id %s", l, inv.ID())
+		}
+		clusterInv = merged
 	}
 	return clusterInv, nil
 }
@@ -381,12 +463,17 @@ func (cic *ClusterClient) ListClusterInventoryObjs(ctx context.Context) (map[str
 		return map[string]object.ObjMetadataSet{}, nil
 	}
 
+	invs, err := mergeListedShards(clusterInvs.Items)
+	if err != nil {
+		return nil, err
+	}
+
 	identifiers := make(map[string]object.ObjMetadataSet)
 
-	for i, inv := range clusterInvs.Items {
+	for _, inv := range invs {
 		invName := inv.GetName()
 		identifiers[invName] = object.ObjMetadataSet{}
-		wrappedInvObjSlice, err := cic.InventoryFactoryFunc(&clusterInvs.Items[i]).Load()
+		wrappedInvObjSlice, err := cic.InventoryFactoryFunc(inv).Load()
 		if err != nil {
 			return nil, err
 		}
@@ -10,6 +10,7 @@ import (
 
 	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/reason"
 )
 
 const noInventoryErrorStr = `Package uninitialized. Please run "init" command.
@@ -41,6 +42,14 @@ func (e *NoInventoryObjError) Is(err error) bool {
 	return ok
 }
 
+func (e *NoInventoryObjError) ErrorReason() reason.Reason {
+	return reason.NoInventory
+}
+
+func (e *NoInventoryObjError) ErrorRetryable() bool {
+	return false
+}
+
 type MultipleInventoryObjError struct {
 	InventoryObjectTemplates object.UnstructuredSet
 }
@@ -62,6 +71,14 @@ func (e *MultipleInventoryObjError) Is(err error) bool {
 	return e.InventoryObjectTemplates.Equal(tErr.InventoryObjectTemplates)
 }
 
+func (e *MultipleInventoryObjError) ErrorReason() reason.Reason {
+	return reason.MultipleInventories
+}
+
+func (e *MultipleInventoryObjError) ErrorRetryable() bool {
+	return false
+}
+
 type PolicyPreventedActuationError struct {
 	Strategy actuation.ActuationStrategy
 	Policy   Policy
@@ -87,3 +104,11 @@ func (e *PolicyPreventedActuationError) Is(err error) bool {
 		e.Policy == tErr.Policy &&
 		e.Status == tErr.Status
 }
+
+func (e *PolicyPreventedActuationError) ErrorReason() reason.Reason {
+	return reason.InventoryPolicy
+}
+
+func (e *PolicyPreventedActuationError) ErrorRetryable() bool {
+	return false
+}
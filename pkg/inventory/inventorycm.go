@@ -12,6 +12,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -35,14 +36,14 @@ var ConfigMapGVK = schema.GroupVersionKind{
 // wraps it with the ConfigMap and upcasts the wrapper as
 // an the Inventory interface.
 func WrapInventoryObj(inv *unstructured.Unstructured) Storage {
-	return &ConfigMap{inv: inv}
+	return &ConfigMap{wrappedInventory{inv: inv}}
 }
 
 // WrapInventoryInfoObj takes a passed ConfigMap (as a resource.Info),
 // wraps it with the ConfigMap and upcasts the wrapper as
 // an the Info interface.
 func WrapInventoryInfoObj(inv *unstructured.Unstructured) Info {
-	return &ConfigMap{inv: inv}
+	return &ConfigMap{wrappedInventory{inv: inv}}
 }
 
 func InvInfoToConfigMap(inv Info) *unstructured.Unstructured {
@@ -53,44 +54,41 @@ func InvInfoToConfigMap(inv Info) *unstructured.Unstructured {
 	return nil
 }
 
-// ConfigMap wraps a ConfigMap resource and implements
-// the Inventory interface. This wrapper loads and stores the
-// object metadata (inventory) to and from the wrapped ConfigMap.
-type ConfigMap struct {
+// wrappedInventory holds the wrapped inventory object and the object
+// metadata to store in it. It implements the functions shared by the
+// ConfigMap and the Secret, which use the same keys in "data".
+type wrappedInventory struct {
 	inv       *unstructured.Unstructured
 	objMetas  object.ObjMetadataSet
 	objStatus []actuation.ObjectStatus
 }
 
-var _ Info = &ConfigMap{}
-var _ Storage = &ConfigMap{}
-
-func (icm *ConfigMap) Name() string {
-	return icm.inv.GetName()
+func (w *wrappedInventory) Name() string {
+	return w.inv.GetName()
 }
 
-func (icm *ConfigMap) Namespace() string {
-	return icm.inv.GetNamespace()
+func (w *wrappedInventory) Namespace() string {
+	return w.inv.GetNamespace()
 }
 
-func (icm *ConfigMap) ID() string {
+func (w *wrappedInventory) ID() string {
 	// Empty string if not set.
-	return icm.inv.GetLabels()[common.InventoryLabel]
+	return w.inv.GetLabels()[common.InventoryLabel]
 }
 
-func (icm *ConfigMap) Strategy() Strategy {
+func (w *wrappedInventory) Strategy() Strategy {
 	return LabelStrategy
 }
 
-func (icm *ConfigMap) UnstructuredInventory() *unstructured.Unstructured {
-	return icm.inv
+func (w *wrappedInventory) UnstructuredInventory() *unstructured.Unstructured {
+	return w.inv
 }
 
 // Load is an Inventory interface function returning the set of
-// object metadata from the wrapped ConfigMap, or an error.
-func (icm *ConfigMap) Load() (object.ObjMetadataSet, error) {
+// object metadata from the wrapped inventory object, or an error.
+func (w *wrappedInventory) Load() (object.ObjMetadataSet, error) {
 	objs := object.ObjMetadataSet{}
-	objMap, exists, err := unstructured.NestedStringMap(icm.inv.Object, "data")
+	objMap, exists, err := unstructured.NestedStringMap(w.inv.Object, "data")
 	if err != nil {
 		err := fmt.Errorf("error retrieving object metadata from inventory object")
 		return objs, err
@@ -108,14 +106,35 @@ func (icm *ConfigMap) Load() (object.ObjMetadataSet, error) {
 }
 
 // Store is an Inventory interface function implemented to store
-// the object metadata in the wrapped ConfigMap. Actual storing
+// the object metadata in the wrapped inventory object. Actual storing
 // happens in "GetObject".
-func (icm *ConfigMap) Store(objMetas object.ObjMetadataSet, status []actuation.ObjectStatus) error {
-	icm.objMetas = objMetas
-	icm.objStatus = status
+func (w *wrappedInventory) Store(objMetas object.ObjMetadataSet, status []actuation.ObjectStatus) error {
+	w.objMetas = objMetas
+	w.objStatus = status
 	return nil
 }
 
+// ConfigMap wraps a ConfigMap resource and implements
+// the Inventory interface. This wrapper loads and stores the
+// object metadata (inventory) to and from the wrapped ConfigMap.
+type ConfigMap struct {
+	wrappedInventory
+}
+
+var _ Info = &ConfigMap{}
+var _ Storage = &ConfigMap{}
+var _ StatusLoader = &ConfigMap{}
+
+// LoadStatus is a StatusLoader interface function returning the stored
+// actuation and reconcile status of the objects in the wrapped ConfigMap.
+func (icm *ConfigMap) LoadStatus() ([]actuation.ObjectStatus, error) {
+	objMap, _, err := unstructured.NestedStringMap(icm.inv.Object, "data")
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving object status from inventory object")
+	}
+	return parseObjMap(objMap)
+}
+
 // GetObject returns the wrapped object (ConfigMap) as a resource.Info
 // or an error if one occurs.
 func (icm *ConfigMap) GetObject() (*unstructured.Unstructured, error) {
@@ -134,65 +153,105 @@ func (icm *ConfigMap) GetObject() (*unstructured.Unstructured, error) {
 
 // Apply is an Storage interface function implemented to apply the inventory
 // object. StatusPolicy is not needed since ConfigMaps do not have a status subresource.
+// Inventories that are too large for a single ConfigMap are sharded.
 func (icm *ConfigMap) Apply(dc dynamic.Interface, mapper meta.RESTMapper, _ StatusPolicy) error {
-	invInfo, namespacedClient, err := icm.getNamespacedClient(dc, mapper)
+	invInfo, err := icm.GetObject()
 	if err != nil {
 		return err
 	}
-
-	// Get cluster object, if exsists.
-	clusterObj, err := namespacedClient.Get(context.TODO(), invInfo.GetName(), metav1.GetOptions{})
-	if err != nil && !apierrors.IsNotFound(err) {
+	namespacedClient, err := inventoryClient(dc, mapper, invInfo)
+	if err != nil {
 		return err
 	}
-
-	// Create cluster inventory object, if it does not exist on cluster.
-	if clusterObj == nil {
-		klog.V(4).Infof("creating inventory object: %s/%s", invInfo.GetNamespace(), invInfo.GetName())
-		_, err = namespacedClient.Create(context.TODO(), invInfo, metav1.CreateOptions{})
+	shards, err := shardInventory(invInfo)
+	if err != nil {
 		return err
 	}
+	invInfo = shards[0]
 
-	// Update the cluster inventory object instead.
-	klog.V(4).Infof("updating inventory object: %s/%s", invInfo.GetNamespace(), invInfo.GetName())
-	_, err = namespacedClient.Update(context.TODO(), invInfo, metav1.UpdateOptions{})
-	return err
+	// Apply the shards before the primary, which records the shard count.
+	if err := applyShards(namespacedClient, invInfo, shards[1:]); err != nil {
+		return err
+	}
+	if err := createOrUpdate(namespacedClient, invInfo); err != nil {
+		return err
+	}
+	// Shards may be left behind by an inventory that was deleted
+	// while they were written.
+	return deleteStaleShards(namespacedClient, invInfo)
 }
 
 // ApplyWithPrune is a Storage interface function implemented to apply the inventory object with a list of objects
 // to be pruned. StatusPolicy is not needed since ConfigMaps do not have a status subresource.
+// Inventories that are too large for a single ConfigMap are sharded.
 func (icm *ConfigMap) ApplyWithPrune(dc dynamic.Interface, mapper meta.RESTMapper, _ StatusPolicy, _ object.ObjMetadataSet) error {
-	invInfo, namespacedClient, err := icm.getNamespacedClient(dc, mapper)
+	invInfo, err := icm.GetObject()
+	if err != nil {
+		return err
+	}
+	namespacedClient, err := inventoryClient(dc, mapper, invInfo)
+	if err != nil {
+		return err
+	}
+	shards, err := shardInventory(invInfo)
 	if err != nil {
 		return err
 	}
+	invInfo = shards[0]
 
-	// Update the cluster inventory object.
-	klog.V(4).Infof("updating inventory object: %s/%s", invInfo.GetNamespace(), invInfo.GetName())
-	_, err = namespacedClient.Update(context.TODO(), invInfo, metav1.UpdateOptions{})
-	return err
-}
+	// Apply the shards before the primary, which records the shard count.
+	if err := applyShards(namespacedClient, invInfo, shards[1:]); err != nil {
+		return err
+	}
 
-// getNamespacedClient is a helper function for Apply and ApplyWithPrune that creates a namespaced client for interacting with the live
-// cluster, as well as returning the ConfigMap object as a wrapped resource.Info object.
-func (icm *ConfigMap) getNamespacedClient(dc dynamic.Interface, mapper meta.RESTMapper) (*unstructured.Unstructured, dynamic.ResourceInterface, error) {
-	invInfo, err := icm.GetObject()
-	if err != nil {
-		return nil, nil, err
+	if err := update(namespacedClient, invInfo); err != nil {
+		return err
 	}
+	return deleteStaleShards(namespacedClient, invInfo)
+}
+
+// inventoryClient is a helper function for Apply and ApplyWithPrune that
+// creates a namespaced client for interacting with the inventory object
+// in the live cluster.
+func inventoryClient(dc dynamic.Interface, mapper meta.RESTMapper, invInfo *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
 	if invInfo == nil {
-		return nil, nil, fmt.Errorf("attempting to create a nil inventory object")
+		return nil, fmt.Errorf("attempting to create a nil inventory object")
 	}
 
 	mapping, err := mapper.RESTMapping(invInfo.GroupVersionKind().GroupKind(), invInfo.GroupVersionKind().Version)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	// Create client to interact with cluster.
-	namespacedClient := dc.Resource(mapping.Resource).Namespace(invInfo.GetNamespace())
+	return dc.Resource(mapping.Resource).Namespace(invInfo.GetNamespace()), nil
+}
+
+// createOrUpdate creates the inventory object in the cluster, if it does
+// not exist, and updates it otherwise.
+func createOrUpdate(namespacedClient dynamic.ResourceInterface, invInfo *unstructured.Unstructured) error {
+	// Get cluster object, if exsists.
+	clusterObj, err := namespacedClient.Get(context.TODO(), invInfo.GetName(), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	// Create cluster inventory object, if it does not exist on cluster.
+	if clusterObj == nil {
+		klog.V(4).Infof("creating inventory object: %s/%s", invInfo.GetNamespace(), invInfo.GetName())
+		_, err = namespacedClient.Create(context.TODO(), invInfo, metav1.CreateOptions{})
+		return err
+	}
 
-	return invInfo, namespacedClient, nil
+	// Update the cluster inventory object instead.
+	return update(namespacedClient, invInfo)
+}
+
+// update updates the inventory object in the cluster.
+func update(namespacedClient dynamic.ResourceInterface, invInfo *unstructured.Unstructured) error {
+	klog.V(4).Infof("updating inventory object: %s/%s", invInfo.GetNamespace(), invInfo.GetName())
+	_, err := namespacedClient.Update(context.TODO(), invInfo, metav1.UpdateOptions{})
+	return err
 }
 
 func buildObjMap(objMetas object.ObjMetadataSet, objStatus []actuation.ObjectStatus) map[string]string {
@@ -224,3 +283,67 @@ func stringFrom(status actuation.ObjectStatus) string {
 	}
 	return string(data)
 }
+
+// parseObjMap returns the object statuses stored in the values of the
+// objMap, sorted by object. Objects without a stored status are skipped.
+func parseObjMap(objMap map[string]string) ([]actuation.ObjectStatus, error) {
+	var ids object.ObjMetadataSet
+	for objStr, value := range objMap {
+		if value == "" {
+			continue
+		}
+		id, err := object.ParseObjMetadata(objStr)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	var statuses []actuation.ObjectStatus
+	for _, id := range ids {
+		status, err := statusFrom(objMap[id.String()])
+		if err != nil {
+			return nil, fmt.Errorf("invalid status of object %s in inventory: %w", id, err)
+		}
+		status.ObjectReference = ObjectReferenceFromObjMetadata(id)
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// statusFrom parses the status encoded by stringFrom.
+func statusFrom(value string) (actuation.ObjectStatus, error) {
+	var tmp map[string]string
+	if err := json.Unmarshal([]byte(value), &tmp); err != nil {
+		return actuation.ObjectStatus{}, err
+	}
+	var status actuation.ObjectStatus
+	var found bool
+	for s := actuation.ActuationStrategyApply; s <= actuation.ActuationStrategyDelete; s++ {
+		if s.String() == tmp["strategy"] {
+			status.Strategy, found = s, true
+		}
+	}
+	if !found {
+		return status, fmt.Errorf("unknown strategy %q", tmp["strategy"])
+	}
+	found = false
+	for s := actuation.ActuationPending; s <= actuation.ActuationFailed; s++ {
+		if s.String() == tmp["actuation"] {
+			status.Actuation, found = s, true
+		}
+	}
+	if !found {
+		return status, fmt.Errorf("unknown actuation %q", tmp["actuation"])
+	}
+	found = false
+	for s := actuation.ReconcilePending; s <= actuation.ReconcileTimeout; s++ {
+		if s.String() == tmp["reconcile"] {
+			status.Reconcile, found = s, true
+		}
+	}
+	if !found {
+		return status, fmt.Errorf("unknown reconcile %q", tmp["reconcile"])
+	}
+	return status, nil
+}
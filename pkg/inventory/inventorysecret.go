@@ -0,0 +1,128 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// Introduces the Secret struct which implements the Inventory
+// interface. The Secret wraps a Secret resource which stores the
+// set of inventory (object metadata). Use it instead of the ConfigMap
+// when the inventory should only be readable by users with access
+// to Secrets.
+
+package inventory
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+var SecretGVK = schema.GroupVersionKind{
+	Group:   "",
+	Kind:    "Secret",
+	Version: "v1",
+}
+
+// WrapSecretInventoryObj takes a passed Secret, wraps it with the
+// Secret and upcasts the wrapper as an the Storage interface.
+// It can be used as the StorageFactoryFunc of a ClusterClient.
+func WrapSecretInventoryObj(inv *unstructured.Unstructured) Storage {
+	return &Secret{wrappedInventory{inv: inv}}
+}
+
+// WrapSecretInventoryInfoObj takes a passed Secret, wraps it with the
+// Secret and upcasts the wrapper as an the Info interface.
+func WrapSecretInventoryInfoObj(inv *unstructured.Unstructured) Info {
+	return &Secret{wrappedInventory{inv: inv}}
+}
+
+func InvInfoToSecret(inv Info) *unstructured.Unstructured {
+	is, ok := inv.(*Secret)
+	if ok {
+		return is.inv
+	}
+	return nil
+}
+
+// Secret wraps a Secret resource and implements the Inventory
+// interface. This wrapper loads and stores the object metadata
+// (inventory) to and from the wrapped Secret. The keys are the same
+// as for the ConfigMap, while the values are base64 encoded.
+type Secret struct {
+	wrappedInventory
+}
+
+var _ Info = &Secret{}
+var _ Storage = &Secret{}
+var _ StatusLoader = &Secret{}
+
+// LoadStatus is a StatusLoader interface function returning the stored
+// actuation and reconcile status of the objects in the wrapped Secret.
+func (is *Secret) LoadStatus() ([]actuation.ObjectStatus, error) {
+	objMap, _, err := unstructured.NestedStringMap(is.inv.Object, "data")
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving object status from inventory object")
+	}
+	for key, value := range objMap {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status of object %s in inventory: %w", key, err)
+		}
+		objMap[key] = string(decoded)
+	}
+	return parseObjMap(objMap)
+}
+
+// GetObject returns the wrapped object (Secret) as a resource.Info
+// or an error if one occurs.
+func (is *Secret) GetObject() (*unstructured.Unstructured, error) {
+	// Create the objMap of all the resources, and encode the values.
+	objMap := buildObjMap(is.objMetas, is.objStatus)
+	for key, value := range objMap {
+		objMap[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	// Create the inventory object by copying the template.
+	invCopy := is.inv.DeepCopy()
+	// Remove any plaintext data, since it would be merged into "data"
+	// by the apiserver.
+	unstructured.RemoveNestedField(invCopy.UnstructuredContent(), "stringData")
+	// Adds the inventory map to the Secret "data" section.
+	err := unstructured.SetNestedStringMap(invCopy.UnstructuredContent(),
+		objMap, "data")
+	if err != nil {
+		return nil, err
+	}
+	return invCopy, nil
+}
+
+// Apply is an Storage interface function implemented to apply the inventory
+// object. StatusPolicy is not needed since Secrets do not have a status subresource.
+func (is *Secret) Apply(dc dynamic.Interface, mapper meta.RESTMapper, _ StatusPolicy) error {
+	invInfo, err := is.GetObject()
+	if err != nil {
+		return err
+	}
+	namespacedClient, err := inventoryClient(dc, mapper, invInfo)
+	if err != nil {
+		return err
+	}
+	return createOrUpdate(namespacedClient, invInfo)
+}
+
+// ApplyWithPrune is a Storage interface function implemented to apply the inventory object with a list of objects
+// to be pruned. StatusPolicy is not needed since Secrets do not have a status subresource.
+func (is *Secret) ApplyWithPrune(dc dynamic.Interface, mapper meta.RESTMapper, _ StatusPolicy, _ object.ObjMetadataSet) error {
+	invInfo, err := is.GetObject()
+	if err != nil {
+		return err
+	}
+	namespacedClient, err := inventoryClient(dc, mapper, invInfo)
+	if err != nil {
+		return err
+	}
+	return update(namespacedClient, invInfo)
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestSecretStoreLoad(t *testing.T) {
+	obj1 := actuation.ObjectReference{
+		Group:     "group1",
+		Kind:      "Kind",
+		Namespace: "ns",
+		Name:      "na",
+	}
+	obj2 := actuation.ObjectReference{
+		Group:     "group2",
+		Kind:      "Kind",
+		Namespace: "ns",
+		Name:      "na",
+	}
+
+	inv := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name":      "inventory",
+				"namespace": "ns",
+				"labels": map[string]interface{}{
+					common.InventoryLabel: "inventory-id",
+				},
+			},
+			"stringData": map[string]interface{}{
+				"stale": "value",
+			},
+		},
+	}
+
+	info := WrapSecretInventoryInfoObj(inv)
+	assert.Equal(t, "inventory", info.Name())
+	assert.Equal(t, "ns", info.Namespace())
+	assert.Equal(t, "inventory-id", info.ID())
+
+	objSet := object.ObjMetadataSet{
+		ObjMetadataFromObjectReference(obj1),
+		ObjMetadataFromObjectReference(obj2),
+	}
+	storage := WrapSecretInventoryObj(inv)
+	err := storage.Store(objSet, []actuation.ObjectStatus{
+		{
+			ObjectReference: obj1,
+			Strategy:        actuation.ActuationStrategyApply,
+			Actuation:       actuation.ActuationSucceeded,
+			Reconcile:       actuation.ReconcilePending,
+		},
+	})
+	require.NoError(t, err)
+
+	secret, err := storage.GetObject()
+	require.NoError(t, err)
+
+	data, found, err := unstructured.NestedStringMap(secret.Object, "data")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, map[string]string{
+		"ns_na_group1_Kind": base64.StdEncoding.EncodeToString(
+			[]byte(`{"actuation":"Succeeded","reconcile":"Pending","strategy":"Apply"}`)),
+		"ns_na_group2_Kind": "",
+	}, data)
+	_, found, err = unstructured.NestedFieldNoCopy(secret.Object, "stringData")
+	require.NoError(t, err)
+	assert.False(t, found, "stringData should be removed")
+
+	loaded, err := WrapSecretInventoryObj(secret).Load()
+	require.NoError(t, err)
+	assert.True(t, objSet.Equal(loaded), "expected %v, got %v", objSet, loaded)
+}
+
+func TestInvInfoToSecret(t *testing.T) {
+	inv := &unstructured.Unstructured{}
+	assert.Same(t, inv, InvInfoToSecret(WrapSecretInventoryInfoObj(inv)))
+	assert.Nil(t, InvInfoToSecret(WrapInventoryInfoObj(inv)))
+}
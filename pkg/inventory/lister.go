@@ -0,0 +1,155 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// StatusLoader is implemented by the Storage of inventory objects that
+// store the status of their objects.
+type StatusLoader interface {
+	// LoadStatus returns the stored status of the objects of the inventory.
+	// Objects without a stored status are omitted.
+	LoadStatus() ([]actuation.ObjectStatus, error)
+}
+
+// Summary summarizes an inventory found in the cluster.
+type Summary struct {
+	Namespace string
+	Name      string
+	// ID is the value of the inventory label.
+	ID string
+	// Objects are the objects in the inventory.
+	Objects object.ObjMetadataSet
+	// Statuses are the stored actuation and reconcile status of the objects,
+	// if the inventory stores them (StatusPolicyAll).
+	Statuses []actuation.ObjectStatus
+	// LastApplied is the last time the inventory object was written, which
+	// happens at the end of each apply that changed the inventory. It is
+	// zero if unknown.
+	LastApplied time.Time
+}
+
+// ObjectCount returns the number of objects in the inventory.
+func (s Summary) ObjectCount() int {
+	return len(s.Objects)
+}
+
+// ListOptions filter the inventories listed by a Lister.
+type ListOptions struct {
+	// Namespace of the inventories. All namespaces if empty.
+	Namespace string
+	// LabelSelector additionally selects the inventory objects by their
+	// labels, e.g. labels added by an ObjectTemplate.
+	LabelSelector string
+}
+
+// Lister lists the inventories of a cluster, e.g. to list all the packages
+// that have been applied.
+type Lister struct {
+	Client dynamic.Interface
+	Mapper meta.RESTMapper
+	// GVK is the kind of the inventory objects. Defaults to ConfigMapGVK.
+	GVK schema.GroupVersionKind
+	// StorageFactoryFunc wraps the inventory objects. Defaults to
+	// WrapInventoryObj.
+	StorageFactoryFunc StorageFactoryFunc
+}
+
+// List returns the summaries of the inventories with the inventory label,
+// sorted by namespace and name. Shards of sharded inventories are merged
+// into their inventory.
+func (l *Lister) List(ctx context.Context, opts ListOptions) ([]Summary, error) {
+	gvk := l.GVK
+	if gvk.Empty() {
+		gvk = ConfigMapGVK
+	}
+	wrap := l.StorageFactoryFunc
+	if wrap == nil {
+		wrap = WrapInventoryObj
+	}
+	mapping, err := l.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	selector := common.InventoryLabel
+	if opts.LabelSelector != "" {
+		selector += "," + opts.LabelSelector
+	}
+	list, err := l.Client.Resource(mapping.Resource).Namespace(opts.Namespace).
+		List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inventories: %w", err)
+	}
+	// Shards are the latest written objects of a sharded inventory.
+	lastWritten := make(map[string]time.Time)
+	for i := range list.Items {
+		obj := &list.Items[i]
+		key := obj.GetNamespace() + "/" + obj.GetLabels()[common.InventoryLabel]
+		if t := lastWriteTime(obj); t.After(lastWritten[key]) {
+			lastWritten[key] = t
+		}
+	}
+	invs, err := mergeListedShards(list.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]Summary, 0, len(invs))
+	for _, inv := range invs {
+		id := inv.GetLabels()[common.InventoryLabel]
+		storage := wrap(inv)
+		objs, err := storage.Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load inventory %s/%s: %w", inv.GetNamespace(), inv.GetName(), err)
+		}
+		sort.Slice(objs, func(i, j int) bool { return objs[i].String() < objs[j].String() })
+		summary := Summary{
+			Namespace:   inv.GetNamespace(),
+			Name:        inv.GetName(),
+			ID:          id,
+			Objects:     objs,
+			LastApplied: lastWritten[inv.GetNamespace()+"/"+id],
+		}
+		if loader, ok := storage.(StatusLoader); ok {
+			summary.Statuses, err = loader.LoadStatus()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load inventory %s/%s: %w", inv.GetNamespace(), inv.GetName(), err)
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Namespace != summaries[j].Namespace {
+			return summaries[i].Namespace < summaries[j].Namespace
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries, nil
+}
+
+// lastWriteTime returns the latest time of the managed fields of the
+// object, or its creation time if it has no managed fields.
+func lastWriteTime(obj *unstructured.Unstructured) time.Time {
+	t := obj.GetCreationTimestamp().Time
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Time != nil && entry.Time.After(t) {
+			t = entry.Time.Time
+		}
+	}
+	return t
+}
@@ -0,0 +1,115 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func listedInventory(namespace, name, id string, labels map[string]string, written time.Time, data map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	l := map[string]string{}
+	if id != "" {
+		l[common.InventoryLabel] = id
+	}
+	for k, v := range labels {
+		l[k] = v
+	}
+	obj.SetLabels(l)
+	obj.SetCreationTimestamp(metav1.NewTime(written.Add(-time.Hour)))
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{{
+		Manager: "kapply",
+		Time:    &metav1.Time{Time: written},
+	}})
+	_ = unstructured.SetNestedStringMap(obj.Object, data, "data")
+	return obj
+}
+
+func TestLister_List(t *testing.T) {
+	written := time.Date(2022, 10, 1, 12, 0, 0, 0, time.Local)
+	pod1 := ignoreErrInfoToObjMeta(pod1Info)
+	pod2 := ignoreErrInfoToObjMeta(pod2Info)
+	objs := []runtime.Object{
+		listedInventory("a", "inv-a", "id-a", map[string]string{"app": "foo"}, written, map[string]string{
+			pod1.String(): `{"actuation":"Succeeded","reconcile":"Succeeded","strategy":"Apply"}`,
+			pod2.String(): "",
+		}),
+		listedInventory("b", "inv-b", "id-b", nil, written.Add(time.Minute), map[string]string{
+			pod2.String(): "",
+		}),
+		// Not an inventory.
+		listedInventory("a", "other", "", nil, written, map[string]string{"key": "value"}),
+	}
+	lister := &Lister{
+		Client: fake.NewSimpleDynamicClient(scheme.Scheme, objs...),
+		Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+			scheme.Scheme.PrioritizedVersionsAllGroups()...),
+	}
+
+	summaries, err := lister.List(context.TODO(), ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, Summary{
+		Namespace: "a",
+		Name:      "inv-a",
+		ID:        "id-a",
+		Objects:   object.ObjMetadataSet{pod1, pod2},
+		Statuses: []actuation.ObjectStatus{{
+			ObjectReference: ObjectReferenceFromObjMetadata(pod1),
+			Strategy:        actuation.ActuationStrategyApply,
+			Actuation:       actuation.ActuationSucceeded,
+			Reconcile:       actuation.ReconcileSucceeded,
+		}},
+		LastApplied: written,
+	}, summaries[0])
+	assert.Equal(t, 2, summaries[0].ObjectCount())
+	assert.Equal(t, "inv-b", summaries[1].Name)
+	assert.Empty(t, summaries[1].Statuses)
+	assert.Equal(t, written.Add(time.Minute), summaries[1].LastApplied)
+
+	summaries, err = lister.List(context.TODO(), ListOptions{LabelSelector: "app=foo"})
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "inv-a", summaries[0].Name)
+
+	summaries, err = lister.List(context.TODO(), ListOptions{Namespace: "b"})
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "inv-b", summaries[0].Name)
+}
+
+func TestStatusFrom(t *testing.T) {
+	status, err := statusFrom(stringFrom(actuation.ObjectStatus{
+		Strategy:  actuation.ActuationStrategyDelete,
+		Actuation: actuation.ActuationFailed,
+		Reconcile: actuation.ReconcileTimeout,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, actuation.ObjectStatus{
+		Strategy:  actuation.ActuationStrategyDelete,
+		Actuation: actuation.ActuationFailed,
+		Reconcile: actuation.ReconcileTimeout,
+	}, status)
+
+	_, err = statusFrom(`{"actuation":"Done","reconcile":"Succeeded","strategy":"Apply"}`)
+	assert.EqualError(t, err, `unknown actuation "Done"`)
+}
@@ -0,0 +1,163 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/reason"
+)
+
+// Migrator moves the objects stored in one inventory object to another,
+// e.g. when the name, the inventory ID or the type of the inventory changes.
+type Migrator struct {
+	// SourceClient is used to read and delete the source inventory.
+	SourceClient Client
+	// TargetClient is used to write the target inventory. It may differ
+	// from the SourceClient when migrating to another inventory type.
+	TargetClient Client
+	// DynamicClient is used to update the owning-inventory annotation of
+	// the objects.
+	DynamicClient dynamic.Interface
+	// Mapper is used to map object identifiers to resources.
+	Mapper meta.RESTMapper
+}
+
+// MigrationResult describes the objects handled by a migration.
+type MigrationResult struct {
+	// Migrated are the objects moved to the target inventory.
+	Migrated object.ObjMetadataSet
+	// NotFound are the objects in the source inventory that no longer exist
+	// in the cluster. They are not stored in the target inventory.
+	NotFound object.ObjMetadataSet
+	// Conflicts are the objects in the source inventory that are owned by
+	// another inventory. They are neither updated nor stored in the target
+	// inventory.
+	Conflicts object.ObjMetadataSet
+}
+
+// Migrate moves the objects of the from inventory to the to inventory.
+// The target inventory is written and read back first, then the
+// owning-inventory annotation of each object is updated and finally the
+// source inventory is deleted. If the migration fails, both inventories
+// reference the objects and it is safe to run the migration again.
+//
+// Migrating to an inventory with the same name or ID as the source is
+// refused, since deleting the source would delete the only inventory
+// holding the objects.
+func (m *Migrator) Migrate(ctx context.Context, from, to Info, dryRun common.DryRunStrategy) (*MigrationResult, error) {
+	if err := validateMigration(from, to); err != nil {
+		return nil, err
+	}
+	ids, err := m.SourceClient.GetClusterObjs(from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source inventory: %w", err)
+	}
+
+	result := &MigrationResult{}
+	for _, id := range ids {
+		client, err := object.ResourceClient(m.DynamicClient, m.Mapper, id)
+		if err != nil {
+			return nil, err
+		}
+		live, err := client.Get(ctx, id.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				result.NotFound = append(result.NotFound, id)
+				continue
+			}
+			return nil, reason.ForObject(id, fmt.Errorf("failed to get object %s: %w", id, err))
+		}
+		if IDMatch(from, live) == NoMatch && IDMatch(to, live) != Match {
+			result.Conflicts = append(result.Conflicts, id)
+			continue
+		}
+		result.Migrated = append(result.Migrated, id)
+	}
+
+	if _, err := m.TargetClient.Merge(to, result.Migrated, dryRun); err != nil {
+		return nil, fmt.Errorf("failed to write target inventory: %w", err)
+	}
+	if !dryRun.ClientOrServerDryRun() {
+		if err := m.verifyTarget(to, result.Migrated); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, id := range result.Migrated {
+		if err := m.updateOwner(ctx, id, to, dryRun); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.SourceClient.DeleteInventoryObj(from, dryRun); err != nil {
+		return nil, fmt.Errorf("failed to delete source inventory: %w", err)
+	}
+	return result, nil
+}
+
+// validateMigration returns an error if the from and to inventories are
+// the same inventory object or have the same inventory ID.
+func validateMigration(from, to Info) error {
+	if from.Namespace() == to.Namespace() && from.Name() == to.Name() {
+		return fmt.Errorf("cannot migrate inventory %s/%s to itself", from.Namespace(), from.Name())
+	}
+	if from.ID() != "" && from.ID() == to.ID() {
+		return fmt.Errorf("cannot migrate inventory %s/%s to inventory %s/%s with the same ID %q",
+			from.Namespace(), from.Name(), to.Namespace(), to.Name(), from.ID())
+	}
+	return nil
+}
+
+// verifyTarget returns an error if the target inventory does not store all
+// the migrated objects, so the source inventory is not deleted unless the
+// objects are stored in the target.
+func (m *Migrator) verifyTarget(to Info, migrated object.ObjMetadataSet) error {
+	stored, err := m.TargetClient.GetClusterObjs(to)
+	if err != nil {
+		return fmt.Errorf("failed to read target inventory: %w", err)
+	}
+	if missing := migrated.Diff(stored); len(missing) > 0 {
+		return fmt.Errorf("target inventory %s/%s is missing %d migrated objects",
+			to.Namespace(), to.Name(), len(missing))
+	}
+	return nil
+}
+
+// updateOwner sets the owning-inventory annotation of the object to the ID
+// of the passed inventory.
+func (m *Migrator) updateOwner(ctx context.Context, id object.ObjMetadata, inv Info, dryRun common.DryRunStrategy) error {
+	client, err := object.ResourceClient(m.DynamicClient, m.Mapper, id)
+	if err != nil {
+		return err
+	}
+	live, err := client.Get(ctx, id.Name, metav1.GetOptions{})
+	if err != nil {
+		return reason.ForObject(id, fmt.Errorf("failed to get object %s: %w", id, err))
+	}
+	if IDMatch(inv, live) == Match {
+		return nil
+	}
+	if dryRun.ClientDryRun() {
+		klog.V(4).Infof("dry-run update owning inventory of %s: not updated", id)
+		return nil
+	}
+	AddInventoryIDAnnotation(live, inv)
+	opts := metav1.UpdateOptions{}
+	if dryRun.ServerDryRun() {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	if _, err := client.Update(ctx, live, opts); err != nil {
+		return reason.ForObject(id, fmt.Errorf("failed to update owning inventory of %s: %w", id, err))
+	}
+	return nil
+}
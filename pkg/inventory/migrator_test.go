@@ -0,0 +1,210 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func podWithOwner(name, owner string) *unstructured.Unstructured {
+	pod := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": testNamespace,
+			},
+		},
+	}
+	if owner != "" {
+		pod.SetAnnotations(map[string]string{OwningInventoryKey: owner})
+	}
+	return pod
+}
+
+func TestMigrator_Migrate(t *testing.T) {
+	target := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "new-inventory-obj",
+				"namespace": testNamespace,
+				"labels": map[string]interface{}{
+					common.InventoryLabel: "new-label",
+				},
+			},
+		},
+	}
+	targetInv := WrapInventoryInfoObj(target)
+
+	owned := podWithOwner("owned", testInventoryLabel)
+	unowned := podWithOwner("unowned", "")
+	conflict := podWithOwner("conflict", "other")
+	missing := podWithOwner("missing", testInventoryLabel)
+	ownedID := object.UnstructuredToObjMetadata(owned)
+	unownedID := object.UnstructuredToObjMetadata(unowned)
+	conflictID := object.UnstructuredToObjMetadata(conflict)
+	missingID := object.UnstructuredToObjMetadata(missing)
+
+	testCases := map[string]struct {
+		dryRun         common.DryRunStrategy
+		expectedTarget object.ObjMetadataSet
+		expectedOwners map[string]string
+	}{
+		"migrate": {
+			dryRun:         common.DryRunNone,
+			expectedTarget: object.ObjMetadataSet{ownedID, unownedID},
+			expectedOwners: map[string]string{
+				"owned":    "new-label",
+				"unowned":  "new-label",
+				"conflict": "other",
+			},
+		},
+		"client dry-run": {
+			dryRun:         common.DryRunClient,
+			expectedTarget: object.ObjMetadataSet{ownedID, unownedID},
+			expectedOwners: map[string]string{
+				"owned":    testInventoryLabel,
+				"unowned":  "",
+				"conflict": "other",
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme,
+				[]runtime.Object{owned.DeepCopy(), unowned.DeepCopy(), conflict.DeepCopy()}...)
+			sourceClient := NewFakeClient(object.ObjMetadataSet{ownedID, unownedID, conflictID, missingID})
+			targetClient := NewFakeClient(object.ObjMetadataSet{})
+			migrator := &Migrator{
+				SourceClient:  sourceClient,
+				TargetClient:  targetClient,
+				DynamicClient: client,
+				Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+					scheme.Scheme.PrioritizedVersionsAllGroups()...),
+			}
+
+			result, err := migrator.Migrate(context.TODO(), localInv, targetInv, tc.dryRun)
+			require.NoError(t, err)
+			assert.Equal(t, &MigrationResult{
+				Migrated:  object.ObjMetadataSet{ownedID, unownedID},
+				NotFound:  object.ObjMetadataSet{missingID},
+				Conflicts: object.ObjMetadataSet{conflictID},
+			}, result)
+			assert.Equal(t, tc.expectedTarget, targetClient.Objs)
+
+			pods := client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "pods"}).
+				Namespace(testNamespace)
+			for name, owner := range tc.expectedOwners {
+				pod, err := pods.Get(context.TODO(), name, metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, owner, pod.GetAnnotations()[OwningInventoryKey], name)
+			}
+		})
+	}
+}
+
+// deleteRecordingClient is a FakeClient that records whether the inventory
+// was deleted.
+type deleteRecordingClient struct {
+	*FakeClient
+	deleted bool
+}
+
+func (c *deleteRecordingClient) DeleteInventoryObj(inv Info, dryRun common.DryRunStrategy) error {
+	c.deleted = true
+	return c.FakeClient.DeleteInventoryObj(inv, dryRun)
+}
+
+// lostWriteClient is a FakeClient that does not store the merged objects.
+type lostWriteClient struct {
+	*FakeClient
+}
+
+func (c *lostWriteClient) Merge(_ Info, _ object.ObjMetadataSet, _ common.DryRunStrategy) (object.ObjMetadataSet, error) {
+	return object.ObjMetadataSet{}, nil
+}
+
+func TestMigrator_MigrateFailure(t *testing.T) {
+	owned := podWithOwner("owned", testInventoryLabel)
+	ownedID := object.UnstructuredToObjMetadata(owned)
+
+	testCases := map[string]struct {
+		to            Info
+		targetClient  Client
+		expectedError string
+	}{
+		"same name": {
+			to:            localInv,
+			targetClient:  NewFakeClient(object.ObjMetadataSet{}),
+			expectedError: "cannot migrate inventory test-inventory-namespace/test-inventory-obj to itself",
+		},
+		"same ID": {
+			to:           WrapInventoryInfoObj(renamedWithID(t, testInventoryLabel)),
+			targetClient: NewFakeClient(object.ObjMetadataSet{}),
+			expectedError: "cannot migrate inventory test-inventory-namespace/test-inventory-obj to inventory " +
+				"test-inventory-namespace/renamed-inventory-obj with the same ID \"test-app-label\"",
+		},
+		"target write fails": {
+			to:            WrapInventoryInfoObj(renamedWithID(t, "new-label")),
+			targetClient:  &FakeClient{Err: errors.New("forbidden")},
+			expectedError: "failed to write target inventory: forbidden",
+		},
+		"target write lost": {
+			to:            WrapInventoryInfoObj(renamedWithID(t, "new-label")),
+			targetClient:  &lostWriteClient{FakeClient: NewFakeClient(object.ObjMetadataSet{})},
+			expectedError: "target inventory test-inventory-namespace/renamed-inventory-obj is missing 1 migrated objects",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, owned.DeepCopy())
+			sourceClient := &deleteRecordingClient{FakeClient: NewFakeClient(object.ObjMetadataSet{ownedID})}
+			migrator := &Migrator{
+				SourceClient:  sourceClient,
+				TargetClient:  tc.targetClient,
+				DynamicClient: client,
+				Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+					scheme.Scheme.PrioritizedVersionsAllGroups()...),
+			}
+
+			_, err := migrator.Migrate(context.TODO(), localInv, tc.to, common.DryRunNone)
+			require.EqualError(t, err, tc.expectedError)
+			// The source inventory is kept, and still holds the objects.
+			assert.False(t, sourceClient.deleted)
+			assert.Equal(t, object.ObjMetadataSet{ownedID}, sourceClient.Objs)
+
+			pod, err := client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "pods"}).
+				Namespace(testNamespace).Get(context.TODO(), "owned", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, testInventoryLabel, pod.GetAnnotations()[OwningInventoryKey])
+		})
+	}
+}
+
+func renamedWithID(t *testing.T, id string) *unstructured.Unstructured {
+	t.Helper()
+	obj := inventoryObj.DeepCopy()
+	obj.SetName("renamed-inventory-obj")
+	obj.SetLabels(map[string]string{common.InventoryLabel: id})
+	return obj
+}
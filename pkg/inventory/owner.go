@@ -0,0 +1,45 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// OwnerLabel is the label of the objects stored next to an inventory object
+// on its behalf, e.g. the reports and checkpoints of its runs. The value of
+// the label is the inventory ID. Unlike common.InventoryLabel, it does not
+// make the objects inventory objects.
+const OwnerLabel = "cli-utils.sigs.k8s.io/owner-inventory-id"
+
+// SetOwner sets the OwnerLabel of the object to the ID of the inventory.
+func SetOwner(obj *unstructured.Unstructured, inv Info) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[OwnerLabel] = inv.ID()
+	obj.SetLabels(labels)
+}
+
+// IsOwner returns true if the OwnerLabel of the object is the ID of the
+// inventory.
+func IsOwner(inv Info, obj *unstructured.Unstructured) bool {
+	value, found := obj.GetLabels()[OwnerLabel]
+	return found && value == inv.ID()
+}
+
+// CheckOwner returns an error if the object stored next to the inventory
+// object is not owned by the inventory, e.g. because another inventory
+// with the same name and namespace, but a different ID, created it.
+func CheckOwner(inv Info, obj *unstructured.Unstructured) error {
+	if IsOwner(inv, obj) {
+		return nil
+	}
+	return fmt.Errorf("refusing to overwrite %s %s/%s: not owned by inventory %q (label %s=%q)",
+		obj.GetKind(), obj.GetNamespace(), obj.GetName(), inv.ID(),
+		OwnerLabel, obj.GetLabels()[OwnerLabel])
+}
@@ -0,0 +1,26 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwner(t *testing.T) {
+	inv := &fakeInventoryInfo{id: "random-id"}
+	other := &fakeInventoryInfo{id: "other-id"}
+
+	obj := testObjectWithAnnotation("", "")
+	assert.False(t, IsOwner(inv, obj))
+	assert.EqualError(t, CheckOwner(inv, obj),
+		`refusing to overwrite Deployment ns/foo: not owned by inventory "random-id" (label cli-utils.sigs.k8s.io/owner-inventory-id="")`)
+
+	SetOwner(obj, inv)
+	assert.True(t, IsOwner(inv, obj))
+	assert.NoError(t, CheckOwner(inv, obj))
+	assert.False(t, IsOwner(other, obj))
+	assert.Error(t, CheckOwner(other, obj))
+}
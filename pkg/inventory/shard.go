@@ -0,0 +1,349 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// Introduces sharding of ConfigMap inventory objects. The data of
+// an inventory that is too large for a single ConfigMap is split
+// across the inventory ConfigMap (the primary) and additional shard
+// ConfigMaps named "<name>-1".."<name>-N". Shards have the same
+// inventory label as the primary, so they are found and deleted
+// together with it, and record the name of the primary, so objects
+// that are not shards of the inventory are never overwritten or
+// deleted. The primary records the number of shards, so shards left
+// behind by an interrupted update are ignored on read.
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	// ShardIndexAnnotation is set on the shards of a sharded inventory
+	// object. The value is the index of the shard. The primary inventory
+	// object has index 0, but does not have the annotation.
+	ShardIndexAnnotation = "cli-utils.sigs.k8s.io/inventory-shard-index"
+	// ShardCountAnnotation is set on the primary inventory object if the
+	// inventory is sharded. The value is the total number of shards,
+	// including the primary.
+	ShardCountAnnotation = "cli-utils.sigs.k8s.io/inventory-shard-count"
+	// ShardOfAnnotation is set on the shards of a sharded inventory
+	// object. The value is the name of the primary inventory object.
+	ShardOfAnnotation = "cli-utils.sigs.k8s.io/inventory-shard-of"
+)
+
+// MaxShardDataSize is the maximum size in bytes of the data stored in a
+// single inventory ConfigMap. It leaves room for the metadata of the
+// object within the 1MiB limit imposed by etcd.
+var MaxShardDataSize = 800 * 1024
+
+// splitObjMap splits the passed inventory data into shards, each with a
+// data size of at most maxSize bytes. Keys are assigned to shards in
+// sorted order, so the result is deterministic. Always returns at least
+// one shard.
+func splitObjMap(objMap map[string]string, maxSize int) []map[string]string {
+	keys := make([]string, 0, len(objMap))
+	for key := range objMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	shards := []map[string]string{{}}
+	size := 0
+	for _, key := range keys {
+		entrySize := len(key) + len(objMap[key])
+		if size > 0 && size+entrySize > maxSize {
+			shards = append(shards, map[string]string{})
+			size = 0
+		}
+		shards[len(shards)-1][key] = objMap[key]
+		size += entrySize
+	}
+	return shards
+}
+
+// shardName returns the name of the shard with the given index.
+func shardName(primaryName string, index int) string {
+	return fmt.Sprintf("%s-%d", primaryName, index)
+}
+
+// shardIndex returns the shard index of the passed inventory object, or
+// 0 if the object is not a shard.
+func shardIndex(obj *unstructured.Unstructured) (int, error) {
+	value, found := obj.GetAnnotations()[ShardIndexAnnotation]
+	if !found {
+		return 0, nil
+	}
+	index, err := strconv.Atoi(value)
+	if err != nil || index < 1 {
+		return 0, fmt.Errorf("invalid %q annotation on inventory object %s/%s: %q",
+			ShardIndexAnnotation, obj.GetNamespace(), obj.GetName(), value)
+	}
+	return index, nil
+}
+
+// isShard returns true if the passed object is the shard of an inventory.
+func isShard(obj *unstructured.Unstructured) bool {
+	_, found := obj.GetAnnotations()[ShardIndexAnnotation]
+	return found
+}
+
+// isShardOf returns true if the passed object is a shard of the primary
+// inventory object.
+func isShardOf(obj, primary *unstructured.Unstructured) bool {
+	id := primary.GetLabels()[common.InventoryLabel]
+	return id != "" &&
+		obj.GetLabels()[common.InventoryLabel] == id &&
+		obj.GetAnnotations()[ShardOfAnnotation] == primary.GetName() &&
+		obj.GetAnnotations()[ShardIndexAnnotation] != ""
+}
+
+// shardCount returns the number of shards recorded on the passed primary
+// inventory object, or 1 if it is not sharded.
+func shardCount(obj *unstructured.Unstructured) (int, error) {
+	value, found := obj.GetAnnotations()[ShardCountAnnotation]
+	if !found {
+		return 1, nil
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 1 {
+		return 0, fmt.Errorf("invalid %q annotation on inventory object %s/%s: %q",
+			ShardCountAnnotation, obj.GetNamespace(), obj.GetName(), value)
+	}
+	return count, nil
+}
+
+// mergeShards merges the data of the passed inventory objects, which all
+// have the same inventory label, into a copy of the primary inventory
+// object. Returns false if the objects are not the shards of a single
+// inventory. Stale shards, with an index beyond the shard count recorded
+// on the primary, and shards of another primary are ignored. If all the
+// objects are shards, e.g. left behind by a deleted primary, the merged
+// object is nil.
+func mergeShards(objs object.UnstructuredSet) (*unstructured.Unstructured, bool, error) {
+	var primary *unstructured.Unstructured
+	shards := map[int]*unstructured.Unstructured{}
+	for _, obj := range objs {
+		index, err := shardIndex(obj)
+		if err != nil {
+			return nil, false, err
+		}
+		if index == 0 {
+			if primary != nil {
+				// More than one primary
+				return nil, false, nil
+			}
+			primary = obj
+			continue
+		}
+		shards[index] = obj
+	}
+	if primary == nil {
+		return nil, len(shards) > 0, nil
+	}
+	count, err := shardCount(primary)
+	if err != nil {
+		return nil, false, err
+	}
+
+	merged := primary.DeepCopy()
+	data, _, err := unstructured.NestedStringMap(merged.Object, "data")
+	if err != nil {
+		return nil, false, err
+	}
+	if data == nil {
+		data = map[string]string{}
+	}
+	for index := 1; index < count; index++ {
+		shard, found := shards[index]
+		if !found || shard.GetAnnotations()[ShardOfAnnotation] != primary.GetName() {
+			return nil, false, fmt.Errorf("inventory object %s/%s is missing shard %d of %d",
+				primary.GetNamespace(), primary.GetName(), index, count)
+		}
+		shardData, _, err := unstructured.NestedStringMap(shard.Object, "data")
+		if err != nil {
+			return nil, false, err
+		}
+		for key, value := range shardData {
+			data[key] = value
+		}
+	}
+	if err := unstructured.SetNestedStringMap(merged.Object, data, "data"); err != nil {
+		return nil, false, err
+	}
+	annos := merged.GetAnnotations()
+	delete(annos, ShardCountAnnotation)
+	merged.SetAnnotations(annos)
+	return merged, true, nil
+}
+
+// mergeListedShards returns the primary inventory objects of the listed
+// objects, with the data of their shards merged into them.
+func mergeListedShards(items []unstructured.Unstructured) (object.UnstructuredSet, error) {
+	// Group the shards of sharded inventories with their primary.
+	shards := make(map[string]object.UnstructuredSet)
+	var invs object.UnstructuredSet
+	for i := range items {
+		inv := &items[i]
+		index, err := shardIndex(inv)
+		if err != nil {
+			return nil, err
+		}
+		if index == 0 {
+			invs = append(invs, inv)
+			continue
+		}
+		key := inv.GetNamespace() + "/" + inv.GetLabels()[common.InventoryLabel]
+		shards[key] = append(shards[key], inv)
+	}
+	for i, inv := range invs {
+		key := inv.GetNamespace() + "/" + inv.GetLabels()[common.InventoryLabel]
+		if invShards, found := shards[key]; found {
+			merged, sharded, err := mergeShards(append(object.UnstructuredSet{inv}, invShards...))
+			if err != nil {
+				return nil, err
+			}
+			if sharded {
+				invs[i] = merged
+			}
+		}
+	}
+	return invs, nil
+}
+
+// newShard returns the shard with the given index and data for the passed
+// primary inventory object.
+func newShard(primary *unstructured.Unstructured, index int, data map[string]string) (*unstructured.Unstructured, error) {
+	shard := &unstructured.Unstructured{}
+	shard.SetGroupVersionKind(primary.GroupVersionKind())
+	shard.SetName(shardName(primary.GetName(), index))
+	shard.SetNamespace(primary.GetNamespace())
+	shard.SetLabels(primary.GetLabels())
+	shard.SetAnnotations(map[string]string{
+		ShardIndexAnnotation: strconv.Itoa(index),
+		ShardOfAnnotation:    primary.GetName(),
+	})
+	if err := unstructured.SetNestedStringMap(shard.Object, data, "data"); err != nil {
+		return nil, err
+	}
+	return shard, nil
+}
+
+// shardInventory splits the data of the passed inventory object into
+// shards. Returns the primary inventory object, with the shard count
+// annotation if sharded, followed by the additional shards.
+func shardInventory(inv *unstructured.Unstructured) (object.UnstructuredSet, error) {
+	data, _, err := unstructured.NestedStringMap(inv.Object, "data")
+	if err != nil {
+		return nil, err
+	}
+	shardData := splitObjMap(data, MaxShardDataSize)
+
+	primary := inv.DeepCopy()
+	if err := unstructured.SetNestedStringMap(primary.Object, shardData[0], "data"); err != nil {
+		return nil, err
+	}
+	annos := primary.GetAnnotations()
+	if len(shardData) > 1 {
+		if annos == nil {
+			annos = map[string]string{}
+		}
+		annos[ShardCountAnnotation] = strconv.Itoa(len(shardData))
+	} else {
+		delete(annos, ShardCountAnnotation)
+	}
+	primary.SetAnnotations(annos)
+
+	objs := object.UnstructuredSet{primary}
+	for index := 1; index < len(shardData); index++ {
+		shard, err := newShard(primary, index, shardData[index])
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, shard)
+	}
+	return objs, nil
+}
+
+// applyShards creates or updates the passed shards of the primary
+// inventory object. They must be applied before the primary, which records
+// the shard count.
+func applyShards(client dynamic.ResourceInterface, primary *unstructured.Unstructured, shards object.UnstructuredSet) error {
+	for _, shard := range shards {
+		if err := applyShard(client, primary, shard); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyShard creates or updates the passed shard. Returns an error if an
+// object with the name of the shard exists, but is not a shard of the
+// primary inventory object.
+func applyShard(client dynamic.ResourceInterface, primary, shard *unstructured.Unstructured) error {
+	clusterObj, err := client.Get(context.TODO(), shard.GetName(), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if apierrors.IsNotFound(err) {
+		klog.V(4).Infof("creating inventory shard: %s/%s", shard.GetNamespace(), shard.GetName())
+		_, err = client.Create(context.TODO(), shard, metav1.CreateOptions{})
+		return err
+	}
+	if !isShardOf(clusterObj, primary) {
+		return fmt.Errorf("refusing to overwrite %s/%s with a shard of inventory object %s: not a shard of the inventory",
+			shard.GetNamespace(), shard.GetName(), primary.GetName())
+	}
+	klog.V(4).Infof("updating inventory shard: %s/%s", shard.GetNamespace(), shard.GetName())
+	_, err = client.Update(context.TODO(), shard, metav1.UpdateOptions{})
+	return err
+}
+
+// deleteStaleShards deletes the shards of the passed primary inventory
+// object which are beyond the recorded shard count. Objects that are not
+// shards of the primary are never deleted.
+func deleteStaleShards(client dynamic.ResourceInterface, primary *unstructured.Unstructured) error {
+	id := primary.GetLabels()[common.InventoryLabel]
+	if id == "" {
+		return nil
+	}
+	count, err := shardCount(primary)
+	if err != nil {
+		return err
+	}
+	uList, err := client.List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", common.InventoryLabel, id),
+	})
+	if err != nil {
+		return err
+	}
+	for i := range uList.Items {
+		obj := &uList.Items[i]
+		if !isShardOf(obj, primary) {
+			continue
+		}
+		index, err := shardIndex(obj)
+		if err != nil {
+			return err
+		}
+		if index < count {
+			continue
+		}
+		klog.V(4).Infof("deleting stale inventory shard: %s/%s", obj.GetNamespace(), obj.GetName())
+		err = client.Delete(context.TODO(), obj.GetName(), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestSplitObjMap(t *testing.T) {
+	tests := map[string]struct {
+		objMap   map[string]string
+		maxSize  int
+		expected []map[string]string
+	}{
+		"empty data is a single empty shard": {
+			objMap:   map[string]string{},
+			maxSize:  10,
+			expected: []map[string]string{{}},
+		},
+		"data within the limit is a single shard": {
+			objMap:   map[string]string{"a": "1", "b": "2"},
+			maxSize:  10,
+			expected: []map[string]string{{"a": "1", "b": "2"}},
+		},
+		"data beyond the limit is split in key order": {
+			objMap:  map[string]string{"c": "3", "a": "1", "b": "2"},
+			maxSize: 4,
+			expected: []map[string]string{
+				{"a": "1", "b": "2"},
+				{"c": "3"},
+			},
+		},
+		"entries larger than the limit get their own shard": {
+			objMap:  map[string]string{"a": "1", "b": "22222"},
+			maxSize: 4,
+			expected: []map[string]string{
+				{"a": "1"},
+				{"b": "22222"},
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, splitObjMap(tc.objMap, tc.maxSize))
+		})
+	}
+}
+
+func TestMergeShards(t *testing.T) {
+	newInv := func(name string, annos map[string]string, data map[string]string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(ConfigMapGVK)
+		u.SetName(name)
+		u.SetNamespace(testNamespace)
+		u.SetAnnotations(annos)
+		_ = unstructured.SetNestedStringMap(u.Object, data, "data")
+		return u
+	}
+
+	tests := map[string]struct {
+		objs         object.UnstructuredSet
+		expectedData map[string]string
+		sharded      bool
+		isError      bool
+	}{
+		"multiple primaries are not shards": {
+			objs: object.UnstructuredSet{
+				newInv("inv-a", nil, map[string]string{"a": ""}),
+				newInv("inv-b", nil, map[string]string{"b": ""}),
+			},
+			sharded: false,
+		},
+		"shards are merged into the primary": {
+			objs: object.UnstructuredSet{
+				newInv("inv-1", map[string]string{ShardIndexAnnotation: "1", ShardOfAnnotation: "inv"}, map[string]string{"b": ""}),
+				newInv("inv", map[string]string{ShardCountAnnotation: "2"}, map[string]string{"a": ""}),
+			},
+			expectedData: map[string]string{"a": "", "b": ""},
+			sharded:      true,
+		},
+		"stale shards are ignored": {
+			objs: object.UnstructuredSet{
+				newInv("inv", map[string]string{ShardCountAnnotation: "2"}, map[string]string{"a": ""}),
+				newInv("inv-1", map[string]string{ShardIndexAnnotation: "1", ShardOfAnnotation: "inv"}, map[string]string{"b": ""}),
+				newInv("inv-2", map[string]string{ShardIndexAnnotation: "2", ShardOfAnnotation: "inv"}, map[string]string{"c": ""}),
+			},
+			expectedData: map[string]string{"a": "", "b": ""},
+			sharded:      true,
+		},
+		"missing shard is an error": {
+			objs: object.UnstructuredSet{
+				newInv("inv", map[string]string{ShardCountAnnotation: "3"}, map[string]string{"a": ""}),
+				newInv("inv-1", map[string]string{ShardIndexAnnotation: "1", ShardOfAnnotation: "inv"}, map[string]string{"b": ""}),
+			},
+			isError: true,
+		},
+		"shard of another primary is an error": {
+			objs: object.UnstructuredSet{
+				newInv("inv", map[string]string{ShardCountAnnotation: "2"}, map[string]string{"a": ""}),
+				newInv("inv-1", map[string]string{ShardIndexAnnotation: "1", ShardOfAnnotation: "other"}, map[string]string{"b": ""}),
+			},
+			isError: true,
+		},
+		"invalid shard index is an error": {
+			objs: object.UnstructuredSet{
+				newInv("inv", map[string]string{ShardCountAnnotation: "2"}, map[string]string{"a": ""}),
+				newInv("inv-1", map[string]string{ShardIndexAnnotation: "one", ShardOfAnnotation: "inv"}, map[string]string{"b": ""}),
+			},
+			isError: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			merged, sharded, err := mergeShards(tc.objs)
+			if tc.isError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.sharded, sharded)
+			if !sharded {
+				return
+			}
+			assert.Equal(t, "inv", merged.GetName())
+			assert.NotContains(t, merged.GetAnnotations(), ShardCountAnnotation)
+			data, _, err := unstructured.NestedStringMap(merged.Object, "data")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedData, data)
+		})
+	}
+}
+
+func TestShardedInventory(t *testing.T) {
+	oldMax := MaxShardDataSize
+	defer func() { MaxShardDataSize = oldMax }()
+	// Fits roughly two object references per shard.
+	MaxShardDataSize = 100
+
+	newObjs := func(n int) object.ObjMetadataSet {
+		var objs object.ObjMetadataSet
+		for i := 0; i < n; i++ {
+			objs = append(objs, object.ObjMetadata{
+				GroupKind: schema.GroupKind{Kind: "Pod"},
+				Name:      fmt.Sprintf("pod-%d", i),
+				Namespace: testNamespace,
+			})
+		}
+		return objs
+	}
+
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+
+	invClient, err := NewClient(tf,
+		WrapInventoryObj, InvInfoToConfigMap, StatusPolicyNone, ConfigMapGVK)
+	require.NoError(t, err)
+
+	listInventoryObjs := func() []unstructured.Unstructured {
+		uList, err := tf.FakeDynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).
+			Namespace(testNamespace).List(context.TODO(), metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%s=%s", common.InventoryLabel, testInventoryLabel),
+		})
+		require.NoError(t, err)
+		return uList.Items
+	}
+
+	// Initial apply creates the inventory with multiple shards.
+	objs := newObjs(10)
+	_, err = invClient.Merge(copyInventory(), objs, common.DryRunNone)
+	require.NoError(t, err)
+	assert.Greater(t, len(listInventoryObjs()), 1)
+
+	clusterObjs, err := invClient.GetClusterObjs(copyInventory())
+	require.NoError(t, err)
+	assert.True(t, objs.Equal(clusterObjs), "expected %v, got %v", objs, clusterObjs)
+
+	listed, err := invClient.ListClusterInventoryObjs(context.TODO())
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+	assert.True(t, objs.Equal(listed[inventoryObjName]), "expected %v, got %v", objs, listed[inventoryObjName])
+
+	// Replacing with fewer objects removes the stale shards.
+	objs = newObjs(1)
+	err = invClient.Replace(copyInventory(), objs, nil, common.DryRunNone)
+	require.NoError(t, err)
+	invObjs := listInventoryObjs()
+	require.Len(t, invObjs, 1)
+	assert.NotContains(t, invObjs[0].GetAnnotations(), ShardCountAnnotation)
+
+	clusterObjs, err = invClient.GetClusterObjs(copyInventory())
+	require.NoError(t, err)
+	assert.True(t, objs.Equal(clusterObjs), "expected %v, got %v", objs, clusterObjs)
+}
+
+func TestShardedInventory_Ownership(t *testing.T) {
+	oldMax := MaxShardDataSize
+	defer func() { MaxShardDataSize = oldMax }()
+	MaxShardDataSize = 100
+
+	var objs object.ObjMetadataSet
+	for i := 0; i < 10; i++ {
+		objs = append(objs, object.ObjMetadata{
+			GroupKind: schema.GroupKind{Kind: "Pod"},
+			Name:      fmt.Sprintf("pod-%d", i),
+			Namespace: testNamespace,
+		})
+	}
+	newConfigMap := func(name string, labels map[string]string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(ConfigMapGVK)
+		u.SetName(name)
+		u.SetNamespace(testNamespace)
+		u.SetLabels(labels)
+		return u
+	}
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+	t.Run("existing object with the name of a shard is not overwritten", func(t *testing.T) {
+		tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+		defer tf.Cleanup()
+		client := tf.FakeDynamicClient.Resource(configMaps).Namespace(testNamespace)
+		_, err := client.Create(context.TODO(), newConfigMap(shardName(inventoryObjName, 1), nil), metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		invClient, err := NewClient(tf,
+			WrapInventoryObj, InvInfoToConfigMap, StatusPolicyNone, ConfigMapGVK)
+		require.NoError(t, err)
+		_, err = invClient.Merge(copyInventory(), objs, common.DryRunNone)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refusing to overwrite")
+
+		obj, err := client.Get(context.TODO(), shardName(inventoryObjName, 1), metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.NotContains(t, obj.GetAnnotations(), ShardIndexAnnotation)
+	})
+
+	t.Run("only stale shards of the inventory are deleted", func(t *testing.T) {
+		tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+		defer tf.Cleanup()
+		client := tf.FakeDynamicClient.Resource(configMaps).Namespace(testNamespace)
+		labels := map[string]string{common.InventoryLabel: testInventoryLabel}
+		// A shard left behind by a deleted inventory, and an object with
+		// the inventory label that is not a shard.
+		stale := newConfigMap(shardName(inventoryObjName, 5), labels)
+		stale.SetAnnotations(map[string]string{
+			ShardIndexAnnotation: "5",
+			ShardOfAnnotation:    inventoryObjName,
+		})
+		other := newConfigMap("other", labels)
+		other.SetAnnotations(map[string]string{ShardIndexAnnotation: "7"})
+		for _, obj := range []*unstructured.Unstructured{stale, other} {
+			_, err := client.Create(context.TODO(), obj, metav1.CreateOptions{})
+			require.NoError(t, err)
+		}
+
+		invClient, err := NewClient(tf,
+			WrapInventoryObj, InvInfoToConfigMap, StatusPolicyNone, ConfigMapGVK)
+		require.NoError(t, err)
+		_, err = invClient.Merge(copyInventory(), objs[:1], common.DryRunNone)
+		require.NoError(t, err)
+
+		_, err = client.Get(context.TODO(), stale.GetName(), metav1.GetOptions{})
+		assert.Error(t, err, "stale shard deleted")
+		_, err = client.Get(context.TODO(), other.GetName(), metav1.GetOptions{})
+		assert.NoError(t, err, "other object kept")
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/common"
+)
+
+// ObjectTemplate is the additional metadata written on the inventory object
+// itself, e.g. to select the inventory objects of an application, or to
+// garbage-collect the inventory object with the object that owns it.
+type ObjectTemplate struct {
+	// Labels are added to the labels of the inventory object. The inventory
+	// label cannot be set.
+	Labels map[string]string
+	// Annotations are added to the annotations of the inventory object.
+	Annotations map[string]string
+	// OwnerReferences are added to the owner references of the inventory
+	// object, unless it already has an owner reference with the same UID.
+	OwnerReferences []metav1.OwnerReference
+}
+
+// Validate returns an error if the template overrides the metadata that
+// identifies the inventory object.
+func (t ObjectTemplate) Validate() error {
+	if _, found := t.Labels[common.InventoryLabel]; found {
+		return fmt.Errorf("inventory template must not set the label %q", common.InventoryLabel)
+	}
+	for _, ref := range t.OwnerReferences {
+		if ref.UID == "" {
+			return fmt.Errorf("inventory template owner reference %s %q must have a UID", ref.Kind, ref.Name)
+		}
+	}
+	return nil
+}
+
+// empty returns true if the template does not add any metadata.
+func (t ObjectTemplate) empty() bool {
+	return len(t.Labels) == 0 && len(t.Annotations) == 0 && len(t.OwnerReferences) == 0
+}
+
+// apply returns a copy of the inventory object with the metadata of the
+// template. Labels and annotations of the template replace existing values.
+func (t ObjectTemplate) apply(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	if obj == nil || t.empty() {
+		return obj
+	}
+	obj = obj.DeepCopy()
+	if len(t.Labels) > 0 {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string, len(t.Labels))
+		}
+		for k, v := range t.Labels {
+			labels[k] = v
+		}
+		obj.SetLabels(labels)
+	}
+	if len(t.Annotations) > 0 {
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, len(t.Annotations))
+		}
+		for k, v := range t.Annotations {
+			annotations[k] = v
+		}
+		obj.SetAnnotations(annotations)
+	}
+	if len(t.OwnerReferences) > 0 {
+		refs := obj.GetOwnerReferences()
+		for _, ref := range t.OwnerReferences {
+			if !hasOwnerReference(refs, ref) {
+				refs = append(refs, ref)
+			}
+		}
+		obj.SetOwnerReferences(refs)
+	}
+	return obj
+}
+
+// changes returns true if applying the template changes the metadata of the
+// inventory object, so it must be updated even if the inventory is the same.
+func (t ObjectTemplate) changes(obj *unstructured.Unstructured) bool {
+	if obj == nil || t.empty() {
+		return false
+	}
+	return !equality.Semantic.DeepEqual(t.apply(obj).Object["metadata"], obj.Object["metadata"])
+}
+
+func hasOwnerReference(refs []metav1.OwnerReference, ref metav1.OwnerReference) bool {
+	for _, r := range refs {
+		if r.UID == ref.UID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+var appOwner = metav1.OwnerReference{
+	APIVersion: "example.com/v1",
+	Kind:       "Application",
+	Name:       "app",
+	UID:        "app-uid",
+}
+
+func TestObjectTemplate_Validate(t *testing.T) {
+	assert.NoError(t, ObjectTemplate{
+		Labels:          map[string]string{"app": "foo"},
+		OwnerReferences: []metav1.OwnerReference{appOwner},
+	}.Validate())
+	assert.EqualError(t, ObjectTemplate{
+		Labels: map[string]string{common.InventoryLabel: "other"},
+	}.Validate(), `inventory template must not set the label "cli-utils.sigs.k8s.io/inventory-id"`)
+	assert.EqualError(t, ObjectTemplate{
+		OwnerReferences: []metav1.OwnerReference{{Kind: "Application", Name: "app"}},
+	}.Validate(), `inventory template owner reference Application "app" must have a UID`)
+}
+
+func TestObjectTemplate_Apply(t *testing.T) {
+	template := ObjectTemplate{
+		Labels:          map[string]string{"app": "foo"},
+		Annotations:     map[string]string{"team": "platform"},
+		OwnerReferences: []metav1.OwnerReference{appOwner},
+	}
+	inv := copyInventoryInfo()
+	assert.True(t, template.changes(inv))
+
+	obj := template.apply(inv)
+	assert.Equal(t, map[string]string{
+		common.InventoryLabel: inv.GetLabels()[common.InventoryLabel],
+		"app":                 "foo",
+	}, obj.GetLabels())
+	assert.Equal(t, map[string]string{"team": "platform"}, obj.GetAnnotations())
+	assert.Equal(t, []metav1.OwnerReference{appOwner}, obj.GetOwnerReferences())
+	// The passed object is not modified.
+	assert.Equal(t, copyInventoryInfo(), inv)
+
+	// Applying the template again does not duplicate the owner reference.
+	assert.False(t, template.changes(obj))
+	assert.Equal(t, obj, template.apply(obj))
+
+	// An empty template does not change the object.
+	assert.False(t, ObjectTemplate{}.changes(inv))
+	assert.Same(t, inv, ObjectTemplate{}.apply(inv))
+}
+
+func TestClusterClient_SetObjectTemplate(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+
+	var created *unstructured.Unstructured
+	tf.FakeDynamicClient.PrependReactor("create", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		created = action.(clienttesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		return true, created, nil
+	})
+
+	invClient, err := NewClient(tf, WrapInventoryObj, InvInfoToConfigMap, StatusPolicyNone, ConfigMapGVK)
+	require.NoError(t, err)
+	assert.Error(t, invClient.SetObjectTemplate(ObjectTemplate{
+		Labels: map[string]string{common.InventoryLabel: "other"},
+	}))
+	require.NoError(t, invClient.SetObjectTemplate(ObjectTemplate{
+		Labels:          map[string]string{"app": "foo"},
+		OwnerReferences: []metav1.OwnerReference{appOwner},
+	}))
+
+	_, err = invClient.Merge(copyInventory(), object.ObjMetadataSet{ignoreErrInfoToObjMeta(pod1Info)}, common.DryRunNone)
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	assert.Equal(t, "foo", created.GetLabels()["app"])
+	assert.Equal(t, []metav1.OwnerReference{appOwner}, created.GetOwnerReferences())
+}
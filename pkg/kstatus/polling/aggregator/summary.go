@@ -0,0 +1,206 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package aggregator
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Condition is the condition an object is desired to reach.
+type Condition string
+
+const (
+	// ConditionCurrent is met when the object has the CurrentStatus.
+	ConditionCurrent Condition = "Current"
+	// ConditionExists is met when the object exists, regardless of whether
+	// it is reconciled.
+	ConditionExists Condition = "Exists"
+	// ConditionDeleted is met when the object has the NotFoundStatus.
+	ConditionDeleted Condition = "Deleted"
+)
+
+// DefaultSummaryInterval is the interval of Run if none is passed.
+const DefaultSummaryInterval = time.Second
+
+// Target is an object included in a summary.
+type Target struct {
+	Identifier object.ObjMetadata
+	// Condition is the desired condition of the object. Defaults to
+	// ConditionCurrent.
+	Condition Condition
+	// Weight is the weight of the object in the progress of the summary.
+	// Defaults to 1.
+	Weight int
+}
+
+// Summary is the aggregated status of a set of objects.
+type Summary struct {
+	// Status is FailedStatus if any object failed to reach its desired
+	// condition, UnknownStatus if the status of any other object is
+	// not known, CurrentStatus if all objects meet their desired condition
+	// and InProgressStatus otherwise.
+	Status status.Status
+	// Total is the sum of the weights of all objects.
+	Total int
+	// Met is the sum of the weights of the objects that meet their desired
+	// condition.
+	Met int
+	// Pending are the objects that do not meet their desired condition yet.
+	Pending object.ObjMetadataSet
+	// Failed are the objects that failed to reach their desired condition.
+	Failed object.ObjMetadataSet
+}
+
+// Progress returns the weighted fraction of objects that meet their desired
+// condition, between 0 and 1.
+func (s Summary) Progress() float64 {
+	if s.Total == 0 {
+		return 1
+	}
+	return float64(s.Met) / float64(s.Total)
+}
+
+// Aggregator tracks the status of a set of objects and summarizes it.
+// It is not safe for concurrent use.
+type Aggregator struct {
+	targets   []Target
+	targetIDs map[object.ObjMetadata]struct{}
+	statuses  map[object.ObjMetadata]status.Status
+}
+
+// NewAggregator returns an Aggregator for the passed targets.
+func NewAggregator(targets []Target) *Aggregator {
+	a := &Aggregator{
+		targets:   make([]Target, len(targets)),
+		targetIDs: make(map[object.ObjMetadata]struct{}, len(targets)),
+		statuses:  make(map[object.ObjMetadata]status.Status, len(targets)),
+	}
+	for i, t := range targets {
+		if t.Condition == "" {
+			t.Condition = ConditionCurrent
+		}
+		if t.Weight <= 0 {
+			t.Weight = 1
+		}
+		a.targets[i] = t
+		a.targetIDs[t.Identifier] = struct{}{}
+	}
+	return a
+}
+
+// Update records the status of an object. Statuses of objects that are not
+// targets are ignored.
+func (a *Aggregator) Update(rs *event.ResourceStatus) {
+	if _, found := a.targetIDs[rs.Identifier]; !found {
+		return
+	}
+	a.statuses[rs.Identifier] = rs.Status
+}
+
+// Summary returns the summary of the current status of all targets.
+func (a *Aggregator) Summary() Summary {
+	summary := Summary{}
+	anyUnknown := false
+	for _, t := range a.targets {
+		summary.Total += t.Weight
+		s, found := a.statuses[t.Identifier]
+		if !found {
+			s = status.UnknownStatus
+		}
+		switch {
+		case conditionMet(t.Condition, s):
+			summary.Met += t.Weight
+		case t.Condition == ConditionCurrent && s == status.FailedStatus:
+			summary.Failed = append(summary.Failed, t.Identifier)
+		default:
+			if s == status.UnknownStatus {
+				anyUnknown = true
+			}
+			summary.Pending = append(summary.Pending, t.Identifier)
+		}
+	}
+	switch {
+	case len(summary.Failed) > 0:
+		summary.Status = status.FailedStatus
+	case anyUnknown:
+		summary.Status = status.UnknownStatus
+	case len(summary.Pending) > 0:
+		summary.Status = status.InProgressStatus
+	default:
+		summary.Status = status.CurrentStatus
+	}
+	return summary
+}
+
+func conditionMet(c Condition, s status.Status) bool {
+	switch c {
+	case ConditionExists:
+		return s != status.NotFoundStatus && s != status.UnknownStatus
+	case ConditionDeleted:
+		return s == status.NotFoundStatus
+	default:
+		return s == status.CurrentStatus
+	}
+}
+
+// SummaryEvent is sent periodically by Run.
+type SummaryEvent struct {
+	Summary Summary
+	// Error is set if the status watcher reported an error.
+	Error error
+}
+
+// Run updates the Aggregator with the status events received on the passed
+// channel, and sends a SummaryEvent every interval, if the summary changed.
+// A final summary is sent when the status channel is closed. Error events
+// are sent as a SummaryEvent with the Error set. The interval defaults to
+// DefaultSummaryInterval. The returned channel is closed when the status
+// channel is closed or the context is cancelled.
+func (a *Aggregator) Run(ctx context.Context, statusCh <-chan event.Event, interval time.Duration) <-chan SummaryEvent {
+	if interval <= 0 {
+		interval = DefaultSummaryInterval
+	}
+	summaryCh := make(chan SummaryEvent)
+	go func() {
+		defer close(summaryCh)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		send := func(e SummaryEvent) {
+			select {
+			case summaryCh <- e:
+			case <-ctx.Done():
+			}
+		}
+		changed := false
+		for {
+			select {
+			case e, ok := <-statusCh:
+				if !ok {
+					send(SummaryEvent{Summary: a.Summary()})
+					return
+				}
+				switch e.Type {
+				case event.ResourceUpdateEvent:
+					a.Update(e.Resource)
+					changed = true
+				case event.ErrorEvent:
+					send(SummaryEvent{Summary: a.Summary(), Error: e.Error})
+				}
+			case <-ticker.C:
+				if changed {
+					send(SummaryEvent{Summary: a.Summary()})
+					changed = false
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return summaryCh
+}
@@ -0,0 +1,191 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestAggregator_Summary(t *testing.T) {
+	deployment := resourceIdentifiers["deployment"]
+	statefulSet := resourceIdentifiers["statefulset"]
+	service := resourceIdentifiers["service"]
+
+	testCases := map[string]struct {
+		targets         []Target
+		statuses        map[object.ObjMetadata]status.Status
+		expectedSummary Summary
+		expectedPercent float64
+	}{
+		"no targets": {
+			expectedSummary: Summary{Status: status.CurrentStatus},
+			expectedPercent: 1,
+		},
+		"no statuses yet": {
+			targets: []Target{
+				{Identifier: deployment},
+			},
+			expectedSummary: Summary{
+				Status:  status.UnknownStatus,
+				Total:   1,
+				Pending: object.ObjMetadataSet{deployment},
+			},
+			expectedPercent: 0,
+		},
+		"all conditions met": {
+			targets: []Target{
+				{Identifier: deployment, Condition: ConditionCurrent},
+				{Identifier: statefulSet, Condition: ConditionExists},
+				{Identifier: service, Condition: ConditionDeleted},
+			},
+			statuses: map[object.ObjMetadata]status.Status{
+				deployment:  status.CurrentStatus,
+				statefulSet: status.InProgressStatus,
+				service:     status.NotFoundStatus,
+			},
+			expectedSummary: Summary{
+				Status: status.CurrentStatus,
+				Total:  3,
+				Met:    3,
+			},
+			expectedPercent: 1,
+		},
+		"weighted progress": {
+			targets: []Target{
+				{Identifier: deployment, Weight: 3},
+				{Identifier: statefulSet},
+			},
+			statuses: map[object.ObjMetadata]status.Status{
+				deployment:  status.CurrentStatus,
+				statefulSet: status.InProgressStatus,
+			},
+			expectedSummary: Summary{
+				Status:  status.InProgressStatus,
+				Total:   4,
+				Met:     3,
+				Pending: object.ObjMetadataSet{statefulSet},
+			},
+			expectedPercent: 0.75,
+		},
+		"failed object": {
+			targets: []Target{
+				{Identifier: deployment},
+				{Identifier: statefulSet},
+			},
+			statuses: map[object.ObjMetadata]status.Status{
+				deployment:  status.FailedStatus,
+				statefulSet: status.UnknownStatus,
+			},
+			expectedSummary: Summary{
+				Status:  status.FailedStatus,
+				Total:   2,
+				Pending: object.ObjMetadataSet{statefulSet},
+				Failed:  object.ObjMetadataSet{deployment},
+			},
+			expectedPercent: 0,
+		},
+		"object not deleted yet": {
+			targets: []Target{
+				{Identifier: service, Condition: ConditionDeleted},
+			},
+			statuses: map[object.ObjMetadata]status.Status{
+				service: status.FailedStatus,
+			},
+			expectedSummary: Summary{
+				Status:  status.InProgressStatus,
+				Total:   1,
+				Pending: object.ObjMetadataSet{service},
+			},
+			expectedPercent: 0,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			a := NewAggregator(tc.targets)
+			for id, s := range tc.statuses {
+				a.Update(&event.ResourceStatus{Identifier: id, Status: s})
+			}
+			summary := a.Summary()
+			assert.Equal(t, tc.expectedSummary, summary)
+			assert.Equal(t, tc.expectedPercent, summary.Progress())
+		})
+	}
+}
+
+func TestAggregator_Run(t *testing.T) {
+	deployment := resourceIdentifiers["deployment"]
+	watchErr := errors.New("watch failed")
+
+	a := NewAggregator([]Target{{Identifier: deployment}})
+	statusCh := make(chan event.Event)
+	summaryCh := a.Run(context.Background(), statusCh, time.Hour)
+
+	go func() {
+		defer close(statusCh)
+		statusCh <- event.Event{
+			Type:     event.ResourceUpdateEvent,
+			Resource: &event.ResourceStatus{Identifier: deployment, Status: status.InProgressStatus},
+		}
+		statusCh <- event.Event{Type: event.ErrorEvent, Error: watchErr}
+		statusCh <- event.Event{
+			Type:     event.ResourceUpdateEvent,
+			Resource: &event.ResourceStatus{Identifier: deployment, Status: status.CurrentStatus},
+		}
+	}()
+
+	var received []SummaryEvent
+	for e := range summaryCh {
+		received = append(received, e)
+	}
+	require.Len(t, received, 2)
+	assert.Equal(t, SummaryEvent{
+		Summary: Summary{
+			Status:  status.InProgressStatus,
+			Total:   1,
+			Pending: object.ObjMetadataSet{deployment},
+		},
+		Error: watchErr,
+	}, received[0])
+	assert.Equal(t, SummaryEvent{
+		Summary: Summary{
+			Status: status.CurrentStatus,
+			Total:  1,
+			Met:    1,
+		},
+	}, received[1])
+}
+
+func TestAggregator_Update_IgnoresOtherObjects(t *testing.T) {
+	deployment := resourceIdentifiers["deployment"]
+	service := resourceIdentifiers["service"]
+
+	a := NewAggregator([]Target{{Identifier: deployment}})
+	a.Update(&event.ResourceStatus{Identifier: service, Status: status.CurrentStatus})
+	assert.Empty(t, a.statuses)
+}
+
+func TestAggregator_Run_Cancel(t *testing.T) {
+	a := NewAggregator([]Target{{Identifier: resourceIdentifiers["deployment"]}})
+	ctx, cancel := context.WithCancel(context.Background())
+	// The status channel is never closed, and the interval is defaulted.
+	summaryCh := a.Run(ctx, make(chan event.Event), 0)
+	cancel()
+
+	select {
+	case _, ok := <-summaryCh:
+		assert.False(t, ok, "summary channel closed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("summary channel not closed after cancel")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -57,6 +58,39 @@ var genGroupKinds = map[schema.GroupKind][]schema.GroupKind{
 // and namespace combinations it needs to cache when the Sync function is called.
 // We only want to fetch the resources that are actually needed.
 func NewCachingClusterReader(reader client.Reader, mapper meta.RESTMapper, identifiers object.ObjMetadataSet) (engine.ClusterReader, error) {
+	return newCachingClusterReaderWithOptions(reader, mapper, identifiers, CachingClusterReaderOptions{})
+}
+
+// CachingClusterReaderOptions tune the LIST calls of the CachingClusterReader,
+// so polling scales to large clusters.
+type CachingClusterReaderOptions struct {
+	// PageSize is the maximum number of resources returned by each LIST
+	// call. Defaults to 500.
+	PageSize int64
+
+	// LabelSelectors scope the LIST calls for resources of a GroupKind, e.g.
+	// to only list the Pods of the applied workloads instead of all the Pods
+	// of a namespace. Resources that don't match the selector are not found
+	// by the reader.
+	LabelSelectors map[schema.GroupKind]labels.Selector
+
+	// ResyncIntervals are the minimum intervals between LIST calls for the
+	// resources of a GroupKind. Syncs within the interval reuse the cached
+	// resources. Defaults to listing on every sync.
+	ResyncIntervals map[schema.GroupKind]time.Duration
+}
+
+// NewCachingClusterReaderFactory returns a factory of CachingClusterReaders
+// with the options.
+func NewCachingClusterReaderFactory(o CachingClusterReaderOptions) engine.ClusterReaderFactory {
+	return engine.ClusterReaderFactoryFunc(func(reader client.Reader, mapper meta.RESTMapper,
+		identifiers object.ObjMetadataSet) (engine.ClusterReader, error) {
+		return newCachingClusterReaderWithOptions(reader, mapper, identifiers, o)
+	})
+}
+
+func newCachingClusterReaderWithOptions(reader client.Reader, mapper meta.RESTMapper,
+	identifiers object.ObjMetadataSet, o CachingClusterReaderOptions) (*CachingClusterReader, error) {
 	gvkNamespaceSet := newGnSet()
 	for _, id := range identifiers {
 		// For every identifier, add the GroupVersionKind and namespace combination to the gvkNamespaceSet and
@@ -68,9 +102,12 @@ func NewCachingClusterReader(reader client.Reader, mapper meta.RESTMapper, ident
 	}
 
 	return &CachingClusterReader{
-		reader: reader,
-		mapper: mapper,
-		gns:    gvkNamespaceSet.gvkNamespaces,
+		reader:   reader,
+		mapper:   mapper,
+		gns:      gvkNamespaceSet.gvkNamespaces,
+		options:  o,
+		now:      time.Now,
+		lastSync: make(map[gkNamespace]time.Time),
 	}, nil
 }
 
@@ -134,6 +171,16 @@ type CachingClusterReader struct {
 	// of GVK and namespace. Before each polling cycle, the framework will call the
 	// Sync function, which is responsible for repopulating the cache.
 	cache map[gkNamespace]cacheEntry
+
+	// options tune the LIST calls.
+	options CachingClusterReaderOptions
+
+	// now returns the current time, to resync after the ResyncIntervals.
+	now func() time.Time
+
+	// lastSync is the time of the last successful LIST call for each of the
+	// cached GVK and namespace combinations.
+	lastSync map[gkNamespace]time.Time
 }
 
 type cacheEntry struct {
@@ -221,7 +268,12 @@ func (c *CachingClusterReader) Sync(ctx context.Context) error {
 	c.mx.Lock()
 	defer c.mx.Unlock()
 	cache := make(map[gkNamespace]cacheEntry)
+	now := c.now()
 	for _, gn := range c.gns {
+		if c.fresh(gn, now) {
+			cache[gn] = c.cache[gn]
+			continue
+		}
 		mapping, err := c.mapper.RESTMapping(gn.GroupKind)
 		if err != nil {
 			if meta.IsNoMatchError(err) {
@@ -241,7 +293,7 @@ func (c *CachingClusterReader) Sync(ctx context.Context) error {
 		if mapping.Scope == meta.RESTScopeNamespace {
 			ns = gn.Namespace
 		}
-		list, err := c.listUnstructured(ctx, mapping.GroupVersionKind, ns)
+		list, err := c.listUnstructured(ctx, mapping.GroupVersionKind, ns, c.options.LabelSelectors[gn.GroupKind])
 		if err != nil {
 			// If the context was cancelled, we just stop the work and return
 			// the error.
@@ -259,22 +311,51 @@ func (c *CachingClusterReader) Sync(ctx context.Context) error {
 		cache[gn] = cacheEntry{
 			resources: *list,
 		}
+		c.lastSync[gn] = now
 	}
 	c.cache = cache
 	return nil
 }
 
+// fresh returns true if the resources of the GVK and namespace combination
+// were listed within their resync interval, so they don't need to be listed
+// again. Errors are never cached across syncs.
+func (c *CachingClusterReader) fresh(gn gkNamespace, now time.Time) bool {
+	interval := c.options.ResyncIntervals[gn.GroupKind]
+	if interval <= 0 {
+		return false
+	}
+	lastSync, found := c.lastSync[gn]
+	if !found {
+		return false
+	}
+	entry, found := c.cache[gn]
+	if !found || entry.err != nil {
+		return false
+	}
+	return now.Sub(lastSync) < interval
+}
+
 // listUnstructured performs one or more LIST calls, paginating the requests
 // and aggregating the results.  If aggregated, only the ResourceVersion,
 // SelfLink, and Items will be populated. The default page size is 500.
+// If the selector is not nil, only the matching resources are listed.
 func (c *CachingClusterReader) listUnstructured(
 	ctx context.Context,
 	gvk schema.GroupVersionKind,
 	namespace string,
+	selector labels.Selector,
 ) (*unstructured.UnstructuredList, error) {
 	mOpts := metav1.ListOptions{}
 	mOpts.SetGroupVersionKind(gvk)
-	obj, _, err := pager.New(c.listPageFunc(namespace)).List(ctx, mOpts)
+	if selector != nil {
+		mOpts.LabelSelector = selector.String()
+	}
+	p := pager.New(c.listPageFunc(namespace))
+	if c.options.PageSize > 0 {
+		p.PageSize = c.options.PageSize
+	}
+	obj, _, err := p.List(ctx, mOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -20,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/cli-utils/pkg/testutil"
@@ -320,6 +322,57 @@ func TestSync_Errors(t *testing.T) {
 	}
 }
 
+func TestSync_Options(t *testing.T) {
+	identifiers := object.ObjMetadataSet{
+		{
+			GroupKind: deploymentGVK.GroupKind(),
+			Name:      "deployment",
+			Namespace: "foo",
+		},
+	}
+	deploymentGKN := gkNamespace{GroupKind: deploymentGVK.GroupKind(), Namespace: "foo"}
+	rsGKN := gkNamespace{GroupKind: rsGVK.GroupKind(), Namespace: "foo"}
+	podGKN := gkNamespace{GroupKind: podGVK.GroupKind(), Namespace: "foo"}
+	selector, err := labels.Parse("app=test")
+	require.NoError(t, err)
+
+	fakeReader := &fakeReader{}
+	fakeMapper := testutil.NewFakeRESTMapper(deploymentGVK, rsGVK, podGVK)
+	r, err := NewCachingClusterReaderFactory(CachingClusterReaderOptions{
+		PageSize: 100,
+		LabelSelectors: map[schema.GroupKind]labels.Selector{
+			podGVK.GroupKind(): selector,
+		},
+		ResyncIntervals: map[schema.GroupKind]time.Duration{
+			rsGVK.GroupKind():  time.Minute,
+			podGVK.GroupKind(): time.Minute,
+		},
+	}).New(fakeReader, fakeMapper, identifiers)
+	require.NoError(t, err)
+	clusterReader := r.(*CachingClusterReader)
+	now := time.Now()
+	clusterReader.now = func() time.Time { return now }
+
+	require.NoError(t, clusterReader.Sync(context.Background()))
+	assert.Equal(t, []gkNamespace{deploymentGKN, rsGKN, podGKN}, fakeReader.syncedGVKNamespaces)
+	assert.Equal(t, []int64{100, 100, 100}, fakeReader.syncedLimits)
+	assert.Equal(t, map[gkNamespace]string{podGKN: "app=test"}, fakeReader.syncedSelectors)
+
+	// Within the resync interval, only the deployments are listed again.
+	fakeReader.syncedGVKNamespaces = nil
+	now = now.Add(30 * time.Second)
+	require.NoError(t, clusterReader.Sync(context.Background()))
+	assert.Equal(t, []gkNamespace{deploymentGKN}, fakeReader.syncedGVKNamespaces)
+	_, found := clusterReader.cache[podGKN]
+	assert.True(t, found)
+
+	// After the resync interval, everything is listed again.
+	fakeReader.syncedGVKNamespaces = nil
+	now = now.Add(time.Minute)
+	require.NoError(t, clusterReader.Sync(context.Background()))
+	assert.Equal(t, []gkNamespace{deploymentGKN, rsGKN, podGKN}, fakeReader.syncedGVKNamespaces)
+}
+
 // newCachingClusterReader creates a new CachingClusterReader and returns it as the concrete
 // type instead of engine.ClusterReader.
 func newCachingClusterReader(reader client.Reader, mapper meta.RESTMapper, identifiers object.ObjMetadataSet) (*CachingClusterReader, error) {
@@ -342,6 +395,8 @@ func sortGVKNamespaces(gvkNamespaces []gkNamespace) {
 type fakeReader struct {
 	clusterObjs         map[gkNamespace][]unstructured.Unstructured
 	syncedGVKNamespaces []gkNamespace
+	syncedSelectors     map[gkNamespace]string
+	syncedLimits        []int64
 	err                 error
 }
 
@@ -361,6 +416,13 @@ func (f *fakeReader) List(_ context.Context, list client.ObjectList, opts ...cli
 	}
 
 	f.syncedGVKNamespaces = append(f.syncedGVKNamespaces, query)
+	f.syncedLimits = append(f.syncedLimits, listOpts.Limit)
+	if listOpts.LabelSelector != nil && !listOpts.LabelSelector.Empty() {
+		if f.syncedSelectors == nil {
+			f.syncedSelectors = make(map[gkNamespace]string)
+		}
+		f.syncedSelectors[query] = listOpts.LabelSelector.String()
+	}
 
 	if f.err != nil {
 		return f.err
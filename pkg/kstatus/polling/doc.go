@@ -37,4 +37,17 @@
 //	for e := range eventsChan {
 //	   // Handle event
 //	}
+//
+// # Custom Status Readers
+//
+// Status for resources that don't follow the status conventions, like many
+// custom resources, can be computed by registering a StatusReader for their
+// GroupKind:
+//
+//	rolloutGK := schema.GroupKind{Group: "argoproj.io", Kind: "Rollout"}
+//	poller := polling.NewStatusPoller(reader, mapper, polling.Options{
+//	  StatusReadersByGroupKind: map[schema.GroupKind]engine.StatusReader{
+//	    rolloutGK: statusreaders.NewGenericStatusReader(mapper, rolloutStatus),
+//	  },
+//	})
 package polling
@@ -68,6 +68,7 @@ func (s *PollerEngine) Poll(ctx context.Context, identifiers object.ObjMetadataS
 			previousResourceStatuses: make(map[object.ObjMetadata]*event.ResourceStatus),
 			eventChannel:             eventChannel,
 			pollingInterval:          options.PollInterval,
+			onPoll:                   options.OnPoll,
 		}
 		runner.Run(ctx)
 	}()
@@ -112,6 +113,10 @@ type Options struct {
 	// PollInterval defines how often the PollerEngine should poll the cluster for the latest
 	// state of the resources.
 	PollInterval time.Duration
+
+	// OnPoll is called after each poll of the cluster, with the duration
+	// and the error of the poll, if any. Optional.
+	OnPoll func(time.Duration, error)
 }
 
 // statusPollerRunner is responsible for polling of a set of resources. Each call to Poll will create
@@ -151,6 +156,9 @@ type statusPollerRunner struct {
 	// pollingInterval determines how often we should poll the cluster for
 	// the latest state of resources.
 	pollingInterval time.Duration
+
+	// onPoll is called after each poll, if set.
+	onPoll func(time.Duration, error)
 }
 
 // Run starts the polling loop of the statusReaders.
@@ -198,6 +206,16 @@ func (r *statusPollerRunner) handleSyncAndPollErr(err error) {
 }
 
 func (r *statusPollerRunner) syncAndPoll(ctx context.Context) error {
+	if r.onPoll == nil {
+		return r.poll(ctx)
+	}
+	start := time.Now()
+	err := r.poll(ctx)
+	r.onPoll(time.Since(start), err)
+	return err
+}
+
+func (r *statusPollerRunner) poll(ctx context.Context) error {
 	// First trigger a sync of the ClusterReader. This may or may not actually
 	// result in calls to the cluster, depending on the implementation.
 	// If this call fails, there is no clean way to recover, so we just return an ErrorEvent
@@ -299,3 +299,48 @@ func (f *fakeStatusReader) ReadStatus(_ context.Context, _ ClusterReader, identi
 func (f *fakeStatusReader) ReadStatusForObject(_ context.Context, _ ClusterReader, _ *unstructured.Unstructured) (*event.ResourceStatus, error) {
 	return nil, nil
 }
+
+func TestStatusPollerRunnerOnPoll(t *testing.T) {
+	identifiers := object.ObjMetadataSet{
+		{
+			GroupKind: schema.GroupKind{
+				Group: "apps",
+				Kind:  "Deployment",
+			},
+			Name:      "foo",
+			Namespace: "default",
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	engine := PollerEngine{
+		Mapper: fakemapper.NewFakeRESTMapper(appsv1.SchemeGroupVersion.WithKind("Deployment")),
+		ClusterReaderFactory: ClusterReaderFactoryFunc(func(client.Reader, meta.RESTMapper, object.ObjMetadataSet) (ClusterReader, error) {
+			return fakecr.NewNoopClusterReader(), nil
+		}),
+		DefaultStatusReader: &fakeStatusReader{
+			resourceStatuses: map[schema.GroupKind][]status.Status{
+				schema.GroupKind{Group: "apps", Kind: "Deployment"}: {status.CurrentStatus},
+			},
+			resourceStatusCount: make(map[schema.GroupKind]int),
+		},
+	}
+
+	polls := make(chan error, 10)
+	eventChannel := engine.Poll(ctx, identifiers, Options{
+		PollInterval: time.Hour,
+		OnPoll: func(d time.Duration, err error) {
+			assert.GreaterOrEqual(t, d, time.Duration(0))
+			polls <- err
+		},
+	})
+
+	e := <-eventChannel
+	assert.Equal(t, event.ResourceUpdateEvent, e.Type)
+	assert.NoError(t, <-polls)
+	cancel()
+	for range eventChannel {
+	}
+}
@@ -6,11 +6,14 @@ package polling
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/clusterreader"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/engine"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
@@ -22,10 +25,18 @@ import (
 
 // NewStatusPoller creates a new StatusPoller using the given clusterreader and mapper. The StatusPoller
 // will use the client for all calls to the cluster.
+//
+// The status of each resource is computed by the first StatusReader that
+// supports its GroupKind, checked in this order:
+//  1. Options.StatusReadersByGroupKind
+//  2. Options.CustomStatusReaders, in the order provided
+//  3. The built-in StatusReaders (Deployment, StatefulSet, ReplicaSet)
+//  4. The generic StatusReader, which uses status.Compute
 func NewStatusPoller(reader client.Reader, mapper meta.RESTMapper, o Options) *StatusPoller {
 	setDefaults(&o)
 	var statusReaders []engine.StatusReader
 
+	statusReaders = append(statusReaders, groupKindStatusReaders(o.StatusReadersByGroupKind)...)
 	statusReaders = append(statusReaders, o.CustomStatusReaders...)
 
 	srs, defaultStatusReader := createStatusReaders(mapper)
@@ -39,6 +50,7 @@ func NewStatusPoller(reader client.Reader, mapper meta.RESTMapper, o Options) *S
 			StatusReaders:        statusReaders,
 			ClusterReaderFactory: o.ClusterReaderFactory,
 		},
+		dryRunStrategy: o.DryRunStrategy,
 	}
 }
 
@@ -65,7 +77,7 @@ func NewStatusPollerFromFactory(f cmdutil.Factory, o Options) (*StatusPoller, er
 
 func setDefaults(o *Options) {
 	if o.ClusterReaderFactory == nil {
-		o.ClusterReaderFactory = engine.ClusterReaderFactoryFunc(clusterreader.NewCachingClusterReader)
+		o.ClusterReaderFactory = clusterreader.NewCachingClusterReaderFactory(o.CachingClusterReaderOptions)
 	}
 }
 
@@ -76,31 +88,109 @@ type Options struct {
 	// be used to compute reconcile status for resources.
 	CustomStatusReaders []engine.StatusReader
 
+	// StatusReadersByGroupKind specifies implementations of the
+	// engine.StatusReader interface that will be used to compute reconcile
+	// status for resources of a specific GroupKind, like custom resources
+	// that don't follow the status conventions. These take precedence over
+	// the CustomStatusReaders. If the registered StatusReader does not
+	// support the GroupKind itself, the next StatusReader is used instead.
+	StatusReadersByGroupKind map[schema.GroupKind]engine.StatusReader
+
 	// ClusterReaderFactory allows for custom implementations of the engine.ClusterReader interface
 	// in the StatusPoller. The default implementation if the clusterreader.CachingClusterReader.
 	ClusterReaderFactory engine.ClusterReaderFactory
+
+	// CachingClusterReaderOptions tune the LIST calls of the default
+	// clusterreader.CachingClusterReader, like the page size, label selectors
+	// and resync intervals by GroupKind. Ignored if a ClusterReaderFactory is
+	// provided.
+	CachingClusterReaderOptions clusterreader.CachingClusterReaderOptions
+
+	// DryRunStrategy defines whether the resources being polled were applied
+	// with a dry-run. Dry-run applies never persist any changes, so instead of
+	// polling the cluster, the StatusPoller reports every resource as Current.
+	DryRunStrategy common.DryRunStrategy
 }
 
 // StatusPoller provides functionality for polling a cluster for status for a set of resources.
 type StatusPoller struct {
-	engine *engine.PollerEngine
+	engine         *engine.PollerEngine
+	dryRunStrategy common.DryRunStrategy
 }
 
 // Poll will create a new statusPollerRunner that will poll all the resources provided and report their status
 // back on the event channel returned. The statusPollerRunner can be cancelled at any time by cancelling the
 // context passed in.
+// If the StatusPoller was created with a dry-run strategy, no calls are made
+// to the cluster and a Current status is sent for each of the resources.
 func (s *StatusPoller) Poll(ctx context.Context, identifiers object.ObjMetadataSet, options PollOptions) <-chan event.Event {
+	if s.dryRunStrategy.ClientOrServerDryRun() {
+		return pollDryRun(ctx, identifiers)
+	}
 	return s.engine.Poll(ctx, identifiers, engine.Options{
 		PollInterval: options.PollInterval,
+		OnPoll:       options.OnPoll,
 	})
 }
 
+// pollDryRun sends a synthesized Current status for each of the identifiers
+// and then blocks until the context is cancelled, after which the event
+// channel is closed.
+func pollDryRun(ctx context.Context, identifiers object.ObjMetadataSet) <-chan event.Event {
+	eventChannel := make(chan event.Event)
+	go func() {
+		defer close(eventChannel)
+		for _, id := range identifiers {
+			e := event.Event{
+				Type: event.ResourceUpdateEvent,
+				Resource: &event.ResourceStatus{
+					Identifier: id,
+					Status:     status.CurrentStatus,
+					Message:    "Resource is current (dry-run)",
+				},
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case eventChannel <- e:
+			}
+		}
+		<-ctx.Done()
+	}()
+	return eventChannel
+}
+
 // PollOptions defines the levers available for tuning the behavior of the
 // StatusPoller.
 type PollOptions struct {
 	// PollInterval defines how often the PollerEngine should poll the cluster for the latest
 	// state of the resources.
 	PollInterval time.Duration
+
+	// OnPoll is called after each poll of the cluster, with the duration
+	// and the error of the poll, if any. Optional.
+	OnPoll func(time.Duration, error)
+}
+
+// groupKindStatusReaders wraps each of the StatusReaders so it only supports
+// the GroupKind it was registered for. The GroupKinds are sorted so the order
+// of the StatusReaders is deterministic.
+func groupKindStatusReaders(readers map[schema.GroupKind]engine.StatusReader) []engine.StatusReader {
+	gks := make([]schema.GroupKind, 0, len(readers))
+	for gk := range readers {
+		gks = append(gks, gk)
+	}
+	sort.Slice(gks, func(i, j int) bool {
+		if gks[i].Group != gks[j].Group {
+			return gks[i].Group < gks[j].Group
+		}
+		return gks[i].Kind < gks[j].Kind
+	})
+	statusReaders := make([]engine.StatusReader, 0, len(gks))
+	for _, gk := range gks {
+		statusReaders = append(statusReaders, statusreaders.NewGroupKindStatusReader(readers[gk], gk))
+	}
+	return statusReaders
 }
 
 // createStatusReaders creates an instance of all the statusreaders. This includes a set of statusreaders for
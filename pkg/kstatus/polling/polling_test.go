@@ -0,0 +1,180 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package polling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/engine"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestStatusPollerDryRun(t *testing.T) {
+	identifiers := object.ObjMetadataSet{
+		{
+			GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+			Name:      "foo",
+			Namespace: "default",
+		},
+		{
+			GroupKind: schema.GroupKind{Kind: "ConfigMap"},
+			Name:      "bar",
+			Namespace: "default",
+		},
+	}
+
+	for _, strategy := range common.Strategies {
+		t.Run(strategy.String(), func(t *testing.T) {
+			// The reader and mapper are nil, so any call to the cluster
+			// would panic.
+			poller := NewStatusPoller(nil, nil, Options{
+				DryRunStrategy: strategy,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			eventCh := poller.Poll(ctx, identifiers, PollOptions{})
+
+			var received object.ObjMetadataSet
+			for range identifiers {
+				e := <-eventCh
+				assert.Equal(t, event.ResourceUpdateEvent, e.Type)
+				assert.Equal(t, status.CurrentStatus, e.Resource.Status)
+				received = append(received, e.Resource.Identifier)
+			}
+			assert.Equal(t, identifiers, received)
+
+			cancel()
+			_, open := <-eventCh
+			assert.False(t, open, "event channel should be closed after cancel")
+		})
+	}
+}
+
+// namedStatusReader is a StatusReader that supports a fixed set of
+// GroupKinds, or all GroupKinds if the set is nil.
+type namedStatusReader struct {
+	name      string
+	supported []schema.GroupKind
+}
+
+var _ engine.StatusReader = &namedStatusReader{}
+
+func (n *namedStatusReader) Supports(gk schema.GroupKind) bool {
+	if n.supported == nil {
+		return true
+	}
+	for _, s := range n.supported {
+		if s == gk {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *namedStatusReader) ReadStatus(_ context.Context, _ engine.ClusterReader, id object.ObjMetadata) (*event.ResourceStatus, error) {
+	return &event.ResourceStatus{Identifier: id, Message: n.name}, nil
+}
+
+func (n *namedStatusReader) ReadStatusForObject(_ context.Context, _ engine.ClusterReader, obj *unstructured.Unstructured) (*event.ResourceStatus, error) {
+	return &event.ResourceStatus{Identifier: object.UnstructuredToObjMetadata(obj), Message: n.name}, nil
+}
+
+func TestNewStatusPollerStatusReaderOrder(t *testing.T) {
+	rolloutGK := schema.GroupKind{Group: "argoproj.io", Kind: "Rollout"}
+	gatewayGK := schema.GroupKind{Group: "networking.istio.io", Kind: "Gateway"}
+	deploymentGK := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	configMapGK := schema.GroupKind{Kind: "ConfigMap"}
+
+	testCases := map[string]struct {
+		options        Options
+		gk             schema.GroupKind
+		expectedReader string
+	}{
+		"registered GroupKind reader wins over custom readers": {
+			options: Options{
+				StatusReadersByGroupKind: map[schema.GroupKind]engine.StatusReader{
+					rolloutGK: &namedStatusReader{name: "rollout"},
+				},
+				CustomStatusReaders: []engine.StatusReader{
+					&namedStatusReader{name: "custom"},
+				},
+			},
+			gk:             rolloutGK,
+			expectedReader: "rollout",
+		},
+		"registered GroupKind reader only applies to its GroupKind": {
+			options: Options{
+				StatusReadersByGroupKind: map[schema.GroupKind]engine.StatusReader{
+					rolloutGK: &namedStatusReader{name: "rollout"},
+				},
+				CustomStatusReaders: []engine.StatusReader{
+					&namedStatusReader{name: "custom"},
+				},
+			},
+			gk:             gatewayGK,
+			expectedReader: "custom",
+		},
+		"registered reader that doesn't support its GroupKind falls back": {
+			options: Options{
+				StatusReadersByGroupKind: map[schema.GroupKind]engine.StatusReader{
+					gatewayGK: &namedStatusReader{name: "gateway", supported: []schema.GroupKind{}},
+				},
+				CustomStatusReaders: []engine.StatusReader{
+					&namedStatusReader{name: "custom"},
+				},
+			},
+			gk:             gatewayGK,
+			expectedReader: "custom",
+		},
+		"registered reader overrides built-in reader": {
+			options: Options{
+				StatusReadersByGroupKind: map[schema.GroupKind]engine.StatusReader{
+					deploymentGK: &namedStatusReader{name: "deployment"},
+				},
+			},
+			gk:             deploymentGK,
+			expectedReader: "deployment",
+		},
+		"custom readers are checked in order": {
+			options: Options{
+				CustomStatusReaders: []engine.StatusReader{
+					&namedStatusReader{name: "first", supported: []schema.GroupKind{configMapGK}},
+					&namedStatusReader{name: "second"},
+				},
+			},
+			gk:             configMapGK,
+			expectedReader: "first",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			poller := NewStatusPoller(nil, nil, tc.options)
+
+			var found engine.StatusReader
+			for _, sr := range poller.engine.StatusReaders {
+				if sr.Supports(tc.gk) {
+					found = sr
+					break
+				}
+			}
+			if !assert.NotNil(t, found) {
+				return
+			}
+			id := object.ObjMetadata{GroupKind: tc.gk, Name: "foo", Namespace: "default"}
+			rs, err := found.ReadStatus(context.Background(), nil, id)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedReader, rs.Message)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package statusreaders
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/engine"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// NewGroupKindStatusReader returns a StatusReader that delegates to the
+// provided StatusReader, but only for the given GroupKinds. This makes it
+// possible to register a StatusReader that would otherwise support every
+// resource, like one returned by NewGenericStatusReader with a custom
+// StatusFunc, for a specific set of custom resources.
+//
+// The returned StatusReader supports a GroupKind only if it is one of the
+// given GroupKinds and the delegate also supports it. Otherwise, the caller
+// should fall back to the next StatusReader.
+func NewGroupKindStatusReader(statusReader engine.StatusReader, gks ...schema.GroupKind) engine.StatusReader {
+	gkSet := make(map[schema.GroupKind]struct{}, len(gks))
+	for _, gk := range gks {
+		gkSet[gk] = struct{}{}
+	}
+	return &groupKindStatusReader{
+		statusReader: statusReader,
+		groupKinds:   gkSet,
+	}
+}
+
+// groupKindStatusReader is a StatusReader that restricts the GroupKinds
+// supported by another StatusReader.
+type groupKindStatusReader struct {
+	statusReader engine.StatusReader
+	groupKinds   map[schema.GroupKind]struct{}
+}
+
+var _ engine.StatusReader = &groupKindStatusReader{}
+
+func (g *groupKindStatusReader) Supports(gk schema.GroupKind) bool {
+	if _, found := g.groupKinds[gk]; !found {
+		return false
+	}
+	return g.statusReader.Supports(gk)
+}
+
+func (g *groupKindStatusReader) ReadStatus(ctx context.Context, reader engine.ClusterReader,
+	id object.ObjMetadata) (*event.ResourceStatus, error) {
+	if !g.Supports(id.GroupKind) {
+		return nil, fmt.Errorf("status reader does not support this resource: %v", id.GroupKind)
+	}
+	return g.statusReader.ReadStatus(ctx, reader, id)
+}
+
+func (g *groupKindStatusReader) ReadStatusForObject(ctx context.Context, reader engine.ClusterReader,
+	obj *unstructured.Unstructured) (*event.ResourceStatus, error) {
+	gk := obj.GroupVersionKind().GroupKind()
+	if !g.Supports(gk) {
+		return nil, fmt.Errorf("status reader does not support this resource: %v", gk)
+	}
+	return g.statusReader.ReadStatusForObject(ctx, reader, obj)
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package statusreaders
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakecr "sigs.k8s.io/cli-utils/pkg/kstatus/polling/clusterreader/fake"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/engine"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+var (
+	rolloutGK = schema.GroupKind{Group: "argoproj.io", Kind: "Rollout"}
+	gatewayGK = schema.GroupKind{Group: "networking.istio.io", Kind: "Gateway"}
+)
+
+// supportsStatusReader is a StatusReader that supports a fixed set of
+// GroupKinds and always reports the resources as Current.
+type supportsStatusReader struct {
+	supported map[schema.GroupKind]bool
+}
+
+var _ engine.StatusReader = &supportsStatusReader{}
+
+func (s *supportsStatusReader) Supports(gk schema.GroupKind) bool {
+	return s.supported[gk]
+}
+
+func (s *supportsStatusReader) ReadStatus(_ context.Context, _ engine.ClusterReader, id object.ObjMetadata) (*event.ResourceStatus, error) {
+	return &event.ResourceStatus{
+		Identifier: id,
+		Status:     status.CurrentStatus,
+	}, nil
+}
+
+func (s *supportsStatusReader) ReadStatusForObject(_ context.Context, _ engine.ClusterReader, obj *unstructured.Unstructured) (*event.ResourceStatus, error) {
+	return &event.ResourceStatus{
+		Identifier: object.UnstructuredToObjMetadata(obj),
+		Status:     status.CurrentStatus,
+	}, nil
+}
+
+func TestGroupKindStatusReader(t *testing.T) {
+	testCases := map[string]struct {
+		delegateSupports map[schema.GroupKind]bool
+		groupKinds       []schema.GroupKind
+		gk               schema.GroupKind
+		expectSupported  bool
+	}{
+		"registered GroupKind supported by delegate": {
+			delegateSupports: map[schema.GroupKind]bool{rolloutGK: true, gatewayGK: true},
+			groupKinds:       []schema.GroupKind{rolloutGK},
+			gk:               rolloutGK,
+			expectSupported:  true,
+		},
+		"unregistered GroupKind supported by delegate": {
+			delegateSupports: map[schema.GroupKind]bool{rolloutGK: true, gatewayGK: true},
+			groupKinds:       []schema.GroupKind{rolloutGK},
+			gk:               gatewayGK,
+			expectSupported:  false,
+		},
+		"registered GroupKind not supported by delegate": {
+			delegateSupports: map[schema.GroupKind]bool{},
+			groupKinds:       []schema.GroupKind{rolloutGK},
+			gk:               rolloutGK,
+			expectSupported:  false,
+		},
+		"multiple registered GroupKinds": {
+			delegateSupports: map[schema.GroupKind]bool{rolloutGK: true, gatewayGK: true},
+			groupKinds:       []schema.GroupKind{rolloutGK, gatewayGK},
+			gk:               gatewayGK,
+			expectSupported:  true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			delegate := &supportsStatusReader{supported: tc.delegateSupports}
+			sr := NewGroupKindStatusReader(delegate, tc.groupKinds...)
+			assert.Equal(t, tc.expectSupported, sr.Supports(tc.gk))
+
+			id := object.ObjMetadata{
+				GroupKind: tc.gk,
+				Name:      "foo",
+				Namespace: "default",
+			}
+			rs, err := sr.ReadStatus(context.Background(), fakecr.NewNoopClusterReader(), id)
+			if !tc.expectSupported {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, status.CurrentStatus, rs.Status)
+			assert.Equal(t, id, rs.Identifier)
+		})
+	}
+}
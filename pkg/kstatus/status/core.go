@@ -20,21 +20,22 @@ type GetConditionsFn func(*unstructured.Unstructured) (*Result, error)
 // legacyTypes defines the mapping from GroupKind to a function that can
 // compute the status for the given resource.
 var legacyTypes = map[string]GetConditionsFn{
-	"Service":                    serviceConditions,
-	"Pod":                        podConditions,
-	"Secret":                     alwaysReady,
-	"PersistentVolumeClaim":      pvcConditions,
-	"apps/StatefulSet":           stsConditions,
-	"apps/DaemonSet":             daemonsetConditions,
-	"extensions/DaemonSet":       daemonsetConditions,
-	"apps/Deployment":            deploymentConditions,
-	"extensions/Deployment":      deploymentConditions,
-	"apps/ReplicaSet":            replicasetConditions,
-	"extensions/ReplicaSet":      replicasetConditions,
-	"policy/PodDisruptionBudget": pdbConditions,
-	"batch/CronJob":              alwaysReady,
-	"ConfigMap":                  alwaysReady,
-	"batch/Job":                  jobConditions,
+	"Service":                             serviceConditions,
+	"Pod":                                 podConditions,
+	"Secret":                              alwaysReady,
+	"PersistentVolumeClaim":               pvcConditions,
+	"apps/StatefulSet":                    stsConditions,
+	"apps/DaemonSet":                      daemonsetConditions,
+	"extensions/DaemonSet":                daemonsetConditions,
+	"apps/Deployment":                     deploymentConditions,
+	"extensions/Deployment":               deploymentConditions,
+	"apps/ReplicaSet":                     replicasetConditions,
+	"extensions/ReplicaSet":               replicasetConditions,
+	"policy/PodDisruptionBudget":          pdbConditions,
+	"batch/CronJob":                       cronJobConditions,
+	"ConfigMap":                           alwaysReady,
+	"batch/Job":                           jobConditions,
+	"autoscaling/HorizontalPodAutoscaler": hpaConditions,
 	"apiextensions.k8s.io/CustomResourceDefinition": crdConditions,
 }
 
@@ -507,20 +508,134 @@ func getCrashLoopingContainers(obj map[string]interface{}) ([]string, bool, erro
 }
 
 // pdbConditions computes the status for PodDisruptionBudgets. A PDB
-// is currently considered Current if the disruption controller has
-// observed the latest version of the PDB resource and has computed
-// the AllowedDisruptions. PDBs do have ObservedGeneration in the
-// Status object, so if this function gets called we know that
-// the controller has observed the latest changes.
-// The disruption controller does not set any conditions if
-// computing the AllowedDisruptions fails (and there are many ways
-// it can fail), but there is PR against OSS Kubernetes to address
-// this: https://github.com/kubernetes/kubernetes/pull/86929
-func pdbConditions(_ *unstructured.Unstructured) (*Result, error) {
+// is considered Current if the disruption controller has observed the
+// latest version of the PDB resource, has computed the AllowedDisruptions,
+// and enough of the selected pods are healthy to satisfy the budget.
+// If the disruption controller is unable to compute the AllowedDisruptions,
+// it sets the DisruptionAllowed condition to False with the SyncFailed
+// reason, which is reported as Failed.
+func pdbConditions(u *unstructured.Unstructured) (*Result, error) {
+	res, err := checkGenerationSet(u)
+	if err != nil || res != nil {
+		return res, err
+	}
+
+	obj := u.UnstructuredContent()
+
+	objc, err := GetObjectWithConditions(obj)
+	if err != nil {
+		return nil, err
+	}
+	c, found := getConditionWithStatus(objc.Status.Conditions, "DisruptionAllowed", corev1.ConditionFalse)
+	if found && c.Reason == "SyncFailed" {
+		return newFailedStatus(c.Reason, c.Message), nil
+	}
+
+	currentHealthy := GetIntField(obj, ".status.currentHealthy", 0)
+	desiredHealthy := GetIntField(obj, ".status.desiredHealthy", 0)
+	disruptionsAllowed := GetIntField(obj, ".status.disruptionsAllowed", 0)
+
+	if desiredHealthy > currentHealthy {
+		message := fmt.Sprintf("Healthy: %d/%d", currentHealthy, desiredHealthy)
+		return newInProgressStatus("LessHealthy", message), nil
+	}
+
+	// All ok
+	return &Result{
+		Status:     CurrentStatus,
+		Message:    fmt.Sprintf("AllowedDisruptions has been computed. AllowedDisruptions: %d", disruptionsAllowed),
+		Conditions: []Condition{},
+	}, nil
+}
+
+// hpaConditions computes the status for HorizontalPodAutoscalers.
+//
+// The HPA controller sets the AbleToScale and ScalingActive conditions
+// (only visible in autoscaling/v2 and later). If the HPA is unable to
+// scale its target, the status is Failed. If the HPA has not yet been
+// able to compute a replica count from its metrics, the status is
+// InProgress. Versions without conditions fall back to checking whether
+// the controller has computed the desired number of replicas.
+func hpaConditions(u *unstructured.Unstructured) (*Result, error) {
+	obj := u.UnstructuredContent()
+
+	objc, err := GetObjectWithConditions(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(objc.Status.Conditions) == 0 {
+		if _, found, err := unstructured.NestedFieldNoCopy(obj, "status", "desiredReplicas"); err != nil || !found {
+			message := "HPA has not computed the desired replicas"
+			return newInProgressStatus("NoDesiredReplicas", message), nil
+		}
+	}
+
+	for _, c := range objc.Status.Conditions {
+		switch c.Type {
+		case "AbleToScale":
+			if c.Status == corev1.ConditionFalse {
+				return newFailedStatus(c.Reason, c.Message), nil
+			}
+		case "ScalingActive":
+			// ScalingDisabled means the target has been scaled to zero,
+			// which is intentional.
+			if c.Status == corev1.ConditionFalse && c.Reason != "ScalingDisabled" {
+				return newInProgressStatus(c.Reason, c.Message), nil
+			}
+		}
+	}
+
+	currentReplicas := GetIntField(obj, ".status.currentReplicas", 0)
+	desiredReplicas := GetIntField(obj, ".status.desiredReplicas", 0)
+
 	// All ok
 	return &Result{
 		Status:     CurrentStatus,
-		Message:    "AllowedDisruptions has been computed.",
+		Message:    fmt.Sprintf("HPA is able to scale. Replicas: %d/%d", currentReplicas, desiredReplicas),
+		Conditions: []Condition{},
+	}, nil
+}
+
+// cronJobConditions computes the status for CronJobs.
+//
+// A CronJob is always considered Current, because the controller does not
+// need to act on it before it is usable. Jobs created by the CronJob have
+// their own status. The message reports whether the CronJob is suspended,
+// how many Jobs are active, and when a Job was last scheduled.
+func cronJobConditions(u *unstructured.Unstructured) (*Result, error) {
+	obj := u.UnstructuredContent()
+
+	suspended, _, err := unstructured.NestedBool(obj, "spec", "suspend")
+	if err != nil {
+		return nil, fmt.Errorf("looking up spec.suspend from resource: %w", err)
+	}
+	if suspended {
+		return &Result{
+			Status:     CurrentStatus,
+			Message:    "CronJob is suspended",
+			Conditions: []Condition{},
+		}, nil
+	}
+
+	active, _, err := unstructured.NestedSlice(obj, "status", "active")
+	if err != nil {
+		return nil, fmt.Errorf("looking up status.active from resource: %w", err)
+	}
+	lastScheduleTime := GetStringField(obj, ".status.lastScheduleTime", "")
+
+	var message string
+	switch {
+	case len(active) > 0:
+		message = fmt.Sprintf("CronJob has %d active Job(s). Last scheduled: %s", len(active), lastScheduleTime)
+	case lastScheduleTime != "":
+		message = fmt.Sprintf("CronJob has no active Jobs. Last scheduled: %s", lastScheduleTime)
+	default:
+		message = "CronJob has not scheduled any Jobs yet"
+	}
+	return &Result{
+		Status:     CurrentStatus,
+		Message:    message,
 		Conditions: []Condition{},
 	}, nil
 }
@@ -990,6 +990,36 @@ status:
    observedGeneration: 1
 `
 
+var pdbLessHealthy = `
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+   generation: 1
+   name: test
+   namespace: qual
+status:
+   observedGeneration: 1
+   currentHealthy: 1
+   desiredHealthy: 2
+   disruptionsAllowed: 0
+`
+
+var pdbSyncFailed = `
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+   generation: 1
+   name: test
+   namespace: qual
+status:
+   observedGeneration: 1
+   conditions:
+   - type: DisruptionAllowed
+     status: "False"
+     reason: SyncFailed
+     message: "found no controllers for pod"
+`
+
 func TestPDBStatus(t *testing.T) {
 	testCases := map[string]testSpec{
 		"pdbNotObserved": {
@@ -1013,6 +1043,30 @@ func TestPDBStatus(t *testing.T) {
 				ConditionReconciling,
 			},
 		},
+		"pdbLessHealthy": {
+			spec:           pdbLessHealthy,
+			expectedStatus: InProgressStatus,
+			expectedConditions: []Condition{{
+				Type:   ConditionReconciling,
+				Status: corev1.ConditionTrue,
+				Reason: "LessHealthy",
+			}},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+			},
+		},
+		"pdbSyncFailed": {
+			spec:           pdbSyncFailed,
+			expectedStatus: FailedStatus,
+			expectedConditions: []Condition{{
+				Type:   ConditionStalled,
+				Status: corev1.ConditionTrue,
+				Reason: "SyncFailed",
+			}},
+			absentConditionTypes: []ConditionType{
+				ConditionReconciling,
+			},
+		},
 	}
 
 	for tn, tc := range testCases {
@@ -1288,6 +1342,31 @@ metadata:
 status:
 `
 
+var cronjobSuspended = `
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+   name: test
+   namespace: qual
+   generation: 1
+spec:
+   suspend: true
+`
+
+var cronjobActive = `
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+   name: test
+   namespace: qual
+   generation: 1
+status:
+   active:
+   - kind: Job
+     name: test-1
+   lastScheduleTime: "2022-01-01T00:00:00Z"
+`
+
 func TestCronJobStatus(t *testing.T) {
 	testCases := map[string]testSpec{
 		"cronjobNoStatus": {
@@ -1308,6 +1387,193 @@ func TestCronJobStatus(t *testing.T) {
 				ConditionReconciling,
 			},
 		},
+		"cronjobSuspended": {
+			spec:               cronjobSuspended,
+			expectedStatus:     CurrentStatus,
+			expectedConditions: []Condition{},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+				ConditionReconciling,
+			},
+		},
+		"cronjobActive": {
+			spec:               cronjobActive,
+			expectedStatus:     CurrentStatus,
+			expectedConditions: []Condition{},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+				ConditionReconciling,
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		tc := tc
+		t.Run(tn, func(t *testing.T) {
+			runStatusTest(t, tc)
+		})
+	}
+}
+
+var hpaNoStatus = `
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+   name: test
+   namespace: qual
+   generation: 1
+`
+
+var hpaScaling = `
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+   name: test
+   namespace: qual
+   generation: 1
+status:
+   currentReplicas: 2
+   desiredReplicas: 2
+   conditions:
+   - type: AbleToScale
+     status: "True"
+     reason: ReadyForNewScale
+   - type: ScalingActive
+     status: "True"
+     reason: ValidMetricFound
+`
+
+var hpaScalingDisabled = `
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+   name: test
+   namespace: qual
+   generation: 1
+status:
+   currentReplicas: 0
+   desiredReplicas: 0
+   conditions:
+   - type: AbleToScale
+     status: "True"
+     reason: SucceededGetScale
+   - type: ScalingActive
+     status: "False"
+     reason: ScalingDisabled
+`
+
+var hpaMetricsMissing = `
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+   name: test
+   namespace: qual
+   generation: 1
+status:
+   currentReplicas: 1
+   desiredReplicas: 0
+   conditions:
+   - type: AbleToScale
+     status: "True"
+     reason: SucceededGetScale
+   - type: ScalingActive
+     status: "False"
+     reason: FailedGetResourceMetric
+`
+
+var hpaUnableToScale = `
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+   name: test
+   namespace: qual
+   generation: 1
+status:
+   currentReplicas: 0
+   desiredReplicas: 0
+   conditions:
+   - type: AbleToScale
+     status: "False"
+     reason: FailedGetScale
+`
+
+var hpaV1WithStatus = `
+apiVersion: autoscaling/v1
+kind: HorizontalPodAutoscaler
+metadata:
+   name: test
+   namespace: qual
+   generation: 1
+status:
+   currentReplicas: 1
+   desiredReplicas: 1
+`
+
+func TestHPAStatus(t *testing.T) {
+	testCases := map[string]testSpec{
+		"hpaNoStatus": {
+			spec:           hpaNoStatus,
+			expectedStatus: InProgressStatus,
+			expectedConditions: []Condition{{
+				Type:   ConditionReconciling,
+				Status: corev1.ConditionTrue,
+				Reason: "NoDesiredReplicas",
+			}},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+			},
+		},
+		"hpaScaling": {
+			spec:               hpaScaling,
+			expectedStatus:     CurrentStatus,
+			expectedConditions: []Condition{},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+				ConditionReconciling,
+			},
+		},
+		"hpaScalingDisabled": {
+			spec:               hpaScalingDisabled,
+			expectedStatus:     CurrentStatus,
+			expectedConditions: []Condition{},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+				ConditionReconciling,
+			},
+		},
+		"hpaMetricsMissing": {
+			spec:           hpaMetricsMissing,
+			expectedStatus: InProgressStatus,
+			expectedConditions: []Condition{{
+				Type:   ConditionReconciling,
+				Status: corev1.ConditionTrue,
+				Reason: "FailedGetResourceMetric",
+			}},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+			},
+		},
+		"hpaUnableToScale": {
+			spec:           hpaUnableToScale,
+			expectedStatus: FailedStatus,
+			expectedConditions: []Condition{{
+				Type:   ConditionStalled,
+				Status: corev1.ConditionTrue,
+				Reason: "FailedGetScale",
+			}},
+			absentConditionTypes: []ConditionType{
+				ConditionReconciling,
+			},
+		},
+		"hpaV1WithStatus": {
+			spec:               hpaV1WithStatus,
+			expectedStatus:     CurrentStatus,
+			expectedConditions: []Condition{},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+				ConditionReconciling,
+			},
+		},
 	}
 
 	for tn, tc := range testCases {
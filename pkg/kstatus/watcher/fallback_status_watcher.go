@@ -0,0 +1,69 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package watcher
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// FallbackStatusWatcher watches objects with the Watcher, and switches to
+// the Fallback if the Watcher fails because listing or watching the objects
+// is forbidden. This allows watching by default, while still supporting
+// users that only have permission to get the objects.
+type FallbackStatusWatcher struct {
+	// Watcher is the StatusWatcher used first.
+	Watcher StatusWatcher
+
+	// Fallback is the StatusWatcher used if the Watcher fails with a
+	// Forbidden error.
+	Fallback StatusWatcher
+}
+
+var _ StatusWatcher = &FallbackStatusWatcher{}
+
+// Watch the objects with the Watcher, falling back to the Fallback if
+// permissions are missing. Only one SyncEvent is sent, even if both
+// watchers synchronize.
+func (w *FallbackStatusWatcher) Watch(ctx context.Context, ids object.ObjMetadataSet, opts Options) <-chan event.Event {
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	watchCh := w.Watcher.Watch(watchCtx, ids, opts)
+	eventCh := make(chan event.Event)
+	go func() {
+		defer close(eventCh)
+		defer watchCancel()
+		synced := false
+		send := func(e event.Event) {
+			if e.Type == event.SyncEvent {
+				if synced {
+					return
+				}
+				synced = true
+			}
+			select {
+			case eventCh <- e:
+			case <-ctx.Done():
+			}
+		}
+		for e := range watchCh {
+			if e.Type == event.ErrorEvent && apierrors.IsForbidden(e.Error) {
+				klog.V(1).Infof("Watch forbidden, using fallback status watcher: %v", e.Error)
+				watchCancel()
+				// Drain the watcher, so it can exit.
+				for range watchCh {
+				}
+				for e := range w.Fallback.Watch(ctx, ids, opts) {
+					send(e)
+				}
+				return
+			}
+			send(e)
+		}
+	}()
+	return eventCh
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package watcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// fakeStatusWatcher sends the events and then blocks until the context is
+// cancelled.
+type fakeStatusWatcher struct {
+	events []event.Event
+}
+
+func (w *fakeStatusWatcher) Watch(ctx context.Context, _ object.ObjMetadataSet, _ Options) <-chan event.Event {
+	eventCh := make(chan event.Event)
+	go func() {
+		defer close(eventCh)
+		for _, e := range w.events {
+			select {
+			case eventCh <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+		<-ctx.Done()
+	}()
+	return eventCh
+}
+
+func TestFallbackStatusWatcher(t *testing.T) {
+	forbiddenErr := apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"},
+		"", errors.New("watch is forbidden"))
+	otherErr := errors.New("connection refused")
+	update := event.Event{
+		Type: event.ResourceUpdateEvent,
+		Resource: &event.ResourceStatus{
+			Identifier: object.ObjMetadata{
+				GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+				Namespace: "default",
+				Name:      "foo",
+			},
+			Status: status.CurrentStatus,
+		},
+	}
+
+	testCases := map[string]struct {
+		watcherEvents  []event.Event
+		fallbackEvents []event.Event
+		expectedEvents []event.Event
+	}{
+		"watch succeeds": {
+			watcherEvents: []event.Event{
+				{Type: event.SyncEvent},
+				update,
+			},
+			fallbackEvents: []event.Event{
+				{Type: event.SyncEvent},
+			},
+			expectedEvents: []event.Event{
+				{Type: event.SyncEvent},
+				update,
+			},
+		},
+		"forbidden watch falls back": {
+			watcherEvents: []event.Event{
+				{Type: event.ErrorEvent, Error: forbiddenErr},
+			},
+			fallbackEvents: []event.Event{
+				{Type: event.SyncEvent},
+				update,
+			},
+			expectedEvents: []event.Event{
+				{Type: event.SyncEvent},
+				update,
+			},
+		},
+		"only one sync event is sent": {
+			watcherEvents: []event.Event{
+				{Type: event.SyncEvent},
+				{Type: event.ErrorEvent, Error: forbiddenErr},
+			},
+			fallbackEvents: []event.Event{
+				{Type: event.SyncEvent},
+				update,
+			},
+			expectedEvents: []event.Event{
+				{Type: event.SyncEvent},
+				update,
+			},
+		},
+		"other errors are passed through": {
+			watcherEvents: []event.Event{
+				{Type: event.ErrorEvent, Error: otherErr},
+			},
+			fallbackEvents: []event.Event{
+				{Type: event.SyncEvent},
+			},
+			expectedEvents: []event.Event{
+				{Type: event.ErrorEvent, Error: otherErr},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			w := &FallbackStatusWatcher{
+				Watcher:  &fakeStatusWatcher{events: tc.watcherEvents},
+				Fallback: &fakeStatusWatcher{events: tc.fallbackEvents},
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			var received []event.Event
+			for e := range w.Watch(ctx, object.ObjMetadataSet{update.Resource.Identifier}, Options{}) {
+				received = append(received, e)
+			}
+			assert.Equal(t, tc.expectedEvents, received)
+		})
+	}
+}
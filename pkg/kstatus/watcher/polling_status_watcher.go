@@ -0,0 +1,72 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package watcher
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PollingStatusWatcher reports on status updates to a set of objects by
+// periodically polling the cluster. It only requires get and list
+// permissions, so it can be used when the objects can not be watched.
+//
+// Use NewPollingStatusWatcher to build a PollingStatusWatcher with default
+// settings.
+type PollingStatusWatcher struct {
+	// Poller is used to poll the status of the objects.
+	Poller *polling.StatusPoller
+
+	// PollInterval is how often the cluster is polled.
+	PollInterval time.Duration
+
+	// OnPoll is called after each poll of the cluster, with the duration
+	// and the error of the poll, if any. Optional.
+	OnPoll func(time.Duration, error)
+}
+
+var _ StatusWatcher = &PollingStatusWatcher{}
+
+// NewPollingStatusWatcher constructs a PollingStatusWatcher with defaults
+// chosen for general use.
+func NewPollingStatusWatcher(reader client.Reader, mapper meta.RESTMapper) *PollingStatusWatcher {
+	return &PollingStatusWatcher{
+		Poller:       polling.NewStatusPoller(reader, mapper, polling.Options{}),
+		PollInterval: 2 * time.Second,
+	}
+}
+
+// Watch polls the cluster for changes made to the specified objects.
+// Returns an event channel on which these updates (and errors) will be
+// reported. A SyncEvent is sent first, since every poll lists the current
+// state of the objects.
+func (w *PollingStatusWatcher) Watch(ctx context.Context, ids object.ObjMetadataSet, _ Options) <-chan event.Event {
+	pollCh := w.Poller.Poll(ctx, ids, polling.PollOptions{
+		PollInterval: w.PollInterval,
+		OnPoll:       w.OnPoll,
+	})
+	eventCh := make(chan event.Event)
+	go func() {
+		defer close(eventCh)
+		select {
+		case eventCh <- event.Event{Type: event.SyncEvent}:
+		case <-ctx.Done():
+		}
+		// Once the context is cancelled, events are dropped until the
+		// poller closes its channel.
+		for e := range pollCh {
+			select {
+			case eventCh <- e:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return eventCh
+}
@@ -36,3 +36,18 @@ const (
 	RESTScopeRoot                               // root
 	RESTScopeNamespace                          // namespace
 )
+
+// Strategy specifies how the applier and destroyer watch the status of
+// objects, when they use the default StatusWatcher.
+//
+//go:generate stringer -type=Strategy -linecomment
+type Strategy int
+
+const (
+	// WatchStrategy watches objects with informers, and falls back to
+	// polling if watching the objects is forbidden.
+	WatchStrategy Strategy = iota // watch
+	// PollStrategy periodically polls objects. It requires fewer
+	// permissions, but causes more requests for large sets of objects.
+	PollStrategy // poll
+)
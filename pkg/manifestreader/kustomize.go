@@ -0,0 +1,65 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package manifestreader
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// KustomizeManifestReader implements ManifestReader interface.
+var _ ManifestReader = &KustomizeManifestReader{}
+
+// KustomizeManifestReader builds the kustomization in the provided
+// directory and returns the resulting objects.
+type KustomizeManifestReader struct {
+	Path string
+	// FileSystem is the file system the kustomization is read from.
+	// Defaults to the local disk.
+	FileSystem filesys.FileSystem
+
+	ReaderOptions
+}
+
+// Read builds the kustomization and returns the objects.
+func (k *KustomizeManifestReader) Read() ([]*unstructured.Unstructured, error) {
+	fSys := k.FileSystem
+	if fSys == nil {
+		fSys = filesys.MakeFsOnDisk()
+	}
+	resMap, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, k.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kustomization %q: %w", k.Path, err)
+	}
+	yamlBytes, err := resMap.AsYaml()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode kustomization %q: %w", k.Path, err)
+	}
+	objs, err := (&StreamManifestReader{
+		ReaderName:    k.Path,
+		Reader:        bytes.NewReader(yamlBytes),
+		ReaderOptions: k.ReaderOptions,
+	}).Read()
+	// The lines of the build output do not match the kustomization sources.
+	clearSourceLines(objs)
+	return objs, err
+}
+
+// IsKustomization returns true if the directory contains a kustomization
+// file.
+func IsKustomization(dir string) bool {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
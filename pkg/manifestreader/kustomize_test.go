@@ -0,0 +1,73 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package manifestreader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const kustomization = `
+namePrefix: test-
+resources:
+- dep.yaml
+- cm.yaml
+`
+
+func TestKustomizeManifestReader_Read(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test-ns")
+	defer tf.Cleanup()
+
+	mapper, err := tf.ToRESTMapper()
+	require.NoError(t, err)
+
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.WriteFile("/app/kustomization.yaml", []byte(kustomization)))
+	require.NoError(t, fSys.WriteFile("/app/dep.yaml", []byte(depManifest)))
+	require.NoError(t, fSys.WriteFile("/app/cm.yaml", []byte(cmManifest)))
+
+	objs, err := (&KustomizeManifestReader{
+		Path:       "/app",
+		FileSystem: fSys,
+		ReaderOptions: ReaderOptions{
+			Mapper:           mapper,
+			Namespace:        "foo",
+			EnforceNamespace: true,
+		},
+	}).Read()
+	require.NoError(t, err)
+
+	var names []string
+	for _, obj := range objs {
+		names = append(names, obj.GetName())
+		assert.Equal(t, "foo", obj.GetNamespace())
+		assert.Equal(t, "/app", object.Source(obj))
+	}
+	assert.ElementsMatch(t, []string{"test-dep", "test-cm"}, names)
+}
+
+func TestKustomizeManifestReader_ReadError(t *testing.T) {
+	_, err := (&KustomizeManifestReader{
+		Path:       "/missing",
+		FileSystem: filesys.MakeFsInMemory(),
+	}).Read()
+	assert.Error(t, err)
+}
+
+func TestIsKustomization(t *testing.T) {
+	dir := t.TempDir()
+	assert.False(t, IsKustomization(dir))
+
+	err := os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte(kustomization), 0600)
+	require.NoError(t, err)
+	assert.True(t, IsKustomization(dir))
+	assert.False(t, IsKustomization(filepath.Join(dir, "kustomization.yaml")))
+}
@@ -15,15 +15,34 @@ type ManifestLoader interface {
 	ManifestReader(reader io.Reader, path string) (ManifestReader, error)
 }
 
+// LoaderOptions configures the readers returned by a ManifestLoader.
+type LoaderOptions struct {
+	// Kustomize builds the kustomization in the directory of the path,
+	// instead of reading the manifest files in it.
+	Kustomize bool
+	// Checksum is the expected digest of manifests fetched from a URL,
+	// e.g. "sha256:<hex>". Optional.
+	Checksum string
+}
+
 // manifestLoader implements the ManifestLoader interface
 type manifestLoader struct {
 	factory util.Factory
+	options *LoaderOptions
 }
 
 // NewManifestLoader returns an instance of manifestLoader.
 func NewManifestLoader(f util.Factory) ManifestLoader {
+	return NewManifestLoaderWithOptions(f, &LoaderOptions{})
+}
+
+// NewManifestLoaderWithOptions returns an instance of manifestLoader with
+// the options. The options are read each time a reader is returned, so they
+// can be bound to command line flags.
+func NewManifestLoaderWithOptions(f util.Factory, options *LoaderOptions) ManifestLoader {
 	return &manifestLoader{
 		factory: f,
+		options: options,
 	}
 }
 
@@ -49,11 +68,11 @@ func (f *manifestLoader) ManifestReader(reader io.Reader, path string) (Manifest
 		EnforceNamespace: enforceNamespace,
 	}
 
-	return mReader(path, reader, readerOptions), nil
+	return mReader(path, reader, readerOptions, *f.options), nil
 }
 
 // mReader returns the ManifestReader based in the input args
-func mReader(path string, reader io.Reader, readerOptions ReaderOptions) ManifestReader {
+func mReader(path string, reader io.Reader, readerOptions ReaderOptions, loaderOptions LoaderOptions) ManifestReader {
 	var mReader ManifestReader
 	// Read from stdin if "-" is specified, similar to kubectl
 	if path == "-" {
@@ -62,6 +81,17 @@ func mReader(path string, reader io.Reader, readerOptions ReaderOptions) Manifes
 			Reader:        reader,
 			ReaderOptions: readerOptions,
 		}
+	} else if IsURL(path) {
+		mReader = &URLManifestReader{
+			URL:           path,
+			Checksum:      loaderOptions.Checksum,
+			ReaderOptions: readerOptions,
+		}
+	} else if loaderOptions.Kustomize {
+		mReader = &KustomizeManifestReader{
+			Path:          path,
+			ReaderOptions: readerOptions,
+		}
 	} else {
 		mReader = &PathManifestReader{
 			Path:          path,
@@ -66,7 +66,7 @@ func TestMReader_Read(t *testing.T) {
 				Namespace:        tc.namespace,
 				EnforceNamespace: tc.enforceNamespace,
 				Validate:         tc.validate,
-			}).Read()
+			}, LoaderOptions{}).Read()
 
 			assert.NoError(t, err)
 			assert.Equal(t, len(objs), tc.infosCount)
@@ -77,3 +77,21 @@ func TestMReader_Read(t *testing.T) {
 		})
 	}
 }
+
+func TestMReader_LoaderOptions(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte(kustomization), 0600)
+	assert.NoError(t, err)
+
+	// Directories are only built with kustomize if requested.
+	_, ok := mReader(dir, nil, ReaderOptions{}, LoaderOptions{}).(*PathManifestReader)
+	assert.True(t, ok, "path reader")
+	_, ok = mReader(dir, nil, ReaderOptions{}, LoaderOptions{Kustomize: true}).(*KustomizeManifestReader)
+	assert.True(t, ok, "kustomize reader")
+
+	r, ok := mReader("https://example.com/app.yaml", nil, ReaderOptions{},
+		LoaderOptions{Checksum: "sha256:abc"}).(*URLManifestReader)
+	if assert.True(t, ok, "URL reader") {
+		assert.Equal(t, "sha256:abc", r.Checksum)
+	}
+}
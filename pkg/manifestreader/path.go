@@ -4,6 +4,9 @@
 package manifestreader
 
 import (
+	"os"
+	"path/filepath"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
@@ -31,7 +34,15 @@ func (p *PathManifestReader) Read() ([]*unstructured.Unstructured, error) {
 		return objs, err
 	}
 
+	fileLines := make(map[string][]int)
 	for _, n := range nodes {
+		path := n.GetAnnotations()[kioutil.PathAnnotation]
+		lines, found := fileLines[path]
+		if !found {
+			lines = p.documentLines(path)
+			fileLines[path] = lines
+		}
+		line := sourceLine(n, lines)
 		err = RemoveAnnotations(n, kioutil.IndexAnnotation)
 		if err != nil {
 			return objs, err
@@ -40,6 +51,7 @@ func (p *PathManifestReader) Read() ([]*unstructured.Unstructured, error) {
 		if err != nil {
 			return objs, err
 		}
+		setSource(u, "", line)
 		objs = append(objs, u)
 	}
 
@@ -48,3 +60,17 @@ func (p *PathManifestReader) Read() ([]*unstructured.Unstructured, error) {
 	err = SetNamespaces(p.Mapper, objs, p.Namespace, p.EnforceNamespace)
 	return objs, err
 }
+
+// documentLines returns the lines at which the documents of the file at the
+// path, relative to the package, start.
+func (p *PathManifestReader) documentLines(path string) []int {
+	dir := p.Path
+	if info, err := os.Stat(p.Path); err == nil && !info.IsDir() {
+		dir = filepath.Dir(p.Path)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, path))
+	if err != nil {
+		return nil
+	}
+	return documentLines(content)
+}
@@ -0,0 +1,101 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package manifestreader
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// documentSeparator matches the YAML document separators the same way as
+// kio.ByteReader.
+var documentSeparator = regexp.MustCompile(`\n---.*\n`)
+
+// documentLines returns the lines at which the non-empty YAML documents in
+// the content start, indexed like the kyaml index annotation. kio.ByteReader
+// decodes each document separately, so the line numbers of the nodes it
+// returns are relative to their document.
+func documentLines(content []byte) []int {
+	s := strings.ReplaceAll(string(content), "\r\n", "\n")
+	var docs []string
+	var offsets []int
+	prev := 0
+	if len(s) > 0 {
+		for _, loc := range documentSeparator.FindAllStringIndex(s, -1) {
+			docs = append(docs, s[prev:loc[0]])
+			offsets = append(offsets, strings.Count(s[:prev], "\n"))
+			prev = loc[1]
+		}
+		docs = append(docs, s[prev:])
+		offsets = append(offsets, strings.Count(s[:prev], "\n"))
+	}
+
+	var lines []int
+	for i, doc := range docs {
+		node := &yaml.Node{}
+		if err := yaml.NewDecoder(bytes.NewBufferString(doc)).Decode(node); err != nil {
+			if err != io.EOF {
+				// ByteReader fails on malformed documents.
+				return lines
+			}
+			continue
+		}
+		if yaml.IsYNodeEmptyDoc(node) {
+			continue
+		}
+		rn := yaml.NewRNode(node)
+		if yaml.IsMissingOrNull(rn) {
+			continue
+		}
+		lines = append(lines, offsets[i]+rn.YNode().Line)
+	}
+	return lines
+}
+
+// sourceLine returns the line at which the object read from the node starts,
+// using the document lines of its source. It must be called before the
+// index annotation is removed from the node.
+func sourceLine(n *yaml.RNode, lines []int) int {
+	if index, err := strconv.Atoi(n.GetAnnotations()[kioutil.IndexAnnotation]); err == nil && index < len(lines) {
+		return lines[index]
+	}
+	return n.YNode().Line
+}
+
+// setSource records the name of the source and the line the object was
+// read from. The name is not recorded if the object has a path annotation,
+// since the path is more specific.
+func setSource(u *unstructured.Unstructured, name string, line int) {
+	annos := u.GetAnnotations()
+	if annos == nil {
+		annos = make(map[string]string)
+	}
+	if _, found := annos[kioutil.PathAnnotation]; !found && name != "" {
+		annos[object.SourceNameAnnotation] = name
+	}
+	u.SetAnnotations(annos)
+	if line > 0 {
+		object.SetSourceLine(u, line)
+	}
+}
+
+// clearSourceLines removes the line annotations from objects whose manifests
+// were generated, so the lines do not match any source file.
+func clearSourceLines(objs []*unstructured.Unstructured) {
+	for _, obj := range objs {
+		annos := obj.GetAnnotations()
+		if _, found := annos[object.SourceLineAnnotation]; found {
+			delete(annos, object.SourceLineAnnotation)
+			obj.SetAnnotations(annos)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package manifestreader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+)
+
+const multiDocManifest = `# leading comment
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  name: first
+---
+---
+
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  name: second
+--- # trailing comment
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  name: third
+`
+
+func TestDocumentLines(t *testing.T) {
+	assert.Equal(t, []int{2, 9, 14}, documentLines([]byte(multiDocManifest)))
+	assert.Equal(t, []int{2, 9, 14},
+		documentLines([]byte(strings.ReplaceAll(multiDocManifest, "\n", "\r\n"))))
+	assert.Empty(t, documentLines(nil))
+}
+
+func TestStreamManifestReader_Source(t *testing.T) {
+	objs, err := (&StreamManifestReader{
+		ReaderName:    "stdin",
+		Reader:        strings.NewReader(multiDocManifest),
+		ReaderOptions: readerOptions(t),
+	}).Read()
+	require.NoError(t, err)
+
+	var sources []string
+	for _, obj := range objs {
+		sources = append(sources, object.Source(obj))
+		// The stream is not a file.
+		assert.NotContains(t, obj.GetAnnotations(), kioutil.PathAnnotation)
+	}
+	assert.Equal(t, []string{"stdin:2", "stdin:9", "stdin:14"}, sources)
+}
+
+func TestPathManifestReader_Source(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cm.yaml"), []byte(multiDocManifest), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dep.yaml"), []byte(depManifest), 0600))
+
+	testCases := map[string]struct {
+		path     string
+		expected []string
+	}{
+		"directory": {
+			path:     dir,
+			expected: []string{"cm.yaml:2", "cm.yaml:9", "cm.yaml:14", "dep.yaml:2"},
+		},
+		"file": {
+			path:     filepath.Join(dir, "cm.yaml"),
+			expected: []string{"cm.yaml:2", "cm.yaml:9", "cm.yaml:14"},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			objs, err := (&PathManifestReader{
+				Path:          tc.path,
+				ReaderOptions: readerOptions(t),
+			}).Read()
+			require.NoError(t, err)
+
+			var sources []string
+			for _, obj := range objs {
+				sources = append(sources, object.Source(obj))
+			}
+			assert.Equal(t, tc.expected, sources)
+		})
+	}
+}
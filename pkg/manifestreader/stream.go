@@ -4,6 +4,7 @@
 package manifestreader
 
 import (
+	"bytes"
 	"io"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -27,14 +28,20 @@ type StreamManifestReader struct {
 // Read reads the manifests and returns them as Info objects.
 func (r *StreamManifestReader) Read() ([]*unstructured.Unstructured, error) {
 	var objs []*unstructured.Unstructured
+	content, err := io.ReadAll(r.Reader)
+	if err != nil {
+		return objs, err
+	}
 	nodes, err := (&kio.ByteReader{
-		Reader: r.Reader,
+		Reader: bytes.NewReader(content),
 	}).Read()
 	if err != nil {
 		return objs, err
 	}
 
+	lines := documentLines(content)
 	for _, n := range nodes {
+		line := sourceLine(n, lines)
 		err = RemoveAnnotations(n, kioutil.IndexAnnotation)
 		if err != nil {
 			return objs, err
@@ -43,6 +50,7 @@ func (r *StreamManifestReader) Read() ([]*unstructured.Unstructured, error) {
 		if err != nil {
 			return objs, err
 		}
+		setSource(u, r.ReaderName, line)
 		objs = append(objs, u)
 	}
 
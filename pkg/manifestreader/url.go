@@ -0,0 +1,378 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package manifestreader
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// HTTPSScheme is the URL scheme of manifests fetched over HTTPS.
+	HTTPSScheme = "https://"
+	// OCIScheme is the URL scheme of manifests stored as an OCI artifact,
+	// e.g. oci://ghcr.io/example/manifests:v1.
+	OCIScheme = "oci://"
+
+	// maxFetchSize is the maximum size of fetched manifests.
+	maxFetchSize = 64 << 20
+
+	ociManifestMediaType    = "application/vnd.oci.image.manifest.v1+json"
+	dockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+)
+
+// URLManifestReader implements ManifestReader interface.
+var _ ManifestReader = &URLManifestReader{}
+
+// URLManifestReader fetches manifests from an HTTPS URL or an OCI artifact
+// and returns them as unstructured objects.
+//
+// OCI artifacts must have a single layer, with either the YAML manifests or
+// a gzipped tarball of them. Layers are verified against their digest.
+type URLManifestReader struct {
+	URL string
+	// Checksum is the expected digest of the fetched content, i.e. the
+	// HTTPS response body or the OCI layer, e.g. "sha256:<hex>". Optional.
+	Checksum string
+	// Client is the HTTP client used to fetch the manifests. Defaults to
+	// http.DefaultClient.
+	Client *http.Client
+
+	ReaderOptions
+}
+
+// IsURL returns true if the path is a URL supported by the
+// URLManifestReader.
+func IsURL(p string) bool {
+	return strings.HasPrefix(p, HTTPSScheme) || strings.HasPrefix(p, OCIScheme)
+}
+
+// Read fetches the manifests and returns them as unstructured objects.
+func (u *URLManifestReader) Read() ([]*unstructured.Unstructured, error) {
+	var content []byte
+	var archived bool
+	var err error
+	switch {
+	case strings.HasPrefix(u.URL, HTTPSScheme):
+		content, err = u.fetch(u.URL, nil)
+		if err == nil {
+			err = verifyDigest(content, u.Checksum)
+		}
+	case strings.HasPrefix(u.URL, OCIScheme):
+		content, archived, err = u.fetchOCI()
+	default:
+		err = fmt.Errorf("unsupported URL %q: must start with %q or %q", u.URL, HTTPSScheme, OCIScheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	objs, err := (&StreamManifestReader{
+		ReaderName:    u.URL,
+		Reader:        bytes.NewReader(content),
+		ReaderOptions: u.ReaderOptions,
+	}).Read()
+	if archived {
+		// The lines of the concatenated files do not match the archive.
+		clearSourceLines(objs)
+	}
+	return objs, err
+}
+
+func (u *URLManifestReader) client() *http.Client {
+	if u.Client == nil {
+		return http.DefaultClient
+	}
+	return u.Client
+}
+
+// fetch returns the body of a GET request to the URL. If the registry
+// responds with a bearer challenge, an anonymous token is requested and the
+// request is retried once.
+func (u *URLManifestReader) fetch(rawURL string, header http.Header) ([]byte, error) {
+	resp, err := u.get(rawURL, header)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		token, err := u.token(challenge)
+		if err != nil {
+			return nil, err
+		}
+		header = header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Set("Authorization", "Bearer "+token)
+		resp, err = u.get(rawURL, header)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return readBody(rawURL, resp)
+}
+
+// readBody returns the body of a successful response, and closes it.
+func readBody(rawURL string, resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %q: %s", rawURL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %q: %w", rawURL, err)
+	}
+	if len(body) > maxFetchSize {
+		return nil, fmt.Errorf("failed to fetch %q: larger than %d bytes", rawURL, maxFetchSize)
+	}
+	return body, nil
+}
+
+func (u *URLManifestReader) get(rawURL string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := u.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %q: %w", rawURL, err)
+	}
+	return resp, nil
+}
+
+// token requests an anonymous bearer token for the challenge returned by a
+// registry, e.g. `Bearer realm="https://auth.example.com/token",service="registry",scope="repository:foo:pull"`.
+func (u *URLManifestReader) token(challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+	params := parseChallenge(strings.TrimPrefix(challenge, "Bearer "))
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Scheme != "https" {
+		return "", fmt.Errorf("invalid authentication realm %q", params["realm"])
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if v, found := params[key]; found {
+			query.Set(key, v)
+		}
+	}
+	realm.RawQuery = query.Encode()
+	// The token realm is not authenticated, so its challenges are not
+	// followed.
+	tokenResp, err := u.get(realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	body, err := readBody(realm.String(), tokenResp)
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("invalid token response: %w", err)
+	}
+	if resp.Token != "" {
+		return resp.Token, nil
+	}
+	if resp.AccessToken != "" {
+		return resp.AccessToken, nil
+	}
+	return "", errors.New("invalid token response: no token")
+}
+
+// parseChallenge parses the comma separated key="value" parameters of an
+// authentication challenge.
+func parseChallenge(s string) map[string]string {
+	params := make(map[string]string)
+	for s != "" {
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			break
+		}
+		key := strings.TrimSpace(s[:eq])
+		s = s[eq+1:]
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				break
+			}
+			value = s[1 : end+1]
+			s = s[end+2:]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value = s[:end]
+			s = s[end:]
+		}
+		params[key] = value
+		s = strings.TrimPrefix(strings.TrimSpace(s), ",")
+	}
+	return params
+}
+
+// fetchOCI returns the manifests stored in the single layer of an OCI
+// artifact, and whether they were extracted from an archive.
+func (u *URLManifestReader) fetchOCI() ([]byte, bool, error) {
+	registry, repository, reference, err := parseOCIReference(strings.TrimPrefix(u.URL, OCIScheme))
+	if err != nil {
+		return nil, false, err
+	}
+	base := fmt.Sprintf("https://%s/v2/%s", registry, repository)
+	manifestBytes, err := u.fetch(base+"/manifests/"+reference, http.Header{
+		"Accept": []string{ociManifestMediaType + ", " + dockerManifestMediaType},
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if strings.Contains(reference, ":") {
+		if err := verifyDigest(manifestBytes, reference); err != nil {
+			return nil, false, err
+		}
+	}
+	var manifest struct {
+		Layers []struct {
+			MediaType string `json:"mediaType"`
+			Digest    string `json:"digest"`
+		} `json:"layers"`
+	}
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, false, fmt.Errorf("invalid OCI manifest %q: %w", u.URL, err)
+	}
+	if len(manifest.Layers) != 1 {
+		return nil, false, fmt.Errorf("invalid OCI manifest %q: must have a single layer, got %d", u.URL, len(manifest.Layers))
+	}
+	layer := manifest.Layers[0]
+	blob, err := u.fetch(base+"/blobs/"+layer.Digest, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := verifyDigest(blob, layer.Digest); err != nil {
+		return nil, false, err
+	}
+	if err := verifyDigest(blob, u.Checksum); err != nil {
+		return nil, false, err
+	}
+	if strings.HasSuffix(layer.MediaType, "tar+gzip") {
+		content, err := extractManifests(blob)
+		return content, true, err
+	}
+	return blob, false, nil
+}
+
+// parseOCIReference splits an OCI reference, e.g. ghcr.io/example/app:v1 or
+// ghcr.io/example/app@sha256:<hex>, into the registry, the repository and the
+// tag or digest. The tag defaults to "latest".
+func parseOCIReference(ref string) (string, string, string, error) {
+	slash := strings.IndexByte(ref, '/')
+	if slash <= 0 || slash == len(ref)-1 {
+		return "", "", "", fmt.Errorf("invalid OCI reference %q: must include a registry and a repository", ref)
+	}
+	registry, repository := ref[:slash], ref[slash+1:]
+	if at := strings.IndexByte(repository, '@'); at >= 0 {
+		return registry, repository[:at], repository[at+1:], nil
+	}
+	if colon := strings.LastIndexByte(repository, ':'); colon >= 0 && !strings.Contains(repository[colon:], "/") {
+		return registry, repository[:colon], repository[colon+1:], nil
+	}
+	return registry, repository, "latest", nil
+}
+
+// extractManifests concatenates the YAML and JSON files in a gzipped
+// tarball, in the order of their names.
+func extractManifests(blob []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(blob))
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest archive: %w", err)
+	}
+	defer gz.Close()
+	files := make(map[string][]byte)
+	tr := tar.NewReader(io.LimitReader(gz, maxFetchSize))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid manifest archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		switch path.Ext(hdr.Name) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid manifest archive: %w", err)
+		}
+		files[hdr.Name] = content
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	for _, name := range names {
+		buf.WriteString("---\n")
+		buf.Write(files[name])
+		buf.WriteString("\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// verifyDigest returns an error if the content does not match the digest,
+// e.g. "sha256:<hex>". An empty digest is not verified.
+func verifyDigest(content []byte, digest string) error {
+	if digest == "" {
+		return nil
+	}
+	algorithm, expected, found := strings.Cut(digest, ":")
+	if !found {
+		return fmt.Errorf("invalid digest %q: must be <algorithm>:<hex>", digest)
+	}
+	var h hash.Hash
+	switch algorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return fmt.Errorf("invalid digest %q: unsupported algorithm %q", digest, algorithm)
+	}
+	h.Write(content)
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != strings.ToLower(expected) {
+		return fmt.Errorf("digest mismatch: expected %s, got %s:%s", digest, algorithm, actual)
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package manifestreader
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func sha256Digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func readerOptions(t *testing.T) ReaderOptions {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test-ns")
+	t.Cleanup(tf.Cleanup)
+	mapper, err := tf.ToRESTMapper()
+	require.NoError(t, err)
+	return ReaderOptions{
+		Mapper:           mapper,
+		Namespace:        "foo",
+		EnforceNamespace: true,
+	}
+}
+
+func TestURLManifestReader_ReadHTTPS(t *testing.T) {
+	content := []byte(depManifest + "---" + cmManifest)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/manifests.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path     string
+		checksum string
+
+		expectedCount int
+		expectedError string
+	}{
+		"no checksum": {
+			path:          "/manifests.yaml",
+			expectedCount: 2,
+		},
+		"matching checksum": {
+			path:          "/manifests.yaml",
+			checksum:      sha256Digest(content),
+			expectedCount: 2,
+		},
+		"mismatching checksum": {
+			path:          "/manifests.yaml",
+			checksum:      sha256Digest([]byte("other")),
+			expectedError: "digest mismatch",
+		},
+		"unsupported checksum": {
+			path:          "/manifests.yaml",
+			checksum:      "md5:abc",
+			expectedError: "unsupported algorithm",
+		},
+		"not found": {
+			path:          "/missing.yaml",
+			expectedError: "404",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			objs, err := (&URLManifestReader{
+				URL:           server.URL + tc.path,
+				Checksum:      tc.checksum,
+				Client:        server.Client(),
+				ReaderOptions: readerOptions(t),
+			}).Read()
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, objs, tc.expectedCount)
+			for _, obj := range objs {
+				assert.Equal(t, "foo", obj.GetNamespace())
+			}
+		})
+	}
+}
+
+func TestURLManifestReader_UnsupportedScheme(t *testing.T) {
+	_, err := (&URLManifestReader{URL: "http://example.com/manifests.yaml"}).Read()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported URL")
+}
+
+func tarball(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0600,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+// fakeRegistry serves a single OCI artifact at /v2/app and requires a bearer
+// token issued by /token.
+func fakeRegistry(t *testing.T, mediaType string, layer []byte) (*httptest.Server, string) {
+	layerDigest := sha256Digest(layer)
+	manifest := []byte(fmt.Sprintf(`{"schemaVersion":2,"mediaType":%q,"layers":[{"mediaType":%q,"digest":%q,"size":%d}]}`,
+		ociManifestMediaType, mediaType, layerDigest, len(layer)))
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if r.URL.Query().Get("scope") != "repository:app:pull" {
+				http.Error(w, "invalid scope", http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token":"secret"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate",
+				fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:app:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/app/manifests/v1", "/v2/app/manifests/" + sha256Digest(manifest):
+			assert.Contains(t, r.Header.Get("Accept"), ociManifestMediaType)
+			_, _ = w.Write(manifest)
+		case "/v2/app/blobs/" + layerDigest:
+			_, _ = w.Write(layer)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return server, sha256Digest(manifest)
+}
+
+func TestURLManifestReader_ReadOCI(t *testing.T) {
+	yamlLayer := []byte(depManifest + "---" + cmManifest)
+	tarLayer := tarball(t, map[string]string{
+		"dep.yaml":  depManifest,
+		"cm.yaml":   cmManifest,
+		"README.md": "not a manifest",
+	})
+
+	testCases := map[string]struct {
+		mediaType string
+		layer     []byte
+		reference string
+		checksum  string
+
+		expectedNames []string
+		expectedError string
+	}{
+		"yaml layer by tag": {
+			mediaType:     "application/yaml",
+			layer:         yamlLayer,
+			reference:     ":v1",
+			expectedNames: []string{"dep", "cm"},
+		},
+		"tarball layer by tag": {
+			mediaType:     "application/vnd.cncf.flux.content.v1.tar+gzip",
+			layer:         tarLayer,
+			reference:     ":v1",
+			expectedNames: []string{"cm", "dep"},
+		},
+		"yaml layer by digest": {
+			mediaType:     "application/yaml",
+			layer:         yamlLayer,
+			reference:     "@${digest}",
+			expectedNames: []string{"dep", "cm"},
+		},
+		"matching checksum": {
+			mediaType:     "application/yaml",
+			layer:         yamlLayer,
+			reference:     ":v1",
+			checksum:      sha256Digest(yamlLayer),
+			expectedNames: []string{"dep", "cm"},
+		},
+		"mismatching checksum": {
+			mediaType:     "application/yaml",
+			layer:         yamlLayer,
+			reference:     ":v1",
+			checksum:      sha256Digest([]byte("other")),
+			expectedError: "digest mismatch",
+		},
+		"unknown tag": {
+			mediaType:     "application/yaml",
+			layer:         yamlLayer,
+			reference:     ":v2",
+			expectedError: "404",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			server, manifestDigest := fakeRegistry(t, tc.mediaType, tc.layer)
+			defer server.Close()
+
+			host := strings.TrimPrefix(server.URL, "https://")
+			reference := strings.ReplaceAll(tc.reference, "${digest}", manifestDigest)
+			objs, err := (&URLManifestReader{
+				URL:           OCIScheme + host + "/app" + reference,
+				Checksum:      tc.checksum,
+				Client:        server.Client(),
+				ReaderOptions: readerOptions(t),
+			}).Read()
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, obj := range objs {
+				names = append(names, obj.GetName())
+			}
+			assert.Equal(t, tc.expectedNames, names)
+		})
+	}
+}
+
+func TestURLManifestReader_TokenChallenge(t *testing.T) {
+	// The token realm challenges for a token too.
+	var requests int
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token"`, server.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := (&URLManifestReader{
+		URL:           OCIScheme + strings.TrimPrefix(server.URL, "https://") + "/app:v1",
+		Client:        server.Client(),
+		ReaderOptions: readerOptions(t),
+	}).Read()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.Equal(t, 2, requests)
+}
+
+func TestParseOCIReference(t *testing.T) {
+	testCases := map[string]struct {
+		ref string
+
+		expectedRegistry   string
+		expectedRepository string
+		expectedReference  string
+		expectedError      bool
+	}{
+		"tag": {
+			ref:                "ghcr.io/example/app:v1",
+			expectedRegistry:   "ghcr.io",
+			expectedRepository: "example/app",
+			expectedReference:  "v1",
+		},
+		"default tag": {
+			ref:                "localhost:5000/app",
+			expectedRegistry:   "localhost:5000",
+			expectedRepository: "app",
+			expectedReference:  "latest",
+		},
+		"digest": {
+			ref:                "ghcr.io/app@sha256:abc",
+			expectedRegistry:   "ghcr.io",
+			expectedRepository: "app",
+			expectedReference:  "sha256:abc",
+		},
+		"no repository": {
+			ref:           "ghcr.io",
+			expectedError: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			registry, repository, reference, err := parseOCIReference(tc.ref)
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedRegistry, registry)
+			assert.Equal(t, tc.expectedRepository, repository)
+			assert.Equal(t, tc.expectedReference, reference)
+		})
+	}
+}
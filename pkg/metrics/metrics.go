@@ -0,0 +1,181 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package metrics provides Prometheus metrics of the runs of the Applier
+// and the Destroyer, e.g. for controllers embedding them.
+//
+// Metrics are registered on the Registerer passed to New, and enabled with
+// the WithMetrics method of the ApplierBuilder and the DestroyerBuilder.
+// The methods of a nil *Metrics do nothing, so callers do not need to check
+// whether metrics are enabled.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+)
+
+// Namespace is the namespace of the metric names.
+const Namespace = "cli_utils"
+
+// Operations, used as the value of the operation label.
+const (
+	ApplyOperation   = "apply"
+	DestroyOperation = "destroy"
+	PruneOperation   = "prune"
+	DeleteOperation  = "delete"
+	StatusOperation  = "status"
+)
+
+// Results, used as the value of the result label.
+const (
+	SuccessResult = "success"
+	FailureResult = "failure"
+)
+
+// Metrics records the metrics of apply and destroy runs.
+type Metrics struct {
+	runDuration        *prometheus.HistogramVec
+	applyDuration      *prometheus.HistogramVec
+	prunedObjects      *prometheus.CounterVec
+	waitTimeouts       prometheus.Counter
+	statusPollDuration prometheus.Histogram
+	apiRequestErrors   *prometheus.CounterVec
+}
+
+// New returns Metrics registered on the Registerer. An error is returned if
+// any metric fails to be registered, e.g. if New was already called with
+// the same Registerer.
+func New(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		runDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "run_duration_seconds",
+			Help:      "Duration of apply and destroy runs, by operation and result.",
+			Buckets:   prometheus.ExponentialBuckets(0.5, 2, 12),
+		}, []string{"operation", "result"}),
+		applyDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "apply_duration_seconds",
+			Help:      "Duration of applying an object, including retries, by result.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"result"}),
+		prunedObjects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "pruned_objects_total",
+			Help:      "Number of objects pruned or deleted, by operation and status.",
+		}, []string{"operation", "status"}),
+		waitTimeouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "wait_timeouts_total",
+			Help:      "Number of objects that did not reconcile before the timeout.",
+		}),
+		statusPollDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "status_poll_duration_seconds",
+			Help:      "Duration of polling the status of the objects of a run.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		apiRequestErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "api_request_errors_total",
+			Help:      "Number of failed API requests, by operation.",
+		}, []string{"operation"}),
+	}
+	for _, c := range []prometheus.Collector{
+		m.runDuration,
+		m.applyDuration,
+		m.prunedObjects,
+		m.waitTimeouts,
+		m.statusPollDuration,
+		m.apiRequestErrors,
+	} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// ObserveApply records the duration of applying an object, and the error
+// of the apply request, if any.
+func (m *Metrics) ObserveApply(d time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.applyDuration.WithLabelValues(result(err)).Observe(d.Seconds())
+	m.observeError(ApplyOperation, err)
+}
+
+// ObserveDelete records the error of a delete request, if any.
+func (m *Metrics) ObserveDelete(err error) {
+	if m == nil {
+		return
+	}
+	m.observeError(DeleteOperation, err)
+}
+
+// ObserveStatusPoll records the duration of polling the status of the
+// objects, and the error of the poll, if any. Polls interrupted by the
+// cancellation of the context are not recorded.
+func (m *Metrics) ObserveStatusPoll(d time.Duration, err error) {
+	if m == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+	m.statusPollDuration.Observe(d.Seconds())
+	m.observeError(StatusOperation, err)
+}
+
+func (m *Metrics) observeError(operation string, err error) {
+	if err != nil {
+		m.apiRequestErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// Instrument records the metrics of the events of a run with the operation,
+// e.g. ApplyOperation, and forwards the events to the returned channel.
+// The duration of the run is recorded when the passed channel is closed.
+// The run fails if any ErrorEvent is received.
+func (m *Metrics) Instrument(operation string, ch <-chan event.Event) <-chan event.Event {
+	if m == nil {
+		return ch
+	}
+	start := time.Now()
+	out := make(chan event.Event)
+	go func() {
+		defer close(out)
+		runResult := SuccessResult
+		for e := range ch {
+			switch e.Type {
+			case event.ErrorType:
+				runResult = FailureResult
+			case event.PruneType:
+				if e.PruneEvent.Status != event.PrunePending {
+					m.prunedObjects.WithLabelValues(PruneOperation, e.PruneEvent.Status.String()).Inc()
+				}
+			case event.DeleteType:
+				if e.DeleteEvent.Status != event.DeletePending {
+					m.prunedObjects.WithLabelValues(DeleteOperation, e.DeleteEvent.Status.String()).Inc()
+				}
+			case event.WaitType:
+				if e.WaitEvent.Status == event.ReconcileTimeout {
+					m.waitTimeouts.Inc()
+				}
+			}
+			out <- e
+		}
+		m.runDuration.WithLabelValues(operation, runResult).Observe(time.Since(start).Seconds())
+	}()
+	return out
+}
+
+func result(err error) string {
+	if err != nil {
+		return FailureResult
+	}
+	return SuccessResult
+}
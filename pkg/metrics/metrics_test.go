@@ -0,0 +1,102 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+)
+
+func TestNew_AlreadyRegistered(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	_, err := New(reg)
+	require.NoError(t, err)
+
+	_, err = New(reg)
+	var are prometheus.AlreadyRegisteredError
+	assert.True(t, errors.As(err, &are), "expected AlreadyRegisteredError, got %v", err)
+}
+
+func TestObserve(t *testing.T) {
+	m, err := New(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	m.ObserveApply(time.Second, nil)
+	m.ObserveApply(2*time.Second, errors.New("apply failed"))
+	m.ObserveDelete(nil)
+	m.ObserveDelete(errors.New("delete failed"))
+	m.ObserveStatusPoll(time.Millisecond, nil)
+	m.ObserveStatusPoll(time.Millisecond, errors.New("list failed"))
+	// Cancelled polls are not recorded.
+	m.ObserveStatusPoll(time.Millisecond, fmt.Errorf("poll: %w", context.Canceled))
+
+	assert.Equal(t, uint64(1), sampleCount(t, m.applyDuration.WithLabelValues(SuccessResult)))
+	assert.Equal(t, uint64(1), sampleCount(t, m.applyDuration.WithLabelValues(FailureResult)))
+	assert.Equal(t, uint64(2), sampleCount(t, m.statusPollDuration))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.apiRequestErrors.WithLabelValues(ApplyOperation)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.apiRequestErrors.WithLabelValues(DeleteOperation)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.apiRequestErrors.WithLabelValues(StatusOperation)))
+}
+
+func TestInstrument(t *testing.T) {
+	m, err := New(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	events := []event.Event{
+		{Type: event.PruneType, PruneEvent: event.PruneEvent{Status: event.PrunePending}},
+		{Type: event.PruneType, PruneEvent: event.PruneEvent{Status: event.PruneSuccessful}},
+		{Type: event.PruneType, PruneEvent: event.PruneEvent{Status: event.PruneSuccessful}},
+		{Type: event.PruneType, PruneEvent: event.PruneEvent{Status: event.PruneSkipped}},
+		{Type: event.DeleteType, DeleteEvent: event.DeleteEvent{Status: event.DeleteFailed}},
+		{Type: event.WaitType, WaitEvent: event.WaitEvent{Status: event.ReconcileTimeout}},
+		{Type: event.WaitType, WaitEvent: event.WaitEvent{Status: event.ReconcileSuccessful}},
+		{Type: event.ErrorType, ErrorEvent: event.ErrorEvent{Err: errors.New("fatal")}},
+	}
+	ch := make(chan event.Event, len(events))
+	for _, e := range events {
+		ch <- e
+	}
+	close(ch)
+
+	var forwarded []event.Event
+	for e := range m.Instrument(ApplyOperation, ch) {
+		forwarded = append(forwarded, e)
+	}
+	assert.Equal(t, events, forwarded)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.prunedObjects.WithLabelValues(PruneOperation, "Successful")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.prunedObjects.WithLabelValues(PruneOperation, "Skipped")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.prunedObjects.WithLabelValues(DeleteOperation, "Failed")))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.prunedObjects))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.waitTimeouts))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.runDuration))
+	assert.Equal(t, uint64(1), sampleCount(t, m.runDuration.WithLabelValues(ApplyOperation, FailureResult)))
+}
+
+// sampleCount returns the number of observations of a histogram.
+func sampleCount(t *testing.T, o prometheus.Observer) uint64 {
+	var metric dto.Metric
+	require.NoError(t, o.(prometheus.Metric).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.ObserveApply(time.Second, errors.New("apply failed"))
+	m.ObserveDelete(errors.New("delete failed"))
+	m.ObserveStatusPoll(time.Second, nil)
+
+	ch := make(chan event.Event)
+	assert.Equal(t, (<-chan event.Event)(ch), m.Instrument(ApplyOperation, ch))
+}
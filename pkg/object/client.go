@@ -0,0 +1,30 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package object
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/dynamic"
+)
+
+// ResourceClient returns a dynamic client for the resource of the object
+// with the given identifier, in the namespace of the object if the
+// resource is namespaced. The versions are passed to the RESTMapper, in
+// order of preference.
+func ResourceClient(client dynamic.Interface, mapper meta.RESTMapper, id ObjMetadata, versions ...string) (dynamic.ResourceInterface, error) {
+	mapping, err := mapper.RESTMapping(id.GroupKind, versions...)
+	if err != nil {
+		return nil, err
+	}
+	return ResourceClientForMapping(client, mapping, id.Namespace), nil
+}
+
+// ResourceClientForMapping returns a dynamic client for the resource of the
+// mapping, in the given namespace if the resource is namespaced.
+func ResourceClientForMapping(client dynamic.Interface, mapping *meta.RESTMapping, namespace string) dynamic.ResourceInterface {
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		return client.Resource(mapping.Resource).Namespace(namespace)
+	}
+	return client.Resource(mapping.Resource)
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package object
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func TestResourceClient(t *testing.T) {
+	cm := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      "cm",
+			"namespace": "default",
+		},
+	}}
+	ns := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata": map[string]interface{}{
+			"name": "default",
+		},
+	}}
+	client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, cm, ns)
+	mapper := testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+		scheme.Scheme.PrioritizedVersionsAllGroups()...)
+
+	for _, obj := range []*unstructured.Unstructured{cm, ns} {
+		id := UnstructuredToObjMetadata(obj)
+		rc, err := ResourceClient(client, mapper, id)
+		require.NoError(t, err)
+		live, err := rc.Get(context.TODO(), id.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, id, UnstructuredToObjMetadata(live))
+	}
+
+	_, err := ResourceClient(client, mapper, ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "example.com", Kind: "Unknown"},
+		Name:      "unknown",
+	})
+	assert.True(t, meta.IsNoMatchError(err))
+}
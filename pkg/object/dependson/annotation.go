@@ -51,6 +51,30 @@ func ReadAnnotation(u *unstructured.Unstructured) (DependencySet, error) {
 	return depSet, nil
 }
 
+// ReadDependencies reads the depends-on annotation and parses the set of
+// object references and the selectors.
+func ReadDependencies(u *unstructured.Unstructured) (DependencySet, []Selector, error) {
+	depSet := DependencySet{}
+	if u == nil {
+		return depSet, nil, nil
+	}
+	depSetStr, found := u.GetAnnotations()[Annotation]
+	if !found {
+		return depSet, nil, nil
+	}
+	klog.V(5).Infof("depends-on annotation found for %s/%s: %q",
+		u.GetNamespace(), u.GetName(), depSetStr)
+
+	depSet, selectors, err := ParseDependencies(depSetStr)
+	if err != nil {
+		return depSet, selectors, object.InvalidAnnotationError{
+			Annotation: Annotation,
+			Cause:      err,
+		}
+	}
+	return depSet, selectors, nil
+}
+
 // WriteAnnotation updates the supplied unstructured object to add the
 // depends-on annotation. The value is a string of objmetas delimited by commas.
 // Each objmeta is formatted as "${group}/${kind}/${name}" if cluster-scoped or
@@ -0,0 +1,188 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package dependson
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	// Wildcard matches any value of a group, kind, namespace or name field.
+	Wildcard = "*"
+	// Used to enclose the label selector of a depends-on selector value.
+	selectorStart = "["
+	selectorEnd   = "]"
+)
+
+// Selector selects a set of dependencies from the objects being applied,
+// instead of referencing a single object.
+//
+// The Group, Kind, Namespace and Name may each be the Wildcard, which
+// matches any value. An empty Namespace only matches cluster-scoped
+// objects. If LabelSelector is set, only objects with matching labels are
+// selected.
+type Selector struct {
+	GroupKind     schema.GroupKind
+	Namespace     string
+	Name          string
+	LabelSelector labels.Selector
+}
+
+// Matches returns true if the object with the passed id and labels is
+// selected by the selector.
+func (s Selector) Matches(id object.ObjMetadata, objLabels map[string]string) bool {
+	if !matchField(s.GroupKind.Group, id.GroupKind.Group) ||
+		!matchField(s.GroupKind.Kind, id.GroupKind.Kind) ||
+		!matchField(s.Name, id.Name) {
+		return false
+	}
+	if s.Namespace == Wildcard {
+		// Wildcard namespace only matches namespaced objects
+		if id.Namespace == "" {
+			return false
+		}
+	} else if s.Namespace != id.Namespace {
+		return false
+	}
+	if s.LabelSelector != nil && !s.LabelSelector.Matches(labels.Set(objLabels)) {
+		return false
+	}
+	return true
+}
+
+// String returns the selector formatted as a depends-on annotation value.
+func (s Selector) String() string {
+	var str string
+	if s.Namespace != "" {
+		str = fmt.Sprintf("%s/namespaces/%s/%s/%s", s.GroupKind.Group, s.Namespace, s.GroupKind.Kind, s.Name)
+	} else {
+		str = fmt.Sprintf("%s/%s/%s", s.GroupKind.Group, s.GroupKind.Kind, s.Name)
+	}
+	if s.LabelSelector != nil {
+		str += selectorStart + s.LabelSelector.String() + selectorEnd
+	}
+	return str
+}
+
+func matchField(pattern, value string) bool {
+	return pattern == Wildcard || pattern == value
+}
+
+// ParseDependencies parses the passed string as a set of object references
+// and a list of selectors.
+//
+// Values are separated by ','. Commas inside a label selector are not
+// treated as separators.
+//
+// Selectors use the same fields as object references, but any field may be
+// the wildcard '*', and they may end with a label selector enclosed in
+// square brackets.
+//
+// Examples:
+//
+//	All CRDs: apiextensions.k8s.io/CustomResourceDefinition/*
+//	In any namespace: apps/namespaces/*/Deployment/*
+//	With labels: */namespaces/my-namespace/*/*[app=db,tier in (backend)]
+//
+// Returns the parsed DependencySet and selectors or an error if unable to
+// parse.
+func ParseDependencies(depsStr string) (DependencySet, []Selector, error) {
+	objs := DependencySet{}
+	var selectors []Selector
+	values, err := splitValues(depsStr)
+	if err != nil {
+		return objs, selectors, err
+	}
+	for i, value := range values {
+		if !isSelector(value) {
+			obj, err := ParseObjMetadata(value)
+			if err != nil {
+				return objs, selectors, fmt.Errorf("failed to parse object reference (index: %d): %w", i, err)
+			}
+			objs = append(objs, obj)
+			continue
+		}
+		selector, err := ParseSelector(value)
+		if err != nil {
+			return objs, selectors, fmt.Errorf("failed to parse selector (index: %d): %w", i, err)
+		}
+		selectors = append(selectors, selector)
+	}
+	return objs, selectors, nil
+}
+
+// ParseSelector parses the passed string as a Selector.
+//
+// Returns the parsed Selector or an error if unable to parse.
+func ParseSelector(selectorStr string) (Selector, error) {
+	var selector Selector
+	selectorStr = strings.TrimSpace(selectorStr)
+	refStr := selectorStr
+	if i := strings.Index(selectorStr, selectorStart); i >= 0 {
+		if !strings.HasSuffix(selectorStr, selectorEnd) {
+			return selector, fmt.Errorf("missing %q at end of label selector: %q", selectorEnd, selectorStr)
+		}
+		labelSelector, err := labels.Parse(selectorStr[i+1 : len(selectorStr)-1])
+		if err != nil {
+			return selector, fmt.Errorf("invalid label selector: %q: %w", selectorStr, err)
+		}
+		selector.LabelSelector = labelSelector
+		refStr = selectorStr[:i]
+	}
+	id, err := ParseObjMetadata(refStr)
+	if err != nil {
+		return selector, err
+	}
+	for _, field := range []string{id.GroupKind.Group, id.GroupKind.Kind, id.Namespace, id.Name} {
+		if strings.Contains(field, Wildcard) && field != Wildcard {
+			return selector, fmt.Errorf("partial wildcards are not supported: %q", selectorStr)
+		}
+	}
+	if id.GroupKind.Kind == "" || id.Name == "" {
+		return selector, fmt.Errorf("kind and name must not be empty: %q", selectorStr)
+	}
+	selector.GroupKind = id.GroupKind
+	selector.Namespace = id.Namespace
+	selector.Name = id.Name
+	return selector, nil
+}
+
+// isSelector returns true if the depends-on value is a selector rather
+// than an object reference.
+func isSelector(value string) bool {
+	return strings.Contains(value, Wildcard) || strings.Contains(value, selectorStart)
+}
+
+// splitValues splits the depends-on annotation into values, ignoring the
+// separators within label selectors.
+func splitValues(depsStr string) ([]string, error) {
+	var values []string
+	depth := 0
+	start := 0
+	for i, c := range depsStr {
+		switch string(c) {
+		case selectorStart:
+			depth++
+		case selectorEnd:
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unexpected %q: %q", selectorEnd, depsStr)
+			}
+		case annotationSeparator:
+			if depth == 0 {
+				values = append(values, depsStr[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("missing %q: %q", selectorEnd, depsStr)
+	}
+	return append(values, depsStr[start:]), nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package dependson
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestParseDependencies(t *testing.T) {
+	testCases := map[string]struct {
+		annotation        string
+		expectedObjs      DependencySet
+		expectedSelectors []string
+		isError           bool
+	}{
+		"object references only": {
+			annotation:   "test-group/test-kind/cluster-obj,test-group/namespaces/test-namespace/test-kind/namespaced-obj",
+			expectedObjs: DependencySet{clusterScopedObj, namespacedObj},
+		},
+		"cluster-scoped wildcard": {
+			annotation:        "apiextensions.k8s.io/CustomResourceDefinition/*",
+			expectedObjs:      DependencySet{},
+			expectedSelectors: []string{"apiextensions.k8s.io/CustomResourceDefinition/*"},
+		},
+		"namespaced wildcard mixed with object reference": {
+			annotation:        "test-group/test-kind/cluster-obj, apps/namespaces/*/Deployment/*",
+			expectedObjs:      DependencySet{clusterScopedObj},
+			expectedSelectors: []string{"apps/namespaces/*/Deployment/*"},
+		},
+		"label selector with commas": {
+			annotation:        "*/namespaces/test-namespace/*/*[app=db,tier in (a,b)],test-group/test-kind/cluster-obj",
+			expectedObjs:      DependencySet{clusterScopedObj},
+			expectedSelectors: []string{"*/namespaces/test-namespace/*/*[app=db,tier in (a,b)]"},
+		},
+		"partial wildcard is error": {
+			annotation: "apps/namespaces/test-namespace/Deployment/foo-*",
+			isError:    true,
+		},
+		"unclosed label selector is error": {
+			annotation: "apps/namespaces/test-namespace/Deployment/*[app=db",
+			isError:    true,
+		},
+		"invalid label selector is error": {
+			annotation: "apps/namespaces/test-namespace/Deployment/*[app in db]",
+			isError:    true,
+		},
+		"wrong number of fields is error": {
+			annotation: "apps/Deployment/*/*",
+			isError:    true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			objs, selectors, err := ParseDependencies(tc.annotation)
+			if tc.isError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tc.expectedObjs.Equal(objs), "expected %v, got %v", tc.expectedObjs, objs)
+			var selectorStrs []string
+			for _, selector := range selectors {
+				selectorStrs = append(selectorStrs, selector.String())
+			}
+			assert.Equal(t, tc.expectedSelectors, selectorStrs)
+		})
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	deployment := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		Namespace: "test-namespace",
+		Name:      "db",
+	}
+	crd := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+		Name:      "foos.example.com",
+	}
+	dbLabels := map[string]string{"app": "db"}
+
+	testCases := map[string]struct {
+		selector string
+		id       object.ObjMetadata
+		labels   map[string]string
+		expected bool
+	}{
+		"wildcard name matches": {
+			selector: "apps/namespaces/test-namespace/Deployment/*",
+			id:       deployment,
+			expected: true,
+		},
+		"wildcard namespace matches": {
+			selector: "apps/namespaces/*/Deployment/db",
+			id:       deployment,
+			expected: true,
+		},
+		"wildcard namespace does not match cluster-scoped": {
+			selector: "apiextensions.k8s.io/namespaces/*/CustomResourceDefinition/*",
+			id:       crd,
+			expected: false,
+		},
+		"cluster-scoped selector matches cluster-scoped": {
+			selector: "apiextensions.k8s.io/CustomResourceDefinition/*",
+			id:       crd,
+			expected: true,
+		},
+		"cluster-scoped selector does not match namespaced": {
+			selector: "*/*/*",
+			id:       deployment,
+			expected: false,
+		},
+		"different kind does not match": {
+			selector: "apps/namespaces/*/StatefulSet/*",
+			id:       deployment,
+			expected: false,
+		},
+		"label selector matches": {
+			selector: "*/namespaces/*/*/*[app=db]",
+			id:       deployment,
+			labels:   dbLabels,
+			expected: true,
+		},
+		"label selector does not match": {
+			selector: "*/namespaces/*/*/*[app=web]",
+			id:       deployment,
+			labels:   dbLabels,
+			expected: false,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			selector, err := ParseSelector(tc.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, selector.Matches(tc.id, tc.labels))
+		})
+	}
+}
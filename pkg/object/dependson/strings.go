@@ -49,12 +49,16 @@ func FormatDependencySet(depSet DependencySet) (string, error) {
 // ParseDependencySet parses the passed string as a set of object
 // references.
 //
-// Object references are separated by ','.
+// Object references are separated by ','. Use ParseDependencies to also
+// parse selectors.
 //
 // Returns the parsed DependencySet or an error if unable to parse.
 func ParseDependencySet(depsStr string) (DependencySet, error) {
 	objs := DependencySet{}
 	for i, objStr := range strings.Split(depsStr, annotationSeparator) {
+		if isSelector(objStr) {
+			return objs, fmt.Errorf("unexpected selector (index: %d): %q", i, objStr)
+		}
 		obj, err := ParseObjMetadata(objStr)
 		if err != nil {
 			return objs, fmt.Errorf("failed to parse object reference (index: %d): %w", i, err)
@@ -50,6 +50,11 @@ func TestParseDependencySet(t *testing.T) {
 			expected:   DependencySet{},
 			isError:    true,
 		},
+		"selector in annotation is error": {
+			annotation: "test-group/test-kind/*",
+			expected:   DependencySet{},
+			isError:    true,
+		},
 		"cluster-scoped object annotation": {
 			annotation: "test-group/test-kind/cluster-obj",
 			expected:   DependencySet{clusterScopedObj},
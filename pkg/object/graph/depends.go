@@ -16,12 +16,35 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/object/dependson"
 	"sigs.k8s.io/cli-utils/pkg/object/mutation"
 	"sigs.k8s.io/cli-utils/pkg/object/validation"
+	"sigs.k8s.io/cli-utils/pkg/object/webhook"
 	"sigs.k8s.io/cli-utils/pkg/ordering"
 )
 
+// Options configure how the dependency graph is built.
+type Options struct {
+	// Rules add implicit dependencies, in addition to the built-in ones.
+	Rules []Rule
+	// ExternalDependencies allows depends-on annotations to reference
+	// objects that are not in the set. Instead of being invalid, they are
+	// returned by Graph.ExternalDependencies, so they can be waited for.
+	ExternalDependencies bool
+	// SatisfiedDependencies are objects that are not in the set, but that
+	// depends-on annotations may reference because they are known to be
+	// reconciled, e.g. objects applied to another cluster before this set.
+	// No edges are added for them.
+	SatisfiedDependencies object.ObjMetadataSet
+}
+
 // DependencyGraph returns a new graph, populated with the supplied objects as
-// vetices and edges built from their dependencies.
-func DependencyGraph(objs object.UnstructuredSet) (*Graph, error) {
+// vetices and edges built from their dependencies, including the implicit
+// dependencies of the passed rules.
+func DependencyGraph(objs object.UnstructuredSet, rules ...Rule) (*Graph, error) {
+	return DependencyGraphWithOptions(objs, Options{Rules: rules})
+}
+
+// DependencyGraphWithOptions is like DependencyGraph, configured with the
+// passed options.
+func DependencyGraphWithOptions(objs object.UnstructuredSet, opts Options) (*Graph, error) {
 	g := New()
 	if len(objs) == 0 {
 		return g, nil
@@ -37,12 +60,20 @@ func DependencyGraph(objs object.UnstructuredSet) (*Graph, error) {
 	// Add dependencies as graph edges
 	addCRDEdges(g, objs, ids)
 	addNamespaceEdges(g, objs, ids)
-	if err := addDependsOnEdges(g, objs, ids); err != nil {
+	if err := addDependsOnEdges(g, objs, ids, opts); err != nil {
 		errors = append(errors, err)
 	}
 	if err := addApplyTimeMutationEdges(g, objs, ids); err != nil {
 		errors = append(errors, err)
 	}
+	if err := addRuleEdges(g, objs, ids, opts.Rules); err != nil {
+		errors = append(errors, err)
+	}
+	// Webhook edges are added last, to skip the ones that would create a
+	// cycle with any other edges.
+	if err := addWebhookEdges(g, objs, ids); err != nil {
+		errors = append(errors, err)
+	}
 	if len(errors) > 0 {
 		return g, multierror.Wrap(errors...)
 	}
@@ -189,7 +220,7 @@ func addApplyTimeMutationEdges(g *Graph, objs object.UnstructuredSet, ids object
 				continue
 			}
 			klog.V(3).Infof("adding edge from: %s, to: %s", id, dep)
-			g.AddEdge(id, dep)
+			g.addEdgeWithReason(id, dep, ApplyTimeMutationReason)
 		}
 		if len(objErrors) > 0 {
 			errors = append(errors,
@@ -203,16 +234,18 @@ func addApplyTimeMutationEdges(g *Graph, objs object.UnstructuredSet, ids object
 }
 
 // addDependsOnEdges updates the graph with edges from objects
-// with an explicit "depends-on" annotation.
+// with an explicit "depends-on" annotation, including the objects matched
+// by its selectors. If external is true, dependencies that are not in the
+// set are recorded as external dependencies, instead of being invalid.
 // The objs and ids must match in order and length (optimization).
-func addDependsOnEdges(g *Graph, objs object.UnstructuredSet, ids object.ObjMetadataSet) error {
+func addDependsOnEdges(g *Graph, objs object.UnstructuredSet, ids object.ObjMetadataSet, opts Options) error {
 	var errors []error
 	for i, obj := range objs {
 		if !dependson.HasAnnotation(obj) {
 			continue
 		}
 		id := ids[i]
-		deps, err := dependson.ReadAnnotation(obj)
+		deps, selectors, err := dependson.ReadDependencies(obj)
 		if err != nil {
 			klog.V(3).Infof("failed to add edges from: %s: %v", id, err)
 			errors = append(errors, validation.NewError(err, id))
@@ -239,8 +272,17 @@ func addDependsOnEdges(g *Graph, objs object.UnstructuredSet, ids object.ObjMeta
 			}
 			// Mark as seen
 			seen[dep] = struct{}{}
-			// Require dependencies to be in the same resource group.
-			// Waiting for external dependencies isn't implemented (yet).
+			// Unless allowed, require dependencies to be in the same
+			// resource group.
+			if !ids.Contains(dep) && opts.SatisfiedDependencies.Contains(dep) {
+				klog.V(3).Infof("skipping satisfied dependency from: %s, to: %s", id, dep)
+				continue
+			}
+			if !ids.Contains(dep) && opts.ExternalDependencies {
+				klog.V(3).Infof("adding external dependency from: %s, to: %s", id, dep)
+				g.addExternalDependency(id, dep)
+				continue
+			}
 			if !ids.Contains(dep) {
 				err := object.InvalidAnnotationError{
 					Annotation: dependson.Annotation,
@@ -256,7 +298,23 @@ func addDependsOnEdges(g *Graph, objs object.UnstructuredSet, ids object.ObjMeta
 				continue
 			}
 			klog.V(3).Infof("adding edge from: %s, to: %s", id, dep)
-			g.AddEdge(id, dep)
+			g.addEdgeWithReason(id, dep, DependsOnReason)
+		}
+		// Expand the selectors against the objects being applied.
+		// Objects don't depend on themselves, and objects that are also
+		// referenced explicitly or by another selector are skipped.
+		for _, selector := range selectors {
+			for j, dep := range ids {
+				if dep == id || !selector.Matches(dep, objs[j].GetLabels()) {
+					continue
+				}
+				if _, found := seen[dep]; found {
+					continue
+				}
+				seen[dep] = struct{}{}
+				klog.V(3).Infof("adding edge from: %s, to: %s (selector: %s)", id, dep, selector)
+				g.addEdgeWithReason(id, dep, DependsOnReason)
+			}
 		}
 		if len(objErrors) > 0 {
 			errors = append(errors,
@@ -292,7 +350,7 @@ func addCRDEdges(g *Graph, objs object.UnstructuredSet, ids object.ObjMetadataSe
 		if to, found := crds[groupKind.String()]; found {
 			from := ids[i]
 			klog.V(3).Infof("adding edge from: custom resource %s, to CRD: %s", from, to)
-			g.AddEdge(from, to)
+			g.addEdgeWithReason(from, to, CRDReason)
 		}
 	}
 }
@@ -318,8 +376,61 @@ func addNamespaceEdges(g *Graph, objs object.UnstructuredSet, ids object.ObjMeta
 			if to, found := namespaces[objNamespace]; found {
 				from := ids[i]
 				klog.V(3).Infof("adding edge from: %s to namespace: %s", from, to)
-				g.AddEdge(from, to)
+				g.addEdgeWithReason(from, to, NamespaceReason)
 			}
 		}
 	}
 }
+
+// addWebhookEdges adds edges to the dependency graph from objects admitted
+// by the webhooks of webhook configurations with the webhook-gating
+// annotation, to the Service and workloads serving the webhooks. Ensures the
+// webhooks are ready before the objects they admit are applied. Edges that
+// would create a cycle are skipped, because the webhook cannot be ready
+// before its own dependencies are applied.
+// The objs and ids must match in order and length (optimization).
+func addWebhookEdges(g *Graph, objs object.UnstructuredSet, ids object.ObjMetadataSet) error {
+	var errors []error
+	for i, obj := range objs {
+		if !webhook.IsWebhookConfiguration(obj) {
+			continue
+		}
+		id := ids[i]
+		enabled, err := webhook.ReadAnnotation(obj)
+		if err != nil {
+			klog.V(3).Infof("failed to add edges to: %s: %v", id, err)
+			errors = append(errors, validation.NewError(err, id))
+			continue
+		}
+		if !enabled {
+			continue
+		}
+		hooks, err := webhook.ReadWebhooks(obj)
+		if err != nil {
+			klog.V(3).Infof("failed to add edges to: %s: %v", id, err)
+			errors = append(errors, validation.NewError(err, id))
+			continue
+		}
+		for _, hook := range hooks {
+			backends := hook.Backends(objs)
+			for j, admitted := range objs {
+				// Webhooks are never called for webhook configurations.
+				if webhook.IsWebhookConfiguration(admitted) || !hook.Admits(admitted) {
+					continue
+				}
+				from := ids[j]
+				for _, to := range backends {
+					if from == to || g.reachable(to, from) {
+						continue
+					}
+					klog.V(3).Infof("adding edge from: %s, to webhook backend: %s", from, to)
+					g.addEdgeWithReason(from, to, WebhookReason)
+				}
+			}
+		}
+	}
+	if len(errors) > 0 {
+		return multierror.Wrap(errors...)
+	}
+	return nil
+}
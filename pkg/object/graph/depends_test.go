@@ -19,6 +19,7 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/object/mutation"
 	mutationutil "sigs.k8s.io/cli-utils/pkg/object/mutation/testutil"
 	"sigs.k8s.io/cli-utils/pkg/object/validation"
+	"sigs.k8s.io/cli-utils/pkg/object/webhook"
 	"sigs.k8s.io/cli-utils/pkg/testutil"
 )
 
@@ -1221,6 +1222,62 @@ func TestAddDependsOnEdges(t *testing.T) {
 				},
 			},
 		},
+		"group/kind wildcard selector adds edges to all matching objects": {
+			objs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["crontab1"],
+					testutil.AddAnnotation(dependson.Annotation, "apiextensions.k8s.io/CustomResourceDefinition/*")),
+				testutil.Unstructured(t, resources["crd"]),
+				testutil.Unstructured(t, resources["pod"]),
+			},
+			expected: []Edge{
+				{
+					From: testutil.ToIdentifier(t, resources["crontab1"]),
+					To:   testutil.ToIdentifier(t, resources["crd"]),
+				},
+			},
+		},
+		"namespace selector skips self and cluster-scoped objects": {
+			objs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["pod"],
+					testutil.AddAnnotation(dependson.Annotation, "*/namespaces/test-namespace/*/*")),
+				testutil.Unstructured(t, resources["deployment"]),
+				testutil.Unstructured(t, resources["secret"]),
+				testutil.Unstructured(t, resources["namespace"]),
+				testutil.Unstructured(t, resources["default-pod"]),
+			},
+			expected: []Edge{
+				{
+					From: testutil.ToIdentifier(t, resources["pod"]),
+					To:   testutil.ToIdentifier(t, resources["deployment"]),
+				},
+				{
+					From: testutil.ToIdentifier(t, resources["pod"]),
+					To:   testutil.ToIdentifier(t, resources["secret"]),
+				},
+			},
+		},
+		"selector matching an explicit dependency is not a duplicate": {
+			objs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["pod"],
+					testutil.AddAnnotation(dependson.Annotation,
+						"/namespaces/test-namespace/Secret/secret,/namespaces/*/Secret/*")),
+				testutil.Unstructured(t, resources["secret"]),
+			},
+			expected: []Edge{
+				{
+					From: testutil.ToIdentifier(t, resources["pod"]),
+					To:   testutil.ToIdentifier(t, resources["secret"]),
+				},
+			},
+		},
+		"selector matching no objects adds no edges": {
+			objs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["pod"],
+					testutil.AddAnnotation(dependson.Annotation, "apps/namespaces/*/StatefulSet/*")),
+				testutil.Unstructured(t, resources["deployment"]),
+			},
+			expected: []Edge{},
+		},
 		"error: invalid annotation": {
 			objs: []*unstructured.Unstructured{
 				{
@@ -1425,7 +1482,7 @@ func TestAddDependsOnEdges(t *testing.T) {
 		t.Run(tn, func(t *testing.T) {
 			g := New()
 			ids := object.UnstructuredSetToObjMetadataSet(tc.objs)
-			err := addDependsOnEdges(g, tc.objs, ids)
+			err := addDependsOnEdges(g, tc.objs, ids, Options{})
 			if tc.expectedError != nil {
 				assert.EqualError(t, err, tc.expectedError.Error())
 			} else {
@@ -1437,6 +1494,38 @@ func TestAddDependsOnEdges(t *testing.T) {
 	}
 }
 
+func TestDependencyGraphExternalDependencies(t *testing.T) {
+	pod := testutil.Unstructured(t, resources["pod"],
+		testutil.AddDependsOn(t,
+			testutil.ToIdentifier(t, resources["deployment"]),
+			testutil.ToIdentifier(t, resources["secret"]),
+		),
+	)
+	secret := testutil.Unstructured(t, resources["secret"])
+	podID := testutil.ToIdentifier(t, resources["pod"])
+	secretID := testutil.ToIdentifier(t, resources["secret"])
+	deploymentID := testutil.ToIdentifier(t, resources["deployment"])
+
+	// By default, dependencies outside the set are invalid.
+	_, err := DependencyGraph(object.UnstructuredSet{pod, secret})
+	assert.Error(t, err)
+
+	g, err := DependencyGraphWithOptions(object.UnstructuredSet{pod, secret},
+		Options{ExternalDependencies: true})
+	require.NoError(t, err)
+	verifyEdges(t, []Edge{{From: podID, To: secretID}}, edgeMapToList(g.edges))
+	assert.Equal(t, object.ObjMetadataSet{deploymentID}, g.ExternalDependencies(podID))
+	assert.Empty(t, g.ExternalDependencies(secretID))
+	assert.Equal(t, object.ObjMetadataSet{deploymentID}, g.AllExternalDependencies())
+
+	// Satisfied dependencies are neither invalid nor external.
+	g, err = DependencyGraphWithOptions(object.UnstructuredSet{pod, secret},
+		Options{SatisfiedDependencies: object.ObjMetadataSet{deploymentID}})
+	require.NoError(t, err)
+	verifyEdges(t, []Edge{{From: podID, To: secretID}}, edgeMapToList(g.edges))
+	assert.Empty(t, g.AllExternalDependencies())
+}
+
 func TestAddNamespaceEdges(t *testing.T) {
 	testCases := map[string]struct {
 		objs     []*unstructured.Unstructured
@@ -1566,6 +1655,156 @@ func TestAddCRDEdges(t *testing.T) {
 	}
 }
 
+func TestAddWebhookEdges(t *testing.T) {
+	webhookConfig := `
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: test-webhook
+  annotations:
+    config.kubernetes.io/webhook-gating: "true"
+webhooks:
+- name: validate.example.com
+  clientConfig:
+    service:
+      namespace: test-namespace
+      name: webhook
+  rules:
+  - operations: ["CREATE", "UPDATE"]
+    apiGroups: [""]
+    apiVersions: ["v1"]
+    resources: ["secrets", "pods"]
+`
+	webhookService := `
+apiVersion: v1
+kind: Service
+metadata:
+  name: webhook
+  namespace: test-namespace
+spec:
+  selector:
+    app: webhook
+`
+	webhookDeployment := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: webhook
+  namespace: test-namespace
+spec:
+  template:
+    metadata:
+      labels:
+        app: webhook
+`
+	disabled := testutil.Unstructured(t, webhookConfig)
+	disabled.SetAnnotations(nil)
+	invalid := testutil.Unstructured(t, webhookConfig)
+	invalid.SetAnnotations(map[string]string{webhook.Annotation: "yes"})
+
+	testCases := map[string]struct {
+		objs          []*unstructured.Unstructured
+		expected      []Edge
+		expectedError error
+	}{
+		"webhook without gating annotation adds no graph edges": {
+			objs: []*unstructured.Unstructured{
+				disabled,
+				testutil.Unstructured(t, webhookService),
+				testutil.Unstructured(t, webhookDeployment),
+				testutil.Unstructured(t, resources["secret"]),
+			},
+			expected: []Edge{},
+		},
+		"admitted objects depend on the webhook service and workload": {
+			objs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, webhookConfig),
+				testutil.Unstructured(t, webhookService),
+				testutil.Unstructured(t, webhookDeployment),
+				testutil.Unstructured(t, resources["secret"]),
+				testutil.Unstructured(t, resources["pod"]),
+				testutil.Unstructured(t, resources["deployment"]),
+			},
+			expected: []Edge{
+				{
+					From: testutil.ToIdentifier(t, resources["secret"]),
+					To:   testutil.ToIdentifier(t, webhookService),
+				},
+				{
+					From: testutil.ToIdentifier(t, resources["secret"]),
+					To:   testutil.ToIdentifier(t, webhookDeployment),
+				},
+				{
+					From: testutil.ToIdentifier(t, resources["pod"]),
+					To:   testutil.ToIdentifier(t, webhookService),
+				},
+				{
+					From: testutil.ToIdentifier(t, resources["pod"]),
+					To:   testutil.ToIdentifier(t, webhookDeployment),
+				},
+			},
+		},
+		"webhook without service in the objects adds no graph edges": {
+			objs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, webhookConfig),
+				testutil.Unstructured(t, webhookDeployment),
+				testutil.Unstructured(t, resources["secret"]),
+			},
+			expected: []Edge{},
+		},
+		"edges that would create a cycle are skipped": {
+			objs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, webhookConfig),
+				testutil.Unstructured(t, webhookService),
+				testutil.Unstructured(t, webhookDeployment,
+					testutil.AddDependsOn(t, testutil.ToIdentifier(t, resources["secret"]))),
+				testutil.Unstructured(t, resources["secret"]),
+			},
+			expected: []Edge{
+				{
+					From: testutil.ToIdentifier(t, webhookDeployment),
+					To:   testutil.ToIdentifier(t, resources["secret"]),
+				},
+				{
+					From: testutil.ToIdentifier(t, resources["secret"]),
+					To:   testutil.ToIdentifier(t, webhookService),
+				},
+			},
+		},
+		"invalid gating annotation": {
+			objs: []*unstructured.Unstructured{
+				invalid,
+				testutil.Unstructured(t, webhookService),
+			},
+			expected: []Edge{},
+			expectedError: validation.NewError(
+				object.InvalidAnnotationError{
+					Annotation: webhook.Annotation,
+					Cause:      errors.New(`must be true or false, got "yes"`),
+				},
+				object.UnstructuredToObjMetadata(invalid),
+			),
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			g := New()
+			ids := object.UnstructuredSetToObjMetadataSet(tc.objs)
+			addVertices(g, ids)
+			require.NoError(t, addDependsOnEdges(g, tc.objs, ids, Options{}))
+			err := addWebhookEdges(g, tc.objs, ids)
+			if tc.expectedError != nil {
+				require.EqualError(t, err, tc.expectedError.Error())
+			} else {
+				require.NoError(t, err)
+			}
+			actual := edgeMapToList(g.edges)
+			verifyEdges(t, tc.expected, actual)
+		})
+	}
+}
+
 // verifyObjSets ensures the expected and actual slice of object sets are the same,
 // and the sets are in order.
 func verifyObjSets(t *testing.T, expected []object.UnstructuredSet, actual []object.UnstructuredSet) {
@@ -6,9 +6,11 @@ package graph
 import (
 	"bytes"
 	"fmt"
+	"strings"
 
 	"sigs.k8s.io/cli-utils/pkg/multierror"
 	"sigs.k8s.io/cli-utils/pkg/object/mutation"
+	"sigs.k8s.io/cli-utils/pkg/reason"
 )
 
 // ExternalDependencyError represents an invalid graph edge caused by an
@@ -23,10 +25,21 @@ func (ede ExternalDependencyError) Error() string {
 		mutation.ResourceReferenceFromObjMetadata(ede.Edge.To))
 }
 
+func (ede ExternalDependencyError) ErrorReason() reason.Reason {
+	return reason.ExternalDependency
+}
+
+func (ede ExternalDependencyError) ErrorRetryable() bool {
+	return false
+}
+
 // CyclicDependencyError represents a cycle in the graph, making topological
 // sort impossible.
 type CyclicDependencyError struct {
 	Edges []Edge
+	// Rules are the names of the rules that added the edges, for the edges
+	// added by rules.
+	Rules map[Edge][]string
 }
 
 func (cde CyclicDependencyError) Error() string {
@@ -36,10 +49,21 @@ func (cde CyclicDependencyError) Error() string {
 		errorBuf.WriteString(fmt.Sprintf("\n%s%s -> %s", multierror.Prefix,
 			mutation.ResourceReferenceFromObjMetadata(edge.From),
 			mutation.ResourceReferenceFromObjMetadata(edge.To)))
+		if rules := cde.Rules[edge]; len(rules) > 0 {
+			errorBuf.WriteString(fmt.Sprintf(" (rule: %s)", strings.Join(rules, ", ")))
+		}
 	}
 	return errorBuf.String()
 }
 
+func (cde CyclicDependencyError) ErrorReason() reason.Reason {
+	return reason.DependencyCycle
+}
+
+func (cde CyclicDependencyError) ErrorRetryable() bool {
+	return false
+}
+
 // DuplicateDependencyError represents an invalid depends-on annotation with
 // duplicate references.
 type DuplicateDependencyError struct {
@@ -51,3 +75,11 @@ func (dde DuplicateDependencyError) Error() string {
 		mutation.ResourceReferenceFromObjMetadata(dde.Edge.From),
 		mutation.ResourceReferenceFromObjMetadata(dde.Edge.To))
 }
+
+func (dde DuplicateDependencyError) ErrorReason() reason.Reason {
+	return reason.InvalidAnnotation
+}
+
+func (dde DuplicateDependencyError) ErrorRetryable() bool {
+	return false
+}
@@ -0,0 +1,216 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package graph
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/cli-utils/pkg/multierror"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/ordering"
+)
+
+// EdgeReason describes why an edge was added to the dependency graph.
+type EdgeReason string
+
+const (
+	// DependsOnReason is used for edges from the depends-on annotation.
+	DependsOnReason EdgeReason = "depends-on"
+	// ApplyTimeMutationReason is used for edges from the apply-time-mutation
+	// annotation.
+	ApplyTimeMutationReason EdgeReason = "apply-time-mutation"
+	// NamespaceReason is used for edges from namespaced objects to their
+	// namespace.
+	NamespaceReason EdgeReason = "namespace"
+	// CRDReason is used for edges from custom resources to their CRD.
+	CRDReason EdgeReason = "crd"
+	// WebhookReason is used for edges from objects admitted by a webhook to
+	// the Service and workloads serving the webhook.
+	WebhookReason EdgeReason = "webhook"
+)
+
+// builtInReasons are the reasons of the edges that are not added by rules.
+var builtInReasons = []EdgeReason{
+	DependsOnReason,
+	ApplyTimeMutationReason,
+	NamespaceReason,
+	CRDReason,
+	WebhookReason,
+}
+
+// isBuiltIn returns true if the reason is not the name of a rule.
+func (r EdgeReason) isBuiltIn() bool {
+	for _, b := range builtInReasons {
+		if r == b {
+			return true
+		}
+	}
+	return false
+}
+
+// LabeledEdge is an edge with the reasons it was added to the graph.
+type LabeledEdge struct {
+	Edge
+	Reasons []EdgeReason
+}
+
+// LabeledEdges returns a sorted slice of the edges in the graph, with the
+// reasons they were added. Edges added with AddEdge have no reasons.
+func (g *Graph) LabeledEdges() []LabeledEdge {
+	edges := edgeMapToList(g.edges)
+	labeled := make([]LabeledEdge, len(edges))
+	for i, e := range edges {
+		labeled[i] = LabeledEdge{Edge: e, Reasons: g.reasons[e]}
+	}
+	return labeled
+}
+
+// EdgeReasons returns the reasons the edge "from" -> "to" was added to the
+// graph, or nil if unknown.
+func (g *Graph) EdgeReasons(from, to object.ObjMetadata) []EdgeReason {
+	return g.reasons[Edge{From: from, To: to}]
+}
+
+// Vertices returns a sorted set of the vertices in the graph.
+func (g *Graph) Vertices() object.ObjMetadataSet {
+	return edgeMapKeys(g.edges)
+}
+
+// ResolvedGraph is the dependency graph of a set of objects, as used to
+// order their apply.
+type ResolvedGraph struct {
+	Vertices object.ObjMetadataSet
+	Edges    []LabeledEdge
+	// Groups are the sets of objects applied together, in apply order.
+	// Objects in a dependency cycle are not in any group.
+	Groups []object.ObjMetadataSet
+}
+
+// Resolve returns the dependency graph of the objects, including the
+// implicit dependencies of the passed rules. Like SortObjs, the graph is
+// returned even if invalid dependencies or cycles are found, along with the
+// errors.
+func Resolve(objs object.UnstructuredSet, rules ...Rule) (*ResolvedGraph, error) {
+	var errors []error
+	g, err := DependencyGraph(objs, rules...)
+	if err != nil {
+		// collect and continue
+		errors = multierror.Unwrap(err)
+	}
+	groups, err := g.Sort()
+	if err != nil {
+		errors = append(errors, err)
+	}
+	for _, group := range groups {
+		sort.Sort(ordering.SortableMetas(group))
+	}
+	r := &ResolvedGraph{
+		Vertices: g.Vertices(),
+		Edges:    g.LabeledEdges(),
+		Groups:   groups,
+	}
+	if len(errors) > 0 {
+		return r, multierror.Wrap(errors...)
+	}
+	return r, nil
+}
+
+// WriteDOT renders the graph in the Graphviz DOT format. Edges point from
+// an object to the object it depends on, and each apply group is rendered
+// as a cluster.
+func (r *ResolvedGraph) WriteDOT(w io.Writer) error {
+	ids := r.vertexIDs()
+	var sb strings.Builder
+	sb.WriteString("digraph dependencies {\n")
+	grouped := make(map[object.ObjMetadata]bool, len(r.Vertices))
+	for i, group := range r.Groups {
+		fmt.Fprintf(&sb, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&sb, "    label=%q;\n", fmt.Sprintf("group %d", i))
+		for _, v := range group {
+			fmt.Fprintf(&sb, "    %s [label=%q];\n", ids[v], vertexLabel(v))
+			grouped[v] = true
+		}
+		sb.WriteString("  }\n")
+	}
+	for _, v := range r.Vertices {
+		if !grouped[v] {
+			fmt.Fprintf(&sb, "  %s [label=%q];\n", ids[v], vertexLabel(v))
+		}
+	}
+	for _, e := range r.Edges {
+		if len(e.Reasons) > 0 {
+			fmt.Fprintf(&sb, "  %s -> %s [label=%q];\n", ids[e.From], ids[e.To], reasonsLabel(e.Reasons))
+		} else {
+			fmt.Fprintf(&sb, "  %s -> %s;\n", ids[e.From], ids[e.To])
+		}
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteMermaid renders the graph as a Mermaid flowchart. Edges point from
+// an object to the object it depends on, and each apply group is rendered
+// as a subgraph.
+func (r *ResolvedGraph) WriteMermaid(w io.Writer) error {
+	ids := r.vertexIDs()
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	grouped := make(map[object.ObjMetadata]bool, len(r.Vertices))
+	for i, group := range r.Groups {
+		fmt.Fprintf(&sb, "  subgraph group%d [\"group %d\"]\n", i, i)
+		for _, v := range group {
+			fmt.Fprintf(&sb, "    %s[\"%s\"]\n", ids[v], vertexLabel(v))
+			grouped[v] = true
+		}
+		sb.WriteString("  end\n")
+	}
+	for _, v := range r.Vertices {
+		if !grouped[v] {
+			fmt.Fprintf(&sb, "  %s[\"%s\"]\n", ids[v], vertexLabel(v))
+		}
+	}
+	for _, e := range r.Edges {
+		if len(e.Reasons) > 0 {
+			fmt.Fprintf(&sb, "  %s -->|%s| %s\n", ids[e.From], reasonsLabel(e.Reasons), ids[e.To])
+		} else {
+			fmt.Fprintf(&sb, "  %s --> %s\n", ids[e.From], ids[e.To])
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// vertexIDs returns the node identifier of each vertex, by vertex index.
+func (r *ResolvedGraph) vertexIDs() map[object.ObjMetadata]string {
+	ids := make(map[object.ObjMetadata]string, len(r.Vertices))
+	for i, v := range r.Vertices {
+		ids[v] = fmt.Sprintf("n%d", i)
+	}
+	return ids
+}
+
+// vertexLabel returns a human readable label for the object, e.g.
+// "Deployment.apps default/foo".
+func vertexLabel(id object.ObjMetadata) string {
+	kind := id.GroupKind.Kind
+	if id.GroupKind.Group != "" {
+		kind = kind + "." + id.GroupKind.Group
+	}
+	if id.Namespace == "" {
+		return kind + " " + id.Name
+	}
+	return kind + " " + id.Namespace + "/" + id.Name
+}
+
+func reasonsLabel(reasons []EdgeReason) string {
+	labels := make([]string, len(reasons))
+	for i, r := range reasons {
+		labels[i] = string(r)
+	}
+	return strings.Join(labels, ", ")
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestResolve(t *testing.T) {
+	namespace := testutil.ToIdentifier(t, resources["namespace"])
+	secret := testutil.ToIdentifier(t, resources["secret"])
+	deployment := testutil.ToIdentifier(t, resources["deployment"])
+	objs := object.UnstructuredSet{
+		testutil.Unstructured(t, resources["deployment"], testutil.AddDependsOn(t, secret)),
+		testutil.Unstructured(t, resources["secret"]),
+		testutil.Unstructured(t, resources["namespace"]),
+	}
+
+	r, err := Resolve(objs)
+	require.NoError(t, err)
+	assert.Equal(t, object.ObjMetadataSet{namespace, secret, deployment}, r.Vertices)
+	assert.Equal(t, []LabeledEdge{
+		{
+			Edge:    Edge{From: secret, To: namespace},
+			Reasons: []EdgeReason{NamespaceReason},
+		},
+		{
+			Edge:    Edge{From: deployment, To: namespace},
+			Reasons: []EdgeReason{NamespaceReason},
+		},
+		{
+			Edge:    Edge{From: deployment, To: secret},
+			Reasons: []EdgeReason{DependsOnReason},
+		},
+	}, r.Edges)
+	assert.Equal(t, []object.ObjMetadataSet{{namespace}, {secret}, {deployment}}, r.Groups)
+
+	var dot strings.Builder
+	require.NoError(t, r.WriteDOT(&dot))
+	assert.Equal(t, `digraph dependencies {
+  subgraph cluster_0 {
+    label="group 0";
+    n0 [label="Namespace test-namespace"];
+  }
+  subgraph cluster_1 {
+    label="group 1";
+    n1 [label="Secret test-namespace/secret"];
+  }
+  subgraph cluster_2 {
+    label="group 2";
+    n2 [label="Deployment.apps test-namespace/foo"];
+  }
+  n1 -> n0 [label="namespace"];
+  n2 -> n0 [label="namespace"];
+  n2 -> n1 [label="depends-on"];
+}
+`, dot.String())
+
+	var mermaid strings.Builder
+	require.NoError(t, r.WriteMermaid(&mermaid))
+	assert.Equal(t, `flowchart TD
+  subgraph group0 ["group 0"]
+    n0["Namespace test-namespace"]
+  end
+  subgraph group1 ["group 1"]
+    n1["Secret test-namespace/secret"]
+  end
+  subgraph group2 ["group 2"]
+    n2["Deployment.apps test-namespace/foo"]
+  end
+  n1 -->|namespace| n0
+  n2 -->|namespace| n0
+  n2 -->|depends-on| n1
+`, mermaid.String())
+}
+
+func TestResolve_Cycle(t *testing.T) {
+	secret := testutil.ToIdentifier(t, resources["secret"])
+	deployment := testutil.ToIdentifier(t, resources["deployment"])
+	objs := object.UnstructuredSet{
+		testutil.Unstructured(t, resources["deployment"], testutil.AddDependsOn(t, secret)),
+		testutil.Unstructured(t, resources["secret"], testutil.AddDependsOn(t, deployment)),
+	}
+
+	r, err := Resolve(objs)
+	require.Error(t, err)
+	assert.Empty(t, r.Groups)
+
+	var dot strings.Builder
+	require.NoError(t, r.WriteDOT(&dot))
+	assert.Equal(t, `digraph dependencies {
+  n0 [label="Secret test-namespace/secret"];
+  n1 [label="Deployment.apps test-namespace/foo"];
+  n0 -> n1 [label="depends-on"];
+  n1 -> n0 [label="depends-on"];
+}
+`, dot.String())
+}
@@ -22,6 +22,10 @@ type Graph struct {
 	edges map[object.ObjMetadata]object.ObjMetadataSet
 	// map "to" vertex -> list of "from" vertices
 	reverseEdges map[object.ObjMetadata]object.ObjMetadataSet
+	// map edge -> reasons the edge was added, if known
+	reasons map[Edge][]EdgeReason
+	// map "from" vertex -> list of dependencies that are not vertices
+	external map[object.ObjMetadata]object.ObjMetadataSet
 }
 
 // New returns a pointer to an empty Graph data structure.
@@ -29,6 +33,7 @@ func New() *Graph {
 	g := &Graph{}
 	g.edges = make(map[object.ObjMetadata]object.ObjMetadataSet)
 	g.reverseEdges = make(map[object.ObjMetadata]object.ObjMetadataSet)
+	g.reasons = make(map[Edge][]EdgeReason)
 	return g
 }
 
@@ -80,6 +85,34 @@ func (g *Graph) AddEdge(from object.ObjMetadata, to object.ObjMetadata) {
 	}
 }
 
+// addEdgeWithReason adds a edge from one ObjMetadata vertex to another, and
+// records why the edge was added.
+func (g *Graph) addEdgeWithReason(from object.ObjMetadata, to object.ObjMetadata, reason EdgeReason) {
+	g.AddEdge(from, to)
+	if g.reasons == nil {
+		g.reasons = make(map[Edge][]EdgeReason)
+	}
+	e := Edge{From: from, To: to}
+	for _, r := range g.reasons[e] {
+		if r == reason {
+			return
+		}
+	}
+	g.reasons[e] = append(g.reasons[e], reason)
+}
+
+// addExternalDependency records that the from vertex depends on an object
+// that is not a vertex of the graph.
+func (g *Graph) addExternalDependency(from object.ObjMetadata, to object.ObjMetadata) {
+	if g.external == nil {
+		g.external = make(map[object.ObjMetadata]object.ObjMetadataSet)
+	}
+	if g.external[from].Contains(to) {
+		return
+	}
+	g.external[from] = append(g.external[from], to)
+}
+
 // edgeMapToList returns a sorted slice of directed graph edges (vertex pairs).
 func edgeMapToList(edgeMap map[object.ObjMetadata]object.ObjMetadataSet) []Edge {
 	edges := []Edge{}
@@ -135,6 +168,29 @@ func (g *Graph) Dependencies(from object.ObjMetadata) object.ObjMetadataSet {
 	return c
 }
 
+// ExternalDependencies returns the objects that this object depends on,
+// which are not vertices of the graph.
+func (g *Graph) ExternalDependencies(from object.ObjMetadata) object.ObjMetadataSet {
+	deps, exists := g.external[from]
+	if !exists {
+		return nil
+	}
+	c := make(object.ObjMetadataSet, len(deps))
+	copy(c, deps)
+	return c
+}
+
+// AllExternalDependencies returns the objects that any vertex depends on,
+// which are not vertices of the graph, sorted.
+func (g *Graph) AllExternalDependencies() object.ObjMetadataSet {
+	var all object.ObjMetadataSet
+	for _, deps := range g.external {
+		all = all.Union(deps)
+	}
+	sort.Sort(ordering.SortableMetas(all))
+	return all
+}
+
 // Dependents returns the objects that depend on this object.
 func (g *Graph) Dependents(to object.ObjMetadata) object.ObjMetadataSet {
 	edgesTo, exists := g.reverseEdges[to]
@@ -146,6 +202,59 @@ func (g *Graph) Dependents(to object.ObjMetadata) object.ObjMetadataSet {
 	return c
 }
 
+// reachable returns true if there is a path of edges from one vertex to
+// another, i.e. if "from" depends on "to", directly or indirectly.
+func (g *Graph) reachable(from object.ObjMetadata, to object.ObjMetadata) bool {
+	visited := make(map[object.ObjMetadata]bool)
+	stack := object.ObjMetadataSet{from}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if v == to {
+			return true
+		}
+		if visited[v] {
+			continue
+		}
+		visited[v] = true
+		stack = append(stack, g.edges[v]...)
+	}
+	return false
+}
+
+// Subgraph returns the graph of the vertices in ids. A vertex depends on
+// another if it does in this graph, directly or through vertices that are
+// not in ids, so the order of the vertices is preserved. Reasons are only
+// kept for direct edges.
+func (g *Graph) Subgraph(ids object.ObjMetadataSet) *Graph {
+	sub := New()
+	for _, v := range ids {
+		if _, exists := g.edges[v]; !exists {
+			continue
+		}
+		sub.AddVertex(v)
+		visited := make(map[object.ObjMetadata]bool)
+		stack := append(object.ObjMetadataSet{}, g.edges[v]...)
+		for len(stack) > 0 {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if visited[w] {
+				continue
+			}
+			visited[w] = true
+			if ids.Contains(w) {
+				sub.AddEdge(v, w)
+				for _, r := range g.reasons[Edge{From: v, To: w}] {
+					sub.addEdgeWithReason(v, w, r)
+				}
+				continue
+			}
+			stack = append(stack, g.edges[w]...)
+		}
+	}
+	return sub
+}
+
 // Sort returns the ordered set of vertices after a topological sort.
 func (g *Graph) Sort() ([]object.ObjMetadataSet, error) {
 	// deep copy edge map to avoid destructive sorting
@@ -169,8 +278,22 @@ func (g *Graph) Sort() ([]object.ObjMetadataSet, error) {
 		// where remaining edges define the cycle.
 		if len(leafVertices) == 0 {
 			// Error can be ignored, so return the full set list
+			cycleEdges := edgeMapToList(edges)
+			var rules map[Edge][]string
+			for _, e := range cycleEdges {
+				for _, r := range g.reasons[e] {
+					if r.isBuiltIn() {
+						continue
+					}
+					if rules == nil {
+						rules = make(map[Edge][]string)
+					}
+					rules[e] = append(rules[e], string(r))
+				}
+			}
 			return sorted, validation.NewError(CyclicDependencyError{
-				Edges: edgeMapToList(edges),
+				Edges: cycleEdges,
+				Rules: rules,
 			}, edgeMapKeys(edges)...)
 		}
 		// Remove all edges to leaf vertices.
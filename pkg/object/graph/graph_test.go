@@ -254,3 +254,23 @@ func TestGraphDependents(t *testing.T) {
 		})
 	}
 }
+
+func TestGraphSubgraph(t *testing.T) {
+	// o1 -> o2 -> o3 -> o4, o5
+	g := New()
+	g.AddVertex(o5)
+	g.AddEdge(o1, o2)
+	g.AddEdge(o2, o3)
+	g.AddEdge(o3, o4)
+
+	sub := g.Subgraph(object.ObjMetadataSet{o1, o3, o5})
+	assert.Equal(t, 3, sub.Size())
+	// o1 depends on o3 through o2, which is not in the subgraph.
+	testutil.AssertEqual(t, object.ObjMetadataSet{o3}, sub.Dependencies(o1))
+	testutil.AssertEqual(t, object.ObjMetadataSet{}, sub.Dependencies(o3))
+	testutil.AssertEqual(t, object.ObjMetadataSet{}, sub.Dependencies(o5))
+
+	sorted, err := sub.Sort()
+	assert.NoError(t, err)
+	testutil.AssertEqual(t, []object.ObjMetadataSet{{o3, o5}, {o1}}, sorted)
+}
@@ -0,0 +1,181 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package graph
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/multierror"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Rule adds implicit dependencies between objects, in addition to the
+// built-in ones like namespaces and CRDs, e.g. from Deployments to the
+// ServiceAccounts they reference.
+type Rule struct {
+	// Name identifies the rule. It is the reason of the edges added by the
+	// rule, and named in the errors of cycles through these edges. Required,
+	// and must not be the reason of a built-in edge, e.g. "namespace".
+	Name string
+	// FromKinds are the kinds of the dependent objects the rule applies to.
+	// The rule applies to objects of any kind if empty.
+	FromKinds []schema.GroupKind
+	// ToKinds are the kinds of the dependencies the rule applies to. The
+	// rule applies to objects of any kind if empty.
+	ToKinds []schema.GroupKind
+	// DependsOn returns true if the "from" object must be applied after the
+	// "to" object. Required.
+	DependsOn func(from, to *unstructured.Unstructured) bool
+}
+
+// Validate returns an error if the rule has no name, a name of a built-in
+// edge reason, or no DependsOn function.
+func (r Rule) Validate() error {
+	switch {
+	case r.Name == "":
+		return errors.New("invalid dependency rule: name is required")
+	case EdgeReason(r.Name).isBuiltIn():
+		return fmt.Errorf("invalid dependency rule %q: name is reserved for built-in dependencies", r.Name)
+	case r.DependsOn == nil:
+		return fmt.Errorf("invalid dependency rule %q: DependsOn is required", r.Name)
+	}
+	return nil
+}
+
+// matchesKind returns true if the object is of one of the kinds, or if there
+// are no kinds.
+func matchesKind(kinds []schema.GroupKind, obj *unstructured.Unstructured) bool {
+	if len(kinds) == 0 {
+		return true
+	}
+	gk := obj.GroupVersionKind().GroupKind()
+	for _, k := range kinds {
+		if k == gk {
+			return true
+		}
+	}
+	return false
+}
+
+// addRuleEdges adds the edges of the rules to the dependency graph.
+// Invalid rules are skipped and returned as errors.
+// The objs and ids must match in order and length (optimization).
+func addRuleEdges(g *Graph, objs object.UnstructuredSet, ids object.ObjMetadataSet, rules []Rule) error {
+	var errs []error
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var froms, tos []int
+		for i, obj := range objs {
+			if matchesKind(rule.FromKinds, obj) {
+				froms = append(froms, i)
+			}
+			if matchesKind(rule.ToKinds, obj) {
+				tos = append(tos, i)
+			}
+		}
+		for _, i := range froms {
+			for _, j := range tos {
+				if i == j || !rule.DependsOn(objs[i], objs[j]) {
+					continue
+				}
+				klog.V(3).Infof("adding edge from: %s, to: %s (rule: %s)", ids[i], ids[j], rule.Name)
+				g.addEdgeWithReason(ids[i], ids[j], EdgeReason(rule.Name))
+			}
+		}
+	}
+	return multierror.Wrap(errs...)
+}
+
+// podTemplateKinds are the kinds of the workloads with a pod template,
+// mapped to the path of the pod spec.
+var podTemplateKinds = map[schema.GroupKind][]string{
+	{Kind: "Pod"}:                        {"spec"},
+	{Group: "apps", Kind: "Deployment"}:  {"spec", "template", "spec"},
+	{Group: "apps", Kind: "StatefulSet"}: {"spec", "template", "spec"},
+	{Group: "apps", Kind: "DaemonSet"}:   {"spec", "template", "spec"},
+	{Group: "apps", Kind: "ReplicaSet"}:  {"spec", "template", "spec"},
+	{Group: "batch", Kind: "Job"}:        {"spec", "template", "spec"},
+	{Group: "batch", Kind: "CronJob"}:    {"spec", "jobTemplate", "spec", "template", "spec"},
+	{Kind: "ReplicationController"}:      {"spec", "template", "spec"},
+}
+
+// podSpec returns the pod spec of the Pod or workload, if any.
+func podSpec(obj *unstructured.Unstructured) (map[string]interface{}, bool) {
+	path, found := podTemplateKinds[obj.GroupVersionKind().GroupKind()]
+	if !found {
+		return nil, false
+	}
+	spec, found, err := unstructured.NestedMap(obj.Object, path...)
+	if err != nil || !found {
+		return nil, false
+	}
+	return spec, true
+}
+
+func workloadKinds() []schema.GroupKind {
+	kinds := make([]schema.GroupKind, 0, len(podTemplateKinds))
+	for gk := range podTemplateKinds {
+		kinds = append(kinds, gk)
+	}
+	return kinds
+}
+
+// ServiceAccountRule returns a rule that applies Pods and workloads after
+// the ServiceAccount of their pods, in the same namespace.
+func ServiceAccountRule() Rule {
+	return Rule{
+		Name:      "service-account",
+		FromKinds: workloadKinds(),
+		ToKinds:   []schema.GroupKind{{Kind: "ServiceAccount"}},
+		DependsOn: func(from, to *unstructured.Unstructured) bool {
+			if from.GetNamespace() != to.GetNamespace() {
+				return false
+			}
+			spec, found := podSpec(from)
+			if !found {
+				return false
+			}
+			name, _, _ := unstructured.NestedString(spec, "serviceAccountName")
+			return name == to.GetName()
+		},
+	}
+}
+
+// SecretVolumeRule returns a rule that applies Pods and workloads after the
+// Secrets mounted as volumes by their pods, in the same namespace.
+func SecretVolumeRule() Rule {
+	return Rule{
+		Name:      "secret-volume",
+		FromKinds: workloadKinds(),
+		ToKinds:   []schema.GroupKind{{Kind: "Secret"}},
+		DependsOn: func(from, to *unstructured.Unstructured) bool {
+			if from.GetNamespace() != to.GetNamespace() {
+				return false
+			}
+			spec, found := podSpec(from)
+			if !found {
+				return false
+			}
+			volumes, _, _ := unstructured.NestedSlice(spec, "volumes")
+			for _, v := range volumes {
+				volume, ok := v.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				name, _, _ := unstructured.NestedString(volume, "secret", "secretName")
+				if name == to.GetName() {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package graph
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/validation"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+var ruleResources = map[string]string{
+	"deployment": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+spec:
+  template:
+    spec:
+      serviceAccountName: app
+      volumes:
+      - name: creds
+        secret:
+          secretName: creds
+`,
+	"service-account": `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: app
+  namespace: default
+`,
+	"secret": `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+  namespace: default
+`,
+	"other-secret": `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+  namespace: other
+`,
+}
+
+func TestDependencyGraph_Rules(t *testing.T) {
+	deployment := testutil.Unstructured(t, ruleResources["deployment"])
+	sa := testutil.Unstructured(t, ruleResources["service-account"])
+	secret := testutil.Unstructured(t, ruleResources["secret"])
+	otherSecret := testutil.Unstructured(t, ruleResources["other-secret"])
+	deploymentID := object.UnstructuredToObjMetadata(deployment)
+	saID := object.UnstructuredToObjMetadata(sa)
+	secretID := object.UnstructuredToObjMetadata(secret)
+
+	objs := object.UnstructuredSet{deployment, sa, secret, otherSecret}
+	g, err := DependencyGraph(objs, ServiceAccountRule(), SecretVolumeRule())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, object.ObjMetadataSet{saID, secretID}, g.Dependencies(deploymentID))
+	assert.Equal(t, []EdgeReason{"service-account"}, g.EdgeReasons(deploymentID, saID))
+	assert.Equal(t, []EdgeReason{"secret-volume"}, g.EdgeReasons(deploymentID, secretID))
+
+	// Without rules, there are no implicit edges between these objects.
+	g, err = DependencyGraph(objs)
+	require.NoError(t, err)
+	assert.Empty(t, g.Dependencies(deploymentID))
+}
+
+func TestDependencyGraph_RuleCycle(t *testing.T) {
+	deployment := testutil.Unstructured(t, ruleResources["deployment"])
+	sa := testutil.Unstructured(t, ruleResources["service-account"],
+		testutil.AddDependsOn(t, object.UnstructuredToObjMetadata(deployment)))
+	g, err := DependencyGraph(object.UnstructuredSet{deployment, sa}, ServiceAccountRule())
+	require.NoError(t, err)
+
+	_, err = g.Sort()
+	require.Error(t, err)
+	var vErr *validation.Error
+	require.True(t, errors.As(err, &vErr))
+	var cycleErr CyclicDependencyError
+	require.True(t, errors.As(vErr.Unwrap(), &cycleErr))
+	assert.Equal(t, map[Edge][]string{
+		{From: object.UnstructuredToObjMetadata(deployment), To: object.UnstructuredToObjMetadata(sa)}: {"service-account"},
+	}, cycleErr.Rules)
+	assert.Contains(t, err.Error(), "apps/namespaces/default/Deployment/app -> /namespaces/default/ServiceAccount/app (rule: service-account)")
+}
+
+func TestRule_Kinds(t *testing.T) {
+	var calls int
+	rule := Rule{
+		Name:      "test",
+		FromKinds: []schema.GroupKind{{Group: "apps", Kind: "Deployment"}},
+		DependsOn: func(from, to *unstructured.Unstructured) bool {
+			calls++
+			return false
+		},
+	}
+	_, err := DependencyGraph(object.UnstructuredSet{
+		testutil.Unstructured(t, ruleResources["deployment"]),
+		testutil.Unstructured(t, ruleResources["service-account"]),
+		testutil.Unstructured(t, ruleResources["secret"]),
+	}, rule)
+	require.NoError(t, err)
+	// Only the Deployment is a dependent object, of any other object.
+	assert.Equal(t, 2, calls)
+}
+
+func TestRule_Validate(t *testing.T) {
+	dependsOn := func(from, to *unstructured.Unstructured) bool { return true }
+	testCases := map[string]struct {
+		rule          Rule
+		expectedError string
+	}{
+		"valid": {
+			rule: Rule{Name: "test", DependsOn: dependsOn},
+		},
+		"missing name": {
+			rule:          Rule{DependsOn: dependsOn},
+			expectedError: "invalid dependency rule: name is required",
+		},
+		"built-in name": {
+			rule:          Rule{Name: "namespace", DependsOn: dependsOn},
+			expectedError: `invalid dependency rule "namespace": name is reserved for built-in dependencies`,
+		},
+		"missing DependsOn": {
+			rule:          Rule{Name: "test"},
+			expectedError: `invalid dependency rule "test": DependsOn is required`,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			err := tc.rule.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestDependencyGraph_InvalidRule(t *testing.T) {
+	deployment := testutil.Unstructured(t, ruleResources["deployment"])
+	sa := testutil.Unstructured(t, ruleResources["service-account"])
+
+	// The invalid rule is skipped instead of panicking.
+	g, err := DependencyGraph(object.UnstructuredSet{deployment, sa},
+		Rule{Name: "nil"}, ServiceAccountRule())
+	assert.EqualError(t, err, `invalid dependency rule "nil": DependsOn is required`)
+	assert.Equal(t, object.ObjMetadataSet{object.UnstructuredToObjMetadata(sa)},
+		g.Dependencies(object.UnstructuredToObjMetadata(deployment)))
+}
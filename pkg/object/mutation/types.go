@@ -44,8 +44,10 @@ func (a ApplyTimeMutation) Equal(b ApplyTimeMutation) bool {
 }
 
 // FieldSubstitution specifies a substitution that will be performed at
-// apply-time. The source object field will be read and substituted into the
-// target object field, replacing the token.
+// apply-time. The source object field will be read, optionally transformed,
+// and substituted into the target object field, replacing the token.
+// Multiple substitutions may target the same field with different tokens,
+// to build a value from several source fields.
 type FieldSubstitution struct {
 	// SourceRef is a reference to the object that contains the source field.
 	SourceRef ResourceReference `json:"sourceRef"`
@@ -63,6 +65,19 @@ type FieldSubstitution struct {
 	// Example: "${project-number}"
 	// +optional
 	Token string `json:"token,omitempty"`
+
+	// Base64Decode decodes the source field value from base64 before it
+	// is substituted. This allows values to be read from the data of a
+	// Secret.
+	// +optional
+	Base64Decode bool `json:"base64Decode,omitempty"`
+
+	// Offset is added to the source field value before it is substituted.
+	// If set, the source field value must be an integer, or a string
+	// containing an integer.
+	// Example: 1000
+	// +optional
+	Offset int64 `json:"offset,omitempty"`
 }
 
 // ResourceReference is a reference to a KRM resource by name and kind.
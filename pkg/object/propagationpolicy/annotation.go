@@ -0,0 +1,48 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package propagationpolicy provides functions to read the
+// delete-propagation-policy annotation, which overrides the deletion
+// propagation policy for a single object, e.g. to orphan the pods of a
+// StatefulSet when it is deleted.
+package propagationpolicy
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	Annotation = "config.kubernetes.io/delete-propagation-policy"
+)
+
+// ReadAnnotation reads the delete-propagation-policy annotation.
+// The second return value is false if the annotation is not present, in
+// which case the global propagation policy applies.
+func ReadAnnotation(u *unstructured.Unstructured) (metav1.DeletionPropagation, bool, error) {
+	if u == nil {
+		return "", false, nil
+	}
+	policy, found := u.GetAnnotations()[Annotation]
+	if !found {
+		return "", false, nil
+	}
+	switch metav1.DeletionPropagation(policy) {
+	case metav1.DeletePropagationBackground,
+		metav1.DeletePropagationForeground,
+		metav1.DeletePropagationOrphan:
+		return metav1.DeletionPropagation(policy), true, nil
+	default:
+		return "", false, object.InvalidAnnotationError{
+			Annotation: Annotation,
+			Cause: fmt.Errorf("must be %q, %q or %q, got %q",
+				metav1.DeletePropagationBackground,
+				metav1.DeletePropagationForeground,
+				metav1.DeletePropagationOrphan,
+				policy),
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package propagationpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+var statefulSetYAML = `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: foo
+  namespace: default
+`
+
+func TestReadAnnotation(t *testing.T) {
+	testCases := map[string]struct {
+		obj      *unstructured.Unstructured
+		expected metav1.DeletionPropagation
+		found    bool
+		isError  bool
+	}{
+		"nil object": {
+			obj: nil,
+		},
+		"no annotation": {
+			obj: testutil.Unstructured(t, statefulSetYAML),
+		},
+		"orphan": {
+			obj:      testutil.Unstructured(t, statefulSetYAML, testutil.AddAnnotation(Annotation, "Orphan")),
+			expected: metav1.DeletePropagationOrphan,
+			found:    true,
+		},
+		"foreground": {
+			obj:      testutil.Unstructured(t, statefulSetYAML, testutil.AddAnnotation(Annotation, "Foreground")),
+			expected: metav1.DeletePropagationForeground,
+			found:    true,
+		},
+		"background": {
+			obj:      testutil.Unstructured(t, statefulSetYAML, testutil.AddAnnotation(Annotation, "Background")),
+			expected: metav1.DeletePropagationBackground,
+			found:    true,
+		},
+		"other value is error": {
+			obj:     testutil.Unstructured(t, statefulSetYAML, testutil.AddAnnotation(Annotation, "orphan")),
+			isError: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			policy, found, err := ReadAnnotation(tc.obj)
+			if tc.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, policy)
+			assert.Equal(t, tc.found, found)
+		})
+	}
+}
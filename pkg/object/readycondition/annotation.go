@@ -0,0 +1,73 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package readycondition provides functions to read and write the
+// ready-condition annotation, which replaces the computed status of a single
+// object with an expression on its fields when waiting for the object to
+// reconcile.
+package readycondition
+
+import (
+	"errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	Annotation = "config.kubernetes.io/ready-condition"
+)
+
+// HasAnnotation returns true if the config.kubernetes.io/ready-condition
+// annotation is present, false if not.
+func HasAnnotation(u *unstructured.Unstructured) bool {
+	if u == nil {
+		return false
+	}
+	_, found := u.GetAnnotations()[Annotation]
+	return found
+}
+
+// ReadAnnotation reads the ready-condition annotation and parses the
+// expression, e.g. "status.readyReplicas >= spec.replicas". Returns nil if
+// the annotation is not present.
+func ReadAnnotation(u *unstructured.Unstructured) (*Expression, error) {
+	if u == nil {
+		return nil, nil
+	}
+	exprStr, found := u.GetAnnotations()[Annotation]
+	if !found {
+		return nil, nil
+	}
+	klog.V(5).Infof("ready-condition annotation found for %s/%s: %q",
+		u.GetNamespace(), u.GetName(), exprStr)
+
+	expr, err := Parse(exprStr)
+	if err != nil {
+		return nil, object.InvalidAnnotationError{
+			Annotation: Annotation,
+			Cause:      err,
+		}
+	}
+	return expr, nil
+}
+
+// WriteAnnotation updates the supplied unstructured object to add the
+// ready-condition annotation.
+func WriteAnnotation(obj *unstructured.Unstructured, expr *Expression) error {
+	if obj == nil {
+		return errors.New("object is nil")
+	}
+	if expr == nil {
+		return errors.New("expression is nil")
+	}
+
+	a := obj.GetAnnotations()
+	if a == nil {
+		a = map[string]string{}
+	}
+	a[Annotation] = expr.String()
+	obj.SetAnnotations(a)
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package readycondition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+var databaseYAML = `
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+  namespace: default
+`
+
+func TestReadAnnotation(t *testing.T) {
+	testCases := map[string]struct {
+		obj      *unstructured.Unstructured
+		expected string
+		isError  bool
+	}{
+		"nil object": {
+			obj: nil,
+		},
+		"no annotation": {
+			obj: testutil.Unstructured(t, databaseYAML),
+		},
+		"expression": {
+			obj:      testutil.Unstructured(t, databaseYAML, testutil.AddAnnotation(Annotation, " status.readyReplicas >= spec.replicas ")),
+			expected: "status.readyReplicas >= spec.replicas",
+		},
+		"invalid expression is error": {
+			obj:     testutil.Unstructured(t, databaseYAML, testutil.AddAnnotation(Annotation, ">= 3")),
+			isError: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			expr, err := ReadAnnotation(tc.obj)
+			if tc.isError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tc.expected == "" {
+				assert.Nil(t, expr)
+				assert.False(t, HasAnnotation(tc.obj))
+				return
+			}
+			assert.Equal(t, tc.expected, expr.String())
+			assert.True(t, HasAnnotation(tc.obj))
+		})
+	}
+}
+
+func TestWriteAnnotation(t *testing.T) {
+	obj := testutil.Unstructured(t, databaseYAML)
+	expr, err := Parse("status.ready")
+	require.NoError(t, err)
+	require.NoError(t, WriteAnnotation(obj, expr))
+	assert.Equal(t, "status.ready", obj.GetAnnotations()[Annotation])
+
+	read, err := ReadAnnotation(obj)
+	require.NoError(t, err)
+	assert.Equal(t, expr, read)
+
+	assert.Error(t, WriteAnnotation(nil, expr))
+	assert.Error(t, WriteAnnotation(obj, nil))
+}
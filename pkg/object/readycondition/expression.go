@@ -0,0 +1,232 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package readycondition
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/jsonpath"
+)
+
+// operators are the supported comparison operators. Two character operators
+// are listed first, so that they are matched before their prefixes.
+var operators = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// Expression is a readiness condition: one or more comparisons joined with
+// "&&", all of which must hold for the object to be ready.
+//
+// Each comparison has the form "<operand> <operator> <operand>", where the
+// operator is one of ==, !=, >, >=, < or <=, or is a single operand that
+// must be true. Operands are either literals (numbers, quoted strings, true,
+// false or null) or field paths, e.g. "status.readyReplicas" or the
+// equivalent JSONPath "$.status.readyReplicas".
+type Expression struct {
+	raw         string
+	comparisons []comparison
+}
+
+type comparison struct {
+	left     operand
+	operator string
+	right    operand
+}
+
+type operand struct {
+	// path is the JSONPath of a field. Empty for literals.
+	path    string
+	literal interface{}
+}
+
+// Parse parses a readiness condition expression.
+func Parse(expr string) (*Expression, error) {
+	e := &Expression{raw: strings.TrimSpace(expr)}
+	if e.raw == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+	for _, part := range splitConjunction(e.raw) {
+		c, err := parseComparison(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		e.comparisons = append(e.comparisons, c)
+	}
+	return e, nil
+}
+
+// String returns the expression as it was parsed.
+func (e *Expression) String() string {
+	return e.raw
+}
+
+// Evaluate returns true if all comparisons of the expression hold for the
+// object. Comparisons with missing fields do not hold.
+func (e *Expression) Evaluate(obj *unstructured.Unstructured) (bool, error) {
+	for _, c := range e.comparisons {
+		ok, err := c.evaluate(obj.Object)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// splitConjunction splits the expression into its comparisons at each "&&"
+// that is not part of a quoted string. An unterminated string is left in
+// the last comparison, where parseComparison reports it.
+func splitConjunction(s string) []string {
+	var parts []string
+	start := 0
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(s[i:], "&&"):
+			parts = append(parts, s[start:i])
+			start = i + len("&&")
+			i++
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func parseComparison(s string) (comparison, error) {
+	if s == "" {
+		return comparison{}, fmt.Errorf("comparison is empty")
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' {
+			// skip quoted strings
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				return comparison{}, fmt.Errorf("unterminated string in %q", s)
+			}
+			i += end + 1
+			continue
+		}
+		for _, op := range operators {
+			if !strings.HasPrefix(s[i:], op) {
+				continue
+			}
+			left, err := parseOperand(s[:i])
+			if err != nil {
+				return comparison{}, err
+			}
+			right, err := parseOperand(s[i+len(op):])
+			if err != nil {
+				return comparison{}, err
+			}
+			return comparison{left: left, operator: op, right: right}, nil
+		}
+	}
+	left, err := parseOperand(s)
+	if err != nil {
+		return comparison{}, err
+	}
+	return comparison{left: left, operator: "==", right: operand{literal: true}}, nil
+}
+
+func parseOperand(s string) (operand, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return operand{}, fmt.Errorf("operand is empty")
+	case s == "true":
+		return operand{literal: true}, nil
+	case s == "false":
+		return operand{literal: false}, nil
+	case s == "null":
+		return operand{}, nil
+	case strings.HasPrefix(s, `"`):
+		if len(s) < 2 || !strings.HasSuffix(s, `"`) {
+			return operand{}, fmt.Errorf("invalid string %s", s)
+		}
+		return operand{literal: s[1 : len(s)-1]}, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return operand{literal: f}, nil
+	}
+	if strings.ContainsAny(s, " \t\"") {
+		return operand{}, fmt.Errorf("invalid field path %q", s)
+	}
+	if !strings.HasPrefix(s, "$") {
+		s = "$." + s
+	}
+	return operand{path: s}, nil
+}
+
+// value returns the value of the operand for the object, and false if the
+// field does not exist.
+func (o operand) value(obj map[string]interface{}) (interface{}, bool, error) {
+	if o.path == "" {
+		return o.literal, true, nil
+	}
+	values, err := jsonpath.Get(obj, o.path)
+	if err != nil {
+		return nil, false, err
+	}
+	switch len(values) {
+	case 0:
+		return nil, false, nil
+	case 1:
+		return values[0], true, nil
+	default:
+		return nil, false, fmt.Errorf("field path %q matches %d fields", o.path, len(values))
+	}
+}
+
+func (c comparison) evaluate(obj map[string]interface{}) (bool, error) {
+	left, found, err := c.left.value(obj)
+	if err != nil || !found {
+		return false, err
+	}
+	right, found, err := c.right.value(obj)
+	if err != nil || !found {
+		return false, err
+	}
+
+	lf, lIsNum := toFloat(left)
+	rf, rIsNum := toFloat(right)
+	if lIsNum && rIsNum {
+		switch c.operator {
+		case "==":
+			return lf == rf, nil
+		case "!=":
+			return lf != rf, nil
+		case ">":
+			return lf > rf, nil
+		case ">=":
+			return lf >= rf, nil
+		case "<":
+			return lf < rf, nil
+		default:
+			return lf <= rf, nil
+		}
+	}
+	switch c.operator {
+	case "==":
+		return reflect.DeepEqual(left, right), nil
+	case "!=":
+		return !reflect.DeepEqual(left, right), nil
+	default:
+		return false, fmt.Errorf("operator %s requires numbers, got %v and %v", c.operator, left, right)
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
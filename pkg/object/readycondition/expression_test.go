@@ -0,0 +1,117 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package readycondition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var testObj = &unstructured.Unstructured{
+	Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Database",
+		"metadata": map[string]interface{}{
+			"name":      "db",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+		},
+		"status": map[string]interface{}{
+			"readyReplicas": int64(3),
+			"phase":         "Running",
+			"ready":         true,
+			"endpoints":     []interface{}{"a", "b"},
+		},
+	},
+}
+
+func TestExpression_Evaluate(t *testing.T) {
+	testCases := map[string]struct {
+		expr      string
+		expected  bool
+		evalError bool
+	}{
+		"fields compared": {
+			expr:     "status.readyReplicas >= spec.replicas",
+			expected: true,
+		},
+		"jsonpath": {
+			expr:     "$.status.readyReplicas == $.spec.replicas",
+			expected: true,
+		},
+		"number literal": {
+			expr:     "status.readyReplicas < 3",
+			expected: false,
+		},
+		"string literal": {
+			expr:     `status.phase == "Running"`,
+			expected: true,
+		},
+		"string literal with operator": {
+			expr:     `status.phase != "a >= b"`,
+			expected: true,
+		},
+		"boolean field": {
+			expr:     "status.ready",
+			expected: true,
+		},
+		"conjunction": {
+			expr:     `status.ready && status.phase == "Pending"`,
+			expected: false,
+		},
+		"string literal with conjunction": {
+			expr:     `status.phase != "a && b" && status.ready`,
+			expected: true,
+		},
+		"missing field": {
+			expr:     "status.observedGeneration >= 1",
+			expected: false,
+		},
+		"array element": {
+			expr:     `status.endpoints[1] == "b"`,
+			expected: true,
+		},
+		"ordering of strings": {
+			expr:      `status.phase > "A"`,
+			evalError: true,
+		},
+		"multiple fields": {
+			expr:      `status.endpoints[*] == "a"`,
+			evalError: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			expr, err := Parse(tc.expr)
+			require.NoError(t, err)
+			ready, err := expr.Evaluate(testObj)
+			if tc.evalError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ready)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"status.ready &&",
+		">= 3",
+		`status.phase == "Running`,
+		"status ready",
+		`status.phase == "a && b`,
+	} {
+		_, err := Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
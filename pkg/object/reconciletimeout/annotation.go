@@ -0,0 +1,79 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package reconciletimeout provides functions to read and write the
+// reconcile-timeout annotation, which overrides how long to wait for a
+// single object to reconcile.
+package reconciletimeout
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	Annotation = "config.kubernetes.io/reconcile-timeout"
+)
+
+// HasAnnotation returns true if the config.kubernetes.io/reconcile-timeout
+// annotation is present, false if not.
+func HasAnnotation(u *unstructured.Unstructured) bool {
+	if u == nil {
+		return false
+	}
+	_, found := u.GetAnnotations()[Annotation]
+	return found
+}
+
+// ReadAnnotation reads the reconcile-timeout annotation and parses the
+// duration, e.g. "5m". Returns zero if the annotation is not present.
+func ReadAnnotation(u *unstructured.Unstructured) (time.Duration, error) {
+	if u == nil {
+		return 0, nil
+	}
+	timeoutStr, found := u.GetAnnotations()[Annotation]
+	if !found {
+		return 0, nil
+	}
+	klog.V(5).Infof("reconcile-timeout annotation found for %s/%s: %q",
+		u.GetNamespace(), u.GetName(), timeoutStr)
+
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		return 0, object.InvalidAnnotationError{
+			Annotation: Annotation,
+			Cause:      err,
+		}
+	}
+	if timeout <= 0 {
+		return 0, object.InvalidAnnotationError{
+			Annotation: Annotation,
+			Cause:      fmt.Errorf("must be positive, got %q", timeoutStr),
+		}
+	}
+	return timeout, nil
+}
+
+// WriteAnnotation updates the supplied unstructured object to add the
+// reconcile-timeout annotation.
+func WriteAnnotation(obj *unstructured.Unstructured, timeout time.Duration) error {
+	if obj == nil {
+		return errors.New("object is nil")
+	}
+	if timeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got %s", timeout)
+	}
+
+	a := obj.GetAnnotations()
+	if a == nil {
+		a = map[string]string{}
+	}
+	a[Annotation] = timeout.String()
+	obj.SetAnnotations(a)
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package reconciletimeout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+var deploymentYAML = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  namespace: default
+`
+
+func TestReadAnnotation(t *testing.T) {
+	testCases := map[string]struct {
+		obj      *unstructured.Unstructured
+		expected time.Duration
+		isError  bool
+	}{
+		"nil object": {
+			obj:      nil,
+			expected: 0,
+		},
+		"no annotation": {
+			obj:      testutil.Unstructured(t, deploymentYAML),
+			expected: 0,
+		},
+		"minutes": {
+			obj:      testutil.Unstructured(t, deploymentYAML, testutil.AddAnnotation(Annotation, "5m")),
+			expected: 5 * time.Minute,
+		},
+		"combined units": {
+			obj:      testutil.Unstructured(t, deploymentYAML, testutil.AddAnnotation(Annotation, "1h30m")),
+			expected: 90 * time.Minute,
+		},
+		"missing unit is error": {
+			obj:     testutil.Unstructured(t, deploymentYAML, testutil.AddAnnotation(Annotation, "300")),
+			isError: true,
+		},
+		"zero is error": {
+			obj:     testutil.Unstructured(t, deploymentYAML, testutil.AddAnnotation(Annotation, "0s")),
+			isError: true,
+		},
+		"negative is error": {
+			obj:     testutil.Unstructured(t, deploymentYAML, testutil.AddAnnotation(Annotation, "-5m")),
+			isError: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			timeout, err := ReadAnnotation(tc.obj)
+			if tc.isError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, timeout)
+			assert.Equal(t, tc.expected != 0, HasAnnotation(tc.obj))
+		})
+	}
+}
+
+func TestWriteAnnotation(t *testing.T) {
+	obj := testutil.Unstructured(t, deploymentYAML)
+	require.NoError(t, WriteAnnotation(obj, 90*time.Second))
+	assert.Equal(t, "1m30s", obj.GetAnnotations()[Annotation])
+
+	timeout, err := ReadAnnotation(obj)
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, timeout)
+
+	assert.Error(t, WriteAnnotation(nil, time.Second))
+	assert.Error(t, WriteAnnotation(obj, 0))
+}
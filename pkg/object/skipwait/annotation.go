@@ -0,0 +1,75 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package skipwait provides functions to read and write the reconcile
+// annotation, which excludes an object from waiting for reconciliation,
+// e.g. long running Jobs or custom resources with a broken status. The
+// object is applied, but not waited for.
+package skipwait
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	Annotation = "config.kubernetes.io/reconcile"
+
+	// Skip is the annotation value that skips waiting for the object.
+	Skip = "skip"
+	// Disabled is an alias of Skip.
+	Disabled = "disabled"
+)
+
+// HasAnnotation returns true if the config.kubernetes.io/reconcile
+// annotation is present, false if not.
+func HasAnnotation(u *unstructured.Unstructured) bool {
+	if u == nil {
+		return false
+	}
+	_, found := u.GetAnnotations()[Annotation]
+	return found
+}
+
+// ReadAnnotation returns true if the reconcile annotation skips waiting for
+// the object. Returns false if the annotation is not present.
+func ReadAnnotation(u *unstructured.Unstructured) (bool, error) {
+	if u == nil {
+		return false, nil
+	}
+	value, found := u.GetAnnotations()[Annotation]
+	if !found {
+		return false, nil
+	}
+	klog.V(5).Infof("reconcile annotation found for %s/%s: %q",
+		u.GetNamespace(), u.GetName(), value)
+
+	switch value {
+	case Skip, Disabled:
+		return true, nil
+	default:
+		return false, object.InvalidAnnotationError{
+			Annotation: Annotation,
+			Cause:      fmt.Errorf("must be %q or %q, got %q", Skip, Disabled, value),
+		}
+	}
+}
+
+// WriteAnnotation updates the supplied unstructured object to add the
+// reconcile annotation, which skips waiting for the object.
+func WriteAnnotation(obj *unstructured.Unstructured) error {
+	if obj == nil {
+		return errors.New("object is nil")
+	}
+	a := obj.GetAnnotations()
+	if a == nil {
+		a = map[string]string{}
+	}
+	a[Annotation] = Skip
+	obj.SetAnnotations(a)
+	return nil
+}
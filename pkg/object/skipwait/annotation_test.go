@@ -0,0 +1,75 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package skipwait
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+var jobYAML = `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: foo
+  namespace: default
+`
+
+func TestReadAnnotation(t *testing.T) {
+	testCases := map[string]struct {
+		obj      *unstructured.Unstructured
+		expected bool
+		isError  bool
+	}{
+		"nil object": {
+			obj:      nil,
+			expected: false,
+		},
+		"no annotation": {
+			obj:      testutil.Unstructured(t, jobYAML),
+			expected: false,
+		},
+		"skip": {
+			obj:      testutil.Unstructured(t, jobYAML, testutil.AddAnnotation(Annotation, Skip)),
+			expected: true,
+		},
+		"disabled": {
+			obj:      testutil.Unstructured(t, jobYAML, testutil.AddAnnotation(Annotation, Disabled)),
+			expected: true,
+		},
+		"other value is error": {
+			obj:     testutil.Unstructured(t, jobYAML, testutil.AddAnnotation(Annotation, "true")),
+			isError: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			skip, err := ReadAnnotation(tc.obj)
+			if tc.isError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, skip)
+			assert.Equal(t, tc.expected, HasAnnotation(tc.obj))
+		})
+	}
+}
+
+func TestWriteAnnotation(t *testing.T) {
+	obj := testutil.Unstructured(t, jobYAML)
+	require.NoError(t, WriteAnnotation(obj))
+	assert.Equal(t, Skip, obj.GetAnnotations()[Annotation])
+
+	skip, err := ReadAnnotation(obj)
+	require.NoError(t, err)
+	assert.True(t, skip)
+
+	assert.Error(t, WriteAnnotation(nil))
+}
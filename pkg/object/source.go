@@ -0,0 +1,73 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package object
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+)
+
+// SourceLineAnnotation is the internal annotation used to record the line at
+// which an object starts in its source file. Like the kyaml path annotation,
+// it is removed before the object is sent to the cluster.
+const SourceLineAnnotation = "internal.config.kubernetes.io/line"
+
+// SourceNameAnnotation is the internal annotation used to record the name
+// of the source an object was read from if it is not a file, e.g. "stdin"
+// or a URL. It is removed before the object is sent to the cluster.
+const SourceNameAnnotation = "internal.config.kubernetes.io/source"
+
+// SetSourceLine records the line at which the object starts in its source
+// file.
+func SetSourceLine(u *unstructured.Unstructured, line int) {
+	annos := u.GetAnnotations()
+	if annos == nil {
+		annos = make(map[string]string)
+	}
+	annos[SourceLineAnnotation] = strconv.Itoa(line)
+	u.SetAnnotations(annos)
+}
+
+// Source returns the location the object was read from, e.g.
+// "deployment.yaml:42", or an empty string if it is unknown.
+func Source(u *unstructured.Unstructured) string {
+	annos := u.GetAnnotations()
+	path := annos[kioutil.PathAnnotation]
+	if path == "" {
+		path = annos[SourceNameAnnotation]
+	}
+	if path == "" {
+		return ""
+	}
+	if line, found := annos[SourceLineAnnotation]; found {
+		return fmt.Sprintf("%s:%s", path, line)
+	}
+	return path
+}
+
+// SourceError wraps an error with the location of the object that caused it.
+type SourceError struct {
+	Source string
+	Err    error
+}
+
+// WrapSourceError returns the error wrapped with the source, or the error
+// itself if the source is unknown.
+func WrapSourceError(err error, source string) error {
+	if err == nil || source == "" {
+		return err
+	}
+	return &SourceError{Source: source, Err: err}
+}
+
+func (se *SourceError) Error() string {
+	return fmt.Sprintf("%s: %v", se.Source, se.Err)
+}
+
+func (se *SourceError) Unwrap() error {
+	return se.Err
+}
@@ -0,0 +1,67 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package object
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+)
+
+func TestSource(t *testing.T) {
+	testCases := map[string]struct {
+		annotations map[string]string
+		expected    string
+	}{
+		"no source": {
+			expected: "",
+		},
+		"path": {
+			annotations: map[string]string{kioutil.PathAnnotation: "deployment.yaml"},
+			expected:    "deployment.yaml",
+		},
+		"path and line": {
+			annotations: map[string]string{
+				kioutil.PathAnnotation: "deployment.yaml",
+				SourceLineAnnotation:   "42",
+			},
+			expected: "deployment.yaml:42",
+		},
+		"line without path": {
+			annotations: map[string]string{SourceLineAnnotation: "42"},
+			expected:    "",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			u := &unstructured.Unstructured{}
+			u.SetAnnotations(tc.annotations)
+			assert.Equal(t, tc.expected, Source(u))
+		})
+	}
+}
+
+func TestSetSourceLine(t *testing.T) {
+	u := &unstructured.Unstructured{}
+	u.SetAnnotations(map[string]string{kioutil.PathAnnotation: "deployment.yaml"})
+	SetSourceLine(u, 7)
+	assert.Equal(t, "deployment.yaml:7", Source(u))
+
+	StripKyamlAnnotations(u)
+	assert.Empty(t, u.GetAnnotations())
+}
+
+func TestWrapSourceError(t *testing.T) {
+	cause := errors.New("failed")
+	assert.Nil(t, WrapSourceError(nil, "deployment.yaml:42"))
+	assert.Equal(t, cause, WrapSourceError(cause, ""))
+
+	err := WrapSourceError(cause, "deployment.yaml:42")
+	assert.EqualError(t, err, "deployment.yaml:42: failed")
+	assert.True(t, errors.Is(err, cause))
+}
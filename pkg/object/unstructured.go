@@ -202,7 +202,7 @@ func crdDefinesVersion(crd *unstructured.Unstructured, version string) (bool, er
 	return false, nil
 }
 
-// StripKyamlAnnotations removes any path and index annotations from the
+// StripKyamlAnnotations removes any path, source, line and index annotations from the
 // unstructured resource.
 func StripKyamlAnnotations(u *unstructured.Unstructured) {
 	annos := u.GetAnnotations()
@@ -210,5 +210,7 @@ func StripKyamlAnnotations(u *unstructured.Unstructured) {
 	delete(annos, kioutil.LegacyPathAnnotation) //nolint:staticcheck
 	delete(annos, kioutil.IndexAnnotation)
 	delete(annos, kioutil.LegacyIndexAnnotation) //nolint:staticcheck
+	delete(annos, SourceLineAnnotation)
+	delete(annos, SourceNameAnnotation)
 	u.SetAnnotations(annos)
 }
@@ -15,12 +15,38 @@ import (
 type Collector struct {
 	Errors     []error
 	InvalidIds object.ObjMetadataSet
+	// Sources are the locations the objects were read from, used to report
+	// where invalid objects are defined.
+	Sources map[object.ObjMetadata]string
+}
+
+// RecordSources records the locations the passed objects were read from.
+func (c *Collector) RecordSources(objs object.UnstructuredSet) {
+	for _, obj := range objs {
+		source := object.Source(obj)
+		if source == "" {
+			continue
+		}
+		if c.Sources == nil {
+			c.Sources = make(map[object.ObjMetadata]string)
+		}
+		c.Sources[object.UnstructuredToObjMetadata(obj)] = source
+	}
 }
 
 // Collect unwraps MultiErrors, adds them to Errors, extracts invalid object
-// IDs from validation.Error, and adds them to InvalidIds.
+// IDs from validation.Error, and adds them to InvalidIds. The recorded
+// Sources are added to the validation.Error.
 func (c *Collector) Collect(err error) {
 	errs := multierror.Unwrap(err)
+	if len(c.Sources) > 0 {
+		for _, err := range errs {
+			var vErr *Error
+			if errors.As(err, &vErr) {
+				vErr.setSources(c.Sources)
+			}
+		}
+	}
 	c.InvalidIds = c.InvalidIds.Union(extractInvalidIds(errs))
 	c.Errors = append(c.Errors, errs...)
 }
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/reason"
 )
 
 func NewError(cause error, ids ...object.ObjMetadata) *Error {
@@ -21,6 +22,8 @@ func NewError(cause error, ids ...object.ObjMetadata) *Error {
 type Error struct {
 	ids   object.ObjMetadataSet
 	cause error
+	// sources are the locations the objects were read from, if known.
+	sources map[object.ObjMetadata]string
 }
 
 // Identifiers returns zero or more object IDs which are invalid.
@@ -28,24 +31,66 @@ func (ve *Error) Identifiers() object.ObjMetadataSet {
 	return ve.ids
 }
 
+// Sources returns the locations the invalid objects were read from, e.g.
+// "deployment.yaml:42", keyed by object ID. Objects with an unknown source
+// are omitted.
+func (ve *Error) Sources() map[object.ObjMetadata]string {
+	return ve.sources
+}
+
+// setSources records the locations of the invalid objects found in the
+// passed sources.
+func (ve *Error) setSources(sources map[object.ObjMetadata]string) {
+	for _, id := range ve.ids {
+		if source, found := sources[id]; found {
+			if ve.sources == nil {
+				ve.sources = make(map[object.ObjMetadata]string)
+			}
+			ve.sources[id] = source
+		}
+	}
+}
+
+// quote returns the quoted object ID, followed by its source if known.
+func (ve *Error) quote(id object.ObjMetadata) string {
+	if source, found := ve.sources[id]; found {
+		return fmt.Sprintf("%q (%s)", id, source)
+	}
+	return fmt.Sprintf("%q", id)
+}
+
 // Unwrap returns the cause of the error.
 // This may be useful when printing the cause without printing the identifiers.
 func (ve *Error) Unwrap() error {
 	return ve.cause
 }
 
+// ErrorReason returns the reason of the cause, if classified, or
+// reason.Invalid.
+func (ve *Error) ErrorReason() reason.Reason {
+	if r := reason.Of(ve.cause); r != reason.Unknown {
+		return r
+	}
+	return reason.Invalid
+}
+
+// ErrorRetryable returns false, because invalid objects stay invalid.
+func (ve *Error) ErrorRetryable() bool {
+	return false
+}
+
 // Error stringifies the the error.
 func (ve *Error) Error() string {
 	switch {
 	case len(ve.ids) == 0:
 		return fmt.Sprintf("validation error: %v", ve.cause.Error())
 	case len(ve.ids) == 1:
-		return fmt.Sprintf("invalid object: %q: %v", ve.ids[0], ve.cause.Error())
+		return fmt.Sprintf("invalid object: %s: %v", ve.quote(ve.ids[0]), ve.cause.Error())
 	default:
 		var b strings.Builder
-		_, _ = fmt.Fprintf(&b, "invalid objects: [%q", ve.ids[0])
+		_, _ = fmt.Fprintf(&b, "invalid objects: [%s", ve.quote(ve.ids[0]))
 		for _, id := range ve.ids[1:] {
-			_, _ = fmt.Fprintf(&b, ", %q", id)
+			_, _ = fmt.Fprintf(&b, ", %s", ve.quote(id))
 		}
 		_, _ = fmt.Fprintf(&b, "] %v", ve.cause)
 		return b.String()
@@ -0,0 +1,55 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/kubectl/pkg/util/openapi"
+	openapivalidation "k8s.io/kubectl/pkg/util/openapi/validation"
+	"sigs.k8s.io/cli-utils/pkg/multierror"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// SchemaValidator validates objects against the OpenAPI schema of the
+// cluster, e.g. to find unknown fields and fields with the wrong type before
+// the objects are applied.
+type SchemaValidator struct {
+	// Resources are the OpenAPI schemas of the cluster resources.
+	Resources openapi.Resources
+	Collector *Collector
+}
+
+// Validate validates the provided resources against their OpenAPI schema.
+// All errors of an object are collected as a single validation error.
+// Objects without a schema, e.g. custom resources of CRDs that have not been
+// applied yet, are not validated.
+func (v *SchemaValidator) Validate(objs []*unstructured.Unstructured) {
+	v.Collector.RecordSources(objs)
+	schemaValidation := openapivalidation.NewSchemaValidation(v.Resources)
+	for _, obj := range objs {
+		if err := validateSchema(schemaValidation, obj); err != nil {
+			v.Collector.Collect(NewError(err, object.UnstructuredToObjMetadata(obj)))
+		}
+	}
+}
+
+func validateSchema(schemaValidation *openapivalidation.SchemaValidation, obj *unstructured.Unstructured) error {
+	// Don't validate the internal annotations added by the readers.
+	obj = obj.DeepCopy()
+	object.StripKyamlAnnotations(obj)
+	data, err := json.Marshal(obj.Object)
+	if err != nil {
+		return err
+	}
+	err = schemaValidation.ValidateBytes(data)
+	var agg utilerrors.Aggregate
+	if errors.As(err, &agg) {
+		return multierror.Wrap(agg.Errors()...)
+	}
+	return err
+}
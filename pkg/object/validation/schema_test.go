@@ -0,0 +1,125 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/util/proto"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/validation"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+)
+
+// fakeResources serves a minimal schema for apps/v1 Deployments.
+type fakeResources struct{}
+
+func (fakeResources) LookupResource(gvk schema.GroupVersionKind) proto.Schema {
+	if gvk != (schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}) {
+		return nil
+	}
+	str := &proto.Primitive{Type: "string"}
+	return &proto.Kind{
+		Fields: map[string]proto.Schema{
+			"apiVersion": str,
+			"kind":       str,
+			"metadata": &proto.Kind{
+				Fields: map[string]proto.Schema{
+					"name":        str,
+					"namespace":   str,
+					"annotations": &proto.Map{SubType: str},
+				},
+			},
+			"spec": &proto.Kind{
+				Fields: map[string]proto.Schema{
+					"replicas": &proto.Primitive{Type: "integer"},
+				},
+			},
+		},
+	}
+}
+
+func deployment(spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "foo",
+				"namespace": "default",
+				"annotations": map[string]interface{}{
+					kioutil.PathAnnotation:      "deployment.yaml",
+					object.SourceLineAnnotation: "3",
+				},
+			},
+			"spec": spec,
+		},
+	}
+}
+
+func TestSchemaValidator_Validate(t *testing.T) {
+	testCases := map[string]struct {
+		resources      []*unstructured.Unstructured
+		expectedErrors []string
+	}{
+		"valid object": {
+			resources: []*unstructured.Unstructured{
+				deployment(map[string]interface{}{"replicas": int64(1)}),
+			},
+		},
+		"unknown field and wrong type": {
+			resources: []*unstructured.Unstructured{
+				deployment(map[string]interface{}{
+					"replicas": "one",
+					"replica":  int64(1),
+				}),
+			},
+			expectedErrors: []string{
+				`invalid object: "default_foo_apps_Deployment" (deployment.yaml:3)`,
+				`ValidationError(Deployment.spec): unknown field "replica"`,
+				`ValidationError(Deployment.spec.replicas): invalid type`,
+			},
+		},
+		"object without schema": {
+			resources: []*unstructured.Unstructured{
+				{
+					Object: map[string]interface{}{
+						"apiVersion": "example.com/v1",
+						"kind":       "Custom",
+						"metadata": map[string]interface{}{
+							"name": "foo",
+						},
+						"spec": map[string]interface{}{
+							"anything": true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			vCollector := &validation.Collector{}
+			validator := &validation.SchemaValidator{
+				Resources: fakeResources{},
+				Collector: vCollector,
+			}
+			validator.Validate(tc.resources)
+			if len(tc.expectedErrors) == 0 {
+				assert.NoError(t, vCollector.ToError())
+				return
+			}
+			require.Len(t, vCollector.Errors, 1)
+			assert.Equal(t, object.UnstructuredSetToObjMetadataSet(tc.resources), vCollector.InvalidIds)
+			for _, expected := range tc.expectedErrors {
+				assert.Contains(t, vCollector.Errors[0].Error(), expected)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/cli-utils/pkg/multierror"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/propagationpolicy"
 )
 
 // Validator contains functionality for validating a set of resources prior
@@ -22,6 +23,7 @@ type Validator struct {
 // Validate validates the provided resources. A RESTMapper will be used
 // to fetch type information from the live cluster.
 func (v *Validator) Validate(objs []*unstructured.Unstructured) {
+	v.Collector.RecordSources(objs)
 	crds := findCRDs(objs)
 	for _, obj := range objs {
 		var objErrors []error
@@ -34,6 +36,9 @@ func (v *Validator) Validate(objs []*unstructured.Unstructured) {
 		if err := v.validateNamespace(obj, crds); err != nil {
 			objErrors = append(objErrors, err)
 		}
+		if err := v.validatePropagationPolicy(obj); err != nil {
+			objErrors = append(objErrors, err)
+		}
 		if len(objErrors) > 0 {
 			// one error per object
 			v.Collector.Collect(NewError(
@@ -92,3 +97,11 @@ func (v *Validator) validateNamespace(u *unstructured.Unstructured, crds []*unst
 	}
 	return nil
 }
+
+// validatePropagationPolicy validates the value of the
+// delete-propagation-policy annotation of the resource, if present, so an
+// invalid value is reported before actuation instead of at delete time.
+func (v *Validator) validatePropagationPolicy(u *unstructured.Unstructured) error {
+	_, _, err := propagationpolicy.ReadAnnotation(u)
+	return err
+}
@@ -4,6 +4,7 @@
 package validation_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,8 +16,10 @@ import (
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 	"sigs.k8s.io/cli-utils/pkg/multierror"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/propagationpolicy"
 	"sigs.k8s.io/cli-utils/pkg/object/validation"
 	"sigs.k8s.io/cli-utils/pkg/testutil"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
 )
 
 func TestValidate(t *testing.T) {
@@ -202,6 +205,34 @@ func TestValidate(t *testing.T) {
 				},
 			),
 		},
+		"invalid propagation policy": {
+			resources: []*unstructured.Unstructured{
+				testutil.Unstructured(t, `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: foo
+  namespace: default
+  annotations:
+    config.kubernetes.io/delete-propagation-policy: orphan
+`,
+				),
+			},
+			expectedError: validation.NewError(
+				object.InvalidAnnotationError{
+					Annotation: propagationpolicy.Annotation,
+					Cause:      errors.New(`must be "Background", "Foreground" or "Orphan", got "orphan"`),
+				},
+				object.ObjMetadata{
+					GroupKind: schema.GroupKind{
+						Group: "apps",
+						Kind:  "StatefulSet",
+					},
+					Name:      "foo",
+					Namespace: "default",
+				},
+			),
+		},
 		"scope for CRs are found in CRDs if available": {
 			resources: []*unstructured.Unstructured{
 				testutil.Unstructured(t, `
@@ -275,3 +306,45 @@ metadata:
 		})
 	}
 }
+
+func TestValidate_Sources(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test-ns")
+	defer tf.Cleanup()
+
+	mapper, err := tf.ToRESTMapper()
+	require.NoError(t, err)
+
+	resources := []*unstructured.Unstructured{
+		{
+			Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]interface{}{
+					"name": "foo",
+					"annotations": map[string]interface{}{
+						kioutil.PathAnnotation:      "deployment.yaml",
+						object.SourceLineAnnotation: "42",
+					},
+				},
+			},
+		},
+	}
+	id := object.UnstructuredToObjMetadata(resources[0])
+
+	vCollector := &validation.Collector{}
+	validator := &validation.Validator{
+		Mapper:    mapper,
+		Collector: vCollector,
+	}
+	validator.Validate(resources)
+	require.Len(t, vCollector.Errors, 1)
+	assert.EqualError(t, vCollector.Errors[0],
+		`invalid object: "_foo_apps_Deployment" (deployment.yaml:42): metadata.namespace: Required value: namespace is required`)
+
+	// Errors collected later, e.g. by the solver, also report the sources.
+	vCollector.Collect(validation.NewError(errors.New("dependency cycle"), id))
+	require.Len(t, vCollector.Errors, 2)
+	var vErr *validation.Error
+	require.True(t, errors.As(vCollector.Errors[1], &vErr))
+	assert.Equal(t, map[object.ObjMetadata]string{id: "deployment.yaml:42"}, vErr.Sources())
+}
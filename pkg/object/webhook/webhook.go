@@ -0,0 +1,232 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package webhook reads the admission webhooks of webhook configurations,
+// to apply the objects admitted by a webhook after the workload serving the
+// webhook, when both are applied together.
+//
+// Ordering is opt-in with the webhook-gating annotation on the
+// ValidatingWebhookConfiguration or MutatingWebhookConfiguration.
+package webhook
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	Annotation = "config.kubernetes.io/webhook-gating"
+)
+
+const admissionGroup = "admissionregistration.k8s.io"
+
+// IsWebhookConfiguration returns true if the object is a
+// ValidatingWebhookConfiguration or MutatingWebhookConfiguration.
+func IsWebhookConfiguration(u *unstructured.Unstructured) bool {
+	if u == nil {
+		return false
+	}
+	gvk := u.GroupVersionKind()
+	return gvk.Group == admissionGroup &&
+		(gvk.Kind == "ValidatingWebhookConfiguration" || gvk.Kind == "MutatingWebhookConfiguration")
+}
+
+// ReadAnnotation reads the webhook-gating annotation, which is either "true"
+// or "false". Returns false if the annotation is not present.
+func ReadAnnotation(u *unstructured.Unstructured) (bool, error) {
+	if u == nil {
+		return false, nil
+	}
+	value, found := u.GetAnnotations()[Annotation]
+	if !found {
+		return false, nil
+	}
+	klog.V(5).Infof("webhook-gating annotation found for %s: %q", u.GetName(), value)
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, object.InvalidAnnotationError{
+			Annotation: Annotation,
+			Cause:      fmt.Errorf("must be true or false, got %q", value),
+		}
+	}
+	return enabled, nil
+}
+
+// ServiceReference is the Service a webhook is served by.
+type ServiceReference struct {
+	Namespace string
+	Name      string
+}
+
+// Rule selects the requests a webhook is called for.
+type Rule struct {
+	Operations  []string
+	APIGroups   []string
+	APIVersions []string
+	Resources   []string
+	Scope       string
+}
+
+// Webhook is an admission webhook of a webhook configuration.
+type Webhook struct {
+	Name string
+	// Service is the Service the webhook is served by, or nil if the
+	// webhook is served by a URL.
+	Service *ServiceReference
+	Rules   []Rule
+}
+
+// ReadWebhooks returns the webhooks of a webhook configuration.
+func ReadWebhooks(u *unstructured.Unstructured) ([]Webhook, error) {
+	items, _, err := unstructured.NestedSlice(u.Object, "webhooks")
+	if err != nil {
+		return nil, err
+	}
+	var webhooks []Webhook
+	for i, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid webhook %d: expected object, got %T", i, item)
+		}
+		var hook Webhook
+		hook.Name, _, _ = unstructured.NestedString(m, "name")
+		if svc, found, _ := unstructured.NestedMap(m, "clientConfig", "service"); found {
+			hook.Service = &ServiceReference{}
+			hook.Service.Namespace, _, _ = unstructured.NestedString(svc, "namespace")
+			hook.Service.Name, _, _ = unstructured.NestedString(svc, "name")
+		}
+		rules, _, err := unstructured.NestedSlice(m, "rules")
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook %q: %w", hook.Name, err)
+		}
+		for _, r := range rules {
+			rm, ok := r.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid webhook %q: expected rule object, got %T", hook.Name, r)
+			}
+			var rule Rule
+			rule.Operations, _, _ = unstructured.NestedStringSlice(rm, "operations")
+			rule.APIGroups, _, _ = unstructured.NestedStringSlice(rm, "apiGroups")
+			rule.APIVersions, _, _ = unstructured.NestedStringSlice(rm, "apiVersions")
+			rule.Resources, _, _ = unstructured.NestedStringSlice(rm, "resources")
+			rule.Scope, _, _ = unstructured.NestedString(rm, "scope")
+			hook.Rules = append(hook.Rules, rule)
+		}
+		webhooks = append(webhooks, hook)
+	}
+	return webhooks, nil
+}
+
+// Admits returns true if any rule of the webhook matches applying the
+// object.
+func (w Webhook) Admits(u *unstructured.Unstructured) bool {
+	for _, rule := range w.Rules {
+		if rule.Matches(u.GroupVersionKind(), object.IsNamespaced(u)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns true if the rule matches creating or updating an object
+// of the kind. The resource of the kind is guessed from the kind, because
+// the kind may not be known to the cluster yet. Rules that only match
+// subresources never match.
+func (r Rule) Matches(gvk schema.GroupVersionKind, namespaced bool) bool {
+	if !containsAny(r.Operations, "*", "CREATE", "UPDATE") ||
+		!containsAny(r.APIGroups, "*", gvk.Group) ||
+		!containsAny(r.APIVersions, "*", gvk.Version) {
+		return false
+	}
+	switch r.Scope {
+	case "Namespaced":
+		if !namespaced {
+			return false
+		}
+	case "Cluster":
+		if namespaced {
+			return false
+		}
+	}
+	plural, _ := meta.UnsafeGuessKindToResource(gvk)
+	return containsAny(r.Resources, "*", "*/*", plural.Resource, plural.Resource+"/*")
+}
+
+func containsAny(values []string, candidates ...string) bool {
+	for _, v := range values {
+		for _, c := range candidates {
+			if v == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var workloadGKs = map[schema.GroupKind]bool{
+	{Group: "apps", Kind: "Deployment"}:  true,
+	{Group: "apps", Kind: "StatefulSet"}: true,
+	{Group: "apps", Kind: "DaemonSet"}:   true,
+	{Group: "apps", Kind: "ReplicaSet"}:  true,
+	{Group: "", Kind: "Pod"}:             true,
+}
+
+// Backends returns the Service of the webhook and the workloads selected by
+// the Service, like Deployments and Pods, among the objects. Returns nil if
+// the webhook is not served by any of the objects.
+func (w Webhook) Backends(objs object.UnstructuredSet) object.ObjMetadataSet {
+	if w.Service == nil {
+		return nil
+	}
+	var service *unstructured.Unstructured
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		if gvk.Group == "" && gvk.Kind == "Service" &&
+			obj.GetNamespace() == w.Service.Namespace && obj.GetName() == w.Service.Name {
+			service = obj
+			break
+		}
+	}
+	if service == nil {
+		return nil
+	}
+	backends := object.ObjMetadataSet{object.UnstructuredToObjMetadata(service)}
+	selector, _, _ := unstructured.NestedStringMap(service.Object, "spec", "selector")
+	if len(selector) == 0 {
+		return backends
+	}
+	for _, obj := range objs {
+		if !workloadGKs[obj.GroupVersionKind().GroupKind()] || obj.GetNamespace() != service.GetNamespace() {
+			continue
+		}
+		if matchesLabels(podLabels(obj), selector) {
+			backends = append(backends, object.UnstructuredToObjMetadata(obj))
+		}
+	}
+	return backends
+}
+
+// podLabels returns the labels of the pods of the workload.
+func podLabels(u *unstructured.Unstructured) map[string]string {
+	if u.GetKind() == "Pod" {
+		return u.GetLabels()
+	}
+	labels, _, _ := unstructured.NestedStringMap(u.Object, "spec", "template", "metadata", "labels")
+	return labels
+}
+
+func matchesLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
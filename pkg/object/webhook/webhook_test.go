@@ -0,0 +1,217 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+var webhookConfig = `
+apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: test-webhook
+  annotations:
+    config.kubernetes.io/webhook-gating: "true"
+webhooks:
+- name: mutate.example.com
+  clientConfig:
+    service:
+      namespace: webhook-system
+      name: webhook
+  rules:
+  - operations: ["CREATE"]
+    apiGroups: ["apps"]
+    apiVersions: ["*"]
+    resources: ["deployments"]
+    scope: Namespaced
+- name: url.example.com
+  clientConfig:
+    url: https://webhook.example.com
+`
+
+func TestReadAnnotation(t *testing.T) {
+	obj := testutil.Unstructured(t, webhookConfig)
+	enabled, err := ReadAnnotation(obj)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	obj.SetAnnotations(map[string]string{Annotation: "false"})
+	enabled, err = ReadAnnotation(obj)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	obj.SetAnnotations(map[string]string{Annotation: "maybe"})
+	_, err = ReadAnnotation(obj)
+	assert.EqualError(t, err, `invalid "config.kubernetes.io/webhook-gating" annotation: must be true or false, got "maybe"`)
+
+	obj.SetAnnotations(nil)
+	enabled, err = ReadAnnotation(obj)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+}
+
+func TestReadWebhooks(t *testing.T) {
+	obj := testutil.Unstructured(t, webhookConfig)
+	require.True(t, IsWebhookConfiguration(obj))
+	webhooks, err := ReadWebhooks(obj)
+	require.NoError(t, err)
+	assert.Equal(t, []Webhook{
+		{
+			Name: "mutate.example.com",
+			Service: &ServiceReference{
+				Namespace: "webhook-system",
+				Name:      "webhook",
+			},
+			Rules: []Rule{
+				{
+					Operations:  []string{"CREATE"},
+					APIGroups:   []string{"apps"},
+					APIVersions: []string{"*"},
+					Resources:   []string{"deployments"},
+					Scope:       "Namespaced",
+				},
+			},
+		},
+		{
+			Name: "url.example.com",
+		},
+	}, webhooks)
+}
+
+func TestRuleMatches(t *testing.T) {
+	deployment := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	testCases := map[string]struct {
+		rule       Rule
+		gvk        schema.GroupVersionKind
+		namespaced bool
+		expected   bool
+	}{
+		"matching resource": {
+			rule: Rule{
+				Operations:  []string{"CREATE"},
+				APIGroups:   []string{"apps"},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"deployments"},
+			},
+			gvk:        deployment,
+			namespaced: true,
+			expected:   true,
+		},
+		"wildcards": {
+			rule: Rule{
+				Operations:  []string{"*"},
+				APIGroups:   []string{"*"},
+				APIVersions: []string{"*"},
+				Resources:   []string{"*/*"},
+			},
+			gvk:      deployment,
+			expected: true,
+		},
+		"delete only": {
+			rule: Rule{
+				Operations:  []string{"DELETE"},
+				APIGroups:   []string{"*"},
+				APIVersions: []string{"*"},
+				Resources:   []string{"*"},
+			},
+			gvk:      deployment,
+			expected: false,
+		},
+		"subresource only": {
+			rule: Rule{
+				Operations:  []string{"UPDATE"},
+				APIGroups:   []string{"apps"},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"deployments/scale"},
+			},
+			gvk:        deployment,
+			namespaced: true,
+			expected:   false,
+		},
+		"other version": {
+			rule: Rule{
+				Operations:  []string{"CREATE"},
+				APIGroups:   []string{"apps"},
+				APIVersions: []string{"v1beta1"},
+				Resources:   []string{"deployments"},
+			},
+			gvk:        deployment,
+			namespaced: true,
+			expected:   false,
+		},
+		"cluster scope": {
+			rule: Rule{
+				Operations:  []string{"CREATE"},
+				APIGroups:   []string{"apps"},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"deployments"},
+				Scope:       "Cluster",
+			},
+			gvk:        deployment,
+			namespaced: true,
+			expected:   false,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.rule.Matches(tc.gvk, tc.namespaced))
+		})
+	}
+}
+
+func TestBackends(t *testing.T) {
+	service := testutil.Unstructured(t, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: webhook
+  namespace: webhook-system
+spec:
+  selector:
+    app: webhook
+`)
+	deployment := testutil.Unstructured(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: webhook
+  namespace: webhook-system
+spec:
+  template:
+    metadata:
+      labels:
+        app: webhook
+        tier: backend
+`)
+	other := testutil.Unstructured(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: other
+  namespace: webhook-system
+spec:
+  template:
+    metadata:
+      labels:
+        app: other
+`)
+	webhooks, err := ReadWebhooks(testutil.Unstructured(t, webhookConfig))
+	require.NoError(t, err)
+
+	objs := object.UnstructuredSet{service, deployment, other}
+	assert.Equal(t, object.ObjMetadataSet{
+		object.UnstructuredToObjMetadata(service),
+		object.UnstructuredToObjMetadata(deployment),
+	}, webhooks[0].Backends(objs))
+	assert.Empty(t, webhooks[0].Backends(object.UnstructuredSet{deployment}))
+	assert.Empty(t, webhooks[1].Backends(objs))
+}
@@ -4,6 +4,8 @@
 package list
 
 import (
+	"sort"
+
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -18,6 +20,13 @@ type Formatter interface {
 	FormatPruneEvent(pe event.PruneEvent) error
 	FormatDeleteEvent(de event.DeleteEvent) error
 	FormatWaitEvent(we event.WaitEvent) error
+	FormatDiffEvent(de event.DiffEvent) error
+	FormatAdoptEvent(ae event.AdoptEvent) error
+	FormatTraceEvent(te event.TraceEvent) error
+	FormatConflictEvent(ce event.ConflictEvent) error
+	FormatRollbackEvent(re event.RollbackEvent) error
+	FormatDriftEvent(de event.DriftEvent) error
+	FormatPlanSummaryEvent(pe event.PlanSummaryEvent) error
 	FormatErrorEvent(ee event.ErrorEvent) error
 	FormatActionGroupEvent(
 		age event.ActionGroupEvent,
@@ -32,6 +41,11 @@ type FormatterFactory func(previewStrategy common.DryRunStrategy) Formatter
 
 type BaseListPrinter struct {
 	FormatterFactory FormatterFactory
+	// Deterministic buffers the events of each action group until the group
+	// finished, and then formats them sorted by the identifier of their
+	// object, so the output does not depend on the order concurrent
+	// operations completed in. Meant for snapshot tests of the output.
+	Deterministic bool
 }
 
 type Collector interface {
@@ -54,68 +68,189 @@ func (sc *StatusCollector) LatestStatus() map[object.ObjMetadata]event.StatusEve
 // format on StdOut. As we support other printer implementations
 // this should probably be an interface.
 // This function will block until the channel is closed.
-//
-//nolint:gocyclo
 func (b *BaseListPrinter) Print(ch <-chan event.Event, previewStrategy common.DryRunStrategy, printStatus bool) error {
-	var actionGroups []event.ActionGroup
 	var statsCollector stats.Stats
-	statusCollector := &StatusCollector{
-		latestStatus: make(map[object.ObjMetadata]event.StatusEvent),
+	lp := &listPrinter{
+		formatter:   b.FormatterFactory(previewStrategy),
+		printStatus: printStatus,
+		stats:       &statsCollector,
+		statusCollector: &StatusCollector{
+			latestStatus: make(map[object.ObjMetadata]event.StatusEvent),
+		},
 	}
-	formatter := b.FormatterFactory(previewStrategy)
+	// buffered are the events of the running action group, if Deterministic.
+	var buffered []event.Event
+	grouping := false
 	for e := range ch {
 		statsCollector.Handle(e)
-		switch e.Type {
-		case event.InitType:
-			actionGroups = e.InitEvent.ActionGroups
-		case event.ErrorType:
-			_ = formatter.FormatErrorEvent(e.ErrorEvent)
-			return e.ErrorEvent.Err
-		case event.ValidationType:
-			if err := formatter.FormatValidationEvent(e.ValidationEvent); err != nil {
-				return err
-			}
-		case event.ApplyType:
-			if err := formatter.FormatApplyEvent(e.ApplyEvent); err != nil {
-				return err
-			}
-		case event.StatusType:
-			statusCollector.updateStatus(e.StatusEvent.Identifier, e.StatusEvent)
-			if printStatus {
-				if err := formatter.FormatStatusEvent(e.StatusEvent); err != nil {
+		if b.Deterministic {
+			isGroupEvent := e.Type == event.ActionGroupType
+			switch {
+			case isGroupEvent && e.ActionGroupEvent.Status == event.Started:
+				grouping = true
+			case isGroupEvent && e.ActionGroupEvent.Status == event.Finished,
+				e.Type == event.ErrorType:
+				grouping = false
+				if err := lp.flush(buffered); err != nil {
 					return err
 				}
+				buffered = nil
+			case grouping:
+				buffered = append(buffered, e)
+				continue
 			}
-		case event.PruneType:
-			if err := formatter.FormatPruneEvent(e.PruneEvent); err != nil {
-				return err
-			}
-		case event.DeleteType:
-			if err := formatter.FormatDeleteEvent(e.DeleteEvent); err != nil {
-				return err
-			}
-		case event.WaitType:
-			if err := formatter.FormatWaitEvent(e.WaitEvent); err != nil {
-				return err
-			}
-		case event.ActionGroupType:
-			if err := formatter.FormatActionGroupEvent(
-				e.ActionGroupEvent,
-				actionGroups,
-				statsCollector,
-				statusCollector,
-			); err != nil {
-				return err
-			}
+		}
+		if err := lp.print(e); err != nil {
+			return err
 		}
 	}
+	if err := lp.flush(buffered); err != nil {
+		return err
+	}
 
-	if err := formatter.FormatSummary(statsCollector); err != nil {
+	if err := lp.formatter.FormatSummary(statsCollector); err != nil {
 		return err
 	}
 	return printcommon.ResultErrorFromStats(statsCollector)
 }
 
+// listPrinter formats the events of a single run.
+type listPrinter struct {
+	formatter       Formatter
+	printStatus     bool
+	actionGroups    []event.ActionGroup
+	stats           *stats.Stats
+	statusCollector *StatusCollector
+}
+
+// flush formats the buffered events, sorted by the identifier of their
+// object. The events of each object remain in the order they were received.
+func (lp *listPrinter) flush(events []event.Event) error {
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventIdentifier(events[i]).String() < eventIdentifier(events[j]).String()
+	})
+	for _, e := range events {
+		if err := lp.print(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// print formats the event. An ErrorEvent is returned as error, which ends
+// the run.
+//
+//nolint:gocyclo
+func (lp *listPrinter) print(e event.Event) error {
+	formatter := lp.formatter
+	switch e.Type {
+	case event.InitType:
+		lp.actionGroups = e.InitEvent.ActionGroups
+	case event.ErrorType:
+		_ = formatter.FormatErrorEvent(e.ErrorEvent)
+		return e.ErrorEvent.Err
+	case event.ValidationType:
+		if err := formatter.FormatValidationEvent(e.ValidationEvent); err != nil {
+			return err
+		}
+	case event.ApplyType:
+		if err := formatter.FormatApplyEvent(e.ApplyEvent); err != nil {
+			return err
+		}
+	case event.StatusType:
+		lp.statusCollector.updateStatus(e.StatusEvent.Identifier, e.StatusEvent)
+		if lp.printStatus {
+			if err := formatter.FormatStatusEvent(e.StatusEvent); err != nil {
+				return err
+			}
+		}
+	case event.PruneType:
+		if err := formatter.FormatPruneEvent(e.PruneEvent); err != nil {
+			return err
+		}
+	case event.DeleteType:
+		if err := formatter.FormatDeleteEvent(e.DeleteEvent); err != nil {
+			return err
+		}
+	case event.WaitType:
+		if err := formatter.FormatWaitEvent(e.WaitEvent); err != nil {
+			return err
+		}
+	case event.DiffType:
+		if err := formatter.FormatDiffEvent(e.DiffEvent); err != nil {
+			return err
+		}
+	case event.AdoptType:
+		if err := formatter.FormatAdoptEvent(e.AdoptEvent); err != nil {
+			return err
+		}
+	case event.TraceType:
+		if err := formatter.FormatTraceEvent(e.TraceEvent); err != nil {
+			return err
+		}
+	case event.ConflictType:
+		if err := formatter.FormatConflictEvent(e.ConflictEvent); err != nil {
+			return err
+		}
+	case event.RollbackType:
+		if err := formatter.FormatRollbackEvent(e.RollbackEvent); err != nil {
+			return err
+		}
+	case event.DriftType:
+		if err := formatter.FormatDriftEvent(e.DriftEvent); err != nil {
+			return err
+		}
+	case event.PlanSummaryType:
+		if err := formatter.FormatPlanSummaryEvent(e.PlanSummaryEvent); err != nil {
+			return err
+		}
+	case event.ActionGroupType:
+		if err := formatter.FormatActionGroupEvent(
+			e.ActionGroupEvent,
+			lp.actionGroups,
+			*lp.stats,
+			lp.statusCollector,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventIdentifier returns the identifier of the object of the event, or
+// the zero value if the event is not about a single object.
+func eventIdentifier(e event.Event) object.ObjMetadata {
+	switch e.Type {
+	case event.ValidationType:
+		if len(e.ValidationEvent.Identifiers) > 0 {
+			return e.ValidationEvent.Identifiers[0]
+		}
+	case event.ApplyType:
+		return e.ApplyEvent.Identifier
+	case event.StatusType:
+		return e.StatusEvent.Identifier
+	case event.PruneType:
+		return e.PruneEvent.Identifier
+	case event.DeleteType:
+		return e.DeleteEvent.Identifier
+	case event.WaitType:
+		return e.WaitEvent.Identifier
+	case event.DiffType:
+		return e.DiffEvent.Identifier
+	case event.AdoptType:
+		return e.AdoptEvent.Identifier
+	case event.TraceType:
+		return e.TraceEvent.Identifier
+	case event.ConflictType:
+		return e.ConflictEvent.Identifier
+	case event.RollbackType:
+		return e.RollbackEvent.Identifier
+	case event.DriftType:
+		return e.DriftEvent.Identifier
+	}
+	return object.ObjMetadata{}
+}
+
 // IsLastActionGroup returns true if the passed ActionGroupEvent is the
 // last of its type in the slice of ActionGroup; false otherwise. For example,
 // this function will determine if an ApplyAction is the last ApplyAction in
@@ -34,6 +34,13 @@ type countingFormatter struct {
 	pruneEvents      []event.PruneEvent
 	deleteEvents     []event.DeleteEvent
 	waitEvents       []event.WaitEvent
+	diffEvents       []event.DiffEvent
+	adoptEvents      []event.AdoptEvent
+	traceEvents      []event.TraceEvent
+	conflictEvents   []event.ConflictEvent
+	rollbackEvents   []event.RollbackEvent
+	driftEvents      []event.DriftEvent
+	planEvents       []event.PlanSummaryEvent
 	errorEvent       event.ErrorEvent
 	actionGroupEvent []event.ActionGroupEvent
 }
@@ -68,6 +75,41 @@ func (c *countingFormatter) FormatWaitEvent(e event.WaitEvent) error {
 	return nil
 }
 
+func (c *countingFormatter) FormatDiffEvent(e event.DiffEvent) error {
+	c.diffEvents = append(c.diffEvents, e)
+	return nil
+}
+
+func (c *countingFormatter) FormatAdoptEvent(e event.AdoptEvent) error {
+	c.adoptEvents = append(c.adoptEvents, e)
+	return nil
+}
+
+func (c *countingFormatter) FormatTraceEvent(e event.TraceEvent) error {
+	c.traceEvents = append(c.traceEvents, e)
+	return nil
+}
+
+func (c *countingFormatter) FormatConflictEvent(e event.ConflictEvent) error {
+	c.conflictEvents = append(c.conflictEvents, e)
+	return nil
+}
+
+func (c *countingFormatter) FormatRollbackEvent(e event.RollbackEvent) error {
+	c.rollbackEvents = append(c.rollbackEvents, e)
+	return nil
+}
+
+func (c *countingFormatter) FormatPlanSummaryEvent(e event.PlanSummaryEvent) error {
+	c.planEvents = append(c.planEvents, e)
+	return nil
+}
+
+func (c *countingFormatter) FormatDriftEvent(e event.DriftEvent) error {
+	c.driftEvents = append(c.driftEvents, e)
+	return nil
+}
+
 func (c *countingFormatter) FormatErrorEvent(e event.ErrorEvent) error {
 	c.errorEvent = e
 	return nil
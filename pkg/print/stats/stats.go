@@ -74,6 +74,9 @@ type PruneStats struct {
 	Successful int
 	Skipped    int
 	Failed     int
+	// Detached objects were removed from the inventory without being
+	// deleted.
+	Detached int
 }
 
 func (p *PruneStats) Inc(op event.PruneEventStatus) {
@@ -84,6 +87,8 @@ func (p *PruneStats) Inc(op event.PruneEventStatus) {
 		p.Skipped++
 	case event.PruneFailed:
 		p.Failed++
+	case event.PruneDetached:
+		p.Detached++
 	default:
 		panic(fmt.Errorf("invalid prune status %s", op.String()))
 	}
@@ -94,13 +99,16 @@ func (p *PruneStats) IncFailed() {
 }
 
 func (p *PruneStats) Sum() int {
-	return p.Successful + p.Skipped + p.Failed
+	return p.Successful + p.Skipped + p.Failed + p.Detached
 }
 
 type DeleteStats struct {
 	Successful int
 	Skipped    int
 	Failed     int
+	// Detached objects were removed from the inventory without being
+	// deleted.
+	Detached int
 }
 
 func (d *DeleteStats) Inc(op event.DeleteEventStatus) {
@@ -111,6 +119,8 @@ func (d *DeleteStats) Inc(op event.DeleteEventStatus) {
 		d.Skipped++
 	case event.DeleteFailed:
 		d.Failed++
+	case event.DeleteDetached:
+		d.Detached++
 	default:
 		panic(fmt.Errorf("invalid delete status %s", op.String()))
 	}
@@ -121,7 +131,7 @@ func (d *DeleteStats) IncFailed() {
 }
 
 func (d *DeleteStats) Sum() int {
-	return d.Successful + d.Skipped + d.Failed
+	return d.Successful + d.Skipped + d.Failed + d.Detached
 }
 
 type WaitStats struct {
@@ -137,7 +147,7 @@ func (w *WaitStats) Inc(status event.WaitEventStatus) {
 		// ignore - should be replaced by one of the others before the WaitTask exits
 	case event.ReconcileSuccessful:
 		w.Successful++
-	case event.ReconcileSkipped:
+	case event.ReconcileSkipped, event.ReconcileSkippedByAnnotation:
 		w.Skipped++
 	case event.ReconcileTimeout:
 		w.Timeout++
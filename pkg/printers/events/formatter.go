@@ -5,6 +5,7 @@ package events
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -64,6 +65,9 @@ func (ef *formatter) FormatApplyEvent(e event.ApplyEvent) error {
 	if e.Error != nil {
 		ef.print("%s apply %s: %s", resourceIDToString(gk, name),
 			strings.ToLower(e.Status.String()), e.Error.Error())
+	} else if strategy := applyStrategyString(e.Strategy); strategy != "" {
+		ef.print("%s apply %s (%s)", resourceIDToString(gk, name),
+			strings.ToLower(e.Status.String()), strategy)
 	} else {
 		ef.print("%s apply %s", resourceIDToString(gk, name),
 			strings.ToLower(e.Status.String()))
@@ -71,6 +75,19 @@ func (ef *formatter) FormatApplyEvent(e event.ApplyEvent) error {
 	return nil
 }
 
+// applyStrategyString returns how an object was applied, or an empty
+// string if it is not known.
+func applyStrategyString(strategy event.ApplyStrategy) string {
+	switch strategy {
+	case event.ApplyStrategyServerSide:
+		return "server-side"
+	case event.ApplyStrategyClientSide:
+		return "client-side"
+	default:
+		return ""
+	}
+}
+
 func (ef *formatter) FormatStatusEvent(se event.StatusEvent) error {
 	id := se.Identifier
 	ef.printResourceStatus(id, se)
@@ -111,6 +128,132 @@ func (ef *formatter) FormatWaitEvent(e event.WaitEvent) error {
 	return nil
 }
 
+func (ef *formatter) FormatDiffEvent(e event.DiffEvent) error {
+	gk := e.Identifier.GroupKind
+	name := e.Identifier.Name
+	if e.Error != nil {
+		ef.print("%s diff failed: %s", resourceIDToString(gk, name), e.Error.Error())
+		return nil
+	}
+	if len(e.Diffs) == 0 {
+		ef.print("%s diff: no changes", resourceIDToString(gk, name))
+		return nil
+	}
+	for _, d := range e.Diffs {
+		ef.print("%s diff %s %s", resourceIDToString(gk, name),
+			strings.ToLower(string(d.Operation)), d.Change())
+	}
+	return nil
+}
+
+func (ef *formatter) FormatAdoptEvent(e event.AdoptEvent) error {
+	if e.Error != nil {
+		ef.print("%s adopt failed: %s", resourceIDToString(e.Identifier.GroupKind, e.Identifier.Name),
+			e.Error.Error())
+		return nil
+	}
+	ef.print("%s adopted", resourceIDToString(e.Identifier.GroupKind, e.Identifier.Name))
+	return nil
+}
+
+func (ef *formatter) FormatTraceEvent(e event.TraceEvent) error {
+	id := resourceIDToString(e.Identifier.GroupKind, e.Identifier.Name)
+	if len(e.After) == 0 {
+		ef.print("%s ordered in %s: no dependencies", id, e.GroupName)
+		return nil
+	}
+	for _, dep := range e.After {
+		ef.print("%s ordered in %s after %s (%s)", id, e.GroupName,
+			resourceIDToString(dep.Identifier.GroupKind, dep.Identifier.Name),
+			strings.Join(dep.Reasons, ", "))
+	}
+	return nil
+}
+
+func (ef *formatter) FormatConflictEvent(e event.ConflictEvent) error {
+	id := resourceIDToString(e.Identifier.GroupKind, e.Identifier.Name)
+	for _, c := range e.Conflicts {
+		ef.print("%s conflict: %s is managed by %q", id, c.Field, c.Manager)
+	}
+	return nil
+}
+
+func (ef *formatter) FormatRollbackEvent(e event.RollbackEvent) error {
+	gk := e.Identifier.GroupKind
+	name := e.Identifier.Name
+	if e.Error != nil {
+		ef.print("%s rollback %s %s: %s", resourceIDToString(gk, name),
+			strings.ToLower(e.Operation.String()), strings.ToLower(e.Status.String()), e.Error.Error())
+	} else {
+		ef.print("%s rollback %s %s", resourceIDToString(gk, name),
+			strings.ToLower(e.Operation.String()), strings.ToLower(e.Status.String()))
+	}
+	return nil
+}
+
+func (ef *formatter) FormatDriftEvent(e event.DriftEvent) error {
+	id := resourceIDToString(e.Identifier.GroupKind, e.Identifier.Name)
+	switch e.Status {
+	case event.DriftInSync:
+		ef.print("%s drift: in sync", id)
+	case event.DriftModified:
+		if len(e.Managers) > 0 {
+			ef.print("%s drift: modified by %s", id, strings.Join(quoteAll(e.Managers), ", "))
+		} else {
+			ef.print("%s drift: modified", id)
+		}
+		for _, d := range e.Diffs {
+			ef.print("%s drift %s %s", id, strings.ToLower(string(d.Operation)), d.Change())
+		}
+	case event.DriftDeleted:
+		ef.print("%s drift: deleted", id)
+	case event.DriftNotApplied:
+		ef.print("%s drift: not applied", id)
+	case event.DriftFailed:
+		ef.print("%s drift failed: %s", id, e.Error.Error())
+	}
+	return nil
+}
+
+func (ef *formatter) FormatPlanSummaryEvent(e event.PlanSummaryEvent) error {
+	ef.print("plan: +%d ~%d -%d (%d unchanged, %d failed)", len(e.Create),
+		len(e.Configure), len(e.Prune), len(e.Unchanged), len(e.Errors))
+	for _, id := range e.Create {
+		ef.print("%s plan: create", resourceIDToString(id.GroupKind, id.Name))
+	}
+	for _, id := range e.Configure {
+		ef.print("%s plan: configure", resourceIDToString(id.GroupKind, id.Name))
+	}
+	for _, id := range e.Prune {
+		ef.print("%s plan: prune", resourceIDToString(id.GroupKind, id.Name))
+	}
+	for _, id := range sortedErrorIDs(e.Errors) {
+		ef.print("%s plan failed: %s", resourceIDToString(id.GroupKind, id.Name), e.Errors[id].Error())
+	}
+	return nil
+}
+
+// sortedErrorIDs returns the identifiers of the errors, sorted.
+func sortedErrorIDs(errs map[object.ObjMetadata]error) object.ObjMetadataSet {
+	ids := make(object.ObjMetadataSet, 0, len(errs))
+	for id := range errs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+	return ids
+}
+
+// quoteAll returns the strings quoted with %q.
+func quoteAll(strs []string) []string {
+	quoted := make([]string, len(strs))
+	for i, s := range strs {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	return quoted
+}
+
 func (ef *formatter) FormatErrorEvent(_ event.ErrorEvent) error {
 	return nil
 }
@@ -146,13 +289,13 @@ func (ef *formatter) FormatSummary(s stats.Stats) error {
 	}
 	if s.PruneStats != (stats.PruneStats{}) {
 		ps := s.PruneStats
-		ef.print("prune result: %d attempted, %d successful, %d skipped, %d failed",
-			ps.Sum(), ps.Successful, ps.Skipped, ps.Failed)
+		ef.print("prune result: %d attempted, %d successful, %d skipped, %d failed%s",
+			ps.Sum(), ps.Successful, ps.Skipped, ps.Failed, detachedSuffix(ps.Detached))
 	}
 	if s.DeleteStats != (stats.DeleteStats{}) {
 		ds := s.DeleteStats
-		ef.print("delete result: %d attempted, %d successful, %d skipped, %d failed",
-			ds.Sum(), ds.Successful, ds.Skipped, ds.Failed)
+		ef.print("delete result: %d attempted, %d successful, %d skipped, %d failed%s",
+			ds.Sum(), ds.Successful, ds.Skipped, ds.Failed, detachedSuffix(ds.Detached))
 	}
 	if s.WaitStats != (stats.WaitStats{}) {
 		ws := s.WaitStats
@@ -162,6 +305,15 @@ func (ef *formatter) FormatSummary(s stats.Stats) error {
 	return nil
 }
 
+// detachedSuffix returns the number of detached objects to append to the
+// prune or delete result, or an empty string if none were detached.
+func detachedSuffix(detached int) string {
+	if detached == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", %d detached", detached)
+}
+
 func (ef *formatter) printResourceStatus(id object.ObjMetadata, se event.StatusEvent) {
 	ef.print("%s is %s: %s", resourceIDToString(id.GroupKind, id.Name),
 		se.PollResourceInfo.Status.String(), se.PollResourceInfo.Message)
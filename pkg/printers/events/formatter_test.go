@@ -16,6 +16,7 @@ import (
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/diff"
 	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -55,6 +56,15 @@ func TestFormatter_FormatApplyEvent(t *testing.T) {
 			},
 			expected: "cronjob.batch/my-cron apply successful",
 		},
+		"resource applied with client-side apply": {
+			previewStrategy: common.DryRunNone,
+			event: event.ApplyEvent{
+				Status:     event.ApplySuccessful,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Strategy:   event.ApplyStrategyClientSide,
+			},
+			expected: "deployment.apps/my-dep apply successful (client-side)",
+		},
 		"apply event with error should display the error": {
 			previewStrategy: common.DryRunServer,
 			event: event.ApplyEvent{
@@ -363,6 +373,117 @@ func TestFormatter_FormatWaitEvent(t *testing.T) {
 	}
 }
 
+func TestFormatter_FormatDiffEvent(t *testing.T) {
+	testCases := map[string]struct {
+		event    event.DiffEvent
+		expected string
+	}{
+		"no changes": {
+			event: event.DiffEvent{
+				GroupName:  "apply-1",
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+			},
+			expected: "deployment.apps/my-dep diff: no changes",
+		},
+		"changed fields": {
+			event: event.DiffEvent{
+				GroupName:  "apply-1",
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Diffs: []diff.FieldDiff{
+					{Path: ".spec.replicas", Operation: diff.Changed, Old: 1, New: 3},
+					{Path: ".spec.template.spec.containers[0].image", Operation: diff.Changed, Old: "app:v1", New: "app:v2"},
+					{Path: ".spec.paused", Operation: diff.Removed, Old: true},
+				},
+			},
+			expected: `deployment.apps/my-dep diff changed .spec.replicas: 1 -> 3
+deployment.apps/my-dep diff changed .spec.template.spec.containers[0].image: "app:v1" -> "app:v2"
+deployment.apps/my-dep diff removed .spec.paused: true`,
+		},
+		"diff failed": {
+			event: event.DiffEvent{
+				GroupName:  "apply-1",
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Error:      errors.New("dry-run not supported"),
+			},
+			expected: "deployment.apps/my-dep diff failed: dry-run not supported",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			formatter := NewFormatter(ioStreams, common.DryRunNone)
+			err := formatter.FormatDiffEvent(tc.event)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expected, strings.TrimSpace(out.String()))
+		})
+	}
+}
+
+func TestFormatter_FormatAdoptEvent(t *testing.T) {
+	ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+	formatter := NewFormatter(ioStreams, common.DryRunNone)
+	err := formatter.FormatAdoptEvent(event.AdoptEvent{
+		Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "deployment.apps/my-dep adopted", strings.TrimSpace(out.String()))
+
+	out.Reset()
+	err = formatter.FormatAdoptEvent(event.AdoptEvent{
+		Identifier: createIdentifier("apps", "Deployment", "default", "other-dep"),
+		Error:      errors.New("owned by another inventory"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "deployment.apps/other-dep adopt failed: owned by another inventory", strings.TrimSpace(out.String()))
+}
+
+func TestFormatter_FormatTraceEvent(t *testing.T) {
+	testCases := map[string]struct {
+		event    event.TraceEvent
+		expected string
+	}{
+		"no dependencies": {
+			event: event.TraceEvent{
+				GroupName:  "apply-0",
+				Identifier: createIdentifier("", "Secret", "default", "my-secret"),
+			},
+			expected: "secret/my-secret ordered in apply-0: no dependencies",
+		},
+		"dependencies": {
+			event: event.TraceEvent{
+				GroupName:  "apply-1",
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				After: []event.TraceDependency{
+					{
+						Identifier: createIdentifier("", "Namespace", "", "default"),
+						Reasons:    []string{"namespace"},
+					},
+					{
+						Identifier: createIdentifier("", "Secret", "default", "my-secret"),
+						Reasons:    []string{"depends-on", "apply-time-mutation"},
+					},
+				},
+			},
+			expected: `
+deployment.apps/my-dep ordered in apply-1 after namespace/default (namespace)
+deployment.apps/my-dep ordered in apply-1 after secret/my-secret (depends-on, apply-time-mutation)
+`,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			formatter := NewFormatter(ioStreams, common.DryRunNone)
+			err := formatter.FormatTraceEvent(tc.event)
+			assert.NoError(t, err)
+			assert.Equal(t, strings.TrimSpace(tc.expected), strings.TrimSpace(out.String()))
+		})
+	}
+}
+
 func TestFormatter_FormatValidationEvent(t *testing.T) {
 	testCases := map[string]struct {
 		previewStrategy common.DryRunStrategy
@@ -530,3 +651,138 @@ func createIdentifier(group, kind, namespace, name string) object.ObjMetadata {
 		},
 	}
 }
+
+func TestFormatter_FormatConflictEvent(t *testing.T) {
+	ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+	formatter := NewFormatter(ioStreams, common.DryRunNone)
+	err := formatter.FormatConflictEvent(event.ConflictEvent{
+		GroupName:  "apply-0",
+		Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+		Conflicts: []event.FieldConflict{
+			{Manager: "kubectl-edit", Field: ".spec.replicas"},
+			{Manager: "hpa-controller", Field: ".spec.template"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(`
+deployment.apps/my-dep conflict: .spec.replicas is managed by "kubectl-edit"
+deployment.apps/my-dep conflict: .spec.template is managed by "hpa-controller"
+`), strings.TrimSpace(out.String()))
+}
+
+func TestFormatter_FormatRollbackEvent(t *testing.T) {
+	testCases := map[string]struct {
+		event    event.RollbackEvent
+		expected string
+	}{
+		"delete successful": {
+			event: event.RollbackEvent{
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Operation:  event.RollbackDelete,
+				Status:     event.RollbackSuccessful,
+			},
+			expected: "deployment.apps/my-dep rollback delete successful",
+		},
+		"revert failed": {
+			event: event.RollbackEvent{
+				Identifier: createIdentifier("", "Secret", "default", "my-secret"),
+				Operation:  event.RollbackRevert,
+				Status:     event.RollbackFailed,
+				Error:      fmt.Errorf("conflict"),
+			},
+			expected: "secret/my-secret rollback revert failed: conflict",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			formatter := NewFormatter(ioStreams, common.DryRunNone)
+			err := formatter.FormatRollbackEvent(tc.event)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, strings.TrimSpace(out.String()))
+		})
+	}
+}
+
+func TestFormatter_FormatDriftEvent(t *testing.T) {
+	depID := createIdentifier("apps", "Deployment", "default", "my-dep")
+	testCases := map[string]struct {
+		event    event.DriftEvent
+		expected string
+	}{
+		"in sync": {
+			event: event.DriftEvent{
+				Identifier: depID,
+				Status:     event.DriftInSync,
+			},
+			expected: "deployment.apps/my-dep drift: in sync",
+		},
+		"modified": {
+			event: event.DriftEvent{
+				Identifier: depID,
+				Status:     event.DriftModified,
+				Diffs: []diff.FieldDiff{
+					{Path: ".spec.replicas", Operation: diff.Changed, Old: int64(5), New: int64(1)},
+				},
+				Managers: []string{"kubectl-edit", "hpa-controller"},
+			},
+			expected: strings.TrimSpace(`
+deployment.apps/my-dep drift: modified by "kubectl-edit", "hpa-controller"
+deployment.apps/my-dep drift changed .spec.replicas: 5 -> 1
+`),
+		},
+		"deleted": {
+			event: event.DriftEvent{
+				Identifier: depID,
+				Status:     event.DriftDeleted,
+			},
+			expected: "deployment.apps/my-dep drift: deleted",
+		},
+		"failed": {
+			event: event.DriftEvent{
+				Identifier: depID,
+				Status:     event.DriftFailed,
+				Error:      fmt.Errorf("forbidden"),
+			},
+			expected: "deployment.apps/my-dep drift failed: forbidden",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			formatter := NewFormatter(ioStreams, common.DryRunNone)
+			err := formatter.FormatDriftEvent(tc.event)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, strings.TrimSpace(out.String()))
+		})
+	}
+}
+
+func TestFormatter_FormatPlanSummaryEvent(t *testing.T) {
+	depID := createIdentifier("apps", "Deployment", "default", "my-dep")
+	cmID := createIdentifier("", "ConfigMap", "default", "my-cm")
+	secretID := createIdentifier("", "Secret", "default", "my-secret")
+	svcID := createIdentifier("", "Service", "default", "my-svc")
+	saID := createIdentifier("", "ServiceAccount", "default", "my-sa")
+	ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+	formatter := NewFormatter(ioStreams, common.DryRunNone)
+	err := formatter.FormatPlanSummaryEvent(event.PlanSummaryEvent{
+		Create:    object.ObjMetadataSet{depID},
+		Configure: object.ObjMetadataSet{cmID},
+		Unchanged: object.ObjMetadataSet{saID},
+		Prune:     object.ObjMetadataSet{secretID},
+		Errors: map[object.ObjMetadata]error{
+			svcID: fmt.Errorf("forbidden"),
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(`
+plan: +1 ~1 -1 (1 unchanged, 1 failed)
+deployment.apps/my-dep plan: create
+configmap/my-cm plan: configure
+secret/my-secret plan: prune
+service/my-svc plan failed: forbidden
+`), strings.TrimSpace(out.String()))
+}
@@ -0,0 +1,377 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package journal persists the event stream of an apply or destroy run to
+// a JSON Lines file, and replays a journal through any printer.
+//
+// The first line of a journal is a header with the version of the format
+// and the dry-run strategy of the run. Every following line is a record of
+// one event, with the time it was received, its type and the sub-event of
+// that type. Errors are recorded by their message, so replayed events have
+// errors with the same message, but not the same type. Validation errors
+// keep the identifiers of the invalid objects, and the errors of a plan
+// summary keep the identifiers of their objects.
+//
+// Objects are recorded as they were received, except for Secrets: their
+// data and stringData values and their last-applied-configuration
+// annotation are replaced with a placeholder, so a journal does not
+// contain credentials. Their metadata, type and keys are kept.
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/validation"
+)
+
+// Version is the version of the journal format.
+const Version = 1
+
+// redactedValue replaces the values of Secrets in a journal.
+const redactedValue = "***"
+
+// header is the first line of a journal.
+type header struct {
+	Version        int    `json:"version"`
+	DryRunStrategy string `json:"dryRunStrategy"`
+}
+
+// record is a line of a journal with one event.
+type record struct {
+	Time  time.Time       `json:"time"`
+	Type  string          `json:"type"`
+	Event json.RawMessage `json:"event"`
+	// Error is the error of the event, if any.
+	Error *recordError `json:"error,omitempty"`
+	// StatusErrors are the errors of the polled resource status of a
+	// StatusEvent, keyed by the path of the status in the tree of generated
+	// resources, e.g. "" for the resource and "0.1" for the second resource
+	// generated by the first generated resource.
+	StatusErrors map[string]*recordError `json:"statusErrors,omitempty"`
+	// ObjectErrors are the errors of the objects of a PlanSummaryEvent,
+	// keyed by the string of the object identifier.
+	ObjectErrors map[string]*recordError `json:"objectErrors,omitempty"`
+}
+
+// recordError is a recorded error.
+type recordError struct {
+	Message string `json:"message"`
+	// Validation is true if the error is a validation error of the objects
+	// with the Identifiers.
+	Validation  bool                  `json:"validation,omitempty"`
+	Identifiers object.ObjMetadataSet `json:"identifiers,omitempty"`
+}
+
+func newRecordError(err error) *recordError {
+	if err == nil {
+		return nil
+	}
+	if vErr, ok := err.(*validation.Error); ok {
+		return &recordError{
+			Message:     vErr.Unwrap().Error(),
+			Validation:  true,
+			Identifiers: vErr.Identifiers(),
+		}
+	}
+	return &recordError{Message: err.Error()}
+}
+
+func (re *recordError) error() error {
+	if re == nil {
+		return nil
+	}
+	if re.Validation {
+		return validation.NewError(errors.New(re.Message), re.Identifiers...)
+	}
+	return errors.New(re.Message)
+}
+
+// Writer writes events to a journal.
+type Writer struct {
+	mu             sync.Mutex
+	enc            *json.Encoder
+	dryRunStrategy common.DryRunStrategy
+	wroteHeader    bool
+	now            func() time.Time
+	// err is the first error of the events written by Tee.
+	err error
+}
+
+// NewWriter returns a Writer that writes a journal of a run with the
+// dry-run strategy to w. The header is written with the first event.
+func NewWriter(w io.Writer, dryRunStrategy common.DryRunStrategy) *Writer {
+	return &Writer{
+		enc:            json.NewEncoder(w),
+		dryRunStrategy: dryRunStrategy,
+		now:            time.Now,
+	}
+}
+
+// Write appends the event to the journal.
+func (w *Writer) Write(e event.Event) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.write(e)
+}
+
+func (w *Writer) write(e event.Event) error {
+	if !w.wroteHeader {
+		if err := w.enc.Encode(header{
+			Version:        Version,
+			DryRunStrategy: w.dryRunStrategy.String(),
+		}); err != nil {
+			return fmt.Errorf("failed to write journal: %w", err)
+		}
+		w.wroteHeader = true
+	}
+	rec, err := newRecord(e)
+	if err != nil {
+		return err
+	}
+	rec.Time = w.now()
+	if err := w.enc.Encode(rec); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// Tee writes the events received from the channel to the journal and
+// forwards them to the returned channel, which is closed after the passed
+// channel is closed. Events are forwarded even if they fail to be written;
+// the first error is returned by Err.
+func (w *Writer) Tee(ch <-chan event.Event) <-chan event.Event {
+	out := make(chan event.Event)
+	go func() {
+		defer close(out)
+		for e := range ch {
+			w.mu.Lock()
+			if err := w.write(e); err != nil && w.err == nil {
+				w.err = err
+			}
+			w.mu.Unlock()
+			out <- e
+		}
+	}()
+	return out
+}
+
+// Err returns the first error writing the events received by Tee.
+func (w *Writer) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// newRecord returns the record of the event, without a time.
+func newRecord(e event.Event) (*record, error) {
+	rec := &record{Type: e.Type.String()}
+	// Errors are replaced by their message, so the event is copied.
+	payload, errp := subEvent(&e)
+	if payload == nil {
+		return nil, fmt.Errorf("failed to write journal: invalid event type %q", rec.Type)
+	}
+	if errp != nil {
+		rec.Error = newRecordError(*errp)
+		*errp = nil
+	}
+	if e.Type == event.StatusType && e.StatusEvent.PollResourceInfo != nil {
+		e.StatusEvent.PollResourceInfo = stripStatusErrors(e.StatusEvent.PollResourceInfo, "", &rec.StatusErrors)
+	}
+	// Objects are shared with the event, so they are copied when redacted.
+	switch e.Type {
+	case event.ApplyType:
+		e.ApplyEvent.Resource = redactSecret(e.ApplyEvent.Resource)
+	case event.StatusType:
+		e.StatusEvent.Resource = redactSecret(e.StatusEvent.Resource)
+	case event.PruneType:
+		e.PruneEvent.Object = redactSecret(e.PruneEvent.Object)
+	case event.DeleteType:
+		e.DeleteEvent.Object = redactSecret(e.DeleteEvent.Object)
+	}
+	if e.Type == event.PlanSummaryType && len(e.PlanSummaryEvent.Errors) > 0 {
+		rec.ObjectErrors = make(map[string]*recordError, len(e.PlanSummaryEvent.Errors))
+		for id, err := range e.PlanSummaryEvent.Errors {
+			rec.ObjectErrors[id.String()] = newRecordError(err)
+		}
+		e.PlanSummaryEvent.Errors = nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write journal: %w", err)
+	}
+	rec.Event = data
+	return rec, nil
+}
+
+// event returns the recorded event.
+func (rec *record) event() (event.Event, error) {
+	var e event.Event
+	t, found := parseType(rec.Type)
+	if !found {
+		return e, fmt.Errorf("failed to read journal: invalid event type %q", rec.Type)
+	}
+	e.Type = t
+	payload, errp := subEvent(&e)
+	if err := json.Unmarshal(rec.Event, payload); err != nil {
+		return e, fmt.Errorf("failed to read journal: invalid %s: %w", rec.Type, err)
+	}
+	if errp != nil {
+		*errp = rec.Error.error()
+	}
+	if e.Type == event.StatusType && e.StatusEvent.PollResourceInfo != nil {
+		restoreStatusErrors(e.StatusEvent.PollResourceInfo, "", rec.StatusErrors)
+	}
+	if e.Type == event.PlanSummaryType && len(rec.ObjectErrors) > 0 {
+		e.PlanSummaryEvent.Errors = make(map[object.ObjMetadata]error, len(rec.ObjectErrors))
+		for key, re := range rec.ObjectErrors {
+			id, err := object.ParseObjMetadata(key)
+			if err != nil {
+				return e, fmt.Errorf("failed to read journal: invalid %s: %w", rec.Type, err)
+			}
+			e.PlanSummaryEvent.Errors[id] = re.error()
+		}
+	}
+	return e, nil
+}
+
+// subEvent returns a pointer to the sub-event of the event type, and a
+// pointer to the error of the sub-event, if it has one. The sub-event is
+// nil if the type is invalid.
+func subEvent(e *event.Event) (interface{}, *error) {
+	switch e.Type {
+	case event.InitType:
+		return &e.InitEvent, nil
+	case event.ErrorType:
+		return &e.ErrorEvent, &e.ErrorEvent.Err
+	case event.ActionGroupType:
+		return &e.ActionGroupEvent, nil
+	case event.ApplyType:
+		return &e.ApplyEvent, &e.ApplyEvent.Error
+	case event.StatusType:
+		return &e.StatusEvent, &e.StatusEvent.Error
+	case event.PruneType:
+		return &e.PruneEvent, &e.PruneEvent.Error
+	case event.DeleteType:
+		return &e.DeleteEvent, &e.DeleteEvent.Error
+	case event.WaitType:
+		return &e.WaitEvent, nil
+	case event.ValidationType:
+		return &e.ValidationEvent, &e.ValidationEvent.Error
+	case event.DiffType:
+		return &e.DiffEvent, &e.DiffEvent.Error
+	case event.AdoptType:
+		return &e.AdoptEvent, &e.AdoptEvent.Error
+	case event.TraceType:
+		return &e.TraceEvent, nil
+	case event.ConflictType:
+		return &e.ConflictEvent, nil
+	case event.RollbackType:
+		return &e.RollbackEvent, &e.RollbackEvent.Error
+	case event.DriftType:
+		return &e.DriftEvent, &e.DriftEvent.Error
+	case event.PlanSummaryType:
+		return &e.PlanSummaryEvent, nil
+	}
+	return nil, nil
+}
+
+// parseType returns the event type with the name.
+func parseType(name string) (event.Type, bool) {
+	for t := event.Type(0); ; t++ {
+		s := t.String()
+		if s == name {
+			return t, true
+		}
+		if s == "Type("+strconv.Itoa(int(t))+")" {
+			return 0, false
+		}
+	}
+}
+
+// parseDryRunStrategy returns the dry-run strategy with the name.
+func parseDryRunStrategy(name string) (common.DryRunStrategy, bool) {
+	for _, s := range []common.DryRunStrategy{common.DryRunNone, common.DryRunClient, common.DryRunServer} {
+		if s.String() == name {
+			return s, true
+		}
+	}
+	return common.DryRunNone, false
+}
+
+// redactSecret returns a copy of the object with its values replaced by
+// redactedValue, if it is a Secret. Other objects are returned as is.
+func redactSecret(u *unstructured.Unstructured) *unstructured.Unstructured {
+	if u == nil {
+		return nil
+	}
+	gvk := u.GroupVersionKind()
+	if gvk.Group != "" || gvk.Version != "v1" || gvk.Kind != "Secret" {
+		return u
+	}
+	c := u.DeepCopy()
+	for _, field := range []string{"data", "stringData"} {
+		values, _ := c.Object[field].(map[string]interface{})
+		for key := range values {
+			values[key] = redactedValue
+		}
+	}
+	annotations := c.GetAnnotations()
+	if _, found := annotations[corev1.LastAppliedConfigAnnotation]; found {
+		annotations[corev1.LastAppliedConfigAnnotation] = redactedValue
+		c.SetAnnotations(annotations)
+	}
+	return c
+}
+
+// stripStatusErrors returns a copy of the resource status without errors
+// and with redacted Secrets, and records the errors of the status tree in
+// errs.
+func stripStatusErrors(rs *pollevent.ResourceStatus, path string, errs *map[string]*recordError) *pollevent.ResourceStatus {
+	c := *rs
+	c.Resource = redactSecret(c.Resource)
+	if c.Error != nil {
+		if *errs == nil {
+			*errs = make(map[string]*recordError)
+		}
+		(*errs)[path] = newRecordError(c.Error)
+		c.Error = nil
+	}
+	if len(rs.GeneratedResources) > 0 {
+		c.GeneratedResources = make(pollevent.ResourceStatuses, len(rs.GeneratedResources))
+		for i, g := range rs.GeneratedResources {
+			if g != nil {
+				c.GeneratedResources[i] = stripStatusErrors(g, childPath(path, i), errs)
+			}
+		}
+	}
+	return &c
+}
+
+// restoreStatusErrors sets the recorded errors of the status tree.
+func restoreStatusErrors(rs *pollevent.ResourceStatus, path string, errs map[string]*recordError) {
+	rs.Error = errs[path].error()
+	for i, g := range rs.GeneratedResources {
+		if g != nil {
+			restoreStatusErrors(g, childPath(path, i), errs)
+		}
+	}
+}
+
+func childPath(path string, i int) string {
+	if path == "" {
+		return strconv.Itoa(i)
+	}
+	return path + "." + strconv.Itoa(i)
+}
@@ -0,0 +1,449 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package journal
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/diff"
+	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/validation"
+)
+
+var (
+	depID = object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		Namespace: "default",
+		Name:      "foo",
+	}
+	rsID = object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apps", Kind: "ReplicaSet"},
+		Namespace: "default",
+		Name:      "foo-123",
+	}
+	depObj = &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":       "foo",
+				"namespace":  "default",
+				"generation": int64(2),
+			},
+		},
+	}
+)
+
+// allEvents returns an event of each type.
+func allEvents() []event.Event {
+	return []event.Event{
+		{
+			Type: event.InitType,
+			InitEvent: event.InitEvent{
+				ActionGroups: event.ActionGroupList{
+					{Name: "apply-0", Action: event.ApplyAction, Identifiers: object.ObjMetadataSet{depID}},
+				},
+			},
+		},
+		{
+			Type:       event.ErrorType,
+			ErrorEvent: event.ErrorEvent{Err: errors.New("fatal")},
+		},
+		{
+			Type: event.ActionGroupType,
+			ActionGroupEvent: event.ActionGroupEvent{
+				GroupName: "apply-0",
+				Action:    event.ApplyAction,
+				Status:    event.Finished,
+			},
+		},
+		{
+			Type: event.ApplyType,
+			ApplyEvent: event.ApplyEvent{
+				GroupName:  "apply-0",
+				Identifier: depID,
+				Status:     event.ApplyFailed,
+				Resource:   depObj,
+				Error:      errors.New("apply failed"),
+			},
+		},
+		{
+			Type: event.StatusType,
+			StatusEvent: event.StatusEvent{
+				Identifier: depID,
+				PollResourceInfo: &pollevent.ResourceStatus{
+					Identifier: depID,
+					Status:     status.InProgressStatus,
+					Resource:   depObj,
+					Message:    "rolling out",
+					Error:      errors.New("status failed"),
+					GeneratedResources: pollevent.ResourceStatuses{
+						{
+							Identifier: rsID,
+							Status:     status.UnknownStatus,
+							Error:      errors.New("generated failed"),
+						},
+					},
+				},
+				Resource: depObj,
+			},
+		},
+		{
+			Type: event.PruneType,
+			PruneEvent: event.PruneEvent{
+				GroupName:  "prune-0",
+				Identifier: depID,
+				Status:     event.PruneSkipped,
+				Object:     depObj,
+				Error:      errors.New("prune skipped"),
+			},
+		},
+		{
+			Type: event.DeleteType,
+			DeleteEvent: event.DeleteEvent{
+				GroupName:  "delete-0",
+				Identifier: depID,
+				Status:     event.DeleteSuccessful,
+			},
+		},
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  "wait-0",
+				Identifier: depID,
+				Status:     event.ReconcileTimeout,
+			},
+		},
+		{
+			Type: event.ValidationType,
+			ValidationEvent: event.ValidationEvent{
+				Identifiers: object.ObjMetadataSet{depID},
+				Error:       validation.NewError(errors.New("invalid spec"), depID),
+			},
+		},
+		{
+			Type: event.DiffType,
+			DiffEvent: event.DiffEvent{
+				GroupName:  "apply-0",
+				Identifier: depID,
+				Diffs: []diff.FieldDiff{
+					{Path: ".spec.replicas", Operation: diff.Changed, Old: float64(1), New: float64(2)},
+				},
+			},
+		},
+		{
+			Type:       event.AdoptType,
+			AdoptEvent: event.AdoptEvent{Identifier: depID, Error: errors.New("owned by another inventory")},
+		},
+		{
+			Type: event.TraceType,
+			TraceEvent: event.TraceEvent{
+				GroupName:  "apply-1",
+				Identifier: depID,
+				After: []event.TraceDependency{
+					{Identifier: rsID, Reasons: []string{"depends-on"}},
+				},
+			},
+		},
+		{
+			Type: event.ConflictType,
+			ConflictEvent: event.ConflictEvent{
+				GroupName:  "apply-0",
+				Identifier: depID,
+				Conflicts:  []event.FieldConflict{{Manager: "kubectl", Field: ".spec.replicas"}},
+			},
+		},
+		{
+			Type: event.RollbackType,
+			RollbackEvent: event.RollbackEvent{
+				Identifier: depID,
+				Operation:  event.RollbackRevert,
+				Status:     event.RollbackFailed,
+				Error:      errors.New("rollback failed"),
+			},
+		},
+		{
+			Type: event.DriftType,
+			DriftEvent: event.DriftEvent{
+				Identifier: depID,
+				Status:     event.DriftModified,
+				Diffs: []diff.FieldDiff{
+					{Path: ".spec.replicas", Operation: diff.Changed, Old: float64(5), New: float64(1)},
+				},
+				Managers: []string{"kubectl-edit"},
+			},
+		},
+		{
+			Type: event.PlanSummaryType,
+			PlanSummaryEvent: event.PlanSummaryEvent{
+				Create:    object.ObjMetadataSet{depID},
+				Configure: object.ObjMetadataSet{},
+				Unchanged: object.ObjMetadataSet{},
+				Prune:     object.ObjMetadataSet{rsID},
+				Errors: map[object.ObjMetadata]error{
+					rsID: errors.New("dry-run failed"),
+				},
+			},
+		},
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	w := NewWriter(&buf, common.DryRunServer)
+	w.now = func() time.Time { return now }
+
+	events := allEvents()
+	for _, e := range events {
+		require.NoError(t, w.Write(e))
+	}
+	// Writing must not modify the events.
+	assert.Equal(t, allEvents(), events)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, len(events)+1)
+	assert.Equal(t, `{"version":1,"dryRunStrategy":"DryRunServer"}`, lines[0])
+
+	r, err := NewReader(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, common.DryRunServer, r.DryRunStrategy())
+
+	var entries []Entry
+	for {
+		entry, err := r.Next()
+		if err != nil {
+			require.Equal(t, "EOF", err.Error())
+			break
+		}
+		entries = append(entries, entry)
+	}
+	require.Len(t, entries, len(events))
+	for i, entry := range entries {
+		assert.True(t, now.Equal(entry.Time))
+		assert.Equal(t, events[i], entry.Event, "event %d", i)
+	}
+
+	vErr, ok := entries[8].Event.ValidationEvent.Error.(*validation.Error)
+	require.True(t, ok, "validation error type")
+	assert.Equal(t, object.ObjMetadataSet{depID}, vErr.Identifiers())
+}
+
+func TestReadAll_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		journal       string
+		expectedError string
+	}{
+		"empty journal": {
+			journal: "",
+		},
+		"invalid header": {
+			journal:       "foo\n",
+			expectedError: "failed to read journal: invalid header",
+		},
+		"unsupported version": {
+			journal:       `{"version":2,"dryRunStrategy":"DryRunNone"}`,
+			expectedError: "failed to read journal: unsupported version 2",
+		},
+		"invalid dry-run strategy": {
+			journal:       `{"version":1,"dryRunStrategy":"foo"}`,
+			expectedError: `failed to read journal: invalid dry-run strategy "foo"`,
+		},
+		"invalid event type": {
+			journal: `{"version":1,"dryRunStrategy":"DryRunNone"}
+{"time":"2022-10-01T12:00:00Z","type":"FooType","event":{}}`,
+			expectedError: `failed to read journal: invalid event type "FooType"`,
+		},
+		"invalid event": {
+			journal: `{"version":1,"dryRunStrategy":"DryRunNone"}
+{"time":"2022-10-01T12:00:00Z","type":"WaitType","event":{"GroupName":1}}`,
+			expectedError: "failed to read journal: invalid WaitType",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			entries, err := ReadAll(strings.NewReader(tc.journal))
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				assert.Empty(t, entries)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedError)
+		})
+	}
+}
+
+func TestTee(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, common.DryRunNone)
+
+	ch := make(chan event.Event)
+	go func() {
+		defer close(ch)
+		for _, e := range allEvents() {
+			ch <- e
+		}
+	}()
+	var forwarded []event.Event
+	for e := range w.Tee(ch) {
+		forwarded = append(forwarded, e)
+	}
+	require.NoError(t, w.Err())
+	assert.Equal(t, allEvents(), forwarded)
+
+	entries, err := ReadAll(&buf)
+	require.NoError(t, err)
+	require.Len(t, entries, len(forwarded))
+	for i, entry := range entries {
+		assert.Equal(t, forwarded[i], entry.Event)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestTee_WriteError(t *testing.T) {
+	w := NewWriter(failingWriter{}, common.DryRunNone)
+
+	ch := make(chan event.Event, 2)
+	ch <- event.Event{Type: event.AdoptType, AdoptEvent: event.AdoptEvent{Identifier: depID}}
+	ch <- event.Event{Type: event.AdoptType, AdoptEvent: event.AdoptEvent{Identifier: rsID}}
+	close(ch)
+
+	var forwarded int
+	for range w.Tee(ch) {
+		forwarded++
+	}
+	assert.Equal(t, 2, forwarded)
+	assert.EqualError(t, w.Err(), "failed to write journal: disk full")
+}
+
+type fakePrinter struct {
+	events          []event.Event
+	previewStrategy common.DryRunStrategy
+	printStatus     bool
+	err             error
+}
+
+func (p *fakePrinter) Print(ch <-chan event.Event, previewStrategy common.DryRunStrategy, printStatus bool) error {
+	p.previewStrategy = previewStrategy
+	p.printStatus = printStatus
+	for e := range ch {
+		p.events = append(p.events, e)
+		if p.err != nil {
+			return p.err
+		}
+	}
+	return nil
+}
+
+func TestReplay(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, common.DryRunClient)
+	for _, e := range allEvents() {
+		require.NoError(t, w.Write(e))
+	}
+
+	p := &fakePrinter{}
+	require.NoError(t, Replay(bytes.NewReader(buf.Bytes()), p, true))
+	assert.Equal(t, allEvents(), p.events)
+	assert.Equal(t, common.DryRunClient, p.previewStrategy)
+	assert.True(t, p.printStatus)
+
+	// Printer errors are returned after the journal is drained.
+	p = &fakePrinter{err: errors.New("print failed")}
+	assert.EqualError(t, Replay(bytes.NewReader(buf.Bytes()), p, false), "print failed")
+	assert.Len(t, p.events, 1)
+}
+
+func TestWrite_RedactsSecrets(t *testing.T) {
+	secret := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name":      "creds",
+				"namespace": "default",
+				"annotations": map[string]interface{}{
+					"kubectl.kubernetes.io/last-applied-configuration": `{"data":{"password":"c2VjcmV0"}}`,
+				},
+			},
+			"type":       "Opaque",
+			"data":       map[string]interface{}{"password": "c2VjcmV0"},
+			"stringData": map[string]interface{}{"token": "plaintext"},
+		},
+	}
+	secretID := object.UnstructuredToObjMetadata(secret)
+	events := []event.Event{
+		{
+			Type: event.ApplyType,
+			ApplyEvent: event.ApplyEvent{
+				GroupName:  "apply-0",
+				Identifier: secretID,
+				Status:     event.ApplySuccessful,
+				Resource:   secret,
+			},
+		},
+		{
+			Type: event.StatusType,
+			StatusEvent: event.StatusEvent{
+				Identifier: secretID,
+				PollResourceInfo: &pollevent.ResourceStatus{
+					Identifier: secretID,
+					Status:     status.CurrentStatus,
+					Resource:   secret,
+				},
+				Resource: secret,
+			},
+		},
+		{
+			Type: event.PruneType,
+			PruneEvent: event.PruneEvent{
+				GroupName:  "prune-0",
+				Identifier: secretID,
+				Status:     event.PruneSuccessful,
+				Object:     secret,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, common.DryRunNone)
+	for _, e := range events {
+		require.NoError(t, w.Write(e))
+	}
+	assert.NotContains(t, buf.String(), "c2VjcmV0")
+	assert.NotContains(t, buf.String(), "plaintext")
+	// The events are not modified.
+	assert.Equal(t, "c2VjcmV0", secret.Object["data"].(map[string]interface{})["password"])
+
+	entries, err := ReadAll(&buf)
+	require.NoError(t, err)
+	require.Len(t, entries, len(events))
+	redacted := entries[0].Event.ApplyEvent.Resource
+	assert.Equal(t, "creds", redacted.GetName())
+	assert.Equal(t, map[string]interface{}{"password": "***"}, redacted.Object["data"])
+	assert.Equal(t, map[string]interface{}{"token": "***"}, redacted.Object["stringData"])
+	assert.Equal(t, "***", redacted.GetAnnotations()["kubectl.kubernetes.io/last-applied-configuration"])
+	assert.Equal(t, redacted, entries[1].Event.StatusEvent.Resource)
+	assert.Equal(t, redacted, entries[1].Event.StatusEvent.PollResourceInfo.Resource)
+	assert.Equal(t, redacted, entries[2].Event.PruneEvent.Object)
+}
@@ -0,0 +1,130 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/printers/printer"
+)
+
+// Entry is an event read from a journal.
+type Entry struct {
+	// Time is when the event was written to the journal.
+	Time  time.Time
+	Event event.Event
+}
+
+// Reader reads events from a journal.
+type Reader struct {
+	dec            *json.Decoder
+	dryRunStrategy common.DryRunStrategy
+	// empty is true if the journal has no header and no events.
+	empty bool
+}
+
+// NewReader returns a Reader of the journal, after reading its header.
+func NewReader(r io.Reader) (*Reader, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var h header
+	if err := dec.Decode(&h); err != nil {
+		if err == io.EOF {
+			// A journal of a run without events.
+			return &Reader{dec: dec, empty: true}, nil
+		}
+		return nil, fmt.Errorf("failed to read journal: invalid header: %w", err)
+	}
+	if h.Version != Version {
+		return nil, fmt.Errorf("failed to read journal: unsupported version %d", h.Version)
+	}
+	strategy, found := parseDryRunStrategy(h.DryRunStrategy)
+	if !found {
+		return nil, fmt.Errorf("failed to read journal: invalid dry-run strategy %q", h.DryRunStrategy)
+	}
+	return &Reader{dec: dec, dryRunStrategy: strategy}, nil
+}
+
+// DryRunStrategy returns the dry-run strategy of the journaled run.
+func (r *Reader) DryRunStrategy() common.DryRunStrategy {
+	return r.dryRunStrategy
+}
+
+// Next returns the next event of the journal, or io.EOF at the end of the
+// journal.
+func (r *Reader) Next() (Entry, error) {
+	if r.empty {
+		return Entry{}, io.EOF
+	}
+	var rec record
+	if err := r.dec.Decode(&rec); err != nil {
+		if err == io.EOF {
+			return Entry{}, io.EOF
+		}
+		return Entry{}, fmt.Errorf("failed to read journal: %w", err)
+	}
+	e, err := rec.event()
+	if err != nil {
+		return Entry{}, err
+	}
+	return Entry{Time: rec.Time, Event: e}, nil
+}
+
+// ReadAll returns all the events of the journal.
+func ReadAll(r io.Reader) ([]Entry, error) {
+	jr, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for {
+		entry, err := jr.Next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// Replay prints the events of the journal with the printer, as they were
+// printed by the journaled run. Replay returns the first error reading the
+// journal or printing the events.
+func Replay(r io.Reader, p printer.Printer, printStatus bool) error {
+	jr, err := NewReader(r)
+	if err != nil {
+		return err
+	}
+	ch := make(chan event.Event)
+	var readErr error
+	go func() {
+		defer close(ch)
+		for {
+			entry, err := jr.Next()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					readErr = err
+				}
+				return
+			}
+			ch <- entry.Event
+		}
+	}()
+	printErr := p.Print(ch, jr.DryRunStrategy(), printStatus)
+	// Drain the channel if the printer stopped early.
+	for range ch {
+	}
+	if readErr != nil {
+		return readErr
+	}
+	return printErr
+}
@@ -5,7 +5,12 @@
 // format. Each event is printed as a json object, so the output will
 // appear as a stream of json objects, each representing a single event.
 //
+// The events are defined as Go structs in this package (for example
+// OperationEvent and StatusEvent), which can be used to decode the stream.
+//
 // Every event will contain the following properties:
+//   - schemaVersion: The version of the event schema, currently "v1". Fields
+//     may be added without changing the version.
 //   - timestamp: RFC3339-formatted timestamp describing when the event happened.
 //   - type: Describes the type of the operation which the event is related to.
 //     Type values include:
@@ -17,6 +22,13 @@
 //   - delete - DeleteEvent
 //   - wait - WaitEvent
 //   - status - StatusEvent
+//   - diff - DiffEvent
+//   - adopt - AdoptEvent
+//   - trace - TraceEvent
+//   - conflict - ConflictEvent
+//   - rollback - RollbackEvent
+//   - drift - DriftEvent
+//   - plan - PlanSummaryEvent
 //   - summary - aggregate stats collected by the printer
 //
 // Validation events correspond to zero or more objects. For these events, the
@@ -33,6 +45,8 @@
 // * timestamp (string) - ISO-8601 format
 // * type (string) - "validation"
 // * error (string) - a fatal error message specific to these objects
+// * reason (string, optional) - the reason code of the error, if known, e.g.
+// "DependencyCycle" (see package sigs.k8s.io/cli-utils/pkg/reason)
 //
 // Error events corespond to a fatal error received outside of a specific task
 // or operation.
@@ -41,6 +55,10 @@
 // * timestamp (string) - ISO-8601 format
 // * type (string) - "error"
 // * error (string)  - a fatal error message
+// * reason (string, optional) - the reason code of the error, if known
+// * retryable (boolean, optional) - true if retrying may succeed
+// * object (object, optional) - the group, kind, name and namespace of the
+// object the error is about, if known
 //
 // Group events correspond to a group of events of the same type: apply, prune,
 // delete, or wait.
@@ -60,11 +78,18 @@
 //   - kind (string) - The object's kind.
 //   - name (string) - The object's name.
 //   - namespace (string, optional) - The object's namespace.
-//   - status (string) - One of: "Pending", "Successful", "Skipped", "Failed", or
-//     "Timeout".
+//   - status (string) - One of: "Pending", "Successful", "Skipped", "Failed",
+//     "Detached", or "Timeout".
 //   - timestamp (string) - ISO-8601 format
 //   - type (string) - "apply", "prune", "delete", or "wait"
 //   - error (string, optional) - A non-fatal error message specific to this object
+//   - reason (string, optional) - The reason code of the error, if known, e.g. "Conflict"
+//     or "DependencyNotReady" (see package sigs.k8s.io/cli-utils/pkg/reason).
+//   - retryable (boolean, optional) - True if retrying may succeed.
+//
+// Apply events also have the following fields:
+//   - strategy (string, optional) - How the object was applied, if it was
+//     applied. One of: "ServerSide" or "ClientSide".
 //
 // Status types are asynchronous events that correspond to status updates for
 // a specific object.
@@ -92,6 +117,98 @@
 // * skipped (number) - Number of objects for which the action was skipped.
 // * failed (number) - Number of objects for which the action failed.
 // * timeout (number, optional) - Number of objects for which the action timed out.
+// * detached (number, optional) - Number of objects detached from the inventory.
 // * timestamp (string) - ISO-8601 format
 // * type (string) - "summary"
+//
+// Diff events report the changes an apply will make to a single object,
+// computed with a server-side dry-run apply. They are only printed if diff
+// events are enabled.
+//
+// Diff events have the following fields:
+// * group, kind, name, namespace - The object identifier.
+// * diffs (array of objects) - The changed fields, sorted by path.
+//   - path (string) - The field path, e.g. ".spec.replicas".
+//   - operation (string) - One of: "Added", "Removed", or "Changed".
+//   - old (any, optional) - The live value of the field.
+//   - new (any, optional) - The value of the field after the apply.
+//
+// * timestamp (string) - ISO-8601 format
+// * type (string) - "diff"
+// * error (string, optional) - An error message if the diff failed.
+//
+// Adopt events report that an object, which existed without an owning
+// inventory, was claimed by the inventory before being applied, or that
+// an object could not be claimed because it is owned by another inventory.
+//
+// Adopt events have the following fields:
+// * group, kind, name, namespace - The object identifier.
+// * timestamp (string) - ISO-8601 format
+// * type (string) - "adopt"
+// * error (string, optional) - An error message if the object was not adopted.
+//
+// Trace events explain why an object was assigned to its task group. They
+// are only printed if trace events are enabled.
+//
+// Trace events have the following fields:
+// * group, kind, name, namespace - The object identifier.
+// * taskGroup (string) - The name of the task group, e.g. "apply-1".
+// * after (array of objects) - The objects this object is ordered after.
+//   - group, kind, name, namespace - The object identifier.
+//   - reasons (array of strings) - e.g. "depends-on", "apply-time-mutation",
+//     "namespace" or "crd".
+//
+// * timestamp (string) - ISO-8601 format
+// * type (string) - "trace"
+//
+// Conflict events are printed before the apply event of an object that
+// failed to be applied, because fields of the object are managed by other
+// field managers.
+//
+// Conflict events have the following fields:
+// * group, kind, name, namespace - The object identifier.
+// * conflicts (array of objects) - The conflicting fields.
+//   - manager (string) - The field manager that owns the field.
+//   - field (string) - The path of the field, e.g. ".spec.replicas".
+//
+// * timestamp (string) - ISO-8601 format
+// * type (string) - "conflict"
+//
+// Rollback events are printed for each object applied by a failed apply,
+// when rollback is enabled.
+//
+// Rollback events have the same fields as operation events, with the
+// following additional field:
+// * operation (string) - One of: "Delete" or "Revert".
+//
+// The status is one of: "Successful" or "Failed". The type is "rollback".
+//
+// Drift events report whether a live object has drifted from the state it
+// would have after being applied. They are printed by drift detection, not
+// by apply or destroy.
+//
+// Drift events have the following fields:
+//   - group, kind, name, namespace - The object identifier.
+//   - status (string) - One of: "InSync", "Modified", "Deleted", "NotApplied",
+//     or "Failed".
+//   - diffs (array of objects, optional) - The fields that applying the object
+//     would change, with the same fields as the diffs of diff events.
+//   - managers (array of strings, optional) - The field managers that manage
+//     any of the changed fields.
+//   - timestamp (string) - ISO-8601 format
+//   - type (string) - "drift"
+//   - error (string, optional) - An error message if the detection failed.
+//
+// Plan summary events report the changes a run would make, computed with a
+// server-side dry-run apply before anything is actuated. They are only
+// printed if enabled with ApplierOptions.EmitPlanSummary.
+//
+// Plan summary events have the following fields:
+//   - create, configure, unchanged, prune (arrays of objects) - The
+//     identifiers of the objects to create, to change, that would not
+//     change, and to prune.
+//   - errors (array of objects, optional) - The objects whose dry-run failed,
+//     with the fields of an object identifier and an error (string).
+//   - timestamp (string) - ISO-8601 format
+//   - type (string) - "plan"
 package json
@@ -6,22 +6,32 @@ package json
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/diff"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/cli-utils/pkg/object/validation"
 	"sigs.k8s.io/cli-utils/pkg/print/list"
 	"sigs.k8s.io/cli-utils/pkg/print/stats"
+	"sigs.k8s.io/cli-utils/pkg/reason"
 )
 
 func NewFormatter(ioStreams genericclioptions.IOStreams,
-	_ common.DryRunStrategy) list.Formatter {
+	previewStrategy common.DryRunStrategy) list.Formatter {
+	return NewFormatterWithClock(ioStreams, previewStrategy, time.Now)
+}
+
+// NewFormatterWithClock returns a formatter that reads the timestamps of the
+// events from the passed clock, e.g. a fixed time for snapshot tests.
+func NewFormatterWithClock(ioStreams genericclioptions.IOStreams,
+	_ common.DryRunStrategy, now func() time.Time) list.Formatter {
 	return &formatter{
 		ioStreams: ioStreams,
-		now:       time.Now,
+		now:       now,
 	}
 }
 
@@ -31,6 +41,7 @@ type formatter struct {
 }
 
 func (jf *formatter) FormatValidationEvent(ve event.ValidationEvent) error {
+	reasonCode := errorReason(ve.Error)
 	// unwrap validation errors
 	err := ve.Error
 	if vErr, ok := err.(*validation.Error); ok {
@@ -40,23 +51,26 @@ func (jf *formatter) FormatValidationEvent(ve event.ValidationEvent) error {
 		// no objects, invalid event
 		return fmt.Errorf("invalid validation event: no identifiers: %w", err)
 	}
-	objects := make([]interface{}, len(ve.Identifiers))
+	objects := make([]ObjectIdentifier, len(ve.Identifiers))
 	for i, id := range ve.Identifiers {
-		objects[i] = jf.baseResourceEvent(id)
+		objects[i] = objectIdentifier(id)
 	}
-	return jf.printEvent("validation", map[string]interface{}{
-		"objects": objects,
-		"error":   err.Error(),
+	return jf.printEvent(ValidationEvent{
+		EventHeader: jf.header(ValidationType),
+		Objects:     objects,
+		Error:       err.Error(),
+		Reason:      reasonCode,
 	})
 }
 
 func (jf *formatter) FormatApplyEvent(e event.ApplyEvent) error {
-	eventInfo := jf.baseResourceEvent(e.Identifier)
-	if e.Error != nil {
-		eventInfo["error"] = e.Error.Error()
+	ae := ApplyEvent{
+		OperationEvent: jf.operationEvent(ApplyType, e.Identifier, e.Status.String(), e.Error),
 	}
-	eventInfo["status"] = e.Status.String()
-	return jf.printEvent("apply", eventInfo)
+	if e.Strategy != event.ApplyStrategyUnknown {
+		ae.Strategy = e.Strategy.String()
+	}
+	return jf.printEvent(ae)
 }
 
 func (jf *formatter) FormatStatusEvent(se event.StatusEvent) error {
@@ -64,40 +78,137 @@ func (jf *formatter) FormatStatusEvent(se event.StatusEvent) error {
 }
 
 func (jf *formatter) printResourceStatus(se event.StatusEvent) error {
-	eventInfo := jf.baseResourceEvent(se.Identifier)
-	eventInfo["status"] = se.PollResourceInfo.Status.String()
-	eventInfo["message"] = se.PollResourceInfo.Message
-	return jf.printEvent("status", eventInfo)
+	return jf.printEvent(StatusEvent{
+		EventHeader:      jf.header(StatusType),
+		ObjectIdentifier: objectIdentifier(se.Identifier),
+		Status:           se.PollResourceInfo.Status.String(),
+		Message:          se.PollResourceInfo.Message,
+	})
 }
 
 func (jf *formatter) FormatPruneEvent(e event.PruneEvent) error {
-	eventInfo := jf.baseResourceEvent(e.Identifier)
+	return jf.printEvent(jf.operationEvent(PruneType, e.Identifier, e.Status.String(), e.Error))
+}
+
+func (jf *formatter) FormatDeleteEvent(e event.DeleteEvent) error {
+	return jf.printEvent(jf.operationEvent(DeleteType, e.Identifier, e.Status.String(), e.Error))
+}
+
+func (jf *formatter) FormatWaitEvent(e event.WaitEvent) error {
+	return jf.printEvent(jf.operationEvent(WaitType, e.Identifier, e.Status.String(), nil))
+}
+
+func (jf *formatter) FormatDiffEvent(e event.DiffEvent) error {
+	de := DiffEvent{
+		EventHeader:      jf.header(DiffType),
+		ObjectIdentifier: objectIdentifier(e.Identifier),
+		Diffs:            fieldDiffs(e.Diffs),
+	}
 	if e.Error != nil {
-		eventInfo["error"] = e.Error.Error()
+		de.Error = e.Error.Error()
 	}
-	eventInfo["status"] = e.Status.String()
-	return jf.printEvent("prune", eventInfo)
+	return jf.printEvent(de)
 }
 
-func (jf *formatter) FormatDeleteEvent(e event.DeleteEvent) error {
-	eventInfo := jf.baseResourceEvent(e.Identifier)
+func (jf *formatter) FormatAdoptEvent(e event.AdoptEvent) error {
+	ae := AdoptEvent{
+		EventHeader:      jf.header(AdoptType),
+		ObjectIdentifier: objectIdentifier(e.Identifier),
+	}
 	if e.Error != nil {
-		eventInfo["error"] = e.Error.Error()
+		ae.Error = e.Error.Error()
 	}
-	eventInfo["status"] = e.Status.String()
-	return jf.printEvent("delete", eventInfo)
+	return jf.printEvent(ae)
 }
 
-func (jf *formatter) FormatWaitEvent(e event.WaitEvent) error {
-	eventInfo := jf.baseResourceEvent(e.Identifier)
-	eventInfo["status"] = e.Status.String()
-	return jf.printEvent("wait", eventInfo)
+func (jf *formatter) FormatTraceEvent(e event.TraceEvent) error {
+	te := TraceEvent{
+		EventHeader:      jf.header(TraceType),
+		ObjectIdentifier: objectIdentifier(e.Identifier),
+		TaskGroup:        e.GroupName,
+		After:            make([]TraceDependency, len(e.After)),
+	}
+	for i, dep := range e.After {
+		te.After[i] = TraceDependency{
+			ObjectIdentifier: objectIdentifier(dep.Identifier),
+			Reasons:          dep.Reasons,
+		}
+	}
+	return jf.printEvent(te)
 }
 
-func (jf *formatter) FormatErrorEvent(e event.ErrorEvent) error {
-	return jf.printEvent("error", map[string]interface{}{
-		"error": e.Err.Error(),
+func (jf *formatter) FormatConflictEvent(e event.ConflictEvent) error {
+	ce := ConflictEvent{
+		EventHeader:      jf.header(ConflictType),
+		ObjectIdentifier: objectIdentifier(e.Identifier),
+		Conflicts:        make([]FieldConflict, len(e.Conflicts)),
+	}
+	for i, c := range e.Conflicts {
+		ce.Conflicts[i] = FieldConflict{
+			Manager: c.Manager,
+			Field:   c.Field,
+		}
+	}
+	return jf.printEvent(ce)
+}
+
+func (jf *formatter) FormatRollbackEvent(e event.RollbackEvent) error {
+	return jf.printEvent(RollbackEvent{
+		OperationEvent: jf.operationEvent(RollbackType, e.Identifier, e.Status.String(), e.Error),
+		Operation:      e.Operation.String(),
+	})
+}
+
+func (jf *formatter) FormatDriftEvent(e event.DriftEvent) error {
+	de := DriftEvent{
+		EventHeader:      jf.header(DriftType),
+		ObjectIdentifier: objectIdentifier(e.Identifier),
+		Status:           e.Status.String(),
+		Diffs:            fieldDiffs(e.Diffs),
+		Managers:         e.Managers,
+	}
+	if e.Error != nil {
+		de.Error = e.Error.Error()
+	}
+	return jf.printEvent(de)
+}
+
+func (jf *formatter) FormatPlanSummaryEvent(e event.PlanSummaryEvent) error {
+	pe := PlanSummaryEvent{
+		EventHeader: jf.header(PlanType),
+		Create:      objectIdentifiers(e.Create),
+		Configure:   objectIdentifiers(e.Configure),
+		Unchanged:   objectIdentifiers(e.Unchanged),
+		Prune:       objectIdentifiers(e.Prune),
+	}
+	ids := make(object.ObjMetadataSet, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
 	})
+	for _, id := range ids {
+		pe.Errors = append(pe.Errors, ObjectError{
+			ObjectIdentifier: objectIdentifier(id),
+			Error:            e.Errors[id].Error(),
+		})
+	}
+	return jf.printEvent(pe)
+}
+
+func (jf *formatter) FormatErrorEvent(e event.ErrorEvent) error {
+	ee := ErrorEvent{
+		EventHeader: jf.header(ErrorType),
+		Error:       e.Err.Error(),
+		Reason:      errorReason(e.Err),
+		Retryable:   reason.IsRetryable(e.Err),
+	}
+	if id, found := reason.ObjectOf(e.Err); found {
+		oi := objectIdentifier(id)
+		ee.Object = &oi
+	}
+	return jf.printEvent(ee)
 }
 
 func (jf *formatter) FormatActionGroupEvent(
@@ -106,44 +217,28 @@ func (jf *formatter) FormatActionGroupEvent(
 	s stats.Stats,
 	_ list.Collector,
 ) error {
-	content := map[string]interface{}{
-		"action": age.Action.String(),
-		"status": age.Status.String(),
+	ge := GroupEvent{
+		EventHeader: jf.header(GroupType),
+		Action:      age.Action.String(),
+		Status:      age.Status.String(),
 	}
 
 	switch age.Action {
 	case event.ApplyAction:
 		if age.Status == event.Finished {
-			as := s.ApplyStats
-			content["count"] = as.Sum()
-			content["successful"] = as.Successful
-			content["skipped"] = as.Skipped
-			content["failed"] = as.Failed
+			ge.ActionStats = applyStats(s.ApplyStats)
 		}
 	case event.PruneAction:
 		if age.Status == event.Finished {
-			ps := s.PruneStats
-			content["count"] = ps.Sum()
-			content["successful"] = ps.Successful
-			content["skipped"] = ps.Skipped
-			content["failed"] = ps.Failed
+			ge.ActionStats = pruneStats(s.PruneStats)
 		}
 	case event.DeleteAction:
 		if age.Status == event.Finished {
-			ds := s.DeleteStats
-			content["count"] = ds.Sum()
-			content["successful"] = ds.Successful
-			content["skipped"] = ds.Skipped
-			content["failed"] = ds.Failed
+			ge.ActionStats = deleteStats(s.DeleteStats)
 		}
 	case event.WaitAction:
 		if age.Status == event.Finished {
-			ws := s.WaitStats
-			content["count"] = ws.Sum()
-			content["successful"] = ws.Successful
-			content["skipped"] = ws.Skipped
-			content["failed"] = ws.Failed
-			content["timeout"] = ws.Timeout
+			ge.ActionStats = waitStats(s.WaitStats)
 		}
 	case event.InventoryAction:
 		// no extra content
@@ -151,59 +246,30 @@ func (jf *formatter) FormatActionGroupEvent(
 		return fmt.Errorf("invalid action group action: %+v", age)
 	}
 
-	return jf.printEvent("group", content)
+	return jf.printEvent(ge)
 }
 
 func (jf *formatter) FormatSummary(s stats.Stats) error {
 	if s.ApplyStats != (stats.ApplyStats{}) {
-		as := s.ApplyStats
-		err := jf.printEvent("summary", map[string]interface{}{
-			"action":     event.ApplyAction.String(),
-			"count":      as.Sum(),
-			"successful": as.Successful,
-			"skipped":    as.Skipped,
-			"failed":     as.Failed,
-		})
+		err := jf.printSummary(event.ApplyAction, applyStats(s.ApplyStats))
 		if err != nil {
 			return err
 		}
 	}
 	if s.PruneStats != (stats.PruneStats{}) {
-		ps := s.PruneStats
-		err := jf.printEvent("summary", map[string]interface{}{
-			"action":     event.PruneAction.String(),
-			"count":      ps.Sum(),
-			"successful": ps.Successful,
-			"skipped":    ps.Skipped,
-			"failed":     ps.Failed,
-		})
+		err := jf.printSummary(event.PruneAction, pruneStats(s.PruneStats))
 		if err != nil {
 			return err
 		}
 	}
 	if s.DeleteStats != (stats.DeleteStats{}) {
-		ds := s.DeleteStats
-		err := jf.printEvent("summary", map[string]interface{}{
-			"action":     event.DeleteAction.String(),
-			"count":      ds.Sum(),
-			"successful": ds.Successful,
-			"skipped":    ds.Skipped,
-			"failed":     ds.Failed,
-		})
+		err := jf.printSummary(event.DeleteAction, deleteStats(s.DeleteStats))
 		if err != nil {
 			return err
 		}
 	}
 	if s.WaitStats != (stats.WaitStats{}) {
-		ws := s.WaitStats
-		err := jf.printEvent("summary", map[string]interface{}{
-			"action":     event.WaitAction.String(),
-			"count":      ws.Sum(),
-			"successful": ws.Successful,
-			"skipped":    ws.Skipped,
-			"failed":     ws.Failed,
-			"timeout":    ws.Timeout,
-		})
+		err := jf.printSummary(event.WaitAction, waitStats(s.WaitStats))
 		if err != nil {
 			return err
 		}
@@ -211,26 +277,132 @@ func (jf *formatter) FormatSummary(s stats.Stats) error {
 	return nil
 }
 
-func (jf *formatter) baseResourceEvent(identifier object.ObjMetadata) map[string]interface{} {
-	return map[string]interface{}{
-		"group":     identifier.GroupKind.Group,
-		"kind":      identifier.GroupKind.Kind,
-		"namespace": identifier.Namespace,
-		"name":      identifier.Name,
+func (jf *formatter) printSummary(action event.ResourceAction, as *ActionStats) error {
+	return jf.printEvent(SummaryEvent{
+		EventHeader: jf.header(SummaryType),
+		Action:      action.String(),
+		ActionStats: *as,
+	})
+}
+
+func applyStats(as stats.ApplyStats) *ActionStats {
+	return &ActionStats{
+		Count:      as.Sum(),
+		Successful: as.Successful,
+		Skipped:    as.Skipped,
+		Failed:     as.Failed,
+	}
+}
+
+func pruneStats(ps stats.PruneStats) *ActionStats {
+	as := &ActionStats{
+		Count:      ps.Sum(),
+		Successful: ps.Successful,
+		Skipped:    ps.Skipped,
+		Failed:     ps.Failed,
+	}
+	if ps.Detached > 0 {
+		detached := ps.Detached
+		as.Detached = &detached
+	}
+	return as
+}
+
+func deleteStats(ds stats.DeleteStats) *ActionStats {
+	as := &ActionStats{
+		Count:      ds.Sum(),
+		Successful: ds.Successful,
+		Skipped:    ds.Skipped,
+		Failed:     ds.Failed,
+	}
+	if ds.Detached > 0 {
+		detached := ds.Detached
+		as.Detached = &detached
+	}
+	return as
+}
+
+func waitStats(ws stats.WaitStats) *ActionStats {
+	timeout := ws.Timeout
+	return &ActionStats{
+		Count:      ws.Sum(),
+		Successful: ws.Successful,
+		Skipped:    ws.Skipped,
+		Failed:     ws.Failed,
+		Timeout:    &timeout,
 	}
 }
 
-func (jf *formatter) printEvent(t string, content map[string]interface{}) error {
-	m := make(map[string]interface{})
-	m["timestamp"] = jf.now().UTC().Format(time.RFC3339)
-	m["type"] = t
-	for key, val := range content {
-		m[key] = val
+func (jf *formatter) operationEvent(t string, identifier object.ObjMetadata, status string, err error) OperationEvent {
+	oe := OperationEvent{
+		EventHeader:      jf.header(t),
+		ObjectIdentifier: objectIdentifier(identifier),
+		Status:           status,
 	}
-	b, err := json.Marshal(m)
+	if err != nil {
+		oe.Error = err.Error()
+		oe.Reason = errorReason(err)
+		oe.Retryable = reason.IsRetryable(err)
+	}
+	return oe
+}
+
+// errorReason returns the reason code of the error, or an empty string if
+// the error is not classified.
+func errorReason(err error) string {
+	r := reason.Of(err)
+	if r == reason.Unknown {
+		return ""
+	}
+	return string(r)
+}
+
+func objectIdentifier(identifier object.ObjMetadata) ObjectIdentifier {
+	return ObjectIdentifier{
+		Group:     identifier.GroupKind.Group,
+		Kind:      identifier.GroupKind.Kind,
+		Namespace: identifier.Namespace,
+		Name:      identifier.Name,
+	}
+}
+
+// objectIdentifiers converts the identifiers, never returning nil, so empty
+// sets are printed as empty arrays.
+func objectIdentifiers(ids object.ObjMetadataSet) []ObjectIdentifier {
+	out := make([]ObjectIdentifier, len(ids))
+	for i, id := range ids {
+		out[i] = objectIdentifier(id)
+	}
+	return out
+}
+
+func (jf *formatter) header(t string) EventHeader {
+	return EventHeader{
+		SchemaVersion: SchemaVersion,
+		Timestamp:     jf.now().UTC().Format(time.RFC3339),
+		Type:          t,
+	}
+}
+
+func (jf *formatter) printEvent(e interface{}) error {
+	b, err := json.Marshal(e)
 	if err != nil {
 		return err
 	}
 	_, err = fmt.Fprint(jf.ioStreams.Out, string(b)+"\n")
 	return err
 }
+
+// fieldDiffs returns the diffs of the fields in the schema of the events.
+func fieldDiffs(diffs []diff.FieldDiff) []FieldDiff {
+	fds := make([]FieldDiff, len(diffs))
+	for i, d := range diffs {
+		fds[i] = FieldDiff{
+			Path:      d.Path,
+			Operation: string(d.Operation),
+			Old:       d.Old,
+			New:       d.New,
+		}
+	}
+	return fds
+}
@@ -4,6 +4,7 @@
 package json
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"strings"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -24,6 +26,7 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/object/validation"
 	"sigs.k8s.io/cli-utils/pkg/print/list"
 	"sigs.k8s.io/cli-utils/pkg/print/stats"
+	"sigs.k8s.io/cli-utils/pkg/reason"
 	"sigs.k8s.io/cli-utils/pkg/testutil"
 )
 
@@ -41,13 +44,35 @@ func TestFormatter_FormatApplyEvent(t *testing.T) {
 			},
 			expected: []map[string]interface{}{
 				{
-					"group":     "apps",
-					"kind":      "Deployment",
-					"name":      "my-dep",
-					"namespace": "default",
-					"status":    "Successful",
-					"timestamp": "",
-					"type":      "apply",
+					"group":         "apps",
+					"kind":          "Deployment",
+					"name":          "my-dep",
+					"namespace":     "default",
+					"status":        "Successful",
+					"schemaVersion": "v1",
+					"timestamp":     "",
+					"type":          "apply",
+				},
+			},
+		},
+		"resource applied with server-side apply": {
+			previewStrategy: common.DryRunNone,
+			event: event.ApplyEvent{
+				Status:     event.ApplySuccessful,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Strategy:   event.ApplyStrategyServerSide,
+			},
+			expected: []map[string]interface{}{
+				{
+					"group":         "apps",
+					"kind":          "Deployment",
+					"name":          "my-dep",
+					"namespace":     "default",
+					"status":        "Successful",
+					"strategy":      "ServerSide",
+					"schemaVersion": "v1",
+					"timestamp":     "",
+					"type":          "apply",
 				},
 			},
 		},
@@ -59,13 +84,14 @@ func TestFormatter_FormatApplyEvent(t *testing.T) {
 			},
 			expected: []map[string]interface{}{
 				{
-					"group":     "apps",
-					"kind":      "Deployment",
-					"name":      "my-dep",
-					"namespace": "",
-					"status":    "Successful",
-					"timestamp": "",
-					"type":      "apply",
+					"group":         "apps",
+					"kind":          "Deployment",
+					"name":          "my-dep",
+					"namespace":     "",
+					"status":        "Successful",
+					"schemaVersion": "v1",
+					"timestamp":     "",
+					"type":          "apply",
 				},
 			},
 		},
@@ -77,13 +103,14 @@ func TestFormatter_FormatApplyEvent(t *testing.T) {
 			},
 			expected: []map[string]interface{}{
 				{
-					"group":     "batch",
-					"kind":      "CronJob",
-					"name":      "my-cron",
-					"namespace": "foo",
-					"status":    "Successful",
-					"timestamp": "",
-					"type":      "apply",
+					"group":         "batch",
+					"kind":          "CronJob",
+					"name":          "my-cron",
+					"namespace":     "foo",
+					"status":        "Successful",
+					"schemaVersion": "v1",
+					"timestamp":     "",
+					"type":          "apply",
 				},
 			},
 		},
@@ -96,14 +123,39 @@ func TestFormatter_FormatApplyEvent(t *testing.T) {
 			},
 			expected: []map[string]interface{}{
 				{
-					"group":     "apps",
-					"kind":      "Deployment",
-					"name":      "my-dep",
-					"namespace": "",
-					"status":    "Failed",
-					"timestamp": "",
-					"type":      "apply",
-					"error":     "example error",
+					"group":         "apps",
+					"kind":          "Deployment",
+					"name":          "my-dep",
+					"namespace":     "",
+					"status":        "Failed",
+					"schemaVersion": "v1",
+					"timestamp":     "",
+					"type":          "apply",
+					"error":         "example error",
+				},
+			},
+		},
+		"resource apply failed with classified error": {
+			previewStrategy: common.DryRunNone,
+			event: event.ApplyEvent{
+				Status:     event.ApplyFailed,
+				Identifier: createIdentifier("apps", "Deployment", "", "my-dep"),
+				Error: apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"},
+					"my-dep", errors.New("example error")),
+			},
+			expected: []map[string]interface{}{
+				{
+					"group":         "apps",
+					"kind":          "Deployment",
+					"name":          "my-dep",
+					"namespace":     "",
+					"status":        "Failed",
+					"schemaVersion": "v1",
+					"timestamp":     "",
+					"type":          "apply",
+					"error":         `Operation cannot be fulfilled on deployments.apps "my-dep": example error`,
+					"reason":        "Conflict",
+					"retryable":     true,
 				},
 			},
 		},
@@ -116,14 +168,15 @@ func TestFormatter_FormatApplyEvent(t *testing.T) {
 			},
 			expected: []map[string]interface{}{
 				{
-					"group":     "apps",
-					"kind":      "Deployment",
-					"name":      "my-dep",
-					"namespace": "",
-					"status":    "Skipped",
-					"timestamp": "",
-					"type":      "apply",
-					"error":     "example error",
+					"group":         "apps",
+					"kind":          "Deployment",
+					"name":          "my-dep",
+					"namespace":     "",
+					"status":        "Skipped",
+					"schemaVersion": "v1",
+					"timestamp":     "",
+					"type":          "apply",
+					"error":         "example error",
 				},
 			},
 		},
@@ -179,14 +232,15 @@ func TestFormatter_FormatStatusEvent(t *testing.T) {
 				},
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"message":   "Resource is Current",
-				"name":      "bar",
-				"namespace": "foo",
-				"status":    "Current",
-				"timestamp": "",
-				"type":      "status",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"message":       "Resource is Current",
+				"name":          "bar",
+				"namespace":     "foo",
+				"status":        "Current",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "status",
 			},
 		},
 	}
@@ -216,13 +270,14 @@ func TestFormatter_FormatPruneEvent(t *testing.T) {
 				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"name":      "my-dep",
-				"namespace": "default",
-				"status":    "Successful",
-				"timestamp": "",
-				"type":      "prune",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"name":          "my-dep",
+				"namespace":     "default",
+				"status":        "Successful",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "prune",
 			},
 		},
 		"resource skipped with client dryrun": {
@@ -232,13 +287,14 @@ func TestFormatter_FormatPruneEvent(t *testing.T) {
 				Identifier: createIdentifier("apps", "Deployment", "", "my-dep"),
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"name":      "my-dep",
-				"namespace": "",
-				"status":    "Skipped",
-				"timestamp": "",
-				"type":      "prune",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"name":          "my-dep",
+				"namespace":     "",
+				"status":        "Skipped",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "prune",
 			},
 		},
 		"resource prune failed": {
@@ -249,14 +305,15 @@ func TestFormatter_FormatPruneEvent(t *testing.T) {
 				Error:      errors.New("example error"),
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"name":      "my-dep",
-				"namespace": "",
-				"status":    "Failed",
-				"timestamp": "",
-				"type":      "prune",
-				"error":     "example error",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"name":          "my-dep",
+				"namespace":     "",
+				"status":        "Failed",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "prune",
+				"error":         "example error",
 			},
 		},
 		"resource prune skip error": {
@@ -267,14 +324,15 @@ func TestFormatter_FormatPruneEvent(t *testing.T) {
 				Error:      errors.New("example error"),
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"name":      "my-dep",
-				"namespace": "",
-				"status":    "Skipped",
-				"timestamp": "",
-				"type":      "prune",
-				"error":     "example error",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"name":          "my-dep",
+				"namespace":     "",
+				"status":        "Skipped",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "prune",
+				"error":         "example error",
 			},
 		},
 	}
@@ -305,13 +363,14 @@ func TestFormatter_FormatDeleteEvent(t *testing.T) {
 				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"name":      "my-dep",
-				"namespace": "default",
-				"status":    "Successful",
-				"timestamp": "",
-				"type":      "delete",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"name":          "my-dep",
+				"namespace":     "default",
+				"status":        "Successful",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "delete",
 			},
 		},
 		"resource skipped with client dryrun": {
@@ -321,13 +380,14 @@ func TestFormatter_FormatDeleteEvent(t *testing.T) {
 				Identifier: createIdentifier("apps", "Deployment", "", "my-dep"),
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"name":      "my-dep",
-				"namespace": "",
-				"status":    "Skipped",
-				"timestamp": "",
-				"type":      "delete",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"name":          "my-dep",
+				"namespace":     "",
+				"status":        "Skipped",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "delete",
 			},
 		},
 		"resource delete failed": {
@@ -338,14 +398,15 @@ func TestFormatter_FormatDeleteEvent(t *testing.T) {
 				Error:      errors.New("example error"),
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"name":      "my-dep",
-				"namespace": "default",
-				"status":    "Failed",
-				"timestamp": "",
-				"type":      "delete",
-				"error":     "example error",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"name":          "my-dep",
+				"namespace":     "default",
+				"status":        "Failed",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "delete",
+				"error":         "example error",
 			},
 		},
 		"resource delete skip error": {
@@ -356,14 +417,15 @@ func TestFormatter_FormatDeleteEvent(t *testing.T) {
 				Error:      errors.New("example error"),
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"name":      "my-dep",
-				"namespace": "default",
-				"status":    "Skipped",
-				"timestamp": "",
-				"type":      "delete",
-				"error":     "example error",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"name":          "my-dep",
+				"namespace":     "default",
+				"status":        "Skipped",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "delete",
+				"error":         "example error",
 			},
 		},
 	}
@@ -395,13 +457,14 @@ func TestFormatter_FormatWaitEvent(t *testing.T) {
 				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"name":      "my-dep",
-				"namespace": "default",
-				"status":    "Successful",
-				"timestamp": "",
-				"type":      "wait",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"name":          "my-dep",
+				"namespace":     "default",
+				"status":        "Successful",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "wait",
 			},
 		},
 		"resource reconciled (client-side dry-run)": {
@@ -412,13 +475,14 @@ func TestFormatter_FormatWaitEvent(t *testing.T) {
 				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"name":      "my-dep",
-				"namespace": "default",
-				"status":    "Successful",
-				"timestamp": "",
-				"type":      "wait",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"name":          "my-dep",
+				"namespace":     "default",
+				"status":        "Successful",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "wait",
 			},
 		},
 		"resource reconciled (server-side dry-run)": {
@@ -429,13 +493,14 @@ func TestFormatter_FormatWaitEvent(t *testing.T) {
 				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"name":      "my-dep",
-				"namespace": "default",
-				"status":    "Successful",
-				"timestamp": "",
-				"type":      "wait",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"name":          "my-dep",
+				"namespace":     "default",
+				"status":        "Successful",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "wait",
 			},
 		},
 		"resource reconcile pending": {
@@ -446,13 +511,14 @@ func TestFormatter_FormatWaitEvent(t *testing.T) {
 				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"name":      "my-dep",
-				"namespace": "default",
-				"status":    "Pending",
-				"timestamp": "",
-				"type":      "wait",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"name":          "my-dep",
+				"namespace":     "default",
+				"status":        "Pending",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "wait",
 			},
 		},
 		"resource reconcile skipped": {
@@ -463,13 +529,14 @@ func TestFormatter_FormatWaitEvent(t *testing.T) {
 				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"name":      "my-dep",
-				"namespace": "default",
-				"status":    "Skipped",
-				"timestamp": "",
-				"type":      "wait",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"name":          "my-dep",
+				"namespace":     "default",
+				"status":        "Skipped",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "wait",
 			},
 		},
 		"resource reconcile timeout": {
@@ -480,13 +547,14 @@ func TestFormatter_FormatWaitEvent(t *testing.T) {
 				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"name":      "my-dep",
-				"namespace": "default",
-				"status":    "Timeout",
-				"timestamp": "",
-				"type":      "wait",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"name":          "my-dep",
+				"namespace":     "default",
+				"status":        "Timeout",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "wait",
 			},
 		},
 		"resource reconcile failed": {
@@ -497,13 +565,14 @@ func TestFormatter_FormatWaitEvent(t *testing.T) {
 				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
 			},
 			expected: map[string]interface{}{
-				"group":     "apps",
-				"kind":      "Deployment",
-				"name":      "my-dep",
-				"namespace": "default",
-				"status":    "Failed",
-				"timestamp": "",
-				"type":      "wait",
+				"group":         "apps",
+				"kind":          "Deployment",
+				"name":          "my-dep",
+				"namespace":     "default",
+				"status":        "Failed",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"type":          "wait",
 			},
 		},
 	}
@@ -550,14 +619,15 @@ func TestFormatter_FormatActionGroupEvent(t *testing.T) {
 				ApplyStats: stats.ApplyStats{},
 			},
 			expected: map[string]interface{}{
-				"action":     "Apply",
-				"count":      0,
-				"failed":     0,
-				"skipped":    0,
-				"status":     "Finished",
-				"successful": 0,
-				"timestamp":  "2022-03-24T01:35:04Z",
-				"type":       "group",
+				"action":        "Apply",
+				"count":         0,
+				"failed":        0,
+				"skipped":       0,
+				"status":        "Finished",
+				"successful":    0,
+				"schemaVersion": "v1",
+				"timestamp":     "2022-03-24T01:35:04Z",
+				"type":          "group",
 			},
 		},
 		"the last apply action group finished": {
@@ -583,14 +653,15 @@ func TestFormatter_FormatActionGroupEvent(t *testing.T) {
 				},
 			},
 			expected: map[string]interface{}{
-				"action":     "Apply",
-				"count":      42,
-				"failed":     0,
-				"skipped":    0,
-				"status":     "Finished",
-				"successful": 42,
-				"timestamp":  "2022-03-24T01:35:04Z",
-				"type":       "group",
+				"action":        "Apply",
+				"count":         42,
+				"failed":        0,
+				"skipped":       0,
+				"status":        "Finished",
+				"successful":    42,
+				"schemaVersion": "v1",
+				"timestamp":     "2022-03-24T01:35:04Z",
+				"type":          "group",
 			},
 		},
 		"last prune action group started": {
@@ -611,10 +682,11 @@ func TestFormatter_FormatActionGroupEvent(t *testing.T) {
 				},
 			},
 			expected: map[string]interface{}{
-				"action":    "Prune",
-				"status":    "Started",
-				"timestamp": "2022-03-24T01:51:36Z",
-				"type":      "group",
+				"action":        "Prune",
+				"status":        "Started",
+				"schemaVersion": "v1",
+				"timestamp":     "2022-03-24T01:51:36Z",
+				"type":          "group",
 			},
 		},
 	}
@@ -672,8 +744,9 @@ func TestFormatter_FormatValidationEvent(t *testing.T) {
 				),
 			},
 			expected: map[string]interface{}{
-				"type":      "validation",
-				"timestamp": "",
+				"type":          "validation",
+				"schemaVersion": "v1",
+				"timestamp":     "",
 				"objects": []interface{}{
 					map[string]interface{}{
 						"group":     "apps",
@@ -682,7 +755,8 @@ func TestFormatter_FormatValidationEvent(t *testing.T) {
 						"namespace": "foo",
 					},
 				},
-				"error": "metadata.namespace: Required value: namespace is required",
+				"error":  "metadata.namespace: Required value: namespace is required",
+				"reason": "Invalid",
 			},
 		},
 		"two objects, cyclic dependency": {
@@ -766,8 +840,9 @@ func TestFormatter_FormatValidationEvent(t *testing.T) {
 				),
 			},
 			expected: map[string]interface{}{
-				"type":      "validation",
-				"timestamp": "",
+				"type":          "validation",
+				"schemaVersion": "v1",
+				"timestamp":     "",
 				"objects": []interface{}{
 					map[string]interface{}{
 						"group":     "apps",
@@ -785,6 +860,7 @@ func TestFormatter_FormatValidationEvent(t *testing.T) {
 				"error": `cyclic dependency:
 - apps/namespaces/default/Deployment/bar -> apps/namespaces/default/Deployment/foo
 - apps/namespaces/default/Deployment/foo -> apps/namespaces/default/Deployment/bar`,
+				"reason": "DependencyCycle",
 			},
 		},
 	}
@@ -804,6 +880,57 @@ func TestFormatter_FormatValidationEvent(t *testing.T) {
 	}
 }
 
+func TestFormatter_FormatErrorEvent(t *testing.T) {
+	testCases := map[string]struct {
+		event    event.ErrorEvent
+		expected map[string]interface{}
+	}{
+		"unclassified error": {
+			event: event.ErrorEvent{
+				Err: errors.New("unexpected"),
+			},
+			expected: map[string]interface{}{
+				"type":          "error",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"error":         "unexpected",
+			},
+		},
+		"error about an object": {
+			event: event.ErrorEvent{
+				Err: reason.ForObject(
+					createIdentifier("apps", "Deployment", "default", "my-dep"),
+					apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"}, "my-dep", errors.New("conflict")),
+				),
+			},
+			expected: map[string]interface{}{
+				"type":          "error",
+				"schemaVersion": "v1",
+				"timestamp":     "",
+				"error":         `Operation cannot be fulfilled on deployments.apps "my-dep": conflict`,
+				"reason":        "Conflict",
+				"retryable":     true,
+				"object": map[string]interface{}{
+					"group":     "apps",
+					"kind":      "Deployment",
+					"name":      "my-dep",
+					"namespace": "default",
+				},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			formatter := NewFormatter(ioStreams, common.DryRunNone)
+			err := formatter.FormatErrorEvent(tc.event)
+			assert.NoError(t, err)
+			assertOutput(t, tc.expected, out.String())
+		})
+	}
+}
+
 func TestFormatter_FormatSummary(t *testing.T) {
 	now := time.Now()
 	nowStr := now.UTC().Format(time.RFC3339)
@@ -833,32 +960,56 @@ func TestFormatter_FormatSummary(t *testing.T) {
 			},
 			expected: []map[string]interface{}{
 				{
-					"action":     "Apply",
-					"count":      float64(6),
-					"successful": float64(1),
-					"skipped":    float64(2),
-					"failed":     float64(3),
-					"timestamp":  nowStr,
-					"type":       "summary",
+					"action":        "Apply",
+					"count":         float64(6),
+					"successful":    float64(1),
+					"skipped":       float64(2),
+					"failed":        float64(3),
+					"schemaVersion": "v1",
+					"timestamp":     nowStr,
+					"type":          "summary",
 				},
 				{
-					"action":     "Prune",
-					"count":      float64(6),
-					"successful": float64(3),
-					"skipped":    float64(2),
-					"failed":     float64(1),
-					"timestamp":  nowStr,
-					"type":       "summary",
+					"action":        "Prune",
+					"count":         float64(6),
+					"successful":    float64(3),
+					"skipped":       float64(2),
+					"failed":        float64(1),
+					"schemaVersion": "v1",
+					"timestamp":     nowStr,
+					"type":          "summary",
+				},
+				{
+					"action":        "Wait",
+					"count":         float64(12),
+					"successful":    float64(4),
+					"skipped":       float64(6),
+					"failed":        float64(1),
+					"timeout":       float64(1),
+					"schemaVersion": "v1",
+					"timestamp":     nowStr,
+					"type":          "summary",
+				},
+			},
+		},
+		"delete with detached": {
+			statsCollector: stats.Stats{
+				DeleteStats: stats.DeleteStats{
+					Successful: 3,
+					Detached:   2,
 				},
+			},
+			expected: []map[string]interface{}{
 				{
-					"action":     "Wait",
-					"count":      float64(12),
-					"successful": float64(4),
-					"skipped":    float64(6),
-					"failed":     float64(1),
-					"timeout":    float64(1),
-					"timestamp":  nowStr,
-					"type":       "summary",
+					"action":        "Delete",
+					"count":         float64(5),
+					"successful":    float64(3),
+					"skipped":       float64(0),
+					"failed":        float64(0),
+					"detached":      float64(2),
+					"schemaVersion": "v1",
+					"timestamp":     nowStr,
+					"type":          "summary",
 				},
 			},
 		},
@@ -932,3 +1083,29 @@ func createIdentifier(group, kind, namespace, name string) object.ObjMetadata {
 		},
 	}
 }
+
+func TestFormatter_DecodeSchema(t *testing.T) {
+	out := &bytes.Buffer{}
+	formatter := NewFormatter(genericclioptions.IOStreams{Out: out}, common.DryRunNone)
+
+	err := formatter.FormatApplyEvent(event.ApplyEvent{
+		Status:     event.ApplyFailed,
+		Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+		Error:      errors.New("example error"),
+	})
+	require.NoError(t, err)
+
+	var oe OperationEvent
+	err = json.Unmarshal(out.Bytes(), &oe)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion, oe.SchemaVersion)
+	assert.Equal(t, ApplyType, oe.Type)
+	assert.Equal(t, ObjectIdentifier{
+		Group:     "apps",
+		Kind:      "Deployment",
+		Name:      "my-dep",
+		Namespace: "default",
+	}, oe.ObjectIdentifier)
+	assert.Equal(t, "Failed", oe.Status)
+	assert.Equal(t, "example error", oe.Error)
+}
@@ -0,0 +1,208 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package json
+
+// SchemaVersion is the version of the event schema printed by the json
+// printer. It is included in every event as the schemaVersion property.
+// Fields may be added to the events without changing the version, but
+// changing or removing fields requires a new version.
+const SchemaVersion = "v1"
+
+// Event types, printed as the type property of each event.
+const (
+	ValidationType = "validation"
+	ErrorType      = "error"
+	GroupType      = "group"
+	ApplyType      = "apply"
+	PruneType      = "prune"
+	DeleteType     = "delete"
+	WaitType       = "wait"
+	StatusType     = "status"
+	DiffType       = "diff"
+	AdoptType      = "adopt"
+	TraceType      = "trace"
+	ConflictType   = "conflict"
+	RollbackType   = "rollback"
+	DriftType      = "drift"
+	PlanType       = "plan"
+	SummaryType    = "summary"
+)
+
+// EventHeader contains the properties shared by all events.
+type EventHeader struct {
+	// SchemaVersion is the version of the event schema.
+	SchemaVersion string `json:"schemaVersion"`
+	// Timestamp is the RFC3339-formatted time of the event.
+	Timestamp string `json:"timestamp"`
+	// Type is the type of the event.
+	Type string `json:"type"`
+}
+
+// ObjectIdentifier identifies the object an event is related to.
+type ObjectIdentifier struct {
+	Group     string `json:"group"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// ValidationEvent reports objects that failed validation.
+type ValidationEvent struct {
+	EventHeader
+	Objects []ObjectIdentifier `json:"objects"`
+	Error   string             `json:"error"`
+	Reason  string             `json:"reason,omitempty"`
+}
+
+// ErrorEvent reports a fatal error that is not specific to an object.
+type ErrorEvent struct {
+	EventHeader
+	Error     string `json:"error"`
+	Reason    string `json:"reason,omitempty"`
+	Retryable bool   `json:"retryable,omitempty"`
+	// Object is the object the error is about, if known.
+	Object *ObjectIdentifier `json:"object,omitempty"`
+}
+
+// ActionStats contains the number of objects by result for an action.
+type ActionStats struct {
+	Count      int `json:"count"`
+	Successful int `json:"successful"`
+	Skipped    int `json:"skipped"`
+	Failed     int `json:"failed"`
+	// Timeout is only set for the Wait action.
+	Timeout *int `json:"timeout,omitempty"`
+	// Detached is only set for the Prune and Delete actions, if any
+	// objects were detached.
+	Detached *int `json:"detached,omitempty"`
+}
+
+// GroupEvent reports the start or end of a group of operations. The stats
+// are only set when a group has finished.
+type GroupEvent struct {
+	EventHeader
+	Action string `json:"action"`
+	Status string `json:"status"`
+	*ActionStats
+}
+
+// OperationEvent reports the result of an apply, prune, delete or wait
+// operation on a single object.
+type OperationEvent struct {
+	EventHeader
+	ObjectIdentifier
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Reason is the reason code of the error, see package reason.
+	Reason    string `json:"reason,omitempty"`
+	Retryable bool   `json:"retryable,omitempty"`
+}
+
+// ApplyEvent reports the result of applying a single object.
+type ApplyEvent struct {
+	OperationEvent
+	// Strategy is how the object was applied, if it was applied.
+	Strategy string `json:"strategy,omitempty"`
+}
+
+// RollbackEvent reports an object reverted after a failed apply.
+type RollbackEvent struct {
+	OperationEvent
+	Operation string `json:"operation"`
+}
+
+// StatusEvent reports a status update for a single object.
+type StatusEvent struct {
+	EventHeader
+	ObjectIdentifier
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// DiffEvent reports the changes an apply will make to a single object.
+type DiffEvent struct {
+	EventHeader
+	ObjectIdentifier
+	Diffs []FieldDiff `json:"diffs"`
+	Error string      `json:"error,omitempty"`
+}
+
+// AdoptEvent reports that an object was claimed by the inventory, or
+// could not be claimed because it is owned by another inventory.
+type AdoptEvent struct {
+	EventHeader
+	ObjectIdentifier
+	Error string `json:"error,omitempty"`
+}
+
+// TraceEvent explains why an object was assigned to a task group.
+type TraceEvent struct {
+	EventHeader
+	ObjectIdentifier
+	TaskGroup string            `json:"taskGroup"`
+	After     []TraceDependency `json:"after"`
+}
+
+// TraceDependency is an object another object is ordered after.
+type TraceDependency struct {
+	ObjectIdentifier
+	Reasons []string `json:"reasons"`
+}
+
+// ConflictEvent lists the fields of an object owned by other field
+// managers.
+type ConflictEvent struct {
+	EventHeader
+	ObjectIdentifier
+	Conflicts []FieldConflict `json:"conflicts"`
+}
+
+// FieldConflict is a field owned by another field manager.
+type FieldConflict struct {
+	Manager string `json:"manager"`
+	Field   string `json:"field"`
+}
+
+// DriftEvent reports whether a live object has drifted from its desired
+// state.
+type DriftEvent struct {
+	EventHeader
+	ObjectIdentifier
+	Status   string      `json:"status"`
+	Diffs    []FieldDiff `json:"diffs,omitempty"`
+	Managers []string    `json:"managers,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// PlanSummaryEvent reports the objects a run would create, configure,
+// leave unchanged and prune.
+type PlanSummaryEvent struct {
+	EventHeader
+	Create    []ObjectIdentifier `json:"create"`
+	Configure []ObjectIdentifier `json:"configure"`
+	Unchanged []ObjectIdentifier `json:"unchanged"`
+	Prune     []ObjectIdentifier `json:"prune"`
+	Errors    []ObjectError      `json:"errors,omitempty"`
+}
+
+// ObjectError is the error of an object.
+type ObjectError struct {
+	ObjectIdentifier
+	Error string `json:"error"`
+}
+
+// FieldDiff describes the change to a single field of an object.
+type FieldDiff struct {
+	Path      string      `json:"path"`
+	Operation string      `json:"operation"`
+	Old       interface{} `json:"old,omitempty"`
+	New       interface{} `json:"new,omitempty"`
+}
+
+// SummaryEvent reports the aggregate stats of an action.
+type SummaryEvent struct {
+	EventHeader
+	Action string `json:"action"`
+	ActionStats
+}
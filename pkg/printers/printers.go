@@ -4,40 +4,74 @@
 package printers
 
 import (
+	"time"
+
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/print/list"
 	"sigs.k8s.io/cli-utils/pkg/printers/events"
 	"sigs.k8s.io/cli-utils/pkg/printers/json"
 	"sigs.k8s.io/cli-utils/pkg/printers/printer"
+	"sigs.k8s.io/cli-utils/pkg/printers/progress"
 	"sigs.k8s.io/cli-utils/pkg/printers/table"
 )
 
 const (
-	EventsPrinter = "events"
-	TablePrinter  = "table"
-	JSONPrinter   = "json"
+	EventsPrinter   = "events"
+	TablePrinter    = "table"
+	JSONPrinter     = "json"
+	ProgressPrinter = "progress"
 )
 
+// Options configures the printers returned by GetPrinterWithOptions.
+type Options struct {
+	// Deterministic prints the events of each action group sorted by
+	// object, after the group finished, so the output of the events and json
+	// printers is stable. The table and progress printers are interactive
+	// and ignore it.
+	Deterministic bool
+	// Now is the clock of the timestamps of the json printer. Defaults to
+	// time.Now.
+	Now func() time.Time
+}
+
 func GetPrinter(printerType string, ioStreams genericclioptions.IOStreams) printer.Printer {
+	return GetPrinterWithOptions(printerType, ioStreams, Options{})
+}
+
+// GetPrinterWithOptions returns the printer of the type, configured with the
+// options.
+func GetPrinterWithOptions(printerType string, ioStreams genericclioptions.IOStreams, opts Options) printer.Printer {
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
 	switch printerType { //nolint:gocritic
 	case TablePrinter:
 		return &table.Printer{
 			IOStreams: ioStreams,
 		}
+	case ProgressPrinter:
+		return progress.NewPrinter(ioStreams)
 	case JSONPrinter:
 		return &list.BaseListPrinter{
 			FormatterFactory: func(previewStrategy common.DryRunStrategy) list.Formatter {
-				return json.NewFormatter(ioStreams, previewStrategy)
+				return json.NewFormatterWithClock(ioStreams, previewStrategy, now)
 			},
+			Deterministic: opts.Deterministic,
 		}
 	default:
-		return events.NewPrinter(ioStreams)
+		return &list.BaseListPrinter{
+			FormatterFactory: func(previewStrategy common.DryRunStrategy) list.Formatter {
+				return events.NewFormatter(ioStreams, previewStrategy)
+			},
+			Deterministic: opts.Deterministic,
+		}
 	}
 }
 
 func SupportedPrinters() []string {
-	return []string{EventsPrinter, TablePrinter, JSONPrinter}
+	return []string{EventsPrinter, TablePrinter, JSONPrinter, ProgressPrinter}
 }
 
 func DefaultPrinter() string {
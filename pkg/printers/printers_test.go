@@ -0,0 +1,93 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package printers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/fake"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/printers/printer"
+	printertesting "sigs.k8s.io/cli-utils/pkg/printers/testutil"
+)
+
+func deployment(name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("apps/v1")
+	u.SetKind("Deployment")
+	u.SetName(name)
+	u.SetNamespace("default")
+	return u
+}
+
+// reversed returns the events of the script with the events of each group
+// in reverse order, as if the operations completed in reverse order.
+func reversed(events []event.Event) []event.Event {
+	out := make([]event.Event, 0, len(events))
+	var group []event.Event
+	for _, e := range events {
+		if e.Type != event.ActionGroupType {
+			group = append([]event.Event{e}, group...)
+			continue
+		}
+		out = append(out, group...)
+		group = nil
+		out = append(out, e)
+	}
+	return append(out, group...)
+}
+
+func TestGetPrinterWithOptions_Deterministic(t *testing.T) {
+	foo := deployment("foo")
+	bar := deployment("bar")
+	events := fake.NewScript().
+		Apply(foo, bar).
+		Wait(event.ReconcileSuccessful, object.UnstructuredSetToObjMetadataSet(object.UnstructuredSet{foo, bar})...).
+		Events()
+	opts := Options{
+		Deterministic: true,
+		Now:           printertesting.FixedClock(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)),
+	}
+
+	for _, printerType := range []string{EventsPrinter, JSONPrinter} {
+		t.Run(printerType, func(t *testing.T) {
+			factory := func(ioStreams genericclioptions.IOStreams) printer.Printer {
+				return GetPrinterWithOptions(printerType, ioStreams, opts)
+			}
+			out, err := printertesting.PrintEvents(factory, events, common.DryRunNone, false)
+			assert.NoError(t, err)
+			printertesting.AssertGolden(t, "testdata/"+printerType+".golden", out)
+
+			// The output does not depend on the order of the events of a group.
+			reversedOut, err := printertesting.PrintEvents(factory, reversed(events), common.DryRunNone, false)
+			assert.NoError(t, err)
+			assert.Equal(t, out, reversedOut)
+		})
+	}
+}
+
+func TestGetPrinterWithOptions_DeterministicError(t *testing.T) {
+	foo := deployment("foo")
+	testErr := errors.New("connection refused")
+	events := fake.NewScript().Error(testErr).Events()
+	factory := func(ioStreams genericclioptions.IOStreams) printer.Printer {
+		return GetPrinterWithOptions(EventsPrinter, ioStreams, Options{Deterministic: true})
+	}
+	_, err := printertesting.PrintEvents(factory, events, common.DryRunNone, false)
+	assert.Equal(t, testErr, err)
+
+	// Buffered events are printed before the error.
+	events = fake.NewScript().Apply(foo).Events()
+	events = append(events[:3], fake.NewScript().Error(testErr).Events()[1:]...)
+	out, err := printertesting.PrintEvents(factory, events, common.DryRunNone, false)
+	assert.Equal(t, testErr, err)
+	assert.Contains(t, out, "deployment.apps/foo apply successful")
+}
@@ -0,0 +1,281 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package progress
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/validation"
+	"sigs.k8s.io/cli-utils/pkg/print/stats"
+)
+
+// InvalidStatus is the status of objects which failed validation.
+const InvalidStatus status.Status = "Invalid"
+
+func newProgressCollector(actionGroups []event.ActionGroup) *progressCollector {
+	objects := make(map[object.ObjMetadata]*objectState)
+	totalOps := 0
+	for _, group := range actionGroups {
+		switch group.Action {
+		case event.ApplyAction, event.PruneAction, event.DeleteAction, event.WaitAction:
+			totalOps += len(group.Identifiers)
+		default:
+			continue
+		}
+		for _, id := range group.Identifiers {
+			obj, found := objects[id]
+			if !found {
+				obj = &objectState{
+					identifier: id,
+					status:     status.UnknownStatus,
+				}
+				objects[id] = obj
+			}
+			if group.Action == event.WaitAction {
+				obj.waited = true
+			} else {
+				// Keep the action that describes the operation for the
+				// object rather than that we will wait for it.
+				obj.action = group.Action
+			}
+		}
+	}
+	return &progressCollector{
+		objects:    objects,
+		totalTasks: len(actionGroups),
+		totalOps:   totalOps,
+		doneOps:    make(map[string]bool),
+	}
+}
+
+// progressCollector consumes the events from the applier eventChannel and
+// keeps track of the latest state of all objects and the overall progress.
+type progressCollector struct {
+	mux sync.RWMutex
+
+	// objects contains the latest state of each object.
+	objects map[object.ObjMetadata]*objectState
+
+	// totalTasks is the number of action groups (tasks).
+	totalTasks int
+	// finishedTasks is the number of action groups that have finished.
+	finishedTasks int
+	// currentTask is the name of the most recently started action group.
+	currentTask string
+
+	// totalOps is the number of operations (apply, prune, delete or wait)
+	// on individual objects.
+	totalOps int
+	// doneOps contains the operations that have completed, keyed by the
+	// action group and object.
+	doneOps map[string]bool
+
+	// stats collect statistics from handled events
+	stats stats.Stats
+}
+
+// objectState captures the latest seen state of a single object.
+type objectState struct {
+	identifier object.ObjMetadata
+
+	// action is the operation performed on the object.
+	action event.ResourceAction
+	// waited is true if the object is waited on after the operation.
+	waited bool
+
+	// opStatus is the result of the operation, or empty if not done yet.
+	opStatus string
+	// opFailed is true if the operation failed.
+	opFailed bool
+	// opSuccessful is true if the operation succeeded.
+	opSuccessful bool
+	// waitStatus is the result of waiting on the object, if done.
+	waitStatus *event.WaitEventStatus
+
+	// status and message are the latest seen status of the object.
+	status  status.Status
+	message string
+}
+
+// collapsed returns true if the object has been handled successfully and
+// doesn't need to be shown individually.
+func (o *objectState) collapsed() bool {
+	if !o.opSuccessful {
+		return false
+	}
+	if !o.waited {
+		return true
+	}
+	return o.waitStatus != nil && *o.waitStatus == event.ReconcileSuccessful
+}
+
+// Listen starts a new goroutine that will listen for events on the
+// provided eventChannel and keep track of the latest state. The returned
+// channel is closed when the eventChannel has been closed and all events
+// have been processed, or after a fatal error has been sent on it.
+func (c *progressCollector) Listen(eventChannel <-chan event.Event) <-chan error {
+	completed := make(chan error)
+	go func() {
+		defer close(completed)
+		for ev := range eventChannel {
+			if err := c.processEvent(ev); err != nil {
+				completed <- err
+				return
+			}
+		}
+	}()
+	return completed
+}
+
+// processEvent processes an event and updates the state.
+func (c *progressCollector) processEvent(ev event.Event) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.stats.Handle(ev)
+	switch ev.Type {
+	case event.ErrorType:
+		return ev.ErrorEvent.Err
+	case event.ValidationType:
+		return c.processValidationEvent(ev.ValidationEvent)
+	case event.ActionGroupType:
+		c.processActionGroupEvent(ev.ActionGroupEvent)
+	case event.StatusType:
+		if obj, found := c.lookup(ev.StatusEvent.Identifier); found && ev.StatusEvent.PollResourceInfo != nil {
+			obj.status = ev.StatusEvent.PollResourceInfo.Status
+			obj.message = ev.StatusEvent.PollResourceInfo.Message
+		}
+	case event.ApplyType:
+		e := ev.ApplyEvent
+		c.processOperation(e.GroupName, e.Identifier, e.Status.String(),
+			e.Status == event.ApplySuccessful, e.Status == event.ApplyFailed, e.Error)
+	case event.PruneType:
+		e := ev.PruneEvent
+		c.processOperation(e.GroupName, e.Identifier, e.Status.String(),
+			e.Status == event.PruneSuccessful, e.Status == event.PruneFailed, e.Error)
+	case event.DeleteType:
+		e := ev.DeleteEvent
+		c.processOperation(e.GroupName, e.Identifier, e.Status.String(),
+			e.Status == event.DeleteSuccessful, e.Status == event.DeleteFailed, e.Error)
+	case event.WaitType:
+		c.processWaitEvent(ev.WaitEvent)
+	}
+	return nil
+}
+
+// processValidationEvent marks the objects that failed validation.
+func (c *progressCollector) processValidationEvent(e event.ValidationEvent) error {
+	klog.V(7).Infoln("processing validation event")
+	// unwrap validation errors
+	err := e.Error
+	if vErr, ok := err.(*validation.Error); ok {
+		err = vErr.Unwrap()
+	}
+	if len(e.Identifiers) == 0 {
+		// no objects, invalid event
+		return fmt.Errorf("invalid validation event: no identifiers: %w", err)
+	}
+	for _, id := range e.Identifiers {
+		if obj, found := c.lookup(id); found {
+			obj.status = InvalidStatus
+			obj.message = err.Error()
+		}
+	}
+	return nil
+}
+
+func (c *progressCollector) processActionGroupEvent(e event.ActionGroupEvent) {
+	switch e.Status {
+	case event.Started:
+		c.currentTask = e.GroupName
+	case event.Finished:
+		c.finishedTasks++
+	}
+}
+
+func (c *progressCollector) processOperation(groupName string, id object.ObjMetadata, opStatus string,
+	successful, failed bool, err error) {
+	c.doneOps[groupName+"/"+id.String()] = true
+	obj, found := c.lookup(id)
+	if !found {
+		return
+	}
+	obj.opStatus = opStatus
+	obj.opSuccessful = successful
+	obj.opFailed = failed
+	if err != nil {
+		obj.message = err.Error()
+	}
+}
+
+func (c *progressCollector) processWaitEvent(e event.WaitEvent) {
+	if e.Status == event.ReconcilePending {
+		return
+	}
+	c.doneOps[e.GroupName+"/"+e.Identifier.String()] = true
+	if obj, found := c.lookup(e.Identifier); found {
+		waitStatus := e.Status
+		obj.waitStatus = &waitStatus
+	}
+}
+
+func (c *progressCollector) lookup(id object.ObjMetadata) (*objectState, bool) {
+	obj, found := c.objects[id]
+	if !found {
+		klog.V(4).Infof("%s not found in objects; no processing", id)
+	}
+	return obj, found
+}
+
+// progressState is a snapshot of the latest state.
+type progressState struct {
+	totalTasks    int
+	finishedTasks int
+	currentTask   string
+	totalOps      int
+	doneOps       int
+	objects       []objectState
+	stats         stats.Stats
+}
+
+// LatestState returns a copy of the latest state, with the objects sorted.
+func (c *progressCollector) LatestState() progressState {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	objects := make([]objectState, 0, len(c.objects))
+	for _, obj := range c.objects {
+		objects = append(objects, *obj)
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return lessObjMetadata(objects[i].identifier, objects[j].identifier)
+	})
+	return progressState{
+		totalTasks:    c.totalTasks,
+		finishedTasks: c.finishedTasks,
+		currentTask:   c.currentTask,
+		totalOps:      c.totalOps,
+		doneOps:       len(c.doneOps),
+		objects:       objects,
+		stats:         c.stats,
+	}
+}
+
+func lessObjMetadata(idI, idJ object.ObjMetadata) bool {
+	if idI.Namespace != idJ.Namespace {
+		return idI.Namespace < idJ.Namespace
+	}
+	if idI.GroupKind.Group != idJ.GroupKind.Group {
+		return idI.GroupKind.Group < idJ.GroupKind.Group
+	}
+	if idI.GroupKind.Kind != idJ.GroupKind.Kind {
+		return idI.GroupKind.Kind < idJ.GroupKind.Kind
+	}
+	return idI.Name < idJ.Name
+}
@@ -0,0 +1,264 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package progress provides a printer that renders an interactive view
+// of the progress of an apply or destroy in the terminal. It prints a
+// progress bar for the overall operation, followed by one line for each
+// object that is still pending, in progress or has failed. Objects that
+// have been handled successfully are collapsed into a single line. When
+// the operation is done, the printer prints a summary of the results.
+package progress
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	printcommon "sigs.k8s.io/cli-utils/pkg/print/common"
+	"sigs.k8s.io/cli-utils/pkg/print/stats"
+	"sigs.k8s.io/cli-utils/pkg/printers/printer"
+)
+
+const (
+	barWidth      = 30
+	resourceWidth = 50
+	opWidth       = 12
+	statusWidth   = 12
+	messageWidth  = 60
+)
+
+// Printer implements the Printer interface and renders the progress of
+// the operation, refreshing the output in place.
+type Printer struct {
+	IOStreams genericclioptions.IOStreams
+	// RefreshInterval is the interval between updates of the output.
+	// Defaults to 500ms.
+	RefreshInterval time.Duration
+}
+
+var _ printer.Printer = &Printer{}
+
+// NewPrinter returns a new instance of the progress Printer.
+func NewPrinter(ioStreams genericclioptions.IOStreams) *Printer {
+	return &Printer{
+		IOStreams: ioStreams,
+	}
+}
+
+func (p *Printer) Print(ch <-chan event.Event, _ common.DryRunStrategy, _ bool) error {
+	// Wait for the init event that will give us the set of
+	// resources.
+	var initEvent event.InitEvent
+	for e := range ch {
+		if e.Type == event.InitType {
+			initEvent = e.InitEvent
+			break
+		}
+		// If we get an error event, we just print it and
+		// exit. The error event signals a fatal error.
+		if e.Type == event.ErrorType {
+			return e.ErrorEvent.Err
+		}
+	}
+	coll := newProgressCollector(initEvent.ActionGroups)
+
+	stop := make(chan struct{})
+	printCompleted := p.runPrintLoop(coll, stop)
+
+	// Block until the collector has processed all events.
+	var err error
+	for e := range coll.Listen(ch) {
+		err = e
+	}
+
+	close(stop)
+	<-printCompleted
+
+	if err != nil {
+		return err
+	}
+	// If no fatal errors happened, we will return a ResultError if
+	// one or more resources failed to apply/prune or reconcile.
+	return printcommon.ResultErrorFromStats(coll.LatestState().stats)
+}
+
+// runPrintLoop starts a new goroutine that will regularly fetch the
+// latest state from the collector and update the output. When stop is
+// closed, it prints the final state and the summary.
+func (p *Printer) runPrintLoop(coll *progressCollector, stop chan struct{}) chan struct{} {
+	finished := make(chan struct{})
+
+	interval := p.RefreshInterval
+	if interval == 0 {
+		interval = 500 * time.Millisecond
+	}
+
+	linesPrinted := p.printLines(render(coll.LatestState(), true), 0)
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				state := coll.LatestState()
+				p.printLines(render(state, true), linesPrinted)
+				p.printLines(summary(state.stats), 0)
+				return
+			case <-ticker.C:
+				linesPrinted = p.printLines(render(coll.LatestState(), true), linesPrinted)
+			}
+		}
+	}()
+	return finished
+}
+
+// printLines erases the moveUpCount previously printed lines, then prints
+// the passed lines. Returns the number of lines printed.
+func (p *Printer) printLines(lines []string, moveUpCount int) int {
+	for i := 0; i < moveUpCount; i++ {
+		// Move up and erase the line
+		p.printOrDie("%c[%dA", printcommon.ESC, 1)
+		p.printOrDie("%c[2K\r", printcommon.ESC)
+	}
+	for _, line := range lines {
+		p.printOrDie("%s\n", line)
+	}
+	return len(lines)
+}
+
+func (p *Printer) printOrDie(format string, a ...interface{}) {
+	_, err := fmt.Fprintf(p.IOStreams.Out, format, a...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// render returns the lines showing the passed state: the progress bar,
+// one line per object that has not completed successfully, and a line
+// with the number of collapsed objects.
+func render(state progressState, color bool) []string {
+	lines := []string{progressLine(state)}
+	collapsed := 0
+	for _, obj := range state.objects {
+		if obj.collapsed() {
+			collapsed++
+			continue
+		}
+		lines = append(lines, objectLine(obj, color))
+	}
+	if collapsed > 0 {
+		lines = append(lines, fmt.Sprintf("%d object(s) completed successfully", collapsed))
+	}
+	return lines
+}
+
+// progressLine returns the progress bar for the overall operation.
+func progressLine(state progressState) string {
+	filled := 0
+	percent := 100
+	if state.totalOps > 0 {
+		filled = barWidth * state.doneOps / state.totalOps
+		percent = 100 * state.doneOps / state.totalOps
+	} else if state.totalTasks > 0 && state.finishedTasks == state.totalTasks {
+		filled = barWidth
+	}
+	line := fmt.Sprintf("[%s%s] %3d%% (%d/%d operations, %d/%d tasks)",
+		strings.Repeat("#", filled), strings.Repeat("-", barWidth-filled),
+		percent, state.doneOps, state.totalOps, state.finishedTasks, state.totalTasks)
+	if state.currentTask != "" && state.finishedTasks < state.totalTasks {
+		line += " " + state.currentTask
+	}
+	return line
+}
+
+// objectLine returns the line for a single object.
+func objectLine(obj objectState, color bool) string {
+	opStatus := obj.opStatus
+	if opStatus == "" {
+		opStatus = event.ApplyPending.String()
+	}
+
+	statusText, s := objectStatus(obj)
+	statusCell := pad(statusText, statusWidth)
+	if c, ok := printcommon.ColorForStatus(s); ok && color {
+		statusCell = printcommon.SprintfWithColor(c, "%s", statusCell)
+	}
+
+	return strings.TrimRight(fmt.Sprintf("%s  %s  %s  %s",
+		pad(resourceString(obj.identifier), resourceWidth),
+		pad(opStatus, opWidth),
+		statusCell,
+		truncate(obj.message, messageWidth)), " ")
+}
+
+// objectStatus returns the text to show for the status of the object,
+// and the status used to pick its color.
+func objectStatus(obj objectState) (string, status.Status) {
+	if obj.opFailed {
+		return "Failed", status.FailedStatus
+	}
+	if obj.waitStatus != nil && *obj.waitStatus == event.ReconcileTimeout {
+		return "Timeout", status.FailedStatus
+	}
+	switch obj.status {
+	case status.InProgressStatus:
+		return "Reconciling", obj.status
+	case status.UnknownStatus:
+		return "", obj.status
+	default:
+		return obj.status.String(), obj.status
+	}
+}
+
+func resourceString(id object.ObjMetadata) string {
+	res := fmt.Sprintf("%s/%s", strings.ToLower(id.GroupKind.String()), id.Name)
+	if id.Namespace != "" {
+		res = id.Namespace + "/" + res
+	}
+	return res
+}
+
+func pad(text string, width int) string {
+	text = truncate(text, width)
+	return text + strings.Repeat(" ", width-len(text))
+}
+
+func truncate(text string, width int) string {
+	if len(text) > width {
+		return text[:width]
+	}
+	return text
+}
+
+// summary returns the lines summarizing the results of the operation.
+func summary(s stats.Stats) []string {
+	var lines []string
+	if s.ApplyStats != (stats.ApplyStats{}) {
+		as := s.ApplyStats
+		lines = append(lines, fmt.Sprintf("apply result: %d attempted, %d successful, %d skipped, %d failed",
+			as.Sum(), as.Successful, as.Skipped, as.Failed))
+	}
+	if s.PruneStats != (stats.PruneStats{}) {
+		ps := s.PruneStats
+		lines = append(lines, fmt.Sprintf("prune result: %d attempted, %d successful, %d skipped, %d failed",
+			ps.Sum(), ps.Successful, ps.Skipped, ps.Failed))
+	}
+	if s.DeleteStats != (stats.DeleteStats{}) {
+		ds := s.DeleteStats
+		lines = append(lines, fmt.Sprintf("delete result: %d attempted, %d successful, %d skipped, %d failed",
+			ds.Sum(), ds.Successful, ds.Skipped, ds.Failed))
+	}
+	if s.WaitStats != (stats.WaitStats{}) {
+		ws := s.WaitStats
+		lines = append(lines, fmt.Sprintf("reconcile result: %d attempted, %d successful, %d skipped, %d failed, %d timed out",
+			ws.Sum(), ws.Successful, ws.Skipped, ws.Failed, ws.Timeout))
+	}
+	return lines
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package progress
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	pe "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/printers/printer"
+	printertesting "sigs.k8s.io/cli-utils/pkg/printers/testutil"
+)
+
+func TestPrint(t *testing.T) {
+	printertesting.PrintResultErrorTest(t, func() printer.Printer {
+		ioStreams, _, _, _ := genericclioptions.NewTestIOStreams()
+		return NewPrinter(ioStreams)
+	})
+}
+
+func TestRender(t *testing.T) {
+	depID := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		Name:      "foo",
+		Namespace: "default",
+	}
+	cmID := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Kind: "ConfigMap"},
+		Name:      "bar",
+		Namespace: "default",
+	}
+	svcID := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Kind: "Service"},
+		Name:      "baz",
+		Namespace: "default",
+	}
+	actionGroups := []event.ActionGroup{
+		{Name: "apply-0", Action: event.ApplyAction, Identifiers: object.ObjMetadataSet{depID, cmID, svcID}},
+		{Name: "wait-0", Action: event.WaitAction, Identifiers: object.ObjMetadataSet{depID, cmID, svcID}},
+	}
+
+	testCases := map[string]struct {
+		events        []event.Event
+		expectedLines []string
+	}{
+		"nothing started": {
+			expectedLines: []string{
+				"[------------------------------]   0% (0/6 operations, 0/2 tasks)",
+				"default/configmap/bar                               Pending",
+				"default/service/baz                                 Pending",
+				"default/deployment.apps/foo                         Pending",
+			},
+		},
+		"successful objects are collapsed": {
+			events: []event.Event{
+				{Type: event.ActionGroupType, ActionGroupEvent: event.ActionGroupEvent{
+					GroupName: "apply-0", Action: event.ApplyAction, Status: event.Started}},
+				{Type: event.ApplyType, ApplyEvent: event.ApplyEvent{
+					GroupName: "apply-0", Identifier: cmID, Status: event.ApplySuccessful}},
+				{Type: event.ApplyType, ApplyEvent: event.ApplyEvent{
+					GroupName: "apply-0", Identifier: depID, Status: event.ApplySuccessful}},
+				{Type: event.ApplyType, ApplyEvent: event.ApplyEvent{
+					GroupName: "apply-0", Identifier: svcID, Status: event.ApplyFailed, Error: errors.New("boom")}},
+				{Type: event.ActionGroupType, ActionGroupEvent: event.ActionGroupEvent{
+					GroupName: "apply-0", Action: event.ApplyAction, Status: event.Finished}},
+				{Type: event.ActionGroupType, ActionGroupEvent: event.ActionGroupEvent{
+					GroupName: "wait-0", Action: event.WaitAction, Status: event.Started}},
+				{Type: event.StatusType, StatusEvent: event.StatusEvent{
+					Identifier: depID,
+					PollResourceInfo: &pe.ResourceStatus{
+						Identifier: depID, Status: status.InProgressStatus, Message: "Replicas: 0/1"},
+				}},
+				{Type: event.WaitType, WaitEvent: event.WaitEvent{
+					GroupName: "wait-0", Identifier: cmID, Status: event.ReconcileSuccessful}},
+			},
+			expectedLines: []string{
+				"[####################----------]  66% (4/6 operations, 1/2 tasks) wait-0",
+				"default/service/baz                                 Failed        Failed        boom",
+				"default/deployment.apps/foo                         Successful    Reconciling   Replicas: 0/1",
+				"1 object(s) completed successfully",
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			coll := newProgressCollector(actionGroups)
+			for _, e := range tc.events {
+				require.NoError(t, coll.processEvent(e))
+			}
+			assert.Equal(t, tc.expectedLines, render(coll.LatestState(), false))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/printers/printer"
+)
+
+// UpdateGoldenEnv is the environment variable that makes AssertGolden
+// write the golden files instead of comparing with them, if set to "true".
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// StreamsPrinterFactoryFunc returns a printer writing to the IOStreams.
+type StreamsPrinterFactoryFunc func(ioStreams genericclioptions.IOStreams) printer.Printer
+
+// FixedClock returns a clock that always returns the time, for printers
+// with timestamps.
+func FixedClock(t time.Time) func() time.Time {
+	return func() time.Time {
+		return t
+	}
+}
+
+// PrintEvents prints the events with the printer of the factory, and
+// returns the output and the error of the printer. Use a deterministic
+// printer, see printers.Options, for stable output.
+func PrintEvents(f StreamsPrinterFactoryFunc, events []event.Event,
+	previewStrategy common.DryRunStrategy, printStatus bool) (string, error) {
+	ioStreams, _, out, _ := genericclioptions.NewTestIOStreams()
+	p := f(ioStreams)
+	eventChannel := make(chan event.Event)
+	go func() {
+		defer close(eventChannel)
+		for _, e := range events {
+			eventChannel <- e
+		}
+	}()
+	err := p.Print(eventChannel, previewStrategy, printStatus)
+	// Drain the channel, in case the printer returned early.
+	for range eventChannel {
+	}
+	return out.String(), err
+}
+
+// AssertGolden asserts that the output equals the content of the golden
+// file, e.g. "testdata/apply.golden". If UpdateGoldenEnv is set, the golden
+// file is written with the output instead.
+func AssertGolden(t *testing.T, goldenFile string, output string) {
+	t.Helper()
+	if os.Getenv(UpdateGoldenEnv) == "true" {
+		require.NoError(t, os.MkdirAll(filepath.Dir(goldenFile), 0o755))
+		require.NoError(t, os.WriteFile(goldenFile, []byte(output), 0o644)) //nolint:gosec
+		return
+	}
+	expected, err := os.ReadFile(goldenFile)
+	require.NoError(t, err, "failed to read golden file, set %s=true to write it", UpdateGoldenEnv)
+	assert.Equal(t, string(expected), output, "output differs from golden file %s", goldenFile)
+}
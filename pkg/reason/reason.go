@@ -0,0 +1,290 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package reason provides machine-readable reason codes for the errors
+// surfaced in events, so programmatic consumers can branch on why an
+// operation failed, and whether retrying may succeed, instead of matching
+// error strings.
+//
+// Errors report their reason by implementing Classified. Errors of the
+// Kubernetes API are classified by their status reason. MultiErrors are
+// classified by the reasons of their causes.
+package reason
+
+import (
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/cli-utils/pkg/multierror"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Reason is a machine-readable code of why an operation failed.
+type Reason string
+
+const (
+	// Unknown is the reason of errors that are not classified.
+	Unknown Reason = "Unknown"
+	// Multiple is the reason of a MultiError with causes of different
+	// reasons.
+	Multiple Reason = "Multiple"
+
+	// ApplyFailed is the reason of objects that failed to apply for an
+	// unclassified reason.
+	ApplyFailed Reason = "ApplyFailed"
+	// UnknownType is the reason of objects whose type is not known to the
+	// cluster.
+	UnknownType Reason = "UnknownType"
+	// Invalid is the reason of objects that failed validation.
+	Invalid Reason = "Invalid"
+	// InvalidAnnotation is the reason of objects with an invalid annotation.
+	InvalidAnnotation Reason = "InvalidAnnotation"
+	// DependencyCycle is the reason of objects with cyclic dependencies.
+	DependencyCycle Reason = "DependencyCycle"
+	// ExternalDependency is the reason of objects that depend on objects
+	// that are not applied with them.
+	ExternalDependency Reason = "ExternalDependency"
+	// DependencyNotReady is the reason of objects that were not actuated,
+	// because a dependency failed or did not reconcile.
+	DependencyNotReady Reason = "DependencyNotReady"
+	// DependencyMismatch is the reason of objects that were not actuated,
+	// because a dependency is actuated with a different strategy.
+	DependencyMismatch Reason = "DependencyMismatch"
+	// PolicyDenied is the reason of objects skipped or failed by a policy
+	// gate.
+	PolicyDenied Reason = "PolicyDenied"
+	// StalePlan is the reason of plans that no longer match the run.
+	StalePlan Reason = "StalePlan"
+
+	// InventoryPolicy is the reason of objects that were not actuated,
+	// because the inventory policy prevented it.
+	InventoryPolicy Reason = "InventoryPolicy"
+	// NoInventory is the reason of packages without inventory object.
+	NoInventory Reason = "NoInventory"
+	// MultipleInventories is the reason of packages with multiple
+	// inventory objects.
+	MultipleInventories Reason = "MultipleInventories"
+
+	// DeletionPrevented is the reason of objects that were not deleted,
+	// because of their lifecycle annotation.
+	DeletionPrevented Reason = "DeletionPrevented"
+	// CurrentlyApplied is the reason of objects that were not deleted,
+	// because they were applied by the same run.
+	CurrentlyApplied Reason = "CurrentlyApplied"
+	// NamespaceInUse is the reason of namespaces that were not deleted,
+	// because they contain applied objects.
+	NamespaceInUse Reason = "NamespaceInUse"
+	// NamespaceNotEmpty is the reason of namespaces that were not deleted,
+	// because they are not empty.
+	NamespaceNotEmpty Reason = "NamespaceNotEmpty"
+
+	// StatusPollFailed is the reason of status polling failures, while
+	// waiting for objects to reconcile.
+	StatusPollFailed Reason = "StatusPollFailed"
+
+	// Conflict is the reason of API conflicts, e.g. field manager conflicts.
+	Conflict Reason = "Conflict"
+	// NotFound is the reason of objects that do not exist.
+	NotFound Reason = "NotFound"
+	// AlreadyExists is the reason of objects that already exist.
+	AlreadyExists Reason = "AlreadyExists"
+	// Forbidden is the reason of requests that are not authorized.
+	Forbidden Reason = "Forbidden"
+	// Unauthorized is the reason of requests that are not authenticated.
+	Unauthorized Reason = "Unauthorized"
+	// Rejected is the reason of objects rejected by the API server, e.g.
+	// by validation or an admission webhook.
+	Rejected Reason = "Rejected"
+	// Timeout is the reason of requests that timed out.
+	Timeout Reason = "Timeout"
+	// TooManyRequests is the reason of requests that were throttled.
+	TooManyRequests Reason = "TooManyRequests"
+	// ServerError is the reason of internal errors of the API server.
+	ServerError Reason = "ServerError"
+	// Unavailable is the reason of requests to an unavailable API server.
+	Unavailable Reason = "Unavailable"
+)
+
+// Classified is implemented by errors that report their reason, and
+// whether retrying may succeed.
+type Classified interface {
+	error
+	ErrorReason() Reason
+	ErrorRetryable() bool
+}
+
+// Error is an error with a reason, the object it is about, if any, and
+// whether retrying may succeed.
+type Error struct {
+	Reason Reason
+	// Object is the object the error is about, or nil.
+	Object    *object.ObjMetadata
+	Retryable bool
+	Err       error
+}
+
+var _ Classified = &Error{}
+
+// New returns an Error with the reason, wrapping err.
+func New(reason Reason, retryable bool, err error) *Error {
+	return &Error{
+		Reason:    reason,
+		Retryable: retryable,
+		Err:       err,
+	}
+}
+
+// NewForObject returns an Error about the object with the reason, wrapping
+// err.
+func NewForObject(reason Reason, id object.ObjMetadata, retryable bool, err error) *Error {
+	e := New(reason, retryable, err)
+	e.Object = &id
+	return e
+}
+
+// ForObject returns an Error about the object, wrapping err, with the
+// reason of err and whether it is retryable.
+func ForObject(id object.ObjMetadata, err error) *Error {
+	return NewForObject(Of(err), id, IsRetryable(err), err)
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) ErrorReason() Reason {
+	return e.Reason
+}
+
+func (e *Error) ErrorRetryable() bool {
+	return e.Retryable
+}
+
+// Of returns the reason of the error, or Unknown if it is not classified.
+// Returns an empty reason if the error is nil.
+//
+// The reason is that of the outermost Classified error in the chain of
+// wrapped errors, or else the status reason of a wrapped API error.
+// The reason of a MultiError is the reason of its causes, if they all have
+// the same reason, or Multiple.
+func Of(err error) Reason {
+	if err == nil {
+		return ""
+	}
+	if _, ok := err.(multierror.Interface); ok {
+		reasons := Reasons(err)
+		switch len(reasons) {
+		case 0:
+			return Unknown
+		case 1:
+			return reasons[0]
+		default:
+			return Multiple
+		}
+	}
+	var cErr Classified
+	if errors.As(err, &cErr) {
+		return cErr.ErrorReason()
+	}
+	var unknownTypeErr *object.UnknownTypeError
+	if errors.As(err, &unknownTypeErr) {
+		return UnknownType
+	}
+	var annotationErr object.InvalidAnnotationError
+	if errors.As(err, &annotationErr) {
+		return InvalidAnnotation
+	}
+	if reason, found := apiReason(err); found {
+		return reason
+	}
+	return Unknown
+}
+
+// Reasons returns the distinct reasons of the causes of a MultiError, in
+// order, or the reason of any other error. Returns nil if the error is nil.
+func Reasons(err error) []Reason {
+	if err == nil {
+		return nil
+	}
+	var reasons []Reason
+	seen := make(map[Reason]bool)
+	for _, cause := range multierror.Unwrap(err) {
+		reason := Of(cause)
+		if !seen[reason] {
+			seen[reason] = true
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons
+}
+
+// IsRetryable returns true if retrying the operation that failed with the
+// error may succeed. A MultiError is retryable if all of its causes are.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if mErr, ok := err.(multierror.Interface); ok {
+		causes := mErr.Errors()
+		for _, cause := range causes {
+			if !IsRetryable(cause) {
+				return false
+			}
+		}
+		return len(causes) > 0
+	}
+	var cErr Classified
+	if errors.As(err, &cErr) {
+		return cErr.ErrorRetryable()
+	}
+	switch reason, _ := apiReason(err); reason {
+	case Conflict, Timeout, TooManyRequests, ServerError, Unavailable:
+		return true
+	}
+	return false
+}
+
+// ObjectOf returns the object the error is about, if it wraps an Error
+// with an object.
+func ObjectOf(err error) (object.ObjMetadata, bool) {
+	var rErr *Error
+	if errors.As(err, &rErr) && rErr.Object != nil {
+		return *rErr.Object, true
+	}
+	return object.ObjMetadata{}, false
+}
+
+// apiReason returns the reason of a wrapped API error.
+func apiReason(err error) (Reason, bool) {
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) {
+		return "", false
+	}
+	switch {
+	case apierrors.IsConflict(err):
+		return Conflict, true
+	case apierrors.IsNotFound(err):
+		return NotFound, true
+	case apierrors.IsAlreadyExists(err):
+		return AlreadyExists, true
+	case apierrors.IsForbidden(err):
+		return Forbidden, true
+	case apierrors.IsUnauthorized(err):
+		return Unauthorized, true
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		return Rejected, true
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err):
+		return Timeout, true
+	case apierrors.IsTooManyRequests(err):
+		return TooManyRequests, true
+	case apierrors.IsInternalError(err):
+		return ServerError, true
+	case apierrors.IsServiceUnavailable(err):
+		return Unavailable, true
+	}
+	return Unknown, true
+}
@@ -0,0 +1,142 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package reason_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	applyerror "sigs.k8s.io/cli-utils/pkg/apply/error"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/multierror"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/graph"
+	"sigs.k8s.io/cli-utils/pkg/object/validation"
+	"sigs.k8s.io/cli-utils/pkg/reason"
+)
+
+var deploymentID = object.ObjMetadata{
+	GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+	Namespace: "default",
+	Name:      "test",
+}
+
+func TestOf(t *testing.T) {
+	conflict := apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"},
+		"test", errors.New("conflict"))
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"},
+		"test", errors.New("forbidden"))
+	policyErr := &inventory.PolicyPreventedActuationError{
+		Strategy: actuation.ActuationStrategyApply,
+		Policy:   inventory.PolicyMustMatch,
+		Status:   inventory.NoMatch,
+	}
+
+	testCases := map[string]struct {
+		err               error
+		expectedReason    reason.Reason
+		expectedReasons   []reason.Reason
+		expectedRetryable bool
+	}{
+		"nil": {
+			err: nil,
+		},
+		"unclassified": {
+			err:             errors.New("failed"),
+			expectedReason:  reason.Unknown,
+			expectedReasons: []reason.Reason{reason.Unknown},
+		},
+		"reason error": {
+			err:               reason.New(reason.StatusPollFailed, true, errors.New("failed")),
+			expectedReason:    reason.StatusPollFailed,
+			expectedReasons:   []reason.Reason{reason.StatusPollFailed},
+			expectedRetryable: true,
+		},
+		"wrapped classified error": {
+			err:             fmt.Errorf("failed: %w", policyErr),
+			expectedReason:  reason.InventoryPolicy,
+			expectedReasons: []reason.Reason{reason.InventoryPolicy},
+		},
+		"api error": {
+			err:               conflict,
+			expectedReason:    reason.Conflict,
+			expectedReasons:   []reason.Reason{reason.Conflict},
+			expectedRetryable: true,
+		},
+		"apply run error": {
+			err:             applyerror.NewApplyRunError(forbidden),
+			expectedReason:  reason.Forbidden,
+			expectedReasons: []reason.Reason{reason.Forbidden},
+		},
+		"unclassified apply run error": {
+			err:             applyerror.NewApplyRunError(errors.New("failed")),
+			expectedReason:  reason.ApplyFailed,
+			expectedReasons: []reason.Reason{reason.ApplyFailed},
+		},
+		"validation error": {
+			err:             validation.NewError(graph.CyclicDependencyError{}, deploymentID),
+			expectedReason:  reason.DependencyCycle,
+			expectedReasons: []reason.Reason{reason.DependencyCycle},
+		},
+		"unclassified validation error": {
+			err:             validation.NewError(errors.New("invalid"), deploymentID),
+			expectedReason:  reason.Invalid,
+			expectedReasons: []reason.Reason{reason.Invalid},
+		},
+		"invalid annotation": {
+			err: object.InvalidAnnotationError{
+				Annotation: "config.kubernetes.io/depends-on",
+				Cause:      errors.New("invalid"),
+			},
+			expectedReason:  reason.InvalidAnnotation,
+			expectedReasons: []reason.Reason{reason.InvalidAnnotation},
+		},
+		"multierror with one reason": {
+			err:               multierror.New(conflict, conflict),
+			expectedReason:    reason.Conflict,
+			expectedReasons:   []reason.Reason{reason.Conflict},
+			expectedRetryable: true,
+		},
+		"multierror with multiple reasons": {
+			err:             multierror.New(conflict, multierror.New(forbidden, policyErr)),
+			expectedReason:  reason.Multiple,
+			expectedReasons: []reason.Reason{reason.Conflict, reason.Forbidden, reason.InventoryPolicy},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expectedReason, reason.Of(tc.err))
+			assert.Equal(t, tc.expectedReasons, reason.Reasons(tc.err))
+			assert.Equal(t, tc.expectedRetryable, reason.IsRetryable(tc.err))
+		})
+	}
+}
+
+func TestObjectOf(t *testing.T) {
+	err := fmt.Errorf("failed: %w",
+		reason.NewForObject(reason.ApplyFailed, deploymentID, false, errors.New("failed")))
+	id, found := reason.ObjectOf(err)
+	assert.True(t, found)
+	assert.Equal(t, deploymentID, id)
+	assert.EqualError(t, err, "failed: failed")
+
+	_, found = reason.ObjectOf(errors.New("failed"))
+	assert.False(t, found)
+}
+
+func TestForObject(t *testing.T) {
+	err := reason.ForObject(deploymentID,
+		fmt.Errorf("failed: %w", apierrors.NewTimeoutError("timeout", 1)))
+	assert.Equal(t, reason.Timeout, reason.Of(err))
+	assert.True(t, reason.IsRetryable(err))
+	id, found := reason.ObjectOf(err)
+	assert.True(t, found)
+	assert.Equal(t, deploymentID, id)
+}
@@ -0,0 +1,233 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ssa migrates objects managed with client-side apply to
+// server-side apply.
+//
+// Objects applied with client-side apply have their fields owned by an
+// Update operation of the client-side field manager. The first server-side
+// apply of such an object with another field manager conflicts with those
+// fields, or leaves them owned by both managers, so fields removed from
+// the manifest are never removed from the object. Upgrading the managed
+// fields transfers the ownership to the server-side apply field manager
+// before the object is applied.
+package ssa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/reason"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+// DefaultClientSideManagers are the field managers used by kubectl for
+// client-side apply.
+var DefaultClientSideManagers = []string{
+	"kubectl-client-side-apply",
+	"before-first-apply",
+}
+
+// lastAppliedPath is the field path of the last-applied-configuration
+// annotation, which is only owned by client-side apply.
+var lastAppliedPath = fieldpath.MakePathOrDie("metadata", "annotations",
+	corev1.LastAppliedConfigAnnotation)
+
+// UpgradeManagedFields transfers the ownership of the fields owned by
+// client-side apply to the server-side apply field manager, by updating the
+// managed fields of the passed object. Update operations are client-side
+// applies if they own the last-applied-configuration annotation, or if
+// their field manager is one of the client-side apply field managers.
+// Update operations of the server-side field manager itself are also
+// transferred, because they are recorded when the same manager applies
+// client-side. Field sets are specific to an apiVersion, so only Update
+// operations of the same apiVersion as the server-side apply, or else as
+// the object, are transferred. Returns true if the managed fields were
+// changed.
+func UpgradeManagedFields(obj *unstructured.Unstructured, fieldManager string, csaManagers ...string) (bool, error) {
+	managers := sets.NewString(csaManagers...).Insert(fieldManager)
+	entries := obj.GetManagedFields()
+
+	apiVersion := obj.GetAPIVersion()
+	for _, entry := range entries {
+		if isApplyOf(entry, fieldManager) {
+			apiVersion = entry.APIVersion
+		}
+	}
+
+	var kept []metav1.ManagedFieldsEntry
+	var owned *fieldpath.Set
+	ssaIndex := -1
+	for _, entry := range entries {
+		switch {
+		case entry.Subresource != "":
+			kept = append(kept, entry)
+		case entry.Operation == metav1.ManagedFieldsOperationUpdate:
+			set, csa, err := clientSideApplied(entry, managers)
+			if err != nil {
+				return false, err
+			}
+			if !csa {
+				kept = append(kept, entry)
+				continue
+			}
+			if entry.APIVersion != apiVersion {
+				klog.V(4).Infof("not upgrading fields of manager %q: apiVersion %q differs from %q",
+					entry.Manager, entry.APIVersion, apiVersion)
+				kept = append(kept, entry)
+				continue
+			}
+			if owned == nil {
+				owned = set
+			} else {
+				owned = owned.Union(set)
+			}
+		case isApplyOf(entry, fieldManager):
+			ssaIndex = len(kept)
+			kept = append(kept, entry)
+		default:
+			kept = append(kept, entry)
+		}
+	}
+	if owned == nil {
+		return false, nil
+	}
+	// The annotation is not part of the applied manifest.
+	owned = owned.Difference(fieldpath.NewSet(lastAppliedPath))
+
+	now := metav1.Now()
+	if ssaIndex >= 0 {
+		set, err := decodeFields(kept[ssaIndex])
+		if err != nil {
+			return false, fmt.Errorf("failed to decode fields of manager %q: %w", fieldManager, err)
+		}
+		owned = owned.Union(set)
+	}
+	raw, err := owned.ToJSON()
+	if err != nil {
+		return false, fmt.Errorf("failed to encode fields: %w", err)
+	}
+	entry := metav1.ManagedFieldsEntry{
+		Manager:    fieldManager,
+		Operation:  metav1.ManagedFieldsOperationApply,
+		APIVersion: apiVersion,
+		Time:       &now,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: raw},
+	}
+	if ssaIndex >= 0 {
+		kept[ssaIndex] = entry
+	} else {
+		kept = append(kept, entry)
+	}
+	obj.SetManagedFields(kept)
+	return true, nil
+}
+
+// isApplyOf returns true if the entry is the server-side apply of the
+// field manager.
+func isApplyOf(entry metav1.ManagedFieldsEntry, fieldManager string) bool {
+	return entry.Subresource == "" &&
+		entry.Operation == metav1.ManagedFieldsOperationApply &&
+		entry.Manager == fieldManager
+}
+
+// clientSideApplied decodes the fields of the Update entry, and returns
+// true if they were client-side applied. Fields of other managers that
+// fail to decode are not client-side applied.
+func clientSideApplied(entry metav1.ManagedFieldsEntry, managers sets.String) (*fieldpath.Set, bool, error) {
+	set, err := decodeFields(entry)
+	if err != nil {
+		if managers.Has(entry.Manager) {
+			return nil, false, fmt.Errorf("failed to decode fields of manager %q: %w", entry.Manager, err)
+		}
+		return nil, false, nil
+	}
+	return set, managers.Has(entry.Manager) || set.Has(lastAppliedPath), nil
+}
+
+func decodeFields(entry metav1.ManagedFieldsEntry) (*fieldpath.Set, error) {
+	set := &fieldpath.Set{}
+	if entry.FieldsV1 == nil {
+		return set, nil
+	}
+	if err := set.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// Upgrader upgrades the managed fields of live objects.
+type Upgrader struct {
+	Client dynamic.Interface
+	Mapper meta.RESTMapper
+	// FieldManager is the server-side apply field manager. Defaults to
+	// common.DefaultFieldManager.
+	FieldManager string
+	// ClientSideManagers are the client-side apply field managers.
+	// Defaults to DefaultClientSideManagers.
+	ClientSideManagers []string
+}
+
+// Upgrade upgrades the managed fields of the live object with the passed
+// identifier, if it was managed with client-side apply. Objects that do
+// not exist are ignored. The update is rejected if the object was changed
+// concurrently. Returns true if the managed fields were changed.
+func (u *Upgrader) Upgrade(ctx context.Context, id object.ObjMetadata, dryRun common.DryRunStrategy) (bool, error) {
+	client, err := object.ResourceClient(u.Client, u.Mapper, id)
+	if err != nil {
+		return false, err
+	}
+	live, err := client.Get(ctx, id.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, reason.ForObject(id, fmt.Errorf("failed to get object %s: %w", id, err))
+	}
+	fieldManager := u.FieldManager
+	if fieldManager == "" {
+		fieldManager = common.DefaultFieldManager
+	}
+	csaManagers := u.ClientSideManagers
+	if csaManagers == nil {
+		csaManagers = DefaultClientSideManagers
+	}
+	changed, err := UpgradeManagedFields(live, fieldManager, csaManagers...)
+	if err != nil || !changed {
+		return false, err
+	}
+	if dryRun.ClientDryRun() {
+		klog.V(4).Infof("dry-run upgrade managed fields of %s: not updated", id)
+		return true, nil
+	}
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": live.GetResourceVersion()},
+		{"op": "replace", "path": "/metadata/managedFields", "value": live.GetManagedFields()},
+	})
+	if err != nil {
+		return false, err
+	}
+	opts := metav1.PatchOptions{}
+	if dryRun.ServerDryRun() {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	if _, err := client.Patch(ctx, id.Name, types.JSONPatchType, patch, opts); err != nil {
+		return false, reason.ForObject(id, fmt.Errorf("failed to upgrade managed fields of %s: %w", id, err))
+	}
+	klog.V(4).Infof("upgraded managed fields of %s to field manager %q", id, fieldManager)
+	return true, nil
+}
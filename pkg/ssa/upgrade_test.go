@@ -0,0 +1,197 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ssa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	csaFields        = `{"f:data":{"f:foo":{}},"f:metadata":{"f:annotations":{".":{},"f:kubectl.kubernetes.io/last-applied-configuration":{}}}}`
+	ssaFields        = `{"f:data":{"f:bar":{}}}`
+	controllerFields = `{"f:metadata":{"f:labels":{"f:owner":{}}}}`
+)
+
+func managedFieldsEntry(manager string, op metav1.ManagedFieldsOperationType, fields string) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:    manager,
+		Operation:  op,
+		APIVersion: "v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+	}
+}
+
+func withAPIVersion(entry metav1.ManagedFieldsEntry, apiVersion string) metav1.ManagedFieldsEntry {
+	entry.APIVersion = apiVersion
+	return entry
+}
+
+func configMap(entries ...metav1.ManagedFieldsEntry) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":            "cm",
+				"namespace":       "default",
+				"resourceVersion": "1",
+			},
+		},
+	}
+	obj.SetManagedFields(entries)
+	return obj
+}
+
+// simplify drops the timestamps of the entries, for comparison.
+func simplify(entries []metav1.ManagedFieldsEntry) []metav1.ManagedFieldsEntry {
+	for i := range entries {
+		entries[i].Time = nil
+	}
+	return entries
+}
+
+func TestUpgradeManagedFields(t *testing.T) {
+	testCases := map[string]struct {
+		entries         []metav1.ManagedFieldsEntry
+		expectedChanged bool
+		expectedEntries []metav1.ManagedFieldsEntry
+	}{
+		"no managed fields": {
+			expectedChanged: false,
+		},
+		"no client-side apply": {
+			entries: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("test", metav1.ManagedFieldsOperationApply, ssaFields),
+				managedFieldsEntry("controller", metav1.ManagedFieldsOperationUpdate, controllerFields),
+			},
+			expectedChanged: false,
+			expectedEntries: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("test", metav1.ManagedFieldsOperationApply, ssaFields),
+				managedFieldsEntry("controller", metav1.ManagedFieldsOperationUpdate, controllerFields),
+			},
+		},
+		"client-side apply is transferred": {
+			entries: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("kubectl-client-side-apply", metav1.ManagedFieldsOperationUpdate, csaFields),
+				managedFieldsEntry("controller", metav1.ManagedFieldsOperationUpdate, controllerFields),
+			},
+			expectedChanged: true,
+			expectedEntries: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("controller", metav1.ManagedFieldsOperationUpdate, controllerFields),
+				managedFieldsEntry("test", metav1.ManagedFieldsOperationApply,
+					`{"f:data":{"f:foo":{}},"f:metadata":{"f:annotations":{}}}`),
+			},
+		},
+		"client-side apply of another manager is detected by annotation": {
+			entries: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("kubectl", metav1.ManagedFieldsOperationUpdate, csaFields),
+				managedFieldsEntry("controller", metav1.ManagedFieldsOperationUpdate, controllerFields),
+			},
+			expectedChanged: true,
+			expectedEntries: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("controller", metav1.ManagedFieldsOperationUpdate, controllerFields),
+				managedFieldsEntry("test", metav1.ManagedFieldsOperationApply,
+					`{"f:data":{"f:foo":{}},"f:metadata":{"f:annotations":{}}}`),
+			},
+		},
+		"client-side apply of another apiVersion is kept": {
+			entries: []metav1.ManagedFieldsEntry{
+				withAPIVersion(managedFieldsEntry("kubectl-client-side-apply", metav1.ManagedFieldsOperationUpdate, csaFields), "v1beta1"),
+			},
+			expectedChanged: false,
+			expectedEntries: []metav1.ManagedFieldsEntry{
+				withAPIVersion(managedFieldsEntry("kubectl-client-side-apply", metav1.ManagedFieldsOperationUpdate, csaFields), "v1beta1"),
+			},
+		},
+		"client-side apply is merged into server-side apply": {
+			entries: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("test", metav1.ManagedFieldsOperationApply, ssaFields),
+				managedFieldsEntry("test", metav1.ManagedFieldsOperationUpdate, csaFields),
+			},
+			expectedChanged: true,
+			expectedEntries: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("test", metav1.ManagedFieldsOperationApply,
+					`{"f:data":{"f:bar":{},"f:foo":{}},"f:metadata":{"f:annotations":{}}}`),
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			obj := configMap(tc.entries...)
+			changed, err := UpgradeManagedFields(obj, "test", DefaultClientSideManagers...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedChanged, changed)
+			assert.Equal(t, tc.expectedEntries, simplify(obj.GetManagedFields()))
+		})
+	}
+}
+
+func TestUpgrader_Upgrade(t *testing.T) {
+	live := configMap(
+		managedFieldsEntry("kubectl-client-side-apply", metav1.ManagedFieldsOperationUpdate, csaFields),
+	)
+	id := object.UnstructuredToObjMetadata(live)
+	mapper := testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+		scheme.Scheme.PrioritizedVersionsAllGroups()...)
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+	testCases := map[string]struct {
+		dryRun          common.DryRunStrategy
+		expectedManager string
+	}{
+		"upgrade": {
+			dryRun:          common.DryRunNone,
+			expectedManager: common.DefaultFieldManager,
+		},
+		"client dry-run": {
+			dryRun:          common.DryRunClient,
+			expectedManager: "kubectl-client-side-apply",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, live.DeepCopy())
+			upgrader := &Upgrader{
+				Client: client,
+				Mapper: mapper,
+			}
+			changed, err := upgrader.Upgrade(context.TODO(), id, tc.dryRun)
+			require.NoError(t, err)
+			assert.True(t, changed)
+
+			obj, err := client.Resource(gvr).Namespace("default").Get(context.TODO(), "cm", metav1.GetOptions{})
+			require.NoError(t, err)
+			entries := obj.GetManagedFields()
+			require.Len(t, entries, 1)
+			assert.Equal(t, tc.expectedManager, entries[0].Manager)
+		})
+	}
+}
+
+func TestUpgrader_Upgrade_NotFound(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme)
+	upgrader := &Upgrader{
+		Client: client,
+		Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+			scheme.Scheme.PrioritizedVersionsAllGroups()...),
+	}
+	changed, err := upgrader.Upgrade(context.TODO(), object.UnstructuredToObjMetadata(configMap()), common.DryRunNone)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
@@ -480,6 +480,8 @@ var waitStatusWeight = map[event.WaitEventStatus]int{
 	event.ReconcileSuccessful: 2,
 	event.ReconcileFailed:     3,
 	event.ReconcileTimeout:    4,
+	// Not waited for, so sorted like skipped
+	event.ReconcileSkippedByAnnotation: 1,
 }
 
 func lessWaitStatus(x, y event.WaitEventStatus) bool {
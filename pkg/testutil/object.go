@@ -160,3 +160,29 @@ func (d dependsOnMutator) Mutate(u *unstructured.Unstructured) {
 		d.t.FailNow()
 	}
 }
+
+// AddAnnotation returns a testutil.Mutator which sets the passed annotation
+// key and value on the object which is mutated.
+func AddAnnotation(key, value string) Mutator {
+	return annotationMutator{
+		key:   key,
+		value: value,
+	}
+}
+
+// annotationMutator encapsulates fields for adding an annotation to a test
+// object. Implements the Mutator interface.
+type annotationMutator struct {
+	key   string
+	value string
+}
+
+// Mutate sets the annotation on the supplied object.
+func (a annotationMutator) Mutate(u *unstructured.Unstructured) {
+	annos := u.GetAnnotations()
+	if annos == nil {
+		annos = make(map[string]string)
+	}
+	annos[a.key] = a.value
+	u.SetAnnotations(annos)
+}